@@ -24,6 +24,9 @@ const (
 	EvictAllKeysRandom = "allkeys-random"
 	EvictAllKeysLRU    = "allkeys-lru"
 	EvictAllKeysLFU    = "allkeys-lfu"
+	EvictVolatileTTL   = "volatile-ttl"
+	EvictVolatileLRU   = "volatile-lru"
+	EvictNoEviction    = "noeviction"
 )
 
 var (
@@ -34,6 +37,10 @@ var (
 	EnableHTTP           = true
 	HTTPPort             = 8082
 
+	// EnableEventLoop serves the connections of the multi-threaded mode from
+	// an event loop instead of a goroutine per connection
+	EnableEventLoop = false
+
 	EnableWebsocket = true
 	WebsocketPort   = 8379
 
@@ -44,6 +51,10 @@ var (
 	FileLocation         = utils.EmptyStr
 
 	InitConfigCmd = false
+
+	// CheckData validates the AOF file and exits, CheckRepair also repairs it
+	CheckData   = false
+	CheckRepair = false
 )
 
 type Config struct {
@@ -64,14 +75,47 @@ type Config struct {
 		PersistenceEnabled     bool          `mapstructure:"persistenceenabled"`
 		WriteAOFOnCleanup      bool          `mapstructure:"writeaofoncleanup"`
 		LFULogFactor           int           `mapstructure:"lfulogfactor"`
+		LFUDecayTime           int           `mapstructure:"lfudecaytime"`
 		LogLevel               string        `mapstructure:"loglevel"`
 		PrettyPrintLogs        bool          `mapstructure:"prettyprintlogs"`
 		EnableMultiThreading   bool          `mapstructure:"enablemultithreading"`
 		StoreMapInitSize       int           `mapstructure:"storemapinitsize"`
+		Databases              int           `mapstructure:"databases"`     // number of logical databases of the shards, see SELECT
+		ShardAffinity          string        `mapstructure:"shardaffinity"` // how the shards are pinned to the CPUs: none, thread, cpu or numa
+		CompressionThreshold   int           `mapstructure:"compressionthreshold"`
+		ListCompressDepth      int           `mapstructure:"listcompressdepth"` // number of nodes of the lists kept plain at each end, the interior ones being compressed, 0 to disable
+		MaxClientsPerIP        int32         `mapstructure:"maxclientsperip"`
+		IdleTimeout            time.Duration `mapstructure:"idletimeout"`
+		SubscriberIdleTimeout  time.Duration `mapstructure:"subscriberidletimeout"`
+		WatchdogThreshold      time.Duration `mapstructure:"watchdogthreshold"`
+		TierHotKeys            int           `mapstructure:"tierhotkeys"`
+		TierPath               string        `mapstructure:"tierpath"`
+		WriteBehindPatterns    []string      `mapstructure:"writebehindpatterns"`
+		WriteBehindWebhook     string        `mapstructure:"writebehindwebhook"`
+		SketchHashFamily       string        `mapstructure:"sketchhashfamily"`
+		SketchHashSeed         uint64        `mapstructure:"sketchhashseed"`
+		KeyspaceSampleInterval time.Duration `mapstructure:"keyspacesampleinterval"`
+		KeyspaceSampleSize     int           `mapstructure:"keyspacesamplesize"`
+		ReplicaMaxStaleness    time.Duration `mapstructure:"replicamaxstaleness"`
+		ReplicaTokenTimeout    time.Duration `mapstructure:"replicatokentimeout"` // how long a replica holds back the commands of a session it has not caught up with, see CLIENT READAFTER
+		DefaultTTLs            []string      `mapstructure:"defaultttls"`         // default TTL policies as pattern=ttl, e.g. session:*=30m, see CONFIG DEFAULTTTL
+		ReplyCompat            string        `mapstructure:"replycompat"`
+		IntegrityCheckKeys     int           `mapstructure:"integritycheckkeys"`
+		IntegrityCheckRepair   bool          `mapstructure:"integritycheckrepair"`
+		ActiveExpireFrequency  time.Duration `mapstructure:"activeexpirefrequency"`
+		ActiveExpireEffort     int           `mapstructure:"activeexpireeffort"`
+		ScanTimeBudget         time.Duration `mapstructure:"scantimebudget"`
+		SnapshotDir            string        `mapstructure:"snapshotdir"` // directory RESTORE-SNAPSHOT loads the snapshots from, the command being disabled if empty
 	} `mapstructure:"server"`
 	Auth struct {
-		UserName string `mapstructure:"username"`
-		Password string `mapstructure:"password"`
+		UserName       string `mapstructure:"username"`
+		Password       string `mapstructure:"password"`
+		RevokePolicy   string `mapstructure:"revokepolicy"`   // what happens to the connections whose user lost access, kill or downgrade
+		TokenSecret    string `mapstructure:"tokensecret"`    // shared secret of the HS256 bearer tokens accepted by AUTH, none if empty
+		TokenJWKSURL   string `mapstructure:"tokenjwksurl"`   // URL of the JWKS holding the public keys of the bearer tokens accepted by AUTH, none if empty
+		TokenIssuer    string `mapstructure:"tokenissuer"`    // iss claim the bearer tokens must have, any if empty
+		TokenAudience  string `mapstructure:"tokenaudience"`  // aud claim the bearer tokens must hold, any if empty
+		TokenUserClaim string `mapstructure:"tokenuserclaim"` // claim of the bearer tokens naming their ACL user
 	} `mapstructure:"auth"`
 	Network struct {
 		IOBufferLength    int `mapstructure:"iobufferlength"`
@@ -98,10 +142,37 @@ var baseConfig = Config{
 		PersistenceEnabled     bool          `mapstructure:"persistenceenabled"`
 		WriteAOFOnCleanup      bool          `mapstructure:"writeaofoncleanup"`
 		LFULogFactor           int           `mapstructure:"lfulogfactor"`
+		LFUDecayTime           int           `mapstructure:"lfudecaytime"`
 		LogLevel               string        `mapstructure:"loglevel"`
 		PrettyPrintLogs        bool          `mapstructure:"prettyprintlogs"`
 		EnableMultiThreading   bool          `mapstructure:"enablemultithreading"`
 		StoreMapInitSize       int           `mapstructure:"storemapinitsize"`
+		Databases              int           `mapstructure:"databases"`     // number of logical databases of the shards, see SELECT
+		ShardAffinity          string        `mapstructure:"shardaffinity"` // how the shards are pinned to the CPUs: none, thread, cpu or numa
+		CompressionThreshold   int           `mapstructure:"compressionthreshold"`
+		ListCompressDepth      int           `mapstructure:"listcompressdepth"` // number of nodes of the lists kept plain at each end, the interior ones being compressed, 0 to disable
+		MaxClientsPerIP        int32         `mapstructure:"maxclientsperip"`
+		IdleTimeout            time.Duration `mapstructure:"idletimeout"`
+		SubscriberIdleTimeout  time.Duration `mapstructure:"subscriberidletimeout"`
+		WatchdogThreshold      time.Duration `mapstructure:"watchdogthreshold"`
+		TierHotKeys            int           `mapstructure:"tierhotkeys"`
+		TierPath               string        `mapstructure:"tierpath"`
+		WriteBehindPatterns    []string      `mapstructure:"writebehindpatterns"`
+		WriteBehindWebhook     string        `mapstructure:"writebehindwebhook"`
+		SketchHashFamily       string        `mapstructure:"sketchhashfamily"`
+		SketchHashSeed         uint64        `mapstructure:"sketchhashseed"`
+		KeyspaceSampleInterval time.Duration `mapstructure:"keyspacesampleinterval"`
+		KeyspaceSampleSize     int           `mapstructure:"keyspacesamplesize"`
+		ReplicaMaxStaleness    time.Duration `mapstructure:"replicamaxstaleness"`
+		ReplicaTokenTimeout    time.Duration `mapstructure:"replicatokentimeout"` // how long a replica holds back the commands of a session it has not caught up with, see CLIENT READAFTER
+		DefaultTTLs            []string      `mapstructure:"defaultttls"`         // default TTL policies as pattern=ttl, e.g. session:*=30m, see CONFIG DEFAULTTTL
+		ReplyCompat            string        `mapstructure:"replycompat"`
+		IntegrityCheckKeys     int           `mapstructure:"integritycheckkeys"`
+		IntegrityCheckRepair   bool          `mapstructure:"integritycheckrepair"`
+		ActiveExpireFrequency  time.Duration `mapstructure:"activeexpirefrequency"`
+		ActiveExpireEffort     int           `mapstructure:"activeexpireeffort"`
+		ScanTimeBudget         time.Duration `mapstructure:"scantimebudget"`
+		SnapshotDir            string        `mapstructure:"snapshotdir"` // directory RESTORE-SNAPSHOT loads the snapshots from, the command being disabled if empty
 	}{
 		Addr:                   DefaultHost,
 		Port:                   DefaultPort,
@@ -119,17 +190,56 @@ var baseConfig = Config{
 		PersistenceEnabled:     true,
 		WriteAOFOnCleanup:      false,
 		LFULogFactor:           10,
+		LFUDecayTime:           1,
 		LogLevel:               "info",
 		PrettyPrintLogs:        false,
 		EnableMultiThreading:   false,
 		StoreMapInitSize:       1024000,
+		Databases:              16,
+		ShardAffinity:          "none",
+		CompressionThreshold:   0,
+		ListCompressDepth:      0,
+		MaxClientsPerIP:        int32(0),
+		IdleTimeout:            0,
+		SubscriberIdleTimeout:  0,
+		WatchdogThreshold:      0,
+		TierHotKeys:            0,
+		TierPath:               "./dice-tier.dat",
+		WriteBehindPatterns:    nil,
+		WriteBehindWebhook:     "",
+		SketchHashFamily:       "murmur3",
+		SketchHashSeed:         0,
+		KeyspaceSampleInterval: 10 * time.Second,
+		KeyspaceSampleSize:     1000,
+		ReplicaMaxStaleness:    0,
+		ReplicaTokenTimeout:    1 * time.Second,
+		DefaultTTLs:            nil,
+		ReplyCompat:            "",
+		IntegrityCheckKeys:     0,
+		IntegrityCheckRepair:   false,
+		ActiveExpireFrequency:  100 * time.Millisecond,
+		ActiveExpireEffort:     1,
+		ScanTimeBudget:         1 * time.Millisecond,
+		SnapshotDir:            "",
 	},
 	Auth: struct {
-		UserName string `mapstructure:"username"`
-		Password string `mapstructure:"password"`
+		UserName       string `mapstructure:"username"`
+		Password       string `mapstructure:"password"`
+		RevokePolicy   string `mapstructure:"revokepolicy"`   // what happens to the connections whose user lost access, kill or downgrade
+		TokenSecret    string `mapstructure:"tokensecret"`    // shared secret of the HS256 bearer tokens accepted by AUTH, none if empty
+		TokenJWKSURL   string `mapstructure:"tokenjwksurl"`   // URL of the JWKS holding the public keys of the bearer tokens accepted by AUTH, none if empty
+		TokenIssuer    string `mapstructure:"tokenissuer"`    // iss claim the bearer tokens must have, any if empty
+		TokenAudience  string `mapstructure:"tokenaudience"`  // aud claim the bearer tokens must hold, any if empty
+		TokenUserClaim string `mapstructure:"tokenuserclaim"` // claim of the bearer tokens naming their ACL user
 	}{
-		UserName: "dice",
-		Password: RequirePass,
+		UserName:       "dice",
+		Password:       RequirePass,
+		RevokePolicy:   "kill",
+		TokenSecret:    "",
+		TokenJWKSURL:   "",
+		TokenIssuer:    "",
+		TokenAudience:  "",
+		TokenUserClaim: "sub",
 	},
 	Network: struct {
 		IOBufferLength    int `mapstructure:"iobufferlength"`
@@ -282,16 +392,21 @@ func setUpViperConfig(configFilePath string) {
 }
 
 func mergeFlagsWithConfig() {
+	mergeFlags(DiceConfig)
+}
+
+// mergeFlags overrides the configurations of c with the command line flags
+func mergeFlags(c *Config) {
 	if RequirePass != utils.EmptyStr {
-		DiceConfig.Auth.Password = RequirePass
+		c.Auth.Password = RequirePass
 	}
 
 	if Host != DefaultHost {
-		DiceConfig.Server.Addr = Host
+		c.Server.Addr = Host
 	}
 
 	if Port != DefaultPort {
-		DiceConfig.Server.Port = Port
+		c.Server.Port = Port
 	}
 }
 
@@ -318,4 +433,5 @@ func getConfigPath() string {
 // ResetConfig resets the DiceConfig to default configurations. This function is only used for testing purposes
 func ResetConfig() {
 	DiceConfig = &defaultConfig
+	live.Store(nil)
 }
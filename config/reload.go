@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+var ErrNoConfigFile = errors.New("no config file loaded, nothing to reload")
+
+var (
+	// live is the snapshot of the configuration published by the last reload,
+	// nil till the config is first reloaded.
+	live atomic.Pointer[Config]
+	// reloadMu serializes the reloads, e.g. SIGHUP racing with CONFIG RELOAD.
+	reloadMu sync.Mutex
+)
+
+// Current returns the current configuration, to be read for the hot-reloadable
+// settings. It is DiceConfig till the config is reloaded, then the snapshot
+// published by the last reload. A snapshot is never modified once published,
+// so that it can be read from any goroutine while a reload is in progress.
+func Current() *Config {
+	if c := live.Load(); c != nil {
+		return c
+	}
+	return DiceConfig
+}
+
+// hotReloadable lists the settings applied live by Reload. They are read from
+// Current every time they are used, whereas the other settings are only read at startup and
+// require a restart to take effect.
+var hotReloadable = map[string]bool{
+	"server.maxmemory":              true,
+	"server.evictionpolicy":         true,
+	"server.evictionratio":          true,
+	"server.keyslimit":              true,
+	"server.aoffile":                true,
+	"server.writeaofoncleanup":      true,
+	"server.lfulogfactor":           true,
+	"server.lfudecaytime":           true,
+	"server.compressionthreshold":   true,
+	"server.listcompressdepth":      true,
+	"server.idletimeout":            true,
+	"server.subscriberidletimeout":  true,
+	"server.keyspacesampleinterval": true,
+	"server.keyspacesamplesize":     true,
+	"server.replicamaxstaleness":    true,
+	"server.replicatokentimeout":    true,
+	"server.defaultttls":            true,
+	"server.replycompat":            true,
+	"server.integritycheckkeys":     true,
+	"server.integritycheckrepair":   true,
+	"server.activeexpireeffort":     true,
+	"server.scantimebudget":         true,
+	"server.snapshotdir":            true,
+	"auth.revokepolicy":             true,
+	"network.iobufferlength":        true,
+	"network.iobufferlengthmax":     true,
+}
+
+// ReloadResult reports the settings that changed during a reload.
+type ReloadResult struct {
+	Applied         []string // settings changed and applied live
+	RestartRequired []string // settings changed in the file which only take effect after a restart
+}
+
+// Reload reads the config file again and publishes a snapshot of the current
+// configuration with the hot-reloadable settings that changed applied, see
+// Current. The other changed settings are left untouched and reported as
+// requiring a restart. The command line flags keep precedence over the config
+// file, as they do at startup. The snapshot is checked with validate, if not
+// nil, and left unpublished if it is invalid, the configuration being kept as
+// is then.
+func Reload(validate func(*Config) error) (ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if viper.ConfigFileUsed() == "" {
+		return ReloadResult{}, ErrNoConfigFile
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		return ReloadResult{}, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	current := Current()
+	next := *current
+	if err := viper.Unmarshal(&next); err != nil {
+		return ReloadResult{}, fmt.Errorf("error unmarshalling config file: %w", err)
+	}
+	mergeFlags(&next)
+
+	snapshot, res := applyConfig(current, &next)
+	if validate != nil {
+		if err := validate(snapshot); err != nil {
+			return ReloadResult{}, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	live.Store(snapshot)
+	return res, nil
+}
+
+// applyConfig returns a copy of current with the hot-reloadable settings of
+// next that differ, and reports the changed settings sorted by name. current
+// is left untouched.
+func applyConfig(current, next *Config) (*Config, ReloadResult) {
+	var res ReloadResult
+
+	snapshot := *current
+	cur, nxt := reflect.ValueOf(&snapshot).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < cur.NumField(); i++ {
+		section := cur.Type().Field(i).Tag.Get("mapstructure")
+		curSection, nxtSection := cur.Field(i), nxt.Field(i)
+
+		for j := 0; j < curSection.NumField(); j++ {
+			name := section + "." + curSection.Type().Field(j).Tag.Get("mapstructure")
+			curField, nxtField := curSection.Field(j), nxtSection.Field(j)
+			if reflect.DeepEqual(curField.Interface(), nxtField.Interface()) {
+				continue
+			}
+
+			if !hotReloadable[name] {
+				res.RestartRequired = append(res.RestartRequired, name)
+				continue
+			}
+			curField.Set(nxtField)
+			res.Applied = append(res.Applied, name)
+		}
+	}
+
+	sort.Strings(res.Applied)
+	sort.Strings(res.RestartRequired)
+	return &snapshot, res
+}
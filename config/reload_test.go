@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"gotest.tools/v3/assert"
+)
+
+func TestReload(t *testing.T) {
+	saved := *DiceConfig
+	defer func() {
+		*DiceConfig = saved
+		live.Store(nil)
+		viper.Reset()
+	}()
+
+	path := filepath.Join(t.TempDir(), DefaultConfigName)
+	writeFile := func(content string) {
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	viper.Reset()
+	_, err := Reload(nil)
+	assert.ErrorIs(t, err, ErrNoConfigFile)
+
+	writeFile("[server]\nkeyslimit = 100\nport = 7379\n")
+	viper.SetConfigFile(path)
+	assert.NilError(t, viper.ReadInConfig())
+	assert.NilError(t, viper.Unmarshal(DiceConfig))
+
+	writeFile("[server]\nkeyslimit = 200\nport = 7380\nidletimeout = \"30s\"\n[network]\niobufferlength = 1024\n")
+	res, err := Reload(nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"network.iobufferlength", "server.idletimeout", "server.keyslimit"}, res.Applied)
+	assert.DeepEqual(t, []string{"server.port"}, res.RestartRequired)
+
+	assert.Equal(t, 200, Current().Server.KeysLimit)
+	assert.Equal(t, 30*time.Second, Current().Server.IdleTimeout)
+	assert.Equal(t, 1024, Current().Network.IOBufferLength)
+	assert.Equal(t, DefaultPort, Current().Server.Port)
+	// the configuration read at startup is never modified by a reload
+	assert.Equal(t, 100, DiceConfig.Server.KeysLimit)
+
+	// reloading an unchanged file is a no-op
+	res, err = Reload(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(res.Applied))
+	assert.DeepEqual(t, []string{"server.port"}, res.RestartRequired)
+
+	// an invalid config is not published
+	writeFile("[server]\nkeyslimit = 400\n")
+	errInvalid := errors.New("invalid")
+	_, err = Reload(func(c *Config) error {
+		assert.Equal(t, 400, c.Server.KeysLimit)
+		return errInvalid
+	})
+	assert.ErrorIs(t, err, errInvalid)
+	assert.Equal(t, 200, Current().Server.KeysLimit)
+}
+
+func TestReloadConcurrentReads(t *testing.T) {
+	defer func() {
+		live.Store(nil)
+		viper.Reset()
+	}()
+
+	path := filepath.Join(t.TempDir(), DefaultConfigName)
+	assert.NilError(t, os.WriteFile(path, []byte("[server]\nkeyslimit = 300\n"), 0o600))
+	viper.SetConfigFile(path)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = Current().Server.KeysLimit
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		_, err := Reload(nil)
+		assert.NilError(t, err)
+	}
+	<-done
+	assert.Equal(t, 300, Current().Server.KeysLimit)
+}
@@ -0,0 +1,97 @@
+package async
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestACL(t *testing.T) {
+	admin := getLocalConnection()
+	defer admin.Close()
+	defer FireCommand(admin, "ACL DELUSER acl:alice acl:bob")
+	defer FireCommand(admin, "ACL LOG RESET")
+
+	assert.Equal(t, "OK", FireCommand(admin, "ACL SETUSER acl:alice on >secret +get +acl"))
+	assert.Equal(t, "OK", FireCommand(admin, "ACL SETUSER acl:bob on nopass allcommands -flushdb"))
+	assert.Equal(t, "user acl:bob on nopass +@all -flushdb", FireCommand(admin, "ACL LIST").([]interface{})[1])
+
+	t.Run("denied commands are logged", func(t *testing.T) {
+		client := getLocalConnection()
+		defer client.Close()
+
+		assert.Equal(t, "WRONGPASS invalid username-password pair or user is disabled", FireCommand(client, "AUTH acl:alice wrong"))
+		assert.Equal(t, "OK", FireCommand(client, "AUTH acl:alice secret"))
+		assert.Equal(t, "(nil)", FireCommand(client, "GET acl:key"))
+		assert.Equal(t, "NOPERM User acl:alice has no permissions to run the 'set' command", FireCommand(client, "SET acl:key v"))
+		assert.Equal(t, "NOPERM User acl:alice has no permissions to run the 'set' command", FireCommand(client, "SET acl:key v"))
+
+		entries := FireCommand(admin, "ACL LOG 1").([]interface{})
+		assert.Equal(t, 1, len(entries))
+		entry := entries[0].([]interface{})
+		assert.DeepEqual(t, []interface{}{"count", int64(2), "reason", "command", "object", "set", "username", "acl:alice"}, entry[:8])
+	})
+
+	t.Run("the commands of a batch are authorized", func(t *testing.T) {
+		client := getLocalConnection()
+		defer client.Close()
+
+		denied := "NOPERM User acl:bob has no permissions to run the 'flushdb' command"
+		assert.Equal(t, "OK", FireCommand(client, "AUTH acl:bob anything"))
+		assert.Equal(t, denied, FireCommand(client, "EXECBATCH 2 3 SET acl:key v 1 FLUSHDB"))
+		assert.Equal(t, int64(0), FireCommand(client, "EXISTS acl:key"))
+		assert.Equal(t, "OK", FireCommand(client, "MULTI"))
+		assert.Equal(t, "QUEUED", FireCommand(client, "EXECBATCH 1 1 FLUSHDB"))
+		assert.Equal(t, "QUEUED", FireCommand(client, "SET acl:key v"))
+		assert.DeepEqual(t, []interface{}{denied, "OK"}, FireCommand(client, "EXEC"))
+
+		entry := FireCommand(admin, "ACL LOG 1").([]interface{})[0].([]interface{})
+		assert.DeepEqual(t, []interface{}{"count", int64(2), "reason", "command", "object", "flushdb", "username", "acl:bob"}, entry[:8])
+		FireCommand(admin, "DEL acl:key")
+	})
+
+	t.Run("permissions changed apply to the connected clients", func(t *testing.T) {
+		client := getLocalConnection()
+		defer client.Close()
+
+		assert.Equal(t, "OK", FireCommand(client, "AUTH acl:alice secret"))
+		assert.Equal(t, "OK", FireCommand(admin, "ACL SETUSER acl:alice +set"))
+		assert.Equal(t, "OK", FireCommand(client, "SET acl:key v"))
+		assert.Equal(t, "OK", FireCommand(admin, "ACL SETUSER acl:alice -get"))
+		assert.Equal(t, "NOPERM User acl:alice has no permissions to run the 'get' command", FireCommand(client, "GET acl:key"))
+		FireCommand(admin, "DEL acl:key")
+	})
+
+	t.Run("clients of a user disabled are killed", func(t *testing.T) {
+		client := getLocalConnection()
+		defer client.Close()
+
+		assert.Equal(t, "OK", FireCommand(client, "AUTH acl:bob anything"))
+		assert.Equal(t, "OK", FireCommand(client, "SET acl:key v"))
+		assert.Equal(t, "OK", FireCommand(admin, "ACL SETUSER acl:bob off"))
+		assertClosed(t, client)
+		FireCommand(admin, "DEL acl:key")
+	})
+
+	t.Run("clients of a user deleted are killed", func(t *testing.T) {
+		client := getLocalConnection()
+		defer client.Close()
+
+		assert.Equal(t, "OK", FireCommand(client, "AUTH acl:alice secret"))
+		assert.Equal(t, int64(1), FireCommand(admin, "ACL DELUSER acl:alice"))
+		assertClosed(t, client)
+	})
+}
+
+// assertClosed asserts that the server closes the connection. Nothing is sent
+// on it, as the server resets the connections it closes with data unread.
+func assertClosed(t *testing.T, conn net.Conn) {
+	t.Helper()
+	assert.NilError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err := conn.Read(make([]byte, 1))
+	assert.Assert(t, err != nil && !errors.Is(err, os.ErrDeadlineExceeded), "expected the connection to be closed, got %v", err)
+}
@@ -0,0 +1,47 @@
+package async
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestClientPause(t *testing.T) {
+	orchestrator := getLocalConnection()
+	client := getLocalConnection()
+	defer orchestrator.Close()
+	defer client.Close()
+
+	FireCommand(client, "SET pause:key v1")
+	defer FireCommand(client, "DEL pause:key")
+
+	t.Run("WRITE holds back the write commands only", func(t *testing.T) {
+		assert.Equal(t, "OK", FireCommand(orchestrator, "CLIENT PAUSE 300 WRITE"))
+		start := time.Now()
+		assert.Equal(t, "v1", FireCommand(client, "GET pause:key"))
+		assert.Assert(t, time.Since(start) < 200*time.Millisecond)
+
+		assert.Equal(t, "OK", FireCommand(client, "SET pause:key v2"))
+		assert.Assert(t, time.Since(start) >= 300*time.Millisecond)
+		assert.Equal(t, "v2", FireCommand(client, "GET pause:key"))
+	})
+
+	t.Run("ALL holds back all the commands till unpaused", func(t *testing.T) {
+		assert.Equal(t, "OK", FireCommand(orchestrator, "CLIENT PAUSE 10000"))
+		time.AfterFunc(200*time.Millisecond, func() {
+			FireCommand(orchestrator, "CLIENT UNPAUSE")
+		})
+
+		start := time.Now()
+		assert.Equal(t, "v2", FireCommand(client, "GET pause:key"))
+		elapsed := time.Since(start)
+		assert.Assert(t, elapsed >= 200*time.Millisecond && elapsed < 5*time.Second, elapsed)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR timeout is negative", FireCommand(orchestrator, "CLIENT PAUSE -1"))
+		assert.Equal(t, "ERR timeout is not an integer or out of range", FireCommand(orchestrator, "CLIENT PAUSE soon"))
+		assert.Equal(t, "ERR syntax error", FireCommand(orchestrator, "CLIENT PAUSE 100 READ"))
+	})
+}
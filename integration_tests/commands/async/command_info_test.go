@@ -11,17 +11,17 @@ var getInfoTestCases = []struct {
 	inCmd    string
 	expected interface{}
 }{
-	{"Set command", "SET", []interface{}{[]interface{}{"SET", int64(-3), int64(1), int64(0), int64(0)}}},
-	{"Get command", "GET", []interface{}{[]interface{}{"GET", int64(2), int64(1), int64(0), int64(0)}}},
-	{"Ping command", "PING", []interface{}{[]interface{}{"PING", int64(-1), int64(0), int64(0), int64(0)}}},
+	{"Set command", "SET", []interface{}{[]interface{}{"SET", int64(-3), int64(1), int64(0), int64(0), []interface{}{"write"}}}},
+	{"Get command", "GET", []interface{}{[]interface{}{"GET", int64(2), int64(1), int64(0), int64(0), []interface{}{"readonly"}}}},
+	{"Ping command", "PING", []interface{}{[]interface{}{"PING", int64(-1), int64(0), int64(0), int64(0), []interface{}{}}}},
 	{"Invalid command", "INVALID_CMD", []interface{}{"(nil)"}},
 	{"Combination of valid and Invalid command", "SET INVALID_CMD", []interface{}{
-		[]interface{}{"SET", int64(-3), int64(1), int64(0), int64(0)},
+		[]interface{}{"SET", int64(-3), int64(1), int64(0), int64(0), []interface{}{"write"}},
 		"(nil)",
 	}},
 	{"Combination of multiple valid commands", "SET GET", []interface{}{
-		[]interface{}{"SET", int64(-3), int64(1), int64(0), int64(0)},
-		[]interface{}{"GET", int64(2), int64(1), int64(0), int64(0)},
+		[]interface{}{"SET", int64(-3), int64(1), int64(0), int64(0), []interface{}{"write"}},
+		[]interface{}{"GET", int64(2), int64(1), int64(0), int64(0), []interface{}{"readonly"}},
 	}},
 }
 
@@ -0,0 +1,64 @@
+package async
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestExecBatch(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name   string
+		cmds   []string
+		expect []interface{}
+	}{
+		{
+			name: "Runs the commands and returns their replies",
+			cmds: []string{"EXECBATCH 4 3 SET k1 v1 3 set k2 v2 3 MGET k1 k2 2 INCR k1", "GET k2"},
+			expect: []interface{}{
+				[]interface{}{"OK", "OK", []interface{}{"v1", "v2"}, "ERR value is not an integer or out of range"},
+				"v2",
+			},
+		},
+		{
+			name:   "Runs none of the commands if one is invalid",
+			cmds:   []string{"EXECBATCH 2 3 SET k1 v1 2 FOO k1", "EXISTS k1"},
+			expect: []interface{}{"ERR unknown command 'FOO', with args beginning with: 'k1' ", int64(0)},
+		},
+		{
+			name:   "Checks the arity of the commands",
+			cmds:   []string{"EXECBATCH 2 3 SET k1 v1 1 GET", "EXISTS k1"},
+			expect: []interface{}{"ERR wrong number of arguments for 'get' command", int64(0)},
+		},
+		{
+			name:   "Rejects the commands changing the state of the client",
+			cmds:   []string{"EXECBATCH 1 1 MULTI", "EXECBATCH 1 3 BLPOP l 0"},
+			expect: []interface{}{"ERR MULTI is not allowed in EXECBATCH", "ERR BLPOP is not allowed in EXECBATCH"},
+		},
+		{
+			name: "Rejects malformed batches",
+			cmds: []string{"EXECBATCH 2 3 SET k1 v1", "EXECBATCH 1 3 SET k1 v1 extra", "EXECBATCH 0 1 PING", "EXECBATCH 1 x PING",
+				"EXECBATCH 1001 1 PING"},
+			expect: []interface{}{"ERR syntax error", "ERR syntax error", "ERR numcommands should be greater than 0",
+				"ERR value is not an integer or out of range", "ERR EXECBATCH holds at most 1000 commands"},
+		},
+		{
+			name:   "Runs in a transaction",
+			cmds:   []string{"MULTI", "EXECBATCH 2 3 SET k1 v1 2 GET k1", "EXEC"},
+			expect: []interface{}{"OK", "QUEUED", []interface{}{[]interface{}{"OK", "v1"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			FireCommand(conn, "DEL k1 k2")
+			for i, cmd := range tc.cmds {
+				result := FireCommand(conn, cmd)
+				assert.DeepEqual(t, tc.expect[i], result)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package async
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHSETEX(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "DEL session k")
+	defer FireCommand(conn, "DEL session k")
+
+	testCases := []TestCase{
+		{
+			commands: []string{"HSETEX session 100 user alice role admin", "HGET session user", "TTL session"},
+			expected: []interface{}{TWO, "alice", int64(100)},
+		},
+		{
+			commands: []string{"HSETEX session 50 user bob", "HGET session user", "TTL session"},
+			expected: []interface{}{ZERO, "bob", int64(50)},
+		},
+		{
+			commands: []string{"HSETEX session 0 user bob", "HSETEX session 10 user"},
+			expected: []interface{}{
+				"ERR invalid expire time in 'hsetex' command",
+				"ERR wrong number of arguments for 'hsetex' command",
+			},
+		},
+		{
+			commands: []string{"SET k v", "HSETEX k 10 f v"},
+			expected: []interface{}{"OK", "WRONGTYPE Operation against a key holding the wrong kind of value"},
+		},
+	}
+
+	for _, tc := range testCases {
+		for i, cmd := range tc.commands {
+			result := FireCommand(conn, cmd)
+			if strings.HasPrefix(cmd, "TTL") {
+				// a second may elapse before the TTL is read
+				ttl := tc.expected[i].(int64)
+				assert.Assert(t, result.(int64) <= ttl && result.(int64) >= ttl-1, "Expected %v to be %v or %v", result, ttl-1, ttl)
+				continue
+			}
+			assert.DeepEqual(t, tc.expected[i], result)
+		}
+	}
+}
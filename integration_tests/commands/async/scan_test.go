@@ -0,0 +1,135 @@
+package async
+
+import (
+	"sort"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestScan(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+	}{
+		{
+			name:     "SCAN on empty database",
+			commands: []string{"SCAN 0"},
+			expected: []interface{}{[]interface{}{"0", []interface{}{}}},
+		},
+		{
+			name:     "SCAN with MATCH and TYPE",
+			commands: []string{"SADD scanset a", "SCAN 0 MATCH scan* TYPE set"},
+			expected: []interface{}{int64(1), []interface{}{"0", []interface{}{"scanset"}}},
+		},
+		{
+			name:     "SCAN with invalid option",
+			commands: []string{"SCAN 0 FOO"},
+			expected: []interface{}{"ERR syntax error"},
+		},
+		{
+			name:     "SCAN with unknown snapshot cursor",
+			commands: []string{"SCAN 4294967296"},
+			expected: []interface{}{"ERR invalid or expired snapshot cursor"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.DeepEqual(t, tc.expected[i], result)
+			}
+		})
+	}
+}
+
+func TestScanBatches(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	FireCommand(conn, "MSET scan:1 v scan:2 v scan:3 v")
+
+	var keys []string
+	result := FireCommand(conn, "SCAN 0 COUNT 2").([]interface{})
+	assert.Equal(t, 2, len(result[1].([]interface{})))
+	for {
+		for _, k := range result[1].([]interface{}) {
+			keys = append(keys, k.(string))
+		}
+		cursor := result[0].(string)
+		if cursor == "0" {
+			break
+		}
+		result = FireCommand(conn, "SCAN "+cursor+" COUNT 2").([]interface{})
+	}
+
+	sort.Strings(keys)
+	assert.DeepEqual(t, []string{"scan:1", "scan:2", "scan:3"}, keys)
+}
+
+func TestScanSnapshot(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	FireCommand(conn, "MSET k1 v k2 v k3 v k4 v k5 v")
+
+	seen := make(map[string]int)
+	result := FireCommand(conn, "SCAN 0 COUNT 2 SNAPSHOT").([]interface{})
+	for {
+		for _, k := range result[1].([]interface{}) {
+			seen[k.(string)]++
+		}
+		cursor := result[0].(string)
+		if cursor == "0" {
+			break
+		}
+		// keys added during the scan are not returned
+		FireCommand(conn, "SET "+cursor+" v")
+		result = FireCommand(conn, "SCAN "+cursor+" COUNT 2").([]interface{})
+	}
+
+	assert.DeepEqual(t, map[string]int{"k1": 1, "k2": 1, "k3": 1, "k4": 1, "k5": 1}, seen)
+}
+
+func TestCollectionScan(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	FireCommand(conn, "SADD myset a b c ab")
+	FireCommand(conn, "HSET myhash f1 v1 f2 v2")
+	FireCommand(conn, "ZADD myzset 1 a 2 b")
+
+	for _, name := range []string{"SSCAN", "HSCAN", "ZSCAN"} {
+		assert.DeepEqual(t, []interface{}{"0", []interface{}{}}, FireCommand(conn, name+" missing 0"))
+		assert.DeepEqual(t, "ERR invalid cursor", FireCommand(conn, name+" myset abc"))
+	}
+
+	result := FireCommand(conn, "SSCAN myset 0 MATCH a*").([]interface{})
+	assert.Equal(t, "0", result[0])
+	assert.DeepEqual(t, 2, len(result[1].([]interface{})))
+
+	result = FireCommand(conn, "HSCAN myhash 0 MATCH f1").([]interface{})
+	assert.DeepEqual(t, []interface{}{"0", []interface{}{"f1", "v1"}}, result)
+
+	result = FireCommand(conn, "ZSCAN myzset 0 MATCH b").([]interface{})
+	assert.DeepEqual(t, []interface{}{"0", []interface{}{"b", "2"}}, result)
+
+	assert.DeepEqual(t, "WRONGTYPE Operation against a key holding the wrong kind of value", FireCommand(conn, "HSCAN myset 0"))
+}
@@ -13,10 +13,73 @@ func TestSelect(t *testing.T) {
 	t.Run("SELECT command response", func(t *testing.T) {
 		actual := FireCommand(conn, "SELECT 1")
 		assert.DeepEqual(t, "OK", actual)
+		FireCommand(conn, "SELECT 0")
 	})
 
 	t.Run("SELECT command error response", func(t *testing.T) {
 		actual := FireCommand(conn, "SELECT")
 		assert.DeepEqual(t, "ERR wrong number of arguments for 'select' command", actual)
+		assert.DeepEqual(t, "ERR DB index is out of range", FireCommand(conn, "SELECT 16"))
+		assert.DeepEqual(t, "ERR value is not an integer or out of range", FireCommand(conn, "SELECT one"))
 	})
+
+	t.Run("SELECT isolates the keys of the databases", func(t *testing.T) {
+		other := getLocalConnection()
+		defer other.Close()
+		defer FireCommand(conn, "FLUSHDB")
+
+		assert.DeepEqual(t, "OK", FireCommand(conn, "SET select:k db0"))
+		assert.DeepEqual(t, "OK", FireCommand(other, "SELECT 5"))
+		assert.DeepEqual(t, "(nil)", FireCommand(other, "GET select:k"))
+		assert.DeepEqual(t, "OK", FireCommand(other, "SET select:k db5"))
+		assert.DeepEqual(t, "db0", FireCommand(conn, "GET select:k"))
+		assert.DeepEqual(t, "db5", FireCommand(other, "GET select:k"))
+		FireCommand(other, "FLUSHDB")
+	})
+
+	t.Run("SELECT inside MULTI applies to the commands following it", func(t *testing.T) {
+		defer FireCommand(conn, "SELECT 0")
+		defer FireCommand(conn, "FLUSHDB")
+
+		assert.DeepEqual(t, "OK", FireCommand(conn, "MULTI"))
+		assert.DeepEqual(t, "QUEUED", FireCommand(conn, "SET select:txn db0"))
+		assert.DeepEqual(t, "QUEUED", FireCommand(conn, "SELECT 2"))
+		assert.DeepEqual(t, "QUEUED", FireCommand(conn, "SET select:txn db2"))
+		assert.DeepEqual(t, []interface{}{"OK", "OK", "OK"}, FireCommand(conn, "EXEC"))
+		assert.DeepEqual(t, "db2", FireCommand(conn, "GET select:txn"))
+		FireCommand(conn, "FLUSHDB")
+		assert.DeepEqual(t, "OK", FireCommand(conn, "SELECT 0"))
+		assert.DeepEqual(t, "db0", FireCommand(conn, "GET select:txn"))
+	})
+}
+
+func TestSwapDBAndMove(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	other := getLocalConnection()
+	defer other.Close()
+	defer FireCommand(conn, "FLUSHDB")
+
+	assert.DeepEqual(t, "OK", FireCommand(other, "SELECT 3"))
+	defer FireCommand(other, "FLUSHDB")
+
+	FireCommand(conn, "SET swap:k db0")
+	FireCommand(conn, "SET swap:moved v EX 100")
+
+	// MOVE keeps the expiry, and never overwrites a key of the target database
+	assert.DeepEqual(t, int64(1), FireCommand(conn, "MOVE swap:moved 3"))
+	assert.DeepEqual(t, int64(0), FireCommand(conn, "EXISTS swap:moved"))
+	assert.DeepEqual(t, "v", FireCommand(other, "GET swap:moved"))
+	assert.Assert(t, FireCommand(other, "TTL swap:moved").(int64) > 0)
+	assert.DeepEqual(t, int64(0), FireCommand(conn, "MOVE swap:missing 3"))
+	assert.DeepEqual(t, "ERR source and destination objects are the same", FireCommand(conn, "MOVE swap:k 0"))
+
+	// the clients see the keys of the other database once swapped
+	assert.DeepEqual(t, "OK", FireCommand(conn, "SWAPDB 0 3"))
+	assert.DeepEqual(t, "v", FireCommand(conn, "GET swap:moved"))
+	assert.DeepEqual(t, "db0", FireCommand(other, "GET swap:k"))
+	assert.DeepEqual(t, "OK", FireCommand(conn, "SWAPDB 3 0"))
+	assert.DeepEqual(t, "db0", FireCommand(conn, "GET swap:k"))
+
+	assert.DeepEqual(t, "ERR DB index is out of range", FireCommand(conn, "SWAPDB 0 16"))
 }
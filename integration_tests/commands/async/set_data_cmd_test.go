@@ -217,12 +217,49 @@ func TestSetDataCommand(t *testing.T) {
 			assertType: []string{"equal", "equal", "equal"},
 			delay:      []time.Duration{0, 0, 0},
 		},
+		// SADD & SINTERSTORE
+		{
+			name:       "SADD & SINTERSTORE",
+			cmd:        []string{"SADD foo bar baz", "SADD foo2 baz bax", "SINTERSTORE foo3 foo foo2", "SMEMBERS foo3", "TTL foo3"},
+			expected:   []interface{}{int64(2), int64(2), int64(1), []any{"baz"}, int64(-1)},
+			assertType: []string{"equal", "equal", "equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0, 0, 0},
+		},
+		{
+			name:       "SADD & SINTERSTORE with empty intersection deletes destination",
+			cmd:        []string{"SADD foo3 bar", "SADD foo bar", "SINTERSTORE foo3 foo foo2", "EXISTS foo3"},
+			expected:   []interface{}{int64(1), int64(1), int64(0), int64(0)},
+			assertType: []string{"equal", "equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0, 0},
+		},
+		{
+			name:       "SADD & SINTERSTORE with EX",
+			cmd:        []string{"SADD foo bar baz", "SADD foo2 baz", "SINTERSTORE foo3 foo foo2 TTL EX 10", "TTL foo3"},
+			expected:   []interface{}{int64(2), int64(1), int64(1), int64(10)},
+			assertType: []string{"equal", "equal", "equal", "assert"},
+			delay:      []time.Duration{0, 0, 0, 0},
+		},
+		{
+			name:       "SADD & SINTERSTORE with INHERITTTL",
+			cmd:        []string{"SADD foo bar baz", "SADD foo2 baz", "EXPIRE foo2 5", "SINTERSTORE foo3 foo foo2 TTL INHERITTTL", "TTL foo3"},
+			expected:   []interface{}{int64(2), int64(1), int64(1), int64(1), int64(5)},
+			assertType: []string{"equal", "equal", "equal", "equal", "assert"},
+			delay:      []time.Duration{0, 0, 0, 0, 0},
+		},
+		{
+			name:       "SADD & SINTERSTORE with both EX and INHERITTTL",
+			cmd:        []string{"SADD foo bar", "SINTERSTORE foo3 foo TTL EX 10 INHERITTTL"},
+			expected:   []interface{}{int64(1), "ERR syntax error"},
+			assertType: []string{"equal", "equal"},
+			delay:      []time.Duration{0, 0},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			FireCommand(conn, "DEL foo")
 			FireCommand(conn, "DEL foo2")
+			FireCommand(conn, "DEL foo3")
 			for i, cmd := range tc.cmd {
 				if tc.delay[i] > 0 {
 					time.Sleep(tc.delay[i])
@@ -0,0 +1,70 @@
+package async
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestStreamConsumerGroups(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "DEL mystream")
+	defer FireCommand(conn, "DEL mystream")
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+	}{
+		{
+			name:     "XGROUP CREATE with MKSTREAM",
+			commands: []string{"XGROUP CREATE mystream workers $ MKSTREAM", "XGROUP CREATE mystream workers $"},
+			expected: []interface{}{"OK", "BUSYGROUP Consumer Group name already exists"},
+		},
+		{
+			name: "XREADGROUP delivers each entry once",
+			commands: []string{
+				"XADD mystream 1-1 job a",
+				"XADD mystream 1-2 job b",
+				"XREADGROUP GROUP workers alice COUNT 1 STREAMS mystream >",
+				"XREADGROUP GROUP workers bob STREAMS mystream >",
+				"XREADGROUP GROUP workers bob STREAMS mystream >",
+			},
+			expected: []interface{}{
+				"1-1",
+				"1-2",
+				[]interface{}{[]interface{}{"mystream", []interface{}{[]interface{}{"1-1", []interface{}{"job", "a"}}}}},
+				[]interface{}{[]interface{}{"mystream", []interface{}{[]interface{}{"1-2", []interface{}{"job", "b"}}}}},
+				"(nil)",
+			},
+		},
+		{
+			name:     "XPENDING summary",
+			commands: []string{"XPENDING mystream workers"},
+			expected: []interface{}{
+				[]interface{}{int64(2), "1-1", "1-2", []interface{}{[]interface{}{"alice", "1"}, []interface{}{"bob", "1"}}},
+			},
+		},
+		{
+			name:     "XCLAIM and XACK",
+			commands: []string{"XCLAIM mystream workers bob 0 1-1 JUSTID", "XACK mystream workers 1-1 1-2", "XPENDING mystream workers"},
+			expected: []interface{}{[]interface{}{"1-1"}, int64(2), []interface{}{int64(0), "(nil)", "(nil)", "(nil)"}},
+		},
+		{
+			name:     "XAUTOCLAIM with nothing pending",
+			commands: []string{"XAUTOCLAIM mystream workers bob 0 0"},
+			expected: []interface{}{[]interface{}{"0-0", []interface{}{}, []interface{}{}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.DeepEqual(t, tc.expected[i], result)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package async
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWatch(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	other := getLocalConnection()
+	defer other.Close()
+
+	testCases := []struct {
+		name   string
+		cmds   []string
+		others map[int]string // others are the commands of another client, run before the command of the same index
+		expect []interface{}
+	}{
+		{
+			name:   "Runs the transaction if no key watched was modified",
+			cmds:   []string{"WATCH k1", "SET k2 v2", "MULTI", "SET k1 v1", "GET k2", "EXEC"},
+			expect: []interface{}{"OK", "OK", "OK", "QUEUED", "QUEUED", []interface{}{"OK", "v2"}},
+		},
+		{
+			name:   "Aborts the transaction if the client modified a key watched itself",
+			cmds:   []string{"WATCH k1 k2", "SET k2 v2", "MULTI", "SET k1 v1", "EXEC", "GET k1"},
+			expect: []interface{}{"OK", "OK", "OK", "QUEUED", "(nil)", "(nil)"},
+		},
+		{
+			name:   "Aborts the transaction if a key watched was modified by another client",
+			cmds:   []string{"WATCH k1", "MULTI", "SET k1 v1", "EXEC", "GET k1"},
+			others: map[int]string{3: "SET k1 other"},
+			expect: []interface{}{"OK", "OK", "QUEUED", "(nil)", "other"},
+		},
+		{
+			name:   "Unwatches the keys once the transaction ran",
+			cmds:   []string{"WATCH k1", "MULTI", "SET k1 v1", "EXEC", "MULTI", "SET k1 v2", "EXEC"},
+			others: map[int]string{1: "SET k2 v2", 4: "SET k1 other"},
+			expect: []interface{}{"OK", "OK", "QUEUED", []interface{}{"OK"}, "OK", "QUEUED", []interface{}{"OK"}},
+		},
+		{
+			name:   "Unwatches the keys with UNWATCH and DISCARD",
+			cmds:   []string{"WATCH k1", "UNWATCH", "WATCH k2", "MULTI", "DISCARD", "MULTI", "SET k1 v1", "EXEC"},
+			others: map[int]string{2: "SET k1 other", 5: "SET k2 other"},
+			expect: []interface{}{"OK", "OK", "OK", "OK", "OK", "OK", "QUEUED", []interface{}{"OK"}},
+		},
+		{
+			name:   "Aborts the transaction if a key watched was deleted",
+			cmds:   []string{"SET k1 v1", "WATCH k1", "MULTI", "GET k1", "EXEC"},
+			others: map[int]string{4: "DEL k1"},
+			expect: []interface{}{"OK", "OK", "OK", "QUEUED", "(nil)"},
+		},
+		{
+			name:   "Does not allow WATCH inside MULTI",
+			cmds:   []string{"MULTI", "WATCH k1", "SET k1 v1", "EXEC"},
+			expect: []interface{}{"OK", "ERR WATCH inside MULTI is not allowed", "QUEUED", []interface{}{"OK"}},
+		},
+		{
+			name: "Does not allow the commands run by the server inside MULTI",
+			cmds: []string{"MULTI", "ROLE", "CLIENT LIST", "REPLICAOF NO ONE", "SET k1 v1", "EXEC"},
+			expect: []interface{}{
+				"OK", "ERR ROLE inside MULTI is not allowed", "ERR CLIENT inside MULTI is not allowed",
+				"ERR REPLICAOF inside MULTI is not allowed", "QUEUED", []interface{}{"OK"},
+			},
+		},
+		{
+			name:   "Checks the arity",
+			cmds:   []string{"WATCH", "UNWATCH k1"},
+			expect: []interface{}{"ERR wrong number of arguments for 'watch' command", "ERR wrong number of arguments for 'unwatch' command"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			FireCommand(conn, "DEL k1 k2")
+			for i, cmd := range tc.cmds {
+				if oc, ok := tc.others[i]; ok {
+					FireCommand(other, oc)
+				}
+				result := FireCommand(conn, cmd)
+				assert.DeepEqual(t, tc.expect[i], result)
+			}
+		})
+	}
+}
@@ -117,3 +117,99 @@ func TestZRANGE(t *testing.T) {
 		})
 	}
 }
+
+func TestZINCRBY(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "DEL key")
+	defer FireCommand(conn, "DEL key")
+
+	testCases := []TestCase{
+		{
+			name:     "ZINCRBY on new member",
+			commands: []string{"ZINCRBY key 2.5 member1", "ZRANGE key 0 -1 WITHSCORES"},
+			expected: []interface{}{"2.5", []interface{}{"member1", "2.5"}},
+		},
+		{
+			name:     "ZINCRBY on existing member",
+			commands: []string{"ZINCRBY key -1 member1", "ZADD key INCR 1 member1"},
+			expected: []interface{}{"1.5", "2.5"},
+		},
+		{
+			name:     "ZINCRBY resulting in NaN",
+			commands: []string{"ZADD key +inf member2", "ZINCRBY key -inf member2", "ZADD key INCR -inf member2"},
+			expected: []interface{}{int64(1), "ERR resulting score is not a number (NaN)", "ERR resulting score is not a number (NaN)"},
+		},
+		{
+			name:     "ZINCRBY overflowing the score",
+			commands: []string{"ZADD key 1.7e308 member3", "ZINCRBY key 1.7e308 member3", "ZRANGE key -1 -1 WITHSCORES"},
+			expected: []interface{}{int64(1), "ERR resulting score is not a number (NaN)", []interface{}{"member2", "+inf"}},
+		},
+		{
+			name:     "ZADD INCR with XX on new member",
+			commands: []string{"ZADD key XX INCR 1 member4"},
+			expected: []interface{}{"(nil)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.DeepEqual(t, tc.expected[i], result)
+			}
+		})
+	}
+}
+
+func TestZRANGEBYLEX(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "DEL key")
+	FireCommand(conn, "ZADD key 0 apple 0 banana 0 cherry 0 date 0 elderberry")
+	defer FireCommand(conn, "DEL key")
+
+	testCases := []TestCase{
+		{
+			name:     "ZRANGEBYLEX over all the members",
+			commands: []string{"ZRANGEBYLEX key - +"},
+			expected: []interface{}{[]interface{}{"apple", "banana", "cherry", "date", "elderberry"}},
+		},
+		{
+			name:     "ZRANGEBYLEX with inclusive and exclusive bounds",
+			commands: []string{"ZRANGEBYLEX key [b (d"},
+			expected: []interface{}{[]interface{}{"banana", "cherry"}},
+		},
+		{
+			name:     "ZRANGEBYLEX with LIMIT",
+			commands: []string{"ZRANGEBYLEX key - + LIMIT 1 2"},
+			expected: []interface{}{[]interface{}{"banana", "cherry"}},
+		},
+		{
+			name:     "ZREVRANGEBYLEX with bounds",
+			commands: []string{"ZREVRANGEBYLEX key [date (apple"},
+			expected: []interface{}{[]interface{}{"date", "cherry", "banana"}},
+		},
+		{
+			name:     "ZLEXCOUNT with bounds",
+			commands: []string{"ZLEXCOUNT key (apple [date", "ZLEXCOUNT key - +"},
+			expected: []interface{}{int64(3), int64(5)},
+		},
+		{
+			name:     "ZRANGEBYLEX with an invalid bound",
+			commands: []string{"ZRANGEBYLEX key apple +"},
+			expected: []interface{}{"ERR min or max not valid string range item"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.DeepEqual(t, tc.expected[i], result)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package async
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestZWATCH(t *testing.T) {
+	publisher := getLocalConnection()
+	subscriber := getLocalConnection()
+	defer publisher.Close()
+	defer subscriber.Close()
+
+	FireCommand(publisher, "DEL zwatch:board")
+	defer FireCommand(publisher, "DEL zwatch:board")
+	FireCommand(publisher, "ZADD zwatch:board 10 alice 20 bob")
+
+	rp := fireCommandAndGetRESPParser(subscriber, "ZWATCH zwatch:board")
+	assert.Assert(t, rp != nil)
+	v, err := rp.DecodeOne()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []interface{}{"ZWATCH", "zwatch:board", []interface{}{"alice", "10", "bob", "20"}}, v)
+
+	steps := []struct {
+		cmd    string
+		deltas []interface{}
+	}{
+		{"ZINCRBY zwatch:board 15 alice", []interface{}{[]interface{}{"alice", "10", "25", int64(1)}}},
+		{"ZADD zwatch:board 5 carol", []interface{}{[]interface{}{"carol", "(nil)", "5", "(nil)"}}},
+		{"ZREM zwatch:board bob", []interface{}{[]interface{}{"bob", "20", "(nil)", "(nil)"}}},
+	}
+	for _, step := range steps {
+		FireCommand(publisher, step.cmd)
+		v, err := rp.DecodeOne()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []interface{}{"ZDELTA", "zwatch:board", step.deltas}, v)
+	}
+
+	FireCommand(subscriber, "ZUNWATCH zwatch:board")
+	time.Sleep(100 * time.Millisecond)
+}
@@ -26,7 +26,7 @@ func TestSELECT(t *testing.T) {
 			commands: []HTTPCommand{
 				{Command: "SELECT", Body: map[string]interface{}{"value": ""}},
 			},
-			expected: []interface{}{"OK"},
+			expected: []interface{}{"ERR value is not an integer or out of range"},
 		},
 	}
 	for _, tc := range testCases {
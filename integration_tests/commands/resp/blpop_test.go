@@ -0,0 +1,38 @@
+package resp
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBLPOP(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	pusher := getLocalConnection()
+	defer pusher.Close()
+
+	FireCommand(conn, "DEL blpop:list")
+	defer FireCommand(conn, "DEL blpop:list")
+
+	t.Run("served right away", func(t *testing.T) {
+		FireCommand(pusher, "RPUSH blpop:list a b")
+		assert.DeepEqual(t, []interface{}{"blpop:list", "a"}, FireCommand(conn, "BLPOP blpop:other blpop:list 0"))
+		assert.DeepEqual(t, []interface{}{"blpop:list", "b"}, FireCommand(conn, "BRPOP blpop:list 0"))
+	})
+
+	t.Run("served by a push", func(t *testing.T) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			FireCommand(pusher, "RPUSH blpop:list c")
+		}()
+		assert.DeepEqual(t, []interface{}{"blpop:list", "c"}, FireCommand(conn, "BLPOP blpop:list 5"))
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		start := time.Now()
+		assert.Equal(t, "(nil)", FireCommand(conn, "BLPOP blpop:list 0.2"))
+		assert.Assert(t, time.Since(start) >= 200*time.Millisecond)
+	})
+}
@@ -0,0 +1,67 @@
+package resp
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBZPOPMIN(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	pusher := getLocalConnection()
+	defer pusher.Close()
+
+	FireCommand(conn, "DEL bzpop:zset")
+	defer FireCommand(conn, "DEL bzpop:zset")
+
+	t.Run("served right away", func(t *testing.T) {
+		FireCommand(pusher, "ZADD bzpop:zset 1 a 2 b")
+		assert.DeepEqual(t, []interface{}{"bzpop:zset", "a", "1"}, FireCommand(conn, "BZPOPMIN bzpop:other bzpop:zset 0"))
+		assert.DeepEqual(t, []interface{}{"bzpop:zset", "b", "2"}, FireCommand(conn, "BZPOPMAX bzpop:zset 0"))
+	})
+
+	t.Run("served by an addition", func(t *testing.T) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			FireCommand(pusher, "ZADD bzpop:zset 3 c")
+		}()
+		assert.DeepEqual(t, []interface{}{"bzpop:zset", "c", "3"}, FireCommand(conn, "BZPOPMIN bzpop:zset 5"))
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		assert.Equal(t, "(nil)", FireCommand(conn, "BZPOPMAX bzpop:zset 0.2"))
+	})
+}
+
+func TestBZMPOP(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	pusher := getLocalConnection()
+	defer pusher.Close()
+
+	FireCommand(conn, "DEL bzmpop:zset")
+	defer FireCommand(conn, "DEL bzmpop:zset")
+
+	t.Run("served right away", func(t *testing.T) {
+		FireCommand(pusher, "ZADD bzmpop:zset 1 a 2 b 3 c")
+		assert.DeepEqual(t, []interface{}{"bzmpop:zset", []interface{}{[]interface{}{"a", "1"}}},
+			FireCommand(conn, "ZMPOP 1 bzmpop:zset MIN"))
+		assert.DeepEqual(t, []interface{}{"bzmpop:zset", []interface{}{[]interface{}{"c", "3"}, []interface{}{"b", "2"}}},
+			FireCommand(conn, "BZMPOP 0 1 bzmpop:zset MAX COUNT 2"))
+	})
+
+	t.Run("served by an addition", func(t *testing.T) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			FireCommand(pusher, "ZADD bzmpop:zset 4 d")
+		}()
+		assert.DeepEqual(t, []interface{}{"bzmpop:zset", []interface{}{[]interface{}{"d", "4"}}},
+			FireCommand(conn, "BZMPOP 5 1 bzmpop:zset MIN"))
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		assert.Equal(t, "(nil)", FireCommand(conn, "BZMPOP 0.2 1 bzmpop:zset MAX"))
+	})
+}
@@ -0,0 +1,55 @@
+package resp
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestShardedCounter(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	defer FireCommand(conn, "DEL views#0 views#1 views#2 views#3")
+
+	testCases := []struct {
+		name   string
+		cmds   []string
+		expect []interface{}
+	}{
+		{
+			name:   "COUNTER.GET on a missing counter",
+			cmds:   []string{"COUNTER.GET views 4"},
+			expect: []interface{}{int64(0)},
+		},
+		{
+			name:   "COUNTER.INCR spread over the sub-counters",
+			cmds:   []string{"COUNTER.INCR views 4", "COUNTER.INCR views 4", "COUNTER.INCR views 4 10", "COUNTER.GET views 4"},
+			expect: []interface{}{nil, nil, nil, int64(12)},
+		},
+		{
+			name:   "COUNTER.INCR with an invalid number of shards",
+			cmds:   []string{"COUNTER.INCR views 0"},
+			expect: []interface{}{"ERR shards must be between 1 and 128"},
+		},
+		{
+			name:   "COUNTER.GET with a sub-counter holding the wrong kind of value",
+			cmds:   []string{"SET other#1 abc", "COUNTER.GET other 2", "DEL other#1"},
+			expect: []interface{}{"OK", "ERR value is not an integer or out of range", int64(1)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.cmds {
+				result := FireCommand(conn, cmd)
+				if tc.expect[i] == nil {
+					// the value of the sub-counter incremented
+					_, ok := result.(int64)
+					assert.Assert(t, ok, "unexpected result %v for cmd %s", result, cmd)
+					continue
+				}
+				assert.Equal(t, tc.expect[i], result, "Value mismatch for cmd %s", cmd)
+			}
+		})
+	}
+}
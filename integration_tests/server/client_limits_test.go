@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	commands "github.com/dicedb/dice/integration_tests/commands/async"
+	"github.com/dicedb/dice/internal/clientio"
+
+	"github.com/dicedb/dice/config"
+)
+
+func TestClientLimitsAndIdleTimeout(t *testing.T) {
+	defer func(maxPerIP int32, idleTimeout time.Duration) {
+		config.DiceConfig.Server.MaxClientsPerIP = maxPerIP
+		config.DiceConfig.Server.IdleTimeout = idleTimeout
+	}(config.DiceConfig.Server.MaxClientsPerIP, config.DiceConfig.Server.IdleTimeout)
+	config.DiceConfig.Server.MaxClientsPerIP = 2
+	config.DiceConfig.Server.IdleTimeout = time.Second
+
+	var wg sync.WaitGroup
+	opts := commands.TestServerOptions{
+		Port:   8742,
+		Logger: slog.Default(),
+	}
+	commands.RunTestServer(context.Background(), &wg, opts)
+
+	time.Sleep(2 * time.Second)
+
+	active, err := getConnection(opts.Port)
+	assert.NilError(t, err)
+	defer active.Close()
+	assert.Equal(t, "PONG", commands.FireCommand(active, "PING"))
+
+	idle, err := getConnection(opts.Port)
+	assert.NilError(t, err)
+	defer idle.Close()
+	assert.Equal(t, "PONG", commands.FireCommand(idle, "PING"))
+
+	t.Run("per IP limit", func(t *testing.T) {
+		rejected, err := getConnection(opts.Port)
+		assert.NilError(t, err)
+		defer rejected.Close()
+
+		assert.NilError(t, rejected.SetReadDeadline(time.Now().Add(5*time.Second)))
+		v, err := clientio.NewRESPParser(rejected).DecodeOne()
+		assert.NilError(t, err)
+		assert.Equal(t, "ERR max number of clients per IP reached", v)
+	})
+
+	t.Run("CLIENT LIST", func(t *testing.T) {
+		list, ok := commands.FireCommand(active, "CLIENT LIST").(string)
+		assert.Assert(t, ok)
+
+		lines := strings.Split(strings.TrimSpace(list), "\n")
+		assert.Equal(t, 2, len(lines))
+		for _, line := range lines {
+			assert.Assert(t, strings.Contains(line, "addr=127.0.0.1:"), line)
+			assert.Assert(t, strings.Contains(line, " idle="), line)
+		}
+	})
+
+	t.Run("idle clients are disconnected", func(t *testing.T) {
+		// keep one connection active while the other one stays idle
+		for i := 0; i < 4; i++ {
+			time.Sleep(500 * time.Millisecond)
+			assert.Equal(t, "PONG", commands.FireCommand(active, "PING"))
+		}
+
+		assert.NilError(t, idle.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, err := idle.Read(make([]byte, 1))
+		assert.Assert(t, err != nil && !errors.Is(err, os.ErrDeadlineExceeded), err)
+
+		// the slot of the idle client is released
+		conn, err := getConnection(opts.Port)
+		assert.NilError(t, err)
+		defer conn.Close()
+		assert.Equal(t, "PONG", commands.FireCommand(conn, "PING"))
+	})
+
+	assert.Equal(t, "OK", commands.FireCommand(active, "ABORT"))
+	wg.Wait()
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+
+	"github.com/dicedb/dice/config"
+	commands "github.com/dicedb/dice/integration_tests/commands/async"
+)
+
+func TestReplication(t *testing.T) {
+	var wg sync.WaitGroup
+	primaryOpts := commands.TestServerOptions{Port: 8743, Logger: slog.Default()}
+	commands.RunTestServer(context.Background(), &wg, primaryOpts)
+	time.Sleep(2 * time.Second)
+
+	replicaOpts := commands.TestServerOptions{Port: 8744, Logger: slog.Default()}
+	commands.RunTestServer(context.Background(), &wg, replicaOpts)
+	time.Sleep(2 * time.Second)
+
+	primary, err := getConnection(primaryOpts.Port)
+	assert.NilError(t, err)
+	defer primary.Close()
+	replica, err := getConnection(replicaOpts.Port)
+	assert.NilError(t, err)
+	defer replica.Close()
+
+	// data written before the replica attaches travels in the snapshot
+	assert.Equal(t, "OK", commands.FireCommand(primary, "SET k1 v1"))
+	assert.Equal(t, "OK", commands.FireCommand(primary, "RPUSH l1 a b c"))
+	assert.Equal(t, int64(2), commands.FireCommand(primary, "ZADD z1 1 a 2 b"))
+	assert.Equal(t, "OK", commands.FireCommand(replica, "SET stale v"))
+
+	assert.Equal(t, "OK", commands.FireCommand(replica, fmt.Sprintf("REPLICAOF localhost %d", primaryOpts.Port)))
+	poll.WaitOn(t, replicaState(replica, "connected"), poll.WithTimeout(10*time.Second))
+
+	t.Run("snapshot", func(t *testing.T) {
+		assert.Equal(t, "v1", commands.FireCommand(replica, "GET k1"))
+		assert.Equal(t, int64(3), commands.FireCommand(replica, "LLEN l1"))
+		assert.DeepEqual(t, []interface{}{"a", "1", "b", "2"}, commands.FireCommand(replica, "ZRANGE z1 0 -1 WITHSCORES"))
+		assert.Equal(t, "(nil)", commands.FireCommand(replica, "GET stale"))
+	})
+
+	t.Run("replication stream", func(t *testing.T) {
+		assert.Equal(t, "OK", commands.FireCommand(primary, "SET k2 v2"))
+		assert.Equal(t, int64(1), commands.FireCommand(primary, "DEL k1"))
+		assert.Equal(t, "c", commands.FireCommand(primary, "RPOP l1"))
+		assert.Equal(t, "(nil)", commands.FireCommand(primary, "GET unknown"))
+
+		// the commands are applied in order, RPOP is the last one
+		poll.WaitOn(t, func(poll.LogT) poll.Result {
+			if commands.FireCommand(replica, "LLEN l1") != int64(2) {
+				return poll.Continue("RPOP not replicated yet")
+			}
+			return poll.Success()
+		}, poll.WithTimeout(5*time.Second))
+		assert.Equal(t, "v2", commands.FireCommand(replica, "GET k2"))
+		assert.Equal(t, "(nil)", commands.FireCommand(replica, "GET k1"))
+	})
+
+	t.Run("expiration", func(t *testing.T) {
+		assert.Equal(t, "OK", commands.FireCommand(primary, "SET k5 v5 PX 500"))
+		poll.WaitOn(t, func(poll.LogT) poll.Result {
+			if commands.FireCommand(replica, "GET k5") != "v5" {
+				return poll.Continue("k5 not replicated yet")
+			}
+			return poll.Success()
+		}, poll.WithTimeout(5*time.Second))
+
+		// the replica serves the key as missing once expired, and deletes it
+		// along with the primary
+		time.Sleep(time.Second)
+		assert.Equal(t, "(nil)", commands.FireCommand(replica, "GET k5"))
+		assert.Equal(t, "(nil)", commands.FireCommand(primary, "GET k5"))
+	})
+
+	t.Run("default TTLs", func(t *testing.T) {
+		defer commands.FireCommand(primary, "CONFIG DEFAULTTTL DEL policy:*")
+
+		assert.Equal(t, "OK", commands.FireCommand(primary, "CONFIG DEFAULTTTL SET policy:* 1h"))
+		assert.Equal(t, "OK", commands.FireCommand(primary, "SET policy:k v"))
+		poll.WaitOn(t, func(poll.LogT) poll.Result {
+			if ttl, _ := commands.FireCommand(replica, "TTL policy:k").(int64); ttl <= 0 {
+				return poll.Continue("the expiry of policy:k is not replicated yet")
+			}
+			return poll.Success()
+		}, poll.WithTimeout(5*time.Second))
+
+		// the replica gets the expiry given by the primary
+		assert.Equal(t, commands.FireCommand(primary, "PEXPIRETIME policy:k"), commands.FireCommand(replica, "PEXPIRETIME policy:k"))
+		assert.Assert(t, commands.FireCommand(replica, "TTL policy:k").(int64) > 3500)
+	})
+
+	t.Run("ROLE", func(t *testing.T) {
+		role := commands.FireCommand(primary, "ROLE").([]interface{})
+		assert.Equal(t, "master", role[0])
+		offset := role[1].(int64)
+		assert.Assert(t, offset > 0)
+
+		replicas := role[2].([]interface{})
+		assert.Equal(t, 1, len(replicas))
+		assert.Equal(t, fmt.Sprint(replicaOpts.Port), replicas[0].([]interface{})[1])
+
+		role = commands.FireCommand(replica, "ROLE").([]interface{})
+		assert.DeepEqual(t, []interface{}{"slave", "localhost", int64(primaryOpts.Port), "connected"}, role[:4])
+		assert.Equal(t, offset, role[4])
+	})
+
+	t.Run("session tokens", func(t *testing.T) {
+		defer func() { config.DiceConfig.Server.ReplicaTokenTimeout = time.Second }()
+
+		assert.Equal(t, "OK", commands.FireCommand(primary, "SET session:k v1"))
+		first := commands.FireCommand(primary, "CLIENT TOKEN").(int64)
+		assert.Equal(t, "OK", commands.FireCommand(primary, "SET session:k v2"))
+		token := commands.FireCommand(primary, "CLIENT TOKEN").(int64)
+		assert.Assert(t, token > first)
+
+		// the replica waits for the write before serving the read
+		assert.Equal(t, "OK", commands.FireCommand(replica, fmt.Sprintf("CLIENT READAFTER %d", token)))
+		assert.Equal(t, "v2", commands.FireCommand(replica, "GET session:k"))
+
+		// a token the replica does not catch up with in time fails the reads
+		config.DiceConfig.Server.ReplicaTokenTimeout = 200 * time.Millisecond
+		assert.Equal(t, "OK", commands.FireCommand(replica, fmt.Sprintf("CLIENT READAFTER %d", token+1<<40)))
+		assert.Equal(t, "TRYAGAIN The replica has not caught up with the session token in time, retry or read from the primary instead.",
+			commands.FireCommand(replica, "GET session:k"))
+		assert.Equal(t, "OK", commands.FireCommand(replica, "CLIENT READAFTER 0"))
+		assert.Equal(t, "v2", commands.FireCommand(replica, "GET session:k"))
+
+		assert.Equal(t, "ERR value is not an integer or out of range", commands.FireCommand(replica, "CLIENT READAFTER -1"))
+	})
+
+	t.Run("replicas refuse writes", func(t *testing.T) {
+		assert.Equal(t, "READONLY You can't write against a read only replica.", commands.FireCommand(replica, "SET k3 v3"))
+		assert.Equal(t, "v2", commands.FireCommand(replica, "GET k2"))
+	})
+
+	t.Run("replicas refuse reads once too stale", func(t *testing.T) {
+		defer func() { config.DiceConfig.Server.ReplicaMaxStaleness = 0 }()
+
+		config.DiceConfig.Server.ReplicaMaxStaleness = time.Hour
+		assert.Equal(t, "v2", commands.FireCommand(replica, "GET k2"))
+
+		config.DiceConfig.Server.ReplicaMaxStaleness = time.Nanosecond
+		assert.Equal(t, "STALE The replica has not heard from its primary within the max staleness, read from the primary instead.",
+			commands.FireCommand(replica, "GET k2"))
+		assert.Equal(t, "v2", commands.FireCommand(primary, "GET k2"))
+		assert.Equal(t, "PONG", commands.FireCommand(replica, "PING"))
+	})
+
+	t.Run("REPLICAOF NO ONE", func(t *testing.T) {
+		assert.Equal(t, "OK", commands.FireCommand(replica, "REPLICAOF NO ONE"))
+		assert.Equal(t, "master", commands.FireCommand(replica, "ROLE").([]interface{})[0])
+		assert.Equal(t, "OK", commands.FireCommand(replica, "SET k3 v3"))
+
+		assert.Equal(t, "OK", commands.FireCommand(primary, "SET k4 v4"))
+		time.Sleep(500 * time.Millisecond)
+		assert.Equal(t, "(nil)", commands.FireCommand(replica, "GET k4"))
+	})
+
+	assert.Equal(t, "OK", commands.FireCommand(replica, "ABORT"))
+	assert.Equal(t, "OK", commands.FireCommand(primary, "ABORT"))
+	wg.Wait()
+}
+
+func replicaState(conn net.Conn, state string) poll.Check {
+	return func(poll.LogT) poll.Result {
+		role, ok := commands.FireCommand(conn, "ROLE").([]interface{})
+		if !ok || len(role) < 4 || role[3] != state {
+			return poll.Continue("replica is not %s yet: %v", state, role)
+		}
+		return poll.Success()
+	}
+}
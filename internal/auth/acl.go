@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/server/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// RevokeKill closes the connections whose user lost access.
+	RevokeKill = "kill"
+	// RevokeDowngrade sends the connections whose user lost access back to the
+	// unauthenticated state, the clients having to AUTH again.
+	RevokeDowngrade = "downgrade"
+)
+
+// RevokePolicy returns the configured revoke policy, RevokeKill unless
+// RevokeDowngrade is set, the connections of a user losing access never being
+// left as they are.
+func RevokePolicy() string {
+	if strings.EqualFold(config.Current().Auth.RevokePolicy, RevokeDowngrade) {
+		return RevokeDowngrade
+	}
+	return RevokeKill
+}
+
+// SetUser creates the user username if needed and applies the ACL rules to it,
+// in order. The rules are:
+//
+//	on, off             enables or disables the user
+//	>password           adds a password
+//	<password           removes a password
+//	#hash               adds the bcrypt hash of a password
+//	nopass              accepts any password, dropping the passwords
+//	resetpass           drops the passwords and nopass
+//	allcommands, +@all  allows all the commands
+//	nocommands, -@all   denies all the commands
+//	+command, -command  allows or denies a command
+//	reset               disables the user, drops its passwords and denies all
+//	                    the commands
+//
+// Either all the rules apply or none, the user being left as is. The users
+// are versioned, for the sessions to be re-evaluated once they changed.
+func (users *Users) SetUser(username string, rules ...string) error {
+	users.stLock.Lock()
+	defer users.stLock.Unlock()
+
+	user := &User{Username: username}
+	if current, ok := users.store[username]; ok {
+		user = current.clone()
+	} else {
+		// a new user is disabled and may run no command till allowed to
+		user.Disabled, user.Restricted = true, true
+	}
+	for _, rule := range rules {
+		if err := user.applyRule(rule); err != nil {
+			return err
+		}
+	}
+
+	// the user is replaced rather than modified, as the sessions read it
+	// without holding the lock
+	users.store[username] = user
+	users.version.Add(1)
+	return nil
+}
+
+// SetupDefault adds the default user username, authenticated by password, or
+// by any password if password is empty, see nopass.
+func (users *Users) SetupDefault(username, password string) error {
+	user, err := users.Add(username)
+	if err != nil {
+		return err
+	}
+	if password == utils.EmptyStr {
+		slog.Warn("DiceDB is running without authentication. Consider setting a password.")
+		user.NoPass = true
+		return nil
+	}
+	return user.SetPassword(password)
+}
+
+// Delete deletes the users and returns the number of users deleted.
+func (users *Users) Delete(usernames ...string) int {
+	users.stLock.Lock()
+	defer users.stLock.Unlock()
+
+	deleted := 0
+	for _, username := range usernames {
+		if _, ok := users.store[username]; ok {
+			delete(users.store, username)
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		users.version.Add(1)
+	}
+	return deleted
+}
+
+// Names returns the names of the users, sorted.
+func (users *Users) Names() []string {
+	users.stLock.RLock()
+	defer users.stLock.RUnlock()
+
+	names := make([]string, 0, len(users.store))
+	for name := range users.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// List describes the users, sorted by name, with their rules.
+func (users *Users) List() []string {
+	users.stLock.RLock()
+	defer users.stLock.RUnlock()
+
+	list := make([]string, 0, len(users.store))
+	for _, user := range users.store {
+		list = append(list, "user "+user.Username+" "+user.Rules())
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Version returns the version of the users, increased at every change.
+func (users *Users) Version() uint64 {
+	return users.version.Load()
+}
+
+// CanRun returns true if the user may run the command cmd.
+func (user *User) CanRun(cmd string) bool {
+	if allowed, ok := user.Commands[cmd]; ok {
+		return allowed
+	}
+	return !user.Restricted
+}
+
+// Rules returns the rules describing the user, as listed by ACL LIST.
+func (user *User) Rules() string {
+	rules := []string{"on"}
+	if user.Disabled {
+		rules[0] = "off"
+	}
+	if user.NoPass {
+		rules = append(rules, "nopass")
+	}
+	for _, password := range user.Passwords {
+		rules = append(rules, "#"+password)
+	}
+	if user.Restricted {
+		rules = append(rules, "-@all")
+	} else {
+		rules = append(rules, "+@all")
+	}
+
+	cmds := make([]string, 0, len(user.Commands))
+	for cmd := range user.Commands {
+		cmds = append(cmds, cmd)
+	}
+	sort.Strings(cmds)
+	for _, cmd := range cmds {
+		if user.Commands[cmd] {
+			rules = append(rules, "+"+strings.ToLower(cmd))
+		} else {
+			rules = append(rules, "-"+strings.ToLower(cmd))
+		}
+	}
+	return strings.Join(rules, " ")
+}
+
+func (user *User) applyRule(rule string) error {
+	switch lower := strings.ToLower(rule); {
+	case lower == "on":
+		user.Disabled = false
+	case lower == "off":
+		user.Disabled = true
+	case lower == "nopass":
+		user.NoPass, user.Passwords = true, nil
+	case lower == "resetpass":
+		user.NoPass, user.Passwords = false, nil
+	case lower == "allcommands" || lower == "+@all":
+		user.Restricted, user.Commands = false, nil
+	case lower == "nocommands" || lower == "-@all":
+		user.Restricted, user.Commands = true, nil
+	case lower == "reset":
+		*user = User{Username: user.Username, Disabled: true, Restricted: true}
+	case strings.HasPrefix(rule, ">"):
+		if err := user.SetPassword(rule[1:]); err != nil {
+			return err
+		}
+		user.NoPass = false
+	case strings.HasPrefix(rule, "<"):
+		passwords := user.Passwords[:0:0]
+		for _, hash := range user.Passwords {
+			if bcrypt.CompareHashAndPassword([]byte(hash), []byte(rule[1:])) != nil {
+				passwords = append(passwords, hash)
+			}
+		}
+		if len(passwords) == len(user.Passwords) {
+			return fmt.Errorf("ERR Error in ACL SETUSER modifier '<...>': no such password")
+		}
+		user.Passwords = passwords
+	case strings.HasPrefix(rule, "#"):
+		if _, err := bcrypt.Cost([]byte(rule[1:])); err != nil {
+			return fmt.Errorf("ERR Error in ACL SETUSER modifier '#...': the password hash must be a bcrypt hash")
+		}
+		user.Passwords = append(user.Passwords, rule[1:])
+		user.NoPass = false
+	case len(rule) > 1 && (rule[0] == '+' || rule[0] == '-') && rule[1] != '@':
+		if user.Commands == nil {
+			user.Commands = make(map[string]bool)
+		}
+		user.Commands[strings.ToUpper(rule[1:])] = rule[0] == '+'
+	default:
+		return fmt.Errorf("ERR Error in ACL SETUSER modifier '%s': Syntax error", rule)
+	}
+	return nil
+}
+
+func (user *User) clone() *User {
+	c := *user
+	c.Passwords = append([]string(nil), user.Passwords...)
+	if user.Commands != nil {
+		c.Commands = make(map[string]bool, len(user.Commands))
+		for cmd, allowed := range user.Commands {
+			c.Commands[cmd] = allowed
+		}
+	}
+	return &c
+}
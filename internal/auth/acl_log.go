@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// LogReasonCommand is the reason of the entries of the commands denied.
+	LogReasonCommand = "command"
+
+	// aclLogMaxLen is the number of entries kept by the ACL log.
+	aclLogMaxLen = 128
+	// aclLogGroupWindow is the time during which the same denial is counted
+	// in the same entry rather than in a new one.
+	aclLogGroupWindow = time.Minute
+)
+
+var (
+	ACLLog = NewLog(aclLogMaxLen)
+)
+
+// LogEntry is an entry of the ACL log, recording the denials of a command to
+// a user from a client.
+type LogEntry struct {
+	ID         uint64
+	Count      int    // number of denials counted in the entry
+	Reason     string // why the command was denied, e.g. LogReasonCommand
+	Object     string // the command denied
+	Username   string
+	ClientInfo string // the client denied, in the CLIENT LIST format
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Log records the commands denied by the ACL rules, for auditing, newest
+// first. The oldest entries are dropped once maxLen are recorded.
+type Log struct {
+	mu      sync.Mutex
+	entries []*LogEntry
+	lastID  uint64
+	maxLen  int
+}
+
+func NewLog(maxLen int) *Log {
+	return &Log{maxLen: maxLen}
+}
+
+// Add records a denial. A denial matching one recorded less than
+// aclLogGroupWindow ago is counted in its entry, which becomes the newest.
+func (l *Log) Add(reason, object, username, clientInfo string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, e := range l.entries {
+		if e.Reason == reason && e.Object == object && e.Username == username && e.ClientInfo == clientInfo &&
+			now.Sub(e.UpdatedAt) < aclLogGroupWindow {
+			e.Count++
+			e.UpdatedAt = now
+			copy(l.entries[1:i+1], l.entries[:i])
+			l.entries[0] = e
+			return
+		}
+	}
+
+	l.lastID++
+	e := &LogEntry{
+		ID:         l.lastID,
+		Count:      1,
+		Reason:     reason,
+		Object:     object,
+		Username:   username,
+		ClientInfo: clientInfo,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	l.entries = append([]*LogEntry{e}, l.entries[:min(len(l.entries), l.maxLen-1)]...)
+}
+
+// Entries returns up to count entries, newest first, or all of them if count
+// is negative.
+func (l *Log) Entries(count int) []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if count < 0 || count > len(l.entries) {
+		count = len(l.entries)
+	}
+	entries := make([]LogEntry, count)
+	for i := range entries {
+		entries[i] = *l.entries[i]
+	}
+	return entries
+}
+
+// Reset drops the entries.
+func (l *Log) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+}
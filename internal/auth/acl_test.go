@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestUsersSetUser(t *testing.T) {
+	users := NewUsersStore()
+
+	assert.NilError(t, users.SetUser("alice"))
+	alice, err := users.Get("alice")
+	assert.NilError(t, err)
+	assert.Assert(t, alice.Disabled)
+	assert.Assert(t, !alice.CanRun("GET"))
+
+	assert.NilError(t, users.SetUser("alice", "on", ">secret", "+get", "+SET"))
+	alice, _ = users.Get("alice")
+	assert.Assert(t, !alice.Disabled)
+	assert.Assert(t, alice.CanRun("GET") && alice.CanRun("SET"))
+	assert.Assert(t, !alice.CanRun("DEL"))
+
+	assert.NilError(t, users.SetUser("alice", "allcommands", "-del"))
+	alice, _ = users.Get("alice")
+	assert.Assert(t, alice.CanRun("GET") && alice.CanRun("FLUSHDB"))
+	assert.Assert(t, !alice.CanRun("DEL"))
+	assert.Equal(t, "on #"+alice.Passwords[0]+" +@all -del", alice.Rules())
+
+	// the rules apply at once, or not at all
+	version := users.Version()
+	assert.ErrorContains(t, users.SetUser("alice", "off", "<unknown"), "no such password")
+	assert.ErrorContains(t, users.SetUser("alice", "off", "+@read"), "Syntax error")
+	assert.Equal(t, users.Version(), version)
+	alice, _ = users.Get("alice")
+	assert.Assert(t, !alice.Disabled)
+
+	assert.NilError(t, users.SetUser("alice", "<secret", "nopass"))
+	alice, _ = users.Get("alice")
+	assert.Assert(t, alice.NoPass && len(alice.Passwords) == 0)
+
+	assert.NilError(t, users.SetUser("bob", "reset"))
+	assert.DeepEqual(t, users.Names(), []string{"alice", "bob"})
+	assert.DeepEqual(t, users.List(), []string{"user alice on nopass +@all -del", "user bob off -@all"})
+
+	assert.Equal(t, users.Delete("bob", "carol"), 1)
+	assert.DeepEqual(t, users.Names(), []string{"alice"})
+}
+
+func TestSessionRevoked(t *testing.T) {
+	defer func(users *Users) { UserStore = users }(UserStore)
+	UserStore = NewUsersStore()
+	defer ACLLog.Reset()
+
+	assert.NilError(t, UserStore.SetUser("alice", "on", ">secret", "+get"))
+	session := NewSession()
+	assert.NilError(t, session.Validate("alice", "secret"))
+	assert.NilError(t, session.Authorize("GET", "id=1"))
+	assert.ErrorContains(t, session.Authorize("SET", "id=1"), "NOPERM User alice has no permissions to run the 'set' command")
+
+	// the changes to the user are picked up by the session
+	assert.NilError(t, UserStore.SetUser("alice", "+set"))
+	assert.Assert(t, !session.Revoked())
+	assert.NilError(t, session.Authorize("SET", "id=1"))
+
+	assert.NilError(t, UserStore.SetUser("alice", "off"))
+	assert.Assert(t, session.Revoked())
+	session.Downgrade()
+	assert.Assert(t, !session.Revoked())
+	assert.ErrorContains(t, session.Validate("alice", "secret"), "WRONGPASS")
+
+	assert.NilError(t, UserStore.SetUser("alice", "on"))
+	assert.NilError(t, session.Validate("alice", "secret"))
+	UserStore.Delete("alice")
+	assert.Assert(t, session.Revoked())
+
+	entries := ACLLog.Entries(-1)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Object, "set")
+	assert.Equal(t, entries[0].Username, "alice")
+	assert.Equal(t, entries[0].ClientInfo, "id=1")
+}
+
+func TestLog(t *testing.T) {
+	log := NewLog(2)
+	now := time.Now()
+
+	log.Add(LogReasonCommand, "set", "alice", "id=1", now)
+	log.Add(LogReasonCommand, "del", "alice", "id=1", now)
+	// the same denial is counted in its entry, which becomes the newest
+	log.Add(LogReasonCommand, "set", "alice", "id=1", now.Add(time.Second))
+	entries := log.Entries(-1)
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].Object, "set")
+	assert.Equal(t, entries[0].Count, 2)
+	assert.Equal(t, entries[1].Object, "del")
+
+	// the denials are no longer grouped after aclLogGroupWindow, and the oldest
+	// entries are dropped
+	log.Add(LogReasonCommand, "set", "alice", "id=1", now.Add(2*aclLogGroupWindow))
+	entries = log.Entries(1)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Count, 1)
+	assert.Equal(t, entries[0].ID, uint64(3))
+	assert.Equal(t, len(log.Entries(10)), 2)
+
+	log.Reset()
+	assert.Equal(t, len(log.Entries(-1)), 0)
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+const (
+	// jwksMaxAge is the age of the keys past which they are fetched again,
+	// for the keys rotated by the identity provider to be picked up.
+	jwksMaxAge = time.Hour
+	// jwksMinRefresh is the time between two fetches of the keys, for the
+	// tokens signed by an unknown key not to flood the identity provider.
+	jwksMinRefresh = time.Minute
+	// jwksTimeout bounds the requests fetching the keys.
+	jwksTimeout = 5 * time.Second
+)
+
+// JWKS is a KeySource fetching the public keys from a JSON Web Key Set, as
+// published by the OIDC identity providers at their jwks_uri. The keys are
+// fetched by Refresh, once at startup and then every jwksMaxAge by Run, and
+// cached. Key only reads the cache, as it is called by the shard threads: when
+// a token is signed by a key unknown, or the keys are older than jwksMaxAge,
+// it schedules a fetch in the background, at most every jwksMinRefresh. The
+// keys cached are kept when a fetch fails.
+type JWKS struct {
+	URL    string
+	Client *http.Client // Client is the client of the requests, one timing out after jwksTimeout if nil
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	refreshing  bool // refreshing is true while a fetch scheduled by Key is in progress
+}
+
+func (s *JWKS) Key(kid, _ string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := utils.GetCurrentTime()
+	key, ok := s.lookup(kid)
+	if (!ok || now.Sub(s.fetchedAt) > jwksMaxAge) && !s.refreshing && now.Sub(s.attemptedAt) >= jwksMinRefresh {
+		s.refreshing, s.attemptedAt = true, now
+		go func() {
+			_ = s.Refresh()
+		}()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+// Refresh fetches the keys and caches them, the lock not being held while
+// fetching.
+func (s *JWKS) Refresh() error {
+	s.mu.Lock()
+	s.attemptedAt = utils.GetCurrentTime()
+	s.mu.Unlock()
+
+	keys, err := s.fetch()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	if err != nil {
+		return err
+	}
+	s.keys, s.fetchedAt = keys, utils.GetCurrentTime()
+	return nil
+}
+
+// Run refreshes the keys every jwksMaxAge till ctx is done.
+func (s *JWKS) Run(ctx context.Context) {
+	ticker := time.NewTicker(jwksMaxAge)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(); err != nil {
+				slog.Warn("could not refresh the keys of the bearer tokens", slog.String("url", s.URL), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// lookup returns the key kid, or the only key of the set if kid is empty.
+func (s *JWKS) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// jwk is a JSON Web Key, holding an RSA or an EC public key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch returns the signing keys of the set by kid. The keys of a type not
+// supported are skipped.
+func (s *JWKS) fetch() (map[string]interface{}, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: jwksTimeout}
+	}
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("fetching the keys from %s: %s", s.URL, resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decoding the keys from %s: %w", s.URL, err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k *jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+
+	// defaultUserClaim is the claim naming the ACL user of a token, unless
+	// told otherwise.
+	defaultUserClaim = "sub"
+)
+
+var (
+	errNoTokenProvider  = errors.New("no token provider")
+	errMalformedToken   = errors.New("malformed token")
+	errInvalidSignature = errors.New("invalid token signature")
+	errTokenExpired     = errors.New("token expired")
+	errTokenNotYetValid = errors.New("token not yet valid")
+)
+
+// KeySource finds the key verifying the signature of a token, given the kid and
+// the alg of its header. The keys are a []byte for HS256, an *rsa.PublicKey for
+// RS256 and an *ecdsa.PublicKey for ES256.
+type KeySource interface {
+	Key(kid, alg string) (interface{}, error)
+}
+
+// StaticKeys is a KeySource holding the keys by kid. The key of the empty kid
+// verifies the tokens whose kid is unknown, or missing.
+type StaticKeys map[string]interface{}
+
+func (keys StaticKeys) Key(kid, _ string) (interface{}, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if key, ok := keys[""]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// JWTProvider is a TokenProvider validating JSON Web Tokens, as issued by the
+// OIDC identity providers. A token must be signed with HS256, RS256 or ES256 by
+// a key of Keys, the type of the key having to match the alg of the token, and
+// be valid at the time, as told by its exp and nbf claims. It authenticates the
+// ACL user named by its UserClaim.
+type JWTProvider struct {
+	Keys      KeySource
+	Issuer    string        // Issuer is the iss claim the tokens must have, any if empty
+	Audience  string        // Audience must be one of the aud claim of the tokens, any if empty
+	UserClaim string        // UserClaim is the claim naming the ACL user, sub if empty
+	Leeway    time.Duration // Leeway is tolerated on exp and nbf, for the clocks skew
+}
+
+func (p *JWTProvider) Authenticate(token string) (Identity, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return Identity{}, errMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(segments[0], &header); err != nil {
+		return Identity{}, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return Identity{}, errMalformedToken
+	}
+	key, err := p.Keys.Key(header.Kid, header.Alg)
+	if err != nil {
+		return Identity{}, err
+	}
+	if err := verifySignature(header.Alg, segments[0]+"."+segments[1], signature, key); err != nil {
+		return Identity{}, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(segments[1], &claims); err != nil {
+		return Identity{}, err
+	}
+	return p.identity(claims)
+}
+
+// identity checks the claims of a token whose signature was verified, and
+// returns the identity they describe.
+func (p *JWTProvider) identity(claims map[string]interface{}) (Identity, error) {
+	now := utils.GetCurrentTime()
+
+	var id Identity
+	if exp, ok := claims["exp"]; ok {
+		seconds, ok := exp.(float64)
+		if !ok {
+			return Identity{}, fmt.Errorf("invalid exp claim")
+		}
+		id.ExpiresAt = time.Unix(int64(seconds), 0)
+		if now.After(id.ExpiresAt.Add(p.Leeway)) {
+			return Identity{}, errTokenExpired
+		}
+	}
+	if nbf, ok := claims["nbf"]; ok {
+		seconds, ok := nbf.(float64)
+		if !ok {
+			return Identity{}, fmt.Errorf("invalid nbf claim")
+		}
+		if now.Add(p.Leeway).Before(time.Unix(int64(seconds), 0)) {
+			return Identity{}, errTokenNotYetValid
+		}
+	}
+	if p.Issuer != "" && claims["iss"] != p.Issuer {
+		return Identity{}, fmt.Errorf("unexpected issuer %v", claims["iss"])
+	}
+	if p.Audience != "" && !hasAudience(claims["aud"], p.Audience) {
+		return Identity{}, fmt.Errorf("unexpected audience %v", claims["aud"])
+	}
+
+	userClaim := p.UserClaim
+	if userClaim == "" {
+		userClaim = defaultUserClaim
+	}
+	if id.Username, _ = claims[userClaim].(string); id.Username == "" {
+		return Identity{}, fmt.Errorf("no %s claim", userClaim)
+	}
+	return id, nil
+}
+
+// hasAudience returns true if the aud claim, a string or an array of strings,
+// holds audience.
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errMalformedToken
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errMalformedToken
+	}
+	return nil
+}
+
+// verifySignature verifies the signature of the signed part of a token with
+// key, which must be of the type alg expects: an alg of none, or an RSA public
+// key used as an HMAC secret, never verifies a token.
+func verifySignature(alg, signed string, signature []byte, key interface{}) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch alg {
+	case AlgHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			break
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errInvalidSignature
+		}
+		return nil
+	case AlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			break
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return errInvalidSignature
+		}
+		return nil
+	case AlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			break
+		}
+		// the signature is r and s, 32 bytes each
+		if len(signature) != 64 {
+			return errInvalidSignature
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+	return fmt.Errorf("the key does not match the alg %q", alg)
+}
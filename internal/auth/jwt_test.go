@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/server/utils"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+)
+
+// signToken returns a token of the claims signed with key, as alg.
+func signToken(t *testing.T, alg, kid string, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	assert.NilError(t, err)
+	payload, err := json.Marshal(claims)
+	assert.NilError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		assert.NilError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		assert.NilError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTProvider(t *testing.T) {
+	defer func(clock utils.Clock) { utils.CurrentTime = clock }(utils.CurrentTime)
+	now := time.Unix(1700000000, 0)
+	utils.CurrentTime = &utils.MockClock{CurrTime: now}
+
+	secret := []byte("secret")
+	provider := &JWTProvider{Keys: StaticKeys{"": secret}, Issuer: "https://idp", Audience: "dice", Leeway: time.Minute}
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "alice", "iss": "https://idp", "aud": []string{"other", "dice"}, "exp": now.Add(time.Hour).Unix()}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	id, err := provider.Authenticate(signToken(t, AlgHS256, "", claims(nil), secret))
+	assert.NilError(t, err)
+	assert.Equal(t, id.Username, "alice")
+	assert.Equal(t, id.ExpiresAt, now.Add(time.Hour))
+
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(nil), []byte("wrong")))
+	assert.ErrorIs(t, err, errInvalidSignature)
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()}), secret))
+	assert.ErrorIs(t, err, errTokenExpired)
+	// the leeway tolerates the clocks skew
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(map[string]interface{}{"exp": now.Add(-time.Second).Unix()}), secret))
+	assert.NilError(t, err)
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()}), secret))
+	assert.ErrorIs(t, err, errTokenNotYetValid)
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(map[string]interface{}{"iss": "https://evil"}), secret))
+	assert.ErrorContains(t, err, "unexpected issuer")
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(map[string]interface{}{"aud": "other"}), secret))
+	assert.ErrorContains(t, err, "unexpected audience")
+
+	provider.UserClaim = "preferred_username"
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(nil), secret))
+	assert.ErrorContains(t, err, "no preferred_username claim")
+	id, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(map[string]interface{}{"preferred_username": "bob"}), secret))
+	assert.NilError(t, err)
+	assert.Equal(t, id.Username, "bob")
+
+	// the alg must match the type of the key, whatever the token tells
+	_, err = provider.Authenticate(signToken(t, "none", "", claims(nil), nil))
+	assert.ErrorContains(t, err, "unsupported alg")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	provider.Keys = StaticKeys{"": &rsaKey.PublicKey}
+	_, err = provider.Authenticate(signToken(t, AlgHS256, "", claims(nil), rsaKey.PublicKey.N.Bytes()))
+	assert.ErrorContains(t, err, "does not match")
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	provider.Keys = StaticKeys{"ec": &ecKey.PublicKey}
+	_, err = provider.Authenticate(signToken(t, AlgES256, "ec", claims(map[string]interface{}{"preferred_username": "bob"}), ecKey))
+	assert.NilError(t, err)
+	_, err = provider.Authenticate(signToken(t, AlgES256, "other", claims(map[string]interface{}{"preferred_username": "bob"}), ecKey))
+	assert.ErrorContains(t, err, "unknown key")
+}
+
+func TestJWKS(t *testing.T) {
+	defer func(clock utils.Clock) { utils.CurrentTime = clock }(utils.CurrentTime)
+	clock := &utils.MockClock{CurrTime: time.Unix(1700000000, 0)}
+	utils.CurrentTime = clock
+
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+
+	var fetches atomic.Int32
+	var published atomic.Value
+	published.Store([]*rsa.PrivateKey{key1})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		var keys []map[string]string
+		for i, key := range published.Load().([]*rsa.PrivateKey) {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": []string{"key1", "key2"}[i],
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer server.Close()
+
+	jwks := &JWKS{URL: server.URL}
+	assert.NilError(t, jwks.Refresh())
+	provider := &JWTProvider{Keys: jwks}
+	claims := map[string]interface{}{"sub": "alice"}
+	_, err = provider.Authenticate(signToken(t, AlgRS256, "key1", claims, key1))
+	assert.NilError(t, err)
+	_, err = provider.Authenticate(signToken(t, AlgRS256, "key1", claims, key1))
+	assert.NilError(t, err)
+	assert.Equal(t, fetches.Load(), int32(1))
+
+	// the keys rotated are fetched again in the background for the tokens of
+	// an unknown key, at most every jwksMinRefresh
+	published.Store([]*rsa.PrivateKey{key1, key2})
+	_, err = provider.Authenticate(signToken(t, AlgRS256, "key2", claims, key2))
+	assert.ErrorContains(t, err, "unknown key")
+	assert.Equal(t, fetches.Load(), int32(1))
+	clock.SetTime(clock.CurrTime.Add(jwksMinRefresh))
+	_, err = provider.Authenticate(signToken(t, AlgRS256, "key2", claims, key2))
+	assert.ErrorContains(t, err, "unknown key")
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if _, err := provider.Authenticate(signToken(t, AlgRS256, "key2", claims, key2)); err != nil {
+			return poll.Continue("the keys are not fetched yet: %v", err)
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second))
+	assert.Equal(t, fetches.Load(), int32(2))
+
+	// the keys cached are kept when a fetch fails
+	server.Close()
+	assert.Assert(t, jwks.Refresh() != nil)
+	clock.SetTime(clock.CurrTime.Add(jwksMaxAge + time.Second))
+	_, err = provider.Authenticate(signToken(t, AlgRS256, "key1", claims, key1))
+	assert.NilError(t, err)
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		jwks.mu.Lock()
+		defer jwks.mu.Unlock()
+		if jwks.refreshing {
+			return poll.Continue("the keys are still being fetched")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second))
+	_, err = provider.Authenticate(signToken(t, AlgRS256, "key1", claims, key1))
+	assert.NilError(t, err)
+}
+
+func TestSessionValidateToken(t *testing.T) {
+	defer func(users *Users) { UserStore = users }(UserStore)
+	UserStore = NewUsersStore()
+	defer func(providers []TokenProvider) { tokenProviders = providers }(tokenProviders)
+	tokenProviders = nil
+	defer func(clock utils.Clock) { utils.CurrentTime = clock }(utils.CurrentTime)
+	clock := &utils.MockClock{CurrTime: time.Unix(1700000000, 0)}
+	utils.CurrentTime = clock
+
+	secret := []byte("secret")
+	token := func(sub string) string {
+		return signToken(t, AlgHS256, "", map[string]interface{}{"sub": sub, "exp": clock.CurrTime.Add(time.Hour).Unix()}, secret)
+	}
+	assert.NilError(t, UserStore.SetUser("alice", "on", ">pass.wo.rd", "+get"))
+	assert.NilError(t, UserStore.SetUser("bob", "off"))
+
+	// the tokens are passwords till a provider is registered
+	session := NewSession()
+	assert.Assert(t, !IsToken(token("alice")))
+	assert.ErrorContains(t, session.Validate("alice", token("alice")), "WRONGPASS")
+
+	RegisterTokenProvider(&JWTProvider{Keys: StaticKeys{"": secret}})
+	assert.Assert(t, IsToken(token("alice")))
+	assert.NilError(t, session.Validate("", token("alice")))
+	assert.Equal(t, session.User.Username, "alice")
+	assert.NilError(t, session.Validate("alice", token("alice")))
+	assert.ErrorContains(t, session.Validate("alice", token("carol")), "WRONGPASS")
+	assert.ErrorContains(t, session.Validate("", token("bob")), "WRONGPASS")
+	// a password looking like a token is still a password
+	assert.NilError(t, session.Validate("alice", "pass.wo.rd"))
+
+	// the session lasts till its token expires
+	assert.NilError(t, session.Validate("", token("alice")))
+	assert.Assert(t, !session.Revoked())
+	clock.SetTime(clock.CurrTime.Add(time.Hour + time.Second))
+	assert.Assert(t, session.Revoked())
+	assert.NilError(t, session.Validate("alice", "pass.wo.rd"))
+	assert.Assert(t, !session.Revoked())
+}
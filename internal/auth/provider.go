@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/config"
+)
+
+// Identity is the ACL user a bearer token authenticates, along with the time
+// the token expires at, zero if it never does.
+type Identity struct {
+	Username  string
+	ExpiresAt time.Time
+}
+
+// TokenProvider validates the bearer tokens given to AUTH in place of a
+// password, e.g. the JSON Web Tokens issued by an identity provider, and maps
+// them to the ACL users. Authenticate must return an error if the token is not
+// valid, whatever the reason.
+type TokenProvider interface {
+	Authenticate(token string) (Identity, error)
+}
+
+var (
+	providersMu    sync.RWMutex
+	tokenProviders []TokenProvider
+)
+
+// RegisterTokenProvider adds p to the providers the bearer tokens are
+// validated by. The providers are tried in the order they were registered, the
+// first one accepting a token authenticating it.
+func RegisterTokenProvider(p TokenProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	tokenProviders = append(tokenProviders, p)
+}
+
+// SetupTokenProviders registers the providers of the bearer tokens configured:
+// a JWTProvider verifying the tokens with the shared secret of the config, and
+// one verifying them with the keys of the JWKS URL of the config. The keys are
+// fetched right away, then refreshed in the background till ctx is done.
+func SetupTokenProviders(ctx context.Context) {
+	cfg := config.DiceConfig.Auth
+	newProvider := func(keys KeySource) *JWTProvider {
+		return &JWTProvider{Keys: keys, Issuer: cfg.TokenIssuer, Audience: cfg.TokenAudience, UserClaim: cfg.TokenUserClaim}
+	}
+	if cfg.TokenSecret != "" {
+		RegisterTokenProvider(newProvider(StaticKeys{"": []byte(cfg.TokenSecret)}))
+	}
+	if cfg.TokenJWKSURL != "" {
+		jwks := &JWKS{URL: cfg.TokenJWKSURL}
+		if err := jwks.Refresh(); err != nil {
+			slog.Warn("could not fetch the keys of the bearer tokens", slog.String("url", jwks.URL), slog.Any("error", err))
+		}
+		go jwks.Run(ctx)
+		RegisterTokenProvider(newProvider(jwks))
+	}
+}
+
+// IsToken returns true if credential may be a bearer token, a JSON Web Token
+// made of three segments, and providers are registered to validate it.
+func IsToken(credential string) bool {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return len(tokenProviders) > 0 && strings.Count(credential, ".") == 2
+}
+
+// authenticateToken returns the identity of the first provider accepting the
+// token.
+func authenticateToken(token string) (Identity, error) {
+	providersMu.RLock()
+	providers := tokenProviders
+	providersMu.RUnlock()
+
+	err := errNoTokenProvider
+	for _, p := range providers {
+		var id Identity
+		if id, err = p.Authenticate(token); err == nil {
+			return id, nil
+		}
+	}
+	slog.Debug("bearer token rejected", slog.Any("error", err))
+	return Identity{}, err
+}
@@ -3,7 +3,9 @@ package auth
 import (
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dicedb/dice/internal/server/utils"
@@ -34,17 +36,25 @@ type (
 		LastAccessedAt time.Time
 
 		Status SessionStatusT
+
+		version   uint64    // version of the users the session was last evaluated against
+		expiresAt time.Time // expiresAt is the expiry of the bearer token the session was authenticated with, if any
 	}
 
 	Users struct {
-		store  map[string]*User
-		stLock *sync.RWMutex
+		store   map[string]*User
+		stLock  *sync.RWMutex
+		version atomic.Uint64
 	}
 
 	User struct {
 		Username          string
 		Passwords         []string
 		IsPasswordEnabled bool
+		Disabled          bool            // a disabled user can not authenticate
+		NoPass            bool            // any password authenticates the user
+		Restricted        bool            // the user may run none of the commands but the ones allowed by Commands
+		Commands          map[string]bool // the commands allowed or denied, overriding Restricted
 	}
 )
 
@@ -73,6 +83,7 @@ func (users *Users) Add(username string) (user *User, err error) {
 	users.stLock.Lock()
 	defer users.stLock.Unlock()
 	users.store[username] = user
+	users.version.Add(1)
 	return
 }
 
@@ -104,8 +115,10 @@ func NewSession() (session *Session) {
 }
 
 func (session *Session) IsActive() (isActive bool) {
-	if config.DiceConfig.Auth.Password == utils.EmptyStr && session.Status != SessionStatusActive {
-		session.Activate(session.User)
+	if session.Status != SessionStatusActive {
+		if user, ok := passwordless(); ok {
+			session.Activate(user)
+		}
 	}
 	isActive = session.Status == SessionStatusActive
 	if isActive {
@@ -119,17 +132,50 @@ func (session *Session) Activate(user *User) {
 	session.Status = SessionStatusActive
 	session.CreatedAt = utils.GetCurrentTime().UTC()
 	session.LastAccessedAt = utils.GetCurrentTime().UTC()
+	session.version = UserStore.Version()
+	session.expiresAt = time.Time{}
+}
+
+// passwordless returns the default user if it needs no password, see nopass,
+// the sessions being authenticated as it without AUTH. Till the users are set
+// up, no password is needed if none is configured.
+func passwordless() (*User, bool) {
+	user, err := UserStore.Get(config.DiceConfig.Auth.UserName)
+	if err != nil {
+		return nil, config.DiceConfig.Auth.Password == utils.EmptyStr
+	}
+	return user, user.NoPass && !user.Disabled
 }
 
+// Validate authenticates the session as the user username, the default user if
+// empty. The password may be a bearer token, see IsToken, in which case the
+// session is authenticated as the user the token maps to, which must be
+// username unless empty. Such a session lasts till its token expires. A token
+// rejected is checked as a password, as a password may look like a token.
 func (session *Session) Validate(username, password string) error {
 	var (
 		err  error
 		user *User
 	)
+	if IsToken(password) {
+		if session.validateToken(username, password) == nil {
+			return nil
+		}
+		// the default user takes any password when it needs none
+		if _, ok := passwordless(); username == "" && ok {
+			return fmt.Errorf("WRONGPASS invalid username-password pair or user is disabled")
+		}
+	}
+	if username == "" {
+		username = config.DiceConfig.Auth.UserName
+	}
 	if user, err = UserStore.Get(username); err != nil {
 		return err
 	}
-	if username == config.DiceConfig.Auth.UserName && len(user.Passwords) == 0 {
+	if user.Disabled {
+		return fmt.Errorf("WRONGPASS invalid username-password pair or user is disabled")
+	}
+	if user.NoPass {
 		session.Activate(user)
 		return nil
 	}
@@ -143,6 +189,70 @@ func (session *Session) Validate(username, password string) error {
 	return fmt.Errorf("WRONGPASS invalid username-password pair or user is disabled")
 }
 
+func (session *Session) validateToken(username, token string) error {
+	id, err := authenticateToken(token)
+	if err != nil {
+		return err
+	}
+	if username != "" && username != id.Username {
+		return fmt.Errorf("the token is for the user %s", id.Username)
+	}
+	user, err := UserStore.Get(id.Username)
+	if err != nil {
+		return err
+	}
+	if user.Disabled {
+		return fmt.Errorf("the user %s is disabled", id.Username)
+	}
+	session.Activate(user)
+	session.expiresAt = id.ExpiresAt
+	return nil
+}
+
 func (session *Session) Expire() {
 	session.Status = SessionStatusExpired
 }
+
+// Revoked re-evaluates the active session if the users changed since it was
+// last evaluated. It returns true if its user lost access, being deleted or
+// disabled, or if the bearer token it was authenticated with expired.
+// Otherwise the session picks up the changes to its user. The sessions active
+// without a user, as no password is required, are never revoked.
+func (session *Session) Revoked() bool {
+	if session.Status != SessionStatusActive || session.User == nil {
+		return false
+	}
+	if !session.expiresAt.IsZero() && utils.GetCurrentTime().After(session.expiresAt) {
+		return true
+	}
+	version := UserStore.Version()
+	if session.version == version {
+		return false
+	}
+	session.version = version
+
+	user, err := UserStore.Get(session.User.Username)
+	if err != nil || user.Disabled {
+		return true
+	}
+	session.User = user
+	return false
+}
+
+// Downgrade sends the session back to the unauthenticated state, the client
+// having to authenticate again.
+func (session *Session) Downgrade() {
+	session.User = nil
+	session.Status = SessionStatusPending
+}
+
+// Authorize returns a NOPERM error if the user of the session may not run the
+// command cmd, recording the denial in ACLLog along with clientInfo, the
+// description of the client. AUTH is always authorized.
+func (session *Session) Authorize(cmd, clientInfo string) error {
+	if session.User == nil || cmd == Cmd || session.User.CanRun(cmd) {
+		return nil
+	}
+	ACLLog.Add(LogReasonCommand, strings.ToLower(cmd), session.User.Username, clientInfo, utils.GetCurrentTime())
+	return fmt.Errorf("NOPERM User %s has no permissions to run the '%s' command", session.User.Username, strings.ToLower(cmd))
+}
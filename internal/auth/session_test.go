@@ -149,3 +149,32 @@ func TestSessionExpire(t *testing.T) {
 		t.Errorf("Session.Expire() did not set status to Expired. Got %v, want %v", session.Status, SessionStatusExpired)
 	}
 }
+
+func TestDefaultUserResetPass(t *testing.T) {
+	username := config.DiceConfig.Auth.UserName
+	if err := UserStore.SetupDefault(username, ""); err != nil {
+		t.Fatalf("Users.SetupDefault() returned an error: %v", err)
+	}
+	defer UserStore.Delete(username)
+
+	// the default user needs no password when none is configured
+	if session := NewSession(); !session.IsActive() || session.User == nil || session.User.Username != username {
+		t.Error("a session should be authenticated as the default user without AUTH")
+	}
+	if err := NewSession().Validate("", "anything"); err != nil {
+		t.Errorf("Session.Validate() returned an error: %v", err)
+	}
+
+	// resetpass makes it impossible to authenticate as the default user
+	if err := UserStore.SetUser(username, "resetpass"); err != nil {
+		t.Fatalf("Users.SetUser() returned an error: %v", err)
+	}
+	if NewSession().IsActive() {
+		t.Error("a session should not be authenticated once the default user has no password")
+	}
+	for _, password := range []string{"", "anything"} {
+		if err := NewSession().Validate(username, password); err == nil {
+			t.Errorf("Session.Validate() accepted the password %q of the default user reset", password)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package ioring
+
+import (
+	"errors"
+	"io"
+	"syscall"
+	"time"
+
+	"github.com/dicedb/dice/internal/iomultiplexer"
+)
+
+// blockedWriteRetry is the interval the writes blocked by a full socket buffer
+// are retried at, the multiplexer only watching the reads.
+const blockedWriteRetry = time.Millisecond
+
+// PollRing is the readiness based Ring: the file descriptors with a read in
+// flight are watched by the multiplexer, and read once ready. The writes are
+// attempted right away by Wait, and retried while the socket buffer is full.
+// The file descriptors must be non-blocking.
+type PollRing struct {
+	mux         iomultiplexer.IOMultiplexer
+	reads       map[int]Op // reads maps the file descriptors to their read in flight
+	writes      []*pendingWrite
+	wakeR       int // wakeR and wakeW are the ends of the pipe waking Wait up
+	wakeW       int
+	completions []Completion
+	closed      bool
+}
+
+type pendingWrite struct {
+	op      Op
+	written int
+}
+
+var _ Ring = (*PollRing)(nil)
+
+// NewPollRing creates a PollRing watching up to maxConns file descriptors.
+func NewPollRing(maxConns int32) (*PollRing, error) {
+	mux, err := iomultiplexer.New(maxConns + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := make([]int, 2)
+	if err := syscall.Pipe(pipe); err != nil {
+		mux.Close()
+		return nil, err
+	}
+	r := &PollRing{mux: mux, reads: make(map[int]Op), wakeR: pipe[0], wakeW: pipe[1]}
+	for _, fd := range pipe {
+		if err := syscall.SetNonblock(fd, true); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	if err := mux.Subscribe(iomultiplexer.Event{Fd: r.wakeR, Op: iomultiplexer.OpRead}); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Submit queues the operations. The file descriptors of the reads are watched
+// till they are ready.
+func (r *PollRing) Submit(ops ...Op) error {
+	if r.closed {
+		return ErrClosed
+	}
+	for _, op := range ops {
+		switch op.Code {
+		case OpRead:
+			if _, ok := r.reads[op.Fd]; ok {
+				return errors.New("read already in flight")
+			}
+			if err := r.mux.Subscribe(iomultiplexer.Event{Fd: op.Fd, Op: iomultiplexer.OpRead}); err != nil {
+				return err
+			}
+			r.reads[op.Fd] = op
+		case OpWrite:
+			r.writes = append(r.writes, &pendingWrite{op: op})
+		}
+	}
+	return nil
+}
+
+// Wait performs the writes submitted, then the reads whose file descriptor is
+// ready, and returns their completions.
+func (r *PollRing) Wait(timeout time.Duration) ([]Completion, error) {
+	if r.closed {
+		return nil, ErrClosed
+	}
+	r.completions = r.completions[:0]
+
+	r.flushWrites()
+	if len(r.completions) > 0 {
+		timeout = 0
+	} else if len(r.writes) > 0 {
+		timeout = min(timeout, blockedWriteRetry)
+	}
+
+	events, err := r.mux.Poll(timeout)
+	if err != nil && !errors.Is(err, syscall.EINTR) {
+		return nil, err
+	}
+	for _, event := range events {
+		if event.Fd == r.wakeR {
+			r.drainWakes()
+			continue
+		}
+		op, ok := r.reads[event.Fd]
+		if !ok {
+			continue
+		}
+		n, err := syscall.Read(op.Fd, op.Buf)
+		if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if n <= 0 && err == nil {
+			err = io.EOF
+		}
+		r.forgetRead(op.Fd)
+		r.completions = append(r.completions, Completion{Op: op, N: max(n, 0), Err: err})
+	}
+	return r.completions, nil
+}
+
+// flushWrites writes out the writes submitted, in order. The writes of a file
+// descriptor following one blocked by a full socket buffer are kept waiting.
+func (r *PollRing) flushWrites() {
+	blocked := make(map[int]bool)
+	pending := r.writes[:0]
+	for _, w := range r.writes {
+		if blocked[w.op.Fd] {
+			pending = append(pending, w)
+			continue
+		}
+		var err error
+		for w.written < len(w.op.Buf) {
+			var n int
+			n, err = syscall.Write(w.op.Fd, w.op.Buf[w.written:])
+			if err != nil {
+				break
+			}
+			w.written += n
+		}
+		if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+			blocked[w.op.Fd] = true
+			pending = append(pending, w)
+			continue
+		}
+		r.completions = append(r.completions, Completion{Op: w.op, N: w.written, Err: err})
+	}
+	for i := len(pending); i < len(r.writes); i++ {
+		r.writes[i] = nil
+	}
+	r.writes = pending
+}
+
+// Wake interrupts the Wait in progress, or the next one.
+func (r *PollRing) Wake() error {
+	_, err := syscall.Write(r.wakeW, []byte{0})
+	if errors.Is(err, syscall.EAGAIN) {
+		// the pipe is full of wakes not yet drained
+		return nil
+	}
+	return err
+}
+
+func (r *PollRing) drainWakes() {
+	buf := make([]byte, 64)
+	for {
+		if n, err := syscall.Read(r.wakeR, buf); n <= 0 || err != nil {
+			return
+		}
+	}
+}
+
+// Cancel drops the read and the writes of fd not yet completed.
+func (r *PollRing) Cancel(fd int) error {
+	r.forgetRead(fd)
+	pending := r.writes[:0]
+	for _, w := range r.writes {
+		if w.op.Fd != fd {
+			pending = append(pending, w)
+		}
+	}
+	r.writes = pending
+	return nil
+}
+
+func (r *PollRing) forgetRead(fd int) {
+	if _, ok := r.reads[fd]; !ok {
+		return
+	}
+	delete(r.reads, fd)
+	// the file descriptor may be closed already
+	_ = r.mux.Unsubscribe(fd)
+}
+
+// Close releases the multiplexer and the pipe. The file descriptors of the
+// operations in flight are left open.
+func (r *PollRing) Close() error {
+	r.closed = true
+	return errors.Join(r.mux.Close(), syscall.Close(r.wakeR), syscall.Close(r.wakeW))
+}
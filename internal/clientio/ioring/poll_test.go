@@ -0,0 +1,76 @@
+package ioring
+
+import (
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func socketPair(t *testing.T) (int, int) {
+	t.Helper()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	assert.NilError(t, err)
+	for _, fd := range fds {
+		assert.NilError(t, syscall.SetNonblock(fd, true))
+	}
+	t.Cleanup(func() {
+		syscall.Close(fds[0])
+		syscall.Close(fds[1])
+	})
+	return fds[0], fds[1]
+}
+
+func TestPollRing(t *testing.T) {
+	r, err := NewPollRing(16)
+	assert.NilError(t, err)
+	defer r.Close()
+	a, b := socketPair(t)
+
+	// the writes complete in order, the read once the data arrives
+	assert.NilError(t, r.Submit(Op{Code: OpWrite, Fd: a, Buf: []byte("hello ")}, Op{Code: OpWrite, Fd: a, Buf: []byte("world")}))
+	assert.NilError(t, r.Submit(Op{Code: OpRead, Fd: b, Buf: make([]byte, 64)}))
+	assert.ErrorContains(t, r.Submit(Op{Code: OpRead, Fd: b, Buf: make([]byte, 64)}), "in flight")
+
+	var read []byte
+	var written int
+	for deadline := time.Now().Add(time.Second); read == nil && time.Now().Before(deadline); {
+		completions, err := r.Wait(100 * time.Millisecond)
+		assert.NilError(t, err)
+		for _, c := range completions {
+			assert.NilError(t, c.Err)
+			if c.Op.Code == OpWrite {
+				written += c.N
+			} else {
+				read = c.Op.Buf[:c.N]
+			}
+		}
+	}
+	assert.Equal(t, 11, written)
+	assert.Equal(t, "hello world", string(read))
+
+	// Wake interrupts a Wait without completions
+	start := time.Now()
+	go r.Wake()
+	completions, err := r.Wait(10 * time.Second)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(completions))
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+
+	// the read of a connection closed by the peer completes with io.EOF
+	assert.NilError(t, r.Submit(Op{Code: OpRead, Fd: b, Buf: make([]byte, 64)}))
+	assert.NilError(t, syscall.Shutdown(a, syscall.SHUT_WR))
+	completions, err = r.Wait(time.Second)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(completions))
+	assert.Equal(t, io.EOF, completions[0].Err)
+
+	// the operations canceled never complete
+	assert.NilError(t, r.Submit(Op{Code: OpRead, Fd: a, Buf: make([]byte, 64)}))
+	assert.NilError(t, r.Cancel(a))
+	completions, err = r.Wait(10 * time.Millisecond)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(completions))
+}
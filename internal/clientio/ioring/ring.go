@@ -0,0 +1,64 @@
+// Package ioring batches the I/O of the connections served by an event loop.
+//
+// A Ring follows the submission and completion queues of io_uring: the reads
+// and writes are submitted without blocking, and their completions are reaped
+// in batches by Wait. The operations never complete partially, a write
+// completes once its whole buffer is written. PollRing performs the operations
+// with plain system calls once the multiplexer reports their file descriptors
+// ready; a completion based backend, e.g. io_uring, can implement Ring as is.
+package ioring
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrClosed = errors.New("ring closed")
+
+// OpCode is the kind of an operation.
+type OpCode uint8
+
+const (
+	// OpRead reads up to len(Buf) bytes into Buf.
+	OpRead OpCode = iota
+	// OpWrite writes the whole Buf.
+	OpWrite
+)
+
+// Op is an I/O operation on a file descriptor.
+type Op struct {
+	Code OpCode
+	Fd   int
+	Buf  []byte
+}
+
+// Completion is the outcome of an operation.
+type Completion struct {
+	Op  Op
+	N   int   // N is the number of bytes read or written
+	Err error // Err is io.EOF once the peer closed the connection
+}
+
+// Ring submits I/O operations and reaps their completions. A file descriptor
+// has at most one read in flight, and its writes complete in the order they
+// were submitted. A Ring is used by a single goroutine, except for Wake.
+type Ring interface {
+	// Submit queues the operations.
+	Submit(ops ...Op) error
+
+	// Wait returns the completions of the operations submitted, blocking up
+	// to timeout until there is at least one, or until Wake is called. The
+	// completions are valid until the next call.
+	Wait(timeout time.Duration) ([]Completion, error)
+
+	// Wake interrupts the Wait in progress, or the next one. It is safe to
+	// call from any goroutine.
+	Wake() error
+
+	// Cancel drops the operations of fd not yet completed, before it is
+	// closed.
+	Cancel(fd int) error
+
+	// Close releases the resources of the ring.
+	Close() error
+}
@@ -1,7 +1,11 @@
 package clientio
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/dicedb/dice/internal/sql"
+	dstore "github.com/dicedb/dice/internal/store"
 )
 
 // CreatePushResponse creates a push response. Push responses refer to messages that the server sends to clients without
@@ -14,3 +18,31 @@ func CreatePushResponse(query *sql.DSQLQuery, result *[]sql.QueryResultRow) (res
 	response[2] = *result
 	return
 }
+
+// ZDelta is the name of the push responses carrying the deltas of a sorted set.
+const ZDelta = "ZDELTA"
+
+// CreateDeltaPushResponse creates the push response of the deltas of the sorted set stored at key, every delta being
+// [member, old score, new score, rank change], with nil for the values a delta does not have.
+func CreateDeltaPushResponse(key string, deltas []dstore.ZSetDelta) []interface{} {
+	encoded := make([]interface{}, len(deltas))
+	for i, delta := range deltas {
+		entry := []interface{}{delta.Member, nil, nil, nil}
+		if delta.OldScore != nil {
+			entry[1] = formatDeltaScore(*delta.OldScore)
+		}
+		if delta.NewScore != nil {
+			entry[2] = formatDeltaScore(*delta.NewScore)
+		}
+		if delta.RankChange != nil {
+			entry[3] = *delta.RankChange
+		}
+		encoded[i] = entry
+	}
+	return []interface{}{ZDelta, key, encoded}
+}
+
+// formatDeltaScore formats a score the way the sorted set commands reply it.
+func formatDeltaScore(score float64) string {
+	return strings.ToLower(strconv.FormatFloat(score, 'g', -1, 64))
+}
@@ -173,6 +173,24 @@ func encodeBool(v bool) []byte {
 	return []byte(fmt.Sprintf("+%t\r\n", v))
 }
 
+// EncodeInt encodes v as a RESP integer.
+func EncodeInt(v int64) []byte {
+	b := strconv.AppendInt([]byte{':'}, v, 10)
+	return append(b, '\r', '\n')
+}
+
+// EncodeSimpleString encodes v as a RESP simple string. v must not hold
+// CR or LF.
+func EncodeSimpleString(v string) []byte {
+	return []byte("+" + v + "\r\n")
+}
+
+// EncodeError encodes err as a RESP error. The message of err holds its error
+// code, e.g. "ERR" or "WRONGTYPE", first.
+func EncodeError(err error) []byte {
+	return []byte("-" + err.Error() + "\r\n")
+}
+
 func Encode(value interface{}, isSimple bool) []byte {
 	// Use a type switch to determine the type of the provided value and encode accordingly.
 	switch v := value.(type) {
@@ -187,11 +205,15 @@ func Encode(value interface{}, isSimple bool) []byte {
 	case string:
 		// encode as simple strings
 		if isSimple || v == "[" || v == "{" {
-			return []byte(fmt.Sprintf("+%s\r\n", v))
+			return EncodeSimpleString(v)
 		}
 		// encode as bulk strings
 		return encodeString(v)
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+	case int:
+		return EncodeInt(int64(v))
+	case int64:
+		return EncodeInt(v)
+	case int8, int16, int32, uint, uint8, uint16, uint32, uint64:
 		return []byte(fmt.Sprintf(":%d\r\n", v)) // Prefix with ':' for RESP integers.
 
 	// Handle floating-point types similarly to integers.
@@ -247,7 +269,7 @@ func Encode(value interface{}, isSimple bool) []byte {
 
 	// Handle error type by formatting it as a RESP error.
 	case error:
-		return []byte(fmt.Sprintf("-%s\r\n", v))
+		return EncodeError(v)
 	case dstore.QueryWatchEvent:
 		var b []byte
 		buf := bytes.NewBuffer(b)
@@ -218,3 +218,16 @@ func TestInteger(t *testing.T) {
 		testifyAssert.Equal(t, ev, v.output)
 	}
 }
+
+func TestEncodeInt(t *testing.T) {
+	for v, output := range map[int64]string{0: ":0\r\n", 9: ":9\r\n", 10: ":10\r\n", -42: ":-42\r\n", math.MaxInt64: ":9223372036854775807\r\n"} {
+		testifyAssert.Equal(t, output, string(clientio.EncodeInt(v)))
+		testifyAssert.Equal(t, output, string(clientio.Encode(v, false)))
+	}
+}
+
+func TestEncodeSimpleStringAndError(t *testing.T) {
+	testifyAssert.Equal(t, "+OK\r\n", string(clientio.EncodeSimpleString("OK")))
+	testifyAssert.Equal(t, "-ERR invalid key\r\n", string(clientio.EncodeError(fmt.Errorf("ERR invalid key"))))
+	testifyAssert.Equal(t, "-ERR invalid key\r\n", string(clientio.Encode(fmt.Errorf("ERR invalid key"), false)))
+}
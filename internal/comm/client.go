@@ -1,11 +1,14 @@
 package comm
 
 import (
+	"fmt"
 	"io"
 	"syscall"
+	"time"
 
 	"github.com/dicedb/dice/internal/auth"
 	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
 )
 
 type QwatchResponse struct {
@@ -20,8 +23,17 @@ type Client struct {
 	Fd                     int
 	Cqueue                 cmd.RedisCmds
 	IsTxn                  bool
+	Watch                  *dstore.KeyWatch // Keys watched by the next transaction of the client, see WATCH, nil if none were yet
 	Session                *auth.Session
 	ClientIdentifierID     uint32
+	ID                     uint64    // Unique id of the connection, as reported by CLIENT LIST
+	Addr                   string    // Remote address of the connection, ip:port
+	CreatedAt              time.Time // Time at which the connection was accepted
+	LastActive             time.Time // Time of the last command received from the client
+	Subscribed             bool      // Set once the client watches a query, switching it to the subscriber idle timeout
+	DB                     int       // Logical database the commands of the client run on, see SELECT
+	Token                  int64     // Replication offset reached by the latest write of the client, see CLIENT TOKEN
+	ReadAfter              int64     // Token the replicas must have caught up with to serve the client, see CLIENT READAFTER
 }
 
 func (c *Client) Write(b []byte) (int, error) {
@@ -45,6 +57,33 @@ func (c *Client) TxnQueue(diceDBCmd *cmd.DiceDBCmd) {
 	c.Cqueue.Cmds = append(c.Cqueue.Cmds, diceDBCmd)
 }
 
+// IdleTime returns the time elapsed since the last command received from the client.
+func (c *Client) IdleTime(now time.Time) time.Duration {
+	return now.Sub(c.LastActive)
+}
+
+// Info describes the client in the CLIENT LIST format, ages and idle times
+// being expressed in seconds.
+func (c *Client) Info(now time.Time) string {
+	flags, sub := "N", 0
+	if c.Subscribed {
+		flags, sub = "P", 1
+	}
+	return fmt.Sprintf("id=%d addr=%s fd=%d age=%d idle=%d flags=%s db=%d sub=%d multi=%d",
+		c.ID, c.Addr, c.Fd,
+		int64(now.Sub(c.CreatedAt).Seconds()), int64(c.IdleTime(now).Seconds()),
+		flags, c.DB, sub, c.multiCount())
+}
+
+// multiCount returns the number of commands queued in a transaction, or -1 if
+// the client is not in a transaction.
+func (c *Client) multiCount() int {
+	if !c.IsTxn {
+		return -1
+	}
+	return len(c.Cqueue.Cmds)
+}
+
 func NewClient(fd int) *Client {
 	cmds := make([]*cmd.DiceDBCmd, 0)
 	return &Client{
@@ -0,0 +1,28 @@
+package comm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"gotest.tools/v3/assert"
+)
+
+func TestClientInfo(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	c := NewClient(8)
+	c.ID = 3
+	c.Addr = "127.0.0.1:50000"
+	c.CreatedAt = now.Add(-10 * time.Second)
+	c.LastActive = now.Add(-2500 * time.Millisecond)
+
+	assert.Equal(t, 2500*time.Millisecond, c.IdleTime(now))
+	assert.Equal(t, "id=3 addr=127.0.0.1:50000 fd=8 age=10 idle=2 flags=N db=0 sub=0 multi=-1", c.Info(now))
+
+	c.Subscribed = true
+	c.DB = 2
+	c.TxnBegin()
+	c.TxnQueue(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}})
+	assert.Equal(t, "id=3 addr=127.0.0.1:50000 fd=8 age=10 idle=2 flags=P db=2 sub=1 multi=1", c.Info(now))
+}
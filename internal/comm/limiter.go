@@ -0,0 +1,56 @@
+package comm
+
+import diceerrors "github.com/dicedb/dice/internal/errors"
+
+// ConnLimiter keeps track of the open connections, globally and per remote IP,
+// and refuses new ones once the configured limits are reached. A limit of 0
+// disables the corresponding check. It is not safe for concurrent use.
+type ConnLimiter struct {
+	maxClients int
+	maxPerIP   int
+	total      int
+	perIP      map[string]int
+}
+
+func NewConnLimiter(maxClients, maxPerIP int) *ConnLimiter {
+	return &ConnLimiter{
+		maxClients: maxClients,
+		maxPerIP:   maxPerIP,
+		perIP:      make(map[string]int),
+	}
+}
+
+// Acquire registers a new connection from ip, or returns an error if it would
+// exceed one of the limits.
+func (l *ConnLimiter) Acquire(ip string) error {
+	if l.maxClients > 0 && l.total >= l.maxClients {
+		return diceerrors.ErrMaxClients
+	}
+	if l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP {
+		return diceerrors.ErrMaxClientsPerIP
+	}
+
+	l.total++
+	l.perIP[ip]++
+	return nil
+}
+
+// Release unregisters a connection from ip previously accepted by Acquire.
+func (l *ConnLimiter) Release(ip string) {
+	n, ok := l.perIP[ip]
+	if !ok {
+		return
+	}
+
+	l.total--
+	if n <= 1 {
+		delete(l.perIP, ip)
+	} else {
+		l.perIP[ip] = n - 1
+	}
+}
+
+// Count returns the number of connections currently open, globally and from ip.
+func (l *ConnLimiter) Count(ip string) (total, fromIP int) {
+	return l.total, l.perIP[ip]
+}
@@ -0,0 +1,45 @@
+package comm
+
+import (
+	"testing"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestConnLimiter(t *testing.T) {
+	t.Run("per IP limit", func(t *testing.T) {
+		l := NewConnLimiter(0, 2)
+		assert.NilError(t, l.Acquire("10.0.0.1"))
+		assert.NilError(t, l.Acquire("10.0.0.1"))
+		assert.ErrorIs(t, l.Acquire("10.0.0.1"), diceerrors.ErrMaxClientsPerIP)
+		assert.NilError(t, l.Acquire("10.0.0.2"))
+
+		l.Release("10.0.0.1")
+		assert.NilError(t, l.Acquire("10.0.0.1"))
+
+		total, fromIP := l.Count("10.0.0.1")
+		assert.Equal(t, 3, total)
+		assert.Equal(t, 2, fromIP)
+	})
+
+	t.Run("global limit", func(t *testing.T) {
+		l := NewConnLimiter(2, 0)
+		assert.NilError(t, l.Acquire("10.0.0.1"))
+		assert.NilError(t, l.Acquire("10.0.0.2"))
+		assert.ErrorIs(t, l.Acquire("10.0.0.3"), diceerrors.ErrMaxClients)
+
+		l.Release("10.0.0.2")
+		assert.NilError(t, l.Acquire("10.0.0.3"))
+	})
+
+	t.Run("releasing an unknown IP", func(t *testing.T) {
+		l := NewConnLimiter(1, 1)
+		assert.NilError(t, l.Acquire("10.0.0.1"))
+		l.Release("10.0.0.2")
+		assert.ErrorIs(t, l.Acquire("10.0.0.3"), diceerrors.ErrMaxClients)
+
+		total, _ := l.Count("10.0.0.2")
+		assert.Equal(t, 1, total)
+	})
+}
@@ -0,0 +1,72 @@
+package comm
+
+import (
+	"sync"
+	"time"
+)
+
+// PauseMode is the set of commands held back by CLIENT PAUSE.
+type PauseMode int
+
+const (
+	PauseNone  PauseMode = iota
+	PauseWrite           // the write commands are held back, the reads being served
+	PauseAll             // all the commands are held back
+)
+
+func (m PauseMode) String() string {
+	switch m {
+	case PauseWrite:
+		return "write"
+	case PauseAll:
+		return "all"
+	}
+	return "none"
+}
+
+// pause is the pause of the clients set by CLIENT PAUSE. It is set by the shard
+// executing the command and read by the server holding back the commands of
+// the clients, hence the lock.
+var pause struct {
+	sync.Mutex
+	mode  PauseMode
+	until time.Time
+}
+
+// PauseClients pauses the clients till until. If the clients are already
+// paused, the pause ends at the latest of both ends and holds back the commands
+// of the most restrictive of both modes, as with Redis.
+func PauseClients(mode PauseMode, until time.Time) {
+	pause.Lock()
+	defer pause.Unlock()
+
+	if pause.mode == PauseNone || !time.Now().Before(pause.until) {
+		pause.mode, pause.until = mode, until
+		return
+	}
+	if until.After(pause.until) {
+		pause.until = until
+	}
+	if mode > pause.mode {
+		pause.mode = mode
+	}
+}
+
+// UnpauseClients ends the pause of the clients right away.
+func UnpauseClients() {
+	pause.Lock()
+	defer pause.Unlock()
+	pause.mode, pause.until = PauseNone, time.Time{}
+}
+
+// ClientPause returns the mode of the pause of the clients at now, along with
+// the time it ends. The mode is PauseNone once the pause ended.
+func ClientPause(now time.Time) (PauseMode, time.Time) {
+	pause.Lock()
+	defer pause.Unlock()
+
+	if pause.mode == PauseNone || !now.Before(pause.until) {
+		return PauseNone, time.Time{}
+	}
+	return pause.mode, pause.until
+}
@@ -0,0 +1,45 @@
+package comm
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestClientPause(t *testing.T) {
+	defer UnpauseClients()
+	now := time.Now()
+
+	mode, _ := ClientPause(now)
+	assert.Equal(t, PauseNone, mode)
+
+	PauseClients(PauseWrite, now.Add(time.Minute))
+	mode, until := ClientPause(now)
+	assert.Equal(t, PauseWrite, mode)
+	assert.Equal(t, now.Add(time.Minute), until)
+
+	// the pause ends at the latest end, holding back the most restrictive mode
+	PauseClients(PauseAll, now.Add(time.Second))
+	mode, until = ClientPause(now)
+	assert.Equal(t, PauseAll, mode)
+	assert.Equal(t, now.Add(time.Minute), until)
+	PauseClients(PauseWrite, now.Add(time.Hour))
+	mode, until = ClientPause(now)
+	assert.Equal(t, PauseAll, mode)
+	assert.Equal(t, now.Add(time.Hour), until)
+
+	mode, _ = ClientPause(now.Add(2 * time.Hour))
+	assert.Equal(t, PauseNone, mode)
+
+	UnpauseClients()
+	mode, _ = ClientPause(now)
+	assert.Equal(t, PauseNone, mode)
+
+	// a pause that ended is replaced
+	PauseClients(PauseAll, time.Now().Add(-time.Second))
+	PauseClients(PauseWrite, now.Add(time.Minute))
+	mode, _ = ClientPause(now)
+	assert.Equal(t, PauseWrite, mode)
+	assert.Equal(t, "write", mode.String())
+}
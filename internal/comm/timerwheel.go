@@ -0,0 +1,89 @@
+package comm
+
+import "time"
+
+// TimerWheel is a hashed timing wheel keeping one timer per file descriptor.
+// Scheduling, rescheduling and cancelling a timer are O(1), which makes it cheap
+// to push back the idle timer of a client every time it sends a command.
+//
+// The wheel is made of `size` slots, each covering `tick`. A timer lands in the
+// slot it expires in, along with the number of full rotations to wait for when
+// it is further away than one rotation. The wheel is not safe for concurrent use.
+type TimerWheel struct {
+	tick    time.Duration
+	slots   []map[int]*wheelTimer
+	timers  map[int]*wheelTimer
+	pos     int
+	current time.Time // start of the current tick
+}
+
+type wheelTimer struct {
+	slot   int
+	rounds int
+}
+
+// NewTimerWheel creates a wheel of `size` slots of `tick` each, starting at now.
+func NewTimerWheel(tick time.Duration, size int, now time.Time) *TimerWheel {
+	slots := make([]map[int]*wheelTimer, size)
+	for i := range slots {
+		slots[i] = make(map[int]*wheelTimer)
+	}
+	return &TimerWheel{
+		tick:    tick,
+		slots:   slots,
+		timers:  make(map[int]*wheelTimer),
+		current: now,
+	}
+}
+
+// Schedule sets the timer of fd to fire after d, replacing any pending one.
+// Durations are rounded up to the tick.
+func (w *TimerWheel) Schedule(fd int, d time.Duration) {
+	w.Cancel(fd)
+
+	ticks := int((d + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	t := &wheelTimer{
+		slot:   (w.pos + ticks) % len(w.slots),
+		rounds: (ticks - 1) / len(w.slots),
+	}
+	w.slots[t.slot][fd] = t
+	w.timers[fd] = t
+}
+
+// Cancel removes the pending timer of fd, if any.
+func (w *TimerWheel) Cancel(fd int) {
+	if t, ok := w.timers[fd]; ok {
+		delete(w.slots[t.slot], fd)
+		delete(w.timers, fd)
+	}
+}
+
+// Len returns the number of pending timers.
+func (w *TimerWheel) Len() int {
+	return len(w.timers)
+}
+
+// Advance moves the wheel forward up to now and returns the file descriptors
+// whose timer fired. Fired timers are removed from the wheel.
+func (w *TimerWheel) Advance(now time.Time) []int {
+	var fired []int
+	for !now.Before(w.current.Add(w.tick)) {
+		w.current = w.current.Add(w.tick)
+		w.pos = (w.pos + 1) % len(w.slots)
+
+		for fd, t := range w.slots[w.pos] {
+			if t.rounds > 0 {
+				t.rounds--
+				continue
+			}
+			delete(w.slots[w.pos], fd)
+			delete(w.timers, fd)
+			fired = append(fired, fd)
+		}
+	}
+	return fired
+}
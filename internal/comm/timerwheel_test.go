@@ -0,0 +1,66 @@
+package comm
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestTimerWheel(t *testing.T) {
+	start := time.Unix(1000, 0)
+	tick := 100 * time.Millisecond
+
+	t.Run("fires after the duration", func(t *testing.T) {
+		w := NewTimerWheel(tick, 10, start)
+		w.Schedule(1, 250*time.Millisecond)
+		w.Schedule(2, 500*time.Millisecond)
+
+		assert.Equal(t, 0, len(w.Advance(start.Add(200*time.Millisecond))))
+		assert.DeepEqual(t, []int{1}, w.Advance(start.Add(300*time.Millisecond)))
+		assert.DeepEqual(t, []int{2}, w.Advance(start.Add(time.Second)))
+		assert.Equal(t, 0, w.Len())
+	})
+
+	t.Run("timers longer than a rotation", func(t *testing.T) {
+		w := NewTimerWheel(tick, 10, start)
+		w.Schedule(1, 2500*time.Millisecond)
+
+		assert.Equal(t, 0, len(w.Advance(start.Add(2400*time.Millisecond))))
+		assert.DeepEqual(t, []int{1}, w.Advance(start.Add(2500*time.Millisecond)))
+	})
+
+	t.Run("rescheduling pushes back the timer", func(t *testing.T) {
+		w := NewTimerWheel(tick, 10, start)
+		w.Schedule(1, 300*time.Millisecond)
+		assert.Equal(t, 0, len(w.Advance(start.Add(200*time.Millisecond))))
+
+		w.Schedule(1, 300*time.Millisecond)
+		assert.Equal(t, 1, w.Len())
+		assert.Equal(t, 0, len(w.Advance(start.Add(400*time.Millisecond))))
+		assert.DeepEqual(t, []int{1}, w.Advance(start.Add(500*time.Millisecond)))
+	})
+
+	t.Run("cancelled timers do not fire", func(t *testing.T) {
+		w := NewTimerWheel(tick, 10, start)
+		w.Schedule(1, tick)
+		w.Schedule(2, tick)
+		w.Cancel(1)
+		w.Cancel(3)
+
+		assert.DeepEqual(t, []int{2}, w.Advance(start.Add(time.Second)))
+	})
+
+	t.Run("several timers in the same slot", func(t *testing.T) {
+		w := NewTimerWheel(tick, 4, start)
+		w.Schedule(1, tick)
+		w.Schedule(2, 5*tick) // same slot, one more rotation
+		w.Schedule(3, tick)
+
+		fired := w.Advance(start.Add(tick))
+		sort.Ints(fired)
+		assert.DeepEqual(t, []int{1, 3}, fired)
+		assert.DeepEqual(t, []int{2}, w.Advance(start.Add(5*tick)))
+	})
+}
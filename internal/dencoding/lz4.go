@@ -0,0 +1,170 @@
+package dencoding
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Implementation of the LZ4 block format.
+// Reference: https://github.com/lz4/lz4/blob/dev/doc/lz4_Block_format.md
+//
+// A block is a series of sequences, each made of a token, the literals and a
+// match (offset + length) into the already decoded data. The last sequence
+// only holds literals.
+
+const (
+	lz4MinMatch     = 4
+	lz4MFLimit      = 12 // the last match must start at least 12 bytes before the end of the block
+	lz4LastLiterals = 5  // the last 5 bytes of the block are always literals
+	lz4MaxOffset    = 65535
+	lz4HashLog      = 14
+)
+
+var ErrCorruptLZ4 = errors.New("lz4: corrupt input")
+
+// CompressLZ4 compresses src into an LZ4 block. The size of src must be known
+// by the caller to decompress the block, see DecompressLZ4.
+func CompressLZ4(src []byte) []byte {
+	dst := make([]byte, 0, len(src)/2+16)
+	table := make([]int32, 1<<lz4HashLog)
+
+	anchor := 0
+	limit := len(src) - lz4MFLimit
+	for i := 0; i < limit; {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := (seq * 2654435761) >> (32 - lz4HashLog)
+		// positions are stored off by one, so that 0 marks an empty slot
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if ref < 0 || i-ref > lz4MaxOffset || binary.LittleEndian.Uint32(src[ref:]) != seq {
+			i++
+			continue
+		}
+
+		matchEnd := i + lz4MinMatch
+		maxEnd := len(src) - lz4LastLiterals
+		for matchEnd < maxEnd && src[matchEnd] == src[ref+matchEnd-i] {
+			matchEnd++
+		}
+		for i > anchor && ref > 0 && src[i-1] == src[ref-1] {
+			i--
+			ref--
+		}
+
+		dst = appendLZ4Sequence(dst, src[anchor:i], i-ref, matchEnd-i)
+		i = matchEnd
+		anchor = i
+	}
+
+	return appendLZ4Literals(dst, src[anchor:])
+}
+
+// DecompressLZ4 decompresses an LZ4 block whose decompressed size is `size`.
+func DecompressLZ4(src []byte, size int) ([]byte, error) {
+	dst := make([]byte, 0, size)
+
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		litLen := int(token >> 4)
+		if litLen == 0xF {
+			n, next, err := readLZ4Length(src, i)
+			if err != nil {
+				return nil, err
+			}
+			litLen += n
+			i = next
+		}
+		if i+litLen > len(src) || len(dst)+litLen > size {
+			return nil, ErrCorruptLZ4
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+
+		// the last sequence has no match
+		if i == len(src) {
+			break
+		}
+
+		if i+2 > len(src) {
+			return nil, ErrCorruptLZ4
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, ErrCorruptLZ4
+		}
+
+		matchLen := int(token & 0xF)
+		if matchLen == 0xF {
+			n, next, err := readLZ4Length(src, i)
+			if err != nil {
+				return nil, err
+			}
+			matchLen += n
+			i = next
+		}
+		matchLen += lz4MinMatch
+		if len(dst)+matchLen > size {
+			return nil, ErrCorruptLZ4
+		}
+
+		// the match may overlap with the bytes being written, hence the byte by byte copy
+		start := len(dst) - offset
+		for k := 0; k < matchLen; k++ {
+			dst = append(dst, dst[start+k])
+		}
+	}
+
+	if len(dst) != size {
+		return nil, ErrCorruptLZ4
+	}
+	return dst, nil
+}
+
+func appendLZ4Sequence(dst, literals []byte, offset, matchLen int) []byte {
+	litLen := len(literals)
+	matchLen -= lz4MinMatch
+
+	dst = append(dst, byte(min(litLen, 0xF))<<4|byte(min(matchLen, 0xF)))
+	if litLen >= 0xF {
+		dst = appendLZ4Length(dst, litLen-0xF)
+	}
+	dst = append(dst, literals...)
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if matchLen >= 0xF {
+		dst = appendLZ4Length(dst, matchLen-0xF)
+	}
+	return dst
+}
+
+func appendLZ4Literals(dst, literals []byte) []byte {
+	litLen := len(literals)
+	dst = append(dst, byte(min(litLen, 0xF))<<4)
+	if litLen >= 0xF {
+		dst = appendLZ4Length(dst, litLen-0xF)
+	}
+	return append(dst, literals...)
+}
+
+func appendLZ4Length(dst []byte, n int) []byte {
+	for ; n >= 0xFF; n -= 0xFF {
+		dst = append(dst, 0xFF)
+	}
+	return append(dst, byte(n))
+}
+
+func readLZ4Length(src []byte, i int) (n, next int, err error) {
+	for {
+		if i >= len(src) {
+			return 0, i, ErrCorruptLZ4
+		}
+		b := src[i]
+		i++
+		n += int(b)
+		if b != 0xFF {
+			return n, i, nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package dencoding_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/internal/dencoding"
+	"gotest.tools/v3/assert"
+)
+
+func TestLZ4RoundTrip(t *testing.T) {
+	random := make([]byte, 70000)
+	rand.New(rand.NewSource(42)).Read(random)
+
+	tests := map[string][]byte{
+		"empty":            {},
+		"single byte":      []byte("a"),
+		"short":            []byte("hello world"),
+		"repeated byte":    bytes.Repeat([]byte{'x'}, 10000),
+		"repeated pattern": []byte(strings.Repeat("dicedb is fast! ", 1000)),
+		"long literals":    random[:300],
+		"random":           random,
+		"mixed":            append(append([]byte(strings.Repeat("abc", 200)), random[:1000]...), []byte(strings.Repeat("abc", 200))...),
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			compressed := dencoding.CompressLZ4(src)
+			decompressed, err := dencoding.DecompressLZ4(compressed, len(src))
+			assert.NilError(t, err)
+			assert.DeepEqual(t, src, decompressed)
+		})
+	}
+}
+
+func TestLZ4CompressesRepetitiveData(t *testing.T) {
+	src := []byte(strings.Repeat("dicedb is fast! ", 1000))
+	assert.Assert(t, len(dencoding.CompressLZ4(src)) < len(src)/10)
+}
+
+func TestLZ4DecompressCorruptInput(t *testing.T) {
+	src := []byte(strings.Repeat("dicedb is fast! ", 100))
+	compressed := dencoding.CompressLZ4(src)
+
+	tests := map[string]struct {
+		input []byte
+		size  int
+	}{
+		"truncated":      {compressed[:len(compressed)/2], len(src)},
+		"wrong size":     {compressed, len(src) - 1},
+		"zero offset":    {[]byte{0x10, 'a', 0x00, 0x00}, 5},
+		"offset beyond":  {[]byte{0x10, 'a', 0x05, 0x00}, 5},
+		"missing length": {[]byte{0xF0}, 20},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := dencoding.DecompressLZ4(tc.input, tc.size)
+			assert.ErrorIs(t, err, dencoding.ErrCorruptLZ4)
+		})
+	}
+}
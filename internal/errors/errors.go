@@ -28,6 +28,11 @@ const (
 	InvalidBitfieldType    = "-ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."
 	BitfieldOffsetErr      = "-ERR bit offset is not an integer or out of range"
 	OverflowTypeErr        = "-ERR Invalid OVERFLOW type specified"
+	ScoreNaNErr            = "resulting score is not a number (NaN)"
+	ReadOnlyErr            = "-READONLY You can't write against a read only replica."
+	StaleReplicaErr        = "-STALE The replica has not heard from its primary within the max staleness, read from the primary instead."
+	TokenTimeoutErr        = "-TRYAGAIN The replica has not caught up with the session token in time, retry or read from the primary instead."
+	OOMErr                 = "-OOM command not allowed when used memory > 'maxmemory'."
 )
 
 type DiceError struct {
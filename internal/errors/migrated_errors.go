@@ -30,6 +30,13 @@ var (
 	ErrAborted                    = errors.New("server received ABORT command")
 	ErrEmptyCommand               = errors.New("empty command")
 	ErrInvalidIPAddress           = errors.New("invalid IP address")
+	ErrMaxClients                 = errors.New("ERR max number of clients reached")                       // Returned to the connections refused because of the maxclients limit.
+	ErrMaxClientsPerIP            = errors.New("ERR max number of clients per IP reached")                // Returned to the connections refused because of the per-IP limit.
+	ErrCrossSlot                  = errors.New("CROSSSLOT Keys in request don't hash to the same slot")   // Returned to the commands whose keys map to different shards.
+	ErrDBIndexOutOfRange          = errors.New("ERR DB index is out of range")                            // Returned for the logical databases beyond the number configured.
+	ErrSameObject                 = errors.New("ERR source and destination objects are the same")         // Returned by MOVE for the database the key is already in.
+	ErrOOM                        = errors.New("OOM command not allowed when used memory > 'maxmemory'.") // Returned to the write commands once maxmemory is reached and nothing can be evicted.
+	ErrReadOnly                   = errors.New("READONLY You can't write against a read only replica.")   // Returned to the write commands run against the dataset of a replica.
 
 	// Error generation functions for specific error messages with dynamic parameters.
 	ErrWrongArgumentCount = func(command string) error {
@@ -0,0 +1,89 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// evalACL manages the users and their permissions.
+// ACL SETUSER username [rule ...] creates or modifies a user, see
+// auth.Users.SetUser for the rules. The connections of the users deleted or
+// disabled are killed or downgraded, as told by the revoke policy.
+// ACL DELUSER username [username ...] deletes users.
+// ACL USERS and ACL LIST return the names of the users, and their rules.
+// ACL LOG [count | RESET] returns the latest commands denied, or forgets them.
+func evalACL(args []string, store *dstore.Store) []byte {
+	switch strings.ToUpper(args[0]) {
+	case SetUser:
+		if len(args) < 2 {
+			return diceerrors.NewErrArity("ACL|SETUSER")
+		}
+		if err := auth.UserStore.SetUser(args[1], args[2:]...); err != nil {
+			return clientio.Encode(err, false)
+		}
+		return clientio.RespOK
+	case DelUser:
+		if len(args) < 2 {
+			return diceerrors.NewErrArity("ACL|DELUSER")
+		}
+		return clientio.Encode(auth.UserStore.Delete(args[1:]...), false)
+	case Users:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("ACL|USERS")
+		}
+		return clientio.Encode(auth.UserStore.Names(), false)
+	case List:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("ACL|LIST")
+		}
+		return clientio.Encode(auth.UserStore.List(), false)
+	case Log:
+		return evalACLLog(args[1:])
+	case Help:
+		return commandHelp("ACL")
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try ACL HELP.", args[0])
+	}
+}
+
+func evalACLLog(args []string) []byte {
+	if len(args) > 1 {
+		return diceerrors.NewErrArity("ACL|LOG")
+	}
+
+	count := -1
+	if len(args) == 1 {
+		if strings.EqualFold(args[0], Reset) {
+			auth.ACLLog.Reset()
+			return clientio.RespOK
+		}
+		var err error
+		if count, err = strconv.Atoi(args[0]); err != nil || count < 0 {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+	}
+
+	now := time.Now()
+	entries := auth.ACLLog.Entries(count)
+	reply := make([]interface{}, len(entries))
+	for i, e := range entries {
+		reply[i] = []interface{}{
+			"count", e.Count,
+			"reason", e.Reason,
+			"object", e.Object,
+			"username", e.Username,
+			"age-seconds", strconv.FormatFloat(now.Sub(e.CreatedAt).Seconds(), 'f', 3, 64),
+			"client-info", e.ClientInfo,
+			"entry-id", e.ID,
+			"timestamp-created", e.CreatedAt.UnixMilli(),
+			"timestamp-last-updated", e.UpdatedAt.UnixMilli(),
+		}
+	}
+	return clientio.Encode(reply, false)
+}
@@ -30,6 +30,9 @@ func evalBITPOS(args []string, store *dstore.Store) []byte {
 
 		return clientio.Encode(-1, true)
 	}
+	if !isStringObj(obj) {
+		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
 
 	byteSlice, err := getValueAsByteSlice(obj)
 	if err != nil {
@@ -0,0 +1,39 @@
+package eval
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// Blocked describes what a blocking command, e.g. BLPOP, waits for when it
+// cannot be served yet. The shard evaluates the command again whenever one of
+// its keys is modified, till it is served or times out.
+//
+// The clients that cannot wait, e.g. the ones of the HTTP server, get the reply
+// of a timed out command right away.
+type Blocked struct {
+	Keys    []string      // Keys are the keys whose modification may let the command be served
+	Timeout time.Duration // Timeout is the time the command blocks for, 0 meaning forever
+}
+
+// IsBlockingCommand returns true if the command may block its client.
+func IsBlockingCommand(name string) bool {
+	diceCmd, ok := DiceCmds[name]
+	return ok && diceCmd.BlockingEval != nil
+}
+
+// parseBlockingTimeout parses the timeout of a blocking command, in seconds with
+// an optional decimal part.
+func parseBlockingTimeout(arg string) (time.Duration, []byte) {
+	seconds, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds*float64(time.Second) > math.MaxInt64 {
+		return 0, diceerrors.NewErrWithMessage("timeout is not a float or out of range")
+	}
+	if seconds < 0 {
+		return 0, diceerrors.NewErrWithMessage("timeout is negative")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
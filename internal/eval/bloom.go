@@ -2,23 +2,28 @@ package eval
 
 import (
 	"fmt"
-	"hash"
 	"math"
-	"math/rand"
 	"strconv"
+	"strings"
 
 	"github.com/dicedb/dice/internal/object"
 
 	"github.com/dicedb/dice/internal/clientio"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hashing"
 	"github.com/dicedb/dice/internal/server/utils"
 	dstore "github.com/dicedb/dice/internal/store"
-	"github.com/twmb/murmur3"
 )
 
 const (
 	defaultErrorRate float64 = 0.01
 	defaultCapacity  uint64  = 1024
+	defaultExpansion uint64  = 2
+
+	// tighteningRatio is the ratio between the error rates of a sub-filter and
+	// of the previous one, so that the error rate of the whole filter converges
+	// to twice the one of its first sub-filter however many are added.
+	tighteningRatio float64 = 0.5
 )
 
 var (
@@ -31,38 +36,58 @@ var (
 	errInvalidErrorRate     = diceerrors.NewErr("invalid error rate value provided")
 	errInvalidCapacityType  = diceerrors.NewErr("only integer values can be provided for capacity")
 	errInvalidCapacity      = diceerrors.NewErr("invalid capacity value provided")
+	errInvalidExpansion     = diceerrors.NewErr("invalid expansion value provided")
+	errNonScalingExpansion  = diceerrors.NewErr("nonscaling filters cannot expand")
+
+	errBloomKeyExists = diceerrors.NewErr("item exists")
+	errBloomFull      = diceerrors.NewErr("non scaling filter is full")
 
 	errInvalidKey = diceerrors.NewErr("invalid key: no bloom filter found")
 
-	errEmptyValue   = diceerrors.NewErr("empty value provided")
-	errUnableToHash = diceerrors.NewErr("unable to hash given value")
+	errEmptyValue = diceerrors.NewErr("empty value provided")
+
+	errNoCreateOpts = diceerrors.NewErr("NOCREATE cannot be used with CAPACITY or ERROR")
 )
 
 type BloomOpts struct {
 	errorRate float64 // desired error rate (the false positive rate) of the filter
 	capacity  uint64  // number of expected entries to be added to the filter
 
-	bits    uint64        // total number of bits reserved for the filter
-	hashFns []hash.Hash64 // array of hash functions
-	bpe     float64       // bits per element
+	expansion  uint64 // growth factor of the capacity of the filters added once full
+	nonScaling bool   // whether the filter refuses to grow once full
+
+	bits   uint64         // total number of bits reserved for the filter
+	hasher hashing.Hasher // hasher deriving the indexes of the bits of a value
+	bpe    float64        // bits per element
 
 	// indexes slice will hold the indexes, representing bits to be set/read and
 	// is under the assumption that it's consumed at only 1 place at a time. Add
-	// a lock when multiple clients can be supported.
+	// a lock when multiple clients can be supported. Its length is the number
+	// of hash functions.
 	indexes []uint64
 }
 
+// Bloom is a scalable bloom filter. Once its capacity is reached, a sub-filter
+// with `expansion` times the capacity and a tighter error rate is stacked on
+// top of it to receive the new entries, so that the error rate does not
+// degrade as the filter grows. The filter itself is the first sub-filter.
 type Bloom struct {
 	opts   *BloomOpts // options for the bloom filter
 	bitset []byte     // underlying bit representation
+	count  uint64     // number of entries added to the filter
+
+	// stacked holds the sub-filters added once full, the last one receiving
+	// the new entries. The stacked sub-filters have no sub-filters of their own.
+	stacked []*Bloom
 }
 
-// newBloomOpts extracts the user defined values from `args`. It falls back to
-// default values if `useDefaults` is set to true. Using those values, it
-// creates and returns the options for bloom filter.
+// newBloomOpts extracts the user defined values from `args`: the error rate,
+// the capacity and optionally the EXPANSION and NONSCALING flags. It falls
+// back to default values if `useDefaults` is set to true. Using those values,
+// it creates and returns the options for bloom filter.
 func newBloomOpts(args []string, useDefaults bool) (*BloomOpts, error) {
 	if useDefaults {
-		return &BloomOpts{errorRate: defaultErrorRate, capacity: defaultCapacity}, nil
+		return &BloomOpts{errorRate: defaultErrorRate, capacity: defaultCapacity, expansion: defaultExpansion}, nil
 	}
 
 	errorRate, err := strconv.ParseFloat(args[0], 64)
@@ -83,7 +108,33 @@ func newBloomOpts(args []string, useDefaults bool) (*BloomOpts, error) {
 		return nil, errInvalidCapacity
 	}
 
-	return &BloomOpts{errorRate: errorRate, capacity: capacity}, nil
+	opts := &BloomOpts{errorRate: errorRate, capacity: capacity, expansion: defaultExpansion}
+	expansionSet := false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "EXPANSION":
+			if i+1 == len(args) {
+				return nil, diceerrors.NewErr(diceerrors.SyntaxErr)
+			}
+			i++
+			expansion, err := strconv.ParseUint(args[i], 10, 64)
+			if err != nil || expansion < 1 {
+				return nil, errInvalidExpansion
+			}
+			opts.expansion = expansion
+			expansionSet = true
+		case "NONSCALING":
+			opts.nonScaling = true
+		default:
+			return nil, diceerrors.NewErr(diceerrors.SyntaxErr)
+		}
+	}
+
+	if opts.nonScaling && expansionSet {
+		return nil, errNonScalingExpansion
+	}
+
+	return opts, nil
 }
 
 // newBloomFilter creates and returns a new filter. It is responsible for initializing the
@@ -97,15 +148,10 @@ func newBloomFilter(opts *BloomOpts) *Bloom {
 	// Calculate the number of hash functions to be used
 	// 		k = ceil(ln(2) * bpe)
 	k := math.Ceil(ln2 * opts.bpe)
-	opts.hashFns = make([]hash.Hash64, int(k))
-
-	// Initialize hash functions with random seeds
-	for i := 0; i < int(k); i++ {
-		opts.hashFns[i] = murmur3.SeedNew64(rand.Uint64()) //nolint:gosec
-	}
+	opts.hasher = sketchHasher()
 
 	// initialize the common slice for storing indexes of bits to be set
-	opts.indexes = make([]uint64, len(opts.hashFns))
+	opts.indexes = make([]uint64, int(k))
 
 	// Calculate the number of bytes to be used
 	// 		bits = k * entries / ln(2)
@@ -121,7 +167,7 @@ func newBloomFilter(opts *BloomOpts) *Bloom {
 
 	bitset := make([]byte, bytes)
 
-	return &Bloom{opts, bitset}
+	return &Bloom{opts: opts, bitset: bitset}
 }
 
 func (b *Bloom) info(name string) string {
@@ -129,81 +175,136 @@ func (b *Bloom) info(name string) string {
 	if name != utils.EmptyStr {
 		info = "name: " + name + ", "
 	}
+	bits, capacity := b.opts.bits, b.opts.capacity
+	for _, sub := range b.stacked {
+		bits += sub.opts.bits
+		capacity += sub.opts.capacity
+	}
 	info += fmt.Sprintf("error rate: %f, ", b.opts.errorRate)
 	info += fmt.Sprintf("capacity: %d, ", b.opts.capacity)
-	info += fmt.Sprintf("total bits reserved: %d, ", b.opts.bits)
+	info += fmt.Sprintf("total bits reserved: %d, ", bits)
 	info += fmt.Sprintf("bits per element: %f, ", b.opts.bpe)
-	info += fmt.Sprintf("hash functions: %d", len(b.opts.hashFns))
+	info += fmt.Sprintf("hash functions: %d, ", len(b.opts.indexes))
+	info += fmt.Sprintf("sub-filters: %d, ", 1+len(b.stacked))
+	info += fmt.Sprintf("total capacity: %d, ", capacity)
+	info += fmt.Sprintf("items inserted: %d", b.card())
 
 	return info
 }
 
 // add adds a new entry for `value` in the filter. It hashes the given
-// value and sets the bit of the underlying bitset. Returns "-1" in
-// case of errors, "0" if all the bits were already set and "1" if
-// atleast 1 new bit was set.
-func (b *Bloom) add(value string) ([]byte, error) {
+// value and sets the bits of the underlying bitset of the last sub-filter,
+// stacking a new one first if it is full. Returns false if the value may
+// already exist in the filter and true if it was added.
+func (b *Bloom) add(value string) (bool, error) {
 	// We're sure that empty values will be handled upper functions itself.
 	// This is just a property check for the bloom struct.
 	if value == utils.EmptyStr {
-		return clientio.RespMinusOne, errEmptyValue
+		return false, errEmptyValue
 	}
 
-	// Update the indexes where bits are supposed to be set
-	err := b.opts.updateIndexes(value)
-	if err != nil {
-		fmt.Println("error in getting indexes for value:", value, "err:", err)
-		return clientio.RespMinusOne, errUnableToHash
+	if b.contains(value) {
+		// All the bits were already set in a sub-filter, nothing to add.
+		return false, nil
 	}
 
-	// Set the bits and keep a count of already set ones
-	count := 0
-	for _, v := range b.opts.indexes {
-		if isBitSet(b.bitset, v) {
-			count++
-		} else {
-			setBit(b.bitset, v)
+	last := b.last()
+	if last.count >= last.opts.capacity {
+		if b.opts.nonScaling {
+			return false, errBloomFull
 		}
+		last = b.grow()
 	}
 
-	if count == len(b.opts.indexes) {
-		// All the bits were already set, return 0 in that case.
-		return clientio.RespZero, nil
+	// Update the indexes where bits are supposed to be set, and set them
+	last.opts.updateIndexes(value)
+	for _, v := range last.opts.indexes {
+		setBit(last.bitset, v)
 	}
+	last.count++
 
-	return clientio.RespOne, nil
+	return true, nil
 }
 
 // exists checks if the given `value` exists in the filter or not.
 // It hashes the given value and checks if the bits are set or not in
-// the underlying bitset. Returns "-1" in case of errors, "0" if the
+// the underlying bitsets. Returns "-1" in case of errors, "0" if the
 // element surely does not exist in the filter, and "1" if the element
 // may or may not exist in the filter.
-func (b *Bloom) exists(value string) ([]byte, error) {
+func (b *Bloom) exists(value string) (bool, error) {
 	// We're sure that empty values will be handled upper functions itself.
 	// This is just a property check for the bloom struct.
 	if value == utils.EmptyStr {
-		return clientio.RespMinusOne, errEmptyValue
+		return false, errEmptyValue
 	}
 
-	// Update the indexes where bits are supposed to be set
-	err := b.opts.updateIndexes(value)
-	if err != nil {
-		fmt.Println("error in getting indexes for value:", value, "err:", err)
-		return clientio.RespMinusOne, errUnableToHash
+	// The element may exist in the filter if all its bits are set.
+	return b.contains(value), nil
+}
+
+// contains returns true if all the bits of `value` are set in any of the
+// sub-filters.
+func (b *Bloom) contains(value string) bool {
+	if b.containsOwn(value) {
+		return true
 	}
+	for _, sub := range b.stacked {
+		if sub.containsOwn(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsOwn returns true if all the bits of `value` are set in the bitset
+// of the sub-filter b, leaving aside its stacked sub-filters.
+func (b *Bloom) containsOwn(value string) bool {
+	// Update the indexes where bits are supposed to be set
+	b.opts.updateIndexes(value)
 
 	// Check if all the bits at given indexes are set or not
 	// Ideally if the element is present, we should find all set bits.
 	for _, v := range b.opts.indexes {
 		if !isBitSet(b.bitset, v) {
-			// Return with "0" as we found one non-set bit (which is enough to conclude)
-			return clientio.RespZero, nil
+			// Return with false as we found one non-set bit (which is enough to conclude)
+			return false
 		}
 	}
+	return true
+}
+
+// card returns the number of entries added to the filter and its stacked
+// sub-filters. The entries found to possibly exist already when added, false
+// positives included, are not counted.
+func (b *Bloom) card() uint64 {
+	count := b.count
+	for _, sub := range b.stacked {
+		count += sub.count
+	}
+	return count
+}
 
-	// We reached here, which means the element may exist in the filter. Return "1" now.
-	return clientio.RespOne, nil
+// last returns the sub-filter receiving the new entries.
+func (b *Bloom) last() *Bloom {
+	if len(b.stacked) == 0 {
+		return b
+	}
+	return b.stacked[len(b.stacked)-1]
+}
+
+// grow stacks a new sub-filter on top of the last one, with `expansion` times
+// its capacity and `tighteningRatio` times its error rate, and returns it. The
+// sub-filters share the hasher of the filter.
+func (b *Bloom) grow() *Bloom {
+	last := b.last()
+	sub := newBloomFilter(&BloomOpts{
+		errorRate: last.opts.errorRate * tighteningRatio,
+		capacity:  last.opts.capacity * b.opts.expansion,
+		expansion: b.opts.expansion,
+	})
+	sub.opts.hasher = b.opts.hasher
+	b.stacked = append(b.stacked, sub)
+	return sub
 }
 
 // DeepCopy creates a deep copy of the Bloom struct
@@ -214,17 +315,16 @@ func (b *Bloom) DeepCopy() *Bloom {
 
 	// Copy the BloomOpts
 	copyOpts := &BloomOpts{
-		errorRate: b.opts.errorRate,
-		capacity:  b.opts.capacity,
-		bits:      b.opts.bits,
-		bpe:       b.opts.bpe,
-		hashFns:   make([]hash.Hash64, len(b.opts.hashFns)),
-		indexes:   make([]uint64, len(b.opts.indexes)),
+		errorRate:  b.opts.errorRate,
+		capacity:   b.opts.capacity,
+		expansion:  b.opts.expansion,
+		nonScaling: b.opts.nonScaling,
+		bits:       b.opts.bits,
+		bpe:        b.opts.bpe,
+		hasher:     b.opts.hasher,
+		indexes:    make([]uint64, len(b.opts.indexes)),
 	}
 
-	// Deep copy the hash functions (assuming they are shallow copyable)
-	copy(copyOpts.hashFns, b.opts.hashFns)
-
 	// Deep copy the indexes slice
 	copy(copyOpts.indexes, b.opts.indexes)
 
@@ -232,30 +332,26 @@ func (b *Bloom) DeepCopy() *Bloom {
 	copyBitset := make([]byte, len(b.bitset))
 	copy(copyBitset, b.bitset)
 
+	// Deep copy the stacked sub-filters
+	var copyStacked []*Bloom
+	for _, sub := range b.stacked {
+		copyStacked = append(copyStacked, sub.DeepCopy())
+	}
+
 	return &Bloom{
-		opts:   copyOpts,
-		bitset: copyBitset,
+		opts:    copyOpts,
+		bitset:  copyBitset,
+		count:   b.count,
+		stacked: copyStacked,
 	}
 }
 
 // updateIndexes updates the list with indexes where bits are supposed to be
-// set (to 1) or read in/from the underlying array. It uses the set hash function
-// against the given `value` and caps the index with the total number of bits.
-func (opts *BloomOpts) updateIndexes(value string) error {
-	// Iterate through the hash functions and get indexes
-	for i := 0; i < len(opts.hashFns); i++ {
-		fn := opts.hashFns[i]
-		fn.Reset()
-
-		if _, err := fn.Write([]byte(value)); err != nil {
-			return err
-		}
-
-		// Save the index capped by total number of bits in the underlying array
-		opts.indexes[i] = fn.Sum64() % opts.bits
-	}
-
-	return nil
+// set (to 1) or read in/from the underlying array. The indexes are derived
+// from two hashes of the given `value` by double hashing, and capped with the
+// total number of bits.
+func (opts *BloomOpts) updateIndexes(value string) {
+	opts.hasher.Indexes(opts.indexes, value, opts.bits)
 }
 
 // evalBFINIT evaluates the BFINIT command responsible for initializing a
@@ -273,17 +369,38 @@ func evalBFINIT(args []string, store *dstore.Store) []byte {
 
 	opts, err := newBloomOpts(args[1:], useDefaults)
 	if err != nil {
-		return diceerrors.NewErrWithFormattedMessage("%w for 'BFINIT' command", err)
+		return probabilisticErr("BFINIT", err)
 	}
 
 	_, err = getOrCreateBloomFilter(args[0], opts, store)
 	if err != nil {
-		return diceerrors.NewErrWithFormattedMessage("%w for 'BFINIT' command", err)
+		return probabilisticErr("BFINIT", err)
 	}
 
 	return clientio.RespOK
 }
 
+// evalBFRESERVE evaluates the BF.RESERVE command responsible for creating an
+// empty bloom filter with the given error rate and capacity. Unlike BFINIT, it
+// fails if the key already exists.
+func evalBFRESERVE(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("BF.RESERVE")
+	}
+
+	opts, err := newBloomOpts(args[1:], false)
+	if err != nil {
+		return probabilisticErr("BF.RESERVE", err)
+	}
+
+	if store.Get(args[0]) != nil {
+		return probabilisticErr("BF.RESERVE", errBloomKeyExists)
+	}
+	store.Put(args[0], store.NewObj(newBloomFilter(opts), -1, object.ObjTypeBitSet, object.ObjEncodingBF))
+
+	return clientio.RespOK
+}
+
 // evalBFADD evaluates the BFADD command responsible for adding an element to a bloom filter. If the filter does not
 // exist, it will create a new one with default parameters.
 func evalBFADD(args []string, store *dstore.Store) []byte {
@@ -295,15 +412,15 @@ func evalBFADD(args []string, store *dstore.Store) []byte {
 
 	bloom, err := getOrCreateBloomFilter(args[0], opts, store)
 	if err != nil {
-		return diceerrors.NewErrWithFormattedMessage("%w for 'BFADD' command", err)
+		return probabilisticErr("BFADD", err)
 	}
 
-	resp, err := bloom.add(args[1])
+	added, err := bloom.add(args[1])
 	if err != nil {
-		return diceerrors.NewErrWithFormattedMessage("%w for 'BFADD' command", err)
+		return probabilisticErr("BFADD", err)
 	}
 
-	return resp
+	return clientio.EncodeInt(respFlag(added))
 }
 
 // evalBFEXISTS evaluates the BFEXISTS command responsible for checking existence of an element in a bloom filter.
@@ -314,15 +431,183 @@ func evalBFEXISTS(args []string, store *dstore.Store) []byte {
 
 	bloom, err := getOrCreateBloomFilter(args[0], nil, store)
 	if err != nil {
-		return diceerrors.NewErrWithFormattedMessage("%w for 'BFEXISTS' command", err)
+		return probabilisticErr("BFEXISTS", err)
+	}
+
+	exists, err := bloom.exists(args[1])
+	if err != nil {
+		return probabilisticErr("BFEXISTS", err)
+	}
+
+	return clientio.EncodeInt(respFlag(exists))
+}
+
+// evalBFMADD evaluates the BF.MADD command responsible for adding one or more
+// elements to a bloom filter. If the filter does not exist, it will create a
+// new one with default parameters. Returns an array holding, for each element,
+// 1 if it was newly added and 0 if it may have been added before.
+func evalBFMADD(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("BF.MADD")
+	}
+	for _, value := range args[1:] {
+		if value == utils.EmptyStr {
+			return probabilisticErr("BF.MADD", errEmptyValue)
+		}
+	}
+
+	opts, _ := newBloomOpts(nil, true)
+
+	bloom, err := getOrCreateBloomFilter(args[0], opts, store)
+	if err != nil {
+		return probabilisticErr("BF.MADD", err)
+	}
+
+	return bloomAddAll("BF.MADD", bloom, args[1:])
+}
+
+// bloomAddAll adds the `values` to the filter and returns the reply of the
+// command `name`, an array holding the results of the additions. A value not
+// added as the filter is full gets an error in its place.
+func bloomAddAll(name string, bloom *Bloom, values []string) []byte {
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		added, err := bloom.add(value)
+		if err != nil {
+			results[i] = probabilisticErr(name, err)
+			continue
+		}
+		results[i] = respFlag(added)
+	}
+
+	return clientio.Encode(results, false)
+}
+
+// evalBFINSERT evaluates the BF.INSERT command responsible for adding one or
+// more items to a bloom filter, creating it with the given options first if it
+// does not exist, unless NOCREATE is given. The creation and the additions
+// happen at once. Returns the same array as BF.MADD.
+//
+// Usage: BF.INSERT key [CAPACITY capacity] [ERROR error] [EXPANSION expansion] [NOCREATE] [NONSCALING] ITEMS item [item ...]
+func evalBFINSERT(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("BF.INSERT")
+	}
+
+	// the options are handed over to newBloomOpts, which validates them
+	errorRate := strconv.FormatFloat(defaultErrorRate, 'f', -1, 64)
+	capacity := strconv.FormatUint(defaultCapacity, 10)
+	var flags []string
+	var noCreate, withOpts bool
+	i := 1
+options:
+	for ; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "CAPACITY", "ERROR", "EXPANSION":
+			if i+1 == len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			i++
+			switch opt {
+			case "CAPACITY":
+				capacity, withOpts = args[i], true
+			case "ERROR":
+				errorRate, withOpts = args[i], true
+			default:
+				flags = append(flags, opt, args[i])
+			}
+		case "NONSCALING":
+			flags = append(flags, args[i])
+		case "NOCREATE":
+			noCreate = true
+		case "ITEMS":
+			break options
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	if i+1 >= len(args) {
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	items := args[i+1:]
+	for _, value := range items {
+		if value == utils.EmptyStr {
+			return probabilisticErr("BF.INSERT", errEmptyValue)
+		}
+	}
+	if noCreate && withOpts {
+		return probabilisticErr("BF.INSERT", errNoCreateOpts)
+	}
+
+	var opts *BloomOpts
+	if !noCreate {
+		var err error
+		if opts, err = newBloomOpts(append([]string{errorRate, capacity}, flags...), false); err != nil {
+			return probabilisticErr("BF.INSERT", err)
+		}
+	}
+
+	bloom, err := getOrCreateBloomFilter(args[0], opts, store)
+	if err != nil {
+		return probabilisticErr("BF.INSERT", err)
+	}
+
+	return bloomAddAll("BF.INSERT", bloom, items)
+}
+
+// evalBFCARD evaluates the BF.CARD command, which returns the number of items
+// added to a bloom filter, 0 if it does not exist.
+func evalBFCARD(args []string, store *dstore.Store) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("BF.CARD")
+	}
+
+	if store.Get(args[0]) == nil {
+		return clientio.RespZero
+	}
+	bloom, err := getOrCreateBloomFilter(args[0], nil, store)
+	if err != nil {
+		return probabilisticErr("BF.CARD", err)
 	}
 
-	resp, err := bloom.exists(args[1])
+	return clientio.Encode(bloom.card(), false)
+}
+
+// evalBFMEXISTS evaluates the BF.MEXISTS command responsible for checking the
+// existence of one or more elements in a bloom filter. Returns an array
+// holding, for each element, 0 if it surely does not exist and 1 if it may.
+func evalBFMEXISTS(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("BF.MEXISTS")
+	}
+	for _, value := range args[1:] {
+		if value == utils.EmptyStr {
+			return probabilisticErr("BF.MEXISTS", errEmptyValue)
+		}
+	}
+
+	bloom, err := getOrCreateBloomFilter(args[0], nil, store)
 	if err != nil {
-		return diceerrors.NewErrWithFormattedMessage("%w for 'BFEXISTS' command", err)
+		return probabilisticErr("BF.MEXISTS", err)
 	}
 
-	return resp
+	results := make([]interface{}, len(args)-1)
+	for i, value := range args[1:] {
+		exists, _ := bloom.exists(value)
+		results[i] = respFlag(exists)
+	}
+
+	return clientio.Encode(results, false)
+}
+
+// respFlag returns the integer reply of a flag, 1 if set and 0 otherwise.
+func respFlag(flag bool) int64 {
+	if flag {
+		return 1
+	}
+	return 0
 }
 
 // evalBFINFO evaluates the BFINFO command responsible for returning the
@@ -334,7 +619,7 @@ func evalBFINFO(args []string, store *dstore.Store) []byte {
 
 	bloom, err := getOrCreateBloomFilter(args[0], nil, store)
 	if err != nil {
-		return diceerrors.NewErrWithFormattedMessage("%w for 'BFINFO' command", err)
+		return probabilisticErr("BFINFO", err)
 	}
 
 	return clientio.Encode(bloom.info(args[0]), false)
@@ -358,12 +643,16 @@ func getOrCreateBloomFilter(key string, opts *BloomOpts, store *dstore.Store) (*
 	}
 
 	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeBitSet); err != nil {
-		return nil, err
+		return nil, errWrongType
 	}
 
 	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingBF); err != nil {
 		return nil, err
 	}
 
-	return obj.Value.(*Bloom), nil
+	bloom, ok := obj.Value.(*Bloom)
+	if !ok {
+		return nil, errWrongType
+	}
+	return bloom, nil
 }
@@ -0,0 +1,223 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hashing"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// A bloom filter is dumped by BF.SCANDUMP in chunks, the first one being its
+// header and the following ones the bitsets of its sub-filters, concatenated
+// and cut every bloomChunkSize bytes. The iterator of a chunk is 1 for the
+// header and 1 plus its offset in the concatenated bitsets for the others, and
+// the dump ends with the iterator 0. BF.LOADCHUNK takes the chunks with their
+// iterators to restore the filter, the header first. The header is laid out
+// as, in big endian:
+//
+//	family (1 byte) seed (8) expansion (8) nonscaling (1) sub-filters (4)
+//	and for each sub-filter: error rate (8) capacity (8) count (8)
+//
+// The bitsets are sized from the error rates and the capacities, hence are not
+// part of the header.
+
+// bloomChunkSize is the maximum number of bytes of the bitsets held by a chunk.
+const bloomChunkSize = 64 * 1024
+
+var (
+	errInvalidIterator = diceerrors.NewErr("invalid iterator")
+	errInvalidHeader   = diceerrors.NewErr("invalid header")
+	errInvalidChunk    = diceerrors.NewErr("invalid chunk")
+)
+
+// bloomHeader holds the layout of a dumped bloom filter.
+type bloomHeader struct {
+	Family     uint8
+	Seed       uint64
+	Expansion  uint64
+	NonScaling uint8
+	Subs       []bloomSubHeader
+}
+
+// bloomSubHeader holds the layout of a dumped sub-filter.
+type bloomSubHeader struct {
+	ErrorRate float64
+	Capacity  uint64
+	Count     uint64
+}
+
+// header returns the header of the dump of the filter.
+func (b *Bloom) header() []byte {
+	h := bloomHeader{
+		Family:    uint8(b.opts.hasher.Family),
+		Seed:      b.opts.hasher.Seed,
+		Expansion: b.opts.expansion,
+	}
+	if b.opts.nonScaling {
+		h.NonScaling = 1
+	}
+	for _, sub := range append([]*Bloom{b}, b.stacked...) {
+		h.Subs = append(h.Subs, bloomSubHeader{ErrorRate: sub.opts.errorRate, Capacity: sub.opts.capacity, Count: sub.count})
+	}
+
+	buf := new(bytes.Buffer)
+	// the writes to a buffer never fail
+	for _, v := range []interface{}{h.Family, h.Seed, h.Expansion, h.NonScaling, uint32(len(h.Subs)), h.Subs} {
+		_ = binary.Write(buf, binary.BigEndian, v)
+	}
+	return buf.Bytes()
+}
+
+// newBloomFromHeader creates the empty filter of the layout of header, its
+// bitsets being filled by the following chunks.
+func newBloomFromHeader(header []byte) (*Bloom, error) {
+	var h bloomHeader
+	var n uint32
+	r := bytes.NewReader(header)
+	for _, v := range []interface{}{&h.Family, &h.Seed, &h.Expansion, &h.NonScaling, &n} {
+		if err := binary.Read(r, binary.BigEndian, v); err != nil {
+			return nil, errInvalidHeader
+		}
+	}
+	if n == 0 || int64(n)*24 != int64(r.Len()) {
+		return nil, errInvalidHeader
+	}
+	h.Subs = make([]bloomSubHeader, n)
+	if err := binary.Read(r, binary.BigEndian, h.Subs); err != nil {
+		return nil, errInvalidHeader
+	}
+
+	family := hashing.Family(h.Family)
+	if _, err := hashing.ParseFamily(family.String()); err != nil || h.Expansion < 1 || h.NonScaling > 1 {
+		return nil, errInvalidHeader
+	}
+	hasher := hashing.Hasher{Family: family, Seed: h.Seed}
+
+	var bloom *Bloom
+	for _, s := range h.Subs {
+		if math.IsNaN(s.ErrorRate) || s.ErrorRate <= 0 || s.ErrorRate >= 1.0 || s.Capacity < 1 || s.Count > s.Capacity {
+			return nil, errInvalidHeader
+		}
+		sub := newBloomFilter(&BloomOpts{errorRate: s.ErrorRate, capacity: s.Capacity, expansion: h.Expansion, nonScaling: h.NonScaling == 1})
+		sub.opts.hasher = hasher
+		sub.count = s.Count
+		if bloom == nil {
+			bloom = sub
+		} else {
+			bloom.stacked = append(bloom.stacked, sub)
+		}
+	}
+	return bloom, nil
+}
+
+// chunk returns the data of the chunk of the bitsets at offset, and the
+// iterator of the next chunk, 0 once the bitsets are dumped.
+func (b *Bloom) chunk(offset int64) (data []byte, next int64) {
+	for _, sub := range append([]*Bloom{b}, b.stacked...) {
+		if offset >= int64(len(sub.bitset)) {
+			offset -= int64(len(sub.bitset))
+			continue
+		}
+		// a chunk never spans two sub-filters
+		end := min(offset+bloomChunkSize, int64(len(sub.bitset)))
+		return sub.bitset[offset:end], end - offset
+	}
+	return nil, 0
+}
+
+// loadChunk copies the data of a chunk to the bitsets at offset. The chunk
+// must be within the bitset of a sub-filter.
+func (b *Bloom) loadChunk(offset int64, data []byte) error {
+	for _, sub := range append([]*Bloom{b}, b.stacked...) {
+		if offset >= int64(len(sub.bitset)) {
+			offset -= int64(len(sub.bitset))
+			continue
+		}
+		if offset+int64(len(data)) > int64(len(sub.bitset)) {
+			return errInvalidChunk
+		}
+		copy(sub.bitset[offset:], data)
+		return nil
+	}
+	return errInvalidChunk
+}
+
+// evalBFSCANDUMP evaluates the BF.SCANDUMP command, which returns the chunk of
+// a bloom filter following the iterator, 0 to begin the dump, and the iterator
+// of the next chunk.
+func evalBFSCANDUMP(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("BF.SCANDUMP")
+	}
+
+	iter, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || iter < 0 {
+		return probabilisticErr("BF.SCANDUMP", errInvalidIterator)
+	}
+	bloom, err := getOrCreateBloomFilter(args[0], nil, store)
+	if err != nil {
+		return probabilisticErr("BF.SCANDUMP", err)
+	}
+
+	if iter == 0 {
+		return encodeBloomChunk(1, bloom.header())
+	}
+	data, n := bloom.chunk(iter - 1)
+	if n == 0 {
+		return encodeBloomChunk(0, nil)
+	}
+	return encodeBloomChunk(iter+n, data)
+}
+
+// encodeBloomChunk encodes the reply to BF.SCANDUMP. The data is always encoded
+// as a bulk string, being binary.
+func encodeBloomChunk(iter int64, data []byte) []byte {
+	return []byte(fmt.Sprintf("*2\r\n:%d\r\n$%d\r\n%s\r\n", iter, len(data), data))
+}
+
+// evalBFLOADCHUNK evaluates the BF.LOADCHUNK command, which restores a chunk
+// of a bloom filter dumped by BF.SCANDUMP. The header creates the filter, the
+// key must not exist then, and the chunks of the bitsets fill it.
+func evalBFLOADCHUNK(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("BF.LOADCHUNK")
+	}
+
+	iter, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || iter < 1 {
+		return probabilisticErr("BF.LOADCHUNK", errInvalidIterator)
+	}
+
+	if iter == 1 {
+		if store.Get(args[0]) != nil {
+			return probabilisticErr("BF.LOADCHUNK", errBloomKeyExists)
+		}
+		bloom, err := newBloomFromHeader([]byte(args[2]))
+		if err != nil {
+			return probabilisticErr("BF.LOADCHUNK", err)
+		}
+		store.Put(args[0], store.NewObj(bloom, -1, object.ObjTypeBitSet, object.ObjEncodingBF))
+		return clientio.RespOK
+	}
+
+	bloom, err := getOrCreateBloomFilter(args[0], nil, store)
+	if err != nil {
+		return probabilisticErr("BF.LOADCHUNK", err)
+	}
+	// the chunk is the one ending at the iterator
+	offset := iter - 1 - int64(len(args[2]))
+	if offset < 0 {
+		return probabilisticErr("BF.LOADCHUNK", errInvalidChunk)
+	}
+	if err := bloom.loadChunk(offset, []byte(args[2])); err != nil {
+		return probabilisticErr("BF.LOADCHUNK", err)
+	}
+	return clientio.RespOK
+}
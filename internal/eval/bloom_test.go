@@ -3,12 +3,14 @@ package eval
 import (
 	"bytes"
 	"errors"
-	"hash"
-	"hash/fnv"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hashing"
 	"github.com/dicedb/dice/internal/server/utils"
 	dstore "github.com/dicedb/dice/internal/store"
 	"gotest.tools/v3/assert"
@@ -111,6 +113,95 @@ func TestBloomFilter(t *testing.T) {
 	}
 }
 
+func TestBloomMultiItems(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	assert.Equal(t, string(clientio.RespOK), string(evalBFRESERVE([]string{"bf", "0.01", "1000", "EXPANSION", "4"}, store)))
+	assert.Equal(t, "-ERR item exists for 'BF.RESERVE' command\r\n", string(evalBFRESERVE([]string{"bf", "0.01", "1000"}, store)))
+	assert.Equal(t, "-ERR syntax error for 'BF.RESERVE' command\r\n", string(evalBFRESERVE([]string{"bf1", "0.01", "1000", "EXPANSION"}, store)))
+	assert.Equal(t, "-ERR nonscaling filters cannot expand for 'BF.RESERVE' command\r\n",
+		string(evalBFRESERVE([]string{"bf1", "0.01", "1000", "NONSCALING", "EXPANSION", "2"}, store)))
+	assert.Equal(t, string(diceerrors.NewErrArity("BF.RESERVE")), string(evalBFRESERVE([]string{"bf1", "0.01"}, store)))
+
+	added := evalBFMADD([]string{"bf", "a", "b", "a"}, store)
+	assert.Equal(t, string(clientio.Encode([]interface{}{1, 1, 0}, false)), string(added))
+	exists := evalBFMEXISTS([]string{"bf", "a", "c", "b"}, store)
+	assert.Equal(t, string(clientio.Encode([]interface{}{1, 0, 1}, false)), string(exists))
+
+	// BF.MADD creates the filter, BF.MEXISTS does not
+	assert.Equal(t, string(clientio.Encode([]interface{}{1}, false)), string(evalBFMADD([]string{"bf2", "a"}, store)))
+	assert.Equal(t, "-ERR invalid key: no bloom filter found for 'BF.MEXISTS' command\r\n", string(evalBFMEXISTS([]string{"bf3", "a"}, store)))
+	assert.Equal(t, "-ERR empty value provided for 'BF.MADD' command\r\n", string(evalBFMADD([]string{"bf", "a", ""}, store)))
+	assert.Equal(t, string(clientio.Encode([]interface{}{0}, false)), string(evalBFMEXISTS([]string{"bf", "d"}, store)))
+}
+
+func TestBFINSERT(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	added := evalBFINSERT([]string{"bf", "CAPACITY", "2", "ERROR", "0.001", "NONSCALING", "ITEMS", "a", "b", "c"}, store)
+	assert.Equal(t, "*3\r\n:1\r\n:1\r\n-ERR non scaling filter is full for 'BF.INSERT' command\r\n", string(added))
+	bloom, err := getOrCreateBloomFilter("bf", nil, store)
+	assert.NilError(t, err)
+	assert.Equal(t, 0.001, bloom.opts.errorRate)
+	assert.Equal(t, string(clientio.Encode(2, false)), string(evalBFCARD([]string{"bf"}, store)))
+
+	// the options are ignored once the filter exists
+	added = evalBFINSERT([]string{"bf", "capacity", "100", "items", "a"}, store)
+	assert.Equal(t, string(clientio.Encode([]interface{}{0}, false)), string(added))
+	added = evalBFINSERT([]string{"bf2", "EXPANSION", "4", "ITEMS", "a"}, store)
+	assert.Equal(t, string(clientio.Encode([]interface{}{1}, false)), string(added))
+	bloom, _ = getOrCreateBloomFilter("bf2", nil, store)
+	assert.Equal(t, uint64(4), bloom.opts.expansion)
+	assert.Equal(t, defaultCapacity, bloom.opts.capacity)
+
+	assert.Equal(t, "-ERR invalid key: no bloom filter found for 'BF.INSERT' command\r\n",
+		string(evalBFINSERT([]string{"bf3", "NOCREATE", "ITEMS", "a"}, store)))
+	assert.Equal(t, string(clientio.RespZero), string(evalBFCARD([]string{"bf3"}, store)))
+	assert.Equal(t, "-ERR NOCREATE cannot be used with CAPACITY or ERROR for 'BF.INSERT' command\r\n",
+		string(evalBFINSERT([]string{"bf", "NOCREATE", "CAPACITY", "10", "ITEMS", "a"}, store)))
+	assert.Equal(t, "-ERR invalid error rate value provided for 'BF.INSERT' command\r\n",
+		string(evalBFINSERT([]string{"bf3", "ERROR", "2", "ITEMS", "a"}, store)))
+	assert.Equal(t, "-ERR syntax error\r\n", string(evalBFINSERT([]string{"bf3", "CAPACITY", "10", "a"}, store)))
+	assert.Equal(t, "-ERR syntax error\r\n", string(evalBFINSERT([]string{"bf3", "CAPACITY", "10", "ITEMS"}, store)))
+	assert.Equal(t, string(diceerrors.NewErrArity("BF.INSERT")), string(evalBFINSERT([]string{"bf3", "ITEMS"}, store)))
+}
+
+func TestScalableBloom(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	assert.Equal(t, string(clientio.RespOK), string(evalBFRESERVE([]string{"bf", "0.01", "10"}, store)))
+	for i := 0; i < 100; i++ {
+		evalBFADD([]string{"bf", "item" + strconv.Itoa(i)}, store)
+	}
+	bloom, err := getOrCreateBloomFilter("bf", nil, store)
+	assert.NilError(t, err)
+
+	// 10, 20, 40 and 80 entries, with error rates halving every time
+	assert.Equal(t, 3, len(bloom.stacked))
+	assert.Equal(t, uint64(80), bloom.stacked[2].opts.capacity)
+	assert.Equal(t, 0.00125, bloom.stacked[2].opts.errorRate)
+	assert.Equal(t, bloom.opts.hasher, bloom.stacked[2].opts.hasher)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, string(clientio.RespOne), string(evalBFEXISTS([]string{"bf", "item" + strconv.Itoa(i)}, store)))
+	}
+	info := bloom.info("bf")
+	assert.Assert(t, strings.Contains(info, "sub-filters: 4, total capacity: 150, items inserted: "), info)
+	assert.NilError(t, validateObj(store.Get("bf")))
+
+	// a non scaling filter refuses the new entries once full
+	evalBFRESERVE([]string{"fixed", "0.01", "2", "NONSCALING"}, store)
+	assert.Equal(t, string(clientio.Encode([]interface{}{1, 1}, false)), string(evalBFMADD([]string{"fixed", "a", "b"}, store)))
+	full := "-ERR non scaling filter is full for 'BF.MADD' command\r\n"
+	assert.Equal(t, "*2\r\n:0\r\n"+full, string(evalBFMADD([]string{"fixed", "a", "c"}, store)))
+	assert.Equal(t, "-ERR non scaling filter is full for 'BFADD' command\r\n", string(evalBFADD([]string{"fixed", "c"}, store)))
+
+	// the copies do not share the stacked sub-filters
+	copyBloom := bloom.DeepCopy()
+	assert.Equal(t, 3, len(copyBloom.stacked))
+	copyBloom.stacked[0].bitset[0] ^= 0xFF
+	assert.Assert(t, copyBloom.stacked[0].bitset[0] != bloom.stacked[0].bitset[0])
+}
+
 func TestGetOrCreateBloomFilter(t *testing.T) {
 	store := dstore.NewStore(nil)
 	// Create a key and default opts
@@ -150,15 +241,19 @@ func TestUpdateIndexes(t *testing.T) {
 	opts, _ := newBloomOpts([]string{}, true)
 	bloom := newBloomFilter(opts)
 
-	err := opts.updateIndexes(value)
-	if err != nil {
-		t.Errorf("non-nil error returned from getIndexes - value: %s, opts: %+v", value, opts)
-	}
+	opts.updateIndexes(value)
 
-	if len(bloom.opts.indexes) != len(opts.hashFns) {
-		t.Errorf("length of indexes does not match with number of hash functions - value: %s, expected: %v, got: %v", value, len(opts.hashFns), len(bloom.opts.indexes))
+	// k = ceil(ln(2) * bpe) hash functions for the default error rate of 1%
+	if len(bloom.opts.indexes) != 7 {
+		t.Errorf("length of indexes does not match with number of hash functions - value: %s, expected: %v, got: %v", value, 7, len(bloom.opts.indexes))
 	}
 
+	// the indexes of a value are the same across filters of the same size
+	indexes := append([]uint64(nil), bloom.opts.indexes...)
+	other, _ := newBloomOpts([]string{}, true)
+	newBloomFilter(other).opts.updateIndexes(value)
+	assert.DeepEqual(t, indexes, other.indexes)
+
 	for _, index := range bloom.opts.indexes {
 		if index >= opts.bits {
 			t.Errorf("bit index returned is out of bounds - value: %s, indexes[i]: %d, bound: %d", value, index, opts.bits)
@@ -174,9 +269,11 @@ func TestBloomOpts(t *testing.T) {
 		response    *BloomOpts
 		err         error
 	}{
-		{"default values", []string{utils.EmptyStr}, true, &BloomOpts{errorRate: defaultErrorRate, capacity: defaultCapacity}, nil},
-		{"should return valid values - 1", []string{"0.01", "1000"}, false, &BloomOpts{errorRate: 0.01, capacity: 1000}, nil},
-		{"should return valid values - 2", []string{"0.1", "200"}, false, &BloomOpts{errorRate: 0.1, capacity: 200}, nil},
+		{"default values", []string{utils.EmptyStr}, true, &BloomOpts{errorRate: defaultErrorRate, capacity: defaultCapacity, expansion: defaultExpansion}, nil},
+		{"should return valid values - 1", []string{"0.01", "1000"}, false, &BloomOpts{errorRate: 0.01, capacity: 1000, expansion: defaultExpansion}, nil},
+		{"should return valid values - 2", []string{"0.1", "200"}, false, &BloomOpts{errorRate: 0.1, capacity: 200, expansion: defaultExpansion}, nil},
+		{"should return valid values - 3", []string{"0.1", "200", "expansion", "4"}, false, &BloomOpts{errorRate: 0.1, capacity: 200, expansion: 4}, nil},
+		{"should return valid values - 4", []string{"0.1", "200", "NONSCALING"}, false, &BloomOpts{errorRate: 0.1, capacity: 200, expansion: defaultExpansion, nonScaling: true}, nil},
 		{"should return invalid error rate type - 1", []string{"aa", "100"}, false, nil, errInvalidErrorRateType},
 		{"should return invalid error rate type - 2", []string{"0.1a", "100"}, false, nil, errInvalidErrorRateType},
 		{"should return invalid error rate - 1", []string{"-0.1", "100"}, false, nil, errInvalidErrorRate},
@@ -185,6 +282,9 @@ func TestBloomOpts(t *testing.T) {
 		{"should return invalid capacity type - 2", []string{"0.01", "100a"}, false, nil, errInvalidCapacityType},
 		{"should return invalid capacity type - 3", []string{"0.01", "-1"}, false, nil, errInvalidCapacityType},
 		{"should return invalid capacity - 1", []string{"0.01", "0"}, false, nil, errInvalidCapacity},
+		{"should return invalid expansion - 1", []string{"0.01", "100", "EXPANSION", "0"}, false, nil, errInvalidExpansion},
+		{"should return invalid expansion - 2", []string{"0.01", "100", "EXPANSION", "a"}, false, nil, errInvalidExpansion},
+		{"should return nonscaling expansion", []string{"0.01", "100", "EXPANSION", "2", "NONSCALING"}, false, nil, errNonScalingExpansion},
 	}
 
 	for _, tc := range testCases {
@@ -194,7 +294,7 @@ func TestBloomOpts(t *testing.T) {
 
 			opts, err := newBloomOpts(tc.args, tc.useDefaults)
 			// Using reflect.DeepEqual as we have pointers to struct and direct value
-			// comparision is not possible because of []hash.Hash64 type.
+			// comparision is not possible because of the []uint64 indexes.
 			if !reflect.DeepEqual(opts, tc.response) {
 				t.Errorf("invalid response in %s - expected: %v, got: %v", t.Name(), tc.response, opts)
 			}
@@ -282,11 +382,8 @@ func TestBloomDeepCopy(t *testing.T) {
 		capacity:  1000,
 		bits:      8000,
 		bpe:       8.0,
-		hashFns: []hash.Hash64{
-			fnv.New64a(),
-			fnv.New64(),
-		},
-		indexes: []uint64{1, 2, 3, 4, 5},
+		hasher:    hashing.Hasher{Family: hashing.XXHash, Seed: 7},
+		indexes:   []uint64{1, 2, 3, 4, 5},
 	}
 
 	original := &Bloom{
@@ -302,6 +399,7 @@ func TestBloomDeepCopy(t *testing.T) {
 
 	assert.Assert(t, original.opts.indexes[0] == copyBloom.opts.indexes[0], "Original and copy indexes values should be same")
 	assert.Assert(t, original.bitset[0] == copyBloom.bitset[0], "Original and copy bitset values should be same")
+	assert.Equal(t, original.opts.hasher, copyBloom.opts.hasher)
 
 	// Verify that changes to the copy do not affect the original
 	copyBloom.opts.indexes[0] = 10
@@ -309,3 +407,46 @@ func TestBloomDeepCopy(t *testing.T) {
 	assert.Assert(t, original.opts.indexes[0] != copyBloom.opts.indexes[0], "Original and copy indexes should not be linked")
 	assert.Assert(t, original.bitset[0] != copyBloom.bitset[0], "Original and copy bitset should not be linked")
 }
+
+func TestBFScanDumpLoadChunk(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	// the header is followed by two chunks of the first sub-filter and, once
+	// full, by three of the stacked one
+	evalBFRESERVE([]string{"bf", "0.01", "60000"}, store)
+	for i := 0; i < 62000; i++ {
+		evalBFADD([]string{"bf", "item" + strconv.Itoa(i)}, store)
+	}
+
+	iter, chunks := int64(0), 0
+	for {
+		value, err := clientio.NewRESPParser(bytes.NewBuffer(evalBFSCANDUMP([]string{"bf", strconv.FormatInt(iter, 10)}, store))).DecodeOne()
+		assert.NilError(t, err)
+		reply := value.([]interface{})
+		if iter = reply[0].(int64); iter == 0 {
+			assert.Equal(t, "", reply[1])
+			break
+		}
+		assert.Equal(t, string(clientio.RespOK), string(evalBFLOADCHUNK([]string{"copy", strconv.FormatInt(iter, 10), reply[1].(string)}, store)))
+		chunks++
+	}
+	assert.Equal(t, 6, chunks)
+
+	bloom, _ := getOrCreateBloomFilter("bf", nil, store)
+	restored, err := getOrCreateBloomFilter("copy", nil, store)
+	assert.NilError(t, err)
+	assert.Equal(t, bloom.info(""), restored.info(""))
+	assert.Equal(t, bloom.card(), restored.card())
+	assert.Equal(t, 1, len(restored.stacked))
+	assert.DeepEqual(t, bloom.stacked[0].bitset, restored.stacked[0].bitset)
+	assert.Equal(t, string(clientio.RespOne), string(evalBFEXISTS([]string{"copy", "item61999"}, store)))
+
+	header := string(bloom.header())
+	assert.Equal(t, "-ERR item exists for 'BF.LOADCHUNK' command\r\n", string(evalBFLOADCHUNK([]string{"copy", "1", header}, store)))
+	assert.Equal(t, "-ERR invalid header for 'BF.LOADCHUNK' command\r\n", string(evalBFLOADCHUNK([]string{"other", "1", header[:10]}, store)))
+	assert.Equal(t, "-ERR invalid chunk for 'BF.LOADCHUNK' command\r\n", string(evalBFLOADCHUNK([]string{"copy", "2", "abc"}, store)))
+	assert.Equal(t, "-ERR invalid chunk for 'BF.LOADCHUNK' command\r\n", string(evalBFLOADCHUNK([]string{"copy", "1000000000", "abc"}, store)))
+	assert.Equal(t, "-ERR invalid iterator for 'BF.SCANDUMP' command\r\n", string(evalBFSCANDUMP([]string{"bf", "-1"}, store)))
+	assert.Equal(t, "-ERR invalid key: no bloom filter found for 'BF.LOADCHUNK' command\r\n",
+		string(evalBFLOADCHUNK([]string{"other", "10", "abc"}, store)))
+}
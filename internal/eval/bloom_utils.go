@@ -1,5 +1,37 @@
 package eval
 
+import (
+	"errors"
+
+	"github.com/dicedb/dice/config"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hashing"
+)
+
+// errWrongType is returned when a key holds another kind of value than the
+// one a command operates on.
+var errWrongType = diceerrors.NewErr(diceerrors.WrongTypeErr)
+
+// sketchHasher returns the hasher of the probabilistic data structures created
+// from now on, as configured. Each structure keeps the hasher it was created
+// with, so that its hashes never change while it lives.
+func sketchHasher() hashing.Hasher {
+	family, err := hashing.ParseFamily(config.DiceConfig.Server.SketchHashFamily)
+	if err != nil {
+		family = hashing.Murmur3
+	}
+	return hashing.Hasher{Family: family, Seed: config.DiceConfig.Server.SketchHashSeed}
+}
+
+// probabilisticErr returns the error reply of the command `name` operating on
+// a probabilistic data structure.
+func probabilisticErr(name string, err error) []byte {
+	if errors.Is(err, errWrongType) {
+		return diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr)
+	}
+	return diceerrors.NewErrWithFormattedMessage("%v for '%s' command", err, name)
+}
+
 // setBit sets the bit at index `b` to "1" in `buf`.
 func setBit(buf []byte, b uint64) {
 	idx, offset := b/8, 7-b%8
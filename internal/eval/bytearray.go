@@ -124,6 +124,20 @@ func ByteSliceToByteArrayObj(store *dstore.Store, oldObj *object.Obj, b []byte)
 	return store.NewObj(byteValue, -1, object.ObjTypeByteArray, object.ObjEncodingByteArray), nil
 }
 
+// maxBitOffset is the largest offset of a bit SETBIT and GETBIT accept, the
+// strings being limited to 512MB like in Redis.
+const maxBitOffset = 512*1024*1024*8 - 1
+
+// parseBitOffset parses the offset of a bit, false if it is not an integer
+// between 0 and maxBitOffset.
+func parseBitOffset(arg string) (int64, bool) {
+	offset, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || offset < 0 || offset > maxBitOffset {
+		return 0, false
+	}
+	return offset, true
+}
+
 // SetBit sets the bit at the given position to the specified value
 func (b *ByteArray) SetBit(pos int, value bool) {
 	byteIndex := pos / 8
@@ -210,89 +224,79 @@ func (b *ByteArray) DeepCopy() *ByteArray {
 	return copyArray
 }
 
-func (b *ByteArray) getBits(offset, width int, signed bool) int64 {
-	extraBits := 0
-	if offset+width > int(b.Length)*8 {
-		// If bits exceed the current data size, we will pad the result with zeros for the missing bits.
-		extraBits = offset + width - int(b.Length)*8
-	}
-	var value int64
-	for i := 0; i < width-extraBits; i++ {
+// getBits returns the integer of width bits at the bit offset, read from its
+// most significant bit like the bits of SETBIT and GETBIT. The bits beyond the
+// end of the array are read as zeros.
+func (b *ByteArray) getBits(offset, width int64, signed bool) int64 {
+	var value uint64
+	for pos := offset; pos < offset+width; pos++ {
 		value <<= 1
-		byteIndex := (offset + i) / 8
-		bitIndex := 7 - ((offset + i) % 8)
-		if b.data[byteIndex]&(1<<bitIndex) != 0 {
-			value |= 1 << 0
+		if pos < b.Length*8 && b.data[pos/8]&(1<<(7-pos%8)) != 0 {
+			value |= 1
 		}
 	}
-	value <<= int64(extraBits)
-	if signed && (value&(1<<(width-1)) != 0) {
-		value -= 1 << width
-	}
-	return value
+	return wrapBitfield(int64(value), signed, width)
 }
 
-func (b *ByteArray) setBits(offset, width int, value int64) {
-	if offset+width > int(b.Length)*8 {
-		newSize := (offset + width + 7) / 8
-		b.IncreaseSize(newSize)
+// setBits writes the lowest width bits of value at the bit offset, from its
+// most significant bit, growing the array as needed.
+func (b *ByteArray) setBits(offset, width, value int64) {
+	if size := (offset + width + 7) / 8; size > b.Length {
+		b.IncreaseSize(int(size))
 	}
-	for i := 0; i < width; i++ {
-		byteIndex := (offset + i) / 8
-		bitIndex := (offset + i) % 8
-		if value&(1<<i) != 0 {
-			b.data[byteIndex] |= 1 << bitIndex
+	for i := int64(0); i < width; i++ {
+		pos := offset + i
+		if uint64(value)>>(width-1-i)&1 != 0 {
+			b.data[pos/8] |= 1 << (7 - pos%8)
 		} else {
-			b.data[byteIndex] &^= 1 << bitIndex
+			b.data[pos/8] &^= 1 << (7 - pos%8)
 		}
 	}
 }
 
-// Increment value at a specific bitfield and handle overflow.
-func (b *ByteArray) incrByBits(offset, width int, increment int64, overflow string, signed bool) (int64, error) {
-	if offset+width > int(b.Length)*8 {
-		newSize := (offset + width + 7) / 8
-		b.IncreaseSize(newSize)
-	}
-
-	value := b.getBits(offset, width, signed)
-	newValue := value + increment
-
-	var maxVal, minVal int64
-	if signed {
-		maxVal = int64(1<<(width-1) - 1)
-		minVal = int64(-1 << (width - 1))
-	} else {
-		maxVal = int64(1<<width - 1)
-		minVal = 0
-	}
+// bitfieldOp is a subcommand of BITFIELD, on the integer of width bits at the
+// bit offset, the overflow being the policy in effect when the subcommand was
+// given.
+type bitfieldOp struct {
+	kind     string
+	signed   bool
+	width    int64
+	offset   int64
+	value    int64
+	overflow string
+}
 
-	switch overflow {
-	case WRAP:
-		if signed {
-			rangeSize := maxVal - minVal + 1
-			newValue = ((newValue-minVal)%rangeSize+rangeSize)%rangeSize + minVal
-		} else {
-			newValue %= maxVal + 1
+// applyBitfield applies the BITFIELD subcommands on the array, returning their
+// results, nil for the ones failing on overflow, and whether the array was
+// modified.
+func (b *ByteArray) applyBitfield(ops []bitfieldOp) ([]interface{}, bool) {
+	modified := false
+	result := make([]interface{}, 0, len(ops))
+	for _, op := range ops {
+		current := b.getBits(op.offset, op.width, op.signed)
+
+		switch op.kind {
+		case GET:
+			result = append(result, current)
+		case SET:
+			if v, ok := addBitfield(op.value, 0, op.signed, op.width, op.overflow); ok {
+				b.setBits(op.offset, op.width, v)
+				modified = true
+				result = append(result, current)
+			} else {
+				result = append(result, nil)
+			}
+		case INCRBY:
+			if v, ok := addBitfield(current, op.value, op.signed, op.width, op.overflow); ok {
+				b.setBits(op.offset, op.width, v)
+				modified = true
+				result = append(result, v)
+			} else {
+				result = append(result, nil)
+			}
 		}
-	case SAT:
-		// Handle saturation
-		if newValue > maxVal {
-			newValue = maxVal
-		} else if newValue < minVal {
-			newValue = minVal
-		}
-	case FAIL:
-		// Handle failure on overflow
-		if newValue > maxVal || newValue < minVal {
-			return value, errors.New("overflow detected")
-		}
-	default:
-		return value, errors.New("invalid overflow type")
 	}
-
-	b.setBits(offset, width, newValue)
-	return newValue, nil
+	return result, modified
 }
 
 // population counting, counts the number of set bits in a byte
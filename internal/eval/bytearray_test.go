@@ -4,6 +4,10 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
 )
 
 func TestMixedOperations(t *testing.T) {
@@ -149,3 +153,22 @@ func TestDeepCopy(t *testing.T) {
 	original.data[1] = 8
 	assert.Assert(t, deepCopy.data[1] != original.data[1], "ByteArray DeepCopy did not create an independent deepCopy, original and deepCopy data are linked")
 }
+
+func TestBitOffsets(t *testing.T) {
+	store := dstore.NewStore(nil)
+	offsetErr := string(diceerrors.NewErrWithMessage("bit offset is not an integer or out of range"))
+	bitErr := string(diceerrors.NewErrWithMessage("bit is not an integer or out of range"))
+
+	assert.Equal(t, offsetErr, string(evalSETBIT([]string{"k", "-1", "1"}, store)))
+	assert.Equal(t, offsetErr, string(evalSETBIT([]string{"k", "4294967296", "1"}, store)))
+	assert.Equal(t, offsetErr, string(evalGETBIT([]string{"k", "-9"}, store)))
+	assert.Equal(t, bitErr, string(evalSETBIT([]string{"k", "1", "true"}, store)))
+	assert.Equal(t, bitErr, string(evalSETBIT([]string{"k", "1", "2"}, store)))
+
+	// setting a bit beyond the end grows the value
+	assert.Equal(t, string(clientio.Encode(0, true)), string(evalSETBIT([]string{"k", "1", "1"}, store)))
+	assert.Equal(t, string(clientio.Encode(0, true)), string(evalSETBIT([]string{"k", "100", "1"}, store)))
+	assert.Equal(t, int64(13), store.Get("k").Value.(*ByteArray).Length)
+	assert.Equal(t, string(clientio.Encode(1, true)), string(evalGETBIT([]string{"k", "100"}, store)))
+	assert.Equal(t, string(clientio.Encode(0, true)), string(evalGETBIT([]string{"k", "4294967295"}, store)))
+}
@@ -1,7 +1,10 @@
 package eval
 
 import (
+	"log/slog"
 	"unsafe"
+
+	"github.com/dicedb/dice/internal/dencoding"
 )
 
 var byteListNodeSize int64 = 0
@@ -24,6 +27,11 @@ type byteListNode struct {
 	buf  []byte
 	next *byteListNode
 	prev *byteListNode
+
+	// packed holds buf LZ4 compressed, along with its length, once the node is
+	// compressed, buf being nil. See compressInterior.
+	packed    []byte
+	packedLen int
 }
 
 func newByteList(bufLen int) *byteList {
@@ -92,6 +100,94 @@ func (b *byteList) delete(bn *byteListNode) {
 	b.size -= byteListNodeSize
 }
 
+// The lists are mostly written and read at their ends, e.g. the queues, hence
+// their interior nodes may be kept compressed, saving memory for the long lists
+// at the cost of decompressing them when they are iterated over, or once they
+// reach an end. The depth nodes at both ends are always plain, so that the
+// pushes and the pops never decompress a node.
+
+// compressInterior keeps the depth nodes at both ends of the list plain, and
+// compresses the nodes next to them. It must be called whenever a node is
+// added or deleted at an end, for the nodes to be compressed as they move past
+// the depth, and decompressed as they come back within it. The nodes are never
+// compressed if depth is 0, those compressed under a former depth being
+// decompressed once they reach an end.
+func (b *byteList) compressInterior(depth int) {
+	if depth <= 0 {
+		if b.head != nil {
+			b.head.decompress()
+			b.tail.decompress()
+		}
+		return
+	}
+
+	front := b.head
+	for i := 0; i < depth && front != nil; i++ {
+		front.decompress()
+		front = front.next
+	}
+	back := b.tail
+	short := front == nil
+	for i := 0; i < depth && back != nil; i++ {
+		// the list has no interior nodes if both ends meet
+		short = short || back == front
+		back.decompress()
+		back = back.prev
+	}
+	if short {
+		return
+	}
+	front.compress()
+	back.compress()
+}
+
+// compress compresses the node unless it is compressed already, or would not
+// be any smaller.
+func (node *byteListNode) compress() {
+	if node.packed != nil || len(node.buf) == 0 {
+		return
+	}
+	packed := dencoding.CompressLZ4(node.buf)
+	if len(packed) >= len(node.buf) {
+		return
+	}
+	node.packed, node.packedLen = packed, len(node.buf)
+	node.buf = nil
+}
+
+// decompress makes the node plain again.
+func (node *byteListNode) decompress() {
+	if node.packed == nil {
+		return
+	}
+	node.buf = node.plain()
+	node.packed, node.packedLen = nil, 0
+}
+
+// plain returns the content of the node, decompressing it if needed without
+// changing the node.
+func (node *byteListNode) plain() []byte {
+	if node.packed == nil {
+		return node.buf
+	}
+	buf, err := dencoding.DecompressLZ4(node.packed, node.packedLen)
+	if err != nil {
+		// should never happen as the data is produced by compress
+		slog.Error("could not decompress list node", slog.Any("error", err))
+		return nil
+	}
+	return buf
+}
+
+// memSize returns the memory used by the list, its nodes and their content.
+func (b *byteList) memSize() int64 {
+	size := b.size
+	for node := b.head; node != nil; node = node.next {
+		size += int64(cap(node.buf) + len(node.packed))
+	}
+	return size
+}
+
 // DeepCopy creates a deep copy of the byteList.
 func (b *byteList) DeepCopy() *byteList {
 	if b == nil {
@@ -126,8 +222,13 @@ func (node *byteListNode) deepCopyNode(prevCopy *byteListNode) *byteListNode {
 
 	// Create a copy of the current node
 	copyNode := &byteListNode{
-		buf:  append([]byte(nil), node.buf...),
-		prev: prevCopy,
+		buf:       append([]byte(nil), node.buf...),
+		prev:      prevCopy,
+		packedLen: node.packedLen,
+	}
+	if node.packed != nil {
+		copyNode.buf = nil
+		copyNode.packed = append([]byte(nil), node.packed...)
 	}
 
 	// Recursively copy the next node
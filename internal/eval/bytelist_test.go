@@ -2,8 +2,11 @@ package eval
 
 import (
 	"bytes"
-	"gotest.tools/v3/assert"
+	"fmt"
 	"testing"
+
+	"github.com/dicedb/dice/config"
+	"gotest.tools/v3/assert"
 )
 
 func newNode(bl *byteList, b byte) *byteListNode {
@@ -101,3 +104,58 @@ func TestByteListDeepCopy(t *testing.T) {
 	original.head.buf[1] = 8
 	assert.Assert(t, original.head.buf[1] != deepCopy.head.buf[1], "Original and deepCopy head buffer should not be linked")
 }
+
+func TestByteListCompressInterior(t *testing.T) {
+	defer func(depth int) { config.DiceConfig.Server.ListCompressDepth = depth }(config.DiceConfig.Server.ListCompressDepth)
+	config.DiceConfig.Server.ListCompressDepth = 1
+
+	// packedNodes returns whether each node of the deque is compressed, from
+	// head to tail.
+	packedNodes := func(q *Deque) []bool {
+		var packed []bool
+		for node := q.list.head; node != nil; node = node.next {
+			packed = append(packed, node.packed != nil)
+		}
+		return packed
+	}
+
+	q := NewDeque()
+	var expected []string
+	for i := 0; i < 100; i++ {
+		x := fmt.Sprintf("element-%03d", i)
+		q.RPush(x)
+		expected = append(expected, x)
+	}
+	packed := packedNodes(q)
+	assert.Assert(t, len(packed) > 3)
+	assert.Assert(t, !packed[0] && !packed[len(packed)-1])
+	for _, p := range packed[1 : len(packed)-1] {
+		assert.Assert(t, p)
+	}
+	plainSize := NewDeque().list.size
+	assert.Assert(t, q.list.memSize() < plainSize+int64(len(packed)*minDequeNodeSize))
+
+	// the compressed nodes are iterated over, and decompressed once at an end
+	var actual []string
+	q.Iterate(func(x string) bool {
+		actual = append(actual, x)
+		return true
+	})
+	assert.DeepEqual(t, expected, actual)
+	for i := 0; i < 100; i++ {
+		x, err := q.LPop()
+		assert.NilError(t, err)
+		assert.Equal(t, expected[i], x)
+	}
+
+	// the nodes compressed are decompressed at the ends once disabled
+	for _, x := range expected {
+		q.LPush(x)
+	}
+	config.DiceConfig.Server.ListCompressDepth = 0
+	for i := 0; i < 100; i++ {
+		x, err := q.RPop()
+		assert.NilError(t, err)
+		assert.Equal(t, expected[i], x)
+	}
+}
@@ -0,0 +1,462 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/axiomhq/hyperloglog"
+	"github.com/google/btree"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The check mode validates the data of the server before it is used: the AOF
+// file is parsed and replayed in a scratch store, then every key is validated
+// against the invariants of its type. The AOF format carries no checksum, hence
+// the file is validated through the framing of its commands.
+
+// CheckIssue describes a problem found while checking the data.
+type CheckIssue struct {
+	Key    string // key breaking an invariant, or the position of the faulty command
+	Reason string
+	Action string // what the repair did about it, empty when only reported
+}
+
+// CheckReport sums up the result of a check.
+type CheckReport struct {
+	Commands  int   // number of commands replayed successfully
+	Keys      int   // number of keys validated
+	Truncated int64 // number of bytes of the corrupt tail of the file
+	Issues    []CheckIssue
+}
+
+// OK returns true if no problem was found.
+func (r *CheckReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// CheckAOF parses and replays the AOF file at path, then validates the resulting
+// keys. With repair, the keys breaking an invariant are fixed when possible and
+// dropped otherwise, and the file is rewritten with the commands that replayed
+// successfully, which drops the corrupt tail and the failing commands.
+func CheckAOF(path string, repair bool) (*CheckReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	cmds, valid, readErr := dstore.ReadAOF(f)
+	info, statErr := f.Stat()
+	f.Close()
+	if statErr != nil {
+		return nil, statErr
+	}
+	if readErr != nil && !errors.Is(readErr, dstore.ErrAOFTruncated) && !errors.Is(readErr, dstore.ErrAOFCorrupt) {
+		return nil, readErr
+	}
+
+	report := &CheckReport{}
+	if readErr != nil {
+		report.Truncated = info.Size() - valid
+		report.Issues = append(report.Issues, CheckIssue{
+			Key:    fmt.Sprintf("command %d", len(cmds)+1),
+			Reason: fmt.Sprintf("%v, %d trailing bytes", readErr, report.Truncated),
+		})
+	}
+
+	store := dstore.NewStore(nil)
+	replayed := make([][]string, 0, len(cmds))
+	for i, c := range cmds {
+		if len(c) == 0 {
+			report.Issues = append(report.Issues, CheckIssue{Key: fmt.Sprintf("command %d", i+1), Reason: "empty command"})
+			continue
+		}
+
+		diceDBCmd := &cmd.DiceDBCmd{Cmd: strings.ToUpper(c[0]), Args: c[1:]}
+		if err := ExecuteCommand(diceDBCmd, nil, store, false, false).Err(); err != nil {
+			report.Issues = append(report.Issues, CheckIssue{
+				Key:    fmt.Sprintf("command %d (%s)", i+1, diceDBCmd.Cmd),
+				Reason: err.Error(),
+			})
+			continue
+		}
+		replayed = append(replayed, c)
+	}
+	report.Commands = len(replayed)
+
+	CheckStore(store, repair, report)
+
+	if repair && !report.OK() {
+		if err := dstore.RewriteAOF(path, replayed); err != nil {
+			return report, err
+		}
+		for i := range report.Issues {
+			if report.Issues[i].Action == "" {
+				report.Issues[i].Action = "dropped"
+			}
+		}
+	}
+	return report, nil
+}
+
+// CheckStore validates every key of the store against the invariants of its
+// type, adding the violations to the report. With repair, the broken keys are
+// fixed when their content can be recovered, and deleted otherwise.
+func CheckStore(store *dstore.Store, repair bool, report *CheckReport) {
+	var dropped []string
+	store.GetStore().All(func(key string, obj *object.Obj) bool {
+		report.Keys++
+
+		issue, ok := checkKey(store, key, obj, repair)
+		if !ok {
+			return true
+		}
+		if repair && issue.Action == "" {
+			issue.Action = "dropped"
+			dropped = append(dropped, key)
+		}
+		report.Issues = append(report.Issues, issue)
+		return true
+	})
+
+	for _, key := range dropped {
+		store.Del(key)
+	}
+}
+
+// CheckKeys validates the given keys of the store like CheckStore, skipping
+// the keys that no longer exist, and returns the violations found. It is meant
+// for the checks of a live store: with repair, the broken keys are fixed when
+// their content can be recovered, but never deleted.
+func CheckKeys(store *dstore.Store, keys []string, repair bool) []CheckIssue {
+	var issues []CheckIssue
+	for _, key := range keys {
+		obj, ok := store.GetStore().Get(key)
+		if !ok {
+			continue
+		}
+		if issue, ok := checkKey(store, key, obj, repair); ok {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// checkKey validates the key of the store holding obj, and returns the
+// violation found, false if there is none. With repair, the key is fixed when
+// possible and the action of the issue tells so. The values compressed or
+// offloaded to the cold tier are validated through a copy, hence only
+// reported.
+func checkKey(store *dstore.Store, key string, obj *object.Obj, repair bool) (CheckIssue, bool) {
+	plain := dstore.PlainObj(obj)
+	err := validateObj(plain)
+	if err == nil {
+		err = validateFieldExpiries(store, key, plain)
+		if err != nil && repair {
+			repairFieldExpiries(store, key, plain)
+			return CheckIssue{Key: key, Reason: err.Error(), Action: "repaired"}, true
+		}
+	}
+	if err == nil {
+		return CheckIssue{}, false
+	}
+
+	issue := CheckIssue{Key: key, Reason: err.Error()}
+	if repair && plain == obj && repairObj(obj) {
+		issue.Action = "repaired"
+	}
+	return issue, true
+}
+
+// validateObj checks that the value of obj matches its type and encoding, and
+// that the internal counters and indexes of the value are consistent.
+func validateObj(obj *object.Obj) error {
+	oType, oEnc := object.ExtractTypeEncoding(obj)
+	switch oType {
+	case object.ObjTypeString:
+		switch obj.Value.(type) {
+		case string, *hyperloglog.Sketch:
+			return nil
+		}
+	case object.ObjTypeInt:
+		if _, ok := obj.Value.(int64); ok {
+			return nil
+		}
+	case object.ObjTypeByteList:
+		if q, ok := obj.Value.(*Deque); ok && oEnc == object.ObjEncodingDeque {
+			if n := dequeLen(q); n != q.Length {
+				return fmt.Errorf("list holds %d elements but its length is %d", n, q.Length)
+			}
+			return nil
+		}
+	case object.ObjTypeBitSet:
+		if b, ok := obj.Value.(*Bloom); ok {
+			for i, sub := range append([]*Bloom{b}, b.stacked...) {
+				if uint64(len(sub.bitset))*8 < sub.opts.bits {
+					return fmt.Errorf("bloom sub-filter %d holds %d bits out of %d", i, len(sub.bitset)*8, sub.opts.bits)
+				}
+			}
+			return nil
+		}
+	case object.ObjTypeJSON:
+		return nil
+	case object.ObjTypeCuckoo:
+		if c, ok := obj.Value.(*Cuckoo); ok {
+			if uint64(len(c.slots)) != c.numBuckets*c.opts.bucketSize {
+				return fmt.Errorf("cuckoo filter holds %d slots out of %d", len(c.slots), c.numBuckets*c.opts.bucketSize)
+			}
+			return nil
+		}
+	case object.ObjTypeCountMinSketch:
+		if c, ok := obj.Value.(*CountMinSketch); ok {
+			if uint64(len(c.matrix)) != c.width*c.depth {
+				return fmt.Errorf("count-min sketch holds %d counters out of %d", len(c.matrix), c.width*c.depth)
+			}
+			return nil
+		}
+	case object.ObjTypeTopK:
+		if t, ok := obj.Value.(*TopK); ok {
+			if uint64(len(t.buckets)) != t.width*t.depth {
+				return fmt.Errorf("top-k tracker holds %d counters out of %d", len(t.buckets), t.width*t.depth)
+			}
+			if uint64(len(t.heap)) > t.k {
+				return fmt.Errorf("top-k tracker holds %d items out of %d", len(t.heap), t.k)
+			}
+			return nil
+		}
+	case object.ObjTypeTDigest:
+		if t, ok := obj.Value.(*TDigest); ok {
+			var weight float64
+			for i, c := range t.centroids {
+				if i > 0 && c.mean < t.centroids[i-1].mean {
+					return fmt.Errorf("t-digest centroid %d is out of order", i)
+				}
+				weight += c.weight
+			}
+			if weight != t.weight {
+				return fmt.Errorf("t-digest centroids weigh %v but its weight is %v", weight, t.weight)
+			}
+			return nil
+		}
+	case object.ObjTypeByteArray:
+		if b, ok := obj.Value.(*ByteArray); ok {
+			if int64(len(b.data)) != b.Length {
+				return fmt.Errorf("byte array holds %d bytes but its length is %d", len(b.data), b.Length)
+			}
+			return nil
+		}
+	case object.ObjTypeSet:
+		if _, ok := obj.Value.(map[string]struct{}); ok {
+			return nil
+		}
+	case object.ObjTypeHashMap:
+		if _, ok := obj.Value.(HashMap); ok {
+			return nil
+		}
+	case object.ObjTypeSortedSet:
+		return validateSortedSet(obj.Value)
+	case object.ObjTypeStream:
+		if s, ok := obj.Value.(*Stream); ok {
+			return validateStream(s)
+		}
+	default:
+		return fmt.Errorf("unknown type %d", oType)
+	}
+	return fmt.Errorf("unexpected value %T for type %d and encoding %d", obj.Value, oType, oEnc)
+}
+
+func dequeLen(q *Deque) int64 {
+	var n int64
+	q.Iterate(func(string) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// validateSortedSet checks that the skip list ordered by score and the member
+// to score map of a sorted set hold the same members with the same scores, and
+// that the links of the skip list are consistent.
+func validateSortedSet(value interface{}) error {
+	parts, ok := value.([]interface{})
+	if !ok || len(parts) != 2 {
+		return fmt.Errorf("unexpected value %T for a sorted set", value)
+	}
+	tree, okTree := parts[0].(*skipList)
+	scores, okScores := parts[1].(map[string]float64)
+	if !okTree || !okScores {
+		return fmt.Errorf("unexpected value %T, %T for a sorted set", parts[0], parts[1])
+	}
+
+	if tree.Len() != len(scores) {
+		return fmt.Errorf("sorted set index holds %d members but its dictionary holds %d", tree.Len(), len(scores))
+	}
+	if err := tree.validate(); err != nil {
+		return fmt.Errorf("sorted set index: %w", err)
+	}
+
+	var err error
+	var prev *SortedSetItem
+	tree.Ascend(func(ssi *SortedSetItem) bool {
+		if prev != nil && !prev.Less(ssi) {
+			err = fmt.Errorf("sorted set member %q is out of order", ssi.Member)
+			return false
+		}
+		if score, ok := scores[ssi.Member]; !ok || score != ssi.Score {
+			err = fmt.Errorf("sorted set member %q has score %v in the index but not in the dictionary", ssi.Member, ssi.Score)
+			return false
+		}
+		prev = ssi
+		return true
+	})
+	return err
+}
+
+// validateStream checks that the last ID of a stream is not lower than the ID
+// of its entries, and that the pending counters of the consumers match the
+// pending entries lists of their groups.
+func validateStream(s *Stream) error {
+	if maxID, ok := s.lastEntryID(); ok && s.lastID.Less(maxID) {
+		return fmt.Errorf("stream last ID %s is lower than entry %s", s.lastID, maxID)
+	}
+	if n := streamNodesLen(s); n != s.length {
+		return fmt.Errorf("stream holds %d entries but its length is %d", n, s.length)
+	}
+
+	for name, g := range s.groups {
+		counts := streamGroupPendingCounts(g)
+		for consumer, c := range g.consumers {
+			if c.Pending != counts[consumer] {
+				return fmt.Errorf("consumer %q of group %q owns %d pending entries but its counter is %d",
+					consumer, name, counts[consumer], c.Pending)
+			}
+			delete(counts, consumer)
+		}
+		for consumer := range counts {
+			return fmt.Errorf("pending entries of group %q belong to unknown consumer %q", name, consumer)
+		}
+	}
+	return nil
+}
+
+// streamNodesLen returns the number of entries of the nodes of a stream.
+func streamNodesLen(s *Stream) int {
+	n := 0
+	s.nodes.Ascend(func(item btree.Item) bool {
+		n += item.(*streamNode).count
+		return true
+	})
+	return n
+}
+
+func streamGroupPendingCounts(g *StreamGroup) map[string]int {
+	counts := make(map[string]int)
+	g.pel.Ascend(func(item btree.Item) bool {
+		counts[item.(*StreamPendingEntry).Consumer]++
+		return true
+	})
+	return counts
+}
+
+// validateFieldExpiries checks that the fields of a hash having an expiry are
+// fields of the hash.
+func validateFieldExpiries(store *dstore.Store, key string, obj *object.Obj) error {
+	hash, ok := obj.Value.(HashMap)
+	if !ok {
+		return nil
+	}
+	var err error
+	store.FieldExpiries(key, func(field string, _ uint64) {
+		if _, ok := hash[field]; !ok && err == nil {
+			err = fmt.Errorf("hash field %q has an expiry but does not exist", field)
+		}
+	})
+	return err
+}
+
+// repairFieldExpiries removes the expiries of the fields a hash does not hold.
+func repairFieldExpiries(store *dstore.Store, key string, obj *object.Obj) {
+	hash := obj.Value.(HashMap)
+	var missing []string
+	store.FieldExpiries(key, func(field string, _ uint64) {
+		if _, ok := hash[field]; !ok {
+			missing = append(missing, field)
+		}
+	})
+	store.DelFieldExpiry(key, missing...)
+}
+
+// repairObj rebuilds the derived counters and indexes of obj from its primary
+// content. It returns false if the value cannot be recovered.
+func repairObj(obj *object.Obj) bool {
+	oType, _ := object.ExtractTypeEncoding(obj)
+	switch oType {
+	case object.ObjTypeByteList:
+		q, ok := obj.Value.(*Deque)
+		if !ok {
+			return false
+		}
+		q.Length = dequeLen(q)
+	case object.ObjTypeByteArray:
+		b, ok := obj.Value.(*ByteArray)
+		if !ok {
+			return false
+		}
+		b.Length = int64(len(b.data))
+	case object.ObjTypeSortedSet:
+		// the dictionary is the source of truth, the index is rebuilt from it
+		parts, ok := obj.Value.([]interface{})
+		if !ok || len(parts) != 2 {
+			return false
+		}
+		scores, ok := parts[1].(map[string]float64)
+		if !ok {
+			return false
+		}
+		// the payloads are only held by the index, they are kept when found in
+		// it, the walk being bounded in case the links of the index loop
+		payloads := make(map[string]string)
+		if old, ok := parts[0].(*skipList); ok {
+			walked := 0
+			old.Ascend(func(item *SortedSetItem) bool {
+				if item.Payload != "" {
+					payloads[item.Member] = item.Payload
+				}
+				walked++
+				return walked < len(scores)
+			})
+		}
+		tree := newSkipList()
+		for member, score := range scores {
+			tree.Insert(&SortedSetItem{Score: score, Member: member, Payload: payloads[member]})
+		}
+		parts[0] = tree
+	case object.ObjTypeStream:
+		s, ok := obj.Value.(*Stream)
+		if !ok {
+			return false
+		}
+		if maxID, ok := s.lastEntryID(); ok && s.lastID.Less(maxID) {
+			s.lastID = maxID
+		}
+		s.length = streamNodesLen(s)
+		for _, g := range s.groups {
+			counts := streamGroupPendingCounts(g)
+			for consumer, n := range counts {
+				if _, ok := g.consumers[consumer]; !ok {
+					g.consumers[consumer] = &StreamConsumer{Name: consumer}
+				}
+				g.consumers[consumer].Pending = n
+			}
+			for consumer, c := range g.consumers {
+				c.Pending = counts[consumer]
+			}
+		}
+	default:
+		return false
+	}
+	return validateObj(obj) == nil
+}
@@ -0,0 +1,148 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func writeTestAOF(t *testing.T, cmds [][]string, tail string) string {
+	t.Helper()
+	var data []byte
+	for _, c := range cmds {
+		args := make([]interface{}, len(c))
+		for i, arg := range c {
+			args[i] = arg
+		}
+		data = append(data, clientio.Encode(args, false)...)
+		data = append(data, '\n')
+	}
+	data = append(data, tail...)
+
+	path := filepath.Join(t.TempDir(), "dice-master.aof")
+	assert.NilError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestCheckAOF(t *testing.T) {
+	cmds := [][]string{
+		{"SET", "k1", "v1"},
+		{"RPUSH", "l1", "a", "b", "c"},
+		{"ZADD", "z1", "1", "a", "2", "b"},
+		{"INCR", "k1"},
+	}
+
+	t.Run("valid file", func(t *testing.T) {
+		path := writeTestAOF(t, cmds[:3], "")
+		report, err := CheckAOF(path, false)
+		assert.NilError(t, err)
+		assert.Assert(t, report.OK())
+		assert.Equal(t, 3, report.Commands)
+		assert.Equal(t, 3, report.Keys)
+	})
+
+	t.Run("failing command and truncated tail", func(t *testing.T) {
+		path := writeTestAOF(t, cmds, "*2\r\n$3\r\nDEL")
+		report, err := CheckAOF(path, false)
+		assert.NilError(t, err)
+		assert.Equal(t, 3, report.Commands)
+		assert.Equal(t, int64(11), report.Truncated)
+		assert.Equal(t, 2, len(report.Issues))
+		assert.Equal(t, "", report.Issues[0].Action)
+
+		report, err = CheckAOF(path, true)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(report.Issues))
+		assert.Equal(t, "dropped", report.Issues[1].Action)
+
+		report, err = CheckAOF(path, false)
+		assert.NilError(t, err)
+		assert.Assert(t, report.OK())
+		assert.Equal(t, 3, report.Commands)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := CheckAOF(filepath.Join(t.TempDir(), "missing.aof"), false)
+		assert.Assert(t, os.IsNotExist(err))
+	})
+}
+
+func TestCheckStore(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalRPUSH([]string{"l1", "a", "b", "c"}, store)
+	evalZADD([]string{"z1", "1", "a", "2", "b"}, store)
+	evalSET([]string{"k1", "v1"}, store)
+
+	report := &CheckReport{}
+	CheckStore(store, false, report)
+	assert.Assert(t, report.OK())
+	assert.Equal(t, 3, report.Keys)
+
+	// corrupt the length counter of the list and the index of the sorted set
+	store.Get("l1").Value.(*Deque).Length = 5
+	tree := store.Get("z1").Value.([]interface{})[0].(*skipList)
+	tree.Insert(&SortedSetItem{Score: 3, Member: "c"})
+
+	report = &CheckReport{}
+	CheckStore(store, false, report)
+	assert.Equal(t, 2, len(report.Issues))
+
+	report = &CheckReport{}
+	CheckStore(store, true, report)
+	assert.Equal(t, 2, len(report.Issues))
+	for _, issue := range report.Issues {
+		assert.Equal(t, "repaired", issue.Action)
+	}
+	assert.Equal(t, int64(3), store.Get("l1").Value.(*Deque).Length)
+	assert.Equal(t, 2, store.Get("z1").Value.([]interface{})[0].(*skipList).Len())
+
+	// a value which does not match its type cannot be repaired
+	store.Get("k1").Value = 42
+
+	report = &CheckReport{}
+	CheckStore(store, true, report)
+	assert.Equal(t, 1, len(report.Issues))
+	assert.Equal(t, "dropped", report.Issues[0].Action)
+	assert.Assert(t, store.Get("k1") == nil)
+
+	report = &CheckReport{}
+	CheckStore(store, false, report)
+	assert.Assert(t, report.OK())
+}
+
+func TestCheckKeys(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalZADD([]string{"z1", "1", "a", "2", "b"}, store)
+	evalHSET([]string{"h1", "f1", "v1", "f2", "v2"}, store)
+	evalSET([]string{"k1", "v1"}, store)
+	store.SetFieldExpiry("h1", "f1", 1<<62, expireHashFields)
+
+	assert.Equal(t, 0, len(CheckKeys(store, []string{"z1", "h1", "k1", "missing"}, false)))
+
+	// the dictionary of the sorted set loses a member, and a hash field having an
+	// expiry is deleted behind the back of the store
+	delete(store.Get("z1").Value.([]interface{})[1].(map[string]float64), "b")
+	delete(store.Get("h1").Value.(HashMap), "f1")
+	store.Get("k1").Value = 42
+
+	issues := CheckKeys(store, []string{"z1", "h1", "k1"}, false)
+	assert.Equal(t, 3, len(issues))
+	assert.Equal(t, "", issues[0].Action)
+
+	issues = CheckKeys(store, []string{"z1", "h1", "k1"}, true)
+	assert.Equal(t, 3, len(issues))
+	assert.Equal(t, "repaired", issues[0].Action)
+	assert.Equal(t, "repaired", issues[1].Action)
+	// the broken keys are never deleted from a live store
+	assert.Equal(t, "", issues[2].Action)
+	assert.Assert(t, store.Get("k1") != nil)
+
+	assert.Equal(t, 1, len(CheckKeys(store, []string{"z1", "h1", "k1"}, false)))
+	_, ok := store.FieldExpiry("h1", "f1")
+	assert.Assert(t, !ok)
+}
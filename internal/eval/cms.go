@@ -0,0 +1,361 @@
+package eval
+
+import (
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hashing"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var (
+	errInvalidCMSDimensions  = diceerrors.NewErr("invalid width or depth value provided")
+	errInvalidCMSErrorRate   = diceerrors.NewErr("invalid error rate value provided")
+	errInvalidCMSProbability = diceerrors.NewErr("invalid probability value provided")
+	errInvalidCMSIncrement   = diceerrors.NewErr("invalid increment value provided")
+	errInvalidCMSNumKeys     = diceerrors.NewErr("invalid number of keys provided")
+	errInvalidCMSWeight      = diceerrors.NewErr("invalid weight value provided")
+
+	errCMSKeyExists      = diceerrors.NewErr("key already exists")
+	errCMSInvalidKey     = diceerrors.NewErr("invalid key: no count-min sketch found")
+	errCMSDimsMismatch   = diceerrors.NewErr("width and depth of the sketches are not equal")
+	errCMSHasherMismatch = diceerrors.NewErr("hash functions of the sketches are not equal")
+	errCMSCountOverflow  = diceerrors.NewErr("counter overflow")
+)
+
+// CountMinSketch counts the frequency of items in a fixed amount of memory.
+// Every item increments one counter in each of the `depth` rows; its count is
+// estimated as the minimum of these counters, which never underestimates it.
+type CountMinSketch struct {
+	width  uint64
+	depth  uint64
+	count  uint64   // total of the increments
+	matrix []uint64 // depth rows of width counters
+	hasher hashing.Hasher
+}
+
+// newCMSDimensions extracts the width and the depth of a sketch from `args`,
+// either given as is, or derived from the error rate and the probability of
+// exceeding it if `byProb` is set to true.
+func newCMSDimensions(args []string, byProb bool) (width, depth uint64, err error) {
+	if !byProb {
+		width, err = strconv.ParseUint(args[0], 10, 64)
+		if err != nil || width < 1 {
+			return 0, 0, errInvalidCMSDimensions
+		}
+		depth, err = strconv.ParseUint(args[1], 10, 64)
+		if err != nil || depth < 1 {
+			return 0, 0, errInvalidCMSDimensions
+		}
+		if hi, size := bits.Mul64(width, depth); hi != 0 || size > math.MaxInt32 {
+			return 0, 0, errInvalidCMSDimensions
+		}
+		return width, depth, nil
+	}
+
+	errorRate, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || errorRate <= 0 || errorRate >= 1 {
+		return 0, 0, errInvalidCMSErrorRate
+	}
+	probability, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || probability <= 0 || probability >= 1 {
+		return 0, 0, errInvalidCMSProbability
+	}
+
+	// The count of an item is overestimated by more than errorRate times the
+	// total of the increments with the given probability
+	// 		width = ceil(2 / errorRate)
+	// 		depth = ceil(log(probability) / log(0.5))
+	w := math.Ceil(2 / errorRate)
+	d := math.Ceil(math.Log(probability) / math.Log(0.5))
+	if w*d > math.MaxInt32 {
+		return 0, 0, errInvalidCMSDimensions
+	}
+	return uint64(w), uint64(d), nil
+}
+
+func newCountMinSketch(width, depth uint64) *CountMinSketch {
+	return &CountMinSketch{
+		width:  width,
+		depth:  depth,
+		matrix: make([]uint64, width*depth),
+		hasher: sketchHasher(),
+	}
+}
+
+// index returns the position of the counter of `item` in the row `row`. Rows
+// use hash functions with fixed seeds, so that sketches can be merged.
+func (c *CountMinSketch) index(row uint64, item string) uint64 {
+	return row*c.width + c.hasher.Nth(row, item)%c.width
+}
+
+// incrBy increments the counters of `item` by `incr` and returns its new count.
+// The counters saturate instead of overflowing.
+func (c *CountMinSketch) incrBy(item string, incr uint64) uint64 {
+	c.count = saturatingAdd(c.count, incr)
+
+	estimate := uint64(math.MaxUint64)
+	for row := uint64(0); row < c.depth; row++ {
+		i := c.index(row, item)
+		c.matrix[i] = saturatingAdd(c.matrix[i], incr)
+		estimate = min(estimate, c.matrix[i])
+	}
+	return estimate
+}
+
+// query returns the estimated count of `item`.
+func (c *CountMinSketch) query(item string) uint64 {
+	estimate := uint64(math.MaxUint64)
+	for row := uint64(0); row < c.depth; row++ {
+		estimate = min(estimate, c.matrix[c.index(row, item)])
+	}
+	return estimate
+}
+
+// merge replaces the counters of the sketch with the weighted sums of the
+// counters of `sources`, which must have the same dimensions. The sketch is
+// left untouched if any of the sums overflows or is negative.
+func (c *CountMinSketch) merge(sources []*CountMinSketch, weights []int64) error {
+	for _, src := range sources {
+		if src.width != c.width || src.depth != c.depth {
+			return errCMSDimsMismatch
+		}
+		if src.hasher != c.hasher {
+			return errCMSHasherMismatch
+		}
+	}
+
+	sum := func(values func(*CountMinSketch) uint64) (uint64, error) {
+		var total int64
+		for j, src := range sources {
+			v := values(src)
+			if v > math.MaxInt64 {
+				return 0, errCMSCountOverflow
+			}
+			product := int64(v) * weights[j]
+			if v != 0 && product/int64(v) != weights[j] {
+				return 0, errCMSCountOverflow
+			}
+			next := total + product
+			if (product > 0 && next < total) || (product < 0 && next > total) {
+				return 0, errCMSCountOverflow
+			}
+			total = next
+		}
+		if total < 0 {
+			return 0, errCMSCountOverflow
+		}
+		return uint64(total), nil
+	}
+
+	matrix := make([]uint64, len(c.matrix))
+	for i := range matrix {
+		v, err := sum(func(src *CountMinSketch) uint64 { return src.matrix[i] })
+		if err != nil {
+			return err
+		}
+		matrix[i] = v
+	}
+	count, err := sum(func(src *CountMinSketch) uint64 { return src.count })
+	if err != nil {
+		return err
+	}
+
+	c.matrix, c.count = matrix, count
+	return nil
+}
+
+// DeepCopy creates a deep copy of the CountMinSketch struct
+func (c *CountMinSketch) DeepCopy() interface{} {
+	matrix := make([]uint64, len(c.matrix))
+	copy(matrix, c.matrix)
+
+	return &CountMinSketch{
+		width:  c.width,
+		depth:  c.depth,
+		count:  c.count,
+		matrix: matrix,
+		hasher: c.hasher,
+	}
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+// evalCMSINITBYDIM evaluates the CMS.INITBYDIM command responsible for creating
+// a count-min sketch of the given width and depth.
+func evalCMSINITBYDIM(args []string, store *dstore.Store) []byte {
+	return cmsInit("CMS.INITBYDIM", args, store, false)
+}
+
+// evalCMSINITBYPROB evaluates the CMS.INITBYPROB command responsible for
+// creating a count-min sketch whose estimates exceed the true counts by more
+// than the given error rate, relative to the total of the increments, with
+// the given probability.
+func evalCMSINITBYPROB(args []string, store *dstore.Store) []byte {
+	return cmsInit("CMS.INITBYPROB", args, store, true)
+}
+
+func cmsInit(name string, args []string, store *dstore.Store, byProb bool) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity(name)
+	}
+
+	width, depth, err := newCMSDimensions(args[1:], byProb)
+	if err != nil {
+		return probabilisticErr(name, err)
+	}
+
+	if store.Get(args[0]) != nil {
+		return probabilisticErr(name, errCMSKeyExists)
+	}
+	store.Put(args[0], store.NewObj(newCountMinSketch(width, depth), -1, object.ObjTypeCountMinSketch, object.ObjEncodingCMS))
+
+	return clientio.RespOK
+}
+
+// evalCMSINCRBY evaluates the CMS.INCRBY command responsible for incrementing
+// the counts of items in a count-min sketch. It returns the new estimated
+// count of each item.
+//
+// Usage: CMS.INCRBY key item increment [item increment ...]
+func evalCMSINCRBY(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 || len(args)%2 != 1 {
+		return diceerrors.NewErrArity("CMS.INCRBY")
+	}
+
+	cms, err := getCountMinSketch(args[0], store)
+	if err != nil {
+		return probabilisticErr("CMS.INCRBY", err)
+	}
+
+	// validate all the increments before applying any of them
+	increments := make([]uint64, 0, len(args)/2)
+	for i := 2; i < len(args); i += 2 {
+		incr, err := strconv.ParseUint(args[i], 10, 64)
+		if err != nil {
+			return probabilisticErr("CMS.INCRBY", errInvalidCMSIncrement)
+		}
+		increments = append(increments, incr)
+	}
+
+	counts := make([]interface{}, len(increments))
+	for j, incr := range increments {
+		counts[j] = cms.incrBy(args[1+2*j], incr)
+	}
+	return clientio.Encode(counts, false)
+}
+
+// evalCMSQUERY evaluates the CMS.QUERY command, which returns the estimated
+// count of each of the given items.
+func evalCMSQUERY(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("CMS.QUERY")
+	}
+
+	cms, err := getCountMinSketch(args[0], store)
+	if err != nil {
+		return probabilisticErr("CMS.QUERY", err)
+	}
+
+	counts := make([]interface{}, len(args)-1)
+	for i, item := range args[1:] {
+		counts[i] = cms.query(item)
+	}
+	return clientio.Encode(counts, false)
+}
+
+// evalCMSINFO evaluates the CMS.INFO command, which returns the width, the
+// depth and the total of the increments of a count-min sketch.
+func evalCMSINFO(args []string, store *dstore.Store) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("CMS.INFO")
+	}
+
+	cms, err := getCountMinSketch(args[0], store)
+	if err != nil {
+		return probabilisticErr("CMS.INFO", err)
+	}
+
+	return clientio.Encode([]interface{}{"width", cms.width, "depth", cms.depth, "count", cms.count}, false)
+}
+
+// evalCMSMERGE evaluates the CMS.MERGE command, which sets the counters of the
+// destination sketch to the sums of the counters of the source sketches,
+// each multiplied by its weight (1 by default). All the sketches must exist
+// and have the same width and depth.
+//
+// Usage: CMS.MERGE destination numKeys source [source ...] [WEIGHTS weight [weight ...]]
+func evalCMSMERGE(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("CMS.MERGE")
+	}
+
+	numKeys, err := strconv.Atoi(args[1])
+	if err != nil || numKeys < 1 || numKeys > len(args)-2 {
+		return probabilisticErr("CMS.MERGE", errInvalidCMSNumKeys)
+	}
+
+	weights := make([]int64, numKeys)
+	for i := range weights {
+		weights[i] = 1
+	}
+	if rest := args[2+numKeys:]; len(rest) > 0 {
+		if !strings.EqualFold(rest[0], "WEIGHTS") || len(rest) != numKeys+1 {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		for i, w := range rest[1:] {
+			if weights[i], err = strconv.ParseInt(w, 10, 64); err != nil {
+				return probabilisticErr("CMS.MERGE", errInvalidCMSWeight)
+			}
+		}
+	}
+
+	dst, err := getCountMinSketch(args[0], store)
+	if err != nil {
+		return probabilisticErr("CMS.MERGE", err)
+	}
+	sources := make([]*CountMinSketch, numKeys)
+	for i, key := range args[2 : 2+numKeys] {
+		if sources[i], err = getCountMinSketch(key, store); err != nil {
+			return probabilisticErr("CMS.MERGE", err)
+		}
+	}
+
+	if err := dst.merge(sources, weights); err != nil {
+		return probabilisticErr("CMS.MERGE", err)
+	}
+	return clientio.RespOK
+}
+
+// getCountMinSketch fetches an existing count-min sketch from the kv store.
+func getCountMinSketch(key string, store *dstore.Store) (*CountMinSketch, error) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, errCMSInvalidKey
+	}
+
+	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeCountMinSketch); err != nil {
+		return nil, errWrongType
+	}
+
+	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingCMS); err != nil {
+		return nil, err
+	}
+
+	cms, ok := obj.Value.(*CountMinSketch)
+	if !ok {
+		return nil, errWrongType
+	}
+	return cms, nil
+}
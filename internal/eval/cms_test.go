@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	// CMS.INITBYDIM and CMS.INITBYPROB
+	assert.Equal(t, "-ERR wrong number of arguments for 'cms.initbydim' command\r\n", string(evalCMSINITBYDIM([]string{"cms", "10"}, store)))
+	assert.Equal(t, "-ERR invalid width or depth value provided for 'CMS.INITBYDIM' command\r\n", string(evalCMSINITBYDIM([]string{"cms", "0", "5"}, store)))
+	assert.Equal(t, string(clientio.RespOK), string(evalCMSINITBYDIM([]string{"cms", "1000", "5"}, store)))
+	assert.Equal(t, "-ERR key already exists for 'CMS.INITBYDIM' command\r\n", string(evalCMSINITBYDIM([]string{"cms", "1000", "5"}, store)))
+	assert.Equal(t, "-ERR invalid error rate value provided for 'CMS.INITBYPROB' command\r\n", string(evalCMSINITBYPROB([]string{"prob", "1.5", "0.01"}, store)))
+	assert.Equal(t, string(clientio.RespOK), string(evalCMSINITBYPROB([]string{"prob", "0.001", "0.01"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{"width", uint64(2000), "depth", uint64(7), "count", uint64(0)}, false),
+		evalCMSINFO([]string{"prob"}, store))
+
+	// CMS.INCRBY and CMS.QUERY
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(5), uint64(1)}, false),
+		evalCMSINCRBY([]string{"cms", "a", "5", "b", "1"}, store))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(7)}, false), evalCMSINCRBY([]string{"cms", "a", "2"}, store))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(7), uint64(1), uint64(0)}, false),
+		evalCMSQUERY([]string{"cms", "a", "b", "c"}, store))
+	assert.Equal(t, "-ERR invalid increment value provided for 'CMS.INCRBY' command\r\n", string(evalCMSINCRBY([]string{"cms", "a", "1", "b", "-1"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(7)}, false), evalCMSQUERY([]string{"cms", "a"}, store))
+	assert.Equal(t, "-ERR invalid key: no count-min sketch found for 'CMS.QUERY' command\r\n", string(evalCMSQUERY([]string{"missing", "a"}, store)))
+
+	// Commands against a key holding the wrong kind of value
+	store.Put("str", store.NewObj("value", -1, 0, 0))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", string(evalCMSQUERY([]string{"str", "a"}, store)))
+}
+
+func TestCountMinSketchMerge(t *testing.T) {
+	store := dstore.NewStore(nil)
+	for _, key := range []string{"dst", "src1", "src2"} {
+		evalCMSINITBYDIM([]string{key, "100", "4"}, store)
+	}
+	evalCMSINITBYDIM([]string{"small", "10", "4"}, store)
+	// a sketch created by a server hashing with another seed
+	evalCMSINITBYDIM([]string{"seeded", "100", "4"}, store)
+	store.Get("seeded").Value.(*CountMinSketch).hasher.Seed = 1
+	evalCMSINCRBY([]string{"src1", "a", "3", "b", "1"}, store)
+	evalCMSINCRBY([]string{"src2", "a", "2"}, store)
+
+	assert.Equal(t, string(clientio.RespOK), string(evalCMSMERGE([]string{"dst", "2", "src1", "src2"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(5), uint64(1)}, false), evalCMSQUERY([]string{"dst", "a", "b"}, store))
+
+	// weights apply to each source, the destination can be one of them
+	assert.Equal(t, string(clientio.RespOK), string(evalCMSMERGE([]string{"dst", "2", "dst", "src2", "WEIGHTS", "2", "-1"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(8), uint64(2)}, false), evalCMSQUERY([]string{"dst", "a", "b"}, store))
+	assert.Equal(t, uint64(10), store.Get("dst").Value.(*CountMinSketch).count)
+
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"missing source":     {[]string{"dst", "2", "src1", "missing"}, "-ERR invalid key: no count-min sketch found for 'CMS.MERGE' command\r\n"},
+		"invalid numkeys":    {[]string{"dst", "3", "src1", "src2"}, "-ERR invalid number of keys provided for 'CMS.MERGE' command\r\n"},
+		"missing weights":    {[]string{"dst", "2", "src1", "src2", "WEIGHTS", "1"}, "-ERR syntax error\r\n"},
+		"invalid weight":     {[]string{"dst", "1", "src1", "WEIGHTS", "x"}, "-ERR invalid weight value provided for 'CMS.MERGE' command\r\n"},
+		"dimensions":         {[]string{"dst", "1", "small"}, "-ERR width and depth of the sketches are not equal for 'CMS.MERGE' command\r\n"},
+		"hash functions":     {[]string{"dst", "1", "seeded"}, "-ERR hash functions of the sketches are not equal for 'CMS.MERGE' command\r\n"},
+		"negative counts":    {[]string{"dst", "1", "src1", "WEIGHTS", "-1"}, "-ERR counter overflow for 'CMS.MERGE' command\r\n"},
+		"overflowing counts": {[]string{"dst", "1", "src1", "WEIGHTS", fmt.Sprint(math.MaxInt64)}, "-ERR counter overflow for 'CMS.MERGE' command\r\n"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(evalCMSMERGE(tc.args, store)))
+			// a failed merge leaves the destination untouched
+			assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(8), uint64(2)}, false), evalCMSQUERY([]string{"dst", "a", "b"}, store))
+		})
+	}
+}
+
+func TestCountMinSketchCopy(t *testing.T) {
+	saved := config.DiceConfig.Server
+	defer func() { config.DiceConfig.Server = saved }()
+	config.DiceConfig.Server.SketchHashFamily = "xxhash"
+	config.DiceConfig.Server.SketchHashSeed = 7
+
+	// the copy hashes the items like the sketch it is copied from
+	store := dstore.NewStore(nil)
+	evalCMSINITBYDIM([]string{"c", "100", "4"}, store)
+	evalCMSINCRBY([]string{"c", "a", "10"}, store)
+	assert.Equal(t, string(clientio.RespOne), string(evalCOPY([]string{"c", "c2"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint64(10)}, false), evalCMSQUERY([]string{"c2", "a"}, store))
+}
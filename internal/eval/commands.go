@@ -25,12 +25,83 @@ type DiceCmdMeta struct {
 	// will utilize this function for evaluation, allowing for better handling of
 	// complex command execution scenarios and improved response consistency.
 	NewEval func([]string, *dstore.Store) *EvalResponse
+
+	// IsWrite indicates whether the command may modify the keyspace. Write commands
+	// are propagated to the replicas once executed, and refused by the replicas
+	// when sent by a client.
+	IsWrite bool
+
+	// IsReadOnly indicates whether the command only reads the keyspace. The clients
+	// may send the read-only commands to the replicas, which refuse them once too
+	// stale, see config.DiceConfig.Server.ReplicaMaxStaleness.
+	IsReadOnly bool
+
+	// FreesMemory indicates whether the write command can only delete data, e.g.
+	// DEL. Those commands are still served once the memory used is above
+	// config.DiceConfig.Server.MaxMemory and no key can be evicted, whereas the
+	// other write commands are refused.
+	FreesMemory bool
+
+	// BlockingEval evaluates the commands that may block their client till one of
+	// their keys is modified, e.g. BLPOP. It returns the reply of the command, or
+	// what the command waits for if it cannot be served yet, see Blocked.
+	BlockingEval func([]string, *dstore.Store) ([]byte, *Blocked)
+
+	// CompatReplies maps the replies of the command that differ from the ones of
+	// a reference server to the replies of the reference server, by version of
+	// the reference server, see config.DiceConfig.Server.ReplyCompat and
+	// CompatReply.
+	CompatReplies map[string]compatTable
 }
 
 type KeySpecs struct {
 	BeginIndex int
 	Step       int
-	LastKey    int
+	LastKey    int // index of the last key, or its offset from the end of the arguments if negative
+}
+
+// keys returns the keys among the arguments of a command, its name excluded.
+// It returns nil if the command has no key arguments.
+func (keySpecs KeySpecs) keys(args []string) []string {
+	if keySpecs.BeginIndex == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0)
+	for _, i := range keySpecs.keyIndexes(args) {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+// keyIndexes returns the indexes of the keys among the arguments of a command,
+// its name excluded.
+func (keySpecs KeySpecs) keyIndexes(args []string) []int {
+	if keySpecs.BeginIndex == 0 {
+		return nil
+	}
+
+	var indexes []int
+	step := max(keySpecs.Step, 1)
+	lastIdx := keySpecs.BeginIndex
+	if keySpecs.LastKey > 0 {
+		lastIdx = keySpecs.LastKey
+	} else if keySpecs.LastKey < 0 {
+		lastIdx = len(args) + 1 + keySpecs.LastKey
+	}
+	for i := keySpecs.BeginIndex; i <= lastIdx && i <= len(args); i += step {
+		indexes = append(indexes, i-1)
+	}
+	return indexes
+}
+
+// checkArity returns true if args, the arguments of the command without its
+// name, match the arity of the command.
+func (diceCmd *DiceCmdMeta) checkArity(args []string) bool {
+	if diceCmd.Arity < 0 {
+		return len(args)+1 >= -diceCmd.Arity
+	}
+	return len(args)+1 == diceCmd.Arity
 }
 
 var (
@@ -40,7 +111,7 @@ var (
 		Name:  "ECHO",
 		Info:  `ECHO returns the string given as argument.`,
 		Eval:  evalECHO,
-		Arity: 1,
+		Arity: 2,
 	}
 
 	pingCmdMeta = DiceCmdMeta{
@@ -66,6 +137,10 @@ var (
 		KeySpecs:   KeySpecs{BeginIndex: 1},
 		IsMigrated: true,
 		NewEval:    evalSET,
+		IsWrite:    true,
+		CompatReplies: map[string]compatTable{
+			CompatRedis7: {"-ERR invalid expire time in 'SET' command\r\n": "-ERR invalid expire time in 'set' command\r\n"},
+		},
 	}
 	getCmdMeta = DiceCmdMeta{
 		Name: "GET",
@@ -77,21 +152,25 @@ var (
 		KeySpecs:   KeySpecs{BeginIndex: 1},
 		IsMigrated: true,
 		NewEval:    evalGET,
+		IsReadOnly: true,
 	}
 
 	getSetCmdMeta = DiceCmdMeta{
 		Name:       "GETSET",
 		Info:       `GETSET returns the previous string value of a key after setting it to a new value.`,
-		Arity:      2,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
 		IsMigrated: true,
 		NewEval:    evalGETSET,
+		IsWrite:    true,
 	}
 
 	authCmdMeta = DiceCmdMeta{
 		Name: "AUTH",
 		Info: `AUTH returns with an encoded "OK" if the user is authenticated.
 		If the user is not authenticated, it returns with an encoded error message`,
-		Eval: nil,
+		Eval:  nil,
+		Arity: -2,
 	}
 	getDelCmdMeta = DiceCmdMeta{
 		Name: "GETDEL",
@@ -99,9 +178,11 @@ var (
 		The key should be the only param in args And If the key exists, it will be deleted before its value is returned.
 		The RESP value of the key is encoded and then returned
 		GETDEL returns RespNIL if key is expired or it does not exist`,
-		Eval:     evalGETDEL,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:        evalGETDEL,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	msetCmdMeta = DiceCmdMeta{
 		Name: "MSET",
@@ -111,6 +192,7 @@ var (
 		Returns encoded error response if the number of arguments is not even
 		Returns encoded OK RESP once all entries are added`,
 		Eval:     evalMSET,
+		IsWrite:  true,
 		Arity:    -3,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 2, LastKey: -1},
 	}
@@ -121,7 +203,8 @@ var (
 		Returns OK if successful.
 		Returns encoded error message if the number of arguments is incorrect or the JSON string is invalid.`,
 		Eval:     evalJSONSET,
-		Arity:    -3,
+		IsWrite:  true,
+		Arity:    -4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsongetCmdMeta = DiceCmdMeta{
@@ -130,9 +213,10 @@ var (
 		Returns the encoded RESP value of the key, if present
 		Null reply: If the key doesn't exist or has expired.
 		Error reply: If the number of arguments is incorrect or the stored value is not a JSON type.`,
-		Eval:     evalJSONGET,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONGET,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	jsonMGetCmdMeta = DiceCmdMeta{
 		Name: "JSON.MGET",
@@ -140,9 +224,10 @@ var (
 		Returns the encoded RESP value of the key, if present
 		Null reply: If the key doesn't exist or has expired.
 		Error reply: If the number of arguments is incorrect or the stored value is not a JSON type.`,
-		Eval:     evalJSONMGET,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONMGET,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -2},
+		IsReadOnly: true,
 	}
 	jsontoggleCmdMeta = DiceCmdMeta{
 		Name: "JSON.TOGGLE",
@@ -157,7 +242,8 @@ var (
     	2.NONEXISTENT if the document key does not exist.
     	3.WRONGTYPE error if the value at the path is not a Boolean value.`,
 		Eval:     evalJSONTOGGLE,
-		Arity:    2,
+		IsWrite:  true,
+		Arity:    3,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsontypeCmdMeta = DiceCmdMeta{
@@ -166,9 +252,10 @@ var (
 		Returns string reply for each path, specified as the value's type.
 		Returns RespNIL If the key doesn't exist.
 		Error reply: If the number of arguments is incorrect.`,
-		Eval:     evalJSONTYPE,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONTYPE,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	jsonclearCmdMeta = DiceCmdMeta{
 		Name: "JSON.CLEAR",
@@ -177,6 +264,7 @@ var (
 		objects cleared +number of matching JSON numerical values zeroed.
 		Error reply: If the number of arguments is incorrect the key doesn't exist.`,
 		Eval:     evalJSONCLEAR,
+		IsWrite:  true,
 		Arity:    -2,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
@@ -186,17 +274,21 @@ var (
 		Returns an integer reply specified as the number of paths deleted (0 or more).
 		Returns RespZero if the key doesn't exist or key is expired.
 		Error reply: If the number of arguments is incorrect.`,
-		Eval:     evalJSONDEL,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:        evalJSONDEL,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	jsonarrappendCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRAPPEND",
 		Info: `JSON.ARRAPPEND key [path] value [value ...]
         Returns an array of integer replies for each path, the array's new size,
         or nil, if the matching JSON value is not an array.`,
-		Eval:  evalJSONARRAPPEND,
-		Arity: -3,
+		Eval:     evalJSONARRAPPEND,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonforgetCmdMeta = DiceCmdMeta{
 		Name: "JSON.FORGET",
@@ -204,9 +296,11 @@ var (
 		Returns an integer reply specified as the number of paths deleted (0 or more).
 		Returns RespZero if the key doesn't exist or key is expired.
 		Error reply: If the number of arguments is incorrect.`,
-		Eval:     evalJSONFORGET,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:        evalJSONFORGET,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	jsonarrlenCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRLEN",
@@ -214,16 +308,18 @@ var (
 		Returns an array of integer replies.
 		Returns error response if the key doesn't exist or key is expired or the matching value is not an array.
 		Error reply: If the number of arguments is incorrect.`,
-		Eval:     evalJSONARRLEN,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONARRLEN,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	jsonnummultbyCmdMeta = DiceCmdMeta{
 		Name: "JSON.NUMMULTBY",
 		Info: `JSON.NUMMULTBY key path value
 		Multiply the number value stored at the specified path by a value.`,
 		Eval:     evalJSONNUMMULTBY,
-		Arity:    3,
+		IsWrite:  true,
+		Arity:    4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonobjlenCmdMeta = DiceCmdMeta{
@@ -232,9 +328,10 @@ var (
 		Report the number of keys in the JSON object at path in key
 		Returns error response if the key doesn't exist or key is expired or the matching value is not an array.
 		Error reply: If the number of arguments is incorrect.`,
-		Eval:     evalJSONOBJLEN,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONOBJLEN,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	jsondebugCmdMeta = DiceCmdMeta{
 		Name: "JSON.DEBUG",
@@ -242,9 +339,10 @@ var (
 		JSON.DEBUG MEMORY returns memory usage by key in bytes
 		JSON.DEBUG HELP displays help message
 		`,
-		Eval:     evalJSONDebug,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONDebug,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 2},
+		IsReadOnly: true,
 	}
 	jsonobjkeysCmdMeta = DiceCmdMeta{
 		Name: "JSON.OBJKEYS",
@@ -252,8 +350,10 @@ var (
 		Retrieves the keys of a JSON object stored at path specified.
 		Null reply: If the key doesn't exist or has expired.
 		Error reply: If the number of arguments is incorrect or the stored value is not a JSON type.`,
-		Eval:  evalJSONOBJKEYS,
-		Arity: 2,
+		Eval:       evalJSONOBJKEYS,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	jsonarrpopCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRPOP",
@@ -263,8 +363,10 @@ var (
 		Return nil if array is empty or there is no array at the path.
 		It supports negative index and is out of bound safe.
 		`,
-		Eval:  evalJSONARRPOP,
-		Arity: -2,
+		Eval:     evalJSONARRPOP,
+		IsWrite:  true,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsoningestCmdMeta = DiceCmdMeta{
 		Name: "JSON.INGEST",
@@ -274,7 +376,8 @@ var (
 		Returns unique identifier if successful.
 		Returns encoded error message if the number of arguments is incorrect or the JSON string is invalid.`,
 		Eval:     evalJSONINGEST,
-		Arity:    -3,
+		IsWrite:  true,
+		Arity:    -4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonarrinsertCmdMeta = DiceCmdMeta{
@@ -285,6 +388,7 @@ var (
 		Returns error response if the key doesn't exist or key is expired or the matching value is not an array.
 		Error reply: If the number of arguments is incorrect.`,
 		Eval:     evalJSONARRINSERT,
+		IsWrite:  true,
 		Arity:    -5,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
@@ -292,9 +396,10 @@ var (
 		Name: "JSON.RESP",
 		Info: `JSON.RESP key [path]
 		Return the JSON in key in Redis serialization protocol specification form`,
-		Eval:     evalJSONRESP,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONRESP,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	jsonarrtrimCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRTRIM",
@@ -303,8 +408,10 @@ var (
 		Returns an array of integer replies for each path.
 		Returns error response if the key doesn't exist or key is expired.
 		Error reply: If the number of arguments is incorrect.`,
-		Eval:  evalJSONARRTRIM,
-		Arity: -5,
+		Eval:     evalJSONARRTRIM,
+		IsWrite:  true,
+		Arity:    5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	ttlCmdMeta = DiceCmdMeta{
 		Name: "TTL",
@@ -314,17 +421,20 @@ var (
 		RESP encoded time (in secs) remaining for the key to expire
 		RESP encoded -2 stating key doesn't exist or key is expired
 		RESP encoded -1 in case no expiration is set on the key`,
-		Eval:     evalTTL,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalTTL,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	delCmdMeta = DiceCmdMeta{
 		Name: "DEL",
 		Info: `DEL deletes all the specified keys in args list
 		returns the count of total deleted keys after encoding`,
-		Eval:     evalDEL,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		Eval:        evalDEL,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -2,
+		KeySpecs:    KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
 	}
 	expireCmdMeta = DiceCmdMeta{
 		Name: "EXPIRE",
@@ -333,9 +443,11 @@ var (
 		The expiry time should be in integer format; if not, it returns encoded error response
 		Returns RespOne if expiry was set on the key successfully.
 		Once the time is lapsed, the key will be deleted automatically`,
-		Eval:     evalEXPIRE,
-		Arity:    -3,
-		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+		Eval:        evalEXPIRE,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -3,
+		KeySpecs:    KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	helloCmdMeta = DiceCmdMeta{
 		Name:  "HELLO",
@@ -360,6 +472,7 @@ var (
 		if not INCR returns encoded error response.
 		evalINCR returns the incremented value for the key if there are no errors.`,
 		Eval:     evalINCR,
+		IsWrite:  true,
 		Arity:    2,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
@@ -372,8 +485,10 @@ var (
 		If the value at the key is a string, it should be parsable to float64,
 		if not INCRBYFLOAT returns an  error response.
 		INCRBYFLOAT returns the incremented value for the key after applying the specified increment if there are no errors.`,
-		Eval:  evalINCRBYFLOAT,
-		Arity: 2,
+		Eval:     evalINCRBYFLOAT,
+		IsWrite:  true,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	infoCmdMeta = DiceCmdMeta{
 		Name: "INFO",
@@ -383,17 +498,116 @@ var (
 		Arity: -1,
 	}
 	clientCmdMeta = DiceCmdMeta{
-		Name:  "CLIENT",
-		Info:  `This is a container command for client connection commands.`,
+		Name: "CLIENT",
+		Info: `This is a container command for client connection commands.
+		CLIENT PAUSE timeout [WRITE|ALL]
+		Holds back the commands of the clients for timeout milliseconds,
+		only the write commands with WRITE, all of them with ALL, the default. The commands held back run once the pause ends.
+		CLIENT UNPAUSE
+		Ends the pause right away. The CLIENT commands are never held back.
+		CLIENT LIST
+		Returns the connected clients.
+		CLIENT TOKEN
+		Returns the session token of the client, the replication offset reached by its latest write, 0 until it writes.
+		CLIENT READAFTER token
+		Holds back the commands of the client on a replica until it caught up with the token, 0 to read whatever the replica holds.`,
 		Eval:  evalCLIENT,
 		Arity: -2,
 	}
+	aclCmdMeta = DiceCmdMeta{
+		Name: "ACL",
+		Info: `This is a container command for the users and their permissions.
+		ACL SETUSER username [rule ...]
+		Creates or modifies a user with the rules: on, off, >password, <password, #hash, nopass, resetpass, allcommands, nocommands, +command, -command and reset.
+		The connections of the users disabled are killed or downgraded, as told by the revoke policy.
+		ACL DELUSER username [username ...]
+		Deletes the users, their connections being killed or downgraded. Returns the number of users deleted.
+		ACL USERS
+		Returns the names of the users.
+		ACL LIST
+		Returns the users with their rules.
+		ACL LOG [count | RESET]
+		Returns the latest commands denied to the users, or forgets them.`,
+		Eval:  evalACL,
+		Arity: -2,
+	}
+	configCmdMeta = DiceCmdMeta{
+		Name: "CONFIG",
+		Info: `CONFIG RELOAD
+		Reads the config file again and applies the settings that can be changed live, without dropping the connections.
+		Returns the settings applied and the changed settings that require a restart to take effect.
+		CONFIG DEFAULTTTL SET pattern ttl
+		Gives the keys matching the pattern an expiry of ttl, a duration such as 30m, once put without one.
+		CONFIG DEFAULTTTL DEL pattern
+		Removes the default TTL policy of the pattern. Returns 1 if it was removed, 0 otherwise.
+		CONFIG DEFAULTTTL LIST
+		Returns the pattern, the TTL in milliseconds and the number of keys given an expiry of every default TTL policy.`,
+		Eval:  evalCONFIG,
+		Arity: -2,
+	}
 	latencyCmdMeta = DiceCmdMeta{
-		Name:  "LATENCY",
-		Info:  `This is a container command for latency diagnostics commands.`,
+		Name: "LATENCY",
+		Info: `This is a container command for latency diagnostics commands.
+		LATENCY LATEST
+		Returns the name, the unix time of the latest event and the latest and largest latencies in milliseconds of every event.
+		LATENCY RESET [event ...]
+		Forgets the given events, or all of them. Returns the number of events forgotten.`,
 		Eval:  evalLATENCY,
 		Arity: -2,
 	}
+	memoryCmdMeta = DiceCmdMeta{
+		Name: "MEMORY",
+		Info: `This is a container command for memory diagnostics commands.
+		MEMORY STATS
+		Returns the load of the tables of the store as name and value pairs,
+		the fragmentation being the ratio of their capacity left unused once keys are deleted.
+		The sparse tables are rebuilt in the background to release their memory.`,
+		Eval:  evalMEMORY,
+		Arity: -2,
+	}
+	ttljobCmdMeta = DiceCmdMeta{
+		Name: "TTLJOB",
+		Info: `This is a container command for the jobs setting the expiry of the keys matching a pattern.
+		TTLJOB START pattern seconds|PERSIST [BATCH count] [RATE keys-per-second]
+		Starts a job setting the time to live of the keys matching pattern to seconds, or removing their expiry with PERSIST.
+		The keys are updated in the background by batches of at most count keys, at most keys-per-second keys per second.
+		Returns the id of the job.
+		TTLJOB STATUS [id]
+		Returns the status of the job id as name and value pairs, or the status of every job.
+		TTLJOB CANCEL id
+		Stops the job id, the keys already updated keeping their new expiry. Returns 1 if the job was running, 0 otherwise.`,
+		Eval:    evalTTLJOB,
+		IsWrite: true,
+		Arity:   -2,
+	}
+	restoreSnapshotCmdMeta = DiceCmdMeta{
+		Name: "RESTORE-SNAPSHOT",
+		Info: `RESTORE-SNAPSHOT is the container command of the restore jobs, loading a snapshot file into the running instance in batches run by the cron of the shards.
+		RESTORE-SNAPSHOT START path [PREFIX prefix] [BATCH count]
+		Starts a job loading the snapshot file at path, as written by ExportSnapshot, relative to the snapshotdir setting. Returns the id of the job.
+		With PREFIX, prefix is prepended to every key of the snapshot, e.g. to stage a dataset next to the live one.
+		The keys already present are replaced. The commands of the snapshot touching no key fail the job.
+		The jobs are not propagated, they are refused and fail while replicas or CDC subscribers are attached.
+		RESTORE-SNAPSHOT STATUS [id]
+		Returns the status of the job id as name and value pairs, or the status of every job.
+		RESTORE-SNAPSHOT CANCEL id
+		Stops the job id, the keys already loaded being kept. Returns 1 if the job was running, 0 otherwise.`,
+		Eval:    evalRESTORESNAPSHOT,
+		IsWrite: true,
+		Arity:   -2,
+	}
+	debugCmdMeta = DiceCmdMeta{
+		Name: "DEBUG",
+		Info: `This is a container command for the administrative commands meant for testing and for mitigating issues.
+		DEBUG CONVERT key int|embstr|raw|bytearray
+		Converts the value of key to the given encoding in place, keeping its expiry.
+		The strings convert between the int, embstr and raw encodings and the byte array of the bitmaps.
+		The converted value is validated, the key being left as it was if the conversion broke it.`,
+		Eval:     evalDEBUG,
+		IsWrite:  true,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 2},
+	}
 	lruCmdMeta = DiceCmdMeta{
 		Name: "LRU",
 		Info: `LRU deletes all the keys from the LRU
@@ -408,13 +622,14 @@ var (
 		Returns error response if the time param in args is not of integer format.
 		SLEEP returns RespOK after sleeping for mentioned seconds`,
 		Eval:  evalSLEEP,
-		Arity: 1,
+		Arity: 2,
 	}
 	bfinitCmdMeta = DiceCmdMeta{
 		Name: "BFINIT",
 		Info: `BFINIT command initializes a new bloom filter and allocation it's relevant parameters based on given inputs.
 		If no params are provided, it uses defaults.`,
 		Eval:     evalBFINIT,
+		IsWrite:  true,
 		Arity:    -2,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
@@ -424,22 +639,358 @@ var (
 		a bloom filter. If the filter does not exists, it will create a new one
 		with default parameters.`,
 		Eval:     evalBFADD,
+		IsWrite:  true,
 		Arity:    3,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	bfexistsCmdMeta = DiceCmdMeta{
-		Name:     "BFEXISTS",
-		Info:     `BFEXISTS checks existence of an element in a bloom filter.`,
-		Eval:     evalBFEXISTS,
+		Name:       "BFEXISTS",
+		Info:       `BFEXISTS checks existence of an element in a bloom filter.`,
+		Eval:       evalBFEXISTS,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+		CompatReplies: map[string]compatTable{
+			CompatRedis7: {"-ERR invalid key: no bloom filter found for 'BFEXISTS' command\r\n": ":0\r\n"},
+		},
+	}
+	bfreserveCmdMeta = DiceCmdMeta{
+		Name: "BF.RESERVE",
+		Info: `BF.RESERVE key error_rate capacity [EXPANSION expansion] [NONSCALING]
+		Creates an empty bloom filter with the given false positive rate and
+		capacity. Once full, the filter grows by stacking a sub-filter with a
+		tighter error rate. EXPANSION sets the growth factor of the capacity of the
+		sub-filters, 2 by default, and NONSCALING prevents the filter from growing.
+		Returns an error if the key already exists.`,
+		Eval:     evalBFRESERVE,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		CompatReplies: map[string]compatTable{
+			CompatRedis7: {"-ERR item exists for 'BF.RESERVE' command\r\n": "-ERR item exists\r\n"},
+		},
+	}
+	bfmaddCmdMeta = DiceCmdMeta{
+		Name: "BF.MADD",
+		Info: `BF.MADD key item [item ...]
+		Adds one or more items to a bloom filter, creating it with default
+		parameters if it does not exist.
+		Returns an array of integers, 1 for each item newly added and 0 for each
+		item that may have been added before.`,
+		Eval:     evalBFMADD,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	bfmexistsCmdMeta = DiceCmdMeta{
+		Name: "BF.MEXISTS",
+		Info: `BF.MEXISTS key item [item ...]
+		Checks the existence of one or more items in a bloom filter.
+		Returns an array of integers, 0 for each item that surely does not exist
+		and 1 for each item that may exist.`,
+		Eval:       evalBFMEXISTS,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	bfinsertCmdMeta = DiceCmdMeta{
+		Name: "BF.INSERT",
+		Info: `BF.INSERT key [CAPACITY capacity] [ERROR error] [EXPANSION expansion] [NOCREATE] [NONSCALING] ITEMS item [item ...]
+		Adds one or more items to a bloom filter, creating it first with the given
+		options if it does not exist, unless NOCREATE is given.
+		Returns an array of integers, 1 for each item newly added and 0 for each
+		item that may have been added before.`,
+		Eval:     evalBFINSERT,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		CompatReplies: map[string]compatTable{
+			CompatRedis7: {"-ERR invalid key: no bloom filter found for 'BF.INSERT' command\r\n": "-ERR not found\r\n"},
+		},
+	}
+	bfcardCmdMeta = DiceCmdMeta{
+		Name: "BF.CARD",
+		Info: `BF.CARD key
+		Returns the number of items added to a bloom filter, 0 if it does not exist.`,
+		Eval:       evalBFCARD,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	bfscandumpCmdMeta = DiceCmdMeta{
+		Name: "BF.SCANDUMP",
+		Info: `BF.SCANDUMP key iterator
+		Dumps a bloom filter in chunks, to be restored by BF.LOADCHUNK. The dump
+		begins with the iterator 0, and each call returns the iterator of the next
+		chunk and the data of the current one. The iterator 0 ends the dump.`,
+		Eval:       evalBFSCANDUMP,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	bfloadchunkCmdMeta = DiceCmdMeta{
+		Name: "BF.LOADCHUNK",
+		Info: `BF.LOADCHUNK key iterator data
+		Restores a chunk of a bloom filter dumped by BF.SCANDUMP, with the iterator
+		returned along with it. The first chunk creates the filter, and returns an
+		error if the key already exists.`,
+		Eval:     evalBFLOADCHUNK,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	bfinfoCmdMeta = DiceCmdMeta{
+		Name:       "BFINFO",
+		Info:       `BFINFO returns the parameters and metadata of an existing bloom filter, along with its number of sub-filters and their total capacity.`,
+		Eval:       evalBFINFO,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+		CompatReplies: map[string]compatTable{
+			CompatRedis7: {"-ERR invalid key: no bloom filter found for 'BFINFO' command\r\n": "-ERR not found\r\n"},
+		},
+	}
+	cfreserveCmdMeta = DiceCmdMeta{
+		Name: "CF.RESERVE",
+		Info: `CF.RESERVE key capacity [BUCKETSIZE bucketsize] [MAXITERATIONS maxiterations]
+		Creates an empty cuckoo filter able to hold capacity items.`,
+		Eval:     evalCFRESERVE,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+		CompatReplies: map[string]compatTable{
+			CompatRedis7: {"-ERR item exists for 'CF.RESERVE' command\r\n": "-ERR item exists\r\n"},
+		},
+	}
+	cfaddCmdMeta = DiceCmdMeta{
+		Name: "CF.ADD",
+		Info: `CF.ADD key item
+		Adds an item to a cuckoo filter. If the filter does not exist, it is
+		created with default parameters.`,
+		Eval:     evalCFADD,
+		IsWrite:  true,
 		Arity:    3,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
-	bfinfoCmdMeta = DiceCmdMeta{
-		Name:  "BFINFO",
-		Info:  `BFINFO returns the parameters and metadata of an existing bloom filter.`,
-		Eval:  evalBFINFO,
-		Arity: 2,
+	cfaddnxCmdMeta = DiceCmdMeta{
+		Name: "CF.ADDNX",
+		Info: `CF.ADDNX key item
+		Adds an item to a cuckoo filter only if it does not exist in it yet.`,
+		Eval:     evalCFADDNX,
+		IsWrite:  true,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	cfexistsCmdMeta = DiceCmdMeta{
+		Name:       "CF.EXISTS",
+		Info:       `CF.EXISTS key item checks existence of an item in a cuckoo filter.`,
+		Eval:       evalCFEXISTS,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	cfdelCmdMeta = DiceCmdMeta{
+		Name:     "CF.DEL",
+		Info:     `CF.DEL key item deletes one occurrence of an item from a cuckoo filter.`,
+		Eval:     evalCFDEL,
+		IsWrite:  true,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	cfcountCmdMeta = DiceCmdMeta{
+		Name:       "CF.COUNT",
+		Info:       `CF.COUNT key item returns the number of times an item may have been added to a cuckoo filter.`,
+		Eval:       evalCFCOUNT,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	cmsinitbydimCmdMeta = DiceCmdMeta{
+		Name: "CMS.INITBYDIM",
+		Info: `CMS.INITBYDIM key width depth
+		Creates a count-min sketch of the given width and depth.`,
+		Eval:     evalCMSINITBYDIM,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	cmsinitbyprobCmdMeta = DiceCmdMeta{
+		Name: "CMS.INITBYPROB",
+		Info: `CMS.INITBYPROB key error probability
+		Creates a count-min sketch whose estimates exceed the true counts by more
+		than error times the total of the increments with the given probability.`,
+		Eval:     evalCMSINITBYPROB,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	cmsincrbyCmdMeta = DiceCmdMeta{
+		Name: "CMS.INCRBY",
+		Info: `CMS.INCRBY key item increment [item increment ...]
+		Increments the counts of items in a count-min sketch and returns their
+		new estimated counts.`,
+		Eval:     evalCMSINCRBY,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	cmsqueryCmdMeta = DiceCmdMeta{
+		Name:       "CMS.QUERY",
+		Info:       `CMS.QUERY key item [item ...] returns the estimated counts of items in a count-min sketch.`,
+		Eval:       evalCMSQUERY,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	cmsinfoCmdMeta = DiceCmdMeta{
+		Name:       "CMS.INFO",
+		Info:       `CMS.INFO key returns the width, the depth and the total count of a count-min sketch.`,
+		Eval:       evalCMSINFO,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	cmsmergeCmdMeta = DiceCmdMeta{
+		Name: "CMS.MERGE",
+		Info: `CMS.MERGE destination numKeys source [source ...] [WEIGHTS weight [weight ...]]
+		Sets the counters of the destination sketch to the weighted sums of the
+		counters of the source sketches.`,
+		Eval:     evalCMSMERGE,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	topkreserveCmdMeta = DiceCmdMeta{
+		Name: "TOPK.RESERVE",
+		Info: `TOPK.RESERVE key topk [width depth decay]
+		Creates a tracker of the topk most frequent items.`,
+		Eval:     evalTOPKRESERVE,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	topkaddCmdMeta = DiceCmdMeta{
+		Name: "TOPK.ADD",
+		Info: `TOPK.ADD key item [item ...]
+		Adds items to a top-k tracker and returns the items they expelled from it.`,
+		Eval:     evalTOPKADD,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	topkqueryCmdMeta = DiceCmdMeta{
+		Name:       "TOPK.QUERY",
+		Info:       `TOPK.QUERY key item [item ...] checks whether items are in the top-k.`,
+		Eval:       evalTOPKQUERY,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	topkcountCmdMeta = DiceCmdMeta{
+		Name:       "TOPK.COUNT",
+		Info:       `TOPK.COUNT key item [item ...] returns the estimated counts of items in a top-k tracker.`,
+		Eval:       evalTOPKCOUNT,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	topklistCmdMeta = DiceCmdMeta{
+		Name:       "TOPK.LIST",
+		Info:       `TOPK.LIST key [WITHCOUNT] returns the top-k items, the most frequent first.`,
+		Eval:       evalTOPKLIST,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	counterincrCmdMeta = DiceCmdMeta{
+		Name: "COUNTER.INCR",
+		Info: `COUNTER.INCR key shards [increment]
+		Increments one of the shards sub-counters of a sharded counter, picked at
+		random, so that a hot counter is not updated on a single shard.
+		Returns the new value of the sub-counter.`,
+		Eval:     evalCOUNTERINCR,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	countergetCmdMeta = DiceCmdMeta{
+		Name:       "COUNTER.GET",
+		Info:       `COUNTER.GET key shards returns the sum of the sub-counters of a sharded counter.`,
+		Eval:       evalCOUNTERGET,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	tdigestcreateCmdMeta = DiceCmdMeta{
+		Name:     "TDIGEST.CREATE",
+		Info:     `TDIGEST.CREATE key [COMPRESSION compression] creates an empty t-digest.`,
+		Eval:     evalTDIGESTCREATE,
+		IsWrite:  true,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	tdigestaddCmdMeta = DiceCmdMeta{
+		Name:     "TDIGEST.ADD",
+		Info:     `TDIGEST.ADD key value [value ...] adds values to a t-digest.`,
+		Eval:     evalTDIGESTADD,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	tdigestquantileCmdMeta = DiceCmdMeta{
+		Name:       "TDIGEST.QUANTILE",
+		Info:       `TDIGEST.QUANTILE key quantile [quantile ...] returns the estimated values of quantiles of a t-digest.`,
+		Eval:       evalTDIGESTQUANTILE,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	tdigestcdfCmdMeta = DiceCmdMeta{
+		Name: "TDIGEST.CDF",
+		Info: `TDIGEST.CDF key value [value ...]
+		Returns the estimated fractions of the values of a t-digest that are
+		lower than or equal to the given values.`,
+		Eval:       evalTDIGESTCDF,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
+	}
+	tdigestmergeCmdMeta = DiceCmdMeta{
+		Name: "TDIGEST.MERGE",
+		Info: `TDIGEST.MERGE destination numKeys source [source ...] [COMPRESSION compression] [OVERRIDE]
+		Merges the source t-digests into the destination one.`,
+		Eval:     evalTDIGESTMERGE,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	viewcreateCmdMeta = DiceCmdMeta{
+		Name: "VIEW.CREATE",
+		Info: `VIEW.CREATE key name TOP count [WITHSCORES] | CARD
+		Creates a read-only materialized view of key, either the count members of a sorted set
+		with the highest scores or the number of elements of a set, a sorted set or a hash.
+		The result of the view is kept until key is written.`,
+		Eval:     evalVIEWCREATE,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	viewgetCmdMeta = DiceCmdMeta{
+		Name: "VIEW.GET",
+		Info: `VIEW.GET key name
+		Returns the result of a view of key.`,
+		Eval:     evalVIEWGET,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	viewdropCmdMeta = DiceCmdMeta{
+		Name: "VIEW.DROP",
+		Info: `VIEW.DROP key name
+		Removes a view of key. Returns 1 if the view was removed, 0 if there was no such view.`,
+		Eval:     evalVIEWDROP,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
+
 	// TODO: Remove this override once we support QWATCH in dice-cli.
 	subscribeCmdMeta = DiceCmdMeta{
 		Name: "SUBSCRIBE",
@@ -448,7 +999,7 @@ var (
 		containing the new value of the key along with the operation that was performed on it.
 		Contains only one argument, the key to be watched.`,
 		Eval:  nil,
-		Arity: 1,
+		Arity: 2,
 	}
 	qwatchCmdMeta = DiceCmdMeta{
 		Name: "QWATCH",
@@ -457,14 +1008,14 @@ var (
 		containing the new value of the key along with the operation that was performed on it.
 		Contains only one argument, the key to be watched.`,
 		Eval:  nil,
-		Arity: 1,
+		Arity: 2,
 	}
 	qUnwatchCmdMeta = DiceCmdMeta{
 		Name: "QUNWATCH",
 		Info: `Unsubscribes or QUnwatches the client from the given key's watch session.
 		It removes the key from the watch list for the caller client.`,
 		Eval:  nil,
-		Arity: 1,
+		Arity: 2,
 	}
 	MultiCmdMeta = DiceCmdMeta{
 		Name: "MULTI",
@@ -477,17 +1028,44 @@ var (
 		Arity: 1,
 	}
 	ExecCmdMeta = DiceCmdMeta{
-		Name:  "EXEC",
-		Info:  `EXEC executes commands in a transaction, which is initiated by MULTI`,
+		Name: "EXEC",
+		Info: `EXEC executes commands in a transaction, which is initiated by MULTI.
+		The commands run atomically, with no other command interleaved. The transaction aborts, running none of
+		them, if one of the keys watched by WATCH was modified, EXEC returning nil then.`,
 		Eval:  nil,
 		Arity: 1,
 	}
 	DiscardCmdMeta = DiceCmdMeta{
 		Name:  "DISCARD",
-		Info:  `DISCARD discards all the commands in a transaction, which is initiated by MULTI`,
+		Info:  `DISCARD discards all the commands in a transaction, which is initiated by MULTI, and unwatches all the keys`,
+		Eval:  nil,
+		Arity: 1,
+	}
+	watchCmdMeta = DiceCmdMeta{
+		Name: "WATCH",
+		Info: `WATCH key [key ...]
+		Watches the keys for the next transaction of the client, which aborts if one of them is modified before EXEC.
+		The keys are unwatched once EXEC or DISCARD is called. WATCH is not allowed inside MULTI.`,
+		Eval:     nil,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+	}
+	unwatchCmdMeta = DiceCmdMeta{
+		Name: "UNWATCH",
+		Info: `UNWATCH
+		Unwatches all the keys watched by WATCH.`,
 		Eval:  nil,
 		Arity: 1,
 	}
+	ExecBatchCmdMeta = DiceCmdMeta{
+		Name: "EXECBATCH",
+		Info: `EXECBATCH numcommands numargs command [arg ...] [numargs command [arg ...] ...]
+		Runs the numcommands commands atomically, without MULTI and EXEC, each one given by its number of
+		arguments, the command name included, followed by the command and its arguments.
+		All the commands are checked before any of them runs. Returns the replies of the commands.`,
+		Eval:  evalEXECBATCH,
+		Arity: -4,
+	}
 	abortCmdMeta = DiceCmdMeta{
 		Name:  "ABORT",
 		Info:  "Quit the server",
@@ -495,37 +1073,63 @@ var (
 		Arity: 1,
 	}
 	setBitCmdMeta = DiceCmdMeta{
-		Name: "SETBIT",
-		Info: "SETBIT sets or clears the bit at offset in the string value stored at key",
-		Eval: evalSETBIT,
+		Name:     "SETBIT",
+		Info:     "SETBIT sets or clears the bit at offset in the string value stored at key",
+		Eval:     evalSETBIT,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	getBitCmdMeta = DiceCmdMeta{
-		Name: "GETBIT",
-		Info: "GETBIT returns the bit value at offset in the string value stored at key",
-		Eval: evalGETBIT,
+		Name:       "GETBIT",
+		Info:       "GETBIT returns the bit value at offset in the string value stored at key",
+		Eval:       evalGETBIT,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	bitCountCmdMeta = DiceCmdMeta{
-		Name:  "BITCOUNT",
-		Info:  "BITCOUNT counts the number of set bits in the string value stored at key",
-		Eval:  evalBITCOUNT,
-		Arity: -1,
+		Name:       "BITCOUNT",
+		Info:       "BITCOUNT counts the number of set bits in the string value stored at key",
+		Eval:       evalBITCOUNT,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	bitOpCmdMeta = DiceCmdMeta{
-		Name: "BITOP",
-		Info: "BITOP performs bitwise operations between multiple keys",
-		Eval: evalBITOP,
+		Name:     "BITOP",
+		Info:     "BITOP performs bitwise operations between multiple keys",
+		Eval:     evalBITOP,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 2, Step: 1, LastKey: -1},
 	}
 	commandCmdMeta = DiceCmdMeta{
-		Name:        "COMMAND <subcommand>",
+		Name:        "COMMAND",
 		Info:        "Evaluates COMMAND <subcommand> command based on subcommand",
 		Eval:        evalCommand,
 		Arity:       -1,
 		SubCommands: []string{Count, GetKeys, List, Help, Info},
 	}
 	keysCmdMeta = DiceCmdMeta{
-		Name: "KEYS",
-		Info: "KEYS command is used to get all the keys in the database. Complexity is O(n) where n is the number of keys in the database.",
-		Eval: evalKeys,
+		Name:       "KEYS",
+		Info:       "KEYS command is used to get all the keys in the database. Complexity is O(n) where n is the number of keys in the database.",
+		Eval:       evalKeys,
+		Arity:      2,
+		IsReadOnly: true,
+	}
+	scanCmdMeta = DiceCmdMeta{
+		Name: "SCAN",
+		Info: `SCAN cursor [MATCH pattern] [COUNT count] [TYPE type] [SNAPSHOT]
+		Incrementally iterates over the keys of the database. Each call returns the cursor
+		of the next call, 0 when the iteration is over, and a batch of keys. Each key present
+		for the whole scan is returned exactly once, MATCH and TYPE filtering the keys.
+		With SNAPSHOT the iteration walks a snapshot of the key set taken when it starts,
+		the keys added in the meantime being never returned.
+		A call returns early, with fewer keys than COUNT, once it ran for the scan time budget.`,
+		Eval:       evalSCAN,
+		Arity:      -2,
+		IsReadOnly: true,
 	}
 	MGetCmdMeta = DiceCmdMeta{
 		Name: "MGET",
@@ -533,20 +1137,27 @@ var (
 		For each key, if the key is expired or does not exist, the response will be RespNIL;
 		otherwise, the response will be the RESP value of the key.
 		`,
-		Eval:     evalMGET,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		Eval:       evalMGET,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		IsReadOnly: true,
 	}
 	persistCmdMeta = DiceCmdMeta{
-		Name: "PERSIST",
-		Info: "PERSIST removes the expiration from a key",
-		Eval: evalPersist,
+		Name:        "PERSIST",
+		Info:        "PERSIST removes the expiration from a key",
+		Eval:        evalPersist,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	copyCmdMeta = DiceCmdMeta{
-		Name:  "COPY",
-		Info:  `COPY command copies the value stored at the source key to the destination key.`,
-		Eval:  evalCOPY,
-		Arity: -2,
+		Name:     "COPY",
+		Info:     `COPY command copies the value stored at the source key to the destination key.`,
+		Eval:     evalCOPY,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: 2},
 	}
 	decrCmdMeta = DiceCmdMeta{
 		Name: "DECR",
@@ -559,6 +1170,7 @@ var (
 		if not DECR returns encoded error response.
 		evalDECR returns the decremented value for the key if there are no errors.`,
 		Eval:     evalDECR,
+		IsWrite:  true,
 		Arity:    2,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
@@ -573,6 +1185,7 @@ var (
 		if not, DECRBY returns an encoded error response.
 		evalDECRBY returns the decremented value for the key after applying the specified decrement if there are no errors.`,
 		Eval:     evalDECRBY,
+		IsWrite:  true,
 		Arity:    3,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
@@ -580,19 +1193,25 @@ var (
 		Name: "EXISTS",
 		Info: `EXISTS key1 key2 ... key_N
 		Return value is the number of keys existing.`,
-		Eval: evalEXISTS,
+		Eval:       evalEXISTS,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		IsReadOnly: true,
 	}
 	renameCmdMeta = DiceCmdMeta{
-		Name:  "RENAME",
-		Info:  "Renames a key and overwrites the destination",
-		Eval:  evalRename,
-		Arity: 3,
+		Name:     "RENAME",
+		Info:     "Renames a key and overwrites the destination",
+		Eval:     evalRename,
+		IsWrite:  true,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: 2},
 	}
 	getexCmdMeta = DiceCmdMeta{
 		Name: "GETEX",
 		Info: `Get the value of key and optionally set its expiration.
 		GETEX is similar to GET, but is a write command with additional options.`,
 		Eval:     evalGETEX,
+		IsWrite:  true,
 		Arity:    -2,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
@@ -604,9 +1223,10 @@ var (
 		RESP encoded time (in secs) remaining for the key to expire
 		RESP encoded -2 stating key doesn't exist or key is expired
 		RESP encoded -1 in case no expiration is set on the key`,
-		Eval:     evalPTTL,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalPTTL,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	hsetCmdMeta = DiceCmdMeta{
 		Name: "HSET",
@@ -617,15 +1237,17 @@ var (
 		This command returns the number of keys that are stored at given key.
 		`,
 		Eval:     evalHSET,
+		IsWrite:  true,
 		Arity:    -4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hkeysCmdMeta = DiceCmdMeta{
-		Name: "HKEYS",
-		Info:  `HKEYS command is used to retrieve all the keys(or field names) within a hash. Complexity is O(n) where n is the size of the hash.`,
-		Eval: evalHKEYS,
-		Arity: 1,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Name:       "HKEYS",
+		Info:       `HKEYS command is used to retrieve all the keys(or field names) within a hash. Complexity is O(n) where n is the size of the hash.`,
+		Eval:       evalHKEYS,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	hsetnxCmdMeta = DiceCmdMeta{
 		Name: "HSETNX",
@@ -633,37 +1255,170 @@ var (
 		If key does not exist, a new key holding a hash is created. If field already exists,
 		this operation has no effect.`,
 		Eval:     evalHSETNX,
+		IsWrite:  true,
 		Arity:    4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
-	hgetCmdMeta = DiceCmdMeta{
-		Name:     "HGET",
-		Info:     `Returns the value associated with field in the hash stored at key.`,
-		Eval:     evalHGET,
-		Arity:    -3,
+	hsetexCmdMeta = DiceCmdMeta{
+		Name: "HSETEX",
+		Info: `HSETEX key ttl field value [field value ...]
+		Sets the specified fields to their respective values in the hash stored at key,
+		and sets the expiry of the key to ttl seconds in the same operation.
+		Returns the number of fields added to the hash.`,
+		Eval:     evalHSETEX,
+		IsWrite:  true,
+		Arity:    -5,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
-	hmgetCmdMeta = DiceCmdMeta{
-		Name:     "HMGET",
-		Info:     `Returns the values associated with the specified fields in the hash stored at key.`,
-		Eval:     evalHMGET,
-		Arity:    -2,
+	hexpireCmdMeta = DiceCmdMeta{
+		Name: "HEXPIRE",
+		Info: `HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+		Sets the expiry of the fields of the hash stored at key to seconds from now.
+		NX sets it only to the fields without expiry, XX only to the fields having one,
+		GT only if it is greater than the current one and LT only if it is lower.
+		Returns an array holding for each field -2 if the field or the key does not exist,
+		0 if the condition is not met, 1 if the expiry is set, or 2 if seconds is 0,
+		in which case the field is deleted.`,
+		Eval:     evalHEXPIRE,
+		IsWrite:  true,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	hpexpireCmdMeta = DiceCmdMeta{
+		Name: "HPEXPIRE",
+		Info: `HPEXPIRE key milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+		Like HEXPIRE, the expiry being given in milliseconds.`,
+		Eval:     evalHPEXPIRE,
+		IsWrite:  true,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	hexpireatCmdMeta = DiceCmdMeta{
+		Name: "HEXPIREAT",
+		Info: `HEXPIREAT key unix-time-seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+		Like HEXPIRE, the expiry being given as a unix timestamp in seconds.
+		A timestamp in the past deletes the fields.`,
+		Eval:     evalHEXPIREAT,
+		IsWrite:  true,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	hpexpireatCmdMeta = DiceCmdMeta{
+		Name: "HPEXPIREAT",
+		Info: `HPEXPIREAT key unix-time-milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+		Like HEXPIRE, the expiry being given as a unix timestamp in milliseconds.
+		A timestamp in the past deletes the fields.`,
+		Eval:     evalHPEXPIREAT,
+		IsWrite:  true,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	expirememberCmdMeta = DiceCmdMeta{
+		Name: "EXPIREMEMBER",
+		Info: `EXPIREMEMBER key member ttl [s | ms]
+		Sets the expiry of member, in the set, the sorted set or the hash stored at key,
+		to ttl seconds from now, or milliseconds with ms.
+		A ttl of 0 or less deletes the member, the key being deleted once empty.
+		Adding the member again once deleted removes its expiry.
+		Returns 1 if the expiry is set, 0 if the member or the key does not exist.`,
+		Eval:     evalEXPIREMEMBER,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	expirememberatCmdMeta = DiceCmdMeta{
+		Name: "EXPIREMEMBERAT",
+		Info: `EXPIREMEMBERAT key member unix-time-seconds
+		Like EXPIREMEMBER, the expiry being given as a unix timestamp in seconds.
+		A timestamp in the past deletes the member.`,
+		Eval:     evalEXPIREMEMBERAT,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	pexpirememberatCmdMeta = DiceCmdMeta{
+		Name: "PEXPIREMEMBERAT",
+		Info: `PEXPIREMEMBERAT key member unix-time-milliseconds
+		Like EXPIREMEMBER, the expiry being given as a unix timestamp in milliseconds.
+		A timestamp in the past deletes the member.`,
+		Eval:     evalPEXPIREMEMBERAT,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	httlCmdMeta = DiceCmdMeta{
+		Name: "HTTL",
+		Info: `HTTL key FIELDS numfields field [field ...]
+		Returns an array holding the time to live in seconds of the fields of the hash stored at key,
+		-2 for the fields or the key that do not exist, and -1 for the fields without expiry.`,
+		Eval:       evalHTTL,
+		Arity:      -5,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	hpttlCmdMeta = DiceCmdMeta{
+		Name: "HPTTL",
+		Info: `HPTTL key FIELDS numfields field [field ...]
+		Like HTTL, the time to live being in milliseconds.`,
+		Eval:       evalHPTTL,
+		Arity:      -5,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	hpersistCmdMeta = DiceCmdMeta{
+		Name: "HPERSIST",
+		Info: `HPERSIST key FIELDS numfields field [field ...]
+		Removes the expiry of the fields of the hash stored at key.
+		Returns an array holding for each field 1 if its expiry is removed, -1 if it has none,
+		or -2 if the field or the key does not exist.`,
+		Eval:     evalHPERSIST,
+		IsWrite:  true,
+		Arity:    -5,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
+	hgetCmdMeta = DiceCmdMeta{
+		Name:       "HGET",
+		Info:       `Returns the value associated with field in the hash stored at key.`,
+		Eval:       evalHGET,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	hmgetCmdMeta = DiceCmdMeta{
+		Name:       "HMGET",
+		Info:       `Returns the values associated with the specified fields in the hash stored at key.`,
+		Eval:       evalHMGET,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
 	hgetAllCmdMeta = DiceCmdMeta{
 		Name: "HGETALL",
 		Info: `Returns all fields and values of the hash stored at key. In the returned value,
         every field name is followed by its value, so the length of the reply is twice the size of the hash.`,
-		Eval:     evalHGETALL,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalHGETALL,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	hscanCmdMeta = DiceCmdMeta{
+		Name: "HSCAN",
+		Info: `HSCAN key cursor [MATCH pattern] [COUNT count]
+		Incrementally iterates over the fields of the hash stored at key. Each call returns the cursor
+		of the next call, 0 when the iteration is over, and a batch of fields along with their values.
+		Each field present for the whole iteration is returned exactly once.`,
+		Eval:       evalHSCAN,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	hValsCmdMeta = DiceCmdMeta{
-		Name:     "HVALS",
-		Info:     `Returns all values of the hash stored at key. The length of the reply is same as the size of the hash.`,
-		Eval:     evalHVALS,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Name:       "HVALS",
+		Info:       `Returns all values of the hash stored at key. The length of the reply is same as the size of the hash.`,
+		Eval:       evalHVALS,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	hincrbyCmdMeta = DiceCmdMeta{
 		Name: "HINCRBY",
@@ -671,15 +1426,17 @@ var (
 		If key does not exist, a new key holding a hash is created.
 		If field does not exist the value is set to 0 before the operation is performed.`,
 		Eval:     evalHINCRBY,
-		Arity:    -4,
+		IsWrite:  true,
+		Arity:    4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hstrLenCmdMeta = DiceCmdMeta{
-		Name:     "HSTRLEN",
-		Info:     `Returns the length of value associated with field in the hash stored at key.`,
-		Eval:     evalHSTRLEN,
-		Arity:    -3,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Name:       "HSTRLEN",
+		Info:       `Returns the length of value associated with field in the hash stored at key.`,
+		Eval:       evalHSTRLEN,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	hdelCmdMeta = DiceCmdMeta{
 		Name: "HDEL",
@@ -689,42 +1446,49 @@ var (
 		If key does not exist, it is treated as an empty hash and this command returns 0.
 		Returns
 		The number of fields that were removed from the hash, not including specified but non-existing fields.`,
-		Eval:     evalHDEL,
-		Arity:    -3,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:        evalHDEL,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -3,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	hexistsCmdMeta = DiceCmdMeta{
-		Name:     "HEXISTS",
-		Info:     `Returns if field is an existing field in the hash stored at key.`,
-		Eval:     evalHEXISTS,
-		Arity:    -3,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Name:       "HEXISTS",
+		Info:       `Returns if field is an existing field in the hash stored at key.`,
+		Eval:       evalHEXISTS,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 
 	objectCmdMeta = DiceCmdMeta{
 		Name: "OBJECT",
-		Info: `OBJECT subcommand [arguments [arguments ...]]
-		OBJECT command is used to inspect the internals of the Redis objects.`,
-		Eval:     evalOBJECT,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 2},
+		Info: `OBJECT command is used to inspect the internals of the Redis objects.
+		OBJECT IDLETIME key
+		Returns the time in seconds since the key was last accessed.`,
+		Eval:       evalOBJECT,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 2},
+		IsReadOnly: true,
 	}
 	touchCmdMeta = DiceCmdMeta{
 		Name: "TOUCH",
 		Info: `TOUCH key1 key2 ... key_N
 		Alters the last access time of a key(s).
 		A key is ignored if it does not exist.`,
-		Eval:     evalTOUCH,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalTOUCH,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		IsReadOnly: true,
 	}
 	expiretimeCmdMeta = DiceCmdMeta{
 		Name: "EXPIRETIME",
 		Info: `EXPIRETIME returns the absolute Unix timestamp (since January 1, 1970) in seconds
 		at which the given key will expire`,
-		Eval:     evalEXPIRETIME,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+		Eval:       evalEXPIRETIME,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
 	}
 	expireatCmdMeta = DiceCmdMeta{
 		Name: "EXPIREAT",
@@ -733,33 +1497,76 @@ var (
 		The expiry time should be in integer format; if not, it returns encoded error response
 		Returns RespOne if expiry was set on the key successfully.
 		Once the time is lapsed, the key will be deleted automatically`,
-		Eval:     evalEXPIREAT,
-		Arity:    -3,
-		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+		Eval:        evalEXPIREAT,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -3,
+		KeySpecs:    KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	pexpireCmdMeta = DiceCmdMeta{
+		Name: "PEXPIRE",
+		Info: `PEXPIRE key milliseconds [NX | XX | GT | LT]
+		Sets a expiry time(in millisecs) on the specified key, like EXPIRE.
+		Returns RespOne if expiry was set on the key successfully.`,
+		Eval:        evalPEXPIRE,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -3,
+		KeySpecs:    KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	pexpireatCmdMeta = DiceCmdMeta{
+		Name: "PEXPIREAT",
+		Info: `PEXPIREAT key unix-time-milliseconds [NX | XX | GT | LT]
+		Sets a expiry time(in unix-time-millisecs) on the specified key, like EXPIREAT.
+		Returns RespOne if expiry was set on the key successfully.`,
+		Eval:        evalPEXPIREAT,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -3,
+		KeySpecs:    KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	pexpiretimeCmdMeta = DiceCmdMeta{
+		Name: "PEXPIRETIME",
+		Info: `PEXPIRETIME returns the absolute Unix timestamp (since January 1, 1970) in milliseconds
+		at which the given key will expire`,
+		Eval:       evalPEXPIRETIME,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		IsReadOnly: true,
 	}
 	lpushCmdMeta = DiceCmdMeta{
-		Name:  "LPUSH",
-		Info:  "LPUSH pushes values into the left side of the deque",
-		Eval:  evalLPUSH,
-		Arity: -3,
+		Name:     "LPUSH",
+		Info:     "LPUSH pushes values into the left side of the deque",
+		Eval:     evalLPUSH,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	rpushCmdMeta = DiceCmdMeta{
-		Name:  "RPUSH",
-		Info:  "RPUSH pushes values into the right side of the deque",
-		Eval:  evalRPUSH,
-		Arity: -3,
+		Name:     "RPUSH",
+		Info:     "RPUSH pushes values into the right side of the deque",
+		Eval:     evalRPUSH,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	lpopCmdMeta = DiceCmdMeta{
-		Name:  "LPOP",
-		Info:  "LPOP pops a value from the left side of the deque",
-		Eval:  evalLPOP,
-		Arity: 2,
+		Name:        "LPOP",
+		Info:        "LPOP pops a value from the left side of the deque",
+		Eval:        evalLPOP,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	rpopCmdMeta = DiceCmdMeta{
-		Name:  "RPOP",
-		Info:  "RPOP pops a value from the right side of the deque",
-		Eval:  evalRPOP,
-		Arity: 2,
+		Name:        "RPOP",
+		Info:        "RPOP pops a value from the right side of the deque",
+		Eval:        evalRPOP,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	llenCmdMeta = DiceCmdMeta{
 		Name: "LLEN",
@@ -767,20 +1574,152 @@ var (
 		Returns the length of the list stored at key. If key does not exist,
 		it is interpreted as an empty list and 0 is returned.
 		An error is returned when the value stored at key is not a list.`,
-		Eval:  evalLLEN,
-		Arity: 1,
+		Eval:       evalLLEN,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	lmoveCmdMeta = DiceCmdMeta{
+		Name: "LMOVE",
+		Info: `LMOVE source destination LEFT|RIGHT LEFT|RIGHT
+		Pops an element from the head or the tail of the list stored at source and pushes it to the head or the tail of the list stored at destination.
+		The destination list is created if needed, and the source key is deleted once its list is empty.
+		Returns the element moved, or nil if the source list does not exist.`,
+		Eval:     evalLMOVE,
+		IsWrite:  true,
+		Arity:    5,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: 2},
+	}
+	rpoplpushCmdMeta = DiceCmdMeta{
+		Name: "RPOPLPUSH",
+		Info: `RPOPLPUSH source destination
+		Pops an element from the tail of the list stored at source and pushes it to the head of the list stored at destination.
+		It is equivalent to LMOVE source destination RIGHT LEFT.
+		Returns the element moved, or nil if the source list does not exist.`,
+		Eval:     evalRPOPLPUSH,
+		IsWrite:  true,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: 2},
+	}
+	linsertCmdMeta = DiceCmdMeta{
+		Name: "LINSERT",
+		Info: `LINSERT key BEFORE|AFTER pivot element
+		Inserts element before or after the first element equal to pivot in the list stored at key.
+		Returns the length of the list after the insertion, -1 if pivot is not found, or 0 if the key does not exist.`,
+		Eval:     evalLINSERT,
+		IsWrite:  true,
+		Arity:    5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	lposCmdMeta = DiceCmdMeta{
+		Name: "LPOS",
+		Info: `LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len]
+		Returns the position of the first element equal to element in the list stored at key, or nil if there is none.
+		RANK starts from the rank-th match, from the tail of the list if negative.
+		COUNT returns the positions of up to num-matches matches as an array, all of them if 0.
+		MAXLEN compares at most len elements, all of them if 0.`,
+		Eval:       evalLPOS,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	lpushxCmdMeta = DiceCmdMeta{
+		Name: "LPUSHX",
+		Info: `LPUSHX key element [element ...]
+		Pushes the elements to the head of the list stored at key, only if the key already holds a list.
+		Returns the length of the list after the push, or 0 if the key does not exist.`,
+		Eval:     evalLPUSHX,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	rpushxCmdMeta = DiceCmdMeta{
+		Name: "RPUSHX",
+		Info: `RPUSHX key element [element ...]
+		Pushes the elements to the tail of the list stored at key, only if the key already holds a list.
+		Returns the length of the list after the push, or 0 if the key does not exist.`,
+		Eval:     evalRPUSHX,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	blpopCmdMeta = DiceCmdMeta{
+		Name: "BLPOP",
+		Info: `BLPOP key [key ...] timeout
+		Pops an element from the head of the first non-empty list among the ones stored at the keys.
+		If all the lists are empty, the client is blocked till one of them is pushed to, or till the timeout in seconds elapses.
+		A timeout of 0 blocks forever. The clients that cannot block, e.g. inside a transaction, time out right away.
+		Returns the key along with the element, or nil once timed out.`,
+		BlockingEval:  evalBLPOP,
+		IsWrite:       true,
+		FreesMemory:   true,
+		Arity:         -3,
+		KeySpecs:      KeySpecs{BeginIndex: 1, Step: 1, LastKey: -2},
+		CompatReplies: blockingPopCompatReplies,
+	}
+	brpopCmdMeta = DiceCmdMeta{
+		Name: "BRPOP",
+		Info: `BRPOP key [key ...] timeout
+		Pops an element from the tail of the first non-empty list among the ones stored at the keys.
+		If all the lists are empty, the client is blocked till one of them is pushed to, or till the timeout in seconds elapses.
+		A timeout of 0 blocks forever. The clients that cannot block, e.g. inside a transaction, time out right away.
+		Returns the key along with the element, or nil once timed out.`,
+		BlockingEval:  evalBRPOP,
+		IsWrite:       true,
+		FreesMemory:   true,
+		Arity:         -3,
+		KeySpecs:      KeySpecs{BeginIndex: 1, Step: 1, LastKey: -2},
+		CompatReplies: blockingPopCompatReplies,
+	}
+	blmoveCmdMeta = DiceCmdMeta{
+		Name: "BLMOVE",
+		Info: `BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout
+		Pops an element from the head or the tail of the list stored at source and pushes it to the head or the tail of the list stored at destination.
+		If the source list is empty, the client is blocked till it is pushed to, or till the timeout in seconds elapses.
+		A timeout of 0 blocks forever. The clients that cannot block, e.g. inside a transaction, time out right away.
+		Returns the element moved, or nil once timed out.`,
+		BlockingEval: evalBLMOVE,
+		IsWrite:      true,
+		Arity:        6,
+		KeySpecs:     KeySpecs{BeginIndex: 1, Step: 1, LastKey: 2},
+	}
+	lmpopCmdMeta = DiceCmdMeta{
+		Name: "LMPOP",
+		Info: `LMPOP numkeys key [key ...] LEFT|RIGHT [COUNT count]
+		Pops up to count elements from the head or the tail of the first non-empty list among the ones stored at the keys.
+		Returns the key along with the elements, or nil if all the lists are empty.`,
+		Eval:     evalLMPOP,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 2},
+	}
+	blmpopCmdMeta = DiceCmdMeta{
+		Name: "BLMPOP",
+		Info: `BLMPOP timeout numkeys key [key ...] LEFT|RIGHT [COUNT count]
+		Pops up to count elements from the head or the tail of the first non-empty list among the ones stored at the keys.
+		If all the lists are empty, the client is blocked till one of them is pushed to, or till the timeout in seconds elapses.
+		A timeout of 0 blocks forever. The clients that cannot block, e.g. inside a transaction, time out right away.
+		Returns the key along with the elements, or nil once timed out.`,
+		BlockingEval:  evalBLMPOP,
+		IsWrite:       true,
+		Arity:         -5,
+		KeySpecs:      KeySpecs{BeginIndex: 3},
+		CompatReplies: blockingPopCompatReplies,
 	}
 	dbSizeCmdMeta = DiceCmdMeta{
-		Name:  "DBSIZE",
-		Info:  `DBSIZE Return the number of keys in the database`,
-		Eval:  evalDBSIZE,
-		Arity: 1,
+		Name:       "DBSIZE",
+		Info:       `DBSIZE Return the number of keys in the database`,
+		Eval:       evalDBSIZE,
+		Arity:      1,
+		IsReadOnly: true,
 	}
 	flushdbCmdMeta = DiceCmdMeta{
-		Name:  "FLUSHDB",
-		Info:  `FLUSHDB deletes all the keys of the currently selected DB`,
-		Eval:  evalFLUSHDB,
-		Arity: -1,
+		Name:        "FLUSHDB",
+		Info:        `FLUSHDB deletes all the keys of the currently selected DB`,
+		Eval:        evalFLUSHDB,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -1,
 	}
 	bitposCmdMeta = DiceCmdMeta{
 		Name: "BITPOS",
@@ -807,8 +1746,10 @@ var (
 		 RESP encoded -1 in case the bit argument is 1 and the string is empty or composed of just zero bytes.
 		 RESP encoded -1 if we look for set bits and the string is empty or composed of just zero bytes, -1 is returned.
 		 RESP encoded -1 if a clear bit isn't found in the specified range.`,
-		Eval:  evalBITPOS,
-		Arity: -2,
+		Eval:       evalBITPOS,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	saddCmdMeta = DiceCmdMeta{
 		Name: "SADD",
@@ -818,6 +1759,7 @@ var (
 		Non existing keys are treated as empty sets.
 		An error is returned when the value stored at key is not a set.`,
 		Eval:     evalSADD,
+		IsWrite:  true,
 		Arity:    -3,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
@@ -825,9 +1767,21 @@ var (
 		Name: "SMEMBERS",
 		Info: `SMEMBERS key
 		Returns all the members of the set value stored at key.`,
-		Eval:     evalSMEMBERS,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalSMEMBERS,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	sscanCmdMeta = DiceCmdMeta{
+		Name: "SSCAN",
+		Info: `SSCAN key cursor [MATCH pattern] [COUNT count]
+		Incrementally iterates over the members of the set stored at key. Each call returns the cursor
+		of the next call, 0 when the iteration is over, and a batch of members.
+		Each member present for the whole iteration is returned exactly once.`,
+		Eval:       evalSSCAN,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	sremCmdMeta = DiceCmdMeta{
 		Name: "SREM",
@@ -835,42 +1789,147 @@ var (
 		Removes the specified members from the set stored at key.
 		Non existing keys are treated as empty sets.
 		An error is returned when the value stored at key is not a set.`,
-		Eval:     evalSREM,
-		Arity:    -3,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:        evalSREM,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -3,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
 	}
 	scardCmdMeta = DiceCmdMeta{
 		Name: "SCARD",
 		Info: `SCARD key
 		Returns the number of elements of the set stored at key.
 		An error is returned when the value stored at key is not a set.`,
-		Eval:     evalSCARD,
-		Arity:    2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalSCARD,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	sismemberCmdMeta = DiceCmdMeta{
+		Name: "SISMEMBER",
+		Info: `SISMEMBER key member
+		Returns 1 if member is a member of the set stored at key, 0 otherwise.
+		An error is returned when the value stored at key is not a set.`,
+		Eval:       evalSISMEMBER,
+		Arity:      3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	smismemberCmdMeta = DiceCmdMeta{
+		Name: "SMISMEMBER",
+		Info: `SMISMEMBER key member [member ...]
+		Returns, for each member, 1 if it is a member of the set stored at key, 0 otherwise.
+		An error is returned when the value stored at key is not a set.`,
+		Eval:       evalSMISMEMBER,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	spopCmdMeta = DiceCmdMeta{
+		Name: "SPOP",
+		Info: `SPOP key [count]
+		Removes and returns random members of the set stored at key.
+		Without count a single member is returned, or nil if the key does not exist.
+		An error is returned when the value stored at key is not a set.`,
+		Eval:        evalSPOP,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	srandmemberCmdMeta = DiceCmdMeta{
+		Name: "SRANDMEMBER",
+		Info: `SRANDMEMBER key [count]
+		Returns random members of the set stored at key.
+		With a positive count, up to count distinct members are returned.
+		With a negative count, -count members are returned, possibly several times the same.
+		An error is returned when the value stored at key is not a set.`,
+		Eval:       evalSRANDMEMBER,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	sdiffCmdMeta = DiceCmdMeta{
 		Name: "SDIFF",
 		Info: `SDIFF key1 [key2 ... key_N]
 		Returns the members of the set resulting from the difference between the first set and all the successive sets.
 		Non existing keys are treated as empty sets.`,
-		Eval:     evalSDIFF,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalSDIFF,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		IsReadOnly: true,
 	}
 	sinterCmdMeta = DiceCmdMeta{
 		Name: "SINTER",
 		Info: `SINTER key1 [key2 ... key_N]
 		Returns the members of the set resulting from the intersection of all the given sets.
 		Non existing keys are treated as empty sets.`,
-		Eval:     evalSINTER,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalSINTER,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		IsReadOnly: true,
+	}
+	sinterStoreCmdMeta = DiceCmdMeta{
+		Name: "SINTERSTORE",
+		Info: `SINTERSTORE destination key1 [key2 ... key_N] [TTL EX seconds | TTL PX milliseconds | TTL INHERITTTL]
+		Stores the intersection of all the given sets at destination and returns its cardinality.
+		TTL EX/PX set an explicit TTL on destination, TTL INHERITTTL applies the smallest TTL of the source keys.
+		Non existing keys are treated as empty sets.`,
+		Eval:     evalSINTERSTORE,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+	}
+	sunionCmdMeta = DiceCmdMeta{
+		Name: "SUNION",
+		Info: `SUNION key1 [key2 ... key_N]
+		Returns the members of the set resulting from the union of all the given sets.
+		Non existing keys are treated as empty sets.`,
+		Eval:       evalSUNION,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		IsReadOnly: true,
+	}
+	sunionStoreCmdMeta = DiceCmdMeta{
+		Name: "SUNIONSTORE",
+		Info: `SUNIONSTORE destination key1 [key2 ... key_N] [TTL EX seconds | TTL PX milliseconds | TTL INHERITTTL]
+		Stores the union of all the given sets at destination and returns its cardinality.
+		TTL EX/PX set an explicit TTL on destination, TTL INHERITTTL applies the smallest TTL of the source keys.
+		Non existing keys are treated as empty sets.`,
+		Eval:     evalSUNIONSTORE,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+	}
+	sdiffStoreCmdMeta = DiceCmdMeta{
+		Name: "SDIFFSTORE",
+		Info: `SDIFFSTORE destination key1 [key2 ... key_N] [TTL EX seconds | TTL PX milliseconds | TTL INHERITTTL]
+		Stores the difference between the first set and all the successive sets at destination
+		and returns its cardinality.
+		TTL EX/PX set an explicit TTL on destination, TTL INHERITTTL applies the smallest TTL of the source keys.
+		Non existing keys are treated as empty sets.`,
+		Eval:     evalSDIFFSTORE,
+		IsWrite:  true,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+	}
+	sinterCardCmdMeta = DiceCmdMeta{
+		Name: "SINTERCARD",
+		Info: `SINTERCARD numkeys key1 [key2 ... key_N] [LIMIT limit]
+		Returns the cardinality of the intersection of all the given sets.
+		With LIMIT, the computation stops once the cardinality reaches limit, 0 meaning no limit.
+		Non existing keys are treated as empty sets.`,
+		Eval:       evalSINTERCARD,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 2},
+		IsReadOnly: true,
 	}
 	pfAddCmdMeta = DiceCmdMeta{
 		Name: "PFADD",
 		Info: `PFADD key [element [element ...]]
 		Adds elements to a HyperLogLog key. Creates the key if it doesn't exist.`,
 		Eval:     evalPFADD,
+		IsWrite:  true,
 		Arity:    -2,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
@@ -878,69 +1937,102 @@ var (
 		Name: "PFCOUNT",
 		Info: `PFCOUNT key [key ...]
 		Returns the approximated cardinality of the set(s) observed by the HyperLogLog key(s).`,
-		Eval:     evalPFCOUNT,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalPFCOUNT,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		IsReadOnly: true,
 	}
 	pfMergeCmdMeta = DiceCmdMeta{
 		Name: "PFMERGE",
 		Info: `PFMERGE destkey [sourcekey [sourcekey ...]]
 		Merges one or more HyperLogLog values into a single key.`,
 		Eval:     evalPFMERGE,
+		IsWrite:  true,
 		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
 	}
 	jsonStrlenCmdMeta = DiceCmdMeta{
 		Name: "JSON.STRLEN",
 		Info: `JSON.STRLEN key [path]
 		Report the length of the JSON String at path in key`,
-		Eval:     evalJSONSTRLEN,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Eval:       evalJSONSTRLEN,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	hlenCmdMeta = DiceCmdMeta{
 		Name: "HLEN",
 		Info: `HLEN key
 		Returns the number of fields contained in the hash stored at key.`,
-		Eval:  evalHLEN,
-		Arity: 2,
+		Eval:       evalHLEN,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	selectCmdMeta = DiceCmdMeta{
-		Name:  "SELECT",
-		Info:  `Select the logical database having the specified zero-based numeric index. New connections always use the database 0`,
+		Name: "SELECT",
+		Info: `SELECT index
+		Select the logical database having the specified zero-based numeric index.
+		New connections always use the database 0. The number of databases is set
+		by the databases setting of the server, 16 by default.`,
 		Eval:  evalSELECT,
-		Arity: 1,
+		Arity: 2,
+	}
+	swapdbCmdMeta = DiceCmdMeta{
+		Name: "SWAPDB",
+		Info: `SWAPDB index1 index2
+		Swaps two logical databases, so that the clients connected to a database
+		see the keys of the other one right away.
+		The replicas only hold the database 0: they sync again once it is swapped.`,
+		Eval:    evalSWAPDB,
+		Arity:   3,
+		IsWrite: true,
+	}
+	moveCmdMeta = DiceCmdMeta{
+		Name: "MOVE",
+		Info: `MOVE key db
+		Moves key from the selected logical database to the database db, along
+		with its expiry.
+		Returns 1 if the key was moved, 0 if it does not exist or db already holds it.`,
+		Eval:     evalMOVE,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		IsWrite:  true,
 	}
 	jsonnumincrbyCmdMeta = DiceCmdMeta{
 		Name:     "JSON.NUMINCRBY",
 		Info:     `Increment the number value stored at path by number.`,
 		Eval:     evalJSONNUMINCRBY,
-		Arity:    3,
+		IsWrite:  true,
+		Arity:    4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
-	dumpkeyCMmdMeta=DiceCmdMeta{
-		Name:	 "DUMP",
-		Info:	`Serialize the value stored at key in a Redis-specific format and return it to the user.
+	dumpkeyCMmdMeta = DiceCmdMeta{
+		Name: "DUMP",
+		Info: `Serialize the value stored at key in a Redis-specific format and return it to the user.
 				The returned value can be synthesized back into a Redis key using the RESTORE command.`,
-		Eval:   evalDUMP,
-		Arity: 	1,
+		Eval:       evalDUMP,
+		Arity:      2,
 		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
-	restorekeyCmdMeta=DiceCmdMeta{
-		Name:	"RESTORE",
-		Info:  `Serialize the value stored at key in a Redis-specific format and return it to the user.
+	restorekeyCmdMeta = DiceCmdMeta{
+		Name: "RESTORE",
+		Info: `Serialize the value stored at key in a Redis-specific format and return it to the user.
 				The returned value can be synthesized back into a Redis key using the RESTORE command.`,
-		Eval: evalRestore,
-		Arity:	2,
+		Eval:     evalRestore,
+		Arity:    -4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
+		IsWrite:  true,
 	}
 	typeCmdMeta = DiceCmdMeta{
-		Name:     "TYPE",
-		Info:     `Returns the string representation of the type of the value stored at key. The different types that can be returned are: string, list, set, zset, hash and stream.`,
-		Eval:     evalTYPE,
-		Arity:    1,
+		Name:  "TYPE",
+		Info:  `Returns the string representation of the type of the value stored at key. The different types that can be returned are: string, list, set, zset, hash and stream.`,
+		Eval:  evalTYPE,
+		Arity: 2,
 
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	incrbyCmdMeta = DiceCmdMeta{
 		Name: "INCRBY",
@@ -953,15 +2045,17 @@ var (
 		if not INCRBY returns encoded error response.
 		evalINCRBY returns the incremented value for the key if there are no errors.`,
 		Eval:     evalINCRBY,
-		Arity:    2,
+		IsWrite:  true,
+		Arity:    3,
 		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	getRangeCmdMeta = DiceCmdMeta{
-		Name:     "GETRANGE",
-		Info:     `Returns a substring of the string stored at a key.`,
-		Eval:     evalGETRANGE,
-		Arity:    4,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Name:       "GETRANGE",
+		Info:       `Returns a substring of the string stored at a key.`,
+		Eval:       evalGETRANGE,
+		Arity:      4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	setexCmdMeta = DiceCmdMeta{
 		Name: "SETEX",
@@ -971,44 +2065,410 @@ var (
 		Returns encoded error response if expiry time value in not integer
 		Returns encoded OK RESP once new entry is added
 		If the key already exists then the value and expiry will be overwritten`,
-		Arity:      3,
+		Arity:      4,
 		KeySpecs:   KeySpecs{BeginIndex: 1},
 		IsMigrated: true,
 		NewEval:    evalSETEX,
+		IsWrite:    true,
+		CompatReplies: map[string]compatTable{
+			CompatRedis7: {"-ERR invalid expire time in 'SETEX' command\r\n": "-ERR invalid expire time in 'setex' command\r\n"},
+		},
 	}
 	hrandfieldCmdMeta = DiceCmdMeta{
-		Name:     "HRANDFIELD",
-		Info:     `Returns one or more random fields from a hash.`,
-		Eval:     evalHRANDFIELD,
-		Arity:    -2,
-		KeySpecs: KeySpecs{BeginIndex: 1},
+		Name:       "HRANDFIELD",
+		Info:       `Returns one or more random fields from a hash.`,
+		Eval:       evalHRANDFIELD,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	appendCmdMeta = DiceCmdMeta{
-		Name:  "APPEND",
-		Info:  `Appends a string to the value of a key. Creates the key if it doesn't exist.`,
-		Eval:  evalAPPEND,
-		Arity: 3,
+		Name:     "APPEND",
+		Info:     `Appends a string to the value of a key. Creates the key if it doesn't exist.`,
+		Eval:     evalAPPEND,
+		IsWrite:  true,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	strlenCmdMeta = DiceCmdMeta{
+		Name:       "STRLEN",
+		Info:       `STRLEN key returns the length of the string value stored at key, or 0 if the key does not exist.`,
+		Eval:       evalSTRLEN,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
 	}
 	zaddCmdMeta = DiceCmdMeta{
 		Name: "ZADD",
-		Info: `ZADD key [NX|XX] [CH] [INCR] score member [score member ...]
+		Info: `ZADD key [NX|XX] [GT|LT] [CH] [INCR] [WITHPAYLOADS] score member [payload] [score member [payload] ...]
 		Adds all the specified members with the specified scores to the sorted set stored at key.
-		Options: NX, XX, CH, INCR
+		Options: NX, XX, GT, LT, CH, INCR, WITHPAYLOADS
+		GT and LT only update the existing members whose score would increase, or decrease.
+		With INCR, the increment only applies if it does, nil being returned otherwise.
+		WITHPAYLOADS takes a payload after every member, stored along with it, an empty payload removing the one of the member.
 		Returns the number of elements added to the sorted set, not including elements already existing for which the score was updated.`,
 		Eval:     evalZADD,
+		IsWrite:  true,
 		Arity:    -4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
+	zincrbyCmdMeta = DiceCmdMeta{
+		Name: "ZINCRBY",
+		Info: `ZINCRBY key increment member
+		Increments the score of member in the sorted set stored at key by increment.
+		If member does not exist in the sorted set, it is added with increment as its score.
+		Returns the new score of member, or an error if the resulting score is not a number.`,
+		Eval:     evalZINCRBY,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
 	zrangeCmdMeta = DiceCmdMeta{
 		Name: "ZRANGE",
-		Info: `ZRANGE key start stop [WithScores]
+		Info: `ZRANGE key start stop [WithScores] [WITHPAYLOADS]
 		Returns the specified range of elements in the sorted set stored at key.
 		The elements are considered to be ordered from the lowest to the highest score.
 		Both start and stop are 0-based indexes, where 0 is the first element, 1 is the next element and so on.
 		These indexes can also be negative numbers indicating offsets from the end of the sorted set, with -1 being the last element of the sorted set, -2 the penultimate element and so on.
+		WITHPAYLOADS returns the payloads of the elements after their scores, an empty string for the elements without one.
 		Returns the specified range of elements in the sorted set.`,
-		Eval:     evalZRANGE,
+		Eval:       evalZRANGE,
+		Arity:      -4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zscanCmdMeta = DiceCmdMeta{
+		Name: "ZSCAN",
+		Info: `ZSCAN key cursor [MATCH pattern] [COUNT count]
+		Incrementally iterates over the members of the sorted set stored at key. Each call returns the cursor
+		of the next call, 0 when the iteration is over, and a batch of members along with their scores.
+		Each member present for the whole iteration is returned exactly once.`,
+		Eval:       evalZSCAN,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zmscoreCmdMeta = DiceCmdMeta{
+		Name: "ZMSCORE",
+		Info: `ZMSCORE key member [member ...]
+		Returns the scores of the members of the sorted set stored at key.
+		Returns nil for the members that are not in the sorted set.`,
+		Eval:       evalZMSCORE,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zrankCmdMeta = DiceCmdMeta{
+		Name: "ZRANK",
+		Info: `ZRANK key member [WITHSCORE]
+		Returns the 0-based rank of member in the sorted set stored at key, by ascending scores.
+		Returns nil if the member or the key does not exist.
+		WITHSCORE returns the score of the member along with its rank.`,
+		Eval:       evalZRANK,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zrevrankCmdMeta = DiceCmdMeta{
+		Name: "ZREVRANK",
+		Info: `ZREVRANK key member [WITHSCORE]
+		Returns the 0-based rank of member in the sorted set stored at key, by descending scores.
+		Returns nil if the member or the key does not exist.
+		WITHSCORE returns the score of the member along with its rank.`,
+		Eval:       evalZREVRANK,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zrandmemberCmdMeta = DiceCmdMeta{
+		Name: "ZRANDMEMBER",
+		Info: `ZRANDMEMBER key [count [WITHSCORES]]
+		Returns random members of the sorted set stored at key.
+		Without count, returns a single member, or nil if the key does not exist.
+		With a positive count, returns up to count distinct members.
+		With a negative count, returns -count members, possibly repeated.
+		WITHSCORES returns the scores along with the members.`,
+		Eval:       evalZRANDMEMBER,
+		Arity:      -2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zrangebylexCmdMeta = DiceCmdMeta{
+		Name: "ZRANGEBYLEX",
+		Info: `ZRANGEBYLEX key min max [LIMIT offset count]
+		Returns the members of the sorted set stored at key between min and max, by lexicographic order.
+		The members are assumed to have the same score, the members returned being unspecified otherwise.
+		The bounds are [member or (member, inclusive and exclusive, or - and + for the lowest and the highest strings.
+		LIMIT skips the first offset members and returns count members at most, all of them if count is negative.
+		Returns the list of members in the range.`,
+		Eval:       evalZRANGEBYLEX,
+		Arity:      -4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zrevrangebylexCmdMeta = DiceCmdMeta{
+		Name: "ZREVRANGEBYLEX",
+		Info: `ZREVRANGEBYLEX key max min [LIMIT offset count]
+		Returns the members of the sorted set stored at key between max and min, by descending lexicographic order.
+		The bounds and LIMIT are the ones of ZRANGEBYLEX.
+		Returns the list of members in the range.`,
+		Eval:       evalZREVRANGEBYLEX,
+		Arity:      -4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zlexcountCmdMeta = DiceCmdMeta{
+		Name: "ZLEXCOUNT",
+		Info: `ZLEXCOUNT key min max
+		Counts the members of the sorted set stored at key between min and max, by lexicographic order.
+		The bounds are the ones of ZRANGEBYLEX.
+		Returns the number of members in the range.`,
+		Eval:       evalZLEXCOUNT,
+		Arity:      4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zrangebyscoreCmdMeta = DiceCmdMeta{
+		Name: "ZRANGEBYSCORE",
+		Info: `ZRANGEBYSCORE key min max [WITHSCORES] [WITHPAYLOADS] [LIMIT offset count]
+		Returns the members of the sorted set stored at key with a score between min and max, by ascending scores.
+		The bounds are inclusive, or exclusive when prefixed by (, -inf and +inf being valid bounds.
+		WITHSCORES returns the scores along with the members, and WITHPAYLOADS their payloads.
+		LIMIT skips the first offset members and returns count members at most, all of them if count is negative.`,
+		Eval:       evalZRANGEBYSCORE,
+		Arity:      -4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zcountCmdMeta = DiceCmdMeta{
+		Name: "ZCOUNT",
+		Info: `ZCOUNT key min max
+		Counts the members of the sorted set stored at key with a score between min and max.
+		The bounds are the ones of ZRANGEBYSCORE.
+		Returns the number of members in the range.`,
+		Eval:       evalZCOUNT,
+		Arity:      4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	zremCmdMeta = DiceCmdMeta{
+		Name: "ZREM",
+		Info: `ZREM key member [member ...]
+		Removes the members from the sorted set stored at key, the members that do not exist being ignored.
+		The key is deleted once the sorted set is empty.
+		Returns the number of members removed.`,
+		Eval:        evalZREM,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -3,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	zremrangebyrankCmdMeta = DiceCmdMeta{
+		Name: "ZREMRANGEBYRANK",
+		Info: `ZREMRANGEBYRANK key start stop
+		Removes the members of the sorted set stored at key with a rank between start and stop, by ascending scores.
+		The ranks are 0-based, negative ones being offsets from the end of the sorted set, -1 being the last member.
+		The key is deleted once the sorted set is empty.
+		Returns the number of members removed.`,
+		Eval:        evalZREMRANGEBYRANK,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       4,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	zremrangebyscoreCmdMeta = DiceCmdMeta{
+		Name: "ZREMRANGEBYSCORE",
+		Info: `ZREMRANGEBYSCORE key min max
+		Removes the members of the sorted set stored at key with a score between min and max.
+		The bounds are inclusive, or exclusive when prefixed by (, -inf and +inf being valid bounds.
+		The key is deleted once the sorted set is empty.
+		Returns the number of members removed.`,
+		Eval:        evalZREMRANGEBYSCORE,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       4,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	zremrangebylexCmdMeta = DiceCmdMeta{
+		Name: "ZREMRANGEBYLEX",
+		Info: `ZREMRANGEBYLEX key min max
+		Removes the members of the sorted set stored at key between min and max, by lexicographic order.
+		The bounds are the ones of ZRANGEBYLEX, the members being assumed to have the same score.
+		The key is deleted once the sorted set is empty.
+		Returns the number of members removed.`,
+		Eval:        evalZREMRANGEBYLEX,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       4,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	zunionstoreCmdMeta = DiceCmdMeta{
+		Name: "ZUNIONSTORE",
+		Info: `ZUNIONSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [EX seconds | PX milliseconds | INHERITTTL]
+		Stores at destination the union of the sorted sets stored at the keys, the sets being sorted sets with scores of 1.
+		The score of a member is the sum of its scores, or their minimum or maximum with AGGREGATE MIN or MAX.
+		With WEIGHTS, the scores of each sorted set are multiplied by its weight first.
+		The destination is replaced, and deleted if the union is empty.
+		EX/PX set an explicit TTL on destination, INHERITTTL applies the smallest TTL of the keys.
+		Returns the number of members of the sorted set stored.`,
+		Eval:     evalZUNIONSTORE,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	zinterstoreCmdMeta = DiceCmdMeta{
+		Name: "ZINTERSTORE",
+		Info: `ZINTERSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [EX seconds | PX milliseconds | INHERITTTL]
+		Stores at destination the intersection of the sorted sets stored at the keys, the scores being computed like ZUNIONSTORE does.
+		The destination is replaced, and deleted if the intersection is empty.
+		EX/PX set an explicit TTL on destination, INHERITTTL applies the smallest TTL of the keys.
+		Returns the number of members of the sorted set stored.`,
+		Eval:     evalZINTERSTORE,
+		IsWrite:  true,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	zunionCmdMeta = DiceCmdMeta{
+		Name: "ZUNION",
+		Info: `ZUNION numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [WITHSCORES]
+		Returns the union of the sorted sets stored at the keys, computed like ZUNIONSTORE does, by ascending scores.
+		The members are returned along with their scores with WITHSCORES.`,
+		Eval:       evalZUNION,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 2},
+		IsReadOnly: true,
+	}
+	zinterCmdMeta = DiceCmdMeta{
+		Name: "ZINTER",
+		Info: `ZINTER numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [WITHSCORES]
+		Returns the intersection of the sorted sets stored at the keys, computed like ZINTERSTORE does, by ascending scores.
+		The members are returned along with their scores with WITHSCORES.`,
+		Eval:       evalZINTER,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 2},
+		IsReadOnly: true,
+	}
+	zdiffCmdMeta = DiceCmdMeta{
+		Name: "ZDIFF",
+		Info: `ZDIFF numkeys key [key ...] [WITHSCORES]
+		Returns the members of the first sorted set that are not in the other ones, by ascending scores.
+		The members are returned along with their scores with WITHSCORES.`,
+		Eval:       evalZDIFF,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 2},
+		IsReadOnly: true,
+	}
+	zpopminCmdMeta = DiceCmdMeta{
+		Name: "ZPOPMIN",
+		Info: `ZPOPMIN key [count]
+		Removes and returns up to count members with the lowest scores in the sorted set stored at key, 1 by default.
+		Returns the members along with their scores, by ascending scores.`,
+		Eval:        evalZPOPMIN,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	zpopmaxCmdMeta = DiceCmdMeta{
+		Name: "ZPOPMAX",
+		Info: `ZPOPMAX key [count]
+		Removes and returns up to count members with the highest scores in the sorted set stored at key, 1 by default.
+		Returns the members along with their scores, by descending scores.`,
+		Eval:        evalZPOPMAX,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -2,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	zmpopCmdMeta = DiceCmdMeta{
+		Name: "ZMPOP",
+		Info: `ZMPOP numkeys key [key ...] MIN|MAX [COUNT count]
+		Pops up to count members with the lowest or the highest scores from the first non-empty sorted set among the ones stored at the keys.
+		Returns the key along with the members and their scores, or nil if all the sorted sets are empty.`,
+		Eval:     evalZMPOP,
+		IsWrite:  true,
 		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 2},
+	}
+	bzmpopCmdMeta = DiceCmdMeta{
+		Name: "BZMPOP",
+		Info: `BZMPOP timeout numkeys key [key ...] MIN|MAX [COUNT count]
+		Pops up to count members with the lowest or the highest scores from the first non-empty sorted set among the ones stored at the keys.
+		If all the sorted sets are empty, the client is blocked till one of them is added to, or till the timeout in seconds elapses.
+		A timeout of 0 blocks forever. The clients that cannot block, e.g. inside a transaction, time out right away.
+		Returns the key along with the members and their scores, or nil once timed out.`,
+		BlockingEval:  evalBZMPOP,
+		IsWrite:       true,
+		Arity:         -5,
+		KeySpecs:      KeySpecs{BeginIndex: 3},
+		CompatReplies: blockingPopCompatReplies,
+	}
+	bzpopminCmdMeta = DiceCmdMeta{
+		Name: "BZPOPMIN",
+		Info: `BZPOPMIN key [key ...] timeout
+		Pops the member with the lowest score of the first non-empty sorted set among the ones stored at the keys.
+		If all the sorted sets are empty, the client is blocked till one of them is added to, or till the timeout in seconds elapses.
+		A timeout of 0 blocks forever. The clients that cannot block, e.g. inside a transaction, time out right away.
+		Returns the key along with the member and its score, or nil once timed out.`,
+		BlockingEval:  evalBZPOPMIN,
+		IsWrite:       true,
+		Arity:         -3,
+		KeySpecs:      KeySpecs{BeginIndex: 1, Step: 1, LastKey: -2},
+		CompatReplies: blockingPopCompatReplies,
+	}
+	bzpopmaxCmdMeta = DiceCmdMeta{
+		Name: "BZPOPMAX",
+		Info: `BZPOPMAX key [key ...] timeout
+		Pops the member with the highest score of the first non-empty sorted set among the ones stored at the keys.
+		If all the sorted sets are empty, the client is blocked till one of them is added to, or till the timeout in seconds elapses.
+		A timeout of 0 blocks forever. The clients that cannot block, e.g. inside a transaction, time out right away.
+		Returns the key along with the member and its score, or nil once timed out.`,
+		BlockingEval:  evalBZPOPMAX,
+		IsWrite:       true,
+		Arity:         -3,
+		KeySpecs:      KeySpecs{BeginIndex: 1, Step: 1, LastKey: -2},
+		CompatReplies: blockingPopCompatReplies,
+	}
+	zwatchCmdMeta = DiceCmdMeta{
+		Name: "ZWATCH",
+		Info: `ZWATCH key
+		Watches the deltas of the sorted set stored at key. Every time members are added, removed or have their score
+		changed by ZADD, ZINCRBY or ZREM, the client is sent a push response [ZDELTA, key, deltas], every delta being
+		[member, old score, new score, rank change], the rank change being by ascending scores.
+		Returns [ZWATCH, key, members] with the current members and scores of the sorted set.`,
+		Eval:     nil,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	zunwatchCmdMeta = DiceCmdMeta{
+		Name: "ZUNWATCH",
+		Info: `ZUNWATCH key
+		Stops watching the deltas of the sorted set stored at key.`,
+		Eval:     nil,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	zpruneCmdMeta = DiceCmdMeta{
+		Name: "ZPRUNE",
+		Info: `ZPRUNE key threshold
+		Removes the members of the sorted set stored at key with a score lower than threshold.
+		The key is deleted once the sorted set is empty.
+		Returns the number of members removed.`,
+		Eval:     evalZPRUNE,
+		IsWrite:  true,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	zretentionCmdMeta = DiceCmdMeta{
+		Name: "ZRETENTION",
+		Info: `ZRETENTION key milliseconds
+		Sets the retention window of the sorted set stored at key, whose scores are timestamps in milliseconds.
+		The members older than the window are periodically removed, a window of 0 removes the retention policy.
+		The policy is dropped once the key is deleted or overwritten.
+		Returns 1 if the policy was set or removed, 0 if the key does not exist.`,
+		Eval:     evalZRETENTION,
+		IsWrite:  true,
+		Arity:    3,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	bitfieldCmdMeta = DiceCmdMeta{
@@ -1031,9 +2491,24 @@ var (
 		There is another subcommand that only changes the behavior of successive
 		INCRBY and SET subcommands calls by setting the overflow behavior:
 		OVERFLOW [WRAP|SAT|FAIL]`,
-		Arity:    -1,
+		Arity:    -2,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 		Eval:     evalBITFIELD,
+		IsWrite:  true,
+	}
+	hbitfieldCmdMeta = DiceCmdMeta{
+		Name: "HBITFIELD",
+		Info: `HBITFIELD key [GET encoding field] [SET encoding field value] [INCRBY encoding field increment] [OVERFLOW WRAP|SAT|FAIL] ...
+		Applies BITFIELD-style subcommands on integer fields of the hash stored at key, atomically.
+		Each field is bounded by the integer encoding of its subcommand, such as i16 or u8, and
+		OVERFLOW sets the overflow policy of the successive SET and INCRBY subcommands, WRAP by default.
+		Missing fields are considered as 0. If a field holds a value that is not an integer within
+		the bounds of its encoding, an error is returned and no subcommand is applied.
+		Returns an array with the result of each subcommand, nil for the ones failing with the FAIL policy.`,
+		Eval:     evalHBITFIELD,
+		IsWrite:  true,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hincrbyFloatCmdMeta = DiceCmdMeta{
 		Name: "HINCRBYFLOAT",
@@ -1044,9 +2519,143 @@ var (
 		is not parsable as floating point number, then an error occurs.
 		`,
 		Eval:     evalHINCRBYFLOAT,
+		IsWrite:  true,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	xaddCmdMeta = DiceCmdMeta{
+		Name: "XADD",
+		Info: `XADD key [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id field value [field value ...]
+		Appends a new entry to the stream stored at key, creating the stream if it does not exist.
+		With * the ID of the entry is generated by the server, otherwise it must be greater than the last ID of the stream.
+		MAXLEN and MINID trim the stream once the entry is added, as XTRIM does.
+		Returns the ID of the added entry, or nil if NOMKSTREAM is given and the stream does not exist.`,
+		Eval:     evalXADD,
+		IsWrite:  true,
+		Arity:    -5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	xtrimCmdMeta = DiceCmdMeta{
+		Name: "XTRIM",
+		Info: `XTRIM key MAXLEN|MINID [=|~] threshold [LIMIT count]
+		Evicts the oldest entries of the stream stored at key.
+		MAXLEN keeps the threshold last entries, MINID the entries whose ID is not lower than threshold.
+		With ~ only the whole nodes of the stream are evicted, the stream possibly keeping a few more entries,
+		and at most count entries are evicted, 0 meaning no limit.
+		Returns the number of entries evicted.`,
+		Eval:        evalXTRIM,
+		IsWrite:     true,
+		FreesMemory: true,
+		Arity:       -4,
+		KeySpecs:    KeySpecs{BeginIndex: 1},
+	}
+	xlenCmdMeta = DiceCmdMeta{
+		Name: "XLEN",
+		Info: `XLEN key
+		Returns the number of entries of the stream stored at key, or 0 if the key does not exist.`,
+		Eval:       evalXLEN,
+		Arity:      2,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	xrangeCmdMeta = DiceCmdMeta{
+		Name: "XRANGE",
+		Info: `XRANGE key start end [COUNT count]
+		Returns the entries of the stream stored at key whose ID is within the given range, from the lowest ID to the highest.
+		- and + stand for the lowest and highest possible IDs, a bound prefixed by ( is exclusive.`,
+		Eval:       evalXRANGE,
+		Arity:      -4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	xrevrangeCmdMeta = DiceCmdMeta{
+		Name: "XREVRANGE",
+		Info: `XREVRANGE key end start [COUNT count]
+		Returns the entries of the stream stored at key whose ID is within the given range, from the highest ID to the lowest.`,
+		Eval:       evalXREVRANGE,
+		Arity:      -4,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	xreadCmdMeta = DiceCmdMeta{
+		Name: "XREAD",
+		Info: `XREAD [COUNT count] STREAMS key [key ...] id [id ...]
+		Returns the entries with an ID greater than the given one for each of the streams, $ standing for the last ID of the stream.
+		Returns nil if none of the streams has new entries.`,
+		Eval:       evalXREAD,
+		Arity:      -4,
+		IsReadOnly: true,
+	}
+	xgroupCmdMeta = DiceCmdMeta{
+		Name: "XGROUP",
+		Info: `XGROUP CREATE key group id|$ [MKSTREAM]
+		XGROUP SETID key group id|$
+		XGROUP DESTROY key group
+		XGROUP CREATECONSUMER key group consumer
+		XGROUP DELCONSUMER key group consumer
+		Manages the consumer groups of the stream stored at key.
+		CREATE creates a group delivering the entries following id, $ standing for the last ID of the stream.
+		SETID changes the last delivered ID of the group.
+		DESTROY deletes the group, CREATECONSUMER and DELCONSUMER create and delete a consumer of the group.`,
+		Eval:     evalXGROUP,
+		IsWrite:  true,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 2},
+	}
+	xreadgroupCmdMeta = DiceCmdMeta{
+		Name: "XREADGROUP",
+		Info: `XREADGROUP GROUP group consumer [COUNT count] [NOACK] STREAMS key [key ...] id [id ...]
+		Reads the entries of the streams on behalf of a consumer of the group.
+		With the ID > the entries never delivered to the group are returned and added to the pending entries of the consumer,
+		unless NOACK is given. Any other ID returns the pending entries of the consumer with a greater ID.
+		Returns nil if there is nothing to deliver.`,
+		Eval:    evalXREADGROUP,
+		IsWrite: true,
+		Arity:   -7,
+	}
+	xackCmdMeta = DiceCmdMeta{
+		Name: "XACK",
+		Info: `XACK key group id [id ...]
+		Removes the entries from the pending entries list of the group.
+		Returns the number of acknowledged entries.`,
+		Eval:     evalXACK,
+		IsWrite:  true,
 		Arity:    -4,
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
+	xpendingCmdMeta = DiceCmdMeta{
+		Name: "XPENDING",
+		Info: `XPENDING key group [[IDLE min-idle-time] start end count [consumer]]
+		Without a range, returns the number of pending entries of the group, the lowest and highest pending IDs
+		and the number of pending entries per consumer.
+		With a range, returns the ID, the consumer, the idle time and the number of deliveries of each pending entry.`,
+		Eval:       evalXPENDING,
+		Arity:      -3,
+		KeySpecs:   KeySpecs{BeginIndex: 1},
+		IsReadOnly: true,
+	}
+	xclaimCmdMeta = DiceCmdMeta{
+		Name: "XCLAIM",
+		Info: `XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-milliseconds]
+		[RETRYCOUNT count] [FORCE] [JUSTID] [LASTID id]
+		Transfers to the consumer the pending entries idle for at least min-idle-time milliseconds.
+		Returns the claimed entries, or their IDs with JUSTID.`,
+		Eval:     evalXCLAIM,
+		IsWrite:  true,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	xautoclaimCmdMeta = DiceCmdMeta{
+		Name: "XAUTOCLAIM",
+		Info: `XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID]
+		Transfers to the consumer the pending entries idle for at least min-idle-time milliseconds, scanning the
+		pending entries from start.
+		Returns the cursor for the next call, the claimed entries and the IDs of the pending entries deleted from the stream.`,
+		Eval:     evalXAUTOCLAIM,
+		IsWrite:  true,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
 )
 
 func init() {
@@ -1081,25 +2690,71 @@ func init() {
 	DiceCmds["EXPIRE"] = expireCmdMeta
 	DiceCmds["EXPIRETIME"] = expiretimeCmdMeta
 	DiceCmds["EXPIREAT"] = expireatCmdMeta
+	DiceCmds["PEXPIRE"] = pexpireCmdMeta
+	DiceCmds["PEXPIREAT"] = pexpireatCmdMeta
+	DiceCmds["PEXPIRETIME"] = pexpiretimeCmdMeta
 	DiceCmds["HELLO"] = helloCmdMeta
 	DiceCmds["BGREWRITEAOF"] = bgrewriteaofCmdMeta
 	DiceCmds["INCR"] = incrCmdMeta
 	DiceCmds["INCRBYFLOAT"] = incrByFloatCmdMeta
 	DiceCmds["INFO"] = infoCmdMeta
 	DiceCmds["CLIENT"] = clientCmdMeta
+	DiceCmds["ACL"] = aclCmdMeta
+	DiceCmds["CONFIG"] = configCmdMeta
 	DiceCmds["LATENCY"] = latencyCmdMeta
+	DiceCmds["MEMORY"] = memoryCmdMeta
+	DiceCmds["TTLJOB"] = ttljobCmdMeta
+	DiceCmds["DEBUG"] = debugCmdMeta
+	DiceCmds["RESTORE-SNAPSHOT"] = restoreSnapshotCmdMeta
 	DiceCmds["LRU"] = lruCmdMeta
 	DiceCmds["SLEEP"] = sleepCmdMeta
 	DiceCmds["BFINIT"] = bfinitCmdMeta
 	DiceCmds["BFADD"] = bfaddCmdMeta
 	DiceCmds["BFEXISTS"] = bfexistsCmdMeta
 	DiceCmds["BFINFO"] = bfinfoCmdMeta
+	DiceCmds["BF.RESERVE"] = bfreserveCmdMeta
+	DiceCmds["BF.MADD"] = bfmaddCmdMeta
+	DiceCmds["BF.MEXISTS"] = bfmexistsCmdMeta
+	DiceCmds["BF.INSERT"] = bfinsertCmdMeta
+	DiceCmds["BF.CARD"] = bfcardCmdMeta
+	DiceCmds["BF.SCANDUMP"] = bfscandumpCmdMeta
+	DiceCmds["BF.LOADCHUNK"] = bfloadchunkCmdMeta
+	DiceCmds["CF.RESERVE"] = cfreserveCmdMeta
+	DiceCmds["CF.ADD"] = cfaddCmdMeta
+	DiceCmds["CF.ADDNX"] = cfaddnxCmdMeta
+	DiceCmds["CF.EXISTS"] = cfexistsCmdMeta
+	DiceCmds["CF.DEL"] = cfdelCmdMeta
+	DiceCmds["CF.COUNT"] = cfcountCmdMeta
+	DiceCmds["CMS.INITBYDIM"] = cmsinitbydimCmdMeta
+	DiceCmds["CMS.INITBYPROB"] = cmsinitbyprobCmdMeta
+	DiceCmds["CMS.INCRBY"] = cmsincrbyCmdMeta
+	DiceCmds["CMS.QUERY"] = cmsqueryCmdMeta
+	DiceCmds["CMS.INFO"] = cmsinfoCmdMeta
+	DiceCmds["CMS.MERGE"] = cmsmergeCmdMeta
+	DiceCmds["TOPK.RESERVE"] = topkreserveCmdMeta
+	DiceCmds["TOPK.ADD"] = topkaddCmdMeta
+	DiceCmds["TOPK.QUERY"] = topkqueryCmdMeta
+	DiceCmds["TOPK.COUNT"] = topkcountCmdMeta
+	DiceCmds["TOPK.LIST"] = topklistCmdMeta
+	DiceCmds["COUNTER.INCR"] = counterincrCmdMeta
+	DiceCmds["COUNTER.GET"] = countergetCmdMeta
+	DiceCmds["TDIGEST.CREATE"] = tdigestcreateCmdMeta
+	DiceCmds["TDIGEST.ADD"] = tdigestaddCmdMeta
+	DiceCmds["TDIGEST.QUANTILE"] = tdigestquantileCmdMeta
+	DiceCmds["TDIGEST.CDF"] = tdigestcdfCmdMeta
+	DiceCmds["TDIGEST.MERGE"] = tdigestmergeCmdMeta
+	DiceCmds["VIEW.CREATE"] = viewcreateCmdMeta
+	DiceCmds["VIEW.GET"] = viewgetCmdMeta
+	DiceCmds["VIEW.DROP"] = viewdropCmdMeta
 	DiceCmds["SUBSCRIBE"] = subscribeCmdMeta
 	DiceCmds["QWATCH"] = qwatchCmdMeta
 	DiceCmds["QUNWATCH"] = qUnwatchCmdMeta
 	DiceCmds["MULTI"] = MultiCmdMeta
 	DiceCmds["EXEC"] = ExecCmdMeta
 	DiceCmds["DISCARD"] = DiscardCmdMeta
+	DiceCmds["WATCH"] = watchCmdMeta
+	DiceCmds["UNWATCH"] = unwatchCmdMeta
+	DiceCmds["EXECBATCH"] = ExecBatchCmdMeta
 	DiceCmds["ABORT"] = abortCmdMeta
 	DiceCmds["COMMAND"] = commandCmdMeta
 	DiceCmds["SETBIT"] = setBitCmdMeta
@@ -1107,6 +2762,7 @@ func init() {
 	DiceCmds["BITCOUNT"] = bitCountCmdMeta
 	DiceCmds["BITOP"] = bitOpCmdMeta
 	DiceCmds["KEYS"] = keysCmdMeta
+	DiceCmds["SCAN"] = scanCmdMeta
 	DiceCmds["MGET"] = MGetCmdMeta
 	DiceCmds["PERSIST"] = persistCmdMeta
 	DiceCmds["COPY"] = copyCmdMeta
@@ -1120,6 +2776,17 @@ func init() {
 	DiceCmds["HSET"] = hsetCmdMeta
 	DiceCmds["HKEYS"] = hkeysCmdMeta
 	DiceCmds["HSETNX"] = hsetnxCmdMeta
+	DiceCmds["HSETEX"] = hsetexCmdMeta
+	DiceCmds["HEXPIRE"] = hexpireCmdMeta
+	DiceCmds["HPEXPIRE"] = hpexpireCmdMeta
+	DiceCmds["HEXPIREAT"] = hexpireatCmdMeta
+	DiceCmds["HPEXPIREAT"] = hpexpireatCmdMeta
+	DiceCmds["HTTL"] = httlCmdMeta
+	DiceCmds["HPTTL"] = hpttlCmdMeta
+	DiceCmds["HPERSIST"] = hpersistCmdMeta
+	DiceCmds["EXPIREMEMBER"] = expirememberCmdMeta
+	DiceCmds["EXPIREMEMBERAT"] = expirememberatCmdMeta
+	DiceCmds["PEXPIREMEMBERAT"] = pexpirememberatCmdMeta
 	DiceCmds["OBJECT"] = objectCmdMeta
 	DiceCmds["TOUCH"] = touchCmdMeta
 	DiceCmds["LPUSH"] = lpushCmdMeta
@@ -1127,17 +2794,39 @@ func init() {
 	DiceCmds["RPUSH"] = rpushCmdMeta
 	DiceCmds["LPOP"] = lpopCmdMeta
 	DiceCmds["LLEN"] = llenCmdMeta
+	DiceCmds["LMOVE"] = lmoveCmdMeta
+	DiceCmds["RPOPLPUSH"] = rpoplpushCmdMeta
+	DiceCmds["LINSERT"] = linsertCmdMeta
+	DiceCmds["LPOS"] = lposCmdMeta
+	DiceCmds["LPUSHX"] = lpushxCmdMeta
+	DiceCmds["RPUSHX"] = rpushxCmdMeta
+	DiceCmds["BLPOP"] = blpopCmdMeta
+	DiceCmds["BRPOP"] = brpopCmdMeta
+	DiceCmds["BLMOVE"] = blmoveCmdMeta
+	DiceCmds["LMPOP"] = lmpopCmdMeta
+	DiceCmds["BLMPOP"] = blmpopCmdMeta
 	DiceCmds["DBSIZE"] = dbSizeCmdMeta
 	DiceCmds["GETSET"] = getSetCmdMeta
 	DiceCmds["FLUSHDB"] = flushdbCmdMeta
 	DiceCmds["BITPOS"] = bitposCmdMeta
 	DiceCmds["SADD"] = saddCmdMeta
 	DiceCmds["SMEMBERS"] = smembersCmdMeta
+	DiceCmds["SSCAN"] = sscanCmdMeta
 	DiceCmds["SREM"] = sremCmdMeta
 	DiceCmds["SCARD"] = scardCmdMeta
+	DiceCmds["SISMEMBER"] = sismemberCmdMeta
+	DiceCmds["SMISMEMBER"] = smismemberCmdMeta
+	DiceCmds["SPOP"] = spopCmdMeta
+	DiceCmds["SRANDMEMBER"] = srandmemberCmdMeta
 	DiceCmds["SDIFF"] = sdiffCmdMeta
 	DiceCmds["SINTER"] = sinterCmdMeta
+	DiceCmds["SINTERSTORE"] = sinterStoreCmdMeta
+	DiceCmds["SUNION"] = sunionCmdMeta
+	DiceCmds["SUNIONSTORE"] = sunionStoreCmdMeta
+	DiceCmds["SDIFFSTORE"] = sdiffStoreCmdMeta
+	DiceCmds["SINTERCARD"] = sinterCardCmdMeta
 	DiceCmds["HGETALL"] = hgetAllCmdMeta
+	DiceCmds["HSCAN"] = hscanCmdMeta
 	DiceCmds["PFADD"] = pfAddCmdMeta
 	DiceCmds["PFCOUNT"] = pfCountCmdMeta
 	DiceCmds["HGET"] = hgetCmdMeta
@@ -1148,9 +2837,12 @@ func init() {
 	DiceCmds["JSON.MGET"] = jsonMGetCmdMeta
 	DiceCmds["HLEN"] = hlenCmdMeta
 	DiceCmds["SELECT"] = selectCmdMeta
+	DiceCmds["SWAPDB"] = swapdbCmdMeta
+	DiceCmds["MOVE"] = moveCmdMeta
 	DiceCmds["JSON.NUMINCRBY"] = jsonnumincrbyCmdMeta
 	DiceCmds["TYPE"] = typeCmdMeta
 	DiceCmds["HINCRBY"] = hincrbyCmdMeta
+	DiceCmds["HBITFIELD"] = hbitfieldCmdMeta
 	DiceCmds["INCRBY"] = incrbyCmdMeta
 	DiceCmds["GETRANGE"] = getRangeCmdMeta
 	DiceCmds["SETEX"] = setexCmdMeta
@@ -1158,16 +2850,76 @@ func init() {
 	DiceCmds["HDEL"] = hdelCmdMeta
 	DiceCmds["HVALS"] = hValsCmdMeta
 	DiceCmds["APPEND"] = appendCmdMeta
+	DiceCmds["STRLEN"] = strlenCmdMeta
 	DiceCmds["ZADD"] = zaddCmdMeta
+	DiceCmds["ZINCRBY"] = zincrbyCmdMeta
 	DiceCmds["ZRANGE"] = zrangeCmdMeta
+	DiceCmds["ZSCAN"] = zscanCmdMeta
+	DiceCmds["ZMSCORE"] = zmscoreCmdMeta
+	DiceCmds["ZRANK"] = zrankCmdMeta
+	DiceCmds["ZREVRANK"] = zrevrankCmdMeta
+	DiceCmds["ZRANDMEMBER"] = zrandmemberCmdMeta
+	DiceCmds["ZRANGEBYLEX"] = zrangebylexCmdMeta
+	DiceCmds["ZREVRANGEBYLEX"] = zrevrangebylexCmdMeta
+	DiceCmds["ZLEXCOUNT"] = zlexcountCmdMeta
+	DiceCmds["ZRANGEBYSCORE"] = zrangebyscoreCmdMeta
+	DiceCmds["ZCOUNT"] = zcountCmdMeta
+	DiceCmds["ZREM"] = zremCmdMeta
+	DiceCmds["ZREMRANGEBYRANK"] = zremrangebyrankCmdMeta
+	DiceCmds["ZREMRANGEBYSCORE"] = zremrangebyscoreCmdMeta
+	DiceCmds["ZREMRANGEBYLEX"] = zremrangebylexCmdMeta
+	DiceCmds["ZUNIONSTORE"] = zunionstoreCmdMeta
+	DiceCmds["ZINTERSTORE"] = zinterstoreCmdMeta
+	DiceCmds["ZUNION"] = zunionCmdMeta
+	DiceCmds["ZINTER"] = zinterCmdMeta
+	DiceCmds["ZDIFF"] = zdiffCmdMeta
+	DiceCmds["ZPOPMIN"] = zpopminCmdMeta
+	DiceCmds["ZPOPMAX"] = zpopmaxCmdMeta
+	DiceCmds["BZPOPMIN"] = bzpopminCmdMeta
+	DiceCmds["BZPOPMAX"] = bzpopmaxCmdMeta
+	DiceCmds["ZMPOP"] = zmpopCmdMeta
+	DiceCmds["BZMPOP"] = bzmpopCmdMeta
+	DiceCmds["ZWATCH"] = zwatchCmdMeta
+	DiceCmds["ZUNWATCH"] = zunwatchCmdMeta
+	DiceCmds["ZPRUNE"] = zpruneCmdMeta
+	DiceCmds["ZRETENTION"] = zretentionCmdMeta
 	DiceCmds["BITFIELD"] = bitfieldCmdMeta
 	DiceCmds["HINCRBYFLOAT"] = hincrbyFloatCmdMeta
 	DiceCmds["HEXISTS"] = hexistsCmdMeta
+	DiceCmds["XADD"] = xaddCmdMeta
+	DiceCmds["XLEN"] = xlenCmdMeta
+	DiceCmds["XTRIM"] = xtrimCmdMeta
+	DiceCmds["XRANGE"] = xrangeCmdMeta
+	DiceCmds["XREVRANGE"] = xrevrangeCmdMeta
+	DiceCmds["XREAD"] = xreadCmdMeta
+	DiceCmds["XGROUP"] = xgroupCmdMeta
+	DiceCmds["XREADGROUP"] = xreadgroupCmdMeta
+	DiceCmds["XACK"] = xackCmdMeta
+	DiceCmds["XPENDING"] = xpendingCmdMeta
+	DiceCmds["XCLAIM"] = xclaimCmdMeta
+	DiceCmds["XAUTOCLAIM"] = xautoclaimCmdMeta
+
+	// the HELP commands of the families list the commands registered above
+	registerFamilyHelp()
 }
 
 // Function to convert DiceCmdMeta to []interface{}
 func convertCmdMetaToSlice(cmdMeta *DiceCmdMeta) []interface{} {
-	return []interface{}{cmdMeta.Name, cmdMeta.Arity, cmdMeta.KeySpecs.BeginIndex, cmdMeta.KeySpecs.LastKey, cmdMeta.KeySpecs.Step}
+	return []interface{}{cmdMeta.Name, cmdMeta.Arity, cmdMeta.KeySpecs.BeginIndex, cmdMeta.KeySpecs.LastKey, cmdMeta.KeySpecs.Step, commandFlags(cmdMeta)}
+}
+
+// commandFlags returns the flags of the command reported by COMMAND INFO, named
+// like the ones of Redis: write or readonly, telling the clients which commands
+// they may send to the replicas.
+func commandFlags(cmdMeta *DiceCmdMeta) []string {
+	flags := make([]string, 0, 1)
+	if cmdMeta.IsWrite {
+		flags = append(flags, "write")
+	}
+	if cmdMeta.IsReadOnly {
+		flags = append(flags, "readonly")
+	}
+	return flags
 }
 
 // Function to convert map[string]DiceCmdMeta{} to []interface{}
@@ -0,0 +1,53 @@
+package eval
+
+// CompatRedis7 is the version of the reference server the replies can be made
+// compatible with, see config.DiceConfig.Server.ReplyCompat.
+const CompatRedis7 = "redis-7"
+
+// compatTable maps the replies of DiceDB that differ from the ones of a
+// reference server to the replies of the reference server they are rewritten
+// to. The replies are matched whole, byte for byte. The tables of a command are
+// held by its DiceCmdMeta, see CompatReplies.
+type compatTable map[string]string
+
+// compatTables holds, by version of the reference server, the replies
+// rewritten for every command.
+var compatTables = map[string]compatTable{
+	CompatRedis7: {
+		"-ERR value is not a valid integer\r\n": "-ERR value is not an integer or out of range\r\n",
+	},
+}
+
+// blockingPopCompatReplies holds the replies of the blocking pops, which time
+// out with a nil array.
+var blockingPopCompatReplies = map[string]compatTable{
+	CompatRedis7: {"$-1\r\n": "*-1\r\n"},
+}
+
+// IsReplyCompat returns true if version is the version of a reference server
+// the replies can be made compatible with, the empty version meaning none.
+func IsReplyCompat(version string) bool {
+	_, ok := compatTables[version]
+	return ok || version == ""
+}
+
+// CompatReply returns the reply of the reference server of the given version
+// to the command named cmdName, for the reply of DiceDB. The reply is returned
+// as is if it does not differ, or if no compatibility version is set.
+func CompatReply(version, cmdName string, reply []byte) []byte {
+	if version == "" {
+		return reply
+	}
+	table, ok := compatTables[version]
+	if !ok {
+		return reply
+	}
+
+	if compat, ok := DiceCmds[cmdName].CompatReplies[version][string(reply)]; ok {
+		return []byte(compat)
+	}
+	if compat, ok := table[string(reply)]; ok {
+		return []byte(compat)
+	}
+	return reply
+}
@@ -0,0 +1,40 @@
+package eval
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestCompatReply(t *testing.T) {
+	store := dstore.NewStore(nil)
+	compat := func(version, cmdName string, reply []byte) string {
+		return string(CompatReply(version, cmdName, reply))
+	}
+
+	// the replies are matched against the ones DiceDB actually sends
+	evalBFRESERVE([]string{"bf", "0.01", "100"}, store)
+	reserved := evalBFRESERVE([]string{"bf", "0.01", "100"}, store)
+	assert.Equal(t, "-ERR item exists\r\n", compat("redis-7", "BF.RESERVE", reserved))
+	assert.Equal(t, string(reserved), compat("", "BF.RESERVE", reserved))
+	assert.Equal(t, "-ERR not found\r\n", compat("redis-7", "BFINFO", evalBFINFO([]string{"missing"}, store)))
+	assert.Equal(t, ":0\r\n", compat("redis-7", "BFEXISTS", evalBFEXISTS([]string{"missing", "a"}, store)))
+	assert.Equal(t, "-ERR not found\r\n", compat("redis-7", "BF.INSERT", evalBFINSERT([]string{"missing", "NOCREATE", "ITEMS", "a"}, store)))
+
+	// the rules of a command only apply to it
+	assert.Equal(t, "*-1\r\n", compat("redis-7", "BLPOP", clientio.RespNIL))
+	assert.Equal(t, "$-1\r\n", compat("redis-7", "BLMOVE", clientio.RespNIL))
+	assert.Equal(t, "$-1\r\n", compat("redis-7", "GET", clientio.RespNIL))
+
+	// the rules without a command apply to all of them
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n",
+		compat("redis-7", "ZRANGE", evalZRANGE([]string{"z", "a", "1"}, store)))
+
+	assert.Equal(t, "$-1\r\n", compat("redis-0", "BLPOP", clientio.RespNIL))
+	assert.Assert(t, IsReplyCompat("redis-7"))
+	assert.Assert(t, IsReplyCompat(""))
+	assert.Assert(t, !IsReplyCompat("redis-0"))
+}
@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"github.com/google/btree"
+
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+const (
+	// keyOverhead is the memory used by a key besides its name and its value,
+	// roughly the object, the header of the name and the entry of the table.
+	keyOverhead = 48
+
+	// elementOverhead is the memory used by an element of a set, a hash or a
+	// sorted set besides its strings, roughly the entry of its map.
+	elementOverhead = 16
+)
+
+// SampleKeyspace returns the composition of the keyspace of store by type,
+// extrapolated from up to n of its keys. The keys sampled are the first ones
+// of an iteration of the table, which starts at a random position. The sizes
+// are approximate: they are the sizes of the strings held by the values plus
+// a fixed overhead per element, and the values offloaded to the cold tier only
+// account for their key.
+func SampleKeyspace(store *dstore.Store, n int) metrics.Composition {
+	c := metrics.Composition{
+		Time:  utils.GetCurrentTime(),
+		Keys:  int64(store.GetKeyCount()),
+		Types: make(map[string]metrics.TypeStats),
+	}
+	if n <= 0 {
+		return c
+	}
+
+	store.GetStore().All(func(k string, obj *object.Obj) bool {
+		t := compositionType(obj)
+		s := c.Types[t]
+		s.Keys++
+		s.Bytes += int64(len(k)) + keyOverhead + valueSize(obj)
+		c.Types[t] = s
+		c.Sampled++
+		return c.Sampled < int64(n)
+	})
+
+	if c.Sampled == 0 {
+		return c
+	}
+	ratio := float64(c.Keys) / float64(c.Sampled)
+	for t, s := range c.Types {
+		c.Types[t] = metrics.TypeStats{
+			Keys:  int64(float64(s.Keys)*ratio + 0.5),
+			Bytes: int64(float64(s.Bytes)*ratio + 0.5),
+		}
+	}
+	return c
+}
+
+// compositionType returns the type of obj as reported by the composition of
+// the keyspace, telling the probabilistic structures and JSON apart.
+func compositionType(obj *object.Obj) string {
+	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
+	case object.ObjTypeJSON:
+		return "json"
+	case object.ObjTypeBitSet:
+		return "bloom"
+	case object.ObjTypeCuckoo:
+		return "cuckoo"
+	case object.ObjTypeCountMinSketch:
+		return "cms"
+	case object.ObjTypeTopK:
+		return "topk"
+	case object.ObjTypeTDigest:
+		return "tdigest"
+	default:
+		return typeName(obj)
+	}
+}
+
+// ValueSummary returns the type of obj, as reported by the composition of the
+// keyspace, and the approximate memory used by its value, which describe the
+// values changed to the CDC subscribers.
+func ValueSummary(obj *object.Obj) (typ string, size int64) {
+	return compositionType(obj), valueSize(obj)
+}
+
+// ValueSize returns the approximate memory used by the value of obj, which the
+// stores count as freed once they evict it.
+func ValueSize(obj *object.Obj) int64 {
+	return valueSize(obj)
+}
+
+// valueSize returns the approximate memory used by the value of obj. The
+// values without a dedicated estimate are measured by their tier encoding.
+func valueSize(obj *object.Obj) int64 {
+	if _, oEnc := object.ExtractTypeEncoding(obj); oEnc == object.ObjEncodingCold {
+		return 0
+	}
+
+	switch v := obj.Value.(type) {
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	case int64:
+		return 8
+	case *ByteArray:
+		return int64(len(v.data))
+	case *Deque:
+		return v.list.memSize()
+	case map[string]struct{}:
+		var size int64
+		for m := range v {
+			size += int64(len(m)) + elementOverhead
+		}
+		return size
+	case HashMap:
+		var size int64
+		for f, val := range v {
+			size += int64(len(f)+len(val)) + elementOverhead
+		}
+		return size
+	case *Stream:
+		var size int64
+		v.nodes.Ascend(func(item btree.Item) bool {
+			size += int64(len(item.(*streamNode).data))
+			return true
+		})
+		return size
+	}
+
+	if oType, _ := object.ExtractTypeEncoding(obj); oType == object.ObjTypeSortedSet {
+		if tree, _, errResp := getSortedSet(obj); errResp == nil {
+			var size int64
+			tree.Ascend(func(item *SortedSetItem) bool {
+				// the member is held by both the map and the skiplist
+				size += int64(len(item.Member)+len(item.Payload)) + 8 + 2*elementOverhead
+				return true
+			})
+			return size
+		}
+	}
+	data, err := encodeTierValue(obj)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
@@ -0,0 +1,54 @@
+package eval
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestSampleKeyspace(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	store := dstore.NewStore(nil)
+	for i := 0; i < 30; i++ {
+		evalSET([]string{fmt.Sprintf("s%02d", i), "abcdefghij"}, store)
+	}
+	for i := 0; i < 10; i++ {
+		evalZADD([]string{fmt.Sprintf("z%02d", i), "1", "abc", "2", "def"}, store)
+	}
+	evalHSET([]string{"h", "field", "value"}, store)
+	evalJSONSET([]string{"j", "$", `{"a":1}`}, store)
+
+	// all the keys sampled, the sizes are exact estimates
+	c := SampleKeyspace(store, 100)
+	assert.Equal(t, time.Unix(1000, 0), c.Time)
+	assert.Equal(t, int64(42), c.Sampled)
+	assert.Equal(t, int64(42), c.Keys)
+	assert.DeepEqual(t, []string{"hash", "json", "string", "zset"}, c.SortedTypes())
+	assert.Equal(t, int64(30), c.Types["string"].Keys)
+	assert.Equal(t, int64(30*(3+keyOverhead+10)), c.Types["string"].Bytes)
+	assert.Equal(t, int64(10*(3+keyOverhead+2*(3+8+2*elementOverhead))), c.Types["zset"].Bytes)
+	assert.Equal(t, int64(1+keyOverhead+5+5+elementOverhead), c.Types["hash"].Bytes)
+	assert.Assert(t, c.Types["json"].Bytes > 1+keyOverhead)
+
+	// a partial sample is extrapolated to the whole keyspace
+	c = SampleKeyspace(store, 21)
+	assert.Equal(t, int64(21), c.Sampled)
+	var keys int64
+	for _, s := range c.Types {
+		assert.Equal(t, int64(0), s.Keys%2)
+		keys += s.Keys
+	}
+	assert.Equal(t, int64(42), keys)
+
+	c = SampleKeyspace(store, 0)
+	assert.Equal(t, int64(0), c.Sampled)
+	assert.Equal(t, 0, len(c.Types))
+}
@@ -17,18 +17,58 @@ const (
 	NX         string = "NX"
 	GT         string = "GT"
 	LT         string = "LT"
+	CH         string = "CH"
+	INCR       string = "INCR"
 	KeepTTL    string = "KEEPTTL"
+	InheritTTL string = "INHERITTTL"
+	StoreTTL   string = "TTL"
 	Sync       string = "SYNC"
 	Async      string = "ASYNC"
 	Help       string = "HELP"
 	Memory     string = "MEMORY"
 	Count      string = "COUNT"
+	Match      string = "MATCH"
+	Type       string = "TYPE"
+	Snapshot   string = "SNAPSHOT"
+	Streams    string = "STREAMS"
+	Block      string = "BLOCK"
+	NoMkStream string = "NOMKSTREAM"
+	MaxLen     string = "MAXLEN"
+	MinID      string = "MINID"
+	Group      string = "GROUP"
+	NoAck      string = "NOACK"
+	MkStream   string = "MKSTREAM"
+	Idle       string = "IDLE"
+	Time       string = "TIME"
+	RetryCount string = "RETRYCOUNT"
+	Force      string = "FORCE"
+	JustID     string = "JUSTID"
+	LastID     string = "LASTID"
+	Create     string = "CREATE"
+	Destroy    string = "DESTROY"
+	SetID      string = "SETID"
+	CreateCons string = "CREATECONSUMER"
+	DelCons    string = "DELCONSUMER"
 	GetKeys    string = "GETKEYS"
 	List       string = "LIST"
 	Info       string = "INFO"
+	Reload     string = "RELOAD"
+	Latest     string = "LATEST"
+	Reset      string = "RESET"
+	Top        string = "TOP"
+	Card       string = "CARD"
+	Limit      string = "LIMIT"
+	Left       string = "LEFT"
+	Right      string = "RIGHT"
+	Before     string = "BEFORE"
+	After      string = "AFTER"
+	Rank       string = "RANK"
+	Freq       string = "FREQ"
 	null       string = "null"
 	WithValues string = "WITHVALUES"
 	WithScores string = "WITHSCORES"
+	WithScore  string = "WITHSCORE"
+	WithCount  string = "WITHCOUNT"
 	REV        string = "REV"
 	GET        string = "GET"
 	SET        string = "SET"
@@ -39,4 +79,37 @@ const (
 	FAIL       string = "FAIL"
 	SIGNED     string = "SIGNED"
 	UNSIGNED   string = "UNSIGNED"
+	Pause      string = "PAUSE"
+	SetUser    string = "SETUSER"
+	DelUser    string = "DELUSER"
+	Users      string = "USERS"
+	Log        string = "LOG"
+	Unpause    string = "UNPAUSE"
+	DefaultTTL string = "DEFAULTTTL"
+	Set        string = "SET"
+	Del        string = "DEL"
+	Token      string = "TOKEN"
+	ReadAfter  string = "READAFTER"
+	Write      string = "WRITE"
+	All        string = "ALL"
+	Weights    string = "WEIGHTS"
+	Aggregate  string = "AGGREGATE"
+	Sum        string = "SUM"
+	Min        string = "MIN"
+	Max        string = "MAX"
+	Stats      string = "STATS"
+	Fields     string = "FIELDS"
+	Start      string = "START"
+	Status     string = "STATUS"
+	Cancel     string = "CANCEL"
+	Persist    string = "PERSIST"
+	Batch      string = "BATCH"
+	Rate       string = "RATE"
+
+	Compression string = "COMPRESSION"
+	Override    string = "OVERRIDE"
+
+	WithPayloads string = "WITHPAYLOADS"
+
+	Prefix string = "PREFIX"
 )
@@ -0,0 +1,148 @@
+package eval
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// MaxCounterShards bounds the number of sub-counters a counter is split into.
+const MaxCounterShards = 128
+
+// A sharded counter spreads the increments of a hot counter over several
+// sub-counters, whose keys are distributed across the shards, and sums them
+// on read. The counter itself is not stored, only its sub-counters are.
+
+// CounterSubKey returns the key of the i-th sub-counter of the counter key.
+func CounterSubKey(key string, i int) string {
+	return key + "#" + strconv.Itoa(i)
+}
+
+// ParseCounterShards parses the number of sub-counters of a counter.
+func ParseCounterShards(arg string) (int, bool) {
+	shards, err := strconv.Atoi(arg)
+	if err != nil || shards < 1 || shards > MaxCounterShards {
+		return 0, false
+	}
+	return shards, true
+}
+
+// RandomCounterSubKey returns the key of a sub-counter of key picked at random,
+// so that the increments are spread evenly over the sub-counters.
+func RandomCounterSubKey(key string, shards int) string {
+	return CounterSubKey(key, rand.Intn(shards)) //nolint:gosec
+}
+
+// SumCounterShards adds up the values of the sub-counters of a counter, as
+// returned by GET. Missing sub-counters count as 0.
+func SumCounterShards(responses ...EvalResponse) interface{} {
+	var total int64
+	for _, resp := range responses {
+		if resp.Error != nil {
+			return resp.Error
+		}
+
+		var value int64
+		switch v := resp.Result.(type) {
+		case int64:
+			value = v
+		case string:
+			var err error
+			if value, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return diceerrors.ErrIntegerOutOfRange
+			}
+		case clientio.RespType, nil:
+			continue
+		default:
+			return diceerrors.ErrWrongTypeOperation
+		}
+
+		if (value > 0 && total > math.MaxInt64-value) || (value < 0 && total < math.MinInt64-value) {
+			return diceerrors.ErrOverflow
+		}
+		total += value
+	}
+	return total
+}
+
+// evalCOUNTERINCR evaluates the COUNTER.INCR command, which increments one of
+// the sub-counters of a sharded counter, picked at random, by increment (1 by
+// default). It returns the new value of the sub-counter, not of the counter.
+//
+// Usage: COUNTER.INCR key shards [increment]
+func evalCOUNTERINCR(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 && len(args) != 3 {
+		return diceerrors.NewErrArity("COUNTER.INCR")
+	}
+
+	shards, ok := ParseCounterShards(args[1])
+	if !ok {
+		return diceerrors.NewErrWithFormattedMessage("shards must be between 1 and %d", MaxCounterShards)
+	}
+
+	incr := int64(1)
+	if len(args) == 3 {
+		var err error
+		if incr, err = strconv.ParseInt(args[2], 10, 64); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+	}
+
+	return incrDecrCmd([]string{RandomCounterSubKey(args[0], shards)}, incr, store)
+}
+
+// evalCOUNTERGET evaluates the COUNTER.GET command, which returns the sum of
+// the sub-counters of a sharded counter.
+//
+// Usage: COUNTER.GET key shards
+func evalCOUNTERGET(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("COUNTER.GET")
+	}
+
+	shards, ok := ParseCounterShards(args[1])
+	if !ok {
+		return diceerrors.NewErrWithFormattedMessage("shards must be between 1 and %d", MaxCounterShards)
+	}
+
+	responses := make([]EvalResponse, shards)
+	for i := range responses {
+		responses[i] = *evalGET([]string{CounterSubKey(args[0], i)}, store)
+	}
+	return clientio.Encode(SumCounterShards(responses...), false)
+}
+
+// rewriteCOUNTERINCR propagates COUNTER.INCR, whose sub-counter is picked at
+// random, as the values of the sub-counters of the counter.
+func rewriteCOUNTERINCR(c *cmd.DiceDBCmd, _ interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	shards, ok := ParseCounterShards(c.Args[1])
+	if !ok {
+		return nil
+	}
+
+	var cmds []*cmd.DiceDBCmd
+	for i := 0; i < shards; i++ {
+		key := CounterSubKey(c.Args[0], i)
+		obj, ok := store.GetStore().Get(key)
+		if !ok || object.AssertType(obj.TypeEncoding, object.ObjTypeInt) != nil {
+			continue
+		}
+		value, ok := dstore.PlainObj(obj).Value.(int64)
+		if !ok {
+			continue
+		}
+
+		args := []string{key, strconv.FormatInt(value, 10)}
+		if exp, ok := expiryOf(key, store); ok {
+			args = append(args, Pxat, exp)
+		}
+		cmds = append(cmds, &cmd.DiceDBCmd{Cmd: "SET", Args: args})
+	}
+	return cmds
+}
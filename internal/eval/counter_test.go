@@ -0,0 +1,62 @@
+package eval
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestShardedCounter(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	assert.Equal(t, ":0\r\n", string(evalCOUNTERGET([]string{"views", "8"}, store)))
+	for i := 0; i < 100; i++ {
+		evalCOUNTERINCR([]string{"views", "8"}, store)
+	}
+	evalCOUNTERINCR([]string{"views", "8", "-10"}, store)
+	assert.Equal(t, ":90\r\n", string(evalCOUNTERGET([]string{"views", "8"}, store)))
+
+	// the increments are spread over the sub-counters
+	used := 0
+	for i := 0; i < 8; i++ {
+		if store.Get(CounterSubKey("views", i)) != nil {
+			used++
+		}
+	}
+	assert.Assert(t, used > 1)
+	assert.Assert(t, store.Get("views") == nil)
+
+	tests := map[string]struct {
+		result   []byte
+		expected string
+	}{
+		"arity":              {evalCOUNTERINCR([]string{"views"}, store), "-ERR wrong number of arguments for 'counter.incr' command\r\n"},
+		"invalid shards":     {evalCOUNTERGET([]string{"views", "0"}, store), "-ERR shards must be between 1 and 128\r\n"},
+		"too many shards":    {evalCOUNTERINCR([]string{"views", "129"}, store), "-ERR shards must be between 1 and 128\r\n"},
+		"invalid increment":  {evalCOUNTERINCR([]string{"views", "8", "x"}, store), "-ERR value is not an integer or out of range\r\n"},
+		"sub-counter number": {evalCOUNTERINCR([]string{"single", "1", "5"}, store), ":5\r\n"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(tc.result))
+		})
+	}
+}
+
+func TestSumCounterShards(t *testing.T) {
+	assert.Equal(t, int64(7), SumCounterShards(
+		EvalResponse{Result: int64(5)}, EvalResponse{Result: "2"}, EvalResponse{Result: clientio.NIL}))
+	assert.Equal(t, diceerrors.ErrOverflow, SumCounterShards(
+		EvalResponse{Result: int64(math.MaxInt64)}, EvalResponse{Result: int64(1)}))
+	assert.Equal(t, diceerrors.ErrIntegerOutOfRange, SumCounterShards(EvalResponse{Result: "x"}))
+
+	err := errors.New("ERR shard failure")
+	assert.Equal(t, err, SumCounterShards(EvalResponse{Result: int64(1)}, EvalResponse{Error: err}))
+	assert.Equal(t, int64(3), SumCounterShards(EvalResponse{Result: strconv.Itoa(3)}))
+}
@@ -0,0 +1,405 @@
+package eval
+
+import (
+	"errors"
+	"math/bits"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hashing"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+const (
+	defaultCuckooCapacity      uint64 = 1024
+	defaultCuckooBucketSize    uint64 = 2
+	defaultCuckooMaxIterations uint64 = 20
+
+	maxCuckooBucketSize    uint64 = 255
+	maxCuckooMaxIterations uint64 = 65535
+
+	// fingerprints are multiplied by this constant to derive the alternate bucket
+	cuckooAltHashMultiplier uint64 = 0x5bd1e995
+)
+
+var (
+	errInvalidBucketSize    = diceerrors.NewErr("invalid bucket size value provided")
+	errInvalidMaxIterations = diceerrors.NewErr("invalid max iterations value provided")
+
+	errCuckooKeyExists  = diceerrors.NewErr("item exists")
+	errCuckooInvalidKey = diceerrors.NewErr("invalid key: no cuckoo filter found")
+	errCuckooFull       = diceerrors.NewErr("filter is full")
+)
+
+type CuckooOpts struct {
+	capacity      uint64 // number of expected entries to be added to the filter
+	bucketSize    uint64 // number of fingerprints held by each bucket
+	maxIterations uint64 // number of relocations attempted before the filter is declared full
+}
+
+// Cuckoo is a cuckoo filter. Unlike a bloom filter it stores a fingerprint
+// of every item in one of two candidate buckets, which allows items to be
+// deleted and counted.
+type Cuckoo struct {
+	opts       *CuckooOpts
+	numBuckets uint64   // always a power of 2 so that the alternate bucket can be computed with a mask
+	slots      []uint16 // numBuckets * bucketSize fingerprints, 0 marks an empty slot
+	items      uint64   // number of fingerprints stored
+	deletes    uint64   // number of items deleted
+	hasher     hashing.Hasher
+}
+
+// newCuckooOpts extracts the user defined values from `args`, the capacity
+// followed by the optional BUCKETSIZE and MAXITERATIONS arguments. It falls
+// back to default values if `useDefaults` is set to true.
+func newCuckooOpts(args []string, useDefaults bool) (*CuckooOpts, error) {
+	opts := &CuckooOpts{
+		capacity:      defaultCuckooCapacity,
+		bucketSize:    defaultCuckooBucketSize,
+		maxIterations: defaultCuckooMaxIterations,
+	}
+	if useDefaults {
+		return opts, nil
+	}
+
+	capacity, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return nil, errInvalidCapacityType
+	}
+	if capacity < 1 {
+		return nil, errInvalidCapacity
+	}
+	opts.capacity = capacity
+
+	for i := 1; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			return nil, diceerrors.NewErr(diceerrors.SyntaxErr)
+		}
+
+		value, err := strconv.ParseUint(args[i+1], 10, 64)
+		switch strings.ToUpper(args[i]) {
+		case "BUCKETSIZE":
+			if err != nil || value < 1 || value > maxCuckooBucketSize {
+				return nil, errInvalidBucketSize
+			}
+			opts.bucketSize = value
+		case "MAXITERATIONS":
+			if err != nil || value < 1 || value > maxCuckooMaxIterations {
+				return nil, errInvalidMaxIterations
+			}
+			opts.maxIterations = value
+		default:
+			return nil, diceerrors.NewErr(diceerrors.SyntaxErr)
+		}
+	}
+
+	return opts, nil
+}
+
+// newCuckooFilter creates and returns a new filter with enough buckets to
+// hold `capacity` items.
+func newCuckooFilter(opts *CuckooOpts) *Cuckoo {
+	numBuckets := (opts.capacity + opts.bucketSize - 1) / opts.bucketSize
+	if numBuckets&(numBuckets-1) != 0 {
+		numBuckets = 1 << bits.Len64(numBuckets)
+	}
+
+	return &Cuckoo{
+		opts:       opts,
+		numBuckets: numBuckets,
+		slots:      make([]uint16, numBuckets*opts.bucketSize),
+		hasher:     sketchHasher(),
+	}
+}
+
+// locate returns the fingerprint of `value` and the two buckets it can be stored in.
+func (c *Cuckoo) locate(value string) (fp uint16, i1, i2 uint64) {
+	h := c.hasher.Sum64(value)
+
+	// the fingerprint is taken from the high bits, the bucket from the low ones
+	fp = uint16(h >> 48)
+	if fp == 0 {
+		fp = 1
+	}
+	i1 = h & (c.numBuckets - 1)
+	return fp, i1, c.altIndex(i1, fp)
+}
+
+// altIndex returns the other bucket of a fingerprint stored in bucket `i`.
+// Since it is a XOR, applying it twice returns the original bucket.
+func (c *Cuckoo) altIndex(i uint64, fp uint16) uint64 {
+	return (i ^ (uint64(fp) * cuckooAltHashMultiplier)) & (c.numBuckets - 1)
+}
+
+// bucket returns the slots of the bucket `i`.
+func (c *Cuckoo) bucket(i uint64) []uint16 {
+	return c.slots[i*c.opts.bucketSize : (i+1)*c.opts.bucketSize]
+}
+
+// insertInto stores `fp` in the first empty slot of the bucket `i`, and
+// returns false if the bucket is full.
+func (c *Cuckoo) insertInto(i uint64, fp uint16) bool {
+	bucket := c.bucket(i)
+	for j := range bucket {
+		if bucket[j] == 0 {
+			bucket[j] = fp
+			c.items++
+			return true
+		}
+	}
+	return false
+}
+
+// add adds a new entry for `value` in the filter. If both candidate buckets
+// are full, fingerprints are relocated to their alternate bucket till a slot
+// frees up. Returns errCuckooFull if no slot could be found, in which case
+// the filter is left untouched.
+func (c *Cuckoo) add(value string) error {
+	fp, i1, i2 := c.locate(value)
+	if c.insertInto(i1, fp) || c.insertInto(i2, fp) {
+		return nil
+	}
+
+	type move struct {
+		slot uint64
+		fp   uint16
+	}
+	moves := make([]move, 0, c.opts.maxIterations)
+
+	i := i1
+	if rand.Intn(2) == 1 { //nolint:gosec
+		i = i2
+	}
+	for n := uint64(0); n < c.opts.maxIterations; n++ {
+		// swap the fingerprint with a random one of the bucket, and move the
+		// latter to its alternate bucket
+		slot := i*c.opts.bucketSize + uint64(rand.Intn(int(c.opts.bucketSize))) //nolint:gosec
+		moves = append(moves, move{slot, c.slots[slot]})
+		fp, c.slots[slot] = c.slots[slot], fp
+
+		i = c.altIndex(i, fp)
+		if c.insertInto(i, fp) {
+			return nil
+		}
+	}
+
+	// undo the relocations so that no fingerprint is lost
+	for j := len(moves) - 1; j >= 0; j-- {
+		c.slots[moves[j].slot] = moves[j].fp
+	}
+	return errCuckooFull
+}
+
+// count returns the number of times the fingerprint of `value` is stored in
+// the filter. It is an upper bound of the number of times `value` was added.
+func (c *Cuckoo) count(value string) int64 {
+	fp, i1, i2 := c.locate(value)
+
+	var n int64
+	for _, v := range c.bucket(i1) {
+		if v == fp {
+			n++
+		}
+	}
+	if i2 != i1 {
+		for _, v := range c.bucket(i2) {
+			if v == fp {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// exists checks if the given `value` may exist in the filter.
+func (c *Cuckoo) exists(value string) bool {
+	return c.count(value) > 0
+}
+
+// remove deletes one entry of `value` from the filter, and returns false if
+// there was none. Deleting an item that was never added may remove another
+// item sharing the same fingerprint.
+func (c *Cuckoo) remove(value string) bool {
+	fp, i1, i2 := c.locate(value)
+	for _, i := range []uint64{i1, i2} {
+		bucket := c.bucket(i)
+		for j := range bucket {
+			if bucket[j] == fp {
+				bucket[j] = 0
+				c.items--
+				c.deletes++
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DeepCopy creates a deep copy of the Cuckoo struct
+func (c *Cuckoo) DeepCopy() interface{} {
+	opts := *c.opts
+	slots := make([]uint16, len(c.slots))
+	copy(slots, c.slots)
+
+	return &Cuckoo{
+		opts:       &opts,
+		numBuckets: c.numBuckets,
+		slots:      slots,
+		items:      c.items,
+		deletes:    c.deletes,
+	}
+}
+
+// evalCFRESERVE evaluates the CF.RESERVE command responsible for creating an
+// empty cuckoo filter able to hold the given capacity.
+func evalCFRESERVE(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("CF.RESERVE")
+	}
+
+	opts, err := newCuckooOpts(args[1:], false)
+	if err != nil {
+		return probabilisticErr("CF.RESERVE", err)
+	}
+
+	if store.Get(args[0]) != nil {
+		return probabilisticErr("CF.RESERVE", errCuckooKeyExists)
+	}
+	store.Put(args[0], store.NewObj(newCuckooFilter(opts), -1, object.ObjTypeCuckoo, object.ObjEncodingCF))
+
+	return clientio.RespOK
+}
+
+// evalCFADD evaluates the CF.ADD command responsible for adding an item to a
+// cuckoo filter. If the filter does not exist, it will create a new one with
+// default parameters. The same item can be added multiple times.
+func evalCFADD(args []string, store *dstore.Store) []byte {
+	return cuckooAdd("CF.ADD", args, store, false)
+}
+
+// evalCFADDNX evaluates the CF.ADDNX command, which adds an item to a cuckoo
+// filter only if it does not exist in it yet. Returns 0 if the item may
+// already exist and 1 if it was added.
+func evalCFADDNX(args []string, store *dstore.Store) []byte {
+	return cuckooAdd("CF.ADDNX", args, store, true)
+}
+
+func cuckooAdd(name string, args []string, store *dstore.Store, nx bool) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity(name)
+	}
+	if args[1] == utils.EmptyStr {
+		return probabilisticErr(name, errEmptyValue)
+	}
+
+	opts, _ := newCuckooOpts(nil, true)
+	cf, err := getOrCreateCuckooFilter(args[0], opts, store)
+	if err != nil {
+		return probabilisticErr(name, err)
+	}
+
+	if nx && cf.exists(args[1]) {
+		return clientio.RespZero
+	}
+	if err := cf.add(args[1]); err != nil {
+		return probabilisticErr(name, err)
+	}
+
+	return clientio.RespOne
+}
+
+// evalCFEXISTS evaluates the CF.EXISTS command responsible for checking
+// existence of an item in a cuckoo filter. Returns 0 if the item surely does
+// not exist, or the filter does not exist, and 1 if it may exist.
+func evalCFEXISTS(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("CF.EXISTS")
+	}
+
+	cf, err := getOrCreateCuckooFilter(args[0], nil, store)
+	if errors.Is(err, errCuckooInvalidKey) {
+		return clientio.RespZero
+	}
+	if err != nil {
+		return probabilisticErr("CF.EXISTS", err)
+	}
+
+	if cf.exists(args[1]) {
+		return clientio.RespOne
+	}
+	return clientio.RespZero
+}
+
+// evalCFDEL evaluates the CF.DEL command responsible for deleting one
+// occurrence of an item from a cuckoo filter. Returns 1 if the item was
+// deleted and 0 if it was not found.
+func evalCFDEL(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("CF.DEL")
+	}
+
+	cf, err := getOrCreateCuckooFilter(args[0], nil, store)
+	if err != nil {
+		return probabilisticErr("CF.DEL", err)
+	}
+
+	if cf.remove(args[1]) {
+		return clientio.RespOne
+	}
+	return clientio.RespZero
+}
+
+// evalCFCOUNT evaluates the CF.COUNT command, which returns the number of
+// times an item may have been added to a cuckoo filter.
+func evalCFCOUNT(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("CF.COUNT")
+	}
+
+	cf, err := getOrCreateCuckooFilter(args[0], nil, store)
+	if errors.Is(err, errCuckooInvalidKey) {
+		return clientio.RespZero
+	}
+	if err != nil {
+		return probabilisticErr("CF.COUNT", err)
+	}
+
+	return clientio.Encode(cf.count(args[1]), false)
+}
+
+// getOrCreateCuckooFilter attempts to fetch an existing cuckoo filter from
+// the kv store. If it does not exist, it tries to create one with
+// given `opts` and returns it.
+func getOrCreateCuckooFilter(key string, opts *CuckooOpts, store *dstore.Store) (*Cuckoo, error) {
+	obj := store.Get(key)
+
+	// If we don't have a filter yet and `opts` are provided, create one.
+	if obj == nil && opts != nil {
+		obj = store.NewObj(newCuckooFilter(opts), -1, object.ObjTypeCuckoo, object.ObjEncodingCF)
+		store.Put(key, obj)
+	}
+
+	// If no `opts` are provided for filter creation, return err
+	if obj == nil {
+		return nil, errCuckooInvalidKey
+	}
+
+	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeCuckoo); err != nil {
+		return nil, errWrongType
+	}
+
+	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingCF); err != nil {
+		return nil, err
+	}
+
+	cf, ok := obj.Value.(*Cuckoo)
+	if !ok {
+		return nil, errWrongType
+	}
+	return cf, nil
+}
@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestCuckooFilter(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	// CF.RESERVE
+	assert.Equal(t, "-ERR wrong number of arguments for 'cf.reserve' command\r\n", string(evalCFRESERVE([]string{"cf"}, store)))
+	assert.Equal(t, string(clientio.RespOK), string(evalCFRESERVE([]string{"cf", "100", "BUCKETSIZE", "4", "MAXITERATIONS", "50"}, store)))
+	assert.Equal(t, "-ERR item exists for 'CF.RESERVE' command\r\n", string(evalCFRESERVE([]string{"cf", "100"}, store)))
+
+	// CF.ADD, CF.EXISTS and CF.COUNT
+	assert.Equal(t, string(clientio.RespZero), string(evalCFEXISTS([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespOne), string(evalCFADD([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespOne), string(evalCFADD([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespOne), string(evalCFEXISTS([]string{"cf", "hello"}, store)))
+	assert.Equal(t, ":2\r\n", string(evalCFCOUNT([]string{"cf", "hello"}, store)))
+
+	// CF.ADDNX does not add existing items
+	assert.Equal(t, string(clientio.RespZero), string(evalCFADDNX([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespOne), string(evalCFADDNX([]string{"cf", "world"}, store)))
+	assert.Equal(t, ":1\r\n", string(evalCFCOUNT([]string{"cf", "world"}, store)))
+
+	// CF.DEL removes a single occurrence
+	assert.Equal(t, string(clientio.RespOne), string(evalCFDEL([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespOne), string(evalCFEXISTS([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespOne), string(evalCFDEL([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespZero), string(evalCFEXISTS([]string{"cf", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespZero), string(evalCFDEL([]string{"cf", "hello"}, store)))
+
+	// CF.ADD creates a filter with default parameters, the other commands don't
+	assert.Equal(t, string(clientio.RespZero), string(evalCFEXISTS([]string{"cf1", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespZero), string(evalCFCOUNT([]string{"cf1", "hello"}, store)))
+	assert.Equal(t, "-ERR invalid key: no cuckoo filter found for 'CF.DEL' command\r\n", string(evalCFDEL([]string{"cf1", "hello"}, store)))
+	assert.Equal(t, string(clientio.RespOne), string(evalCFADD([]string{"cf1", "hello"}, store)))
+	assert.Equal(t, defaultCuckooCapacity, store.Get("cf1").Value.(*Cuckoo).opts.capacity)
+
+	// Commands against a key holding the wrong kind of value
+	store.Put("str", store.NewObj("value", -1, 0, 0))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", string(evalCFADD([]string{"str", "hello"}, store)))
+}
+
+func TestCuckooOpts(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+		err  error
+		opts *CuckooOpts
+	}{
+		{"capacity only", []string{"10"}, nil, &CuckooOpts{10, defaultCuckooBucketSize, defaultCuckooMaxIterations}},
+		{"all options", []string{"10", "bucketsize", "4", "MAXITERATIONS", "5"}, nil, &CuckooOpts{10, 4, 5}},
+		{"invalid capacity", []string{"abc"}, errInvalidCapacityType, nil},
+		{"zero capacity", []string{"0"}, errInvalidCapacity, nil},
+		{"invalid bucket size", []string{"10", "BUCKETSIZE", "0"}, errInvalidBucketSize, nil},
+		{"bucket size too large", []string{"10", "BUCKETSIZE", "256"}, errInvalidBucketSize, nil},
+		{"invalid max iterations", []string{"10", "MAXITERATIONS", "x"}, errInvalidMaxIterations, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := newCuckooOpts(tc.args, false)
+			assert.Assert(t, errors.Is(err, tc.err))
+			if tc.opts == nil {
+				assert.Assert(t, opts == nil)
+			} else {
+				assert.Equal(t, *tc.opts, *opts)
+			}
+		})
+	}
+
+	_, err := newCuckooOpts([]string{"10", "BUCKETSIZE"}, false)
+	assert.ErrorContains(t, err, "syntax error")
+	_, err = newCuckooOpts([]string{"10", "UNKNOWN", "1"}, false)
+	assert.ErrorContains(t, err, "syntax error")
+}
+
+func TestCuckooFilterFull(t *testing.T) {
+	opts, err := newCuckooOpts([]string{"8", "BUCKETSIZE", "2", "MAXITERATIONS", "10"}, false)
+	assert.NilError(t, err)
+	cf := newCuckooFilter(opts)
+	assert.Equal(t, uint64(4), cf.numBuckets)
+
+	// Fill the filter till it rejects an item, all the accepted ones must remain
+	added := make([]string, 0, len(cf.slots))
+	for i := 0; ; i++ {
+		item := fmt.Sprintf("item-%d", i)
+		if err := cf.add(item); err != nil {
+			assert.Assert(t, errors.Is(err, errCuckooFull))
+			break
+		}
+		added = append(added, item)
+	}
+	assert.Assert(t, len(added) <= len(cf.slots))
+	assert.Equal(t, uint64(len(added)), cf.items)
+
+	for _, item := range added {
+		assert.Assert(t, cf.exists(item), "%s was lost", item)
+	}
+	for _, item := range added {
+		assert.Assert(t, cf.remove(item))
+	}
+	assert.Equal(t, uint64(0), cf.items)
+	assert.DeepEqual(t, make([]uint16, len(cf.slots)), cf.slots)
+}
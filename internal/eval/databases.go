@@ -0,0 +1,110 @@
+package eval
+
+import (
+	"strconv"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/comm"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// ParseDBIndex returns the index of the logical database named by arg, which
+// must be below config.DiceConfig.Server.Databases.
+func ParseDBIndex(arg string) (int, error) {
+	db, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, diceerrors.ErrIntegerOutOfRange
+	}
+	if db < 0 || db >= max(config.DiceConfig.Server.Databases, 1) {
+		return 0, diceerrors.ErrDBIndexOutOfRange
+	}
+	return db, nil
+}
+
+// EvalSELECT switches the logical database the commands of the client run on.
+// The index is only checked if there is no client to switch, e.g. over HTTP.
+func EvalSELECT(args []string, client *comm.Client) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("SELECT")
+	}
+	db, err := ParseDBIndex(args[0])
+	if err != nil {
+		return clientio.Encode(err, false)
+	}
+	if client != nil {
+		client.DB = db
+	}
+	return clientio.RespOK
+}
+
+func evalSELECT(args []string, _ *dstore.Store) []byte {
+	return EvalSELECT(args, nil)
+}
+
+// databaseOf returns the store of the database named by arg, among the
+// databases store is one of.
+func databaseOf(arg string, store *dstore.Store) (int, *dstore.Store, error) {
+	db, err := ParseDBIndex(arg)
+	if err != nil {
+		return 0, nil, err
+	}
+	dbs := store.Databases()
+	if dbs == nil || dbs.Get(db) == nil {
+		return 0, nil, diceerrors.ErrDBIndexOutOfRange
+	}
+	return db, dbs.Get(db), nil
+}
+
+// evalSWAPDB swaps two logical databases, the clients connected to either
+// seeing the keys of the other right away. The keys of both are dumped again
+// by the next AOF rewrite, should one of them be the database 0.
+func evalSWAPDB(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("SWAPDB")
+	}
+	i, first, err := databaseOf(args[0], store)
+	if err != nil {
+		return clientio.Encode(err, false)
+	}
+	j, second, err := databaseOf(args[1], store)
+	if err != nil {
+		return clientio.Encode(err, false)
+	}
+
+	store.Databases().Swap(i, j)
+	first.MarkDirty(nil)
+	second.MarkDirty(nil)
+	return clientio.RespOK
+}
+
+// evalMOVE moves a key to another logical database, along with its expiry.
+// It returns 1 if the key was moved, 0 if it does not exist or the target
+// database already holds it.
+func evalMOVE(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("MOVE")
+	}
+	_, dst, err := databaseOf(args[1], store)
+	if err != nil {
+		return clientio.Encode(err, false)
+	}
+	if dst == store {
+		return clientio.Encode(diceerrors.ErrSameObject, false)
+	}
+
+	if !store.Move(args[0], dst) {
+		return clientio.RespZero
+	}
+	return clientio.RespOne
+}
+
+// aofStore returns the store of the database 0 of the databases store is one
+// of, the only database the AOF holds.
+func aofStore(store *dstore.Store) *dstore.Store {
+	if dbs := store.Databases(); dbs != nil {
+		return dbs.Get(0)
+	}
+	return store
+}
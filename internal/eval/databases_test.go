@@ -0,0 +1,74 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/comm"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestDatabases(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	dbs := dstore.NewDatabases(3, func() *dstore.Store { return dstore.NewStore(nil) })
+	db0, db1 := dbs.Get(0), dbs.Get(1)
+	exec := func(client *comm.Client, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: args[0], Args: args[1:]}, client, dbs.Get(client.DB), false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	// the databases hold keys of their own
+	client := comm.NewClient(0)
+	assert.Equal(t, exec(client, "MSET", "k", "v0"), string(clientio.RespOK))
+	assert.Equal(t, exec(client, "SELECT", "1"), string(clientio.RespOK))
+	assert.Equal(t, client.DB, 1)
+	assert.Equal(t, exec(client, "GETEX", "k"), string(clientio.RespNIL))
+	assert.Equal(t, exec(client, "MSET", "k", "v1"), string(clientio.RespOK))
+	assert.Equal(t, exec(client, "EXPIRE", "k", "10"), string(clientio.RespOne))
+
+	// MOVE keeps the expiry, and never overwrites a key
+	assert.Equal(t, exec(client, "MOVE", "k", "0"), string(clientio.RespZero))
+	assert.Equal(t, exec(client, "MOVE", "k", "2"), string(clientio.RespOne))
+	assert.Equal(t, exec(client, "EXISTS", "k"), string(clientio.RespZero))
+	assert.Equal(t, exec(client, "MOVE", "k", "2"), string(clientio.RespZero))
+	assert.Equal(t, exec(client, "SELECT", "2"), string(clientio.RespOK))
+	assert.Equal(t, exec(client, "GETEX", "k"), string(clientio.Encode("v1", false)))
+	assert.Equal(t, exec(client, "TTL", "k"), string(clientio.Encode(10, false)))
+	mockTime.SetTime(time.Unix(1011, 0))
+	assert.Equal(t, exec(client, "GETEX", "k"), string(clientio.RespNIL))
+
+	// SWAPDB swaps the stores, the clients seeing the keys of the other database
+	assert.Equal(t, exec(client, "SWAPDB", "0", "1"), string(clientio.RespOK))
+	assert.Assert(t, dbs.Get(0) == db1 && dbs.Get(1) == db0)
+	assert.Equal(t, exec(client, "SELECT", "1"), string(clientio.RespOK))
+	assert.Equal(t, exec(client, "GETEX", "k"), string(clientio.Encode("v0", false)))
+
+	tests := []struct {
+		args []string
+		want []byte
+	}{
+		{[]string{"SELECT", "one"}, clientio.Encode(diceerrors.ErrIntegerOutOfRange, false)},
+		{[]string{"SELECT", "16"}, clientio.Encode(diceerrors.ErrDBIndexOutOfRange, false)},
+		{[]string{"SELECT", "-1"}, clientio.Encode(diceerrors.ErrDBIndexOutOfRange, false)},
+		{[]string{"SWAPDB", "0", "3"}, clientio.Encode(diceerrors.ErrDBIndexOutOfRange, false)},
+		{[]string{"SWAPDB", "0"}, diceerrors.NewErrArity("SWAPDB")},
+		{[]string{"MOVE", "k", "1"}, clientio.Encode(diceerrors.ErrSameObject, false)},
+		{[]string{"MOVE", "k", "x"}, clientio.Encode(diceerrors.ErrIntegerOutOfRange, false)},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, exec(client, tt.args...), string(tt.want), tt.args)
+	}
+	assert.Equal(t, client.DB, 1)
+
+	// a store standing alone has no other database
+	assert.Equal(t, string(evalMOVE([]string{"k", "1"}, dstore.NewStore(nil))), string(clientio.Encode(diceerrors.ErrDBIndexOutOfRange, false)))
+}
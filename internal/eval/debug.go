@@ -0,0 +1,89 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// evalDEBUG is the container command of the administrative commands meant for
+// testing and for mitigating issues.
+// DEBUG CONVERT key encoding converts the value of key to encoding, see
+// evalDEBUGConvert.
+func evalDEBUG(args []string, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("DEBUG")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "CONVERT":
+		if len(args) != 3 {
+			return diceerrors.NewErrArity("DEBUG|CONVERT")
+		}
+		return evalDEBUGConvert(args[1], args[2], store)
+	case Help:
+		return commandHelp("DEBUG")
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try DEBUG HELP.", args[0])
+	}
+}
+
+// evalDEBUGConvert converts the value of key to the given encoding in place,
+// keeping its expiry. The strings convert between the int, embstr and raw
+// encodings and the byte array of the bitmaps. The value is validated once
+// converted, and left as it was if the conversion broke it.
+//
+// Usage: DEBUG CONVERT key int|embstr|raw|bytearray
+func evalDEBUGConvert(key, encoding string, store *dstore.Store) []byte {
+	obj := store.Get(key)
+	if obj == nil {
+		return diceerrors.NewErrWithMessage(diceerrors.NoKeyErr)
+	}
+	if !isStringObj(obj) {
+		return diceerrors.NewErrWithFormattedMessage("cannot convert a %s to encoding '%s'", typeName(obj), strings.ToLower(encoding))
+	}
+
+	var s string
+	switch v := obj.Value.(type) {
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case *ByteArray:
+		s = string(v.data)
+	}
+
+	converted := &object.Obj{}
+	switch strings.ToLower(encoding) {
+	case "int":
+		v, err := strconv.ParseInt(s, 10, 64)
+		// the integer must be formatted back to the same string
+		if err != nil || strconv.FormatInt(v, 10) != s {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		converted.TypeEncoding, converted.Value = object.ObjTypeInt|object.ObjEncodingInt, v
+	case "embstr":
+		if len(s) > maxEmbStrLen {
+			return diceerrors.NewErrWithFormattedMessage("the embstr encoding holds strings of %d bytes at most", maxEmbStrLen)
+		}
+		converted.TypeEncoding, converted.Value = object.ObjTypeString|object.ObjEncodingEmbStr, s
+	case "raw":
+		converted.TypeEncoding, converted.Value = object.ObjTypeString|object.ObjEncodingRaw, s
+	case "bytearray":
+		converted.TypeEncoding = object.ObjTypeByteArray | object.ObjEncodingByteArray
+		converted.Value = &ByteArray{data: []byte(s), Length: int64(len(s))}
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown encoding '%s'", encoding)
+	}
+
+	if err := validateObj(converted); err != nil {
+		return diceerrors.NewErrWithFormattedMessage("conversion of key %s failed: %v", key, err)
+	}
+	obj.TypeEncoding, obj.Value = converted.TypeEncoding, converted.Value
+
+	return clientio.RespOK
+}
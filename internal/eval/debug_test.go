@@ -0,0 +1,50 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestDEBUGConvert(t *testing.T) {
+	store := dstore.NewStore(nil)
+	convert := func(key, encoding string) string {
+		return string(evalDEBUG([]string{"CONVERT", key, encoding}, store))
+	}
+
+	evalSET([]string{"n", "42", Ex, "100"}, store)
+	assert.Equal(t, object.ObjTypeInt|object.ObjEncodingInt, store.Get("n").TypeEncoding)
+
+	assert.Equal(t, string(clientio.RespOK), convert("n", "embstr"))
+	assert.Equal(t, object.ObjTypeString|object.ObjEncodingEmbStr, store.Get("n").TypeEncoding)
+	assert.Equal(t, "42", store.Get("n").Value)
+	assert.Equal(t, string(clientio.RespOK), convert("n", "RAW"))
+	assert.Equal(t, object.ObjTypeString|object.ObjEncodingRaw, store.Get("n").TypeEncoding)
+	assert.Equal(t, string(clientio.RespOK), convert("n", "bytearray"))
+	assert.Equal(t, object.ObjTypeByteArray|object.ObjEncodingByteArray, store.Get("n").TypeEncoding)
+	assert.Equal(t, string(clientio.RespOK), convert("n", "int"))
+	assert.Equal(t, int64(42), store.Get("n").Value)
+
+	// the expiry is kept
+	_, ok := dstore.GetExpiry(store.Get("n"), store)
+	assert.Assert(t, ok)
+
+	evalSET([]string{"s", strings.Repeat("x", 45)}, store)
+	assert.Equal(t, "-ERR the embstr encoding holds strings of 44 bytes at most\r\n", convert("s", "embstr"))
+	assert.Equal(t, string(diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)), convert("s", "int"))
+	evalSET([]string{"f", "1.5"}, store)
+	assert.Equal(t, string(diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)), convert("f", "int"))
+	assert.Equal(t, "-ERR unknown encoding 'listpack'\r\n", convert("s", "listpack"))
+
+	evalSADD([]string{"set", "a"}, store)
+	assert.Equal(t, "-ERR cannot convert a set to encoding 'raw'\r\n", convert("set", "raw"))
+	assert.Equal(t, "-ERR no such key\r\n", convert("missing", "raw"))
+	assert.Equal(t, string(diceerrors.NewErrArity("DEBUG|CONVERT")), string(evalDEBUG([]string{"CONVERT", "s"}, store)))
+	assert.Equal(t, "-ERR unknown subcommand 'SLEEP'. Try DEBUG HELP.\r\n", string(evalDEBUG([]string{"SLEEP", "1"}, store)))
+}
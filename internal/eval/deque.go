@@ -2,8 +2,11 @@ package eval
 
 import (
 	"errors"
+	"math"
 	"strconv"
+	"strings"
 
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/dencoding"
 )
 
@@ -99,17 +102,52 @@ const (
 
 var _ DequeI = (*Deque)(nil)
 
+// ElementEqual reports whether two elements of a list are equal. The searches
+// in a Deque use the ElementEqual it was created with.
+type ElementEqual func(a, b string) bool
+
+// ExactEqual compares the elements byte for byte, it is the default ElementEqual.
+func ExactEqual(a, b string) bool {
+	return a == b
+}
+
+// CaseInsensitiveEqual compares the elements under Unicode case-folding.
+func CaseInsensitiveEqual(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// NumericEqual returns an ElementEqual for which two numbers are equal if they
+// differ by at most tolerance. Elements that are not both numbers are compared
+// byte for byte.
+func NumericEqual(tolerance float64) ElementEqual {
+	return func(a, b string) bool {
+		x, errA := strconv.ParseFloat(a, 64)
+		y, errB := strconv.ParseFloat(b, 64)
+		if errA != nil || errB != nil {
+			return a == b
+		}
+		return math.Abs(x-y) <= tolerance
+	}
+}
+
 type Deque struct {
 	Length  int64
 	list    *byteList
 	leftIdx int
+	equal   ElementEqual
 }
 
 func NewDeque() *Deque {
+	return NewDequeWithEqual(ExactEqual)
+}
+
+// NewDequeWithEqual returns an empty Deque whose searches compare the elements with equal.
+func NewDequeWithEqual(equal ElementEqual) *Deque {
 	return &Deque{
 		Length:  0,
 		list:    newByteList(minDequeNodeSize),
 		leftIdx: 0,
+		equal:   equal,
 	}
 }
 
@@ -137,6 +175,7 @@ func (q *Deque) LPush(x string) {
 		head.buf = head.buf[:cap(head.buf)]
 		q.leftIdx = len(head.buf) - entrySize
 		EncodeDeqEntryInPlace(x, head.buf[q.leftIdx:])
+		q.compressInterior()
 	}
 
 	q.Length++
@@ -156,6 +195,7 @@ func (q *Deque) RPush(x string) {
 		q.list.append(tail)
 		tail.buf = tail.buf[:entrySize]
 		EncodeDeqEntryInPlace(x, tail.buf[:entrySize])
+		q.compressInterior()
 	} else if cap(tail.buf)-len(tail.buf) < entrySize {
 		newBuf := make([]byte, len(tail.buf)+entrySize)
 		copy(newBuf, tail.buf)
@@ -182,6 +222,7 @@ func (q *Deque) LPop() (string, error) {
 	if q.leftIdx == len(head.buf) {
 		q.list.delete(head)
 		q.leftIdx = 0
+		q.compressInterior()
 	}
 	q.Length--
 
@@ -209,12 +250,121 @@ func (q *Deque) RPop() (string, error) {
 		if q.list.tail == nil {
 			q.leftIdx = 0
 		}
+		q.compressInterior()
 	}
 	q.Length--
 
 	return x, nil
 }
 
+// compressInterior compresses the nodes of the deque but the
+// config.DiceConfig.Server.ListCompressDepth ones at both ends, see
+// byteList.compressInterior.
+func (q *Deque) compressInterior() {
+	q.list.compressInterior(config.Current().Server.ListCompressDepth)
+}
+
+// Iterate calls fn for every element of the deque from head to tail.
+// The iteration stops as soon as fn returns false.
+func (q *Deque) Iterate(fn func(x string) bool) {
+	idx := q.leftIdx
+	for node := q.list.head; node != nil; node = node.next {
+		buf := node.plain()
+		for idx < len(buf) {
+			x, entryLen := DecodeDeqEntry(buf[idx:])
+			if !fn(x) {
+				return
+			}
+			idx += entryLen
+		}
+		idx = 0
+	}
+}
+
+// Index returns the position of the first element equal to x from head to
+// tail, or -1 if there is none.
+func (q *Deque) Index(x string) int64 {
+	var pos, idx int64 = -1, 0
+	q.Iterate(func(e string) bool {
+		if q.equal(e, x) {
+			pos = idx
+			return false
+		}
+		idx++
+		return true
+	})
+	return pos
+}
+
+// Insert inserts x before or after the first element equal to pivot from head
+// to tail, and returns false if there is none.
+func (q *Deque) Insert(pivot, x string, before bool) bool {
+	elements := make([]string, 0, q.Length+1)
+	found := false
+	q.Iterate(func(e string) bool {
+		if !found && q.equal(e, pivot) {
+			found = true
+			if before {
+				elements = append(elements, x, e)
+			} else {
+				elements = append(elements, e, x)
+			}
+			return true
+		}
+		elements = append(elements, e)
+		return true
+	})
+	if !found {
+		return false
+	}
+
+	// the elements are encoded back to back, hence the deque is rebuilt
+	q.list = newByteList(minDequeNodeSize)
+	q.leftIdx = 0
+	q.Length = 0
+	for _, e := range elements {
+		q.RPush(e)
+	}
+	return true
+}
+
+// Positions returns the positions of the elements equal to x, starting from
+// the rank-th match from head to tail, or from tail to head if rank is
+// negative. At most count positions are returned, all of them if count is 0,
+// and at most maxLen elements are compared, all of them if maxLen is 0.
+func (q *Deque) Positions(x string, rank, count, maxLen int64) []int64 {
+	elements := make([]string, 0, q.Length)
+	q.Iterate(func(e string) bool {
+		elements = append(elements, e)
+		return true
+	})
+
+	var idx, step int64 = 0, 1
+	if rank < 0 {
+		idx, step, rank = q.Length-1, -1, -rank
+	}
+
+	positions := make([]int64, 0)
+	for compared := int64(0); idx >= 0 && idx < q.Length; idx += step {
+		if maxLen > 0 && compared == maxLen {
+			break
+		}
+		compared++
+		if !q.equal(elements[idx], x) {
+			continue
+		}
+		if rank > 1 {
+			rank--
+			continue
+		}
+		positions = append(positions, idx)
+		if count > 0 && int64(len(positions)) == count {
+			break
+		}
+	}
+	return positions
+}
+
 // *************************** deque entry encode/decode ***************************
 
 // EncodeDeqEntry encodes `x` into an entry of Deque. An entry will be encoded as [enc + data + backlen].
@@ -73,6 +73,42 @@ func TestDeqEncodeEntryString(t *testing.T) {
 	}
 }
 
+func TestDequeIterate(t *testing.T) {
+	deqTestInit()
+	deq := eval.NewDeque()
+	var expected []string
+	for i := 0; i < 500; i++ {
+		x := deqRandStr(deqRandGenerator.Intn(100) + 1)
+		if i%2 == 0 {
+			deq.RPush(x)
+			expected = append(expected, x)
+		} else {
+			deq.LPush(x)
+			expected = append([]string{x}, expected...)
+		}
+	}
+	// pop a few elements so that the head node is partially consumed
+	for i := 0; i < 3; i++ {
+		_, _ = deq.LPop()
+		_, _ = deq.RPop()
+	}
+	expected = expected[3 : len(expected)-3]
+
+	var actual []string
+	deq.Iterate(func(x string) bool {
+		actual = append(actual, x)
+		return true
+	})
+	assert.DeepEqual(t, expected, actual)
+
+	count := 0
+	deq.Iterate(func(x string) bool {
+		count++
+		return count < 10
+	})
+	assert.Equal(t, 10, count)
+}
+
 func dequeRPushIntStrMany(howmany int, deq eval.DequeI) {
 	for i := 0; i < howmany; i++ {
 		deq.RPush(strconv.FormatInt(int64(i), 10))
@@ -138,3 +174,76 @@ func BenchmarkDequeLPush2000(b *testing.B) {
 		dequeLPushIntStrMany(2000, eval.NewDeque())
 	}
 }
+
+func TestDequeIndex(t *testing.T) {
+	deq := eval.NewDeque()
+	for _, x := range []string{"a", "B", "10", "b", "10.05", "a"} {
+		deq.RPush(x)
+	}
+	assert.Equal(t, int64(0), deq.Index("a"))
+	assert.Equal(t, int64(3), deq.Index("b"))
+	assert.Equal(t, int64(2), deq.Index("10"))
+	assert.Equal(t, int64(-1), deq.Index("10.0"))
+	assert.Equal(t, int64(-1), deq.Index("c"))
+
+	deq = eval.NewDequeWithEqual(eval.CaseInsensitiveEqual)
+	deq.RPush("a")
+	deq.RPush("B")
+	assert.Equal(t, int64(1), deq.Index("b"))
+
+	deq = eval.NewDequeWithEqual(eval.NumericEqual(0.1))
+	for _, x := range []string{"x", "9.8", "10.05"} {
+		deq.RPush(x)
+	}
+	assert.Equal(t, int64(2), deq.Index("10"))
+	assert.Equal(t, int64(0), deq.Index("x"))
+	assert.Equal(t, int64(-1), deq.Index("X"))
+}
+
+func TestDequeInsert(t *testing.T) {
+	deqTestInit()
+	deq := eval.NewDeque()
+	var expected []string
+	for i := 0; i < 200; i++ {
+		x := deqRandStr(deqRandGenerator.Intn(100) + 1)
+		deq.RPush(x)
+		expected = append(expected, x)
+	}
+
+	assert.Assert(t, deq.Insert(expected[100], "before", true))
+	assert.Assert(t, deq.Insert(expected[0], "after", false))
+	assert.Assert(t, !deq.Insert("missing pivot", "x", true))
+	expected = append(expected[:100], append([]string{"before"}, expected[100:]...)...)
+	expected = append(expected[:1], append([]string{"after"}, expected[1:]...)...)
+
+	var actual []string
+	deq.Iterate(func(x string) bool {
+		actual = append(actual, x)
+		return true
+	})
+	assert.DeepEqual(t, expected, actual)
+	assert.Equal(t, int64(len(expected)), deq.Length)
+
+	// the deque is still usable from both ends
+	deq.LPush("head")
+	x, _ := deq.LPop()
+	assert.Equal(t, "head", x)
+	x, _ = deq.RPop()
+	assert.Equal(t, expected[len(expected)-1], x)
+}
+
+func TestDequePositions(t *testing.T) {
+	deq := eval.NewDeque()
+	for _, x := range []string{"a", "b", "c", "1", "2", "3", "c", "c"} {
+		deq.RPush(x)
+	}
+	assert.DeepEqual(t, []int64{2}, deq.Positions("c", 1, 1, 0))
+	assert.DeepEqual(t, []int64{2, 6, 7}, deq.Positions("c", 1, 0, 0))
+	assert.DeepEqual(t, []int64{6, 7}, deq.Positions("c", 2, 0, 0))
+	assert.DeepEqual(t, []int64{7, 6}, deq.Positions("c", -1, 2, 0))
+	assert.DeepEqual(t, []int64{2}, deq.Positions("c", -3, 0, 0))
+	assert.DeepEqual(t, []int64{}, deq.Positions("c", 4, 0, 0))
+	assert.DeepEqual(t, []int64{2}, deq.Positions("c", 1, 0, 3))
+	assert.DeepEqual(t, []int64{}, deq.Positions("c", 1, 0, 2))
+	assert.DeepEqual(t, []int64{}, deq.Positions("x", 1, 0, 0))
+}
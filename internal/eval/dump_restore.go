@@ -58,7 +58,7 @@ func evalRestore(args []string, store *dstore.Store) []byte {
 	if(ttl>0){
 		store.Put(key, newobj, dstore.WithKeepTTL(keepttl))
 	}else{
-		store.Put(key,obj)
+		store.Put(key,obj, dstore.WithRelocation(true))
 	}
 	
 	return clientio.RespOK
@@ -3,15 +3,12 @@ package eval
 import (
 	"bytes"
 
-	"crypto/rand"
-
 	"errors"
 	"fmt"
 
 	"log/slog"
 
 	"math"
-	"math/big"
 	"math/bits"
 	"regexp"
 	"sort"
@@ -21,8 +18,6 @@ import (
 	"unicode"
 	"unsafe"
 
-	"github.com/google/btree"
-
 	"github.com/dicedb/dice/internal/object"
 	"github.com/rs/xid"
 
@@ -31,12 +26,16 @@ import (
 	"github.com/axiomhq/hyperloglog"
 	"github.com/bytedance/sonic"
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/auth"
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/comm"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/metrics"
 	"github.com/dicedb/dice/internal/querymanager"
+	"github.com/dicedb/dice/internal/regex"
 	"github.com/dicedb/dice/internal/server/utils"
 	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/watchdog"
 	"github.com/ohler55/ojg/jp"
 )
 
@@ -54,8 +53,21 @@ var (
 )
 
 type EvalResponse struct {
-	Result interface{} // Result of the Store operation, for now the type is set to []byte, but this can change in the future.
-	Error  error
+	Result  interface{} // Result of the Store operation, for now the type is set to []byte, but this can change in the future.
+	Error   error
+	Blocked *Blocked // Blocked is set if the command cannot be served yet, Result being the reply once it times out.
+}
+
+// Err returns the error of the response, whether it is set as Error or encoded
+// as a RESP error in Result.
+func (r *EvalResponse) Err() error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if b, ok := r.Result.([]byte); ok && len(b) > 0 && b[0] == '-' {
+		return errors.New(strings.TrimSpace(string(b[1:])))
+	}
+	return nil
 }
 
 type jsonOperation string
@@ -107,11 +119,14 @@ func evalECHO(args []string, store *dstore.Store) []byte {
 func EvalAUTH(args []string, c *comm.Client) []byte {
 	var err error
 
-	if config.DiceConfig.Auth.Password == "" {
+	// the users set up by ACL SETUSER may authenticate without a password
+	// configured for the default user, as may the bearer tokens
+	if config.DiceConfig.Auth.Password == "" && len(args) == 1 && !auth.IsToken(args[0]) {
 		return diceerrors.NewErrWithMessage("AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
 	}
 
-	username := config.DiceConfig.Auth.UserName
+	// the default user, or the user named by a bearer token
+	username := ""
 	var password string
 
 	if len(args) == 1 {
@@ -193,13 +208,7 @@ func evalGETDEL(args []string, store *dstore.Store) []byte {
 		return clientio.RespNIL
 	}
 
-	// If the object exists, check if it is a Set object.
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err == nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	// If the object exists, check if it is a JSON object.
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeJSON); err == nil {
+	if !isStringObj(obj) {
 		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 	}
 
@@ -1252,24 +1261,19 @@ func evalJSONSET(args []string, store *dstore.Store) []byte {
 	key := args[0]
 	path := args[1]
 	jsonStr := args[2]
+	var nx, xx bool
 	for i := 3; i < len(args); i++ {
 		switch strings.ToUpper(args[i]) {
 		case NX:
 			if i != len(args)-1 {
 				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
 			}
-			obj := store.Get(key)
-			if obj != nil {
-				return clientio.RespNIL
-			}
+			nx = true
 		case XX:
 			if i != len(args)-1 {
 				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
 			}
-			obj := store.Get(key)
-			if obj == nil {
-				return clientio.RespNIL
-			}
+			xx = true
 
 		default:
 			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
@@ -1287,6 +1291,9 @@ func evalJSONSET(args []string, store *dstore.Store) []byte {
 	var rootData interface{}
 
 	if obj == nil {
+		if xx {
+			return clientio.RespNIL
+		}
 		// If the key doesn't exist, create a new object
 		if path != defaultRootPath {
 			rootData = make(map[string]interface{})
@@ -1304,6 +1311,9 @@ func evalJSONSET(args []string, store *dstore.Store) []byte {
 			return clientio.Encode(err, false)
 		}
 		rootData = obj.Value
+		if nx && path == defaultRootPath {
+			return clientio.RespNIL
+		}
 	}
 
 	// If path is not root, use JSONPath to set the value
@@ -1313,6 +1323,14 @@ func evalJSONSET(args []string, store *dstore.Store) []byte {
 			return diceerrors.NewErrWithMessage("invalid JSONPath")
 		}
 
+		// NX and XX apply to the path: NX sets only missing values, XX only existing ones
+		if obj != nil && (nx || xx) {
+			exists := len(expr.Get(rootData)) > 0
+			if (nx && exists) || (xx && !exists) {
+				return clientio.RespNIL
+			}
+		}
+
 		err = expr.Set(rootData, jsonValue)
 		if err != nil {
 			return diceerrors.NewErrWithMessage("failed to set value")
@@ -1675,7 +1693,7 @@ func evalEXPIRE(args []string, store *dstore.Store) []byte {
 	if obj == nil {
 		return clientio.RespZero
 	}
-	isExpirySet, err2 := evaluateAndSetExpiry(args[2:], utils.AddSecondsToUnixEpoch(exDurationSec), key, store)
+	isExpirySet, err2 := evaluateAndSetExpiry(args[2:], utils.AddSecondsToUnixEpoch(exDurationSec)*1000, key, store)
 
 	if isExpirySet {
 		return clientio.RespOne
@@ -1733,7 +1751,62 @@ func evalEXPIREAT(args []string, store *dstore.Store) []byte {
 		return clientio.Encode(errors.New(diceerrors.InvalidIntErr), false)
 	}
 
-	isExpirySet, err2 := evaluateAndSetExpiry(args[2:], exUnixTimeSec, key, store)
+	isExpirySet, err2 := evaluateAndSetExpiry(args[2:], exUnixTimeSec*1000, key, store)
+	if isExpirySet {
+		return clientio.RespOne
+	} else if err2 != nil {
+		return err2
+	}
+	return clientio.RespZero
+}
+
+// evalPEXPIRE sets an expiry time(in millisecs) on the specified key in args,
+// like EXPIRE does, and takes the same NX, XX, GT and LT options.
+// Returns response.RespOne if expiry was set on the key successfully.
+func evalPEXPIRE(args []string, store *dstore.Store) []byte {
+	if len(args) <= 1 {
+		return diceerrors.NewErrArity("PEXPIRE")
+	}
+
+	var key = args[0]
+	exDurationMs, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	if exDurationMs < 0 || exDurationMs > math.MaxInt64-now {
+		return diceerrors.NewErrExpireTime("PEXPIRE")
+	}
+
+	// 0 if the timeout was not set. e.g. key doesn't exist, or operation skipped due to the provided arguments
+	isExpirySet, err2 := evaluateAndSetExpiry(args[2:], now+exDurationMs, key, store)
+	if isExpirySet {
+		return clientio.RespOne
+	} else if err2 != nil {
+		return err2
+	}
+	return clientio.RespZero
+}
+
+// evalPEXPIREAT sets an expiry time(in unix-time-millisecs) on the specified
+// key in args, like EXPIREAT does.
+// Returns response.RespOne if expiry was set on the key successfully.
+func evalPEXPIREAT(args []string, store *dstore.Store) []byte {
+	if len(args) <= 1 {
+		return diceerrors.NewErrArity("PEXPIREAT")
+	}
+
+	var key = args[0]
+	exUnixTimeMs, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	if exUnixTimeMs < 0 {
+		return diceerrors.NewErrExpireTime("PEXPIREAT")
+	}
+
+	isExpirySet, err2 := evaluateAndSetExpiry(args[2:], exUnixTimeMs, key, store)
 	if isExpirySet {
 		return clientio.RespOne
 	} else if err2 != nil {
@@ -1742,16 +1815,38 @@ func evalEXPIREAT(args []string, store *dstore.Store) []byte {
 	return clientio.RespZero
 }
 
+// evalPEXPIRETIME returns the absolute Unix timestamp in milliseconds at which
+// the given key will expire, like EXPIRETIME does in seconds.
+// Returns -1 if the key exists but has no associated expiration time.
+// Returns -2 if the key does not exist.
+func evalPEXPIRETIME(args []string, store *dstore.Store) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("PEXPIRETIME")
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.RespMinusTwo
+	}
+
+	exTimeMili, ok := dstore.GetExpiry(obj, store)
+	if !ok {
+		return clientio.RespMinusOne
+	}
+
+	return clientio.Encode(int64(exTimeMili), false)
+}
+
 // NX: Set the expiration only if the key does not already have an expiration time.
 // XX: Set the expiration only if the key already has an expiration time.
 // GT: Set the expiration only if the new expiration time is greater than the current one.
 // LT: Set the expiration only if the new expiration time is less than the current one.
+// The new expiry is given in unix-time-milliseconds.
 // Returns Boolean True and error nil if expiry was set on the key successfully.
 // Returns Boolean False and error nil if conditions didn't met.
 // Returns Boolean False and error not-nil if invalid combination of subCommands or if subCommand is invalid
-func evaluateAndSetExpiry(subCommands []string, newExpiry int64, key string,
+func evaluateAndSetExpiry(subCommands []string, newExpInMilli int64, key string,
 	store *dstore.Store) (shouldSetExpiry bool, err []byte) {
-	var newExpInMilli = newExpiry * 1000
 	var prevExpiry *uint64 = nil
 	var nxCmd, xxCmd, gtCmd, ltCmd bool
 
@@ -1763,7 +1858,7 @@ func evaluateAndSetExpiry(subCommands []string, newExpiry int64, key string,
 	shouldSetExpiry = true
 	// if no condition exists
 	if len(subCommands) == 0 {
-		store.SetUnixTimeExpiry(obj, newExpiry)
+		store.SetUnixTimeMsExpiry(obj, uint64(newExpInMilli))
 		return shouldSetExpiry, nil
 	}
 
@@ -1811,7 +1906,7 @@ func evaluateAndSetExpiry(subCommands []string, newExpiry int64, key string,
 	}
 
 	if shouldSetExpiry {
-		store.SetUnixTimeExpiry(obj, newExpiry)
+		store.SetUnixTimeMsExpiry(obj, uint64(newExpInMilli))
 	}
 	return shouldSetExpiry, nil
 }
@@ -1821,15 +1916,17 @@ func evalHELLO(args []string, store *dstore.Store) []byte {
 		return diceerrors.NewErrArity("HELLO")
 	}
 
-	var resp []interface{}
-	resp = append(resp,
-		"proto", 2,
-		"id", serverID,
-		"mode", "standalone",
-		"role", "master",
-		"modules", []interface{}{})
+	return cachedReply("HELLO", func() []byte {
+		var resp []interface{}
+		resp = append(resp,
+			"proto", 2,
+			"id", serverID,
+			"mode", "standalone",
+			"role", "master",
+			"modules", []interface{}{})
 
-	return clientio.Encode(resp, false)
+		return clientio.Encode(resp, false)
+	})
 }
 
 // evalINCR increments the value of the specified key in args by 1,
@@ -1897,6 +1994,10 @@ func evalDECRBY(args []string, store *dstore.Store) []byte {
 	if err != nil {
 		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
 	}
+	// the opposite of the smallest decrement does not fit in an int64
+	if decrementAmount == math.MinInt64 {
+		return diceerrors.NewErrWithMessage(diceerrors.IncrDecrOverflowErr)
+	}
 	return incrDecrCmd(args, -decrementAmount, store)
 }
 
@@ -1927,20 +2028,30 @@ func incrDecrCmd(args []string, incr int64, store *dstore.Store) []byte {
 		store.Put(key, obj)
 	}
 
-	// If the object exists, check if it is a set object.
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err == nil {
+	var i int64
+	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
+	case object.ObjTypeInt:
+		if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingInt); err != nil {
+			return diceerrors.NewErrWithFormattedMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		i, _ = obj.Value.(int64)
+	case object.ObjTypeString:
+		// a string holding an integer, e.g. after APPEND, is incremented as well
+		// and is stored as an integer from then on
+		str, ok := obj.Value.(string)
+		if !ok {
+			return diceerrors.NewErrWithFormattedMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil || strconv.FormatInt(v, 10) != str {
+			return diceerrors.NewErrWithFormattedMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		i = v
+		obj.TypeEncoding = object.ObjTypeInt | object.ObjEncodingInt
+	default:
 		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 	}
 
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeInt); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.IntOrOutOfRangeErr)
-	}
-
-	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingInt); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.IntOrOutOfRangeErr)
-	}
-
-	i, _ := obj.Value.(int64)
 	// check overflow
 	if (incr < 0 && i < 0 && incr < (math.MinInt64-i)) ||
 		(incr > 0 && i > 0 && incr > (math.MaxInt64-i)) {
@@ -2015,19 +2126,201 @@ func floatValue(value interface{}) (float64, error) {
 func evalINFO(args []string, store *dstore.Store) []byte {
 	var info []byte
 	buf := bytes.NewBuffer(info)
+	stats := store.CompressionStats()
+	buf.WriteString("# Memory\r\n")
+	fmt.Fprintf(buf, "compressed_keys:%d\r\n", stats.Keys)
+	fmt.Fprintf(buf, "compressed_raw_bytes:%d\r\n", stats.RawBytes)
+	fmt.Fprintf(buf, "compressed_bytes:%d\r\n", stats.CompressedBytes)
+	fmt.Fprintf(buf, "compression_ratio:%.2f\r\n", stats.Ratio())
+	fmt.Fprintf(buf, "used_memory:%d\r\n", dstore.UsedMemory())
+	fmt.Fprintf(buf, "maxmemory:%d\r\n", config.Current().Server.MaxMemory)
+	fmt.Fprintf(buf, "maxmemory_policy:%s\r\n", config.Current().Server.EvictionPolicy)
+	fmt.Fprintf(buf, "evicted_keys:%d\r\n", dstore.EvictedKeys())
+	buf.WriteString("\r\n")
+	if tierStats, ok := store.TierStats(); ok {
+		buf.WriteString("# Tiering\r\n")
+		fmt.Fprintf(buf, "cold_keys:%d\r\n", tierStats.ColdKeys)
+		fmt.Fprintf(buf, "cold_bytes:%d\r\n", tierStats.ColdBytes)
+		fmt.Fprintf(buf, "tier_file_bytes:%d\r\n", tierStats.FileBytes)
+		fmt.Fprintf(buf, "tier_offloaded:%d\r\n", tierStats.Offloaded)
+		fmt.Fprintf(buf, "tier_hits:%d\r\n", tierStats.Hits)
+		fmt.Fprintf(buf, "tier_misses:%d\r\n", tierStats.Misses)
+		fmt.Fprintf(buf, "tier_hit_rate:%.2f\r\n", tierStats.HitRate())
+		buf.WriteString("\r\n")
+	}
+	pauseMode, pauseEnd := comm.ClientPause(time.Now())
+	buf.WriteString("# Clients\r\n")
+	fmt.Fprintf(buf, "paused_actions:%s\r\n", pauseMode)
+	if pauseMode == comm.PauseNone {
+		buf.WriteString("paused_timeout_milliseconds:0\r\n")
+	} else {
+		fmt.Fprintf(buf, "paused_timeout_milliseconds:%d\r\n", time.Until(pauseEnd).Milliseconds())
+	}
+	buf.WriteString("\r\n")
+	policies := dstore.DefaultTTLs.List()
+	buf.WriteString("# Default TTLs\r\n")
+	fmt.Fprintf(buf, "default_ttl_policies:%d\r\n", len(policies))
+	for i, policy := range policies {
+		fmt.Fprintf(buf, "policy%d:pattern=%s,ttl_ms=%d,applied=%d\r\n", i, policy.Pattern, policy.TTL.Milliseconds(), policy.Applied())
+	}
+	buf.WriteString("\r\n")
 	buf.WriteString("# Keyspace\r\n")
 	fmt.Fprintf(buf, "db0:keys=%d,expires=0,avg_ttl=0\r\n", store.GetKeyCount())
+	// the composition by type is sampled periodically by the shards, see SampleKeyspace
+	composition := metrics.KeyspaceComposition()
+	for _, t := range composition.SortedTypes() {
+		fmt.Fprintf(buf, "type_%s:keys=%d,bytes=%d\r\n", t, composition.Types[t].Keys, composition.Types[t].Bytes)
+	}
 	return clientio.Encode(buf.String(), false)
 }
 
-// TODO: Placeholder to support monitoring
+// evalCONFIG manages the configuration of the server.
+// CONFIG RELOAD reads the config file again and applies the settings that can be
+// changed live, the connections being kept open. It returns the list of settings
+// applied and the list of changed settings that require a restart to take effect.
+// CONFIG DEFAULTTTL manages the default TTL policies, see evalConfigDefaultTTL.
+func evalCONFIG(args []string, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("CONFIG")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case Reload:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("CONFIG|RELOAD")
+		}
+		res, err := ReloadConfig()
+		if err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		return clientio.Encode([]interface{}{"applied", res.Applied, "restart_required", res.RestartRequired}, false)
+	case DefaultTTL:
+		return evalConfigDefaultTTL(args[1:])
+	case Help:
+		return commandHelp("CONFIG")
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try CONFIG HELP.", args[0])
+	}
+}
+
+// evalCLIENT manages the client connections.
+// CLIENT PAUSE timeout [WRITE|ALL] holds back the commands of the clients for
+// timeout milliseconds, only the write commands with WRITE, all of them with
+// ALL, the default. The commands held back run once the pause ends. CLIENT
+// UNPAUSE ends the pause right away. The CLIENT commands themselves are never
+// held back, so that an orchestrator can always end the pause.
+// CLIENT LIST is answered by the server, which knows the connections, and so
+// are CLIENT TOKEN and CLIENT READAFTER, which deal with the replication.
+// TODO: Placeholder to support monitoring for the other subcommands
 func evalCLIENT(args []string, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return clientio.RespOK
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case Pause:
+		return evalClientPause(args[1:])
+	case Unpause:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("CLIENT|UNPAUSE")
+		}
+		comm.UnpauseClients()
+		return clientio.RespOK
+	case Help:
+		return commandHelp("CLIENT")
+	default:
+		return clientio.RespOK
+	}
+}
+
+func evalClientPause(args []string) []byte {
+	if len(args) < 1 || len(args) > 2 {
+		return diceerrors.NewErrArity("CLIENT|PAUSE")
+	}
+
+	timeout, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return diceerrors.NewErrWithMessage("timeout is not an integer or out of range")
+	}
+	if timeout < 0 {
+		return diceerrors.NewErrWithMessage("timeout is negative")
+	}
+
+	mode := comm.PauseAll
+	if len(args) == 2 {
+		switch strings.ToUpper(args[1]) {
+		case Write:
+			mode = comm.PauseWrite
+		case All:
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	comm.PauseClients(mode, time.Now().Add(time.Duration(timeout)*time.Millisecond))
 	return clientio.RespOK
 }
 
-// TODO: Placeholder to support monitoring
+// evalLATENCY reports the latency events recorded, e.g. of the commands that
+// ran for longer than the watchdog threshold.
+// LATENCY LATEST returns the event name, the unix time of the latest event and
+// the latest and largest latencies in milliseconds of every event.
+// LATENCY RESET [event ...] forgets the given events, or all of them, and
+// returns the number of events forgotten.
 func evalLATENCY(args []string, store *dstore.Store) []byte {
-	return clientio.Encode([]string{}, false)
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("LATENCY")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case Latest:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("LATENCY|LATEST")
+		}
+		samples := watchdog.LatestLatency()
+		events := make([]interface{}, len(samples))
+		for i, s := range samples {
+			events[i] = []interface{}{s.Event, s.Time.Unix(), s.Latest.Milliseconds(), s.Max.Milliseconds()}
+		}
+		return clientio.Encode(events, false)
+	case Reset:
+		return clientio.Encode(watchdog.ResetLatency(args[1:]...), false)
+	case Help:
+		return commandHelp("LATENCY")
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try LATENCY HELP.", args[0])
+	}
+}
+
+// evalMEMORY is the container command for memory diagnostics.
+// MEMORY STATS returns the load of the tables of the store as name and value
+// pairs: their number of keys, the most keys they held since they were built,
+// the ratio of their capacity left unused, and how many times they were rebuilt
+// to release the memory of the keys deleted.
+func evalMEMORY(args []string, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("MEMORY")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case Stats:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("MEMORY|STATS")
+		}
+		stats := store.TableStats()
+		return clientio.Encode([]interface{}{
+			"keys.count", stats.Keys,
+			"keys.peak", stats.PeakKeys,
+			"expires.count", stats.Expires,
+			"fragmentation", fmt.Sprintf("%.2f", stats.Fragmentation()),
+			"rebuilds", stats.Rebuilds,
+			"rebuilds.reclaimed", stats.ReclaimedKeys,
+		}, false)
+	case Help:
+		return commandHelp("MEMORY")
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try MEMORY HELP.", args[0])
+	}
 }
 
 // evalLRU deletes all the keys from the LRU
@@ -2063,6 +2356,34 @@ func evalMULTI(args []string, store *dstore.Store) []byte {
 	return clientio.RespOK
 }
 
+// EvalWATCH makes the next transaction of the caller client abort if one of the
+// keys is modified before EXEC, see dstore.KeyWatch.
+func EvalWATCH(args []string, client *comm.Client, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("WATCH")
+	}
+	if client == nil {
+		return diceerrors.NewErrWithMessage("WATCH is not supported by this server")
+	}
+
+	if client.Watch == nil {
+		client.Watch = dstore.NewKeyWatch()
+	}
+	store.Watch(client.Watch, args...)
+	return clientio.RespOK
+}
+
+// EvalUNWATCH unwatches all the keys watched by the caller client.
+func EvalUNWATCH(args []string, client *comm.Client) []byte {
+	if len(args) != 0 {
+		return diceerrors.NewErrArity("UNWATCH")
+	}
+	if client != nil && client.Watch != nil {
+		client.Watch.Unwatch()
+	}
+	return clientio.RespOK
+}
+
 // EvalQWATCH adds the specified key to the watch list for the caller client.
 // Every time a key in the watch list is modified, the client will be sent a response
 // containing the new value of the key along with the operation that was performed on it.
@@ -2101,6 +2422,7 @@ func EvalQWATCH(args []string, httpOp bool, client *comm.Client, store *dstore.S
 			ClientFD:  client.Fd,
 			CacheChan: cacheChannel,
 		}
+		client.Subscribed = true
 	}
 
 	querymanager.QuerySubscriptionChan <- watchSubscription
@@ -2159,15 +2481,15 @@ func evalSETBIT(args []string, store *dstore.Store) []byte {
 	}
 
 	key := args[0]
-	offset, err := strconv.ParseInt(args[1], 10, 64)
-	if err != nil {
+	offset, ok := parseBitOffset(args[1])
+	if !ok {
 		return diceerrors.NewErrWithMessage("bit offset is not an integer or out of range")
 	}
 
-	value, err := strconv.ParseBool(args[2])
-	if err != nil {
+	if args[2] != "0" && args[2] != "1" {
 		return diceerrors.NewErrWithMessage("bit is not an integer or out of range")
 	}
+	value := args[2] == "1"
 
 	obj := store.Get(key)
 	requiredByteArraySize := offset>>3 + 1
@@ -2235,15 +2557,13 @@ func evalSETBIT(args []string, store *dstore.Store) []byte {
 
 // GETBIT key offset
 func evalGETBIT(args []string, store *dstore.Store) []byte {
-	var err error
-
 	if len(args) != 2 {
 		return diceerrors.NewErrArity("GETBIT")
 	}
 
 	key := args[0]
-	offset, err := strconv.ParseInt(args[1], 10, 64)
-	if err != nil {
+	offset, ok := parseBitOffset(args[1])
+	if !ok {
 		return diceerrors.NewErrWithMessage("bit offset is not an integer or out of range")
 	}
 
@@ -2252,10 +2572,12 @@ func evalGETBIT(args []string, store *dstore.Store) []byte {
 		return clientio.Encode(0, true)
 	}
 
+	if !isStringObj(obj) {
+		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
+
 	requiredByteArraySize := offset>>3 + 1
 	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
-	case object.ObjTypeSet:
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 	case object.ObjTypeByteArray:
 		byteArray := obj.Value.(*ByteArray)
 		byteArrayLength := byteArray.Length
@@ -2306,6 +2628,9 @@ func evalBITCOUNT(args []string, store *dstore.Store) []byte {
 	if obj == nil {
 		return clientio.Encode(0, false)
 	}
+	if !isStringObj(obj) {
+		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
 
 	var value []byte
 	var valueLength int64
@@ -2568,9 +2893,10 @@ func evalBITOP(args []string, store *dstore.Store) []byte {
 
 // evalCommand evaluates COMMAND <subcommand> command based on subcommand
 // COUNT: return total count of commands in Dice.
+// The replies listing the commands are cached, see cachedReply.
 func evalCommand(args []string, store *dstore.Store) []byte {
 	if len(args) == 0 {
-		return evalCommandDefault()
+		return cachedReply("COMMAND", evalCommandDefault)
 	}
 	subcommand := strings.ToUpper(args[0])
 	switch subcommand {
@@ -2579,9 +2905,9 @@ func evalCommand(args []string, store *dstore.Store) []byte {
 	case GetKeys:
 		return evalCommandGetKeys(args[1:])
 	case List:
-		return evalCommandList()
+		return cachedReply("COMMAND|LIST", evalCommandList)
 	case Help:
-		return evalCommandHelp()
+		return cachedReply("COMMAND|HELP", evalCommandHelp)
 	case Info:
 		return evalCommandInfo(args[1:])
 	default:
@@ -2634,19 +2960,93 @@ func evalCommandList() []byte {
 	return clientio.Encode(cmds, false)
 }
 
-// evalKeys returns the list of keys that match the pattern should be the only param in args
+// evalKeys returns the list of keys that match the pattern should be the only param in args.
+// The pattern is glob-style, as with SCAN MATCH, see regex.GlobMatch.
 func evalKeys(args []string, store *dstore.Store) []byte {
 	if len(args) != 1 {
 		return diceerrors.NewErrArity("KEYS")
 	}
 
-	pattern := args[0]
-	keys, err := store.Keys(pattern)
+	return clientio.Encode(store.Keys(args[0]), false)
+}
+
+// evalSCAN incrementally iterates over the keys of the database.
+// It returns the cursor to be used in the next call, 0 once the iteration is
+// over, along with a batch of keys.
+//
+// Usage: SCAN cursor [MATCH pattern] [COUNT count] [TYPE type] [SNAPSHOT]
+//
+// Like in Redis, MATCH and TYPE are applied once the batch is retrieved, so a
+// call may return fewer keys than COUNT, or even none, without the iteration
+// being over. Every key present for the whole scan is returned exactly once,
+// see dstore.Store.ScanFiltered. When SNAPSHOT is given while starting an
+// iteration, the scan walks a snapshot of the key set taken at that time, the
+// keys added afterwards being never returned. The snapshot cursors are
+// recognized on their own, the flag does not need to be repeated.
+func evalSCAN(args []string, store *dstore.Store) []byte {
+	if len(args) < 1 {
+		return diceerrors.NewErrArity("SCAN")
+	}
+
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
 	if err != nil {
-		return clientio.Encode(err, false)
+		return diceerrors.NewErrWithMessage("invalid cursor")
+	}
+
+	pattern, typ := "*", ""
+	count := 10
+	snapshot := false
+	for i := 1; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case Match:
+			if i+1 >= len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			pattern = args[i+1]
+			i++
+		case Count:
+			if i+1 >= len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			count, err = strconv.Atoi(args[i+1])
+			if err != nil {
+				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			if count < 1 {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			i++
+		case Type:
+			if i+1 >= len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			typ = strings.ToLower(args[i+1])
+			i++
+		case Snapshot:
+			snapshot = true
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	next, matched, ok := store.ScanFiltered(cursor, count, snapshot, func(key string) bool {
+		if !regex.GlobMatch(pattern, key) {
+			return false
+		}
+
+		if typ != "" {
+			obj := store.GetNoTouch(key)
+			if obj == nil || typeName(obj) != typ {
+				return false
+			}
+		}
+		return true
+	}, scanDeadline())
+	if !ok {
+		return diceerrors.NewErrWithMessage("invalid or expired snapshot cursor")
 	}
 
-	return clientio.Encode(keys, false)
+	return clientio.Encode([]interface{}{strconv.FormatUint(next, 10), matched}, false)
 }
 
 // evalCommandCount returns a number of commands supported by DiceDB
@@ -2671,44 +3071,33 @@ func evalCommandGetKeys(args []string) []byte {
 		return diceerrors.NewErrWithMessage("the command has no key arguments")
 	}
 
-	arity := diceCmd.Arity
-	if (arity < 0 && len(args) < -arity) ||
-		(arity >= 0 && len(args) != arity) {
+	if !diceCmd.checkArity(args[1:]) {
 		return diceerrors.NewErrWithMessage("invalid number of arguments specified for command")
 	}
-	keys := make([]string, 0)
-	step := max(keySpecs.Step, 1)
-	lastIdx := keySpecs.BeginIndex
-	if keySpecs.LastKey != 0 {
-		lastIdx = len(args) + keySpecs.LastKey
-	}
-	for i := keySpecs.BeginIndex; i <= lastIdx; i += step {
-		keys = append(keys, args[i])
-	}
-	return clientio.Encode(keys, false)
+	return clientio.Encode(keySpecs.keys(args[1:]), false)
 }
 
+// evalCommandInfo returns the details of the commands named in args, nil for the
+// unknown ones, or of all the commands without args. The reply is assembled from
+// the cached details of every command, see cachedReply.
 func evalCommandInfo(args []string) []byte {
 	if len(args) == 0 {
-		return evalCommandDefault()
+		return cachedReply("COMMAND", evalCommandDefault)
 	}
 
-	cmdMetaMap := make(map[string]interface{})
-	for _, cmdMeta := range DiceCmds {
-		cmdMetaMap[cmdMeta.Name] = convertCmdMetaToSlice(&cmdMeta)
-	}
-
-	var result []interface{}
+	buf := bytes.NewBufferString(fmt.Sprintf("*%d\r\n", len(args)))
 	for _, arg := range args {
-		arg = strings.ToUpper(arg)
-		if cmdMeta, found := cmdMetaMap[arg]; found {
-			result = append(result, cmdMeta)
-		} else {
-			result = append(result, clientio.RespNIL)
+		name := strings.ToUpper(arg)
+		cmdMeta, found := DiceCmds[name]
+		if !found {
+			buf.Write(clientio.RespNIL)
+			continue
 		}
+		buf.Write(cachedReply("COMMAND|INFO|"+name, func() []byte {
+			return clientio.Encode(convertCmdMetaToSlice(&cmdMeta), false)
+		}))
 	}
-
-	return clientio.Encode(result, false)
+	return buf.Bytes()
 }
 
 func evalRename(args []string, store *dstore.Store) []byte {
@@ -2784,15 +3173,12 @@ func evalPersist(args []string, store *dstore.Store) []byte {
 		return clientio.RespZero
 	}
 
-	// If the object exists but no expiration is set on it, return -1
-	_, isExpirySet := dstore.GetExpiry(obj, store)
-	if !isExpirySet {
+	// If the object exists, remove the expiration time, or return -1 if no
+	// expiration is set on it
+	if !store.Persist(obj) {
 		return clientio.RespMinusOne
 	}
 
-	// If the object exists, remove the expiration time
-	dstore.DelExpiry(obj, store)
-
 	return clientio.RespOne
 }
 
@@ -2872,9 +3258,7 @@ func evalGETEX(args []string, store *dstore.Store) []byte {
 		return clientio.RespNIL
 	}
 
-	// check if the object is set type or json type if yes then return error
-	if object.AssertType(obj.TypeEncoding, object.ObjTypeSet) == nil ||
-		object.AssertType(obj.TypeEncoding, object.ObjTypeJSON) == nil {
+	if !isStringObj(obj) {
 		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 	}
 
@@ -2949,7 +3333,7 @@ func evalGETEX(args []string, store *dstore.Store) []byte {
 
 	if state == Initialized {
 		if persist {
-			dstore.DelExpiry(obj, store)
+			store.Persist(obj)
 		} else {
 			store.SetExpiry(obj, exDurationMs)
 		}
@@ -3027,6 +3411,7 @@ func evalHSET(args []string, store *dstore.Store) []byte {
 	obj = store.NewObj(hashMap, -1, object.ObjTypeHashMap, object.ObjEncodingHashMap)
 
 	store.Put(key, obj)
+	dropFieldExpiries(key, keyValuePairs, store)
 
 	return clientio.Encode(numKeys, false)
 }
@@ -3130,6 +3515,48 @@ func evalHSETNX(args []string, store *dstore.Store) []byte {
 	return clientio.RespOne
 }
 
+// evalHSETEX sets the fields of the hash stored at key to their values, like
+// HSET, and sets the expiry of the key to ttl seconds at once, so that the hash
+// never lives without its expiry as it would between HSET and EXPIRE. It is
+// the usual way of storing sessions.
+// Returns the number of fields added to the hash.
+//
+// Usage: HSETEX key ttl field value [field value ...]
+func evalHSETEX(args []string, store *dstore.Store) []byte {
+	if len(args) < 4 || len(args)%2 != 0 {
+		return diceerrors.NewErrArity("HSETEX")
+	}
+
+	key := args[0]
+	ttlSec, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	if ttlSec <= 0 || ttlSec > maxExDuration {
+		return diceerrors.NewErrExpireTime("HSETEX")
+	}
+
+	var hashMap HashMap
+	if obj := store.Get(key); obj != nil {
+		if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeHashMap, object.ObjEncodingHashMap); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr)
+		}
+		hashMap = obj.Value.(HashMap)
+	}
+
+	hashMap, numKeys, err := hashMapBuilder(args[2:], hashMap)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	obj := store.NewObj(hashMap, -1, object.ObjTypeHashMap, object.ObjEncodingHashMap)
+	store.Put(key, obj)
+	store.SetExpiry(obj, ttlSec*1000)
+	dropFieldExpiries(key, args[2:], store)
+
+	return clientio.Encode(numKeys, false)
+}
+
 func evalHGETALL(args []string, store *dstore.Store) []byte {
 	if len(args) != 1 {
 		return diceerrors.NewErrArity("HGETALL")
@@ -3232,6 +3659,7 @@ func evalHDEL(args []string, store *dstore.Store) []byte {
 	}
 
 	if count > 0 {
+		store.DelFieldExpiry(key, fields...)
 		store.Put(key, obj)
 	}
 
@@ -3345,6 +3773,9 @@ func evalObjectIdleTime(key string, store *dstore.Store) []byte {
 }
 
 func evalOBJECT(args []string, store *dstore.Store) []byte {
+	if len(args) == 1 && strings.EqualFold(args[0], Help) {
+		return commandHelp("OBJECT")
+	}
 	if len(args) < 2 {
 		return diceerrors.NewErrArity("OBJECT")
 	}
@@ -3551,37 +3982,19 @@ func evalSADD(args []string, store *dstore.Store) []byte {
 	if len(args) < 2 {
 		return diceerrors.NewErrArity("SADD")
 	}
-	key := args[0]
 
-	// Get the set object from the store.
-	obj := store.Get(key)
-	lengthOfItems := len(args[1:])
+	// Get the set object from the store, creating it if needed.
+	set, errResp := getOrCreateSet(args[0], len(args[1:]), store)
+	if errResp != nil {
+		return errResp
+	}
 
 	var count = 0
-	if obj == nil {
-		var exDurationMs int64 = -1
-		var keepttl = false
-		// If the object does not exist, create a new set object.
-		value := make(map[string]struct{}, lengthOfItems)
-		// Create a new object.
-		obj = store.NewObj(value, exDurationMs, object.ObjTypeSet, object.ObjEncodingSetStr)
-		store.Put(key, obj, dstore.WithKeepTTL(keepttl))
-	}
-
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingSetStr); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	// Get the set object.
-	set := obj.Value.(map[string]struct{})
-
 	for _, arg := range args[1:] {
 		if _, ok := set[arg]; !ok {
 			set[arg] = struct{}{}
+			// the member may have been deleted without its expiry
+			store.DelFieldExpiry(args[0], arg)
 			count++
 		}
 	}
@@ -3593,26 +4006,13 @@ func evalSMEMBERS(args []string, store *dstore.Store) []byte {
 	if len(args) != 1 {
 		return diceerrors.NewErrArity("SMEMBERS")
 	}
-	key := args[0]
 
 	// Get the set object from the store.
-	obj := store.Get(key)
-
-	if obj == nil {
-		return clientio.Encode([]string{}, false)
-	}
-
-	// If the object exists, check if it is a set object.
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingSetStr); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	set, errResp := getSet(args[0], store)
+	if errResp != nil {
+		return errResp
 	}
 
-	// Get the set object.
-	set := obj.Value.(map[string]struct{})
 	// Get the members of the set.
 	members := make([]string, 0, len(set))
 	for k := range set {
@@ -3629,25 +4029,12 @@ func evalSREM(args []string, store *dstore.Store) []byte {
 	key := args[0]
 
 	// Get the set object from the store.
-	obj := store.Get(key)
-
-	var count = 0
-	if obj == nil {
-		return clientio.Encode(count, false)
-	}
-
-	// If the object exists, check if it is a set object.
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingSetStr); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	set, errResp := getSet(key, store)
+	if errResp != nil {
+		return errResp
 	}
 
-	// Get the set object.
-	set := obj.Value.(map[string]struct{})
-
+	var count = 0
 	for _, arg := range args[1:] {
 		if _, ok := set[arg]; ok {
 			delete(set, arg)
@@ -3655,6 +4042,11 @@ func evalSREM(args []string, store *dstore.Store) []byte {
 		}
 	}
 
+	// The key is deleted once its set is empty.
+	if set != nil && len(set) == 0 {
+		store.Del(key)
+	}
+
 	return clientio.Encode(count, false)
 }
 
@@ -3663,27 +4055,13 @@ func evalSCARD(args []string, store *dstore.Store) []byte {
 		return diceerrors.NewErrArity("SCARD")
 	}
 
-	key := args[0]
-
 	// Get the set object from the store.
-	obj := store.Get(key)
-
-	if obj == nil {
-		return clientio.Encode(0, false)
-	}
-
-	// If the object exists, check if it is a set object.
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingSetStr); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	set, errResp := getSet(args[0], store)
+	if errResp != nil {
+		return errResp
 	}
 
-	// Get the set object.
-	count := len(obj.Value.(map[string]struct{}))
-	return clientio.Encode(count, false)
+	return clientio.Encode(len(set), false)
 }
 
 func evalSDIFF(args []string, store *dstore.Store) []byte {
@@ -3691,89 +4069,53 @@ func evalSDIFF(args []string, store *dstore.Store) []byte {
 		return diceerrors.NewErrArity("SDIFF")
 	}
 
-	srcKey := args[0]
-	obj := store.Get(srcKey)
-
-	// if the source key does not exist, return an empty response
-	if obj == nil {
-		return clientio.Encode([]string{}, false)
-	}
-
-	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingSetStr); err != nil {
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-	}
-
-	// Get the set object from the store.
-	// store the count as the number of elements in the first set
-	srcSet := obj.Value.(map[string]struct{})
-	count := len(srcSet)
-
-	tmpSet := make(map[string]struct{}, count)
-	for k := range srcSet {
-		tmpSet[k] = struct{}{}
+	resultSet, errResp := sdiffHelper(args, store)
+	if errResp != nil {
+		return errResp
 	}
+	return encodeSetMembers(resultSet)
+}
 
-	// we decrement the count as we find the elements in the other sets
-	// if the count is 0, we skip further sets but still get them from
-	// the store to check if they are set objects and update their last accessed time
-
-	for _, arg := range args[1:] {
-		// Get the set object from the store.
-		obj := store.Get(arg)
-
-		if obj == nil {
-			continue
-		}
-
-		// If the object exists, check if it is a set object.
-		if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err != nil {
-			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-		}
-
-		if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingSetStr); err != nil {
-			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
-		}
-
-		// only if the count is greater than 0, we need to check the other sets
-		if count > 0 {
-			// Get the set object.
-			set := obj.Value.(map[string]struct{})
-
-			for k := range set {
-				if _, ok := tmpSet[k]; ok {
-					delete(tmpSet, k)
-					count--
-				}
-			}
-		}
+func evalSINTER(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("SINTER")
 	}
 
-	if count == 0 {
-		return clientio.Encode([]string{}, false)
+	resultSet, errResp := sinterHelper(args, store)
+	if errResp != nil {
+		return errResp
 	}
 
-	// Get the members of the set.
-	members := make([]string, 0, len(tmpSet))
-	for k := range tmpSet {
+	members := make([]string, 0, len(resultSet))
+	for k := range resultSet {
 		members = append(members, k)
 	}
 	return clientio.Encode(members, false)
 }
 
-func evalSINTER(args []string, store *dstore.Store) []byte {
-	if len(args) < 2 {
-		return diceerrors.NewErrArity("SINTER")
-	}
+// evalSINTERSTORE computes the intersection of the sets stored at the given
+// keys and stores it at destination. If destination already exists, it is
+// overwritten. When the intersection is empty, destination is deleted.
+//
+// The TTL of destination can be set atomically with the store using either
+// TTL EX/PX or TTL INHERITTTL, the latter applying the smallest TTL among the
+// source keys. TTL separates the options from the keys, which may be named alike.
+//
+// Returns the number of elements in the resulting set.
+//
+// Usage: SINTERSTORE destination key [key ...] [TTL EX seconds | TTL PX milliseconds | TTL INHERITTTL]
+func evalSINTERSTORE(args []string, store *dstore.Store) []byte {
+	return setAlgebraStore("SINTERSTORE", args, sinterHelper, store)
+}
 
-	sets := make([]map[string]struct{}, 0, len(args))
+// sinterHelper returns the intersection of the sets stored at keys.
+// Non-existing keys are considered to be empty sets.
+func sinterHelper(keys []string, store *dstore.Store) (map[string]struct{}, []byte) {
+	sets := make([]map[string]struct{}, 0, len(keys))
 
 	var empty = 0
 
-	for _, arg := range args {
+	for _, arg := range keys {
 		// Get the set object from the store.
 		obj := store.Get(arg)
 
@@ -3784,11 +4126,11 @@ func evalSINTER(args []string, store *dstore.Store) []byte {
 
 		// If the object exists, check if it is a set object.
 		if err := object.AssertType(obj.TypeEncoding, object.ObjTypeSet); err != nil {
-			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+			return nil, diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 		}
 
 		if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingSetStr); err != nil {
-			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+			return nil, diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 		}
 
 		// Get the set object.
@@ -3797,7 +4139,7 @@ func evalSINTER(args []string, store *dstore.Store) []byte {
 	}
 
 	if empty > 0 {
-		return clientio.Encode([]string{}, false)
+		return map[string]struct{}{}, nil
 	}
 
 	// sort the sets by the number of elements in the set
@@ -3830,15 +4172,24 @@ func evalSINTER(args []string, store *dstore.Store) []byte {
 		}
 	}
 
-	if count == 0 {
-		return clientio.Encode([]string{}, false)
-	}
+	return resultSet, nil
+}
 
-	members := make([]string, 0, len(resultSet))
-	for k := range resultSet {
-		members = append(members, k)
+// storeSetResult writes the result of a set algebra command at destination,
+// applying the TTL options of the *STORE variant. An empty result deletes
+// destination, mirroring the behavior of the non-storing commands returning
+// an empty array.
+func storeSetResult(dest string, resultSet map[string]struct{}, srcKeys []string, ttlOpts storeTTLOpts, store *dstore.Store) []byte {
+	if len(resultSet) == 0 {
+		store.Del(dest)
+		return clientio.Encode(0, false)
 	}
-	return clientio.Encode(members, false)
+
+	exDurationMs := ttlOpts.destExpiryMs(srcKeys, store)
+	obj := store.NewObj(resultSet, exDurationMs, object.ObjTypeSet, object.ObjEncodingSetStr)
+	store.Put(dest, obj)
+
+	return clientio.Encode(len(resultSet), false)
 }
 
 // PFADD Adds all the element arguments to the HyperLogLog data structure stored at the variable
@@ -3943,9 +4294,11 @@ func evalPFMERGE(args []string, store *dstore.Store) []byte {
 		}
 	}
 
-	// Save the mergedHll
-	obj = store.NewObj(mergedHll, -1, object.ObjTypeString, object.ObjEncodingRaw)
-	store.Put(destKey, obj)
+	// An existing destKey is merged in place, hence keeps its expiry
+	if obj == nil {
+		obj = store.NewObj(mergedHll, -1, object.ObjTypeString, object.ObjEncodingRaw)
+		store.Put(destKey, obj)
+	}
 
 	return clientio.RespOK
 }
@@ -4043,14 +4396,6 @@ func evalHLEN(args []string, store *dstore.Store) []byte {
 	return clientio.Encode(len(hashMap), false)
 }
 
-func evalSELECT(args []string, store *dstore.Store) []byte {
-	if len(args) != 1 {
-		return diceerrors.NewErrArity("SELECT")
-	}
-
-	return clientio.RespOK
-}
-
 // formatFloat formats float64 as string.
 // Optionally appends a decimal (.0) for whole numbers,
 // if b is true.
@@ -4267,21 +4612,27 @@ func evalTYPE(args []string, store *dstore.Store) []byte {
 		return clientio.Encode("none", true)
 	}
 
-	var typeStr string
+	return clientio.Encode(typeName(obj), true)
+}
+
+// typeName returns the name of the type of obj, as reported by the TYPE command.
+func typeName(obj *object.Obj) string {
 	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
 	case object.ObjTypeString, object.ObjTypeInt, object.ObjTypeByteArray:
-		typeStr = "string"
+		return "string"
 	case object.ObjTypeByteList:
-		typeStr = "list"
+		return "list"
 	case object.ObjTypeSet:
-		typeStr = "set"
+		return "set"
 	case object.ObjTypeHashMap:
-		typeStr = "hash"
+		return "hash"
+	case object.ObjTypeSortedSet:
+		return "zset"
+	case object.ObjTypeStream:
+		return "stream"
 	default:
-		typeStr = "non-supported type"
+		return "non-supported type"
 	}
-
-	return clientio.Encode(typeStr, true)
 }
 
 // evalGETRANGE returns the substring of the string value stored at key, determined by the offsets start and end
@@ -4298,6 +4649,9 @@ func evalGETRANGE(args []string, store *dstore.Store) []byte {
 	if obj == nil {
 		return clientio.Encode("", false)
 	}
+	if !isStringObj(obj) {
+		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
 
 	start, err := strconv.Atoi(args[1])
 	if err != nil {
@@ -4398,45 +4752,18 @@ func evalHRANDFIELD(args []string, store *dstore.Store) []byte {
 	return selectRandomFields(hashMap, count, withValues)
 }
 
-// selectRandomFields returns random fields from a hashmap.
+// selectRandomFields returns random fields from a hashmap, along with their
+// values if withValues is set, see sampleIndexes for the meaning of count.
 func selectRandomFields(hashMap HashMap, count int, withValues bool) []byte {
-	keys := make([]string, 0, len(hashMap))
-	for k := range hashMap {
-		keys = append(keys, k)
-	}
-
-	var results []string
-	resultSet := make(map[string]struct{})
-
-	abs := func(x int) int {
-		if x < 0 {
-			return -x
-		}
-		return x
+	fields := hashMap.randomFields(count)
+	if !withValues {
+		return clientio.Encode(fields, false)
 	}
 
-	for i := 0; i < abs(count); i++ {
-		if count > 0 && len(resultSet) == len(keys) {
-			break
-		}
-
-		randomIndex, _ := rand.Int(rand.Reader, big.NewInt(int64(len(keys))))
-		randomField := keys[randomIndex.Int64()]
-
-		if count > 0 {
-			if _, exists := resultSet[randomField]; exists {
-				i--
-				continue
-			}
-			resultSet[randomField] = struct{}{}
-		}
-
-		results = append(results, randomField)
-		if withValues {
-			results = append(results, hashMap[randomField])
-		}
+	results := make([]string, 0, 2*len(fields))
+	for _, field := range fields {
+		results = append(results, field, hashMap[field])
 	}
-
 	return clientio.Encode(results, false)
 }
 
@@ -4471,28 +4798,54 @@ func evalAPPEND(args []string, store *dstore.Store) []byte {
 		return clientio.Encode(len(value), false)
 	}
 	// Key exists path
-	_, currentEnc := object.ExtractTypeEncoding(obj)
-
-	var currentValueStr string
-	switch currentEnc {
-	case object.ObjEncodingInt:
-		// If the encoding is an integer, convert the current value to a string for concatenation
-		currentValueStr = strconv.FormatInt(obj.Value.(int64), 10)
-	case object.ObjEncodingEmbStr, object.ObjEncodingRaw:
-		// If the encoding is a string, retrieve the string value for concatenation
-		currentValueStr = obj.Value.(string)
-	default:
-		// If the encoding is neither integer nor string, return a "wrong type" error
+	currentValueStr, ok := stringValue(obj)
+	if !ok {
+		// If the value is neither integer nor string, return a "wrong type" error
 		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 	}
 
 	newValue := currentValueStr + value
 
-	store.Put(key, store.NewObj(newValue, -1, object.ObjTypeString, object.ObjEncodingRaw))
+	// the expiration of the key is kept
+	store.Put(key, store.NewObj(newValue, -1, object.ObjTypeString, object.ObjEncodingRaw), dstore.WithKeepTTL(true))
 
 	return clientio.Encode(len(newValue), false)
 }
 
+// stringValue returns the value of a string or integer object as a string.
+func stringValue(obj *object.Obj) (string, bool) {
+	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
+	case object.ObjTypeInt:
+		if v, ok := obj.Value.(int64); ok {
+			return strconv.FormatInt(v, 10), true
+		}
+	case object.ObjTypeString:
+		if v, ok := obj.Value.(string); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// evalSTRLEN returns the length of the string value stored at key, or 0 if the
+// key does not exist. An error is returned if the key holds another kind of value.
+func evalSTRLEN(args []string, store *dstore.Store) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("STRLEN")
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.Encode(0, false)
+	}
+
+	value, ok := stringValue(obj)
+	if !ok {
+		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
+	return clientio.Encode(len(value), false)
+}
+
 func evalJSONRESP(args []string, store *dstore.Store) []byte {
 	if len(args) < 1 {
 		return diceerrors.NewErrArity("json.resp")
@@ -4573,15 +4926,78 @@ func parseJSONStructure(jsonData interface{}, nested bool) (resp []any) {
 // evalZADD adds all the specified members with the specified scores to the sorted set stored at key.
 // If a specified member is already a member of the sorted set, the score is updated and the element reinserted at the right position to ensure the correct ordering.
 // If key does not exist, a new sorted set with the specified members as sole members is created.
+//
+// NX only adds new members and XX only updates existing ones. GT only updates the existing
+// members whose new score is greater than the current one, and LT the ones whose new score
+// is lower, neither preventing new members from being added. CH counts the updated members
+// in the reply along with the added ones. INCR increments the score of a single member
+// like ZINCRBY, and returns its new score, or nil if NX, XX, GT or LT prevented the
+// operation: with GT or LT, the increment only applies if it moves the score up, or down,
+// e.g. for high-water marks.
+// WITHPAYLOADS takes a payload after every member, stored along with it and returned by
+// the range commands, an empty payload removing the one of the member. Without it, the
+// payload of an existing member is kept.
 func evalZADD(args []string, store *dstore.Store) []byte {
-	if len(args) < 3 || len(args)%2 == 0 {
+	if len(args) < 3 {
 		return diceerrors.NewErrArity("ZADD")
 	}
 
 	key := args[0]
+	var nx, xx, gt, lt, ch, incr, withPayloads bool
+	i := 1
+options:
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case NX:
+			nx = true
+		case XX:
+			xx = true
+		case GT:
+			gt = true
+		case LT:
+			lt = true
+		case CH:
+			ch = true
+		case INCR:
+			incr = true
+		case WithPayloads:
+			withPayloads = true
+		default:
+			break options
+		}
+	}
+
+	// width is the number of arguments of every member
+	width := 2
+	if withPayloads {
+		width = 3
+	}
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%width != 0 {
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	if nx && xx {
+		return diceerrors.NewErrWithMessage("XX and NX options at the same time are not compatible")
+	}
+	if (gt && lt) || (gt && nx) || (lt && nx) {
+		return diceerrors.NewErrWithMessage("GT, LT, and/or NX options at the same time are not compatible")
+	}
+	if incr && len(pairs) != width {
+		return diceerrors.NewErrWithMessage("INCR option supports a single increment-element pair")
+	}
+
+	scores := make([]float64, len(pairs)/width)
+	for j := range scores {
+		score, err := strconv.ParseFloat(pairs[width*j], 64)
+		if err != nil || math.IsNaN(score) {
+			return diceerrors.NewErrWithMessage(diceerrors.InvalidFloatErr)
+		}
+		scores[j] = score
+	}
+
 	obj := store.Get(key)
 
-	var tree *btree.BTree
+	var tree *skipList
 	var memberMap map[string]float64
 
 	if obj != nil {
@@ -4592,48 +5008,117 @@ func evalZADD(args []string, store *dstore.Store) []byte {
 		if !ok || len(valueSlice) != 2 {
 			return diceerrors.NewErrWithMessage("Invalid sorted set object")
 		}
-		tree = valueSlice[0].(*btree.BTree)
+		tree = valueSlice[0].(*skipList)
 		memberMap = valueSlice[1].(map[string]float64)
 	} else {
-		tree = btree.New(2)
+		tree = newSkipList()
 		memberMap = make(map[string]float64)
 	}
 
-	added := 0
-	for i := 1; i < len(args); i += 2 {
-		scoreStr := args[i]
-		member := args[i+1]
+	recorder := newZSetDeltaRecorder(key, tree, store)
+	added, changed := 0, 0
+	var score float64
+	for j := range scores {
+		score = scores[j]
+		member := pairs[width*j+1]
 
-		score, err := strconv.ParseFloat(scoreStr, 64)
-		if err != nil || math.IsNaN(score) {
-			return diceerrors.NewErrWithMessage(diceerrors.InvalidFloatErr)
+		existingScore, exists := memberMap[member]
+		if (nx && exists) || (xx && !exists) {
+			if incr {
+				return clientio.RespNIL
+			}
+			continue
 		}
 
-		existingScore, exists := memberMap[member]
+		if incr {
+			var err error
+			if score, err = incrScore(existingScore, score); err != nil {
+				return diceerrors.NewErrWithMessage(err.Error())
+			}
+		}
+		if exists && ((gt && score <= existingScore) || (lt && score >= existingScore)) {
+			if incr {
+				return clientio.RespNIL
+			}
+			continue
+		}
+
+		item := &SortedSetItem{Score: score, Member: member}
+		if withPayloads {
+			item.Payload = pairs[width*j+2]
+		}
+
+		var oldScore *float64
 		if exists {
-			// Remove the existing item from the B-tree
-			oldItem := &SortedSetItem{Score: existingScore, Member: member}
-			tree.Delete(oldItem)
+			existing := tree.Get(existingScore, member)
+			if existingScore == score {
+				// the payload does not take part in the order, hence is updated in place
+				if withPayloads && existing.Payload != item.Payload {
+					existing.Payload = item.Payload
+					changed++
+				}
+				continue
+			}
+			if !withPayloads {
+				item.Payload = existing.Payload
+			}
+			recorder.before(member, existingScore)
+			// Remove the existing item from the skip list
+			tree.Delete(existing)
+			oldScore = &existingScore
+			changed++
 		} else {
+			// the member may have been deleted without its expiry
+			store.DelFieldExpiry(key, member)
 			added++
 		}
 
-		// Insert the new item into the B-tree
-		newItem := &SortedSetItem{Score: score, Member: member}
-		tree.ReplaceOrInsert(newItem)
+		// Insert the new item into the skip list
+		tree.Insert(item)
 
 		// Update the member map
 		memberMap[member] = score
+		newScore := score
+		recorder.after(member, oldScore, &newScore)
 	}
 
-	obj = store.NewObj([]interface{}{tree, memberMap}, -1, object.ObjTypeSortedSet, object.ObjEncodingBTree)
-	store.Put(key, obj)
+	if obj == nil && len(memberMap) > 0 {
+		obj = store.NewObj([]interface{}{tree, memberMap}, -1, object.ObjTypeSortedSet, object.ObjEncodingBTree)
+		store.Put(key, obj)
+	}
+	recorder.publish(store)
 
+	if incr {
+		return clientio.Encode(formatScore(score), false)
+	}
+	if ch {
+		return clientio.Encode(added+changed, false)
+	}
 	return clientio.Encode(added, false)
 }
 
+// evalZINCRBY increments the score of member in the sorted set stored at key by increment.
+// If member does not exist in the sorted set, it is added with increment as its score.
+// If key does not exist, a new sorted set with the specified member as its sole member is created.
+// Returns the new score of member, or an error if the resulting score is not a number.
+func evalZINCRBY(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("ZINCRBY")
+	}
+
+	return evalZADD([]string{args[0], INCR, args[1], args[2]}, store)
+}
+
+// formatScore formats the score of a sorted set member the way it is replied.
+func formatScore(score float64) string {
+	// Use 'g' format to match Redis's float formatting
+	return strings.ToLower(strconv.FormatFloat(score, 'g', -1, 64))
+}
+
 // evalZRANGE returns the specified range of elements in the sorted set stored at key.
 // The elements are considered to be ordered from the lowest to the highest score.
+// WITHPAYLOADS returns the payloads of the elements after their scores, an empty string
+// for the elements without one.
 func evalZRANGE(args []string, store *dstore.Store) []byte {
 	if len(args) < 3 {
 		return diceerrors.NewErrArity("ZRANGE")
@@ -4644,11 +5129,14 @@ func evalZRANGE(args []string, store *dstore.Store) []byte {
 	stopStr := args[2]
 
 	withScores := false
+	withPayloads := false
 	reverse := false
 	for i := 3; i < len(args); i++ {
 		arg := strings.ToUpper(args[i])
 		if arg == WithScores {
 			withScores = true
+		} else if arg == WithPayloads {
+			withPayloads = true
 		} else if arg == REV {
 			reverse = true
 		} else {
@@ -4679,7 +5167,7 @@ func evalZRANGE(args []string, store *dstore.Store) []byte {
 	if !ok || len(valueSlice) != 2 {
 		return diceerrors.NewErrWithMessage("Invalid sorted set object")
 	}
-	tree := valueSlice[0].(*btree.BTree)
+	tree := valueSlice[0].(*skipList)
 	length := tree.Len()
 
 	// Handle negative indices
@@ -4702,85 +5190,79 @@ func evalZRANGE(args []string, store *dstore.Store) []byte {
 	}
 
 	var result []interface{}
-	index := 0
+	index := start
 
-	// iterFunc is the function that will be called for each item in the B-tree. It will append the item to the result if it is within the specified range.
+	// iterFunc is the function that will be called for each item of the skip list from the one of rank start. It will append the item to the result.
 	// It will return false if the specified range has been reached.
-	iterFunc := func(item btree.Item) bool {
+	iterFunc := func(item *SortedSetItem) bool {
 		if index > stop {
 			return false
 		}
-		if index >= start {
-			ssi := item.(*SortedSetItem)
-			result = append(result, ssi.Member)
-			if withScores {
-				// Use 'g' format to match Redis's float formatting
-				scoreStr := strings.ToLower(strconv.FormatFloat(ssi.Score, 'g', -1, 64))
-				result = append(result, scoreStr)
-			}
+		result = append(result, item.Member)
+		if withScores {
+			result = append(result, formatScore(item.Score))
+		}
+		if withPayloads {
+			result = append(result, item.Payload)
 		}
 		index++
 		return true
 	}
 
 	if !reverse {
-		tree.Ascend(iterFunc)
+		tree.AscendFromRank(start, iterFunc)
 	} else {
-		tree.Descend(iterFunc)
+		tree.DescendFromRank(length-1-start, iterFunc)
 	}
 
 	return clientio.Encode(result, false)
 }
 
-// parseEncodingAndOffet function parses offset and encoding type for bitfield commands
-// as this part is common to all subcommands
-func parseEncodingAndOffset(args []string) (eType, eVal, offset interface{}, err error) {
-	encodingRaw := args[0]
-	offsetRaw := args[1]
+// parseBitfieldEncoding parses the encoding type of bitfield commands, such as
+// i16 or u8, into its signedness and width in bits
+func parseBitfieldEncoding(encodingRaw string) (eType string, eVal int64, err error) {
+	if encodingRaw == "" {
+		return eType, eVal, diceerrors.NewErr(diceerrors.InvalidBitfieldType)
+	}
 	switch encodingRaw[0] {
 	case 'i':
 		eType = SIGNED
 		eVal, err = strconv.ParseInt(encodingRaw[1:], 10, 64)
-		if err != nil {
-			err = diceerrors.NewErr(diceerrors.InvalidBitfieldType)
-			return eType, eVal, offset, err
-		}
-		if eVal.(int64) <= 0 || eVal.(int64) > 64 {
-			err = diceerrors.NewErr(diceerrors.InvalidBitfieldType)
-			return eType, eVal, offset, err
+		if err != nil || eVal <= 0 || eVal > 64 {
+			return eType, eVal, diceerrors.NewErr(diceerrors.InvalidBitfieldType)
 		}
 	case 'u':
 		eType = UNSIGNED
 		eVal, err = strconv.ParseInt(encodingRaw[1:], 10, 64)
-		if err != nil {
-			err = diceerrors.NewErr(diceerrors.InvalidBitfieldType)
-			return eType, eVal, offset, err
-		}
-		if eVal.(int64) <= 0 || eVal.(int64) >= 64 {
-			err = diceerrors.NewErr(diceerrors.InvalidBitfieldType)
-			return eType, eVal, offset, err
+		if err != nil || eVal <= 0 || eVal >= 64 {
+			return eType, eVal, diceerrors.NewErr(diceerrors.InvalidBitfieldType)
 		}
 	default:
-		err = diceerrors.NewErr(diceerrors.InvalidBitfieldType)
+		return eType, eVal, diceerrors.NewErr(diceerrors.InvalidBitfieldType)
+	}
+	return eType, eVal, nil
+}
+
+// parseEncodingAndOffset parses the encoding and the offset of the BITFIELD
+// subcommands, the offset being multiplied by the width of the encoding if it
+// is prefixed with '#'. The field must fit in a string of 512MB.
+func parseEncodingAndOffset(args []string) (eType string, eVal, offset int64, err error) {
+	encodingRaw := args[0]
+	offsetRaw := args[1]
+	eType, eVal, err = parseBitfieldEncoding(encodingRaw)
+	if err != nil {
 		return eType, eVal, offset, err
 	}
 
-	switch offsetRaw[0] {
-	case '#':
-		offset, err = strconv.ParseInt(offsetRaw[1:], 10, 64)
-		if err != nil {
-			err = diceerrors.NewErr(diceerrors.BitfieldOffsetErr)
-			return eType, eVal, offset, err
-		}
-		offset = offset.(int64) * eVal.(int64)
-	default:
-		offset, err = strconv.ParseInt(offsetRaw, 10, 64)
-		if err != nil {
-			err = diceerrors.NewErr(diceerrors.BitfieldOffsetErr)
-			return eType, eVal, offset, err
-		}
+	multiplier := int64(1)
+	if strings.HasPrefix(offsetRaw, "#") {
+		offsetRaw, multiplier = offsetRaw[1:], eVal
+	}
+	offset, err = strconv.ParseInt(offsetRaw, 10, 64)
+	if err != nil || offset < 0 || offset > maxBitOffset/multiplier || offset*multiplier+eVal-1 > maxBitOffset {
+		return eType, eVal, offset, diceerrors.NewErr(diceerrors.BitfieldOffsetErr)
 	}
-	return eType, eVal, offset, err
+	return eType, eVal, offset * multiplier, nil
 }
 
 // evalBITFIELD evaluates BITFIELD operations on a key store string, int or bytearray types
@@ -4794,81 +5276,147 @@ func parseEncodingAndOffset(args []string) (eType, eVal, offset interface{}, err
 // (if a negative increment is given) the specified bit field and returns the new value.
 // There is another subcommand that only changes the behavior of successive
 // INCRBY and SET subcommands calls by setting the overflow behavior:
-// OVERFLOW [WRAP|SAT|FAIL]`
+// OVERFLOW [WRAP|SAT|FAIL]
+// With FAIL, the subcommands that would overflow return nil and are not applied.
+// The fields are read from their most significant bit, like the bits of SETBIT
+// and GETBIT, and the bits beyond the end of the string are read as zeros.
+// A missing key is only created if a subcommand writes to it.
 func evalBITFIELD(args []string, store *dstore.Store) []byte {
 	if len(args) < 1 {
 		return diceerrors.NewErrArity("BITFIELD")
 	}
 
-	var overflowType string = WRAP // Default overflow type
-
-	type BitFieldOp struct {
-		Kind   string
-		EType  string
-		EVal   int64
-		Offset int64
-		Value  int64
-	}
-	var ops []BitFieldOp
-
+	overflowType := WRAP // Default overflow type
+	var ops []bitfieldOp
 	for i := 1; i < len(args); {
-		switch strings.ToUpper(args[i]) {
-		case GET:
-			if len(args) <= i+2 {
+		kind := strings.ToUpper(args[i])
+		switch kind {
+		case GET, SET, INCRBY:
+			argc := 3
+			if kind == GET {
+				argc = 2
+			}
+			if len(args) <= i+argc {
 				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
 			}
 			eType, eVal, offset, err := parseEncodingAndOffset(args[i+1 : i+3])
 			if err != nil {
 				return diceerrors.NewErrWithFormattedMessage(err.Error())
 			}
-			ops = append(ops, BitFieldOp{
-				Kind:   GET,
-				EType:  eType.(string),
-				EVal:   eVal.(int64),
-				Offset: offset.(int64),
-				Value:  int64(-1),
-			})
-			i += 3
-		case SET:
-			if len(args) <= i+3 {
+			op := bitfieldOp{
+				kind:     kind,
+				signed:   eType == SIGNED,
+				width:    eVal,
+				offset:   offset,
+				overflow: overflowType,
+			}
+			if kind != GET {
+				if op.value, err = strconv.ParseInt(args[i+3], 10, 64); err != nil {
+					return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+				}
+			}
+			ops = append(ops, op)
+			i += argc + 1
+		case OVERFLOW:
+			if len(args) <= i+1 {
 				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
 			}
-			eType, eVal, offset, err := parseEncodingAndOffset(args[i+1 : i+3])
+			switch strings.ToUpper(args[i+1]) {
+			case WRAP, FAIL, SAT:
+				overflowType = strings.ToUpper(args[i+1])
+			default:
+				return diceerrors.NewErrWithFormattedMessage(diceerrors.OverflowTypeErr)
+			}
+			i += 2
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	key := args[0]
+	obj := store.Get(key)
+	value := NewByteArray(0)
+	if obj != nil {
+		if !isStringObj(obj) {
+			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+		}
+		var err error
+		switch oType, _ := object.ExtractTypeEncoding(obj); oType {
+		case object.ObjTypeByteArray:
+			value = obj.Value.(*ByteArray)
+		case object.ObjTypeString, object.ObjTypeInt:
+			value, err = NewByteArrayFromObj(obj)
 			if err != nil {
-				return diceerrors.NewErrWithFormattedMessage(err.Error())
+				return diceerrors.NewErrWithMessage("value is not a valid byte array")
 			}
-			value, err1 := strconv.ParseInt(args[i+3], 10, 64)
-			if err1 != nil {
-				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		default:
+			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+		}
+	}
+
+	result, modified := value.applyBitfield(ops)
+	if modified {
+		switch {
+		case obj == nil:
+			store.Put(key, store.NewObj(value, -1, object.ObjTypeByteArray, object.ObjEncodingByteArray))
+		case obj.Value != value:
+			// the strings are copied to an array, hence are written back
+			store.Put(key, store.NewObj(string(value.data), -1, object.ObjTypeString, object.ObjEncodingRaw), dstore.WithKeepTTL(true))
+		}
+	}
+	return clientio.Encode(result, false)
+}
+
+// evalHBITFIELD applies BITFIELD-style subcommands on integer fields of the hash
+// stored at key, in a single atomic command. It allows updating many counters
+// of a hash at once, each bounded by an integer encoding with the overflow
+// policy in effect.
+// GET <encoding> <field> -- Returns the value of the field.
+// SET <encoding> <field> <value> -- Sets the field and returns its old value.
+// INCRBY <encoding> <field> <increment> -- Increments or decrements the field
+// and returns the new value.
+// OVERFLOW [WRAP|SAT|FAIL] -- Sets the overflow policy of the successive SET
+// and INCRBY subcommands, WRAP by default. With FAIL, the subcommands that
+// would overflow return nil and are not applied.
+// Missing fields are considered as 0. If a field holds a value that is not an
+// integer within the bounds of its encoding, an error is returned and no
+// subcommand is applied.
+func evalHBITFIELD(args []string, store *dstore.Store) []byte {
+	if len(args) < 1 {
+		return diceerrors.NewErrArity("HBITFIELD")
+	}
+
+	overflowType := WRAP
+	var ops []hashBitfieldOp
+	for i := 1; i < len(args); {
+		kind := strings.ToUpper(args[i])
+		switch kind {
+		case GET, SET, INCRBY:
+			argc := 3
+			if kind == GET {
+				argc = 2
 			}
-			ops = append(ops, BitFieldOp{
-				Kind:   SET,
-				EType:  eType.(string),
-				EVal:   eVal.(int64),
-				Offset: offset.(int64),
-				Value:  value,
-			})
-			i += 4
-		case INCRBY:
-			if len(args) <= i+3 {
+			if len(args) <= i+argc {
 				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
 			}
-			eType, eVal, offset, err := parseEncodingAndOffset(args[i+1 : i+3])
+			eType, eVal, err := parseBitfieldEncoding(args[i+1])
 			if err != nil {
 				return diceerrors.NewErrWithFormattedMessage(err.Error())
 			}
-			value, err1 := strconv.ParseInt(args[i+3], 10, 64)
-			if err1 != nil {
-				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			op := hashBitfieldOp{
+				kind:     kind,
+				field:    args[i+2],
+				signed:   eType == SIGNED,
+				width:    eVal,
+				overflow: overflowType,
+			}
+			if kind != GET {
+				if op.value, err = strconv.ParseInt(args[i+3], 10, 64); err != nil {
+					return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+				}
 			}
-			ops = append(ops, BitFieldOp{
-				Kind:   INCRBY,
-				EType:  eType.(string),
-				EVal:   eVal.(int64),
-				Offset: offset.(int64),
-				Value:  value,
-			})
-			i += 4
+			ops = append(ops, op)
+			i += argc + 1
 		case OVERFLOW:
 			if len(args) <= i+1 {
 				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
@@ -4879,59 +5427,28 @@ func evalBITFIELD(args []string, store *dstore.Store) []byte {
 			default:
 				return diceerrors.NewErrWithFormattedMessage(diceerrors.OverflowTypeErr)
 			}
-			ops = append(ops, BitFieldOp{
-				Kind:   OVERFLOW,
-				EType:  overflowType,
-				EVal:   int64(-1),
-				Offset: int64(-1),
-				Value:  int64(-1),
-			})
 			i += 2
 		default:
 			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
 		}
 	}
+
 	key := args[0]
 	obj := store.Get(key)
-	if obj == nil {
-		obj = store.NewObj(NewByteArray(1), -1, object.ObjTypeByteArray, object.ObjEncodingByteArray)
-		store.Put(args[0], obj)
-	}
-	var value *ByteArray
-	var err error
-
-	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
-	case object.ObjTypeByteArray:
-		value = obj.Value.(*ByteArray)
-	case object.ObjTypeString, object.ObjTypeInt:
-		value, err = NewByteArrayFromObj(obj)
-		if err != nil {
-			return diceerrors.NewErrWithMessage("value is not a valid byte array")
+	hashmap := make(HashMap)
+	if obj != nil {
+		if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeHashMap, object.ObjEncodingHashMap); err != nil {
+			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
 		}
-	default:
-		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+		hashmap = obj.Value.(HashMap)
 	}
 
-	var result []interface{}
-	for _, op := range ops {
-		switch op.Kind {
-		case GET:
-			res := value.getBits(int(op.Offset), int(op.EVal), op.EType == SIGNED)
-			result = append(result, res)
-		case SET:
-			prevValue := value.getBits(int(op.Offset), int(op.EVal), op.EType == SIGNED)
-			value.setBits(int(op.Offset), int(op.EVal), op.Value)
-			result = append(result, prevValue)
-		case INCRBY:
-			res, err := value.incrByBits(int(op.Offset), int(op.EVal), op.Value, overflowType, op.EType == SIGNED)
-			if err != nil {
-				result = append(result, nil)
-			} else {
-				result = append(result, res)
-			}
-		case OVERFLOW:
-			overflowType = op.EType
-		}
+	result, modified, err := hashmap.applyBitfield(ops)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	if modified && obj == nil {
+		store.Put(key, store.NewObj(hashmap, -1, object.ObjTypeHashMap, object.ObjEncodingHashMap))
 	}
 
 	return clientio.Encode(result, false)
@@ -29,10 +29,13 @@ func EvalBGREWRITEAOF(args []string, store *dstore.Store) []byte {
 	if config.EnableMultiThreading {
 		return nil
 	}
+	store = aofStore(store)
+	// the child dumps the keys modified since the previous rewrite
+	dirty := store.TakeAOFDirty()
 	newChild, _, _ := syscall.Syscall(syscall.SYS_FORK, 0, 0, 0)
 	if newChild == 0 {
 		// We are inside child process now, so we'll start flushing to disk.
-		if err := dstore.DumpAllAOF(store); err != nil {
+		if err := dstore.DumpAOF(store, dirty); err != nil {
 			return diceerrors.NewErrWithMessage("AOF failed")
 		}
 		return []byte(utils.EmptyStr)
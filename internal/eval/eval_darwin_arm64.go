@@ -28,14 +28,19 @@ func EvalBGREWRITEAOF(args []string, store *dstore.Store) []byte {
 	if config.EnableMultiThreading {
 		return nil
 	}
+	store = aofStore(store)
 
 	// Get the original PID (Process ID) - This is needed to check if we are in child process or not.
 	// The document approach is to check the return value of fork (it's 0 for child and non-zero for parent) but this is more reliable.
 	// For more details check - https://github.com/DiceDB/dice/issues/683
 	originalPID := syscall.Getpid()
+	// the child dumps the keys modified since the previous rewrite
+	dirty := store.TakeAOFDirty()
 	_, _, err := syscall.RawSyscall(syscall.SYS_FORK, 0, 0, 0)
 
 	if err != 0 {
+		// the keys are dumped by the next rewrite instead
+		store.MarkDirty(dirty.Keys)
 		return diceerrors.NewErrWithMessage("Fork failed")
 	}
 
@@ -43,7 +48,7 @@ func EvalBGREWRITEAOF(args []string, store *dstore.Store) []byte {
 
 	if isChild {
 		// We are inside child process now, so we'll start flushing to disk.
-		if err := dstore.DumpAllAOF(store); err != nil {
+		if err := dstore.DumpAOF(store, dirty); err != nil {
 			return diceerrors.NewErrWithMessage("AOF failed")
 		}
 		syscall.Exit(0)
@@ -29,11 +29,14 @@ func EvalBGREWRITEAOF(args []string, store *dstore.Store) []byte {
 	if config.EnableMultiThreading {
 		return nil
 	}
+	store = aofStore(store)
+	// the child dumps the keys modified since the previous rewrite
+	dirty := store.TakeAOFDirty()
 	childThreadID, _, _ := syscall.Syscall(syscall.SYS_GETTID, 0, 0, 0)
 	newChild, _, _ := syscall.Syscall(syscall.SYS_CLONE, syscall.CLONE_PARENT_SETTID|syscall.CLONE_CHILD_CLEARTID|uintptr(syscall.SIGCHLD), 0, childThreadID)
 	if newChild == 0 {
 		// We are inside child process now, so we'll start flushing to disk.
-		if err := dstore.DumpAllAOF(store); err != nil {
+		if err := dstore.DumpAOF(store, dirty); err != nil {
 			return diceerrors.NewErrWithMessage("AOF failed")
 		}
 		return []byte(utils.EmptyStr)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -71,6 +72,9 @@ func TestEval(t *testing.T) {
 	testEvalEXPIRE(t, store)
 	testEvalEXPIRETIME(t, store)
 	testEvalEXPIREAT(t, store)
+	testEvalPEXPIRE(t, store)
+	testEvalPEXPIREAT(t, store)
+	testEvalPEXPIRETIME(t, store)
 	testEvalDbsize(t, store)
 	testEvalGETSET(t, store)
 	testEvalHSET(t, store)
@@ -97,18 +101,26 @@ func TestEval(t *testing.T) {
 	testEvalJSONOBJKEYS(t, store)
 	testEvalGETRANGE(t, store)
 	testEvalHSETNX(t, store)
+	testEvalHSETEX(t, store)
 	testEvalPING(t, store)
 	testEvalSETEX(t, store)
 	testEvalFLUSHDB(t, store)
 	testEvalINCRBYFLOAT(t, store)
 	testEvalBITOP(t, store)
 	testEvalAPPEND(t, store)
+	testEvalSTRLEN(t, store)
+	testEvalINCRBY(t, store)
 	testEvalHRANDFIELD(t, store)
 	testEvalZADD(t, store)
+	testEvalZINCRBY(t, store)
 	testEvalZRANGE(t, store)
 	testEvalHVALS(t, store)
 	testEvalBitField(t, store)
 	testEvalHINCRBYFLOAT(t, store)
+	testEvalSINTERSTORE(t, store)
+	testEvalKEYS(t, store)
+	testEvalSCAN(t, store)
+	testEvalCONFIG(t, store)
 }
 
 func testEvalPING(t *testing.T, store *dstore.Store) {
@@ -240,6 +252,16 @@ func testEvalSET(t *testing.T, store *dstore.Store) {
 			input:          []string{"KEY", "VAL", Pxat, strconv.FormatInt(time.Now().Add(2*time.Minute).UnixMilli(), 10)},
 			migratedOutput: EvalResponse{Result: clientio.OK, Error: nil},
 		},
+		{
+			name:           "key val pair and both NX and XX",
+			input:          []string{"KEY", "VAL", NX, XX},
+			migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR syntax error")},
+		},
+		{
+			name:           "key val pair and both KEEPTTL and EX",
+			input:          []string{"KEY", "VAL", KeepTTL, Ex, "2"},
+			migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR syntax error")},
+		},
 	}
 
 	for _, tt := range tests {
@@ -680,6 +702,138 @@ func testEvalEXPIREAT(t *testing.T, store *dstore.Store) {
 	runEvalTests(t, tests, evalEXPIREAT, store)
 }
 
+func testEvalPEXPIRE(t *testing.T, store *dstore.Store) {
+	putKey := func(expireAtMs int64) func() {
+		return func() {
+			obj := store.NewObj("mock_value", -1, object.ObjTypeString, object.ObjEncodingRaw)
+			store.Put("EXISTING_KEY", obj)
+			if expireAtMs > 0 {
+				store.SetUnixTimeMsExpiry(obj, uint64(expireAtMs))
+			}
+		}
+	}
+	inAMinute := time.Now().Add(time.Minute).UnixMilli()
+
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:  []string{"KEY1"},
+			output: []byte("-ERR wrong number of arguments for 'pexpire' command\r\n"),
+		},
+		"key does not exist": {
+			input:  []string{"NONEXISTENT_KEY", "10000"},
+			output: clientio.RespZero,
+		},
+		"key exists": {
+			setup:  putKey(0),
+			input:  []string{"EXISTING_KEY", "10000"},
+			output: clientio.RespOne,
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.RespOne), string(output))
+				exp, ok := dstore.GetExpiry(store.Get("EXISTING_KEY"), store)
+				assert.Assert(t, ok)
+				remaining := int64(exp) - time.Now().UnixMilli()
+				assert.Assert(t, remaining > 9000 && remaining <= 10000, remaining)
+			},
+		},
+		"NX on a key with an expiry": {
+			setup:  putKey(inAMinute),
+			input:  []string{"EXISTING_KEY", "10000", "NX"},
+			output: clientio.RespZero,
+		},
+		"GT with a lower expiry": {
+			setup:  putKey(inAMinute),
+			input:  []string{"EXISTING_KEY", "10000", "GT"},
+			output: clientio.RespZero,
+		},
+		"LT with a lower expiry": {
+			setup:  putKey(inAMinute),
+			input:  []string{"EXISTING_KEY", "10000", "LT"},
+			output: clientio.RespOne,
+		},
+		"invalid expire time - not an integer": {
+			setup:  putKey(0),
+			input:  []string{"EXISTING_KEY", "soon"},
+			output: []byte("-ERR value is not an integer or out of range\r\n"),
+		},
+		"invalid expire time - negative integer": {
+			setup:  putKey(0),
+			input:  []string{"EXISTING_KEY", "-1"},
+			output: []byte("-ERR invalid expire time in 'pexpire' command\r\n"),
+		},
+		"invalid expire time - overflowing": {
+			setup:  putKey(0),
+			input:  []string{"EXISTING_KEY", strconv.FormatInt(math.MaxInt64, 10)},
+			output: []byte("-ERR invalid expire time in 'pexpire' command\r\n"),
+		},
+	}
+
+	runEvalTests(t, tests, evalPEXPIRE, store)
+}
+
+func testEvalPEXPIREAT(t *testing.T, store *dstore.Store) {
+	expireAtMs := time.Now().Add(2 * time.Minute).UnixMilli()
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:  []string{"KEY1"},
+			output: []byte("-ERR wrong number of arguments for 'pexpireat' command\r\n"),
+		},
+		"key does not exist": {
+			input:  []string{"NONEXISTENT_KEY", strconv.FormatInt(expireAtMs, 10)},
+			output: clientio.RespZero,
+		},
+		"key exists": {
+			setup: func() {
+				store.Put("EXISTING_KEY", store.NewObj("mock_value", -1, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input: []string{"EXISTING_KEY", strconv.FormatInt(expireAtMs, 10)},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.RespOne), string(output))
+				assert.Equal(t, string(clientio.Encode(expireAtMs, false)), string(evalPEXPIRETIME([]string{"EXISTING_KEY"}, store)))
+			},
+		},
+		"invalid expire time - negative integer": {
+			setup: func() {
+				store.Put("EXISTING_KEY", store.NewObj("mock_value", -1, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input:  []string{"EXISTING_KEY", "-1"},
+			output: []byte("-ERR invalid expire time in 'pexpireat' command\r\n"),
+		},
+	}
+
+	runEvalTests(t, tests, evalPEXPIREAT, store)
+}
+
+func testEvalPEXPIRETIME(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:  []string{},
+			output: []byte("-ERR wrong number of arguments for 'pexpiretime' command\r\n"),
+		},
+		"key does not exist": {
+			input:  []string{"NONEXISTENT_KEY"},
+			output: clientio.RespMinusTwo,
+		},
+		"key without expiry": {
+			setup: func() {
+				store.Put("EXISTING_KEY", store.NewObj("mock_value", -1, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input:  []string{"EXISTING_KEY"},
+			output: clientio.RespMinusOne,
+		},
+		"key with expiry": {
+			setup: func() {
+				obj := store.NewObj("mock_value", -1, object.ObjTypeString, object.ObjEncodingRaw)
+				store.Put("EXISTING_KEY", obj)
+				store.SetUnixTimeMsExpiry(obj, 33177117420123)
+			},
+			input:  []string{"EXISTING_KEY"},
+			output: clientio.Encode(int64(33177117420123), false),
+		},
+	}
+
+	runEvalTests(t, tests, evalPEXPIRETIME, store)
+}
+
 func testEvalJSONARRTRIM(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
 		"nil value": {
@@ -1565,6 +1719,43 @@ func testEvalJSONSET(t *testing.T, store *dstore.Store) {
 			input:  []string{"doc", "$", "{\"a\":2}"},
 			output: clientio.RespOK,
 		},
+		"NX on an existing path": {
+			setup: func() {
+				evalJSONSET([]string{"doc", "$", "{\"a\":2}"}, store)
+			},
+			input:  []string{"doc", "$.a", "3", "NX"},
+			output: clientio.RespNIL,
+		},
+		"NX on a missing path": {
+			setup: func() {
+				evalJSONSET([]string{"doc", "$", "{\"a\":2}"}, store)
+			},
+			input:  []string{"doc", "$.b", "3", "NX"},
+			output: clientio.RespOK,
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.RespOK), string(output))
+				assert.DeepEqual(t, map[string]interface{}{"a": float64(2), "b": float64(3)}, store.Get("doc").Value)
+			},
+		},
+		"XX on a missing path": {
+			setup: func() {
+				evalJSONSET([]string{"doc", "$", "{\"a\":2}"}, store)
+			},
+			input:  []string{"doc", "$.b", "3", "XX"},
+			output: clientio.RespNIL,
+		},
+		"XX on an existing path": {
+			setup: func() {
+				evalJSONSET([]string{"doc", "$", "{\"a\":2}"}, store)
+			},
+			input:  []string{"doc", "$.a", "3", "XX"},
+			output: clientio.RespOK,
+		},
+		"XX on a missing key": {
+			setup:  func() {},
+			input:  []string{"missing", "$.a", "3", "XX"},
+			output: clientio.RespNIL,
+		},
 	}
 
 	runEvalTests(t, tests, evalJSONSET, store)
@@ -2500,6 +2691,20 @@ func testEvalPFMERGE(t *testing.T, store *dstore.Store) {
 			input:  []string{"EXISTING_DEST_KEY", "EXISTING_SRC_KEY"},
 			output: clientio.RespOK,
 		},
+		"PFMERGE keeps the expiry of destKey": {
+			setup: func() {
+				evalPFADD([]string{"EXISTING_DEST_KEY", "a"}, store)
+				evalPFADD([]string{"EXISTING_SRC_KEY", "b"}, store)
+				evalEXPIRE([]string{"EXISTING_DEST_KEY", "100"}, store)
+			},
+			input: []string{"EXISTING_DEST_KEY", "EXISTING_SRC_KEY"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.RespOK), string(output))
+				ttl := string(evalTTL([]string{"EXISTING_DEST_KEY"}, store))
+				assert.Assert(t, ttl == ":100\r\n" || ttl == ":99\r\n", ttl)
+				assert.Equal(t, ":2\r\n", string(evalPFCOUNT([]string{"EXISTING_DEST_KEY"}, store)))
+			},
+		},
 	}
 
 	runEvalTests(t, tests, evalPFMERGE, store)
@@ -3277,6 +3482,16 @@ func testEvalSELECT(t *testing.T, store *dstore.Store) {
 			input:  []string{"1"},
 			output: clientio.RespOK,
 		},
+		"database is not an integer": {
+			setup:  func() {},
+			input:  []string{""},
+			output: []byte("-ERR value is not an integer or out of range\r\n"),
+		},
+		"database is out of range": {
+			setup:  func() {},
+			input:  []string{"16"},
+			output: []byte("-ERR DB index is out of range\r\n"),
+		},
 	}
 	runEvalTests(t, tests, evalSELECT, store)
 }
@@ -3550,19 +3765,19 @@ func testEvalCOMMAND(t *testing.T, store *dstore.Store) {
 		},
 		"command info valid command SET": {
 			input:  []string{"INFO", "SET"},
-			output: []byte("*1\r\n*5\r\n$3\r\nSET\r\n:-3\r\n:1\r\n:0\r\n:0\r\n"),
+			output: []byte("*1\r\n*6\r\n$3\r\nSET\r\n:-3\r\n:1\r\n:0\r\n:0\r\n*1\r\n$5\r\nwrite\r\n"),
 		},
 		"command info valid command GET": {
 			input:  []string{"INFO", "GET"},
-			output: []byte("*1\r\n*5\r\n$3\r\nGET\r\n:2\r\n:1\r\n:0\r\n:0\r\n"),
+			output: []byte("*1\r\n*6\r\n$3\r\nGET\r\n:2\r\n:1\r\n:0\r\n:0\r\n*1\r\n$8\r\nreadonly\r\n"),
 		},
 		"command info valid command PING": {
 			input:  []string{"INFO", "PING"},
-			output: []byte("*1\r\n*5\r\n$4\r\nPING\r\n:-1\r\n:0\r\n:0\r\n:0\r\n"),
+			output: []byte("*1\r\n*6\r\n$4\r\nPING\r\n:-1\r\n:0\r\n:0\r\n:0\r\n*0\r\n"),
 		},
 		"command info multiple valid commands": {
 			input:  []string{"INFO", "SET", "GET"},
-			output: []byte("*2\r\n*5\r\n$3\r\nSET\r\n:-3\r\n:1\r\n:0\r\n:0\r\n*5\r\n$3\r\nGET\r\n:2\r\n:1\r\n:0\r\n:0\r\n"),
+			output: []byte("*2\r\n*6\r\n$3\r\nSET\r\n:-3\r\n:1\r\n:0\r\n:0\r\n*1\r\n$5\r\nwrite\r\n*6\r\n$3\r\nGET\r\n:2\r\n:1\r\n:0\r\n:0\r\n*1\r\n$8\r\nreadonly\r\n"),
 		},
 		"command info invalid command": {
 			input:  []string{"INFO", "INVALID_CMD"},
@@ -3570,7 +3785,7 @@ func testEvalCOMMAND(t *testing.T, store *dstore.Store) {
 		},
 		"command info mixture of valid and invalid commands": {
 			input:  []string{"INFO", "SET", "INVALID_CMD"},
-			output: []byte("*2\r\n*5\r\n$3\r\nSET\r\n:-3\r\n:1\r\n:0\r\n:0\r\n$-1\r\n"),
+			output: []byte("*2\r\n*6\r\n$3\r\nSET\r\n:-3\r\n:1\r\n:0\r\n:0\r\n*1\r\n$5\r\nwrite\r\n$-1\r\n"),
 		},
 		"command unknown": {
 			input:  []string{"UNKNOWN"},
@@ -3931,6 +4146,69 @@ func testEvalHSETNX(t *testing.T, store *dstore.Store) {
 	runEvalTests(t, tests, evalHSETNX, store)
 }
 
+func testEvalHSETEX(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args passed": {
+			setup:  func() {},
+			input:  []string{"KEY", "10", "field"},
+			output: []byte("-ERR wrong number of arguments for 'hsetex' command\r\n"),
+		},
+		"field without value passed": {
+			setup:  func() {},
+			input:  []string{"KEY", "10", "field1", "value1", "field2"},
+			output: []byte("-ERR wrong number of arguments for 'hsetex' command\r\n"),
+		},
+		"ttl not an integer": {
+			setup:  func() {},
+			input:  []string{"KEY", "ten", "field", "value"},
+			output: []byte("-ERR value is not an integer or out of range\r\n"),
+		},
+		"ttl not positive": {
+			setup:  func() {},
+			input:  []string{"KEY", "0", "field", "value"},
+			output: []byte("-ERR invalid expire time in 'hsetex' command\r\n"),
+		},
+		"key of another type": {
+			setup: func() {
+				evalSET([]string{"KEY", "value"}, store)
+			},
+			input:  []string{"KEY", "10", "field", "value"},
+			output: []byte("-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"),
+		},
+		"fields and expiry set": {
+			setup:  func() {},
+			input:  []string{"KEY", "10", "field1", "value1", "field2", "value2"},
+			output: clientio.Encode(int64(2), false),
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(int64(2), false)), string(output))
+				obj := store.Get("KEY")
+				assert.DeepEqual(t, HashMap{"field1": "value1", "field2": "value2"}, obj.Value)
+				exp, ok := dstore.GetExpiry(obj, store)
+				assert.Assert(t, ok)
+				remaining := int64(exp) - time.Now().UnixMilli()
+				assert.Assert(t, remaining > 9000 && remaining <= 10000, remaining)
+			},
+		},
+		"existing hash updated and expiry replaced": {
+			setup: func() {
+				evalHSETEX([]string{"KEY", "1000", "field1", "value1"}, store)
+			},
+			input:  []string{"KEY", "10", "field1", "new", "field2", "value2"},
+			output: clientio.Encode(int64(1), false),
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(int64(1), false)), string(output))
+				obj := store.Get("KEY")
+				assert.DeepEqual(t, HashMap{"field1": "new", "field2": "value2"}, obj.Value)
+				exp, ok := dstore.GetExpiry(obj, store)
+				assert.Assert(t, ok)
+				assert.Assert(t, int64(exp)-time.Now().UnixMilli() <= 10000)
+			},
+		},
+	}
+
+	runEvalTests(t, tests, evalHSETEX, store)
+}
+
 func TestMSETConsistency(t *testing.T) {
 	store := dstore.NewStore(nil)
 	evalMSET([]string{"KEY", "VAL", "KEY2", "VAL2"}, store)
@@ -4611,6 +4889,34 @@ func testEvalHRANDFIELD(t *testing.T, store *dstore.Store) {
 				assert.Equal(t, 4, count, "Expected 4 fields and values, found %d", count)
 			},
 		},
+		"key exists with negative count and WITHVALUES argument": {
+			setup: func() {
+				key := "KEY_MOCK"
+				newMap := make(HashMap)
+				newMap["field1"] = "value1"
+				newMap["field2"] = "value2"
+
+				obj := &object.Obj{
+					TypeEncoding:   object.ObjTypeHashMap | object.ObjEncodingHashMap,
+					Value:          newMap,
+					LastAccessedAt: uint32(time.Now().Unix()),
+				}
+
+				store.Put(key, obj)
+			},
+			input: []string{"KEY_MOCK", "-5", WithValues},
+			validator: func(output []byte) {
+				value, err := clientio.NewRESPParser(bytes.NewBuffer(output)).DecodeOne()
+				assert.NilError(t, err)
+				items := value.([]interface{})
+				assert.Equal(t, 10, len(items))
+				for i := 0; i < len(items); i += 2 {
+					field := items[i].(string)
+					assert.Assert(t, field == "field1" || field == "field2", "Unexpected field returned: %s", field)
+					assert.Equal(t, "value"+strings.TrimPrefix(field, "field"), items[i+1])
+				}
+			},
+		},
 	}
 
 	runEvalTests(t, tests, evalHRANDFIELD, store)
@@ -4756,6 +5062,19 @@ func testEvalAPPEND(t *testing.T, store *dstore.Store) {
 			input:  []string{"hashKey", "val"},
 			output: diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr),
 		},
+		"append keeps the expiration of the key": {
+			setup: func() {
+				store.Del("key")
+				store.Put("key", store.NewObj("val", 10000, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input:  []string{"key", "val"},
+			output: clientio.Encode(6, false),
+			validator: func(output []byte) {
+				if _, ok := dstore.GetExpiry(store.Get("key"), store); !ok {
+					t.Errorf("expiration of the key was removed")
+				}
+			},
+		},
 		"append to key created using SETBIT": {
 			setup: func() {
 				key := "bitKey"
@@ -4773,6 +5092,87 @@ func testEvalAPPEND(t *testing.T, store *dstore.Store) {
 	runEvalTests(t, tests, evalAPPEND, store)
 }
 
+func testEvalSTRLEN(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:  []string{"key", "extra"},
+			output: []byte("-ERR wrong number of arguments for 'strlen' command\r\n"),
+		},
+		"key does not exist": {
+			input:  []string{"nonexistent"},
+			output: clientio.Encode(0, false),
+		},
+		"string value": {
+			setup: func() {
+				store.Put("key", store.NewObj("hello", -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+			},
+			input:  []string{"key"},
+			output: clientio.Encode(5, false),
+		},
+		"integer value": {
+			setup: func() {
+				store.Put("key", store.NewObj(int64(-1234), -1, object.ObjTypeInt, object.ObjEncodingInt))
+			},
+			input:  []string{"key"},
+			output: clientio.Encode(5, false),
+		},
+		"key holding a set": {
+			setup: func() {
+				store.Put("setKey", store.NewObj(map[string]struct{}{"a": {}}, -1, object.ObjTypeSet, object.ObjEncodingSetStr))
+			},
+			input:  []string{"setKey"},
+			output: diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr),
+		},
+	}
+
+	runEvalTests(t, tests, evalSTRLEN, store)
+}
+
+func testEvalINCRBY(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"increment a string holding an integer": {
+			setup: func() {
+				store.Put("key", store.NewObj("10", -1, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input:  []string{"key", "5"},
+			output: clientio.Encode(15, false),
+			validator: func(output []byte) {
+				if err := object.AssertTypeAndEncoding(store.Get("key").TypeEncoding, object.ObjTypeInt, object.ObjEncodingInt); err != nil {
+					t.Errorf("value was not converted to an integer")
+				}
+			},
+		},
+		"increment a string not holding an integer": {
+			setup: func() {
+				store.Put("key", store.NewObj("010", -1, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input:  []string{"key", "5"},
+			output: []byte("-ERR value is not an integer or out of range\r\n"),
+		},
+		"increment a key holding a hash": {
+			setup: func() {
+				store.Put("hashKey", store.NewObj(HashMap{"field": "value"}, -1, object.ObjTypeHashMap, object.ObjEncodingHashMap))
+			},
+			input:  []string{"hashKey", "5"},
+			output: diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr),
+		},
+		"increment overflows": {
+			setup: func() {
+				store.Put("key", store.NewObj(int64(math.MaxInt64), -1, object.ObjTypeInt, object.ObjEncodingInt))
+			},
+			input:  []string{"key", "1"},
+			output: []byte("-ERR increment or decrement would overflow\r\n"),
+		},
+	}
+
+	runEvalTests(t, tests, evalINCRBY, store)
+
+	// the opposite of the smallest decrement cannot be represented
+	store.Del("key")
+	assert.Equal(t, "-ERR increment or decrement would overflow\r\n", string(evalDECRBY([]string{"key", "-9223372036854775808"}, store)))
+	assert.Assert(t, store.Get("key") == nil)
+}
+
 func BenchmarkEvalAPPEND(b *testing.B) {
 	store := dstore.NewStore(nil)
 	for i := 0; i < b.N; i++ {
@@ -4963,11 +5363,186 @@ func testEvalZADD(t *testing.T, store *dstore.Store) {
 			input:  []string{"myzset", "1", "member1"},
 			output: []byte("-ERR Existing key has wrong Dice type\r\n"),
 		},
+		"ZADD NX does not update existing members": {
+			setup: func() {
+				evalZADD([]string{"myzset", "1", "member1"}, store)
+			},
+			input:  []string{"myzset", "NX", "CH", "2", "member1", "3", "member2"},
+			output: clientio.Encode(int64(1), false),
+		},
+		"ZADD XX does not add new members": {
+			setup: func() {
+				evalZADD([]string{"myzset", "1", "member1"}, store)
+			},
+			input:  []string{"myzset", "XX", "CH", "2", "member1", "3", "member2"},
+			output: clientio.Encode(int64(1), false),
+		},
+		"ZADD XX on non-existing key": {
+			input:  []string{"myzset_xx", "XX", "1", "member1"},
+			output: clientio.Encode(int64(0), false),
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(int64(0), false)), string(output))
+				assert.Assert(t, store.Get("myzset_xx") == nil)
+			},
+		},
+		"ZADD CH counts updated members": {
+			setup: func() {
+				evalZADD([]string{"myzset", "1", "member1", "2", "member2"}, store)
+			},
+			input:  []string{"myzset", "CH", "1", "member1", "3", "member2", "4", "member3"},
+			output: clientio.Encode(int64(2), false),
+		},
+		"ZADD with NX and XX": {
+			input:  []string{"myzset", "NX", "XX", "1", "member1"},
+			output: diceerrors.NewErrWithMessage("XX and NX options at the same time are not compatible"),
+		},
+		"ZADD INCR with several members": {
+			input:  []string{"myzset", "INCR", "1", "member1", "2", "member2"},
+			output: diceerrors.NewErrWithMessage("INCR option supports a single increment-element pair"),
+		},
+		"ZADD INCR increments the score": {
+			setup: func() {
+				evalZADD([]string{"myzset", "1.5", "member1"}, store)
+			},
+			input:  []string{"myzset", "INCR", "2", "member1"},
+			output: clientio.Encode("3.5", false),
+		},
+		"ZADD INCR NX on existing member": {
+			setup: func() {
+				evalZADD([]string{"myzset", "1", "member1"}, store)
+			},
+			input:  []string{"myzset", "NX", "INCR", "2", "member1"},
+			output: clientio.RespNIL,
+		},
+		"ZADD with GT and LT": {
+			input:  []string{"myzset", "GT", "LT", "1", "member1"},
+			output: diceerrors.NewErrWithMessage("GT, LT, and/or NX options at the same time are not compatible"),
+		},
+		"ZADD with GT and NX": {
+			input:  []string{"myzset", "GT", "NX", "1", "member1"},
+			output: diceerrors.NewErrWithMessage("GT, LT, and/or NX options at the same time are not compatible"),
+		},
+		"ZADD GT only updates the members whose score increases": {
+			setup: func() {
+				evalZADD([]string{"myzset", "2", "member1", "2", "member2"}, store)
+			},
+			input: []string{"myzset", "GT", "CH", "1", "member1", "3", "member2", "1", "member3"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(int64(2), false)), string(output))
+				assert.Equal(t, string(clientio.Encode([]string{"member3", "1", "member1", "2", "member2", "3"}, false)),
+					string(evalZRANGE([]string{"myzset", "0", "-1", WithScores}, store)))
+			},
+		},
+		"ZADD LT only updates the members whose score decreases": {
+			setup: func() {
+				evalZADD([]string{"myzset", "2", "member1", "2", "member2"}, store)
+			},
+			input:  []string{"myzset", "LT", "CH", "1", "member1", "3", "member2"},
+			output: clientio.Encode(int64(1), false),
+		},
+		"ZADD GT INCR increments a score moving up": {
+			setup: func() {
+				evalZADD([]string{"myzset", "5", "member1"}, store)
+			},
+			input:  []string{"myzset", "GT", "INCR", "2", "member1"},
+			output: clientio.Encode("7", false),
+		},
+		"ZADD GT INCR does not increment a score moving down": {
+			setup: func() {
+				evalZADD([]string{"myzset", "5", "member1"}, store)
+			},
+			input: []string{"myzset", "GT", "INCR", "-2", "member1"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.RespNIL), string(output))
+				assert.Equal(t, string(clientio.Encode([]string{"member1", "5"}, false)), string(evalZRANGE([]string{"myzset", "0", "-1", WithScores}, store)))
+			},
+		},
+		"ZADD LT INCR does not increment a score moving up": {
+			setup: func() {
+				evalZADD([]string{"myzset", "5", "member1"}, store)
+			},
+			input:  []string{"myzset", "LT", "INCR", "0", "member1"},
+			output: clientio.RespNIL,
+		},
+		"ZADD GT INCR adds a new member": {
+			input:  []string{"myzset", "GT", "INCR", "-2", "member1"},
+			output: clientio.Encode("-2", false),
+		},
+		"ZADD INCR resulting in NaN": {
+			setup: func() {
+				evalZADD([]string{"myzset", "+inf", "member1"}, store)
+			},
+			input:  []string{"myzset", "INCR", "-inf", "member1"},
+			output: diceerrors.NewErrWithMessage(diceerrors.ScoreNaNErr),
+		},
+		"ZADD with options but no pairs": {
+			input:  []string{"myzset", "NX", "CH"},
+			output: diceerrors.NewErrWithMessage(diceerrors.SyntaxErr),
+		},
 	}
 
 	runEvalTests(t, tests, evalZADD, store)
 }
 
+func testEvalZINCRBY(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"ZINCRBY with wrong number of arguments": {
+			input:  []string{"myzset", "1"},
+			output: diceerrors.NewErrArity("ZINCRBY"),
+		},
+		"ZINCRBY with non-numeric increment": {
+			input:  []string{"myzset", "one", "member1"},
+			output: diceerrors.NewErrWithMessage(diceerrors.InvalidFloatErr),
+		},
+		"ZINCRBY on non-existing key": {
+			input:  []string{"myzset", "2.5", "member1"},
+			output: clientio.Encode("2.5", false),
+		},
+		"ZINCRBY on existing member": {
+			setup: func() {
+				evalZADD([]string{"myzset", "1", "member1"}, store)
+			},
+			input:  []string{"myzset", "-3", "member1"},
+			output: clientio.Encode("-2", false),
+		},
+		"ZINCRBY adding opposite infinities": {
+			setup: func() {
+				evalZADD([]string{"myzset", "-inf", "member1"}, store)
+			},
+			input:  []string{"myzset", "+inf", "member1"},
+			output: diceerrors.NewErrWithMessage(diceerrors.ScoreNaNErr),
+			validator: func(output []byte) {
+				assert.Equal(t, string(diceerrors.NewErrWithMessage(diceerrors.ScoreNaNErr)), string(output))
+				assert.Equal(t, string(clientio.Encode([]string{"member1", "-inf"}, false)),
+					string(evalZRANGE([]string{"myzset", "0", "-1", "WITHSCORES"}, store)))
+			},
+		},
+		"ZINCRBY overflowing the score": {
+			setup: func() {
+				evalZADD([]string{"myzset", "1.7e308", "member1"}, store)
+			},
+			input:  []string{"myzset", "1.7e308", "member1"},
+			output: diceerrors.NewErrWithMessage(diceerrors.ScoreNaNErr),
+		},
+		"ZINCRBY on infinite score": {
+			setup: func() {
+				evalZADD([]string{"myzset", "+inf", "member1"}, store)
+			},
+			input:  []string{"myzset", "1", "member1"},
+			output: clientio.Encode("+inf", false),
+		},
+		"ZINCRBY to a key of wrong type": {
+			setup: func() {
+				store.Put("myzset", store.NewObj("string_value", -1, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input:  []string{"myzset", "1", "member1"},
+			output: []byte("-ERR Existing key has wrong Dice type\r\n"),
+		},
+	}
+
+	runEvalTests(t, tests, evalZINCRBY, store)
+}
+
 func testEvalZRANGE(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
 		"ZRANGE on non-existing key": {
@@ -5103,6 +5678,50 @@ func testEvalBitField(t *testing.T, store *dstore.Store) {
 			input:  []string{"bits", "SET", "u8", "0", "INCRBY", "u8", "0", "100", "GET", "u8", "288"},
 			output: []byte("-ERR value is not an integer or out of range\r\n"),
 		},
+		"BITFIELD fields read from their most significant bit": {
+			setup: func() {
+				evalBITFIELD([]string{"bits", "set", "u16", "0", "258"}, store)
+			},
+			input:  []string{"bits", "get", "u8", "0", "get", "u8", "8", "get", "u16", "0", "get", "u4", "4"},
+			output: clientio.Encode([]int64{1, 2, 258, 1}, false),
+		},
+		"BITFIELD OVERFLOW only applies to the following subcommands": {
+			input:  []string{"bits", "incrby", "u2", "0", "5", "overflow", "fail", "incrby", "u2", "0", "5", "overflow", "sat", "set", "u2", "0", "9", "get", "u2", "0"},
+			output: clientio.Encode([]interface{}{int64(1), nil, int64(1), int64(3)}, false),
+		},
+		"BITFIELD i64 overflow wrap": {
+			setup: func() {
+				evalBITFIELD([]string{"bits", "set", "i64", "0", "9223372036854775807"}, store)
+			},
+			input:  []string{"bits", "incrby", "i64", "0", "1"},
+			output: clientio.Encode([]int64{-9223372036854775808}, false),
+		},
+		"BITFIELD GET does not create the key": {
+			input: []string{"bits", "get", "u8", "100"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode([]int64{0}, false)), string(output))
+				assert.Assert(t, store.Get("bits") == nil)
+			},
+		},
+		"BITFIELD on a string writes it back": {
+			setup: func() {
+				evalSET([]string{"bits", "a", "EX", "100"}, store)
+			},
+			input: []string{"bits", "set", "u8", "#0", "99"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode([]int64{97}, false)), string(output))
+				assert.Equal(t, "c", store.Get("bits").Value)
+				assert.Equal(t, string(clientio.Encode(100, false)), string(evalTTL([]string{"bits"}, store)))
+			},
+		},
+		"BITFIELD negative bit offset": {
+			input:  []string{"bits", "set", "u8", "-1", "1"},
+			output: []byte("-ERR bit offset is not an integer or out of range\r\n"),
+		},
+		"BITFIELD bit offset beyond 512MB": {
+			input:  []string{"bits", "get", "u8", "#536870912"},
+			output: []byte("-ERR bit offset is not an integer or out of range\r\n"),
+		},
 	}
 	runEvalTests(t, testCases, evalBITFIELD, store)
 }
@@ -5341,3 +5960,334 @@ func testEvalDUMP(t *testing.T, store *dstore.Store) {
 
 	runEvalTests(t, tests, evalDUMP, store)
 }
+
+func testEvalSINTERSTORE(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"SINTERSTORE with wrong number of arguments": {
+			input:  []string{"dest"},
+			output: diceerrors.NewErrArity("SINTERSTORE"),
+		},
+		"SINTERSTORE with keys named like the TTL options": {
+			setup: func() {
+				evalSADD([]string{"EX", "a", "b"}, store)
+				evalSADD([]string{"INHERITTTL", "a"}, store)
+			},
+			input: []string{"dest", "EX", "INHERITTTL"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(1, false)), string(output))
+				_, isExpirySet := dstore.GetExpiry(store.Get("dest"), store)
+				assert.Assert(t, !isExpirySet)
+			},
+		},
+		"SINTERSTORE stores the intersection": {
+			setup: func() {
+				evalSADD([]string{"set1", "a", "b", "c"}, store)
+				evalSADD([]string{"set2", "b", "c", "d"}, store)
+			},
+			input: []string{"dest", "set1", "set2"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(2, false)), string(output))
+				obj := store.Get("dest")
+				assert.Assert(t, obj != nil)
+				assert.DeepEqual(t, map[string]struct{}{"b": {}, "c": {}}, obj.Value.(map[string]struct{}))
+				_, isExpirySet := dstore.GetExpiry(obj, store)
+				assert.Assert(t, !isExpirySet)
+			},
+		},
+		"SINTERSTORE with empty intersection deletes destination": {
+			setup: func() {
+				evalSADD([]string{"dest", "x"}, store)
+				evalSADD([]string{"set1", "a"}, store)
+			},
+			input: []string{"dest", "set1", "set2"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(0, false)), string(output))
+				assert.Assert(t, store.Get("dest") == nil)
+			},
+		},
+		"SINTERSTORE with explicit PX": {
+			setup: func() {
+				evalSADD([]string{"set1", "a"}, store)
+			},
+			input: []string{"dest", "set1", "TTL", "PX", "5000"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(1, false)), string(output))
+				exp, isExpirySet := dstore.GetExpiry(store.Get("dest"), store)
+				assert.Assert(t, isExpirySet)
+				remaining := int64(exp) - utils.GetCurrentTime().UnixMilli()
+				assert.Assert(t, remaining > 0 && remaining <= 5000)
+			},
+		},
+		"SINTERSTORE with INHERITTTL picks the smallest TTL": {
+			setup: func() {
+				evalSADD([]string{"set1", "a"}, store)
+				evalSADD([]string{"set2", "a"}, store)
+				evalSADD([]string{"set3", "a"}, store)
+				evalEXPIRE([]string{"set1", "100"}, store)
+				evalEXPIRE([]string{"set2", "10"}, store)
+			},
+			input: []string{"dest", "set1", "set2", "set3", "TTL", "INHERITTTL"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(1, false)), string(output))
+				exp, isExpirySet := dstore.GetExpiry(store.Get("dest"), store)
+				assert.Assert(t, isExpirySet)
+				remaining := int64(exp) - utils.GetCurrentTime().UnixMilli()
+				assert.Assert(t, remaining > 0 && remaining <= 10000)
+			},
+		},
+		"SINTERSTORE with INHERITTTL and no source TTL": {
+			setup: func() {
+				evalSADD([]string{"set1", "a"}, store)
+			},
+			input: []string{"dest", "set1", "TTL", "INHERITTTL"},
+			validator: func(output []byte) {
+				assert.Equal(t, string(clientio.Encode(1, false)), string(output))
+				_, isExpirySet := dstore.GetExpiry(store.Get("dest"), store)
+				assert.Assert(t, !isExpirySet)
+			},
+		},
+		"SINTERSTORE with invalid expire time": {
+			setup: func() {
+				evalSADD([]string{"set1", "a"}, store)
+			},
+			input:  []string{"dest", "set1", "TTL", "EX", "0"},
+			output: diceerrors.NewErrExpireTime("SINTERSTORE"),
+		},
+		"SINTERSTORE with both EX and INHERITTTL": {
+			setup: func() {
+				evalSADD([]string{"set1", "a"}, store)
+			},
+			input:  []string{"dest", "set1", "TTL", "EX", "10", "INHERITTTL"},
+			output: diceerrors.NewErrWithMessage(diceerrors.SyntaxErr),
+		},
+		"SINTERSTORE with wrong type source": {
+			setup: func() {
+				store.Put("str", store.NewObj("value", -1, object.ObjTypeString, object.ObjEncodingRaw))
+			},
+			input:  []string{"dest", "str"},
+			output: diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr),
+		},
+	}
+
+	runEvalTests(t, tests, evalSINTERSTORE, store)
+}
+
+func testEvalKEYS(t *testing.T, store *dstore.Store) {
+	defer func(clock utils.Clock) { utils.CurrentTime = clock }(utils.CurrentTime)
+	mockTime := &utils.MockClock{CurrTime: time.Now()}
+	utils.CurrentTime = mockTime
+
+	setup := func() {
+		evalMSET([]string{"k1", "v", "k2", "v", "k3", "v", "ka", "v", "k*", "v", "a/b", "v"}, store)
+		evalSET([]string{"k4", "v", Px, "1"}, store)
+		// the expired keys are never returned
+		mockTime.SetTime(mockTime.CurrTime.Add(time.Second))
+	}
+	// keysOf returns the keys of the output of KEYS, sorted.
+	keysOf := func(output []byte) []string {
+		value, err := clientio.NewRESPParser(bytes.NewBuffer(output)).DecodeOne()
+		assert.NilError(t, err)
+		keys := []string{}
+		for _, k := range value.([]interface{}) {
+			keys = append(keys, k.(string))
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	tests := map[string]evalTestCase{
+		"KEYS with wrong number of arguments": {
+			input:  []string{},
+			output: diceerrors.NewErrArity("KEYS"),
+		},
+		"KEYS on empty database": {
+			input:  []string{"*"},
+			output: clientio.Encode([]string{}, false),
+		},
+	}
+	for pattern, keys := range map[string][]string{
+		"k?":      {"k*", "k1", "k2", "k3", "ka"},
+		"k[12]":   {"k1", "k2"},
+		"k[^12]":  {"k*", "k3", "ka"},
+		"k[1-2a]": {"k1", "k2", "ka"},
+		"k\\*":    {"k*"},
+		"a*":      {"a/b"},
+		"k[":      {},
+	} {
+		keys := keys
+		tests["KEYS "+pattern] = evalTestCase{
+			setup: setup,
+			input: []string{pattern},
+			validator: func(output []byte) {
+				assert.DeepEqual(t, keys, keysOf(output))
+			},
+		}
+	}
+
+	runEvalTests(t, tests, evalKeys, store)
+}
+
+func testEvalSCAN(t *testing.T, store *dstore.Store) {
+	// scanAll runs SCAN until the iteration is over, calling between each call.
+	scanAll := func(args []string, between func()) []string {
+		var keys []string
+		cursor := "0"
+		for {
+			output := evalSCAN(append([]string{cursor}, args...), store)
+			rp := clientio.NewRESPParser(bytes.NewBuffer(output))
+			value, err := rp.DecodeOne()
+			assert.NilError(t, err)
+			reply := value.([]interface{})
+			for _, k := range reply[1].([]interface{}) {
+				keys = append(keys, k.(string))
+			}
+			cursor = reply[0].(string)
+			if cursor == "0" {
+				return keys
+			}
+			if between != nil {
+				between()
+			}
+		}
+	}
+
+	tests := map[string]evalTestCase{
+		"SCAN with wrong number of arguments": {
+			input:  []string{},
+			output: diceerrors.NewErrArity("SCAN"),
+		},
+		"SCAN with invalid cursor": {
+			input:  []string{"abc"},
+			output: diceerrors.NewErrWithMessage("invalid cursor"),
+		},
+		"SCAN with invalid COUNT": {
+			input:  []string{"0", "COUNT", "0"},
+			output: diceerrors.NewErrWithMessage(diceerrors.SyntaxErr),
+		},
+		"SCAN with unknown option": {
+			input:  []string{"0", "FOO"},
+			output: diceerrors.NewErrWithMessage(diceerrors.SyntaxErr),
+		},
+		"SCAN with unknown snapshot cursor": {
+			input:  []string{strconv.FormatUint(42<<32, 10)},
+			output: diceerrors.NewErrWithMessage("invalid or expired snapshot cursor"),
+		},
+		"SCAN on empty database": {
+			input:  []string{"0"},
+			output: clientio.Encode([]interface{}{"0", []string{}}, false),
+		},
+		"SCAN returns keys in batches": {
+			setup: func() {
+				evalMSET([]string{"k1", "v", "k2", "v", "k3", "v"}, store)
+			},
+			input: []string{"0", "COUNT", "2"},
+			validator: func(output []byte) {
+				value, err := clientio.NewRESPParser(bytes.NewBuffer(output)).DecodeOne()
+				assert.NilError(t, err)
+				reply := value.([]interface{})
+				assert.Assert(t, reply[0] != "0")
+				assert.Equal(t, 2, len(reply[1].([]interface{})))
+
+				keys := scanAll([]string{"COUNT", "2"}, nil)
+				sort.Strings(keys)
+				assert.DeepEqual(t, []string{"k1", "k2", "k3"}, keys)
+			},
+		},
+		"SCAN with MATCH and TYPE": {
+			setup: func() {
+				evalMSET([]string{"user:1", "v", "user:2", "v", "item:1", "v"}, store)
+				evalSADD([]string{"user:set", "a"}, store)
+			},
+			input: []string{"0", "MATCH", "user:*", "TYPE", "string"},
+			validator: func(output []byte) {
+				keys := scanAll([]string{"MATCH", "user:*", "TYPE", "string"}, nil)
+				sort.Strings(keys)
+				assert.DeepEqual(t, []string{"user:1", "user:2"}, keys)
+			},
+		},
+		"SCAN returns each key present for the whole scan exactly once": {
+			setup: func() {
+				for i := 0; i < 100; i++ {
+					evalSET([]string{fmt.Sprintf("key:%03d", i), "v"}, store)
+				}
+			},
+			input: []string{"0", "COUNT", "7"},
+			validator: func(output []byte) {
+				deleted := 0
+				added := 0
+				keys := scanAll([]string{"COUNT", "7"}, func() {
+					// mutate the keyspace between the calls
+					store.Del(fmt.Sprintf("key:%03d", 99-deleted))
+					deleted++
+					for i := 0; i < 10; i++ {
+						evalSET([]string{fmt.Sprintf("new:%03d", added), "v"}, store)
+						added++
+					}
+				})
+
+				seen := make(map[string]int)
+				for _, k := range keys {
+					seen[k]++
+					assert.Equal(t, 1, seen[k], "key returned twice: %s", k)
+				}
+				for i := 0; i < 100-deleted; i++ {
+					assert.Equal(t, 1, seen[fmt.Sprintf("key:%03d", i)])
+				}
+			},
+		},
+		"SCAN with SNAPSHOT returns each key exactly once": {
+			setup: func() {
+				for i := 0; i < 100; i++ {
+					evalSET([]string{fmt.Sprintf("key:%03d", i), "v"}, store)
+				}
+			},
+			input: []string{"0", "COUNT", "7", "SNAPSHOT"},
+			validator: func(output []byte) {
+				deleted := 0
+				added := 0
+				keys := scanAll([]string{"COUNT", "7", "SNAPSHOT"}, func() {
+					// mutate the keyspace between the calls
+					store.Del(fmt.Sprintf("key:%03d", 99-deleted))
+					deleted++
+					evalSET([]string{fmt.Sprintf("new:%03d", added), "v"}, store)
+					added++
+				})
+
+				seen := make(map[string]int)
+				for _, k := range keys {
+					seen[k]++
+					assert.Equal(t, 1, seen[k], "key returned twice: %s", k)
+					assert.Assert(t, !strings.HasPrefix(k, "new:"), "key added during the scan: %s", k)
+				}
+				// the deleted keys may have been returned before their deletion
+				for i := 0; i < 100-deleted; i++ {
+					assert.Equal(t, 1, seen[fmt.Sprintf("key:%03d", i)])
+				}
+			},
+		},
+	}
+
+	runEvalTests(t, tests, evalSCAN, store)
+}
+
+func testEvalCONFIG(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"no subcommand": {
+			input:  []string{},
+			output: []byte("-ERR wrong number of arguments for 'config' command\r\n"),
+		},
+		"unknown subcommand": {
+			input:  []string{"FOO"},
+			output: []byte("-ERR unknown subcommand 'FOO'. Try CONFIG HELP.\r\n"),
+		},
+		"RELOAD with extra arguments": {
+			input:  []string{"RELOAD", "now"},
+			output: []byte("-ERR wrong number of arguments for 'config|reload' command\r\n"),
+		},
+		"RELOAD without config file": {
+			input:  []string{"reload"},
+			output: []byte("-ERR no config file loaded, nothing to reload\r\n"),
+		},
+	}
+
+	runEvalTests(t, tests, evalCONFIG, store)
+}
@@ -0,0 +1,103 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// EXECBATCH carries several commands, run back-to-back by the shard holding
+// their keys with no other command interleaved, without the round trips of
+// MULTI and EXEC. The commands are all checked before any of them runs, so
+// that a batch holding an invalid command runs none of them. As with EXEC,
+// the commands failing at runtime do not roll back the others.
+
+// MaxExecBatchCommands is the maximum number of commands of an EXECBATCH.
+const MaxExecBatchCommands = 1000
+
+// execBatchDenied are the commands that cannot run in an EXECBATCH, as they
+// change the state of the client or of the connection rather than the keys.
+var execBatchDenied = map[string]bool{
+	"MULTI":       true,
+	"EXEC":        true,
+	"DISCARD":     true,
+	"EXECBATCH":   true,
+	"ABORT":       true,
+	"AUTH":        true,
+	"SUBSCRIBE":   true,
+	"UNSUBSCRIBE": true,
+	"QWATCH":      true,
+	"QUNWATCH":    true,
+	"ZWATCH":      true,
+	"ZUNWATCH":    true,
+	"CLIENT":      true,
+}
+
+// ParseExecBatch returns the commands carried by the arguments of EXECBATCH,
+// or an encoded error if one of them cannot run.
+//
+// Usage: EXECBATCH numcommands numargs command [arg ...] [numargs command [arg ...] ...]
+func ParseExecBatch(args []string) ([]*cmd.DiceDBCmd, []byte) {
+	if len(args) < 3 {
+		return nil, diceerrors.NewErrArity("EXECBATCH")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	if n < 1 {
+		return nil, diceerrors.NewErrWithMessage("numcommands should be greater than 0")
+	}
+	if n > MaxExecBatchCommands {
+		return nil, diceerrors.NewErrWithFormattedMessage("EXECBATCH holds at most %d commands", MaxExecBatchCommands)
+	}
+
+	cmds := make([]*cmd.DiceDBCmd, 0, n)
+	i := 1
+	for len(cmds) < n {
+		if i >= len(args) {
+			return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		numArgs, err := strconv.Atoi(args[i])
+		if err != nil {
+			return nil, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if numArgs < 1 || numArgs > len(args)-i-1 {
+			return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+
+		c := &cmd.DiceDBCmd{Cmd: strings.ToUpper(args[i+1]), Args: args[i+2 : i+1+numArgs]}
+		if errResp := checkExecBatchCommand(c); errResp != nil {
+			return nil, errResp
+		}
+		cmds = append(cmds, c)
+		i += 1 + numArgs
+	}
+	if i != len(args) {
+		return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	return cmds, nil
+}
+
+func checkExecBatchCommand(c *cmd.DiceDBCmd) []byte {
+	diceCmd, ok := DiceCmds[c.Cmd]
+	if !ok {
+		return errUnknownCommand(c)
+	}
+	if execBatchDenied[c.Cmd] || diceCmd.BlockingEval != nil {
+		return diceerrors.NewErrWithFormattedMessage("%s is not allowed in EXECBATCH", c.Cmd)
+	}
+	if !diceCmd.checkArity(c.Args) {
+		return diceerrors.NewErrArity(diceCmd.Name)
+	}
+	return nil
+}
+
+// evalEXECBATCH is only reached by the servers that do not run the batches,
+// EXECBATCH being handled by the server itself.
+func evalEXECBATCH(args []string, store *dstore.Store) []byte {
+	return diceerrors.NewErrWithMessage("EXECBATCH is not supported by this server")
+}
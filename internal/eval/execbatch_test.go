@@ -0,0 +1,41 @@
+package eval
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+func TestParseExecBatch(t *testing.T) {
+	cmds, errResp := ParseExecBatch([]string{"3", "3", "set", "k", "v", "1", "PING", "2", "GET", "k"})
+	assert.Assert(t, errResp == nil, string(errResp))
+	assert.DeepEqual(t, []*cmd.DiceDBCmd{
+		{Cmd: "SET", Args: []string{"k", "v"}},
+		{Cmd: "PING", Args: []string{}},
+		{Cmd: "GET", Args: []string{"k"}},
+	}, cmds)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"1", "1"}, "-ERR wrong number of arguments for 'execbatch' command\r\n"},
+		{[]string{"2", "1", "PING"}, "-ERR syntax error\r\n"},
+		{[]string{"1", "2", "PING"}, "-ERR syntax error\r\n"},
+		{[]string{"1", "0", "PING"}, "-ERR syntax error\r\n"},
+		{[]string{"1", "1", "PING", "2"}, "-ERR syntax error\r\n"},
+		{[]string{"-1", "1", "PING"}, "-ERR numcommands should be greater than 0\r\n"},
+		{[]string{"1", "one", "PING"}, "-ERR value is not an integer or out of range\r\n"},
+		{[]string{"1", "2", "EXECBATCH", "1"}, "-ERR EXECBATCH is not allowed in EXECBATCH\r\n"},
+		{[]string{"1", "3", "BZPOPMIN", "z", "0"}, "-ERR BZPOPMIN is not allowed in EXECBATCH\r\n"},
+		{[]string{"1", "2", "CLIENT", "LIST"}, "-ERR CLIENT is not allowed in EXECBATCH\r\n"},
+		{[]string{"1", "2", "SET", "k"}, "-ERR wrong number of arguments for 'set' command\r\n"},
+	}
+	for _, tt := range tests {
+		cmds, errResp := ParseExecBatch(tt.args)
+		assert.Assert(t, cmds == nil)
+		assert.Equal(t, tt.want, string(errResp), "%v", tt.args)
+	}
+}
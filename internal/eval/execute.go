@@ -14,7 +14,74 @@ import (
 func ExecuteCommand(c *cmd.DiceDBCmd, client *comm.Client, store *dstore.Store, httpOp, websocketOp bool) *EvalResponse {
 	diceCmd, ok := DiceCmds[c.Cmd]
 	if !ok {
-		return &EvalResponse{Result: diceerrors.NewErrWithFormattedMessage("unknown command '%s', with args beginning with: %s", c.Cmd, strings.Join(c.Args, " ")), Error: nil}
+		return &EvalResponse{Result: errUnknownCommand(c), Error: nil}
+	}
+
+	if !diceCmd.checkArity(c.Args) {
+		if diceCmd.IsMigrated {
+			return &EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount(diceCmd.Name)}
+		}
+		return &EvalResponse{Result: diceerrors.NewErrArity(diceCmd.Name), Error: nil}
+	}
+
+	// the dataset of a replica is only modified by the replication stream,
+	// whichever way the command reached the shard
+	if diceCmd.IsWrite && store.ReadOnly() {
+		if diceCmd.IsMigrated {
+			return &EvalResponse{Result: nil, Error: diceerrors.ErrReadOnly}
+		}
+		return &EvalResponse{Result: diceerrors.NewErrWithMessage(diceerrors.ReadOnlyErr), Error: nil}
+	}
+
+	// the write commands may only use more memory once enough keys are evicted
+	// to get below maxmemory, see Store.FreeMemory
+	if diceCmd.IsWrite && !diceCmd.FreesMemory && !store.FreeMemory() {
+		if diceCmd.IsMigrated {
+			return &EvalResponse{Result: nil, Error: diceerrors.ErrOOM}
+		}
+		return &EvalResponse{Result: diceerrors.NewErrWithMessage(diceerrors.OOMErr), Error: nil}
+	}
+
+	// the views of the keys a command writes are computed again when read next,
+	// and the keys are dumped again by the next AOF rewrite, as the command may
+	// modify the objects of the keys in place. Their expiry is indexed again once
+	// the command is done, as it may have been changed in place as well.
+	if diceCmd.IsWrite {
+		keys := diceCmd.KeySpecs.keys(c.Args)
+		store.MarkDirty(keys)
+		if store.HasViews() {
+			store.MarkViewsStale(keys)
+		}
+		defer store.IndexExpiries(keys)
+
+		// the values the command decompresses or writes are compressed once it
+		// is done
+		store.BeginWrite()
+		defer store.EndWrite()
+
+		// the watches of the keys the command puts or deletes are touched by the
+		// store, the ones of the keys it modifies in place once it is done
+		if watched := store.WatchedKeys(keys); len(watched) > 0 {
+			defer touchModifiedWatches(watched, valueDigests(watched, store), store)
+		}
+	}
+
+	var blockingResp *EvalResponse
+	if diceCmd.BlockingEval != nil {
+		result, blocked := diceCmd.BlockingEval(c.Args, store)
+		if blocked != nil {
+			// a blocked command modifies nothing, hence wakes up no other client
+			return &EvalResponse{Result: clientio.RespNIL, Error: nil, Blocked: blocked}
+		}
+		blockingResp = &EvalResponse{Result: result, Error: nil}
+	}
+
+	// the clients blocked on the keys a command writes are served once it is done
+	if diceCmd.IsWrite && store.HasWaiters() {
+		store.SignalKeysReady(diceCmd.KeySpecs.keys(c.Args))
+	}
+	if blockingResp != nil {
+		return blockingResp
 	}
 
 	// Till the time we refactor to handle QWATCH differently for websocket
@@ -43,11 +110,61 @@ func ExecuteCommand(c *cmd.DiceDBCmd, client *comm.Client, store *dstore.Store,
 		return &EvalResponse{Result: EvalQWATCH(c.Args, httpOp, client, store), Error: nil}
 	case "UNSUBSCRIBE", "QUNWATCH":
 		return &EvalResponse{Result: EvalQUNWATCH(c.Args, httpOp, client), Error: nil}
+	case "ZWATCH":
+		return &EvalResponse{Result: EvalZWATCH(c.Args, client, store), Error: nil}
+	case "ZUNWATCH":
+		return &EvalResponse{Result: EvalZUNWATCH(c.Args, client, store), Error: nil}
 	case auth.Cmd:
 		return &EvalResponse{Result: EvalAUTH(c.Args, client), Error: nil}
+	case "SELECT":
+		return &EvalResponse{Result: EvalSELECT(c.Args, client), Error: nil}
+	case "WATCH":
+		return &EvalResponse{Result: EvalWATCH(c.Args, client, store), Error: nil}
+	case "UNWATCH":
+		return &EvalResponse{Result: EvalUNWATCH(c.Args, client), Error: nil}
 	case "ABORT":
 		return &EvalResponse{Result: clientio.RespOK, Error: nil}
 	default:
 		return &EvalResponse{Result: diceCmd.Eval(c.Args, store), Error: nil}
 	}
 }
+
+// IsWriteCommand returns true if the command may modify the keyspace.
+func IsWriteCommand(name string) bool {
+	diceCmd, ok := DiceCmds[name]
+	return ok && diceCmd.IsWrite
+}
+
+// IsReadOnlyCommand returns true if the command only reads the keyspace.
+func IsReadOnlyCommand(name string) bool {
+	diceCmd, ok := DiceCmds[name]
+	return ok && diceCmd.IsReadOnly
+}
+
+// CommandKeys returns the keys of the invocation of a command, as told by the
+// key specs of the command. It returns nil for the unknown commands.
+func CommandKeys(c *cmd.DiceDBCmd) []string {
+	diceCmd, ok := DiceCmds[c.Cmd]
+	if !ok {
+		return nil
+	}
+	return diceCmd.KeySpecs.keys(c.Args)
+}
+
+// maxUnknownCommandArgsLen is the length beyond which the arguments of an
+// unknown command are left out of its error.
+const maxUnknownCommandArgsLen = 128
+
+// errUnknownCommand returns the error of an unknown command, quoting its first
+// arguments like Redis does.
+func errUnknownCommand(c *cmd.DiceDBCmd) []byte {
+	var args strings.Builder
+	for _, arg := range c.Args {
+		if args.Len() >= maxUnknownCommandArgsLen {
+			break
+		}
+		arg = arg[:min(len(arg), maxUnknownCommandArgsLen-args.Len())]
+		args.WriteString("'" + arg + "' ")
+	}
+	return diceerrors.NewErrWithFormattedMessage("unknown command '%.128s', with args beginning with: %s", c.Cmd, args.String())
+}
@@ -0,0 +1,191 @@
+package eval
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestExecuteCommandArity(t *testing.T) {
+	store := dstore.NewStore(nil)
+	tests := map[string]struct {
+		cmd      *cmd.DiceDBCmd
+		expected string
+	}{
+		"missing arguments":   {&cmd.DiceDBCmd{Cmd: "HGET", Args: []string{"key"}}, "-ERR wrong number of arguments for 'hget' command\r\n"},
+		"extra arguments":     {&cmd.DiceDBCmd{Cmd: "LLEN", Args: []string{"key", "extra"}}, "-ERR wrong number of arguments for 'llen' command\r\n"},
+		"below minimum":       {&cmd.DiceDBCmd{Cmd: "BITOP", Args: []string{"AND", "dest"}}, "-ERR wrong number of arguments for 'bitop' command\r\n"},
+		"unknown command":     {&cmd.DiceDBCmd{Cmd: "FOO", Args: []string{"bar", "baz"}}, "-ERR unknown command 'FOO', with args beginning with: 'bar' 'baz' \r\n"},
+		"unknown no argument": {&cmd.DiceDBCmd{Cmd: "FOO"}, "-ERR unknown command 'FOO', with args beginning with: \r\n"},
+		"unknown long argument": {
+			&cmd.DiceDBCmd{Cmd: "FOO", Args: []string{strings.Repeat("a", 200), "bar"}},
+			"-ERR unknown command 'FOO', with args beginning with: '" + strings.Repeat("a", 128) + "' \r\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := ExecuteCommand(tc.cmd, nil, store, false, false)
+			assert.Equal(t, tc.expected, string(resp.Result.([]byte)))
+		})
+	}
+
+	// the migrated commands report the error in the response
+	resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"a", "b"}}, nil, store, false, false)
+	assert.Error(t, resp.Error, "ERR wrong number of arguments for 'GET' command")
+}
+
+func TestExecuteCommandOOM(t *testing.T) {
+	store := dstore.NewStore(nil)
+	defer func(maxMemory int64, policy string) {
+		config.DiceConfig.Server.MaxMemory = maxMemory
+		config.DiceConfig.Server.EvictionPolicy = policy
+		// the memory limit of the runtime is set back along with maxmemory
+		store.FreeMemory()
+	}(config.DiceConfig.Server.MaxMemory, config.DiceConfig.Server.EvictionPolicy)
+
+	ExecuteCommand(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}, nil, store, false, false)
+	ExecuteCommand(&cmd.DiceDBCmd{Cmd: "LPUSH", Args: []string{"l", "v"}}, nil, store, false, false)
+
+	// the process uses more than a byte whatever the keys, once the live heap is
+	// measured by a garbage collection
+	runtime.GC()
+	config.DiceConfig.Server.MaxMemory = 1
+	config.DiceConfig.Server.EvictionPolicy = config.EvictNoEviction
+
+	resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v2"}}, nil, store, false, false)
+	assert.Error(t, resp.Error, "OOM command not allowed when used memory > 'maxmemory'.")
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "LPUSH", Args: []string{"l", "v2"}}, nil, store, false, false)
+	assert.Equal(t, "-OOM command not allowed when used memory > 'maxmemory'.\r\n", string(resp.Result.([]byte)))
+
+	// the reads and the commands deleting data are still served
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "LLEN", Args: []string{"l"}}, nil, store, false, false)
+	assert.Equal(t, ":1\r\n", string(resp.Result.([]byte)))
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "DEL", Args: []string{"k", "l"}}, nil, store, false, false)
+	assert.Equal(t, ":2\r\n", string(resp.Result.([]byte)))
+
+	// the keys are evicted to make room with any other policy
+	config.DiceConfig.Server.MaxMemory = 0
+	ExecuteCommand(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}, nil, store, false, false)
+	evicted := dstore.EvictedKeys()
+	config.DiceConfig.Server.MaxMemory = 1
+	config.DiceConfig.Server.EvictionPolicy = config.EvictAllKeysLRU
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "LPUSH", Args: []string{"l", "v"}}, nil, store, false, false)
+	assert.Equal(t, "-OOM command not allowed when used memory > 'maxmemory'.\r\n", string(resp.Result.([]byte)))
+	assert.Equal(t, 0, store.GetKeyCount())
+	assert.Equal(t, evicted+1, dstore.EvictedKeys())
+}
+
+func TestExecuteCommandReadOnly(t *testing.T) {
+	store := dstore.NewStore(nil)
+	ExecuteCommand(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}, nil, store, false, false)
+	store.SetReplica(true)
+
+	// the writes are refused, whichever way they reached the shard
+	resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v2"}}, nil, store, false, false)
+	assert.Error(t, resp.Error, "READONLY You can't write against a read only replica.")
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "LPUSH", Args: []string{"l", "v"}}, nil, store, false, false)
+	assert.Equal(t, "-READONLY You can't write against a read only replica.\r\n", string(resp.Result.([]byte)))
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}, nil, store, false, false)
+	assert.Equal(t, "v", resp.Result)
+
+	// and accepted from the replication stream
+	store.SetReplicating(true)
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v2"}}, nil, store, false, false)
+	assert.NilError(t, resp.Error)
+	store.SetReplicating(false)
+	resp = ExecuteCommand(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}, nil, store, false, false)
+	assert.Equal(t, "v2", resp.Result)
+}
+
+func TestCommandsMetadata(t *testing.T) {
+	for name, diceCmd := range DiceCmds {
+		assert.Equal(t, name, diceCmd.Name)
+		assert.Assert(t, diceCmd.Arity != 0, "%s has no arity", name)
+		assert.Assert(t, !diceCmd.IsWrite || !diceCmd.IsReadOnly, "%s is both a write and a read-only command", name)
+	}
+}
+
+func TestReadOnlyCommands(t *testing.T) {
+	assert.Assert(t, IsReadOnlyCommand("GET"))
+	assert.Assert(t, IsReadOnlyCommand("SCAN"))
+	assert.Assert(t, !IsReadOnlyCommand("SET"))
+	assert.Assert(t, !IsReadOnlyCommand("RESTORE"))
+	assert.Assert(t, !IsReadOnlyCommand("PING"))
+	assert.Assert(t, !IsReadOnlyCommand("UNKNOWN"))
+
+	assert.DeepEqual(t, []interface{}{"GET", 2, 1, 0, 0, []string{"readonly"}}, convertCmdMetaToSlice(&getCmdMeta))
+	assert.DeepEqual(t, []string{"write"}, commandFlags(&restorekeyCmdMeta))
+	assert.DeepEqual(t, []string{}, commandFlags(&pingCmdMeta))
+}
+
+func TestCommandKeys(t *testing.T) {
+	keys := func(name string, args ...string) []string {
+		return CommandKeys(&cmd.DiceDBCmd{Cmd: name, Args: args})
+	}
+
+	assert.DeepEqual(t, []string{"k"}, keys("GET", "k"))
+	assert.DeepEqual(t, []string{"a", "b"}, keys("MSET", "a", "1", "b", "2"))
+	assert.DeepEqual(t, []string{"a", "b"}, keys("BLPOP", "a", "b", "0"))
+	assert.DeepEqual(t, []string{"src", "dst"}, keys("RENAME", "src", "dst"))
+	assert.Equal(t, 0, len(keys("PING")))
+	assert.Equal(t, 0, len(keys("UNKNOWN", "k")))
+}
+
+func TestExecuteCommandMarksDirty(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) {
+		ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+
+	exec("HSET", "hash", "f", "v")
+	exec("ZADD", "zset", "1", "m")
+	dirty := store.TrackDirty()
+	dirty.Take()
+
+	// the objects modified in place are dirty as well, the reads are not
+	exec("HSET", "hash", "f", "w")
+	exec("ZADD", "zset", "2", "m")
+	exec("HGET", "hash", "f")
+	exec("ZRANGE", "zset", "0", "-1")
+	taken := dirty.Take()
+	sort.Strings(taken.Keys)
+	assert.DeepEqual(t, dstore.DirtyKeys{Keys: []string{"hash", "zset"}}, taken)
+}
+
+func TestExecuteCommandTouchesWatches(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) {
+		ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+	touched := func(key string, name string, args ...string) bool {
+		w := dstore.NewKeyWatch()
+		defer w.Unwatch()
+		store.Watch(w, key)
+		exec(name, args...)
+		return w.Touched()
+	}
+
+	exec("SADD", "set", "a", "b")
+	exec("HSET", "hash", "f", "v")
+	exec("RPUSH", "list", "x")
+
+	// the write commands touch the watches of the keys they modify in place
+	assert.Assert(t, touched("set", "SADD", "set", "c"))
+	assert.Assert(t, touched("hash", "HSET", "hash", "f", "w"))
+	assert.Assert(t, touched("list", "RPUSH", "list", "y"))
+	assert.Assert(t, touched("set", "EXPIRE", "set", "100"))
+	assert.Assert(t, touched("hash", "HEXPIRE", "hash", "100", "FIELDS", "1", "f"))
+
+	// and not the ones of the keys they leave alone
+	assert.Assert(t, !touched("set", "SADD", "set", "a"))
+	assert.Assert(t, !touched("hash", "HDEL", "hash", "missing"))
+	assert.Assert(t, !touched("list", "LREM", "list", "0", "z"))
+	assert.Assert(t, !touched("set", "SADD", "other", "a"))
+}
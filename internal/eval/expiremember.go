@@ -0,0 +1,185 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The members of the sets and of the sorted sets can expire on their own, like
+// the fields of the hashes, e.g. for the sets of the users online without a key
+// per user. The store keeps their expiry along with the collection, see
+// dstore.SetFieldExpiry, the members being deleted once read after they expired
+// or by the active expiry of the fields. The expiry of a member is removed once
+// it is added again after being deleted, by SADD, ZADD or ZINCRBY, while
+// updating an existing member keeps it.
+
+// expireSetMembers is the dstore.ExpireFieldsFunc of the sets.
+func expireSetMembers(obj *object.Obj, members []string) bool {
+	set := obj.Value.(map[string]struct{})
+	for _, member := range members {
+		delete(set, member)
+	}
+	return len(set) == 0
+}
+
+// expireSortedSetMembers is the dstore.ExpireFieldsFunc of the sorted sets.
+func expireSortedSetMembers(obj *object.Obj, members []string) bool {
+	tree, memberMap, _ := getSortedSet(obj)
+	for _, member := range members {
+		score, ok := memberMap[member]
+		if !ok {
+			continue
+		}
+		tree.Delete(&SortedSetItem{Score: score, Member: member})
+		delete(memberMap, member)
+	}
+	return len(memberMap) == 0
+}
+
+// memberExpiry returns whether member is a field of the hash, or a member of
+// the set or the sorted set, held by obj, along with the function deleting the
+// members of obj once they expire. It returns an encoded error if obj holds
+// another type.
+func memberExpiry(obj *object.Obj, member string) (bool, dstore.ExpireFieldsFunc, []byte) {
+	var ok bool
+	switch object.GetType(obj.TypeEncoding) {
+	case object.ObjTypeHashMap:
+		_, ok = obj.Value.(HashMap)[member]
+		return ok, expireHashFields, nil
+	case object.ObjTypeSet:
+		_, ok = obj.Value.(map[string]struct{})[member]
+		return ok, expireSetMembers, nil
+	case object.ObjTypeSortedSet:
+		_, memberMap, errResp := getSortedSet(obj)
+		if errResp != nil {
+			return false, nil, errResp
+		}
+		_, ok = memberMap[member]
+		return ok, expireSortedSetMembers, nil
+	default:
+		return false, nil, diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
+}
+
+// expireMemberGeneric sets the expiry of member in the collection stored at key
+// to expireAtMs, in unix-time-milliseconds, deleting it right away if the expiry
+// is in the past. It returns 1 if the expiry is set, 0 if the member or the key
+// does not exist.
+func expireMemberGeneric(key, member string, expireAtMs int64, store *dstore.Store) []byte {
+	obj := store.Get(key)
+	if obj == nil {
+		return clientio.RespZero
+	}
+	exists, expire, errResp := memberExpiry(obj, member)
+	if errResp != nil {
+		return errResp
+	}
+	if !exists {
+		return clientio.RespZero
+	}
+
+	if expireAtMs > utils.GetCurrentTime().UnixMilli() {
+		store.SetFieldExpiry(key, member, uint64(expireAtMs), expire)
+		return clientio.RespOne
+	}
+	store.DelFieldExpiry(key, member)
+	if expire(obj, []string{member}) {
+		store.Del(key)
+	} else {
+		store.Put(key, obj)
+	}
+	return clientio.RespOne
+}
+
+// parseMemberExpiry parses the time of the member expiry commands, as an integer
+// no greater than limit.
+func parseMemberExpiry(name, arg string, limit int64) (int64, []byte) {
+	t, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	if t > limit || t < -limit {
+		return 0, diceerrors.NewErrExpireTime(name)
+	}
+	return t, nil
+}
+
+// evalEXPIREMEMBER sets the expiry of member, in the set, the sorted set or the
+// hash stored at key, to ttl from now, in seconds, or in milliseconds with the
+// ms unit. A ttl of 0 or less deletes the member, the key being deleted once
+// empty. Returns 1 if the expiry is set, 0 if the member or the key does not
+// exist.
+//
+// Usage: EXPIREMEMBER key member ttl [s | ms]
+func evalEXPIREMEMBER(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 && len(args) != 4 {
+		return diceerrors.NewErrArity("EXPIREMEMBER")
+	}
+
+	unit := int64(1000)
+	if len(args) == 4 {
+		switch strings.ToLower(args[3]) {
+		case "s":
+		case "ms":
+			unit = 1
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+	ttl, errResp := parseMemberExpiry("EXPIREMEMBER", args[2], maxExDuration*1000/unit)
+	if errResp != nil {
+		return errResp
+	}
+	return expireMemberGeneric(args[0], args[1], utils.GetCurrentTime().UnixMilli()+ttl*unit, store)
+}
+
+// evalEXPIREMEMBERAT is like EXPIREMEMBER, the expiry being given as a unix
+// timestamp in seconds.
+//
+// Usage: EXPIREMEMBERAT key member unix-time-seconds
+func evalEXPIREMEMBERAT(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("EXPIREMEMBERAT")
+	}
+	sec, errResp := parseMemberExpiry("EXPIREMEMBERAT", args[2], maxExDuration)
+	if errResp != nil {
+		return errResp
+	}
+	return expireMemberGeneric(args[0], args[1], sec*1000, store)
+}
+
+// evalPEXPIREMEMBERAT is like EXPIREMEMBER, the expiry being given as a unix
+// timestamp in milliseconds.
+//
+// Usage: PEXPIREMEMBERAT key member unix-time-milliseconds
+func evalPEXPIREMEMBERAT(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("PEXPIREMEMBERAT")
+	}
+	ms, errResp := parseMemberExpiry("PEXPIREMEMBERAT", args[2], maxExDuration*1000)
+	if errResp != nil {
+		return errResp
+	}
+	return expireMemberGeneric(args[0], args[1], ms, store)
+}
+
+// ExpiredMembersCmd returns the command deleting the members of obj, stored at
+// key, that expired: HDEL for the fields of a hash, SREM for the members of a
+// set and ZREM for the ones of a sorted set.
+func ExpiredMembersCmd(key string, obj *object.Obj, members []string) *cmd.DiceDBCmd {
+	name := "HDEL"
+	switch object.GetType(obj.TypeEncoding) {
+	case object.ObjTypeSet:
+		name = "SREM"
+	case object.ObjTypeSortedSet:
+		name = "ZREM"
+	}
+	return &cmd.DiceDBCmd{Cmd: name, Args: append([]string{key}, members...)}
+}
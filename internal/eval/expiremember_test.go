@@ -0,0 +1,94 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestMemberExpiry(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	store := dstore.NewStore(nil)
+	evalSADD([]string{"online", "alice", "bob", "carol"}, store)
+	evalZADD([]string{"z", "1", "a", "2", "b"}, store)
+
+	assert.Equal(t, ":1\r\n", string(evalEXPIREMEMBER([]string{"online", "alice", "10"}, store)))
+	assert.Equal(t, ":1\r\n", string(evalEXPIREMEMBER([]string{"online", "bob", "20500", "ms"}, store)))
+	assert.Equal(t, ":0\r\n", string(evalEXPIREMEMBER([]string{"online", "dave", "10"}, store)))
+	assert.Equal(t, ":0\r\n", string(evalEXPIREMEMBER([]string{"missing", "alice", "10"}, store)))
+	assert.Equal(t, ":1\r\n", string(evalEXPIREMEMBERAT([]string{"z", "a", "1010"}, store)))
+	assert.Equal(t, ":1\r\n", string(evalPEXPIREMEMBERAT([]string{"z", "b", "1030000"}, store)))
+	exp, ok := store.FieldExpiry("online", "bob")
+	assert.Assert(t, ok)
+	assert.Equal(t, uint64(1020500), exp)
+
+	// updating a member keeps its expiry, adding it again once deleted does not
+	evalSADD([]string{"online", "alice"}, store)
+	evalZADD([]string{"z", "5", "b"}, store)
+	_, ok = store.FieldExpiry("online", "alice")
+	assert.Assert(t, ok)
+	_, ok = store.FieldExpiry("z", "b")
+	assert.Assert(t, ok)
+	evalSREM([]string{"online", "bob"}, store)
+	evalSADD([]string{"online", "bob"}, store)
+	_, ok = store.FieldExpiry("online", "bob")
+	assert.Assert(t, !ok)
+
+	// the members that expired are deleted when the collection is read
+	mockTime.SetTime(time.Unix(1015, 0))
+	assert.Equal(t, ":2\r\n", string(evalSCARD([]string{"online"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]string{"b"}, false), evalZRANGE([]string{"z", "0", "-1"}, store))
+
+	// an expiry in the past deletes the members, and the key once empty
+	assert.Equal(t, ":1\r\n", string(evalEXPIREMEMBER([]string{"z", "b", "0"}, store)))
+	assert.Assert(t, store.Get("z") == nil)
+	assert.Equal(t, ":1\r\n", string(evalEXPIREMEMBERAT([]string{"online", "bob", "900"}, store)))
+	assert.Equal(t, ":1\r\n", string(evalSCARD([]string{"online"}, store)))
+
+	// the members that expired are deleted by the replicas as told by the primary
+	assert.DeepEqual(t, []string{"online", "carol"}, ExpiredMembersCmd("online", store.Get("online"), []string{"carol"}).Args)
+	assert.Equal(t, "SREM", ExpiredMembersCmd("online", store.Get("online"), nil).Cmd)
+
+	tests := []struct {
+		got  []byte
+		want string
+	}{
+		{evalEXPIREMEMBER([]string{"online", "carol"}, store), "-ERR wrong number of arguments for 'expiremember' command\r\n"},
+		{evalEXPIREMEMBER([]string{"online", "carol", "10", "m"}, store), "-ERR syntax error\r\n"},
+		{evalEXPIREMEMBER([]string{"online", "carol", "ten"}, store), "-ERR value is not an integer or out of range\r\n"},
+		{evalEXPIREMEMBER([]string{"online", "carol", "9223372036854775807"}, store), "-ERR invalid expire time in 'expiremember' command\r\n"},
+		{evalPEXPIREMEMBERAT([]string{"online", "carol"}, store), "-ERR wrong number of arguments for 'pexpirememberat' command\r\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(tt.got))
+	}
+}
+
+func TestExportMemberExpiries(t *testing.T) {
+	utils.CurrentTime = &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	src := dstore.NewStore(nil)
+	evalZADD([]string{"z", "1", "a", "2", "b"}, src)
+	evalEXPIREMEMBER([]string{"z", "a", "100"}, src)
+
+	cmds, err := exportKey("z", src.Get("z"), src, 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"EXPIREMEMBERAT", "z", "a", "1100"}, cmds[len(cmds)-1])
+
+	dst := dstore.NewStore(nil)
+	for _, c := range cmds {
+		DiceCmds[c[0]].Eval(c[1:], dst)
+	}
+	exp, ok := dst.FieldExpiry("z", "a")
+	assert.Assert(t, ok)
+	assert.Equal(t, uint64(1100000), exp)
+}
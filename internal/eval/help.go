@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The HELP replies are generated from the Info of the commands, whose first
+// line is the syntax of the command, the following lines describing it. The
+// Info of a container command, e.g. CLIENT, gives the syntax of each of its
+// subcommands on a line of its own, followed by their description.
+
+// helpFamilyPrefixes are the prefixes of the command families getting a
+// <family>.HELP command besides the ones of their dotted names, e.g. CMS for
+// CMS.INCRBY, as the bloom filter commands predate the dotted names.
+var helpFamilyPrefixes = []string{"BF"}
+
+// helpLines returns the lines of info, each one trimmed, without the empty ones.
+func helpLines(info string) []string {
+	var lines []string
+	for _, line := range strings.Split(info, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// commandHelp returns the reply of the HELP subcommand of the container command
+// name: the syntax of each of its subcommands, followed by their description.
+func commandHelp(name string) []byte {
+	help := []string{name + " <subcommand> [<arg> [value] [opt] ...]. Subcommands are:"}
+	for _, line := range helpLines(DiceCmds[name].Info) {
+		if subcommand, ok := strings.CutPrefix(line, name+" "); ok {
+			help = append(help, subcommand)
+		} else {
+			help = append(help, "    "+line)
+		}
+	}
+	help = append(help, Help, "    Print this help.")
+	return clientio.Encode(help, false)
+}
+
+// familyHelp returns the reply of the HELP command of the family of commands
+// whose names start with prefix: the syntax of each of them, followed by their
+// description.
+func familyHelp(prefix string) []byte {
+	var names []string
+	for name := range DiceCmds {
+		if strings.HasPrefix(name, prefix) && name != prefix+"."+Help {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	help := []string{prefix + ".<command> <key> [<arg> ...]. Commands are:"}
+	for _, name := range names {
+		lines := helpLines(DiceCmds[name].Info)
+		if len(lines) == 0 || !strings.HasPrefix(lines[0], name) {
+			lines = append([]string{name}, lines...)
+		}
+		help = append(help, lines[0])
+		for _, line := range lines[1:] {
+			help = append(help, "    "+line)
+		}
+	}
+	help = append(help, prefix+"."+Help, "    Print this help.")
+	return clientio.Encode(help, false)
+}
+
+// registerFamilyHelp registers the <family>.HELP command of every family of
+// commands with dotted names, along with the ones of helpFamilyPrefixes.
+func registerFamilyHelp() {
+	prefixes := append([]string(nil), helpFamilyPrefixes...)
+	for name := range DiceCmds {
+		if prefix, _, ok := strings.Cut(name, "."); ok {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	for _, prefix := range prefixes {
+		name := prefix + "." + Help
+		if _, ok := DiceCmds[name]; ok {
+			continue
+		}
+		RegisterCommand(DiceCmdMeta{
+			Name: name,
+			Info: name + `
+		Returns the syntax of the ` + prefix + ` commands along with their description.`,
+			Eval: func(args []string, store *dstore.Store) []byte {
+				return cachedReply(name, func() []byte { return familyHelp(prefix) })
+			},
+			Arity: 1,
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func execHelp(t *testing.T, name string, args ...string) []string {
+	t.Helper()
+	resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, dstore.NewStore(nil), false, false)
+	value, err := clientio.NewRESPParser(bytes.NewBuffer(resp.Result.([]byte))).DecodeOne()
+	assert.NilError(t, err)
+
+	var lines []string
+	for _, line := range value.([]interface{}) {
+		lines = append(lines, line.(string))
+	}
+	return lines
+}
+
+func TestCommandHelp(t *testing.T) {
+	assert.DeepEqual(t, []string{
+		"CONFIG <subcommand> [<arg> [value] [opt] ...]. Subcommands are:",
+		"RELOAD",
+		"    Reads the config file again and applies the settings that can be changed live, without dropping the connections.",
+		"    Returns the settings applied and the changed settings that require a restart to take effect.",
+		"DEFAULTTTL SET pattern ttl",
+		"    Gives the keys matching the pattern an expiry of ttl, a duration such as 30m, once put without one.",
+		"DEFAULTTTL DEL pattern",
+		"    Removes the default TTL policy of the pattern. Returns 1 if it was removed, 0 otherwise.",
+		"DEFAULTTTL LIST",
+		"    Returns the pattern, the TTL in milliseconds and the number of keys given an expiry of every default TTL policy.",
+		"HELP",
+		"    Print this help.",
+	}, execHelp(t, "CONFIG", "HELP"))
+
+	for _, tc := range []struct {
+		name        string
+		subcommands []string
+	}{
+		{"CLIENT", []string{"PAUSE timeout [WRITE|ALL]", "UNPAUSE", "LIST", "TOKEN", "READAFTER token"}},
+		{"LATENCY", []string{"LATEST", "RESET [event ...]"}},
+		{"MEMORY", []string{"STATS"}},
+		{"OBJECT", []string{"IDLETIME key"}},
+		{"XGROUP", []string{"CREATE key group id|$ [MKSTREAM]", "SETID key group id|$", "DESTROY key group"}},
+	} {
+		help := execHelp(t, tc.name, "help")
+		assert.Equal(t, tc.name+" <subcommand> [<arg> [value] [opt] ...]. Subcommands are:", help[0])
+		for _, subcommand := range tc.subcommands {
+			assert.Assert(t, slices.Contains(help, subcommand), "%s HELP lacks %q: %q", tc.name, subcommand, help)
+		}
+		assert.Equal(t, "HELP", help[len(help)-2])
+	}
+}
+
+func TestFamilyHelp(t *testing.T) {
+	help := execHelp(t, "CMS.HELP")
+	assert.Equal(t, "CMS.<command> <key> [<arg> ...]. Commands are:", help[0])
+	assert.Assert(t, slices.Contains(help, "CMS.INCRBY key item increment [item increment ...]"), help)
+	assert.Assert(t, slices.Contains(help, "    Creates a count-min sketch of the given width and depth."), help)
+	assert.Assert(t, slices.Contains(help, "CMS.QUERY key item [item ...] returns the estimated counts of items in a count-min sketch."), help)
+	assert.DeepEqual(t, []string{"CMS.HELP", "    Print this help."}, help[len(help)-2:])
+
+	// the bloom filter commands are listed whether their names are dotted or not
+	help = execHelp(t, "BF.HELP")
+	assert.Equal(t, "BF.CARD key", help[1])
+	assert.Assert(t, slices.ContainsFunc(help, func(line string) bool {
+		return strings.HasPrefix(line, "BFADD adds an element")
+	}), help)
+
+	// the commands whose Info does not start with their syntax are listed by name
+	assert.Assert(t, slices.Contains(execHelp(t, "JSON.HELP"), "JSON.NUMINCRBY"))
+
+	for name := range DiceCmds {
+		if prefix, _, ok := strings.Cut(name, "."); ok {
+			_, registered := DiceCmds[prefix+".HELP"]
+			assert.Assert(t, registered, "%s.HELP is not registered", prefix)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The fields of a hash can expire on their own, the store keeping their expiry
+// along with the hash, see dstore.SetFieldExpiry. The expiry of a field is
+// removed once the field is deleted or overwritten by HSET, while HINCRBY and
+// HINCRBYFLOAT keep it.
+
+// the replies of the hash field expiry commands for each field
+const (
+	hfieldMissing    = -2 // the field does not exist
+	hfieldNoExpiry   = -1 // the field has no expiry
+	hfieldNotSet     = 0  // the condition of the command is not met
+	hfieldSet        = 1  // the expiry is set, or removed by HPERSIST
+	hfieldExpiredNow = 2  // the expiry is in the past, the field is deleted
+)
+
+// expireHashFields is the dstore.ExpireFieldsFunc of the hashes.
+func expireHashFields(obj *object.Obj, fields []string) bool {
+	hashMap := obj.Value.(HashMap)
+	for _, field := range fields {
+		delete(hashMap, field)
+	}
+	return len(hashMap) == 0
+}
+
+// dropFieldExpiries removes the expiry of the fields of the field value pairs
+// set in the hash stored at key.
+func dropFieldExpiries(key string, fieldValuePairs []string, store *dstore.Store) {
+	for i := 0; i < len(fieldValuePairs); i += 2 {
+		store.DelFieldExpiry(key, fieldValuePairs[i])
+	}
+}
+
+// parseHashFields returns the fields of the arguments FIELDS numfields field
+// [field ...] closing the hash field expiry commands.
+func parseHashFields(args []string) ([]string, []byte) {
+	if len(args) < 3 || !strings.EqualFold(args[0], Fields) {
+		return nil, diceerrors.NewErrWithMessage("Mandatory argument FIELDS is missing or not at the right position")
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n <= 0 {
+		return nil, diceerrors.NewErrWithMessage("Parameter `numFields` should be greater than 0")
+	}
+	if n != len(args)-2 {
+		return nil, diceerrors.NewErrWithMessage("The `numfields` parameter must match the number of arguments")
+	}
+	return args[2:], nil
+}
+
+// getHashForFields returns the hash stored at key, nil if the key does not
+// exist.
+func getHashForFields(key string, store *dstore.Store) (*object.Obj, []byte) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, nil
+	}
+	if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeHashMap, object.ObjEncodingHashMap); err != nil {
+		return nil, diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr)
+	}
+	return obj, nil
+}
+
+// missingFields returns the reply of the fields of a missing hash.
+func missingFields(fields []string) []byte {
+	replies := make([]int64, len(fields))
+	for i := range replies {
+		replies[i] = hfieldMissing
+	}
+	return clientio.Encode(replies, false)
+}
+
+// hexpireGeneric sets the expiry of the fields of the hash stored at key, in
+// unix-time-milliseconds as returned by expireAt for the time argument. It
+// returns for each field one of the replies hfieldMissing, hfieldNotSet,
+// hfieldSet and hfieldExpiredNow.
+//
+// Usage: cmd key time [NX | XX | GT | LT] FIELDS numfields field [field ...]
+func hexpireGeneric(name string, args []string, store *dstore.Store, expireAt func(t int64) (int64, bool)) []byte {
+	if len(args) < 5 {
+		return diceerrors.NewErrArity(name)
+	}
+
+	key := args[0]
+	t, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	expireAtMs, ok := expireAt(t)
+	if t < 0 || !ok {
+		return diceerrors.NewErrExpireTime(name)
+	}
+
+	condition, rest := "", args[2:]
+	switch opt := strings.ToUpper(rest[0]); opt {
+	case NX, XX, GT, LT:
+		condition, rest = opt, rest[1:]
+	}
+	fields, errResp := parseHashFields(rest)
+	if errResp != nil {
+		return errResp
+	}
+
+	obj, errResp := getHashForFields(key, store)
+	if errResp != nil {
+		return errResp
+	}
+	if obj == nil {
+		return missingFields(fields)
+	}
+
+	hashMap := obj.Value.(HashMap)
+	now := utils.GetCurrentTime().UnixMilli()
+	replies := make([]int64, len(fields))
+	deleted := 0
+	for i, field := range fields {
+		if _, ok := hashMap[field]; !ok {
+			replies[i] = hfieldMissing
+			continue
+		}
+
+		current, hasExpiry := store.FieldExpiry(key, field)
+		switch {
+		case condition == NX && hasExpiry,
+			condition == XX && !hasExpiry,
+			// a field without expiry never expires, hence has the greatest one
+			condition == GT && (!hasExpiry || uint64(expireAtMs) <= current),
+			condition == LT && hasExpiry && uint64(expireAtMs) >= current:
+			replies[i] = hfieldNotSet
+		case expireAtMs <= now:
+			delete(hashMap, field)
+			store.DelFieldExpiry(key, field)
+			replies[i] = hfieldExpiredNow
+			deleted++
+		default:
+			store.SetFieldExpiry(key, field, uint64(expireAtMs), expireHashFields)
+			replies[i] = hfieldSet
+		}
+	}
+
+	switch {
+	case deleted > 0 && len(hashMap) == 0:
+		store.Del(key)
+	case deleted > 0:
+		store.Put(key, obj)
+	}
+	return clientio.Encode(replies, false)
+}
+
+// evalHEXPIRE sets the expiry of the fields of the hash stored at key to
+// seconds from now. NX sets it only to the fields having no expiry, XX only to
+// the ones having one, GT only if it is greater than the current one and LT
+// only if it is lower, a field without expiry having an infinite one.
+// Returns an array holding for each field:
+//   - -2 if the field, or the key, does not exist
+//   - 0 if the condition is not met
+//   - 1 if the expiry is set
+//   - 2 if seconds is 0, in which case the field is deleted
+//
+// Usage: HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+func evalHEXPIRE(args []string, store *dstore.Store) []byte {
+	return hexpireGeneric("HEXPIRE", args, store, func(sec int64) (int64, bool) {
+		return utils.GetCurrentTime().UnixMilli() + sec*1000, sec <= maxExDuration
+	})
+}
+
+// evalHPEXPIRE is like HEXPIRE, the expiry being given in milliseconds.
+//
+// Usage: HPEXPIRE key milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+func evalHPEXPIRE(args []string, store *dstore.Store) []byte {
+	return hexpireGeneric("HPEXPIRE", args, store, func(ms int64) (int64, bool) {
+		return utils.GetCurrentTime().UnixMilli() + ms, ms <= maxExDuration*1000
+	})
+}
+
+// evalHEXPIREAT is like HEXPIRE, the expiry being given in unix-time-seconds.
+// A time in the past deletes the fields.
+//
+// Usage: HEXPIREAT key unix-time-seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+func evalHEXPIREAT(args []string, store *dstore.Store) []byte {
+	return hexpireGeneric("HEXPIREAT", args, store, func(sec int64) (int64, bool) {
+		return sec * 1000, sec <= maxExDuration
+	})
+}
+
+// evalHPEXPIREAT is like HEXPIRE, the expiry being given in
+// unix-time-milliseconds. A time in the past deletes the fields.
+//
+// Usage: HPEXPIREAT key unix-time-milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+func evalHPEXPIREAT(args []string, store *dstore.Store) []byte {
+	return hexpireGeneric("HPEXPIREAT", args, store, func(ms int64) (int64, bool) {
+		return ms, ms <= maxExDuration*1000
+	})
+}
+
+// httlGeneric returns the time to live of the fields of the hash stored at
+// key, in units of milliseconds, or -2 for the missing fields and -1 for the
+// fields without expiry.
+//
+// Usage: cmd key FIELDS numfields field [field ...]
+func httlGeneric(name string, args []string, store *dstore.Store, unit int64) []byte {
+	if len(args) < 4 {
+		return diceerrors.NewErrArity(name)
+	}
+
+	key := args[0]
+	fields, errResp := parseHashFields(args[1:])
+	if errResp != nil {
+		return errResp
+	}
+	obj, errResp := getHashForFields(key, store)
+	if errResp != nil {
+		return errResp
+	}
+	if obj == nil {
+		return missingFields(fields)
+	}
+
+	hashMap := obj.Value.(HashMap)
+	now := utils.GetCurrentTime().UnixMilli()
+	replies := make([]int64, len(fields))
+	for i, field := range fields {
+		if _, ok := hashMap[field]; !ok {
+			replies[i] = hfieldMissing
+			continue
+		}
+		exp, ok := store.FieldExpiry(key, field)
+		if !ok {
+			replies[i] = hfieldNoExpiry
+			continue
+		}
+		// the fields that expired were deleted by Get, but on the replicas
+		replies[i] = max(int64(exp)-now, 0) / unit
+	}
+	return clientio.Encode(replies, false)
+}
+
+// evalHTTL returns the time to live of the fields of the hash stored at key in
+// seconds, -2 for the fields, or the key, that do not exist and -1 for the
+// fields without expiry.
+//
+// Usage: HTTL key FIELDS numfields field [field ...]
+func evalHTTL(args []string, store *dstore.Store) []byte {
+	return httlGeneric("HTTL", args, store, 1000)
+}
+
+// evalHPTTL is like HTTL, the time to live being in milliseconds.
+//
+// Usage: HPTTL key FIELDS numfields field [field ...]
+func evalHPTTL(args []string, store *dstore.Store) []byte {
+	return httlGeneric("HPTTL", args, store, 1)
+}
+
+// evalHPERSIST removes the expiry of the fields of the hash stored at key.
+// Returns an array holding for each field 1 if its expiry is removed, -1 if it
+// has none and -2 if the field, or the key, does not exist.
+//
+// Usage: HPERSIST key FIELDS numfields field [field ...]
+func evalHPERSIST(args []string, store *dstore.Store) []byte {
+	if len(args) < 4 {
+		return diceerrors.NewErrArity("HPERSIST")
+	}
+
+	key := args[0]
+	fields, errResp := parseHashFields(args[1:])
+	if errResp != nil {
+		return errResp
+	}
+	obj, errResp := getHashForFields(key, store)
+	if errResp != nil {
+		return errResp
+	}
+	if obj == nil {
+		return missingFields(fields)
+	}
+
+	hashMap := obj.Value.(HashMap)
+	replies := make([]int64, len(fields))
+	for i, field := range fields {
+		if _, ok := hashMap[field]; !ok {
+			replies[i] = hfieldMissing
+			continue
+		}
+		if store.DelFieldExpiry(key, field) == 0 {
+			replies[i] = hfieldNoExpiry
+			continue
+		}
+		replies[i] = hfieldSet
+	}
+	return clientio.Encode(replies, false)
+}
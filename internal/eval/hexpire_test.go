@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestHashFieldExpiry(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	store := dstore.NewStore(nil)
+	ints := func(values ...int64) string {
+		return string(clientio.Encode(values, false))
+	}
+	evalHSET([]string{"h", "a", "1", "b", "2", "c", "3"}, store)
+
+	assert.Equal(t, ints(1, 1, -2), string(evalHEXPIRE([]string{"h", "100", "FIELDS", "3", "a", "b", "missing"}, store)))
+	assert.Equal(t, ints(1), string(evalHPEXPIRE([]string{"h", "500000", "FIELDS", "1", "c"}, store)))
+	assert.Equal(t, ints(100, 100, 500, -2), string(evalHTTL([]string{"h", "FIELDS", "4", "a", "b", "c", "missing"}, store)))
+	assert.Equal(t, ints(500000), string(evalHPTTL([]string{"h", "FIELDS", "1", "c"}, store)))
+
+	// the conditions, a field without expiry having an infinite one
+	evalHPERSIST([]string{"h", "FIELDS", "1", "c"}, store)
+	assert.Equal(t, ints(0), string(evalHEXPIRE([]string{"h", "300", "GT", "FIELDS", "1", "c"}, store)))
+	assert.Equal(t, ints(0), string(evalHEXPIRE([]string{"h", "300", "XX", "FIELDS", "1", "c"}, store)))
+	assert.Equal(t, ints(0, 1), string(evalHEXPIRE([]string{"h", "50", "NX", "FIELDS", "2", "a", "c"}, store)))
+	assert.Equal(t, ints(0, 0), string(evalHEXPIRE([]string{"h", "20", "GT", "FIELDS", "2", "a", "c"}, store)))
+	assert.Equal(t, ints(1), string(evalHEXPIRE([]string{"h", "150", "GT", "FIELDS", "1", "a"}, store)))
+	assert.Equal(t, ints(1, 0), string(evalHEXPIRE([]string{"h", "120", "LT", "FIELDS", "2", "a", "b"}, store)))
+	assert.Equal(t, ints(120, 100, 50), string(evalHTTL([]string{"h", "FIELDS", "3", "a", "b", "c"}, store)))
+
+	// HPERSIST, then HSET and HDEL remove the expiries, HINCRBY keeps them
+	assert.Equal(t, ints(1, -1, -2), string(evalHPERSIST([]string{"h", "FIELDS", "3", "a", "a", "missing"}, store)))
+	evalHSET([]string{"h", "b", "3"}, store)
+	evalHINCRBY([]string{"h", "c", "1"}, store)
+	assert.Equal(t, ints(-1, -1, 50), string(evalHTTL([]string{"h", "FIELDS", "3", "a", "b", "c"}, store)))
+	evalHDEL([]string{"h", "c"}, store)
+	evalHSET([]string{"h", "c", "5"}, store)
+	assert.Equal(t, ints(-1), string(evalHTTL([]string{"h", "FIELDS", "1", "c"}, store)))
+
+	// an expiry in the past deletes the fields, and the hash once empty
+	assert.Equal(t, ints(2, 2), string(evalHEXPIREAT([]string{"h", "990", "FIELDS", "2", "a", "b"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]string{"c"}, false), evalHKEYS([]string{"h"}, store))
+	assert.Equal(t, ints(1), string(evalHPEXPIREAT([]string{"h", "1000500", "FIELDS", "1", "c"}, store)))
+	assert.Equal(t, ints(500), string(evalHPTTL([]string{"h", "FIELDS", "1", "c"}, store)))
+	assert.Equal(t, ints(2), string(evalHEXPIRE([]string{"h", "0", "FIELDS", "1", "c"}, store)))
+	assert.Assert(t, store.Get("h") == nil)
+	assert.Equal(t, ints(-2, -2), string(evalHTTL([]string{"h", "FIELDS", "2", "a", "b"}, store)))
+
+	// the fields that expired are deleted when the hash is read
+	evalHSET([]string{"h", "a", "1", "b", "2"}, store)
+	evalHPEXPIRE([]string{"h", "1500", "FIELDS", "1", "a"}, store)
+	mockTime.SetTime(time.Unix(1001, 500*int64(time.Millisecond)))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$1\r\n2\r\n", string(evalHGETALL([]string{"h"}, store)))
+
+	tests := []struct {
+		got  []byte
+		want string
+	}{
+		{evalHEXPIRE([]string{"h", "10", "FIELDS", "1"}, store), "-ERR wrong number of arguments for 'hexpire' command\r\n"},
+		{evalHEXPIRE([]string{"h", "-1", "FIELDS", "1", "a"}, store), "-ERR invalid expire time in 'hexpire' command\r\n"},
+		{evalHEXPIRE([]string{"h", "ten", "FIELDS", "1", "a"}, store), "-ERR value is not an integer or out of range\r\n"},
+		{evalHEXPIRE([]string{"h", "10", "XX", "NX", "FIELDS", "1", "a"}, store), "-ERR Mandatory argument FIELDS is missing or not at the right position\r\n"},
+		{evalHEXPIRE([]string{"h", "10", "FIELDS", "0", "a"}, store), "-ERR Parameter `numFields` should be greater than 0\r\n"},
+		{evalHEXPIRE([]string{"h", "10", "FIELDS", "2", "a"}, store), "-ERR The `numfields` parameter must match the number of arguments\r\n"},
+		{evalHTTL([]string{"h", "FIELDS", "1"}, store), "-ERR wrong number of arguments for 'httl' command\r\n"},
+		{evalHPERSIST([]string{"h", "FIELD", "1", "a"}, store), "-ERR Mandatory argument FIELDS is missing or not at the right position\r\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(tt.got))
+	}
+}
+
+func TestExportFieldExpiries(t *testing.T) {
+	utils.CurrentTime = &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	src := dstore.NewStore(nil)
+	evalHSET([]string{"h", "a", "1", "b", "2"}, src)
+	evalHEXPIRE([]string{"h", "100", "FIELDS", "1", "a"}, src)
+
+	cmds, err := exportKey("h", src.Get("h"), src, 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"HEXPIREAT", "h", "1100", "FIELDS", "1", "a"}, cmds[len(cmds)-1])
+
+	dst := dstore.NewStore(nil)
+	for _, c := range cmds {
+		DiceCmds[c[0]].Eval(c[1:], dst)
+	}
+	assert.Equal(t, "*2\r\n:100\r\n:-1\r\n", string(evalHTTL([]string{"h", "FIELDS", "2", "a", "b"}, dst)))
+}
@@ -12,6 +12,8 @@ import (
 
 type HashMap map[string]string
 
+var errHashBitfieldRange = diceerrors.NewErr("hash value is out of range of the bitfield type")
+
 func (h HashMap) Get(k string) (*string, bool) {
 	value, ok := h[k]
 	if !ok {
@@ -20,6 +22,19 @@ func (h HashMap) Get(k string) (*string, bool) {
 	return &value, true
 }
 
+// randomFields returns fields chosen at random, see sampleIndexes for the
+// meaning of count. The fields are sampled while iterating over the hash,
+// which is not copied.
+func (h HashMap) randomFields(count int) []string {
+	return sampleStream(len(h), count, func(yield func(string) bool) {
+		for field := range h {
+			if !yield(field) {
+				return
+			}
+		}
+	})
+}
+
 func (h HashMap) Set(k, v string) (*string, bool) {
 	value, ok := h[k]
 	if ok {
@@ -131,3 +146,114 @@ func (h HashMap) incrementFloatValue(field string, incr float64) (string, error)
 
 	return strValue, nil
 }
+
+// hashBitfieldOp is a subcommand of HBITFIELD. The field value is an integer
+// bounded by the encoding of the subcommand, such as i16 or u8, the overflow
+// being the policy in effect when the subcommand was given.
+type hashBitfieldOp struct {
+	kind     string
+	field    string
+	signed   bool
+	width    int64
+	value    int64
+	overflow string
+}
+
+// bitfieldBounds returns the smallest and largest values of an integer of the
+// given signedness and width in bits.
+func bitfieldBounds(signed bool, width int64) (minVal, maxVal int64) {
+	if signed {
+		return -1 << (width - 1), 1<<(width-1) - 1
+	}
+	return 0, 1<<width - 1
+}
+
+// wrapBitfield truncates value to its lowest width bits, as two's complement
+// if signed.
+func wrapBitfield(value int64, signed bool, width int64) int64 {
+	if width == 64 {
+		return value
+	}
+	u := uint64(value) & (1<<width - 1)
+	if signed && u >= 1<<(width-1) {
+		return int64(u) - 1<<width
+	}
+	return int64(u)
+}
+
+// addBitfield adds increment to value following the overflow policy, with the
+// bounds of the given signedness and width. It returns false if the sum
+// overflows with the FAIL policy.
+func addBitfield(value, increment int64, signed bool, width int64, overflow string) (int64, bool) {
+	minVal, maxVal := bitfieldBounds(signed, width)
+	sum := value + increment
+	// the sum overflowing int64 overflows the bounds as well, WRAP being
+	// unaffected as the truncation of the sum is the same modulo 2^64
+	overflowed := (increment > 0 && sum < value) || (increment < 0 && sum > value)
+
+	switch {
+	case !overflowed && sum >= minVal && sum <= maxVal:
+		return sum, true
+	case overflow == WRAP:
+		return wrapBitfield(sum, signed, width), true
+	case overflow == SAT && (increment > 0 || (!overflowed && sum > maxVal)):
+		return maxVal, true
+	case overflow == SAT:
+		return minVal, true
+	default:
+		return value, false
+	}
+}
+
+// applyBitfield applies the HBITFIELD subcommands on the hash, returning their
+// results, nil for the ones failing on overflow. Either all the subcommands are
+// applied or, if a field holds a value that is not an integer in the bounds of
+// its encoding, none is.
+func (h HashMap) applyBitfield(ops []hashBitfieldOp) ([]interface{}, bool, error) {
+	values := make(map[string]int64)
+	get := func(op hashBitfieldOp) (int64, error) {
+		v, ok := values[op.field]
+		if raw, present := h[op.field]; !ok && present {
+			var err error
+			if v, err = strconv.ParseInt(raw, 10, 64); err != nil {
+				return 0, diceerrors.NewErr(diceerrors.HashValueNotIntegerErr)
+			}
+		}
+		if minVal, maxVal := bitfieldBounds(op.signed, op.width); v < minVal || v > maxVal {
+			return 0, errHashBitfieldRange
+		}
+		return v, nil
+	}
+
+	result := make([]interface{}, 0, len(ops))
+	for _, op := range ops {
+		current, err := get(op)
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch op.kind {
+		case GET:
+			result = append(result, current)
+		case SET:
+			if v, ok := addBitfield(op.value, 0, op.signed, op.width, op.overflow); ok {
+				values[op.field] = v
+				result = append(result, current)
+			} else {
+				result = append(result, nil)
+			}
+		case INCRBY:
+			if v, ok := addBitfield(current, op.value, op.signed, op.width, op.overflow); ok {
+				values[op.field] = v
+				result = append(result, v)
+			} else {
+				result = append(result, nil)
+			}
+		}
+	}
+
+	for field, v := range values {
+		h[field] = strconv.FormatInt(v, 10)
+	}
+	return result, len(values) > 0, nil
+}
@@ -117,3 +117,75 @@ func TestHashMapIncrementFloatValue(t *testing.T) {
 	assert.NotNil(t, err, "Expected error when incrementing a non-float value")
 	assert.Equal(t, errors.IntOrFloatErr, err.Error(), "Expected int or float error")
 }
+
+func TestAddBitfield(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     int64
+		increment int64
+		signed    bool
+		width     int64
+		overflow  string
+		expected  int64
+		ok        bool
+	}{
+		{"in bounds", 100, 27, true, 8, FAIL, 127, true},
+		{"signed wrap", 100, 28, true, 8, WRAP, -128, true},
+		{"unsigned wrap", 250, 10, false, 8, WRAP, 4, true},
+		{"unsigned wrap below zero", 0, -1, false, 8, WRAP, 255, true},
+		{"saturate above", 100, 100, true, 8, SAT, 127, true},
+		{"saturate below", -100, -100, true, 8, SAT, -128, true},
+		{"fail", 255, 1, false, 8, FAIL, 255, false},
+		{"int64 wrap", math.MaxInt64, 1, true, 64, WRAP, math.MinInt64, true},
+		{"int64 saturate", math.MaxInt64, 1, true, 64, SAT, math.MaxInt64, true},
+		{"int64 saturate below", math.MinInt64, -1, true, 64, SAT, math.MinInt64, true},
+		{"int64 fail", math.MinInt64, -1, true, 64, FAIL, math.MinInt64, false},
+		{"u63 overflowing int64", math.MaxInt64, math.MaxInt64, false, 63, SAT, math.MaxInt64, true},
+		{"u63 wrap overflowing int64", math.MaxInt64, 2, false, 63, WRAP, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := addBitfield(tt.value, tt.increment, tt.signed, tt.width, tt.overflow)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestHBITFIELD(t *testing.T) {
+	store := store.NewStore(nil)
+
+	result := evalHBITFIELD([]string{"counters", "INCRBY", "u8", "hits", "200", "INCRBY", "u8", "hits", "100",
+		"OVERFLOW", "SAT", "INCRBY", "u8", "hits", "300", "INCRBY", "i16", "delta", "-5", "GET", "u8", "misses"}, store)
+	assert.Equal(t, clientio.Encode([]interface{}{int64(200), int64(44), int64(255), int64(-5), int64(0)}, false), result)
+	hmap := store.Get("counters").Value.(HashMap)
+	assert.Equal(t, HashMap{"hits": "255", "delta": "-5"}, hmap)
+
+	result = evalHBITFIELD([]string{"counters", "OVERFLOW", "FAIL", "INCRBY", "u8", "hits", "1", "SET", "i8", "delta", "7"}, store)
+	assert.Equal(t, clientio.Encode([]interface{}{nil, int64(-5)}, false), result)
+	assert.Equal(t, HashMap{"hits": "255", "delta": "7"}, hmap)
+
+	// the command is atomic, no subcommand being applied if one of them fails
+	hmap.Set("name", "dice")
+	result = evalHBITFIELD([]string{"counters", "INCRBY", "u8", "hits", "1", "INCRBY", "u8", "name", "1"}, store)
+	assert.Equal(t, []byte("-ERR hash value is not an integer\r\n"), result)
+	result = evalHBITFIELD([]string{"counters", "INCRBY", "i8", "delta", "1", "GET", "u4", "hits"}, store)
+	assert.Equal(t, []byte("-ERR hash value is out of range of the bitfield type\r\n"), result)
+	assert.Equal(t, HashMap{"hits": "255", "delta": "7", "name": "dice"}, hmap)
+
+	// reads do not create the key
+	result = evalHBITFIELD([]string{"other", "GET", "i64", "f"}, store)
+	assert.Equal(t, clientio.Encode([]interface{}{int64(0)}, false), result)
+	assert.Nil(t, store.Get("other"))
+
+	assert.Equal(t, errors.NewErrArity("HBITFIELD"), evalHBITFIELD([]string{}, store))
+	assert.Equal(t, []byte("-ERR syntax error\r\n"), evalHBITFIELD([]string{"counters", "INCRBY", "u8", "hits"}, store))
+	assert.Equal(t, []byte("-ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is.\r\n"),
+		evalHBITFIELD([]string{"counters", "GET", "u64", "hits"}, store))
+	assert.Equal(t, []byte("-ERR Invalid OVERFLOW type specified\r\n"), evalHBITFIELD([]string{"counters", "OVERFLOW", "NONE"}, store))
+
+	evalSET([]string{"string", "value"}, store)
+	assert.Equal(t, []byte("-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"),
+		evalHBITFIELD([]string{"string", "GET", "u8", "f"}, store))
+}
@@ -0,0 +1,725 @@
+package eval
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// exportItemsPerCmd caps the number of elements emitted in a single command
+// when exporting collections, so that huge lists, sets, hashes and sorted sets
+// do not end up as one gigantic RESP array.
+const exportItemsPerCmd = 64
+
+// SnapshotVersion is the latest version of the format of the keyspace exported
+// by ExportSnapshot. Version 1 is the plain stream of commands of ExportRESP.
+// Version 2 opens with a SNAPSHOT.VERSION header and follows the commands of
+// every key with a SNAPSHOT.KEYMETA command carrying its LFU counter, its idle
+// time and its expiry in milliseconds, so that loading a snapshot resets neither
+// the eviction signals nor the TTLs. Version 3 restores the values with no
+// command representation, e.g. the streams, with SNAPSHOT.VALUE commands
+// carrying their binary dump, see dumpValue.
+const SnapshotVersion = 3
+
+// the commands of the snapshots of version 2 and above, handled by ImportRESP
+// itself
+const (
+	snapshotVersionCmd = "SNAPSHOT.VERSION"
+	snapshotKeyMetaCmd = "SNAPSHOT.KEYMETA"
+	snapshotValueCmd   = "SNAPSHOT.VALUE"
+)
+
+// ExportRESP renders the whole keyspace of the store as a stream of RESP encoded
+// commands (SET, RPUSH, SADD, HSET, ZADD, JSON.SET, BF.LOADCHUNK) written to w. Keys having an
+// expiry are followed by an EXPIREAT command, strings carry their expiry inline
+// through SET ... PXAT, and the fields of hashes having an expiry are followed
+// by HEXPIREAT commands, the members of sets and sorted sets by EXPIREMEMBERAT
+// ones. The produced stream can be replayed with ImportRESP or
+// piped into any RESP compatible server, e.g. `redis-cli --pipe`.
+//
+// Keys whose type has no command representation (e.g. streams) are skipped.
+// It returns the number of keys exported.
+func ExportRESP(w io.Writer, store *dstore.Store) (int, error) {
+	return exportSnapshot(w, store, 1, true)
+}
+
+// ExportSnapshot renders the whole keyspace of the store like ExportRESP, in the
+// given version of the snapshot format, see SnapshotVersion. It fails rather
+// than skipping the keys whose type has no representation in the version,
+// e.g. for the replicas that could not restore them.
+func ExportSnapshot(w io.Writer, store *dstore.Store, version int) (int, error) {
+	return exportSnapshot(w, store, version, false)
+}
+
+func exportSnapshot(w io.Writer, store *dstore.Store, version int, skipUnsupported bool) (int, error) {
+	if version < 1 || version > SnapshotVersion {
+		return 0, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+
+	bw := bufio.NewWriter(w)
+	exported := 0
+	if version >= 2 {
+		header := []string{snapshotVersionCmd, strconv.Itoa(version)}
+		if _, err := bw.Write(clientio.Encode(header, false)); err != nil {
+			return 0, err
+		}
+	}
+
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+
+	var err error
+	store.GetStore().All(func(key string, obj *object.Obj) bool {
+		if exp, ok := dstore.GetExpiry(obj, store); ok && exp <= now {
+			return true
+		}
+
+		var cmds [][]string
+		if cmds, err = exportKey(key, obj, store, version); err != nil {
+			return false
+		}
+		if len(cmds) == 0 {
+			if !skipUnsupported {
+				err = fmt.Errorf("the %s at key %s cannot be exported in snapshot version %d", compositionType(obj), key, version)
+				return false
+			}
+			return true
+		}
+
+		for _, c := range cmds {
+			if _, err = bw.Write(clientio.Encode(c, false)); err != nil {
+				return false
+			}
+		}
+		exported++
+		return true
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	return exported, bw.Flush()
+}
+
+// exportKey returns the commands required to rebuild the key along with its
+// expiry, and its metadata from version 2 of the snapshot format on.
+func exportKey(key string, obj *object.Obj, store *dstore.Store, version int) ([][]string, error) {
+	exp, hasExpiry := dstore.GetExpiry(obj, store)
+	plain := dstore.PlainObj(obj)
+	cmds, inlineExpiry, err := exportValue(key, plain, exp, hasExpiry)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmds) == 0 && version >= 3 {
+		if typ, data, ok := dumpValue(plain); ok {
+			cmds = [][]string{{snapshotValueCmd, key, typ, string(data)}}
+		}
+	}
+
+	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
+	case object.ObjTypeHashMap:
+		cmds = append(cmds, exportFieldExpiries(key, store)...)
+	case object.ObjTypeSet, object.ObjTypeSortedSet:
+		cmds = append(cmds, exportMemberExpiries(key, store)...)
+	}
+
+	switch {
+	case len(cmds) == 0:
+	case version >= 2:
+		cmds = append(cmds, exportKeyMeta(key, obj.LastAccessedAt, exp, hasExpiry))
+	case hasExpiry && !inlineExpiry:
+		// EXPIREAT has a second granularity, round up so that the key never
+		// expires earlier than it would have on the source.
+		expSec := (exp + 999) / 1000
+		cmds = append(cmds, []string{"EXPIREAT", key, strconv.FormatUint(expSec, 10)})
+	}
+
+	return cmds, nil
+}
+
+// exportFieldExpiries returns the HEXPIREAT commands restoring the expiry of
+// the fields of the hash stored at key, rounded up to the second like the
+// expiry of the keys.
+func exportFieldExpiries(key string, store *dstore.Store) [][]string {
+	var cmds [][]string
+	store.FieldExpiries(key, func(field string, exp uint64) {
+		expSec := (exp + 999) / 1000
+		cmds = append(cmds, []string{"HEXPIREAT", key, strconv.FormatUint(expSec, 10), Fields, "1", field})
+	})
+	return cmds
+}
+
+// exportMemberExpiries returns the EXPIREMEMBERAT commands restoring the expiry
+// of the members of the set or the sorted set stored at key, rounded up to the
+// second like the expiry of the keys.
+func exportMemberExpiries(key string, store *dstore.Store) [][]string {
+	var cmds [][]string
+	store.FieldExpiries(key, func(member string, exp uint64) {
+		expSec := (exp + 999) / 1000
+		cmds = append(cmds, []string{"EXPIREMEMBERAT", key, member, strconv.FormatUint(expSec, 10)})
+	})
+	return cmds
+}
+
+// WriteBehindValue returns the value of the write-behind record of the key,
+// the RESP encoded commands rebuilding it along with its expiry, or nil if its
+// type has no command representation.
+func WriteBehindValue(key string, obj *object.Obj, store *dstore.Store) ([]byte, error) {
+	cmds, err := exportKey(key, obj, store, 1)
+	if err != nil || len(cmds) == 0 {
+		return nil, err
+	}
+
+	var data []byte
+	for _, c := range cmds {
+		data = append(data, clientio.Encode(c, false)...)
+	}
+	return data, nil
+}
+
+// exportValue returns the commands required to rebuild the value of the plain
+// object obj at key, nil if its type has no command representation. The
+// strings carry their expiry inline if hasExpiry is true, in which case
+// inlineExpiry is true.
+func exportValue(key string, obj *object.Obj, exp uint64, hasExpiry bool) (cmds [][]string, inlineExpiry bool, err error) {
+	oType, oEnc := object.ExtractTypeEncoding(obj)
+	switch oType {
+	case object.ObjTypeString, object.ObjTypeInt:
+		c := []string{"SET", key, exportStringValue(obj.Value, oEnc)}
+		if hasExpiry {
+			c = append(c, Pxat, strconv.FormatUint(exp, 10))
+		}
+		cmds, inlineExpiry = [][]string{c}, true
+	case object.ObjTypeByteArray:
+		c := []string{"SET", key, string(obj.Value.(*ByteArray).data)}
+		if hasExpiry {
+			c = append(c, Pxat, strconv.FormatUint(exp, 10))
+		}
+		cmds, inlineExpiry = [][]string{c}, true
+	case object.ObjTypeByteList:
+		var items []string
+		obj.Value.(*Deque).Iterate(func(x string) bool {
+			items = append(items, x)
+			return true
+		})
+		cmds = batchExportCmds("RPUSH", key, items, 1)
+	case object.ObjTypeSet:
+		items := make([]string, 0, len(obj.Value.(map[string]struct{})))
+		for member := range obj.Value.(map[string]struct{}) {
+			items = append(items, member)
+		}
+		cmds = batchExportCmds("SADD", key, items, 1)
+	case object.ObjTypeHashMap:
+		hashMap := obj.Value.(HashMap)
+		items := make([]string, 0, 2*len(hashMap))
+		for field, value := range hashMap {
+			items = append(items, field, value)
+		}
+		cmds = batchExportCmds("HSET", key, items, 2)
+	case object.ObjTypeSortedSet:
+		tree := obj.Value.([]interface{})[0].(*skipList)
+		items := make([]string, 0, 2*tree.Len())
+		var payloadItems []string
+		tree.Ascend(func(item *SortedSetItem) bool {
+			score := strconv.FormatFloat(item.Score, 'g', -1, 64)
+			if item.Payload == "" {
+				items = append(items, score, item.Member)
+			} else {
+				payloadItems = append(payloadItems, score, item.Member, item.Payload)
+			}
+			return true
+		})
+		cmds = batchExportCmds("ZADD", key, items, 2)
+		// the members having a payload are added by ZADD key WITHPAYLOADS score member payload ...
+		for _, c := range batchExportCmds("ZADD", key, payloadItems, 3) {
+			cmds = append(cmds, append([]string{c[0], c[1], WithPayloads}, c[2:]...))
+		}
+	case object.ObjTypeJSON:
+		value, err := sonic.MarshalString(obj.Value)
+		if err != nil {
+			return nil, false, fmt.Errorf("could not export key %s: %w", key, err)
+		}
+		cmds = [][]string{{"JSON.SET", key, defaultRootPath, value}}
+	case object.ObjTypeBitSet:
+		// the filter is loaded by chunks, like BF.SCANDUMP dumps it
+		bloom := obj.Value.(*Bloom)
+		cmds = [][]string{{"BF.LOADCHUNK", key, "1", string(bloom.header())}}
+		for iter := int64(1); ; {
+			data, n := bloom.chunk(iter - 1)
+			if n == 0 {
+				break
+			}
+			iter += n
+			cmds = append(cmds, []string{"BF.LOADCHUNK", key, strconv.FormatInt(iter, 10), string(data)})
+		}
+	}
+	return cmds, inlineExpiry, nil
+}
+
+// exportKeyMeta returns the SNAPSHOT.KEYMETA command restoring the LFU counter,
+// the idle time in seconds and the expiry in milliseconds of the key.
+//
+// Usage: SNAPSHOT.KEYMETA key FREQ counter IDLE seconds [PXAT unix-time-milliseconds]
+func exportKeyMeta(key string, lastAccessedAt uint32, exp uint64, hasExpiry bool) []string {
+	c := []string{
+		snapshotKeyMetaCmd, key,
+		Freq, strconv.Itoa(int(dstore.GetLFULogCounter(lastAccessedAt))),
+		Idle, strconv.FormatUint(uint64(dstore.GetIdleTime(lastAccessedAt)), 10),
+	}
+	if hasExpiry {
+		c = append(c, Pxat, strconv.FormatUint(exp, 10))
+	}
+	return c
+}
+
+func exportStringValue(value interface{}, oEnc uint8) string {
+	if oEnc == object.ObjEncodingInt {
+		if v, ok := value.(int64); ok {
+			return strconv.FormatInt(v, 10)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// batchExportCmds splits items into commands of at most exportItemsPerCmd elements,
+// where an element is made of `width` consecutive items (e.g. field and value).
+func batchExportCmds(name, key string, items []string, width int) [][]string {
+	batch := exportItemsPerCmd * width
+	cmds := make([][]string, 0, (len(items)+batch-1)/batch)
+	for start := 0; start < len(items); start += batch {
+		end := min(start+batch, len(items))
+		c := make([]string, 0, 2+end-start)
+		c = append(c, name, key)
+		c = append(c, items[start:end]...)
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+// respReader adapts an io.Reader to the io.ReadWriter expected by the RESP parser.
+type respReader struct {
+	io.Reader
+}
+
+func (respReader) Write(p []byte) (int, error) {
+	return 0, errors.New("write not supported")
+}
+
+// ImportRESP reads a stream of RESP encoded commands from r, such as the one
+// produced by ExportRESP or ExportSnapshot, and executes them against the store.
+// The import stops at the first malformed or failing command and reports its
+// position in the stream. A stream without a SNAPSHOT.VERSION header is of
+// version 1, the snapshots of a version above SnapshotVersion are rejected.
+// It returns the number of commands executed successfully.
+func ImportRESP(r io.Reader, store *dstore.Store) (int, error) {
+	return importRESP(r, store, nil)
+}
+
+// ImportSnapshot loads a snapshot, such as the one produced by ExportSnapshot,
+// into the live keyspace of the store, prepending prefix to every key so that
+// a dataset can be staged next to the one being served, e.g. to compare them.
+// The keys already present are replaced rather than merged with the snapshot.
+// The commands touching no key, e.g. FLUSHDB, are rejected as they could not
+// be confined to the prefix. Like ImportRESP, the import stops at the first
+// malformed or failing command, the keys loaded until then being kept.
+// It returns the number of keys loaded.
+func ImportSnapshot(r io.Reader, store *dstore.Store, prefix string) (int, error) {
+	im, loaded := newSnapshotImporter(r, store, prefix)
+	_, err := im.run()
+	return len(loaded), err
+}
+
+// newSnapshotImporter returns the importer loading a snapshot into the store
+// under prefix, along with the keys it loaded, see ImportSnapshot.
+func newSnapshotImporter(r io.Reader, store *dstore.Store, prefix string) (*respImporter, map[string]struct{}) {
+	loaded := make(map[string]struct{})
+	return newRESPImporter(r, store, func(diceDBCmd *cmd.DiceDBCmd) error {
+		switch diceDBCmd.Cmd {
+		case snapshotVersionCmd:
+			return nil
+		case snapshotKeyMetaCmd:
+			if len(diceDBCmd.Args) > 0 {
+				diceDBCmd.Args[0] = prefix + diceDBCmd.Args[0]
+			}
+			return nil
+		case snapshotValueCmd:
+			if len(diceDBCmd.Args) > 0 {
+				diceDBCmd.Args[0] = prefix + diceDBCmd.Args[0]
+				store.Del(diceDBCmd.Args[0])
+				loaded[diceDBCmd.Args[0]] = struct{}{}
+			}
+			return nil
+		}
+
+		diceCmd, ok := DiceCmds[diceDBCmd.Cmd]
+		if !ok {
+			return fmt.Errorf("unknown command '%s'", diceDBCmd.Cmd)
+		}
+		indexes := diceCmd.KeySpecs.keyIndexes(diceDBCmd.Args)
+		if len(indexes) == 0 {
+			return errors.New("the commands touching no key cannot be restored")
+		}
+		for _, i := range indexes {
+			key := prefix + diceDBCmd.Args[i]
+			diceDBCmd.Args[i] = key
+			if _, ok := loaded[key]; !ok {
+				// the key is replaced, e.g. RPUSH would append to a list
+				store.Del(key)
+				loaded[key] = struct{}{}
+			}
+		}
+		return nil
+	}), loaded
+}
+
+// importRESP executes the commands read from r against the store, once
+// rewritten by rewrite if not nil, see ImportRESP.
+func importRESP(r io.Reader, store *dstore.Store, rewrite func(diceDBCmd *cmd.DiceDBCmd) error) (int, error) {
+	return newRESPImporter(r, store, rewrite).run()
+}
+
+// respImporter executes the commands of a RESP stream against a store one at a
+// time, once rewritten by rewrite if not nil, see importRESP.
+type respImporter struct {
+	rp       *clientio.RESPParser
+	store    *dstore.Store
+	rewrite  func(diceDBCmd *cmd.DiceDBCmd) error
+	imported int // imported is the number of commands executed successfully
+}
+
+func newRESPImporter(r io.Reader, store *dstore.Store, rewrite func(diceDBCmd *cmd.DiceDBCmd) error) *respImporter {
+	return &respImporter{
+		rp:      clientio.NewRESPParser(respReader{bufio.NewReader(r)}),
+		store:   store,
+		rewrite: rewrite,
+	}
+}
+
+// run executes the commands left in the stream. It returns the number of
+// commands executed successfully.
+func (im *respImporter) run() (int, error) {
+	for {
+		more, err := im.next()
+		if err != nil || !more {
+			return im.imported, err
+		}
+	}
+}
+
+// next executes the next command of the stream. It returns false once the
+// stream is exhausted.
+func (im *respImporter) next() (bool, error) {
+	// the keys get the expiry of the stream, if any, not a default one
+	im.store.SetRestoring(true)
+	defer im.store.SetRestoring(false)
+
+	value, err := im.rp.DecodeOne()
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not decode command %d: %w", im.imported+1, err)
+	}
+
+	diceDBCmd, err := importCmd(value)
+	if err == nil && im.rewrite != nil {
+		err = im.rewrite(diceDBCmd)
+	}
+	if err != nil {
+		return false, fmt.Errorf("invalid command %d: %w", im.imported+1, err)
+	}
+
+	switch diceDBCmd.Cmd {
+	case snapshotVersionCmd:
+		err = checkSnapshotVersion(diceDBCmd.Args)
+	case snapshotKeyMetaCmd:
+		err = importKeyMeta(diceDBCmd.Args, im.store)
+	case snapshotValueCmd:
+		err = importValue(diceDBCmd.Args, im.store)
+	default:
+		err = ExecuteCommand(diceDBCmd, nil, im.store, false, false).Err()
+	}
+	if err != nil {
+		return false, fmt.Errorf("command %d (%s) failed: %w", im.imported+1, diceDBCmd.Cmd, err)
+	}
+	im.imported++
+	return true, nil
+}
+
+// checkSnapshotVersion checks that the version of the SNAPSHOT.VERSION header
+// is supported.
+func checkSnapshotVersion(args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid snapshot header")
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil || version < 1 || version > SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %s", args[0])
+	}
+	return nil
+}
+
+// importKeyMeta applies the metadata of a SNAPSHOT.KEYMETA command to its key,
+// which is ignored if it does not exist, e.g. because it expired in between.
+func importKeyMeta(args []string, store *dstore.Store) error {
+	if len(args)%2 != 1 {
+		return errors.New("invalid key metadata")
+	}
+
+	var counter uint8
+	var idle uint32
+	var exp uint64
+	hasExpiry := false
+	for i := 1; i < len(args); i += 2 {
+		var v uint64
+		var err error
+		switch strings.ToUpper(args[i]) {
+		case Freq:
+			v, err = strconv.ParseUint(args[i+1], 10, 8)
+			counter = uint8(v)
+		case Idle:
+			v, err = strconv.ParseUint(args[i+1], 10, 32)
+			idle = uint32(v)
+		case Pxat:
+			exp, err = strconv.ParseUint(args[i+1], 10, 64)
+			hasExpiry = true
+		default:
+			return fmt.Errorf("invalid key metadata %s", args[i])
+		}
+		if err != nil {
+			return fmt.Errorf("invalid key metadata %s: %w", args[i], err)
+		}
+	}
+
+	// the metadata are set on the object stored, not on a decompressed copy
+	store.BeginWrite()
+	defer store.EndWrite()
+	obj := store.GetNoTouch(args[0])
+	if obj == nil {
+		return nil
+	}
+	obj.LastAccessedAt = dstore.NewLastAccessedAt(counter, idle)
+	if hasExpiry {
+		store.SetUnixTimeMsExpiry(obj, exp)
+		store.IndexExpiries([]string{args[0]})
+	}
+	return nil
+}
+
+// importValue restores the value of a SNAPSHOT.VALUE command at its key, from
+// its type and its binary dump.
+//
+// Usage: SNAPSHOT.VALUE key type dump
+func importValue(args []string, store *dstore.Store) error {
+	if len(args) != 3 {
+		return errors.New("invalid value")
+	}
+	obj, err := loadValue(args[1], []byte(args[2]))
+	if err != nil {
+		return err
+	}
+	oType, oEnc := object.ExtractTypeEncoding(obj)
+	store.Put(args[0], store.NewObj(obj.Value, -1, oType, oEnc))
+	return nil
+}
+
+func importCmd(value interface{}) (*cmd.DiceDBCmd, error) {
+	tokens, ok := value.([]interface{})
+	if !ok || len(tokens) == 0 {
+		return nil, errors.New("expected a non empty array of bulk strings")
+	}
+
+	args := make([]string, len(tokens))
+	for i, token := range tokens {
+		if args[i], ok = token.(string); !ok {
+			return nil, errors.New("expected a non empty array of bulk strings")
+		}
+	}
+
+	return &cmd.DiceDBCmd{
+		Cmd:  strings.ToUpper(args[0]),
+		Args: args[1:],
+	}, nil
+}
+
+// evalRESTORESNAPSHOT is the container command of the restore jobs, loading a
+// snapshot file into the store in the background, see dstore.StartRestoreJob.
+// RESTORE-SNAPSHOT START path [PREFIX prefix] [BATCH count] starts a job
+// loading the snapshot at path, relative to
+// config.DiceConfig.Server.SnapshotDir, the keys being prefixed with prefix,
+// see ImportSnapshot, and returns its id.
+// RESTORE-SNAPSHOT STATUS [id] returns the status of the job id as name and
+// value pairs, or the status of every job.
+// RESTORE-SNAPSHOT CANCEL id stops the job id, returning 1 if it was running
+// and 0 otherwise.
+func evalRESTORESNAPSHOT(args []string, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("RESTORE-SNAPSHOT")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case Start:
+		return evalRESTORESNAPSHOTStart(args[1:], store)
+	case Status:
+		if len(args) > 2 {
+			return diceerrors.NewErrArity("RESTORE-SNAPSHOT|STATUS")
+		}
+		jobs := store.RestoreJobs()
+		if len(args) == 1 {
+			statuses := make([]interface{}, len(jobs))
+			for i := range jobs {
+				statuses[i] = restoreJobStatus(&jobs[i])
+			}
+			return clientio.Encode(statuses, false)
+		}
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		for i := range jobs {
+			if jobs[i].ID == id {
+				return clientio.Encode(restoreJobStatus(&jobs[i]), false)
+			}
+		}
+		return diceerrors.NewErrWithFormattedMessage("no such restore job %d", id)
+	case Cancel:
+		if len(args) != 2 {
+			return diceerrors.NewErrArity("RESTORE-SNAPSHOT|CANCEL")
+		}
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if store.CancelRestoreJob(id) {
+			return clientio.RespOne
+		}
+		return clientio.RespZero
+	case Help:
+		return commandHelp("RESTORE-SNAPSHOT")
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try RESTORE-SNAPSHOT HELP.", args[0])
+	}
+}
+
+// evalRESTORESNAPSHOTStart starts a restore job. The jobs are not propagated,
+// hence refused while replicas or CDC subscribers follow the store.
+//
+// Usage: RESTORE-SNAPSHOT START path [PREFIX prefix] [BATCH count]
+func evalRESTORESNAPSHOTStart(args []string, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("RESTORE-SNAPSHOT|START")
+	}
+
+	prefix, batch := "", dstore.DefaultRestoreJobBatch
+	for i := 1; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		switch strings.ToUpper(args[i]) {
+		case Prefix:
+			prefix = args[i+1]
+		case Batch:
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n <= 0 {
+				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			batch = n
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	if store.Followed() {
+		return diceerrors.NewErrWithFormattedMessage("cannot restore a snapshot: %v", dstore.ErrFollowed)
+	}
+	path, err := snapshotPath(args[0])
+	if err != nil {
+		return diceerrors.NewErrWithFormattedMessage("could not open the snapshot: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return diceerrors.NewErrWithFormattedMessage("could not open the snapshot: %v", err)
+	}
+
+	im, loaded := newSnapshotImporter(f, store, prefix)
+	return clientio.Encode(store.StartRestoreJob(args[0], prefix, batch, &snapshotLoader{f: f, im: im, loaded: loaded}), false)
+}
+
+// snapshotPath returns the path of the snapshot file name, relative to
+// config.DiceConfig.Server.SnapshotDir. The snapshots outside the directory,
+// e.g. reached through a symbolic link, are refused.
+func snapshotPath(name string) (string, error) {
+	dir := config.Current().Server.SnapshotDir
+	if dir == "" {
+		return "", errors.New("no snapshot directory is configured")
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%s is not in the snapshot directory", name)
+	}
+
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, path); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is not in the snapshot directory", name)
+	}
+	return path, nil
+}
+
+// snapshotLoader loads a snapshot file into the store for a restore job, see
+// ImportSnapshot.
+type snapshotLoader struct {
+	f      *os.File
+	im     *respImporter
+	loaded map[string]struct{}
+}
+
+func (l *snapshotLoader) Load(n int) (bool, error) {
+	for ; n > 0; n-- {
+		more, err := l.im.next()
+		if err != nil || !more {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (l *snapshotLoader) Keys() int {
+	return len(l.loaded)
+}
+
+func (l *snapshotLoader) Close() error {
+	return l.f.Close()
+}
+
+// restoreJobStatus returns the status of job as name and value pairs.
+func restoreJobStatus(job *dstore.RestoreJob) []interface{} {
+	return []interface{}{
+		"id", job.ID,
+		"path", job.Path,
+		"prefix", job.Prefix,
+		"batch", job.Batch,
+		"state", string(job.State),
+		"started", job.StartedAt.Unix(),
+		"keys", job.Keys,
+		"error", job.Error,
+	}
+}
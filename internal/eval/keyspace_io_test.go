@@ -0,0 +1,377 @@
+package eval
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestExportImportRESP(t *testing.T) {
+	src := dstore.NewStore(nil)
+
+	evalSET([]string{"str", "hello"}, src)
+	evalSET([]string{"int", "42"}, src)
+	evalSET([]string{"volatile", "bye", Px, "100000"}, src)
+	evalRPUSH([]string{"list", "a", "b", "c"}, src)
+	evalSADD([]string{"set", "x", "y"}, src)
+	evalHSET([]string{"hash", "f1", "v1", "f2", "v2"}, src)
+	evalZADD([]string{"zset", "1.5", "one", "2", "two"}, src)
+	evalZADD([]string{"zset", WithPayloads, "3", "three", "payload"}, src)
+	evalEXPIRE([]string{"zset", "1000"}, src)
+	evalJSONSET([]string{"doc", defaultRootPath, `{"a":1,"b":["x"]}`}, src)
+
+	// large collections are split across several commands
+	bigList := make([]string, 0, 2*exportItemsPerCmd+2)
+	bigList = append(bigList, "biglist")
+	for i := 0; i < 2*exportItemsPerCmd+1; i++ {
+		bigList = append(bigList, strconv.Itoa(i))
+	}
+	evalRPUSH(bigList, src)
+
+	var buf bytes.Buffer
+	exported, err := ExportRESP(&buf, src)
+	assert.NilError(t, err)
+	assert.Equal(t, 9, exported)
+
+	dst := dstore.NewStore(nil)
+	imported, err := ImportRESP(&buf, dst)
+	assert.NilError(t, err)
+	// 9 keys, 2 extra RPUSH for biglist, 1 extra ZADD and 1 EXPIREAT for zset
+	assert.Equal(t, 13, imported)
+
+	assert.Equal(t, "hello", evalGET([]string{"str"}, dst).Result)
+	assert.Equal(t, int64(42), evalGET([]string{"int"}, dst).Result)
+	assert.Equal(t, "bye", evalGET([]string{"volatile"}, dst).Result)
+
+	srcExp, _ := dstore.GetExpiry(src.Get("volatile"), src)
+	dstExp, ok := dstore.GetExpiry(dst.Get("volatile"), dst)
+	assert.Assert(t, ok)
+	assert.Equal(t, srcExp, dstExp)
+
+	srcExp, _ = dstore.GetExpiry(src.Get("zset"), src)
+	dstExp, ok = dstore.GetExpiry(dst.Get("zset"), dst)
+	assert.Assert(t, ok)
+	assert.Assert(t, dstExp >= srcExp && dstExp-srcExp < 1000)
+
+	assert.DeepEqual(t, []string{"a", "b", "c"}, dequeElements(dst.Get("list")))
+	assert.DeepEqual(t, bigList[1:], dequeElements(dst.Get("biglist")))
+	assert.DeepEqual(t, map[string]struct{}{"x": {}, "y": {}}, dst.Get("set").Value)
+	assert.DeepEqual(t, HashMap{"f1": "v1", "f2": "v2"}, dst.Get("hash").Value)
+	assert.DeepEqual(t, map[string]float64{"one": 1.5, "two": 2, "three": 3},
+		dst.Get("zset").Value.([]interface{})[1].(map[string]float64))
+	assert.Equal(t, "payload", dst.Get("zset").Value.([]interface{})[0].(*skipList).Get(3, "three").Payload)
+	assertJSONEqual(t, `{"a":1,"b":["x"]}`, evalJSONGET([]string{"doc"}, dst))
+}
+
+func TestExportImportSnapshotMetadata(t *testing.T) {
+	src := dstore.NewStore(nil)
+	evalSET([]string{"str", "hello", Px, "100500"}, src)
+	evalRPUSH([]string{"list", "a", "b"}, src)
+	src.SetExpiry(src.GetNoTouch("list"), 100500)
+	evalSADD([]string{"set", "x"}, src)
+
+	// a key accessed 100 seconds ago with an LFU counter of 42
+	src.GetNoTouch("list").LastAccessedAt = dstore.NewLastAccessedAt(42, 100)
+
+	var buf bytes.Buffer
+	exported, err := ExportSnapshot(&buf, src, SnapshotVersion)
+	assert.NilError(t, err)
+	assert.Equal(t, 3, exported)
+
+	dst := dstore.NewStore(nil)
+	imported, err := ImportRESP(&buf, dst)
+	assert.NilError(t, err)
+	// the header, then one command and one SNAPSHOT.KEYMETA per key
+	assert.Equal(t, 7, imported)
+
+	for _, key := range []string{"str", "list"} {
+		srcExp, _ := dstore.GetExpiry(src.GetNoTouch(key), src)
+		dstExp, ok := dstore.GetExpiry(dst.GetNoTouch(key), dst)
+		assert.Assert(t, ok)
+		assert.Equal(t, srcExp, dstExp, key)
+	}
+	_, ok := dstore.GetExpiry(dst.GetNoTouch("set"), dst)
+	assert.Assert(t, !ok)
+
+	obj := dst.GetNoTouch("list")
+	assert.Equal(t, uint8(42), dstore.GetLFULogCounter(obj.LastAccessedAt))
+	idle := dstore.GetIdleTime(obj.LastAccessedAt)
+	assert.Assert(t, idle >= 100 && idle <= 101, idle)
+	assert.DeepEqual(t, []string{"a", "b"}, dequeElements(obj))
+}
+
+func TestExportImportSnapshotValues(t *testing.T) {
+	src := dstore.NewStore(nil)
+	exec := func(name string, args ...string) {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, src, false, false)
+		assert.NilError(t, resp.Err(), name)
+	}
+	exec("BF.RESERVE", "bf", "0.01", "100")
+	exec("BF.MADD", "bf", "a", "b")
+	exec("CF.ADD", "cf", "a")
+	exec("CF.ADD", "cf", "a")
+	exec("CMS.INITBYDIM", "cms", "10", "3")
+	exec("CMS.INCRBY", "cms", "a", "5")
+	exec("TOPK.RESERVE", "topk", "2")
+	exec("TOPK.ADD", "topk", "a", "b", "a")
+	exec("TDIGEST.CREATE", "td")
+	exec("TDIGEST.ADD", "td", "1", "2", "3")
+	exec("XADD", "s", "1-1", "f", "v")
+	exec("XADD", "s", "1-2", "f", "w")
+	exec("XGROUP", "CREATE", "s", "g", "0")
+	exec("XREADGROUP", "GROUP", "g", "c", "COUNT", "1", "STREAMS", "s", ">")
+	exec("EXPIRE", "cf", "1000")
+
+	var buf bytes.Buffer
+	exported, err := ExportSnapshot(&buf, src, SnapshotVersion)
+	assert.NilError(t, err)
+	assert.Equal(t, 6, exported)
+
+	dst := dstore.NewStore(nil)
+	_, err = ImportRESP(&buf, dst)
+	assert.NilError(t, err)
+
+	// the values are restored exactly, along with their expiry
+	for _, key := range []string{"cf", "cms", "topk", "td", "s"} {
+		_, want, ok := dumpValue(src.GetNoTouch(key))
+		assert.Assert(t, ok, key)
+		_, got, _ := dumpValue(dst.GetNoTouch(key))
+		assert.DeepEqual(t, want, got)
+	}
+	want, got := src.GetNoTouch("bf").Value.(*Bloom), dst.GetNoTouch("bf").Value.(*Bloom)
+	assert.DeepEqual(t, want.header(), got.header())
+	assert.DeepEqual(t, want.bitset, got.bitset)
+	srcExp, _ := dstore.GetExpiry(src.GetNoTouch("cf"), src)
+	dstExp, ok := dstore.GetExpiry(dst.GetNoTouch("cf"), dst)
+	assert.Assert(t, ok)
+	assert.Equal(t, srcExp, dstExp)
+
+	// a corrupted dump is rejected
+	_, data, _ := dumpValue(src.GetNoTouch("s"))
+	assert.ErrorIs(t, importValue([]string{"s", "stream", string(data[:len(data)-1])}, dst), errInvalidDump)
+	assert.ErrorContains(t, importValue([]string{"s", "list", string(data)}, dst), "unknown value type list")
+}
+
+func TestSnapshotVersions(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalSET([]string{"k", "v"}, store)
+
+	// version 1 is the plain stream of commands
+	var buf bytes.Buffer
+	_, err := ExportSnapshot(&buf, store, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, string(clientio.Encode([]string{"SET", "k", "v"}, false)), buf.String())
+
+	_, err = ExportSnapshot(&buf, store, SnapshotVersion+1)
+	assert.ErrorContains(t, err, "unsupported snapshot version")
+
+	future := string(clientio.Encode([]string{snapshotVersionCmd, strconv.Itoa(SnapshotVersion + 1)}, false))
+	imported, err := ImportRESP(strings.NewReader(future), dstore.NewStore(nil))
+	assert.Equal(t, 0, imported)
+	assert.ErrorContains(t, err, "unsupported snapshot version")
+
+	// the metadata of a key missing from the snapshot is ignored
+	meta := string(clientio.Encode([]string{snapshotKeyMetaCmd, "missing", Freq, "1", Idle, "0"}, false))
+	imported, err = ImportRESP(strings.NewReader(meta), dstore.NewStore(nil))
+	assert.NilError(t, err)
+	assert.Equal(t, 1, imported)
+
+	meta = string(clientio.Encode([]string{snapshotKeyMetaCmd, "k", Freq, "256"}, false))
+	_, err = ImportRESP(strings.NewReader(meta), store)
+	assert.ErrorContains(t, err, "invalid key metadata FREQ")
+}
+
+func TestExportRESPSkipsUnsupportedTypes(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalXADD([]string{"s", "1-1", "f", "v"}, store)
+	evalSET([]string{"k", "v"}, store)
+
+	var buf bytes.Buffer
+	exported, err := ExportRESP(&buf, store)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, exported)
+	assert.Equal(t, string(clientio.Encode([]string{"SET", "k", "v"}, false)), buf.String())
+
+	// the snapshots of the versions that cannot restore them fail instead
+	_, err = ExportSnapshot(&buf, store, 2)
+	assert.ErrorContains(t, err, "the stream at key s cannot be exported in snapshot version 2")
+}
+
+func TestImportRESPErrors(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		imported int
+		err      string
+	}{
+		"empty stream": {
+			input: "",
+		},
+		"failing command": {
+			input:    string(clientio.Encode([]string{"SET", "k", "v"}, false)) + string(clientio.Encode([]string{"LPUSH", "k", "v"}, false)),
+			imported: 1,
+			err:      "command 2 (LPUSH) failed",
+		},
+		"unknown command": {
+			input: string(clientio.Encode([]string{"NOPE", "k"}, false)),
+			err:   "command 1 (NOPE) failed",
+		},
+		"not an array": {
+			input: "$3\r\nSET\r\n",
+			err:   "invalid command 1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			imported, err := ImportRESP(strings.NewReader(tc.input), dstore.NewStore(nil))
+			assert.Equal(t, tc.imported, imported)
+			if tc.err == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestImportSnapshotUnderPrefix(t *testing.T) {
+	src := dstore.NewStore(nil)
+	evalSET([]string{"str", "hello", Px, "100500"}, src)
+	evalRPUSH([]string{"list", "a", "b"}, src)
+	var buf bytes.Buffer
+	_, err := ExportSnapshot(&buf, src, SnapshotVersion)
+	assert.NilError(t, err)
+
+	live := dstore.NewStore(nil)
+	evalSET([]string{"str", "live"}, live)
+	evalRPUSH([]string{"staging:list", "stale"}, live)
+
+	assert.Equal(t, 2, mustImportSnapshot(t, buf.Bytes(), live, "staging:"))
+	// the live keys are left untouched, the prefixed ones being replaced
+	assert.Equal(t, "live", evalGET([]string{"str"}, live).Result)
+	assert.Equal(t, "hello", evalGET([]string{"staging:str"}, live).Result)
+	assert.DeepEqual(t, []string{"a", "b"}, dequeElements(live.Get("staging:list")))
+	srcExp, _ := dstore.GetExpiry(src.GetNoTouch("str"), src)
+	dstExp, ok := dstore.GetExpiry(live.GetNoTouch("staging:str"), live)
+	assert.Assert(t, ok)
+	assert.Equal(t, srcExp, dstExp)
+
+	// without a prefix, the snapshot replaces the live keys
+	assert.Equal(t, 2, mustImportSnapshot(t, buf.Bytes(), live, ""))
+	assert.Equal(t, "hello", evalGET([]string{"str"}, live).Result)
+
+	// the commands touching no key cannot be confined to the prefix
+	flush := string(clientio.Encode([]string{"FLUSHDB"}, false))
+	_, err = ImportSnapshot(strings.NewReader(flush), live, "staging:")
+	assert.ErrorContains(t, err, "the commands touching no key cannot be restored")
+	assert.Equal(t, "hello", evalGET([]string{"str"}, live).Result)
+}
+
+func mustImportSnapshot(t *testing.T, data []byte, store *dstore.Store, prefix string) int {
+	loaded, err := ImportSnapshot(bytes.NewReader(data), store, prefix)
+	assert.NilError(t, err)
+	return loaded
+}
+
+func TestRESTORESNAPSHOT(t *testing.T) {
+	dir := t.TempDir()
+	defer func(dir string) { config.DiceConfig.Server.SnapshotDir = dir }(config.DiceConfig.Server.SnapshotDir)
+	config.DiceConfig.Server.SnapshotDir = dir
+
+	src := dstore.NewStore(nil)
+	for i := 0; i < 5; i++ {
+		evalSET([]string{"k" + strconv.Itoa(i), "v", Px, "100500"}, src)
+	}
+	var buf bytes.Buffer
+	_, err := ExportSnapshot(&buf, src, SnapshotVersion)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "snapshot.resp"), buf.Bytes(), 0o644))
+
+	status := func(store *dstore.Store, id int64) map[string]interface{} {
+		value, err := clientio.NewRESPParser(bytes.NewBuffer(evalRESTORESNAPSHOT([]string{"STATUS", strconv.FormatInt(id, 10)}, store))).DecodeOne()
+		assert.NilError(t, err)
+		pairs := value.([]interface{})
+		m := make(map[string]interface{})
+		for i := 0; i < len(pairs); i += 2 {
+			m[pairs[i].(string)] = pairs[i+1]
+		}
+		return m
+	}
+	start := func(store *dstore.Store, args ...string) int64 {
+		value, err := clientio.NewRESPParser(bytes.NewBuffer(evalRESTORESNAPSHOT(append([]string{"START"}, args...), store))).DecodeOne()
+		assert.NilError(t, err)
+		return value.(int64)
+	}
+
+	// the snapshot is loaded by the cron, BATCH commands at a time, the keys
+	// being marked dirty for the AOF
+	live := dstore.NewStore(nil)
+	dirty := live.TrackDirty()
+	dirty.Take()
+	id := start(live, "snapshot.resp", "PREFIX", "staging:", "BATCH", "3")
+	assert.Equal(t, "running", status(live, id)["state"])
+	dstore.RunRestoreJobs(live)
+	assert.Equal(t, int64(1), status(live, id)["keys"])
+	dstore.RunRestoreJobs(live)
+	dstore.RunRestoreJobs(live)
+	assert.Equal(t, "running", status(live, id)["state"])
+	dstore.RunRestoreJobs(live)
+	assert.Equal(t, "done", status(live, id)["state"])
+	assert.Equal(t, int64(5), status(live, id)["keys"])
+	assert.Equal(t, "v", evalGET([]string{"staging:k4"}, live).Result)
+	assert.Equal(t, 5, len(dirty.Take().Keys))
+
+	// a job is canceled, the keys already loaded being kept
+	id = start(live, "snapshot.resp", "PREFIX", "canceled:", "BATCH", "3")
+	dstore.RunRestoreJobs(live)
+	assert.DeepEqual(t, clientio.RespOne, evalRESTORESNAPSHOT([]string{"CANCEL", strconv.FormatInt(id, 10)}, live))
+	assert.DeepEqual(t, clientio.RespZero, evalRESTORESNAPSHOT([]string{"CANCEL", strconv.FormatInt(id, 10)}, live))
+	assert.Equal(t, "canceled", status(live, id)["state"])
+	assert.Equal(t, 6, live.GetKeyCount())
+
+	// a corrupted snapshot fails the job
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "bad.resp"), []byte("*1\r\n$7\r\nFLUSHDB\r\n"), 0o644))
+	id = start(live, "bad.resp", "PREFIX", "bad:")
+	dstore.RunRestoreJobs(live)
+	assert.Equal(t, "failed", status(live, id)["state"])
+	assert.Assert(t, strings.Contains(status(live, id)["error"].(string), "the commands touching no key cannot be restored"))
+
+	// the jobs are refused while replicas or CDC subscribers follow the store
+	live.SetFollowed(func() bool { return true })
+	assert.Assert(t, strings.Contains(string(evalRESTORESNAPSHOT([]string{"START", "snapshot.resp"}, live)), "replicas or CDC subscribers are attached"))
+	live.SetFollowed(nil)
+
+	// the snapshots must be in the snapshot directory
+	outside := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(outside, "secret.resp"), buf.Bytes(), 0o644))
+	assert.NilError(t, os.Symlink(filepath.Join(outside, "secret.resp"), filepath.Join(dir, "link.resp")))
+	for _, path := range []string{"../" + filepath.Base(outside) + "/secret.resp", filepath.Join(outside, "secret.resp"), "link.resp"} {
+		assert.Assert(t, strings.Contains(string(evalRESTORESNAPSHOT([]string{"START", path}, live)), "is not in the snapshot directory"), path)
+	}
+	assert.Assert(t, strings.Contains(string(evalRESTORESNAPSHOT([]string{"START", "missing.resp"}, live)), "could not open the snapshot"))
+	config.DiceConfig.Server.SnapshotDir = ""
+	assert.Assert(t, strings.Contains(string(evalRESTORESNAPSHOT([]string{"START", "snapshot.resp"}, live)), "no snapshot directory is configured"))
+
+	assert.DeepEqual(t, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr), evalRESTORESNAPSHOT([]string{"START", "snapshot.resp", "AS", "x"}, live))
+	assert.DeepEqual(t, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr), evalRESTORESNAPSHOT([]string{"START", "snapshot.resp", "BATCH", "0"}, live))
+}
+
+func dequeElements(obj *object.Obj) []string {
+	var items []string
+	obj.Value.(*Deque).Iterate(func(x string) bool {
+		items = append(items, x)
+		return true
+	})
+	return items
+}
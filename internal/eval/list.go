@@ -0,0 +1,463 @@
+package eval
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// getList returns the list stored at key, nil if the key does not exist, or an
+// encoded error if the key holds another type.
+func getList(key string, store *dstore.Store) (*Deque, []byte) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, nil
+	}
+
+	if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeByteList, object.ObjEncodingDeque); err != nil {
+		return nil, diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
+	return obj.Value.(*Deque), nil
+}
+
+// getOrCreateList returns the list stored at key, creating an empty one if the
+// key does not exist, or an encoded error if the key holds another type.
+func getOrCreateList(key string, store *dstore.Store) (*Deque, []byte) {
+	deq, errResp := getList(key, store)
+	if errResp != nil || deq != nil {
+		return deq, errResp
+	}
+
+	deq = NewDeque()
+	store.Put(key, store.NewObj(deq, -1, object.ObjTypeByteList, object.ObjEncodingDeque))
+	return deq, nil
+}
+
+// parseListSide parses the LEFT or RIGHT argument of a command, true meaning the
+// head of the list.
+func parseListSide(arg string) (bool, []byte) {
+	switch strings.ToUpper(arg) {
+	case Left:
+		return true, nil
+	case Right:
+		return false, nil
+	default:
+		return false, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+}
+
+// popList removes and returns up to count elements from the head or the tail of
+// the list stored at key, deleting the key once the list is empty.
+func popList(key string, deq *Deque, left bool, count int64, store *dstore.Store) []string {
+	elements := make([]string, 0, min(count, deq.Length))
+	for int64(len(elements)) < count && deq.Length > 0 {
+		var x string
+		if left {
+			x, _ = deq.LPop()
+		} else {
+			x, _ = deq.RPop()
+		}
+		elements = append(elements, x)
+	}
+
+	if deq.Length == 0 {
+		store.Del(key)
+	}
+	return elements
+}
+
+// firstNonEmptyList returns the first key holding a non-empty list, along with
+// the list, or an encoded error if one of the keys before it holds another
+// type.
+func firstNonEmptyList(keys []string, store *dstore.Store) (string, *Deque, []byte) {
+	for _, key := range keys {
+		deq, errResp := getList(key, store)
+		if errResp != nil {
+			return "", nil, errResp
+		}
+		if deq != nil && deq.Length > 0 {
+			return key, deq, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// evalBLPOP pops an element from the head of the first non-empty list among
+// the ones stored at the given keys, and returns the key along with the
+// element. If all the lists are empty, the client is blocked till one of them
+// is pushed to, or till the timeout in seconds elapses, 0 blocking forever.
+//
+// Usage: BLPOP key [key ...] timeout
+func evalBLPOP(args []string, store *dstore.Store) ([]byte, *Blocked) {
+	return blockingPop("BLPOP", args, true, store)
+}
+
+// evalBRPOP is the counterpart of evalBLPOP popping from the tail of the lists.
+//
+// Usage: BRPOP key [key ...] timeout
+func evalBRPOP(args []string, store *dstore.Store) ([]byte, *Blocked) {
+	return blockingPop("BRPOP", args, false, store)
+}
+
+func blockingPop(cmd string, args []string, left bool, store *dstore.Store) ([]byte, *Blocked) {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity(cmd), nil
+	}
+
+	keys := args[:len(args)-1]
+	timeout, errResp := parseBlockingTimeout(args[len(args)-1])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	key, deq, errResp := firstNonEmptyList(keys, store)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if deq == nil {
+		return nil, &Blocked{Keys: keys, Timeout: timeout}
+	}
+
+	element := popList(key, deq, left, 1, store)[0]
+	return clientio.Encode([]string{key, element}, false), nil
+}
+
+// evalBLMOVE pops an element from the head (LEFT) or the tail (RIGHT) of the
+// list stored at source, pushes it to the head or the tail of the list stored
+// at destination, and returns it. If the source list is empty, the client is
+// blocked till it is pushed to, or till the timeout in seconds elapses, 0
+// blocking forever.
+//
+// Usage: BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout
+func evalBLMOVE(args []string, store *dstore.Store) ([]byte, *Blocked) {
+	if len(args) != 5 {
+		return diceerrors.NewErrArity("BLMOVE"), nil
+	}
+
+	src, dst := args[0], args[1]
+	fromLeft, errResp := parseListSide(args[2])
+	if errResp != nil {
+		return errResp, nil
+	}
+	toLeft, errResp := parseListSide(args[3])
+	if errResp != nil {
+		return errResp, nil
+	}
+	timeout, errResp := parseBlockingTimeout(args[4])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	element, moved, errResp := moveListElement(src, dst, fromLeft, toLeft, store)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if !moved {
+		return nil, &Blocked{Keys: []string{src}, Timeout: timeout}
+	}
+	return clientio.Encode(element, false), nil
+}
+
+// evalLMOVE pops an element from the head (LEFT) or the tail (RIGHT) of the
+// list stored at source, pushes it to the head or the tail of the list stored
+// at destination, and returns it. The destination list is created if needed,
+// and the source key is deleted once its list is empty. Returns nil if the
+// source list does not exist.
+//
+// Usage: LMOVE source destination LEFT|RIGHT LEFT|RIGHT
+func evalLMOVE(args []string, store *dstore.Store) []byte {
+	if len(args) != 4 {
+		return diceerrors.NewErrArity("LMOVE")
+	}
+
+	fromLeft, errResp := parseListSide(args[2])
+	if errResp != nil {
+		return errResp
+	}
+	toLeft, errResp := parseListSide(args[3])
+	if errResp != nil {
+		return errResp
+	}
+	return lmoveHelper(args[0], args[1], fromLeft, toLeft, store)
+}
+
+// evalRPOPLPUSH is the legacy form of LMOVE source destination RIGHT LEFT.
+//
+// Usage: RPOPLPUSH source destination
+func evalRPOPLPUSH(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("RPOPLPUSH")
+	}
+	return lmoveHelper(args[0], args[1], false, true, store)
+}
+
+func lmoveHelper(src, dst string, fromLeft, toLeft bool, store *dstore.Store) []byte {
+	element, moved, errResp := moveListElement(src, dst, fromLeft, toLeft, store)
+	if errResp != nil {
+		return errResp
+	}
+	if !moved {
+		return clientio.RespNIL
+	}
+	return clientio.Encode(element, false)
+}
+
+// moveListElement pops an element from the head or the tail of the list stored
+// at src and pushes it to the head or the tail of the list stored at dst,
+// creating it if needed. It returns false if the source list is empty, or an
+// encoded error if one of the keys holds another type, in which case nothing
+// is moved.
+func moveListElement(src, dst string, fromLeft, toLeft bool, store *dstore.Store) (string, bool, []byte) {
+	srcDeq, errResp := getList(src, store)
+	if errResp != nil {
+		return "", false, errResp
+	}
+	if srcDeq == nil || srcDeq.Length == 0 {
+		return "", false, nil
+	}
+
+	// the destination is checked before popping, so that nothing is lost
+	if _, errResp := getList(dst, store); errResp != nil {
+		return "", false, errResp
+	}
+
+	element := popList(src, srcDeq, fromLeft, 1, store)[0]
+	dstDeq, _ := getOrCreateList(dst, store)
+	if toLeft {
+		dstDeq.LPush(element)
+	} else {
+		dstDeq.RPush(element)
+	}
+	return element, true, nil
+}
+
+// evalLMPOP pops up to count elements, 1 by default, from the head (LEFT) or
+// the tail (RIGHT) of the first non-empty list among the ones stored at the
+// given keys, and returns the key along with the elements. Returns nil if all
+// the lists are empty.
+//
+// Usage: LMPOP numkeys key [key ...] LEFT|RIGHT [COUNT count]
+func evalLMPOP(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("LMPOP")
+	}
+
+	keys, left, count, errResp := parseMPopArgs(args, parseListSide)
+	if errResp != nil {
+		return errResp
+	}
+
+	key, deq, errResp := firstNonEmptyList(keys, store)
+	if errResp != nil {
+		return errResp
+	}
+	if deq == nil {
+		return clientio.RespNIL
+	}
+
+	elements := popList(key, deq, left, count, store)
+	return clientio.Encode([]interface{}{key, elements}, false)
+}
+
+// evalBLMPOP is the blocking variant of LMPOP. If all the lists are empty, the
+// client is blocked till one of them is pushed to, or till the timeout in
+// seconds elapses, 0 blocking forever.
+//
+// Usage: BLMPOP timeout numkeys key [key ...] LEFT|RIGHT [COUNT count]
+func evalBLMPOP(args []string, store *dstore.Store) ([]byte, *Blocked) {
+	if len(args) < 4 {
+		return diceerrors.NewErrArity("BLMPOP"), nil
+	}
+
+	timeout, errResp := parseBlockingTimeout(args[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	keys, left, count, errResp := parseMPopArgs(args[1:], parseListSide)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	key, deq, errResp := firstNonEmptyList(keys, store)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if deq == nil {
+		return nil, &Blocked{Keys: keys, Timeout: timeout}
+	}
+
+	elements := popList(key, deq, left, count, store)
+	return clientio.Encode([]interface{}{key, elements}, false), nil
+}
+
+// parseMPopArgs parses the arguments shared by LMPOP, ZMPOP and their blocking
+// variants, that is numkeys key [key ...] side [COUNT count], side being parsed
+// by parseSide, e.g. LEFT|RIGHT for the lists.
+func parseMPopArgs(args []string, parseSide func(arg string) (bool, []byte)) (keys []string, side bool, count int64, errResp []byte) {
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil || numKeys <= 0 {
+		return nil, false, 0, diceerrors.NewErrWithMessage("numkeys should be greater than 0")
+	}
+	if numKeys > len(args)-2 {
+		return nil, false, 0, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	keys = args[1 : 1+numKeys]
+
+	if side, errResp = parseSide(args[1+numKeys]); errResp != nil {
+		return nil, false, 0, errResp
+	}
+
+	count = 1
+	if opts := args[2+numKeys:]; len(opts) > 0 {
+		if len(opts) != 2 || !strings.EqualFold(opts[0], Count) {
+			return nil, false, 0, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		if count, err = strconv.ParseInt(opts[1], 10, 64); err != nil || count <= 0 {
+			return nil, false, 0, diceerrors.NewErrWithMessage("count should be greater than 0")
+		}
+	}
+	return keys, side, count, nil
+}
+
+// evalLINSERT inserts element before or after the first element equal to pivot
+// in the list stored at key, and returns the length of the list. Returns -1 if
+// pivot is not found, or 0 if the key does not exist.
+//
+// Usage: LINSERT key BEFORE|AFTER pivot element
+func evalLINSERT(args []string, store *dstore.Store) []byte {
+	if len(args) != 4 {
+		return diceerrors.NewErrArity("LINSERT")
+	}
+
+	var before bool
+	switch strings.ToUpper(args[1]) {
+	case Before:
+		before = true
+	case After:
+		before = false
+	default:
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+
+	deq, errResp := getList(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+	if deq == nil {
+		return clientio.Encode(0, false)
+	}
+
+	if !deq.Insert(args[2], args[3], before) {
+		return clientio.Encode(-1, false)
+	}
+	return clientio.Encode(deq.Length, false)
+}
+
+// evalLPOS returns the position of the first element equal to element in the
+// list stored at key, or nil if there is none. RANK starts from the rank-th
+// match, from the tail if negative. With COUNT, the positions of up to count
+// matches are returned, all of them if count is 0. MAXLEN compares at most
+// maxlen elements, all of them if 0.
+//
+// Usage: LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len]
+func evalLPOS(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 || len(args)%2 != 0 {
+		return diceerrors.NewErrArity("LPOS")
+	}
+
+	rank, count, maxLen := int64(1), int64(-1), int64(0)
+	for i := 2; i < len(args); i += 2 {
+		value, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+
+		switch strings.ToUpper(args[i]) {
+		case Rank:
+			if value == 0 || value == math.MinInt64 {
+				return diceerrors.NewErrWithMessage("RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list")
+			}
+			rank = value
+		case Count:
+			if value < 0 {
+				return diceerrors.NewErrWithMessage("COUNT can't be negative")
+			}
+			count = value
+		case MaxLen:
+			if value < 0 {
+				return diceerrors.NewErrWithMessage("MAXLEN can't be negative")
+			}
+			maxLen = value
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	deq, errResp := getList(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+
+	// without COUNT, the first position is returned as an integer
+	limit := count
+	if count < 0 {
+		limit = 1
+	}
+	positions := []int64{}
+	if deq != nil {
+		positions = deq.Positions(args[1], rank, limit, maxLen)
+	}
+
+	if count >= 0 {
+		return clientio.Encode(positions, false)
+	}
+	if len(positions) == 0 {
+		return clientio.RespNIL
+	}
+	return clientio.Encode(positions[0], false)
+}
+
+// evalLPUSHX pushes the elements to the head of the list stored at key, only
+// if the key already holds a list, and returns the length of the list, 0 if
+// the key does not exist.
+//
+// Usage: LPUSHX key element [element ...]
+func evalLPUSHX(args []string, store *dstore.Store) []byte {
+	return pushxHelper("LPUSHX", args, true, store)
+}
+
+// evalRPUSHX is the counterpart of evalLPUSHX pushing to the tail of the list.
+//
+// Usage: RPUSHX key element [element ...]
+func evalRPUSHX(args []string, store *dstore.Store) []byte {
+	return pushxHelper("RPUSHX", args, false, store)
+}
+
+func pushxHelper(cmd string, args []string, left bool, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity(cmd)
+	}
+
+	deq, errResp := getList(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+	if deq == nil {
+		return clientio.Encode(0, false)
+	}
+
+	for _, element := range args[1:] {
+		if left {
+			deq.LPush(element)
+		} else {
+			deq.RPush(element)
+		}
+	}
+	return clientio.Encode(deq.Length, false)
+}
@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestBlockingListPops(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) *EvalResponse {
+		return ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+	result := func(name string, args ...string) string {
+		return string(exec(name, args...).Result.([]byte))
+	}
+
+	exec("RPUSH", "b", "1", "2", "3")
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$1\r\n1\r\n", result("BLPOP", "a", "b", "0"))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$1\r\n3\r\n", result("BRPOP", "a", "b", "0"))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$1\r\n2\r\n", result("BLPOP", "b", "0"))
+
+	// the key is deleted once the list is empty
+	assert.Equal(t, ":0\r\n", result("EXISTS", "b"))
+
+	resp := exec("BLPOP", "a", "b", "1.5")
+	assert.Equal(t, "$-1\r\n", string(resp.Result.([]byte)))
+	assert.DeepEqual(t, &Blocked{Keys: []string{"a", "b"}, Timeout: 1500 * time.Millisecond}, resp.Blocked)
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", result("BLPOP", "str", "0"))
+	assert.Equal(t, "-ERR timeout is not a float or out of range\r\n", result("BLPOP", "a", "abc"))
+	assert.Equal(t, "-ERR timeout is negative\r\n", result("BRPOP", "a", "-1"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'blpop' command\r\n", result("BLPOP", "a"))
+}
+
+func TestBLMOVE(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) *EvalResponse {
+		return ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+	result := func(name string, args ...string) string {
+		return string(exec(name, args...).Result.([]byte))
+	}
+
+	exec("RPUSH", "src", "a", "b", "c")
+	assert.Equal(t, "$1\r\nc\r\n", result("BLMOVE", "src", "dst", "RIGHT", "LEFT", "0"))
+	assert.Equal(t, "$1\r\na\r\n", result("BLMOVE", "src", "dst", "left", "left", "0"))
+	assert.Equal(t, "$1\r\nb\r\n", result("BLMOVE", "src", "src", "LEFT", "RIGHT", "0"))
+	assert.Equal(t, ":2\r\n", result("LLEN", "dst"))
+	assert.Equal(t, "$1\r\na\r\n", result("LPOP", "dst"))
+
+	resp := exec("BLMOVE", "empty", "dst", "LEFT", "LEFT", "0")
+	assert.DeepEqual(t, &Blocked{Keys: []string{"empty"}}, resp.Blocked)
+
+	// nothing is popped if the destination holds another type
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", result("BLMOVE", "src", "str", "LEFT", "LEFT", "0"))
+	assert.Equal(t, ":1\r\n", result("LLEN", "src"))
+	assert.Equal(t, "-ERR syntax error\r\n", result("BLMOVE", "src", "dst", "UP", "LEFT", "0"))
+}
+
+func TestLMOVE(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, "$-1\r\n", exec("LMOVE", "src", "dst", "LEFT", "RIGHT"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "dst"))
+
+	exec("RPUSH", "src", "a", "b", "c")
+	assert.Equal(t, "$1\r\na\r\n", exec("LMOVE", "src", "dst", "LEFT", "RIGHT"))
+	assert.Equal(t, "$1\r\nc\r\n", exec("RPOPLPUSH", "src", "dst"))
+	assert.Equal(t, "$1\r\nc\r\n", exec("LPOP", "dst"))
+	assert.Equal(t, "$1\r\na\r\n", exec("LPOP", "dst"))
+
+	// rotating a list onto itself
+	exec("RPUSH", "src", "d")
+	assert.Equal(t, "$1\r\nb\r\n", exec("LMOVE", "src", "src", "LEFT", "RIGHT"))
+	assert.Equal(t, "$1\r\nd\r\n", exec("LPOP", "src"))
+
+	// the source key is deleted once its list is empty
+	assert.Equal(t, "$1\r\nb\r\n", exec("RPOPLPUSH", "src", "dst"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "src"))
+	assert.Equal(t, ":1\r\n", exec("LLEN", "dst"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("LMOVE", "str", "dst", "LEFT", "LEFT"))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("RPOPLPUSH", "dst", "str"))
+	assert.Equal(t, ":1\r\n", exec("LLEN", "dst"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("LMOVE", "dst", "src", "LEFT", "DOWN"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'rpoplpush' command\r\n", exec("RPOPLPUSH", "dst"))
+}
+
+func TestLMPOP(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, "$-1\r\n", exec("LMPOP", "2", "a", "b", "LEFT"))
+
+	exec("RPUSH", "b", "1", "2", "3")
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n*1\r\n$1\r\n1\r\n", exec("LMPOP", "2", "a", "b", "LEFT"))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n*2\r\n$1\r\n3\r\n$1\r\n2\r\n", exec("LMPOP", "2", "a", "b", "right", "count", "5"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "b"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("LMPOP", "2", "a", "str", "LEFT"))
+	assert.Equal(t, "-ERR numkeys should be greater than 0\r\n", exec("LMPOP", "abc", "a", "LEFT"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("LMPOP", "1", "a", "MIDDLE"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'lmpop' command\r\n", exec("LMPOP", "1", "a"))
+}
+
+func TestBLMPOP(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) *EvalResponse {
+		return ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+	result := func(name string, args ...string) string {
+		return string(exec(name, args...).Result.([]byte))
+	}
+
+	exec("RPUSH", "b", "1", "2", "3")
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n*2\r\n$1\r\n1\r\n$1\r\n2\r\n", result("BLMPOP", "0", "2", "a", "b", "LEFT", "COUNT", "2"))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n*1\r\n$1\r\n3\r\n", result("BLMPOP", "0", "1", "b", "RIGHT", "COUNT", "10"))
+
+	resp := exec("BLMPOP", "0.1", "2", "a", "b", "RIGHT")
+	assert.DeepEqual(t, &Blocked{Keys: []string{"a", "b"}, Timeout: 100 * time.Millisecond}, resp.Blocked)
+
+	assert.Equal(t, "-ERR numkeys should be greater than 0\r\n", result("BLMPOP", "0", "0", "a", "LEFT"))
+	assert.Equal(t, "-ERR syntax error\r\n", result("BLMPOP", "0", "3", "a", "LEFT"))
+	assert.Equal(t, "-ERR syntax error\r\n", result("BLMPOP", "0", "1", "a", "LEFT", "COUNT"))
+	assert.Equal(t, "-ERR count should be greater than 0\r\n", result("BLMPOP", "0", "1", "a", "LEFT", "COUNT", "0"))
+}
+
+func TestBlockedClientsWokenUpByWrites(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) *EvalResponse {
+		return ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+
+	var served []string
+	block := func(name string, args ...string) {
+		c := &cmd.DiceDBCmd{Cmd: name, Args: args}
+		resp := ExecuteCommand(c, nil, store, false, false)
+		assert.Assert(t, resp.Blocked != nil)
+		store.Block(&dstore.Waiter{
+			Keys: resp.Blocked.Keys,
+			Serve: func() bool {
+				resp := ExecuteCommand(c, nil, store, false, false)
+				if resp.Blocked != nil {
+					return false
+				}
+				served = append(served, string(resp.Result.([]byte)))
+				return true
+			},
+		})
+	}
+
+	block("BLPOP", "a", "0")
+	block("BLMOVE", "b", "a", "LEFT", "LEFT", "0")
+	block("BRPOP", "a", "0")
+
+	// a blocked command wakes up no one
+	dstore.ServeBlocked(store)
+	assert.Equal(t, 0, len(served))
+
+	// the element moved by BLMOVE serves the next client blocked on its destination
+	exec("RPUSH", "b", "x")
+	dstore.ServeBlocked(store)
+	assert.DeepEqual(t, []string{"$1\r\nx\r\n", "*2\r\n$1\r\na\r\n$1\r\nx\r\n"}, served)
+	assert.Assert(t, store.HasWaiters())
+
+	exec("RPUSH", "a", "y", "z")
+	dstore.ServeBlocked(store)
+	assert.Equal(t, "*2\r\n$1\r\na\r\n$1\r\nz\r\n", served[2])
+	assert.Assert(t, !store.HasWaiters())
+}
+
+func TestLINSERT(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, ":0\r\n", exec("LINSERT", "list", "BEFORE", "a", "x"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "list"))
+
+	exec("RPUSH", "list", "a", "b", "a")
+	assert.Equal(t, ":4\r\n", exec("LINSERT", "list", "before", "a", "x"))
+	assert.Equal(t, ":5\r\n", exec("LINSERT", "list", "AFTER", "b", "y"))
+	assert.Equal(t, ":-1\r\n", exec("LINSERT", "list", "AFTER", "c", "z"))
+	assert.Equal(t, "*2\r\n:1\r\n:4\r\n", exec("LPOS", "list", "a", "COUNT", "0"))
+	assert.Equal(t, ":3\r\n", exec("LPOS", "list", "y"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("LINSERT", "str", "BEFORE", "a", "x"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("LINSERT", "list", "AROUND", "a", "x"))
+}
+
+func TestLPOS(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, "$-1\r\n", exec("LPOS", "list", "a"))
+	assert.Equal(t, "*0\r\n", exec("LPOS", "list", "a", "COUNT", "1"))
+
+	exec("RPUSH", "list", "a", "b", "c", "1", "2", "3", "c", "c")
+	assert.Equal(t, ":2\r\n", exec("LPOS", "list", "c"))
+	assert.Equal(t, ":6\r\n", exec("LPOS", "list", "c", "RANK", "2"))
+	assert.Equal(t, ":7\r\n", exec("LPOS", "list", "c", "RANK", "-1"))
+	assert.Equal(t, "$-1\r\n", exec("LPOS", "list", "x"))
+	assert.Equal(t, "*2\r\n:2\r\n:6\r\n", exec("LPOS", "list", "c", "COUNT", "2"))
+	assert.Equal(t, "*3\r\n:7\r\n:6\r\n:2\r\n", exec("LPOS", "list", "c", "RANK", "-1", "COUNT", "0"))
+	assert.Equal(t, "*1\r\n:2\r\n", exec("LPOS", "list", "c", "COUNT", "0", "MAXLEN", "3"))
+	assert.Equal(t, "$-1\r\n", exec("LPOS", "list", "c", "MAXLEN", "2"))
+
+	assert.Equal(t, "-ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list\r\n", exec("LPOS", "list", "c", "RANK", "0"))
+	assert.Equal(t, "-ERR COUNT can't be negative\r\n", exec("LPOS", "list", "c", "COUNT", "-1"))
+	assert.Equal(t, "-ERR MAXLEN can't be negative\r\n", exec("LPOS", "list", "c", "MAXLEN", "-1"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("LPOS", "list", "c", "RANK", "x"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("LPOS", "list", "c", "FOO", "1"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'lpos' command\r\n", exec("LPOS", "list", "c", "RANK"))
+}
+
+func TestPushX(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, ":0\r\n", exec("LPUSHX", "list", "a"))
+	assert.Equal(t, ":0\r\n", exec("RPUSHX", "list", "a"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "list"))
+
+	exec("RPUSH", "list", "b")
+	assert.Equal(t, ":3\r\n", exec("LPUSHX", "list", "a", "c"))
+	assert.Equal(t, ":4\r\n", exec("RPUSHX", "list", "d"))
+	assert.Equal(t, "$1\r\nc\r\n", exec("LPOP", "list"))
+	assert.Equal(t, "$1\r\nd\r\n", exec("RPOP", "list"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("RPUSHX", "str", "a"))
+}
@@ -0,0 +1,375 @@
+package eval
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// propagationRewriter returns the commands to propagate in place of c, which was
+// executed successfully with the given reply. The store holds the state that
+// results from the execution of c.
+type propagationRewriter func(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd
+
+// propagationRewriters rewrite the commands whose effects depend on the time
+// they are executed at or on values computed while executing them. The
+// rewritten commands have the same effects whenever they are applied, so that
+// the replicas converge exactly with the primary.
+var propagationRewriters = map[string]propagationRewriter{
+	"SET":              rewriteSET,
+	"SETEX":            rewriteSETEX,
+	"HSETEX":           rewriteHSETEX,
+	"GETEX":            rewriteGETEX,
+	"EXPIRE":           rewriteEXPIRE,
+	"PEXPIRE":          rewritePEXPIRE,
+	"EXPIREMEMBER":     rewriteEXPIREMEMBER,
+	"HEXPIRE":          rewriteHEXPIRE,
+	"HPEXPIRE":         rewriteHEXPIRE,
+	"INCRBYFLOAT":      rewriteINCRBYFLOAT,
+	"HINCRBYFLOAT":     rewriteHINCRBYFLOAT,
+	"XADD":             rewriteXADD,
+	"SPOP":             rewriteSPOP,
+	"COUNTER.INCR":     rewriteCOUNTERINCR,
+	"TTLJOB":           rewriteTTLJOB,
+	"RESTORE-SNAPSHOT": rewriteRESTORESNAPSHOT,
+	"SINTERSTORE":      rewriteSetStore,
+	"SUNIONSTORE":      rewriteSetStore,
+	"SDIFFSTORE":       rewriteSetStore,
+	"ZUNIONSTORE":      rewriteZSetStore,
+	"ZINTERSTORE":      rewriteZSetStore,
+	"MOVE":             rewriteMOVE,
+	"SWAPDB":           rewriteSWAPDB,
+}
+
+// PropagatedCommands returns the commands to propagate to the replicas for the
+// write command c, executed successfully with the response resp. Most commands
+// are propagated as is, the others are rewritten into deterministic commands.
+func PropagatedCommands(c *cmd.DiceDBCmd, resp *EvalResponse, store *dstore.Store) []*cmd.DiceDBCmd {
+	rewrite, ok := propagationRewriters[c.Cmd]
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return rewrite(c, decodeReply(resp.Result), store)
+}
+
+// decodeReply returns the value of a reply, decoding it if it is RESP encoded.
+// A nil reply is returned as nil.
+func decodeReply(result interface{}) interface{} {
+	b, ok := result.([]byte)
+	if !ok {
+		if result == clientio.NIL {
+			return nil
+		}
+		return result
+	}
+	if bytes.Equal(b, clientio.RespNIL) {
+		return nil
+	}
+
+	value, err := clientio.NewRESPParser(bytes.NewBuffer(b)).DecodeOne()
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// expiryOf returns the expiration time of key, in unix-time-milliseconds. The key
+// is read as is, so that reading it never deletes it, even if it expired.
+func expiryOf(key string, store *dstore.Store) (string, bool) {
+	obj, ok := store.GetStore().Get(key)
+	if !ok {
+		return "", false
+	}
+	exp, ok := dstore.GetExpiry(obj, store)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatUint(exp, 10), true
+}
+
+// rewriteSET replaces the relative expiration of SET with the absolute one.
+func rewriteSET(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	if reply == nil || len(c.Args) < 2 {
+		return nil
+	}
+
+	args := append([]string(nil), c.Args[:2]...)
+	for i := 2; i < len(c.Args); i++ {
+		opt := strings.ToUpper(c.Args[i])
+		if (opt == Ex || opt == Px) && i+1 < len(c.Args) {
+			exp, ok := expiryOf(c.Args[0], store)
+			if !ok {
+				return []*cmd.DiceDBCmd{c}
+			}
+			args = append(args, Pxat, exp)
+			i++
+			continue
+		}
+		args = append(args, c.Args[i])
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "SET", Args: args}}
+}
+
+// rewriteSETEX propagates SETEX key seconds value as SET key value PXAT.
+func rewriteSETEX(c *cmd.DiceDBCmd, _ interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	exp, ok := expiryOf(c.Args[0], store)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{c.Args[0], c.Args[2], Pxat, exp}}}
+}
+
+// rewriteHSETEX propagates HSETEX key seconds field value [field value ...] as
+// HSET key field value [field value ...] followed by PEXPIREAT.
+func rewriteHSETEX(c *cmd.DiceDBCmd, _ interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	exp, ok := expiryOf(c.Args[0], store)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return []*cmd.DiceDBCmd{
+		{Cmd: "HSET", Args: append([]string{c.Args[0]}, c.Args[2:]...)},
+		{Cmd: "PEXPIREAT", Args: []string{c.Args[0], exp}},
+	}
+}
+
+// rewriteGETEX propagates the expiration set by GETEX as an absolute one. GETEX
+// without options does not modify the key and is not propagated.
+func rewriteGETEX(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	if len(c.Args) == 1 || reply == nil {
+		return nil
+	}
+
+	switch strings.ToUpper(c.Args[1]) {
+	case Ex, Px, Exat, Pxat:
+		if exp, ok := expiryOf(c.Args[0], store); ok {
+			return []*cmd.DiceDBCmd{{Cmd: "GETEX", Args: []string{c.Args[0], Pxat, exp}}}
+		}
+	}
+	return []*cmd.DiceDBCmd{c}
+}
+
+// rewriteEXPIRE propagates the expiration set by EXPIRE as EXPIREAT. EXPIRE is
+// not propagated if it did not set the expiration, e.g. because of NX or GT.
+func rewriteEXPIRE(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	if reply != int64(1) {
+		return nil
+	}
+
+	exp, ok := expiryOf(c.Args[0], store)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	// EXPIRE sets the expiration with a precision of one second
+	ms, _ := strconv.ParseUint(exp, 10, 64)
+	return []*cmd.DiceDBCmd{{Cmd: "EXPIREAT", Args: []string{c.Args[0], strconv.FormatUint(ms/1000, 10)}}}
+}
+
+// rewritePEXPIRE propagates the expiration set by PEXPIRE as PEXPIREAT. Like
+// EXPIRE, it is not propagated if it did not set the expiration.
+func rewritePEXPIRE(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	if reply != int64(1) {
+		return nil
+	}
+
+	exp, ok := expiryOf(c.Args[0], store)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "PEXPIREAT", Args: []string{c.Args[0], exp}}}
+}
+
+// rewriteEXPIREMEMBER replaces the relative expiry of the member with the
+// absolute one. A member deleted right away is deleted by the replicas as well.
+func rewriteEXPIREMEMBER(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	if reply != int64(1) {
+		return nil
+	}
+
+	exp, ok := store.FieldExpiry(c.Args[0], c.Args[1])
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "PEXPIREMEMBERAT", Args: []string{c.Args[0], c.Args[1], strconv.FormatUint(exp, 10)}}}
+}
+
+// rewriteHEXPIRE propagates the expiries set by HEXPIRE and HPEXPIRE as
+// HPEXPIREAT, without condition as the fields whose expiry is not set are left
+// out, and the fields deleted right away as HDEL.
+func rewriteHEXPIRE(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	replies, ok := reply.([]interface{})
+	rest := c.Args[2:]
+	if len(rest) > 0 && !strings.EqualFold(rest[0], Fields) {
+		rest = rest[1:]
+	}
+	fields, errResp := parseHashFields(rest)
+	if !ok || errResp != nil || len(replies) != len(fields) {
+		return []*cmd.DiceDBCmd{c}
+	}
+
+	var set, deleted []string
+	for i, field := range fields {
+		switch replies[i] {
+		case int64(hfieldSet):
+			set = append(set, field)
+		case int64(hfieldExpiredNow):
+			deleted = append(deleted, field)
+		}
+	}
+
+	var cmds []*cmd.DiceDBCmd
+	if len(set) > 0 {
+		exp, ok := store.FieldExpiry(c.Args[0], set[0])
+		if !ok {
+			return []*cmd.DiceDBCmd{c}
+		}
+		args := append([]string{c.Args[0], strconv.FormatUint(exp, 10), Fields, strconv.Itoa(len(set))}, set...)
+		cmds = append(cmds, &cmd.DiceDBCmd{Cmd: "HPEXPIREAT", Args: args})
+	}
+	if len(deleted) > 0 {
+		cmds = append(cmds, &cmd.DiceDBCmd{Cmd: "HDEL", Args: append([]string{c.Args[0]}, deleted...)})
+	}
+	return cmds
+}
+
+// rewriteSetStore propagates SINTERSTORE, SUNIONSTORE and SDIFFSTORE with a
+// relative TTL, EX or PX, without it, followed by PEXPIREAT for the
+// destination. INHERITTTL is deterministic, the source keys having the same
+// expiry on the replicas.
+func rewriteSetStore(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	keys, opts, errResp := parseStoreTTLOpts(c.Cmd, c.Args[1:])
+	if errResp != nil || opts.expMs < 0 {
+		return []*cmd.DiceDBCmd{c}
+	}
+
+	cmds := []*cmd.DiceDBCmd{{Cmd: c.Cmd, Args: append([]string{c.Args[0]}, keys...)}}
+	if reply == int64(0) {
+		return cmds
+	}
+	exp, ok := expiryOf(c.Args[0], store)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return append(cmds, &cmd.DiceDBCmd{Cmd: "PEXPIREAT", Args: []string{c.Args[0], exp}})
+}
+
+// rewriteZSetStore propagates ZUNIONSTORE and ZINTERSTORE with a relative TTL
+// like rewriteSetStore does for the sets.
+func rewriteZSetStore(c *cmd.DiceDBCmd, reply interface{}, store *dstore.Store) []*cmd.DiceDBCmd {
+	if len(c.Args) < 3 {
+		return []*cmd.DiceDBCmd{c}
+	}
+	op, errResp := parseZSetOpArgs(c.Cmd, c.Args[1:], true, true)
+	if errResp != nil || op.ttl.expMs < 0 {
+		return []*cmd.DiceDBCmd{c}
+	}
+
+	// neither the weights nor the aggregate functions can be mistaken for EX
+	// or PX, the options are dropped along with their value
+	optsIdx := 2 + len(op.keys)
+	args := append([]string{}, c.Args[:optsIdx]...)
+	for i := optsIdx; i < len(c.Args); i++ {
+		if opt := strings.ToUpper(c.Args[i]); opt == Ex || opt == Px {
+			i++
+			continue
+		}
+		args = append(args, c.Args[i])
+	}
+
+	cmds := []*cmd.DiceDBCmd{{Cmd: c.Cmd, Args: args}}
+	if reply == int64(0) {
+		return cmds
+	}
+	exp, ok := expiryOf(c.Args[0], store)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return append(cmds, &cmd.DiceDBCmd{Cmd: "PEXPIREAT", Args: []string{c.Args[0], exp}})
+}
+
+// rewriteTTLJOB never propagates TTLJOB: the replicas do not run the jobs, the
+// primary propagates the expiries set by its jobs instead.
+func rewriteTTLJOB(*cmd.DiceDBCmd, interface{}, *dstore.Store) []*cmd.DiceDBCmd {
+	return nil
+}
+
+// rewriteRESTORESNAPSHOT never propagates RESTORE-SNAPSHOT: the restore jobs
+// only run while no replica follows the primary, the replicas syncing the keys
+// they loaded along with the rest of the dataset.
+func rewriteRESTORESNAPSHOT(*cmd.DiceDBCmd, interface{}, *dstore.Store) []*cmd.DiceDBCmd {
+	return nil
+}
+
+// rewriteMOVE propagates the key moved out of the database 0, the only one the
+// replicas hold, as its deletion.
+func rewriteMOVE(c *cmd.DiceDBCmd, reply interface{}, _ *dstore.Store) []*cmd.DiceDBCmd {
+	if reply != int64(1) {
+		return nil
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "DEL", Args: []string{c.Args[0]}}}
+}
+
+// rewriteSWAPDB never propagates SWAPDB: the replicas only hold the database 0,
+// they are disconnected to sync again once it is swapped.
+func rewriteSWAPDB(*cmd.DiceDBCmd, interface{}, *dstore.Store) []*cmd.DiceDBCmd {
+	return nil
+}
+
+// rewriteINCRBYFLOAT propagates the result of INCRBYFLOAT, so that replicas
+// don't accumulate floating point rounding differences.
+func rewriteINCRBYFLOAT(c *cmd.DiceDBCmd, reply interface{}, _ *dstore.Store) []*cmd.DiceDBCmd {
+	value, ok := reply.(string)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{c.Args[0], value, KeepTTL}}}
+}
+
+// rewriteHINCRBYFLOAT propagates the result of HINCRBYFLOAT as HSET.
+func rewriteHINCRBYFLOAT(c *cmd.DiceDBCmd, reply interface{}, _ *dstore.Store) []*cmd.DiceDBCmd {
+	value, ok := reply.(string)
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "HSET", Args: []string{c.Args[0], c.Args[1], value}}}
+}
+
+// rewriteSPOP propagates the members removed at random by SPOP as SREM.
+func rewriteSPOP(c *cmd.DiceDBCmd, reply interface{}, _ *dstore.Store) []*cmd.DiceDBCmd {
+	var members []string
+	switch popped := reply.(type) {
+	case string:
+		members = []string{popped}
+	case []interface{}:
+		for _, member := range popped {
+			if m, ok := member.(string); ok {
+				members = append(members, m)
+			}
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return []*cmd.DiceDBCmd{{Cmd: "SREM", Args: append([]string{c.Args[0]}, members...)}}
+}
+
+// rewriteXADD replaces the ID of XADD, which may be generated from the time,
+// with the ID of the added entry.
+func rewriteXADD(c *cmd.DiceDBCmd, reply interface{}, _ *dstore.Store) []*cmd.DiceDBCmd {
+	id, ok := reply.(string)
+	if !ok {
+		// NOMKSTREAM did not add any entry
+		return nil
+	}
+
+	i, _, _, errResp := parseXADDOpts(c.Args)
+	if errResp != nil || i >= len(c.Args) {
+		return []*cmd.DiceDBCmd{c}
+	}
+	args := append([]string(nil), c.Args...)
+	args[i] = id
+	return []*cmd.DiceDBCmd{{Cmd: "XADD", Args: args}}
+}
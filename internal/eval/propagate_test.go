@@ -0,0 +1,237 @@
+package eval
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestPropagatedCommands(t *testing.T) {
+	store := dstore.NewDatabases(2, func() *dstore.Store { return dstore.NewStore(nil) }).Get(0)
+	now := utils.GetCurrentTime()
+	utils.CurrentTime = &utils.MockClock{CurrTime: now}
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	ms := func(d int64) string { return strconv.FormatInt(now.UnixMilli()+d, 10) }
+
+	tests := []struct {
+		name     string
+		setup    []string
+		command  []string
+		expected [][]string
+	}{
+		{
+			name:     "deterministic command",
+			command:  []string{"SET", "k", "v"},
+			expected: [][]string{{"SET", "k", "v"}},
+		},
+		{
+			name:     "SET with a relative expiration",
+			command:  []string{"SET", "k", "v", "px", "1500", "NX"},
+			expected: [][]string{{"SET", "k", "v", "PXAT", ms(1500), "NX"}},
+		},
+		{
+			name:     "SET not performed",
+			setup:    []string{"SET", "k", "v"},
+			command:  []string{"SET", "k", "v2", "EX", "10", "NX"},
+			expected: nil,
+		},
+		{
+			name:     "SETEX",
+			command:  []string{"SETEX", "k", "10", "v"},
+			expected: [][]string{{"SET", "k", "v", "PXAT", ms(10000)}},
+		},
+		{
+			name:     "HSETEX",
+			command:  []string{"HSETEX", "h", "10", "f", "v", "g", "w"},
+			expected: [][]string{{"HSET", "h", "f", "v", "g", "w"}, {"PEXPIREAT", "h", ms(10000)}},
+		},
+		{
+			name:     "GETEX with a relative expiration",
+			setup:    []string{"SET", "k", "v"},
+			command:  []string{"GETEX", "k", "PX", "200"},
+			expected: [][]string{{"GETEX", "k", "PXAT", ms(200)}},
+		},
+		{
+			name:     "GETEX PERSIST",
+			setup:    []string{"SET", "k", "v", "EX", "10"},
+			command:  []string{"GETEX", "k", "PERSIST"},
+			expected: [][]string{{"GETEX", "k", "PERSIST"}},
+		},
+		{
+			name:     "GETEX without options",
+			setup:    []string{"SET", "k", "v"},
+			command:  []string{"GETEX", "k"},
+			expected: nil,
+		},
+		{
+			name:     "EXPIRE",
+			setup:    []string{"SET", "k", "v"},
+			command:  []string{"EXPIRE", "k", "10"},
+			expected: [][]string{{"EXPIREAT", "k", strconv.FormatInt(now.Unix()+10, 10)}},
+		},
+		{
+			name:     "EXPIRE not performed",
+			command:  []string{"EXPIRE", "k", "10"},
+			expected: nil,
+		},
+		{
+			name:     "PEXPIRE",
+			setup:    []string{"SET", "k", "v"},
+			command:  []string{"PEXPIRE", "k", "1500", "NX"},
+			expected: [][]string{{"PEXPIREAT", "k", ms(1500)}},
+		},
+		{
+			name:     "PEXPIRE not performed",
+			setup:    []string{"SET", "k", "v", "PX", "500"},
+			command:  []string{"PEXPIRE", "k", "1500", "NX"},
+			expected: nil,
+		},
+		{
+			name:     "EXPIREMEMBER",
+			setup:    []string{"SADD", "k", "a"},
+			command:  []string{"EXPIREMEMBER", "k", "a", "1500", "ms"},
+			expected: [][]string{{"PEXPIREMEMBERAT", "k", "a", ms(1500)}},
+		},
+		{
+			name:     "EXPIREMEMBER deleting the member",
+			setup:    []string{"SADD", "k", "a"},
+			command:  []string{"EXPIREMEMBER", "k", "a", "0"},
+			expected: [][]string{{"EXPIREMEMBER", "k", "a", "0"}},
+		},
+		{
+			name:     "HEXPIRE",
+			setup:    []string{"HSET", "h", "a", "1", "b", "2"},
+			command:  []string{"HEXPIRE", "h", "10", "NX", "FIELDS", "3", "a", "missing", "b"},
+			expected: [][]string{{"HPEXPIREAT", "h", ms(10000), "FIELDS", "2", "a", "b"}},
+		},
+		{
+			name:     "HPEXPIRE deleting the fields",
+			setup:    []string{"HSET", "h", "a", "1", "b", "2"},
+			command:  []string{"HPEXPIRE", "h", "0", "FIELDS", "1", "a"},
+			expected: [][]string{{"HDEL", "h", "a"}},
+		},
+		{
+			name:     "HEXPIRE of a missing key",
+			command:  []string{"HEXPIRE", "h", "10", "FIELDS", "1", "a"},
+			expected: nil,
+		},
+		{
+			name:     "INCRBYFLOAT",
+			setup:    []string{"SET", "k", "10.5"},
+			command:  []string{"INCRBYFLOAT", "k", "0.1"},
+			expected: [][]string{{"SET", "k", "10.6", "KEEPTTL"}},
+		},
+		{
+			name:     "HINCRBYFLOAT",
+			setup:    []string{"HSET", "h", "f", "1"},
+			command:  []string{"HINCRBYFLOAT", "h", "f", "0.5"},
+			expected: [][]string{{"HSET", "h", "f", "1.5"}},
+		},
+		{
+			name:     "XADD with a generated ID",
+			command:  []string{"XADD", "s", "MAXLEN", "~", "10", "*", "f", "v"},
+			expected: [][]string{{"XADD", "s", "MAXLEN", "~", "10", strconv.FormatInt(now.UnixMilli(), 10) + "-0", "f", "v"}},
+		},
+		{
+			name:     "COUNTER.INCR",
+			setup:    []string{"SET", "c#0", "10"},
+			command:  []string{"COUNTER.INCR", "c", "1", "5"},
+			expected: [][]string{{"SET", "c#0", "15"}},
+		},
+		{
+			name:     "SPOP",
+			setup:    []string{"SADD", "s", "a"},
+			command:  []string{"SPOP", "s", "2"},
+			expected: [][]string{{"SREM", "s", "a"}},
+		},
+		{
+			name:     "SPOP of a missing key",
+			command:  []string{"SPOP", "missing"},
+			expected: nil,
+		},
+		{
+			name:     "XADD NOMKSTREAM",
+			command:  []string{"XADD", "s", "NOMKSTREAM", "*", "f", "v"},
+			expected: nil,
+		},
+		{
+			name:     "SINTERSTORE with a relative TTL",
+			setup:    []string{"SADD", "s", "a"},
+			command:  []string{"SINTERSTORE", "d", "s", "s", "TTL", "PX", "1500"},
+			expected: [][]string{{"SINTERSTORE", "d", "s", "s"}, {"PEXPIREAT", "d", ms(1500)}},
+		},
+		{
+			name:     "SUNIONSTORE with an empty result",
+			command:  []string{"SUNIONSTORE", "d", "s", "TTL", "EX", "10"},
+			expected: [][]string{{"SUNIONSTORE", "d", "s"}},
+		},
+		{
+			name:     "SDIFFSTORE INHERITTTL",
+			setup:    []string{"SADD", "s", "a"},
+			command:  []string{"SDIFFSTORE", "d", "s", "TTL", "INHERITTTL"},
+			expected: [][]string{{"SDIFFSTORE", "d", "s", "TTL", "INHERITTTL"}},
+		},
+		{
+			name:     "ZUNIONSTORE with a relative TTL",
+			setup:    []string{"ZADD", "z", "1", "a"},
+			command:  []string{"ZUNIONSTORE", "d", "2", "z", "z", "EX", "10", "WEIGHTS", "1", "2"},
+			expected: [][]string{{"ZUNIONSTORE", "d", "2", "z", "z", "WEIGHTS", "1", "2"}, {"PEXPIREAT", "d", ms(10000)}},
+		},
+		{
+			name:     "ZINTERSTORE with an empty result",
+			command:  []string{"ZINTERSTORE", "d", "1", "z", "PX", "1500"},
+			expected: [][]string{{"ZINTERSTORE", "d", "1", "z"}},
+		},
+		{
+			name:     "ZINTERSTORE INHERITTTL",
+			setup:    []string{"ZADD", "z", "1", "a"},
+			command:  []string{"ZINTERSTORE", "d", "1", "z", "INHERITTTL"},
+			expected: [][]string{{"ZINTERSTORE", "d", "1", "z", "INHERITTTL"}},
+		},
+		{
+			name:     "MOVE",
+			setup:    []string{"SET", "k", "v"},
+			command:  []string{"MOVE", "k", "1"},
+			expected: [][]string{{"DEL", "k"}},
+		},
+		{
+			name:     "MOVE of a missing key",
+			command:  []string{"MOVE", "missing", "1"},
+			expected: nil,
+		},
+		{
+			name:     "SWAPDB",
+			command:  []string{"SWAPDB", "0", "1"},
+			expected: nil,
+		},
+		{
+			name:     "RESTORE-SNAPSHOT",
+			command:  []string{"RESTORE-SNAPSHOT", "STATUS"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dstore.ResetStore(store)
+			if tc.setup != nil {
+				ExecuteCommand(&cmd.DiceDBCmd{Cmd: tc.setup[0], Args: tc.setup[1:]}, nil, store, false, false)
+			}
+
+			c := &cmd.DiceDBCmd{Cmd: tc.command[0], Args: tc.command[1:]}
+			resp := ExecuteCommand(c, nil, store, false, false)
+			assert.NilError(t, resp.Err())
+
+			var propagated [][]string
+			for _, pc := range PropagatedCommands(c, resp, store) {
+				propagated = append(propagated, append([]string{pc.Cmd}, pc.Args...))
+			}
+			assert.DeepEqual(t, tc.expected, propagated)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"sync"
+)
+
+// The replies of the introspection commands, e.g. COMMAND or HELLO, only depend
+// on the commands registered, yet listing all of them takes milliseconds while
+// many clients send them on every connect. Their encoded replies are cached till
+// the registry of the commands changes, see RegisterCommand, so that they are
+// then served in microseconds. The cached replies are shared by all the shards,
+// they must never be modified.
+
+type replyCache struct {
+	mu      sync.RWMutex
+	version uint64            // version is bumped on every change of the registry
+	replies map[string][]byte // replies maps the cache keys to the encoded replies
+}
+
+var introspectionReplies = &replyCache{replies: make(map[string][]byte)}
+
+// get returns the reply cached under key, building it if it is not cached yet.
+func (c *replyCache) get(key string, build func() []byte) []byte {
+	c.mu.RLock()
+	reply, ok := c.replies[key]
+	version := c.version
+	c.mu.RUnlock()
+	if ok {
+		return reply
+	}
+
+	reply = build()
+	c.mu.Lock()
+	// the reply is not cached if the registry changed while it was built
+	if c.version == version {
+		c.replies[key] = reply
+	}
+	c.mu.Unlock()
+	return reply
+}
+
+// invalidate drops the replies cached.
+func (c *replyCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.replies = make(map[string][]byte)
+}
+
+// cachedReply returns the reply of an introspection command cached under key,
+// built by build if it is not cached yet.
+func cachedReply(key string, build func() []byte) []byte {
+	return introspectionReplies.get(key, build)
+}
+
+// RegisterCommand adds meta to the commands, or replaces the command of the same
+// name, and drops the cached replies of the introspection commands listing them.
+// It must not be called while the commands are being executed.
+func RegisterCommand(meta DiceCmdMeta) {
+	DiceCmds[meta.Name] = meta
+	diceCommandsCount = len(DiceCmds)
+	introspectionReplies.invalidate()
+}
@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestIntrospectionRepliesCached(t *testing.T) {
+	store := dstore.NewStore(nil)
+	defer func() {
+		delete(DiceCmds, "TEST.CACHED")
+		diceCommandsCount = len(DiceCmds)
+		introspectionReplies.invalidate()
+	}()
+
+	list := evalCommand([]string{List}, store)
+	assert.Equal(t, &list[0], &evalCommand([]string{List}, store)[0])
+	info := evalCommand([]string{Info, "get", "missing"}, store)
+	assert.Equal(t, string(clientio.Encode([]interface{}{convertCmdMetaToSlice(&getCmdMeta), clientio.RespNIL}, false)), string(info))
+
+	// the replies are built again once the registry changes
+	RegisterCommand(DiceCmdMeta{Name: "TEST.CACHED", Arity: 1})
+	assert.Equal(t, ":"+strconv.Itoa(len(DiceCmds))+"\r\n", string(evalCommand([]string{Count}, store)))
+	assert.Assert(t, len(evalCommand([]string{List}, store)) > len(list))
+	assert.Equal(t, "*1\r\n*6\r\n$11\r\nTEST.CACHED\r\n:1\r\n:0\r\n:0\r\n:0\r\n*0\r\n",
+		string(evalCommand([]string{Info, "test.cached"}, store)))
+}
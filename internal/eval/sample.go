@@ -0,0 +1,148 @@
+package eval
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// sampleIndexes returns indexes of [0, n) chosen at random, following the
+// convention of the random-member commands such as SRANDMEMBER and HRANDFIELD.
+// With a positive count, up to count distinct indexes are returned, sampled
+// without replacement. With a negative count, -count indexes are returned,
+// sampled with replacement, the same index possibly several times.
+//
+// If weight is nil, all the indexes are equally likely. Otherwise, index i is
+// chosen with a probability proportional to weight(i), the indexes whose weight
+// is not positive never being chosen.
+func sampleIndexes(n, count int, weight func(i int) float64) []int {
+	if n == 0 || count == 0 {
+		return []int{}
+	}
+
+	switch {
+	case weight == nil && count > 0:
+		return sampleDistinct(n, count)
+	case weight == nil:
+		indexes := make([]int, -count)
+		for i := range indexes {
+			indexes[i] = rand.Intn(n) //nolint:gosec
+		}
+		return indexes
+	case count > 0:
+		return sampleWeightedDistinct(n, count, weight)
+	default:
+		return sampleWeighted(n, -count, weight)
+	}
+}
+
+// sampleDistinct returns up to count distinct indexes of [0, n), as the first
+// count indexes of a partial Fisher-Yates shuffle.
+func sampleDistinct(n, count int) []int {
+	count = min(count, n)
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	for i := 0; i < count; i++ {
+		j := i + rand.Intn(n-i) //nolint:gosec
+		indexes[i], indexes[j] = indexes[j], indexes[i]
+	}
+	return indexes[:count]
+}
+
+// sampleWeighted returns count indexes of [0, n) sampled with replacement, by
+// a binary search of a uniform value in the cumulative weights.
+func sampleWeighted(n, count int, weight func(i int) float64) []int {
+	cumulative := make([]float64, n)
+	total := 0.0
+	for i := range cumulative {
+		if w := weight(i); w > 0 {
+			total += w
+		}
+		cumulative[i] = total
+	}
+	if total == 0 {
+		return []int{}
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		r := rand.Float64() * total //nolint:gosec
+		indexes[i] = sort.Search(n, func(j int) bool { return cumulative[j] > r })
+	}
+	return indexes
+}
+
+// sampleWeightedDistinct returns up to count distinct indexes of [0, n) sampled
+// without replacement. Each index is given an exponential key of rate its
+// weight, the count smallest keys being the sample (Efraimidis-Spirakis).
+func sampleWeightedDistinct(n, count int, weight func(i int) float64) []int {
+	indexes := make([]int, 0, n)
+	keys := make([]float64, n)
+	for i := 0; i < n; i++ {
+		if w := weight(i); w > 0 {
+			keys[i] = rand.ExpFloat64() / w //nolint:gosec
+			indexes = append(indexes, i)
+		}
+	}
+
+	sort.Slice(indexes, func(a, b int) bool { return keys[indexes[a]] < keys[indexes[b]] })
+	return indexes[:min(count, len(indexes))]
+}
+
+// sampleStream returns items chosen at random among the n items iterated by
+// all, following the convention of sampleIndexes for count. It only holds the
+// items chosen, so that sampling a large collection does not copy it: the
+// distinct items are chosen by reservoir sampling, the repeated ones by
+// walking the collection once to the indexes drawn beforehand.
+func sampleStream(n, count int, all func(yield func(item string) bool)) []string {
+	if n == 0 || count == 0 {
+		return []string{}
+	}
+	if count < 0 {
+		return sampleStreamRepeated(n, -count, all)
+	}
+
+	reservoir := make([]string, 0, min(count, n))
+	seen := 0
+	all(func(item string) bool {
+		if len(reservoir) < cap(reservoir) {
+			reservoir = append(reservoir, item)
+		} else if j := rand.Intn(seen + 1); j < len(reservoir) { //nolint:gosec
+			reservoir[j] = item
+		}
+		seen++
+		return true
+	})
+	// the order of the reservoir follows the one of the iteration
+	rand.Shuffle(len(reservoir), func(i, j int) { //nolint:gosec
+		reservoir[i], reservoir[j] = reservoir[j], reservoir[i]
+	})
+	return reservoir
+}
+
+// sampleStreamRepeated returns count items of the n items iterated by all,
+// sampled with replacement.
+func sampleStreamRepeated(n, count int, all func(yield func(item string) bool)) []string {
+	// the positions of the samples, by ascending index of the item they get
+	positions := make([]int, count)
+	indexes := make([]int, count)
+	for i := range indexes {
+		positions[i] = i
+		indexes[i] = rand.Intn(n) //nolint:gosec
+	}
+	sort.Slice(positions, func(a, b int) bool { return indexes[positions[a]] < indexes[positions[b]] })
+
+	samples := make([]string, count)
+	next, index := 0, 0
+	all(func(item string) bool {
+		for next < count && indexes[positions[next]] == index {
+			samples[positions[next]] = item
+			next++
+		}
+		index++
+		return next < count
+	})
+	return samples
+}
@@ -0,0 +1,192 @@
+package eval
+
+import (
+	"sort"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// chiSquareLimit is above the chi-square statistic of the samples below with a
+// probability of more than 1 - 1e-6, for their 9 degrees of freedom at most.
+const chiSquareLimit = 50
+
+// chiSquare returns the chi-square statistic of the counts observed against the
+// ones expected.
+func chiSquare(observed []int, expected []float64) float64 {
+	stat := 0.0
+	for i, o := range observed {
+		if expected[i] == 0 {
+			continue
+		}
+		d := float64(o) - expected[i]
+		stat += d * d / expected[i]
+	}
+	return stat
+}
+
+func TestSampleIndexesUniform(t *testing.T) {
+	const n, draws = 10, 100000
+
+	t.Run("with replacement", func(t *testing.T) {
+		observed := make([]int, n)
+		indexes := sampleIndexes(n, -draws, nil)
+		assert.Equal(t, draws, len(indexes))
+		for _, i := range indexes {
+			observed[i]++
+		}
+
+		expected := make([]float64, n)
+		for i := range expected {
+			expected[i] = draws / n
+		}
+		assert.Assert(t, chiSquare(observed, expected) < chiSquareLimit, observed)
+	})
+
+	t.Run("without replacement", func(t *testing.T) {
+		const count, trials = 3, 30000
+		observed := make([]int, n)
+		for trial := 0; trial < trials; trial++ {
+			indexes := sampleIndexes(n, count, nil)
+			assert.Equal(t, count, len(indexes))
+			seen := make(map[int]bool)
+			for _, i := range indexes {
+				assert.Assert(t, !seen[i], "index %d sampled twice", i)
+				seen[i] = true
+				observed[i]++
+			}
+		}
+
+		expected := make([]float64, n)
+		for i := range expected {
+			expected[i] = float64(trials) * count / n
+		}
+		assert.Assert(t, chiSquare(observed, expected) < chiSquareLimit, observed)
+	})
+
+	t.Run("count above n", func(t *testing.T) {
+		indexes := sampleIndexes(n, n+5, nil)
+		sort.Ints(indexes)
+		assert.DeepEqual(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, indexes)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.DeepEqual(t, []int{}, sampleIndexes(0, 5, nil))
+		assert.DeepEqual(t, []int{}, sampleIndexes(0, -5, nil))
+		assert.DeepEqual(t, []int{}, sampleIndexes(n, 0, nil))
+	})
+}
+
+func TestSampleIndexesWeighted(t *testing.T) {
+	weights := []float64{1, 2, 0, 3, 4, -1}
+	weight := func(i int) float64 { return weights[i] }
+	expectedShare := []float64{0.1, 0.2, 0, 0.3, 0.4, 0}
+
+	t.Run("with replacement", func(t *testing.T) {
+		const draws = 100000
+		observed := make([]int, len(weights))
+		for _, i := range sampleIndexes(len(weights), -draws, weight) {
+			observed[i]++
+		}
+
+		expected := make([]float64, len(weights))
+		for i, share := range expectedShare {
+			expected[i] = share * draws
+		}
+		assert.Equal(t, 0, observed[2]+observed[5])
+		assert.Assert(t, chiSquare(observed, expected) < chiSquareLimit, observed)
+	})
+
+	t.Run("without replacement", func(t *testing.T) {
+		// the first index sampled is chosen proportionally to the weights
+		const trials = 50000
+		observed := make([]int, len(weights))
+		for trial := 0; trial < trials; trial++ {
+			observed[sampleIndexes(len(weights), 1, weight)[0]]++
+		}
+
+		expected := make([]float64, len(weights))
+		for i, share := range expectedShare {
+			expected[i] = share * trials
+		}
+		assert.Equal(t, 0, observed[2]+observed[5])
+		assert.Assert(t, chiSquare(observed, expected) < chiSquareLimit, observed)
+
+		// only the indexes of positive weights are ever sampled
+		indexes := sampleIndexes(len(weights), 10, weight)
+		sort.Ints(indexes)
+		assert.DeepEqual(t, []int{0, 1, 3, 4}, indexes)
+	})
+
+	t.Run("no positive weight", func(t *testing.T) {
+		zero := func(int) float64 { return 0 }
+		assert.DeepEqual(t, []int{}, sampleIndexes(3, 2, zero))
+		assert.DeepEqual(t, []int{}, sampleIndexes(3, -2, zero))
+	})
+}
+
+func TestSampleStream(t *testing.T) {
+	const n = 10
+	items := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	position := func(item string) int {
+		return sort.SearchStrings(items, item)
+	}
+	all := func(yield func(string) bool) {
+		for _, item := range items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+
+	t.Run("with replacement", func(t *testing.T) {
+		const draws = 100000
+		samples := sampleStream(n, -draws, all)
+		assert.Equal(t, draws, len(samples))
+		observed := make([]int, n)
+		for _, item := range samples {
+			observed[position(item)]++
+		}
+
+		expected := make([]float64, n)
+		for i := range expected {
+			expected[i] = draws / n
+		}
+		assert.Assert(t, chiSquare(observed, expected) < chiSquareLimit, observed)
+	})
+
+	t.Run("without replacement", func(t *testing.T) {
+		const count, trials = 3, 30000
+		observed := make([]int, n)
+		// the first sample of each trial, as the order is random too
+		first := make([]int, n)
+		for trial := 0; trial < trials; trial++ {
+			samples := sampleStream(n, count, all)
+			assert.Equal(t, count, len(samples))
+			seen := make(map[string]bool)
+			for _, item := range samples {
+				assert.Assert(t, !seen[item], "%s sampled twice", item)
+				seen[item] = true
+				observed[position(item)]++
+			}
+			first[position(samples[0])]++
+		}
+
+		expected := make([]float64, n)
+		for i := range expected {
+			expected[i] = count * trials / n
+		}
+		assert.Assert(t, chiSquare(observed, expected) < chiSquareLimit, observed)
+		for i := range expected {
+			expected[i] = trials / n
+		}
+		assert.Assert(t, chiSquare(first, expected) < chiSquareLimit, first)
+	})
+
+	t.Run("count beyond the items", func(t *testing.T) {
+		samples := sampleStream(n, 2*n, all)
+		sort.Strings(samples)
+		assert.DeepEqual(t, items, samples)
+		assert.DeepEqual(t, []string{}, sampleStream(0, 5, all))
+	})
+}
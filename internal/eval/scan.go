@@ -0,0 +1,210 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/regex"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// SSCAN, HSCAN and ZSCAN walk the members of a collection by ascending hash of
+// the members, the cursor being the hash the next call starts from. They hold
+// no state between the calls, yet every member present for the whole
+// iteration is returned exactly once, whatever the members added or removed
+// in the meantime: a call returns all the members sharing the hash of its last
+// member. The store indexes the members by hash for the iteration, see
+// Store.ScanMembers, so that a call costs O(log n + count) unless the
+// collection was written since the previous one.
+//
+// The calls of the SCAN commands are given config.DiceConfig.Server.ScanTimeBudget
+// to run, so that a large COUNT or a costly MATCH never stalls the shard serving
+// them: once past their deadline, they return the items walked so far along with
+// the cursor resuming the iteration, COUNT adapting to the cost of the items.
+
+// defaultScanCount is the number of members a call returns when COUNT is not
+// given.
+const defaultScanCount = 10
+
+// scanArgs are the arguments of SSCAN, HSCAN and ZSCAN after the key.
+type scanArgs struct {
+	cursor   uint64
+	pattern  string
+	count    int
+	deadline time.Time // the time the call must return by, none if zero
+}
+
+// scanDeadline returns the deadline of a call of a SCAN command starting now,
+// the zero time if the time budget of the calls is disabled.
+func scanDeadline() time.Time {
+	budget := config.Current().Server.ScanTimeBudget
+	if budget <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(budget)
+}
+
+func parseScanArgs(args []string) (*scanArgs, []byte) {
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return nil, diceerrors.NewErrWithMessage("invalid cursor")
+	}
+
+	scan := &scanArgs{cursor: cursor, pattern: "*", count: defaultScanCount, deadline: scanDeadline()}
+	for i := 1; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case Match:
+			if i+1 >= len(args) {
+				return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			scan.pattern = args[i+1]
+			i++
+		case Count:
+			if i+1 >= len(args) {
+				return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			scan.count, err = strconv.Atoi(args[i+1])
+			if err != nil {
+				return nil, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			if scan.count < 1 {
+				return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			i++
+		default:
+			return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+	return scan, nil
+}
+
+// scanMembers returns the members walked by a call of a SCAN command starting
+// at cursor, matching the pattern, along with the cursor of the next call, 0
+// once the iteration is over, see Store.ScanMembers. obj is the collection
+// stored at key, nil if the key does not exist, all iterates over its members
+// and has tells whether a member is in it. As with Redis, COUNT bounds the
+// members walked, the pattern being applied afterwards.
+func scanMembers(scan *scanArgs, key string, obj *object.Obj, all func(yield func(member string)),
+	has func(member string) bool, store *dstore.Store) (next uint64, members []string) {
+	if obj == nil {
+		return 0, []string{}
+	}
+	return store.ScanMembers(key, obj, scan.cursor, scan.count, all, has, func(member string) bool {
+		return regex.GlobMatch(scan.pattern, member)
+	}, scan.deadline)
+}
+
+func scanReply(next uint64, items []string) []byte {
+	return clientio.Encode([]interface{}{strconv.FormatUint(next, 10), items}, false)
+}
+
+// evalSSCAN iterates over the members of the set stored at key, see
+// scanMembers.
+//
+// Usage: SSCAN key cursor [MATCH pattern] [COUNT count]
+func evalSSCAN(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("SSCAN")
+	}
+	scan, errResp := parseScanArgs(args[1:])
+	if errResp != nil {
+		return errResp
+	}
+
+	var set map[string]struct{}
+	obj := store.Get(args[0])
+	if obj != nil {
+		if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeSet, object.ObjEncodingSetStr); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr)
+		}
+		set = obj.Value.(map[string]struct{})
+	}
+
+	next, members := scanMembers(scan, args[0], obj, func(yield func(string)) {
+		for member := range set {
+			yield(member)
+		}
+	}, func(member string) bool {
+		_, ok := set[member]
+		return ok
+	}, store)
+	return scanReply(next, members)
+}
+
+// evalHSCAN iterates over the fields of the hash stored at key, see
+// scanMembers. It returns the fields along with their values.
+//
+// Usage: HSCAN key cursor [MATCH pattern] [COUNT count]
+func evalHSCAN(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("HSCAN")
+	}
+	scan, errResp := parseScanArgs(args[1:])
+	if errResp != nil {
+		return errResp
+	}
+
+	var hashMap HashMap
+	obj := store.Get(args[0])
+	if obj != nil {
+		if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeHashMap, object.ObjEncodingHashMap); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr)
+		}
+		hashMap = obj.Value.(HashMap)
+	}
+
+	next, fields := scanMembers(scan, args[0], obj, func(yield func(string)) {
+		for field := range hashMap {
+			yield(field)
+		}
+	}, func(field string) bool {
+		_, ok := hashMap[field]
+		return ok
+	}, store)
+	items := make([]string, 0, 2*len(fields))
+	for _, field := range fields {
+		items = append(items, field, hashMap[field])
+	}
+	return scanReply(next, items)
+}
+
+// evalZSCAN iterates over the members of the sorted set stored at key, see
+// scanMembers. It returns the members along with their scores.
+//
+// Usage: ZSCAN key cursor [MATCH pattern] [COUNT count]
+func evalZSCAN(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("ZSCAN")
+	}
+	scan, errResp := parseScanArgs(args[1:])
+	if errResp != nil {
+		return errResp
+	}
+
+	var memberMap map[string]float64
+	obj := store.Get(args[0])
+	if obj != nil {
+		if _, memberMap, errResp = getSortedSet(obj); errResp != nil {
+			return errResp
+		}
+	}
+
+	next, members := scanMembers(scan, args[0], obj, func(yield func(string)) {
+		for member := range memberMap {
+			yield(member)
+		}
+	}, func(member string) bool {
+		_, ok := memberMap[member]
+		return ok
+	}, store)
+	items := make([]string, 0, 2*len(members))
+	for _, member := range members {
+		items = append(items, member, formatScore(memberMap[member]))
+	}
+	return scanReply(next, items)
+}
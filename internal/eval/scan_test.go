@@ -0,0 +1,287 @@
+package eval
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func execScanCommand(t *testing.T, store *dstore.Store, args ...string) string {
+	t.Helper()
+	return wrongTypeReply(ExecuteCommand(&cmd.DiceDBCmd{Cmd: args[0], Args: args[1:]}, nil, store, false, false))
+}
+
+// scanAll iterates with the SCAN command name over the collection stored at
+// key, running between with the cursor of the second call before making it,
+// and returns the items returned by every call.
+func scanAll(t *testing.T, store *dstore.Store, name string, between func(cursor string), opts ...string) []string {
+	t.Helper()
+	var items []string
+	cursor := "0"
+	for calls := 0; ; calls++ {
+		if calls == 1 && between != nil {
+			between(cursor)
+		}
+		assert.Assert(t, calls < 1000, "%s did not end", name)
+
+		output := execScanCommand(t, store, append([]string{name, "k", cursor}, opts...)...)
+		value, err := clientio.NewRESPParser(bytes.NewBufferString(output)).DecodeOne()
+		assert.NilError(t, err)
+		reply := value.([]interface{})
+		for _, item := range reply[1].([]interface{}) {
+			items = append(items, item.(string))
+		}
+		cursor = reply[0].(string)
+		if cursor == "0" {
+			return items
+		}
+	}
+}
+
+func TestScanCollections(t *testing.T) {
+	var members []string
+	for i := 0; i < 50; i++ {
+		members = append(members, fmt.Sprintf("m%d", i))
+	}
+
+	tests := []struct {
+		name   string
+		add    func(store *dstore.Store, member string)
+		remove []string
+		pairs  bool // whether the replies hold the members along with a value
+	}{
+		{
+			name: "SSCAN",
+			add: func(store *dstore.Store, member string) {
+				execScanCommand(t, store, "SADD", "k", member)
+			},
+			remove: []string{"SREM", "k"},
+		},
+		{
+			name: "HSCAN",
+			add: func(store *dstore.Store, member string) {
+				execScanCommand(t, store, "HSET", "k", member, "v"+member)
+			},
+			remove: []string{"HDEL", "k"},
+			pairs:  true,
+		},
+		{
+			name: "ZSCAN",
+			add: func(store *dstore.Store, member string) {
+				execScanCommand(t, store, "ZADD", "k", "1.5", member)
+			},
+			remove: []string{"ZREM", "k"},
+			pairs:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		newStore := func() *dstore.Store {
+			store := dstore.NewStore(nil)
+			for _, member := range members {
+				tt.add(store, member)
+			}
+			return store
+		}
+		// keys returns the members of the items returned by a scan.
+		keys := func(items []string) []string {
+			if !tt.pairs {
+				return items
+			}
+			var keys []string
+			for i := 0; i < len(items); i += 2 {
+				keys = append(keys, items[i])
+			}
+			return keys
+		}
+
+		t.Run(tt.name+" returns every member once", func(t *testing.T) {
+			got := keys(scanAll(t, newStore(), tt.name, nil, "COUNT", "3"))
+			sort.Strings(got)
+			want := append([]string(nil), members...)
+			sort.Strings(want)
+			assert.DeepEqual(t, want, got)
+		})
+
+		t.Run(tt.name+" returns the values", func(t *testing.T) {
+			items := scanAll(t, newStore(), tt.name, nil)
+			if tt.pairs {
+				for i := 0; i < len(items); i += 2 {
+					if tt.name == "HSCAN" {
+						assert.Equal(t, "v"+items[i], items[i+1])
+					} else {
+						assert.Equal(t, "1.5", items[i+1])
+					}
+				}
+			}
+		})
+
+		t.Run(tt.name+" with MATCH", func(t *testing.T) {
+			got := keys(scanAll(t, newStore(), tt.name, nil, "MATCH", "m1?", "COUNT", "4"))
+			sort.Strings(got)
+			assert.DeepEqual(t, []string{"m10", "m11", "m12", "m13", "m14", "m15", "m16", "m17", "m18", "m19"}, got)
+		})
+
+		t.Run(tt.name+" with members changing", func(t *testing.T) {
+			store := newStore()
+			var cursor uint64
+			got := keys(scanAll(t, store, tt.name, func(next string) {
+				cursor, _ = strconv.ParseUint(next, 10, 64)
+				for _, member := range members[:10] {
+					execScanCommand(t, store, append(tt.remove, member)...)
+				}
+				for i := 50; i < 60; i++ {
+					tt.add(store, fmt.Sprintf("m%d", i))
+				}
+			}, "COUNT", "5"))
+
+			// the members present for the whole iteration are returned once
+			seen := map[string]int{}
+			for _, member := range got {
+				seen[member]++
+			}
+			for member, n := range seen {
+				assert.Equal(t, 1, n, "%s returned %d times", member, n)
+			}
+			for _, member := range members[10:] {
+				assert.Equal(t, 1, seen[member], "%s not returned", member)
+			}
+			// the members are indexed again once the key is written, the ones
+			// added ahead of the cursor being returned
+			for i := 50; i < 60; i++ {
+				if member := fmt.Sprintf("m%d", i); xxhash.Sum64String(member) >= cursor {
+					assert.Equal(t, 1, seen[member], "%s not returned", member)
+				}
+			}
+		})
+
+		t.Run(tt.name+" on a missing key", func(t *testing.T) {
+			store := dstore.NewStore(nil)
+			assert.Equal(t, "*2\r\n$1\r\n0\r\n*0\r\n", execScanCommand(t, store, tt.name, "k", "0"))
+		})
+
+		t.Run(tt.name+" errors", func(t *testing.T) {
+			store := newStore()
+			assert.Equal(t, "-ERR wrong number of arguments for '"+strings.ToLower(tt.name)+"' command\r\n",
+				execScanCommand(t, store, tt.name, "k"))
+			assert.Equal(t, "-ERR invalid cursor\r\n", execScanCommand(t, store, tt.name, "k", "abc"))
+			assert.Equal(t, "-ERR syntax error\r\n", execScanCommand(t, store, tt.name, "k", "0", "FOO"))
+			assert.Equal(t, "-ERR syntax error\r\n", execScanCommand(t, store, tt.name, "k", "0", "MATCH"))
+			assert.Equal(t, "-ERR syntax error\r\n", execScanCommand(t, store, tt.name, "k", "0", "COUNT", "0"))
+			assert.Equal(t, "-ERR value is not an integer or out of range\r\n",
+				execScanCommand(t, store, tt.name, "k", "0", "COUNT", "x"))
+
+			execScanCommand(t, store, "SET", "s", "v")
+			assert.Assert(t, isWrongTypeReply(execScanCommand(t, store, tt.name, "s", "0")))
+		})
+	}
+}
+
+func TestScanDeadline(t *testing.T) {
+	var members []string
+	for i := 0; i < 100; i++ {
+		members = append(members, fmt.Sprintf("m%d", i))
+	}
+	store := dstore.NewStore(nil)
+	evalSADD(append([]string{"set"}, members...), store)
+	set := store.Get("set").Value.(map[string]struct{})
+	all := func(yield func(string)) {
+		for member := range set {
+			yield(member)
+		}
+	}
+	has := func(member string) bool {
+		_, ok := set[member]
+		return ok
+	}
+
+	// past its deadline, a call returns the members walked so far, the next
+	// call resuming from there
+	past := time.Now().Add(-time.Second)
+	var got []string
+	calls := 0
+	for cursor := uint64(0); ; calls++ {
+		scan := &scanArgs{cursor: cursor, pattern: "*", count: 100, deadline: past}
+		next, returned := scanMembers(scan, "set", store.Get("set"), all, has, store)
+		got = append(got, returned...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	assert.Assert(t, calls > 1, "expected the calls to return early")
+	sort.Strings(got)
+	want := append([]string(nil), members...)
+	sort.Strings(want)
+	assert.DeepEqual(t, want, got)
+
+	for _, member := range members {
+		evalSET([]string{member, "v"}, store)
+	}
+	for _, snapshot := range []bool{false, true} {
+		var keys []string
+		calls = 0
+		for cursor := uint64(0); ; calls++ {
+			next, returned, ok := store.ScanFiltered(cursor, 100, snapshot, func(key string) bool {
+				return strings.HasPrefix(key, "m1")
+			}, past)
+			assert.Assert(t, ok)
+			keys = append(keys, returned...)
+			if cursor = next; cursor == 0 {
+				break
+			}
+		}
+		assert.Assert(t, calls > 1, "expected the calls to return early")
+		assert.Equal(t, 11, len(keys), "snapshot: %v", snapshot)
+	}
+}
+
+func TestScanKeysChanging(t *testing.T) {
+	store := dstore.NewStore(nil)
+	for i := 0; i < 100; i++ {
+		evalSET([]string{fmt.Sprintf("k%d", i), "v"}, store)
+	}
+
+	// the keys deleted, renamed or added while iterating may or may not be
+	// returned, every other key is returned exactly once
+	var keys []string
+	calls := 0
+	for cursor := uint64(0); ; calls++ {
+		next, returned, ok := store.ScanFiltered(cursor, 10, false, nil, time.Time{})
+		assert.Assert(t, ok)
+		assert.Assert(t, len(returned) < 20, "expected a call to return about count keys, got %d", len(returned))
+		keys = append(keys, returned...)
+		if calls == 0 {
+			for i := 0; i < 10; i++ {
+				store.Del(fmt.Sprintf("k%d", i))
+				store.Rename(fmt.Sprintf("k%d", 10+i), fmt.Sprintf("r%d", i))
+				evalSET([]string{fmt.Sprintf("n%d", i), "v"}, store)
+			}
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	assert.Assert(t, calls > 5, "expected the iteration to take several calls")
+
+	seen := make(map[string]int)
+	for _, k := range keys {
+		seen[k]++
+	}
+	for k, n := range seen {
+		assert.Equal(t, 1, n, "key %s returned %d times", k, n)
+	}
+	for i := 20; i < 100; i++ {
+		assert.Equal(t, 1, seen[fmt.Sprintf("k%d", i)], "key k%d not returned", i)
+	}
+}
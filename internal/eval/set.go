@@ -0,0 +1,358 @@
+package eval
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// getSet returns the set stored at key, nil if the key does not exist, or an
+// encoded error if the key holds another type.
+func getSet(key string, store *dstore.Store) (map[string]struct{}, []byte) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, nil
+	}
+
+	if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeSet, object.ObjEncodingSetStr); err != nil {
+		return nil, diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
+	return obj.Value.(map[string]struct{}), nil
+}
+
+// getOrCreateSet returns the set stored at key, creating an empty one with
+// room for size members if the key does not exist, or an encoded error if the
+// key holds another type.
+func getOrCreateSet(key string, size int, store *dstore.Store) (map[string]struct{}, []byte) {
+	set, errResp := getSet(key, store)
+	if errResp != nil || set != nil {
+		return set, errResp
+	}
+
+	set = make(map[string]struct{}, size)
+	store.Put(key, store.NewObj(set, -1, object.ObjTypeSet, object.ObjEncodingSetStr))
+	return set, nil
+}
+
+// evalSISMEMBER returns 1 if member is a member of the set stored at key, 0 otherwise.
+func evalSISMEMBER(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("SISMEMBER")
+	}
+
+	set, errResp := getSet(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+
+	if _, ok := set[args[1]]; ok {
+		return clientio.RespOne
+	}
+	return clientio.RespZero
+}
+
+// evalSMISMEMBER returns, for each member, 1 if it is a member of the set
+// stored at key, 0 otherwise.
+func evalSMISMEMBER(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("SMISMEMBER")
+	}
+
+	set, errResp := getSet(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+
+	found := make([]interface{}, len(args)-1)
+	for i, member := range args[1:] {
+		found[i] = 0
+		if _, ok := set[member]; ok {
+			found[i] = 1
+		}
+	}
+	return clientio.Encode(found, false)
+}
+
+// evalSPOP removes and returns random members of the set stored at key. Without
+// count, a single member is returned, or nil if the key does not exist. With
+// count, up to count members are returned. The key is deleted once its set is
+// empty.
+func evalSPOP(args []string, store *dstore.Store) []byte {
+	if len(args) < 1 || len(args) > 2 {
+		return diceerrors.NewErrArity("SPOP")
+	}
+
+	count := 1
+	if len(args) == 2 {
+		var err error
+		count, err = strconv.Atoi(args[1])
+		if err != nil || count < 0 {
+			return diceerrors.NewErrWithMessage(diceerrors.ValOutOfRangeErr + ", must be positive")
+		}
+	}
+
+	set, errResp := getSet(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+
+	members := randomSetMembers(set, count)
+	for _, member := range members {
+		delete(set, member)
+	}
+	if set != nil && len(set) == 0 {
+		store.Del(args[0])
+	}
+
+	if len(args) == 2 {
+		return clientio.Encode(members, false)
+	}
+	if len(members) == 0 {
+		return clientio.RespNIL
+	}
+	return clientio.Encode(members[0], false)
+}
+
+// evalSRANDMEMBER returns random members of the set stored at key. Without
+// count, a single member is returned, or nil if the key does not exist. With a
+// positive count, up to count distinct members are returned. With a negative
+// count, -count members are returned, the same member possibly several times.
+func evalSRANDMEMBER(args []string, store *dstore.Store) []byte {
+	if len(args) < 1 || len(args) > 2 {
+		return diceerrors.NewErrArity("SRANDMEMBER")
+	}
+
+	count := 1
+	if len(args) == 2 {
+		var err error
+		count, err = strconv.Atoi(args[1])
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+	}
+
+	set, errResp := getSet(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+
+	members := randomSetMembers(set, count)
+	if len(args) == 2 {
+		return clientio.Encode(members, false)
+	}
+	if len(members) == 0 {
+		return clientio.RespNIL
+	}
+	return clientio.Encode(members[0], false)
+}
+
+// randomSetMembers returns members of set chosen at random: up to count
+// distinct ones with a positive count, or -count ones possibly repeated with a
+// negative count, see sampleIndexes.
+func randomSetMembers(set map[string]struct{}, count int) []string {
+	all := make([]string, 0, len(set))
+	for member := range set {
+		all = append(all, member)
+	}
+
+	indexes := sampleIndexes(len(all), count, nil)
+	members := make([]string, len(indexes))
+	for i, idx := range indexes {
+		members[i] = all[idx]
+	}
+	return members
+}
+
+// evalSUNION returns the members of the union of the sets stored at keys.
+// Non-existing keys are considered to be empty sets.
+func evalSUNION(args []string, store *dstore.Store) []byte {
+	if len(args) < 1 {
+		return diceerrors.NewErrArity("SUNION")
+	}
+
+	resultSet, errResp := sunionHelper(args, store)
+	if errResp != nil {
+		return errResp
+	}
+	return encodeSetMembers(resultSet)
+}
+
+// evalSUNIONSTORE stores the union of the sets stored at the given keys at
+// destination and returns its cardinality, see evalSINTERSTORE.
+//
+// Usage: SUNIONSTORE destination key [key ...] [TTL EX seconds | TTL PX milliseconds | TTL INHERITTTL]
+func evalSUNIONSTORE(args []string, store *dstore.Store) []byte {
+	return setAlgebraStore("SUNIONSTORE", args, sunionHelper, store)
+}
+
+// evalSDIFFSTORE stores the difference between the set stored at the first key
+// and the sets stored at the successive keys at destination and returns its
+// cardinality, see evalSINTERSTORE.
+//
+// Usage: SDIFFSTORE destination key [key ...] [TTL EX seconds | TTL PX milliseconds | TTL INHERITTTL]
+func evalSDIFFSTORE(args []string, store *dstore.Store) []byte {
+	return setAlgebraStore("SDIFFSTORE", args, sdiffHelper, store)
+}
+
+// evalSINTERCARD returns the cardinality of the intersection of the sets
+// stored at the given keys. With LIMIT, the computation stops once the
+// cardinality reaches limit, 0 meaning no limit.
+//
+// Usage: SINTERCARD numkeys key [key ...] [LIMIT limit]
+func evalSINTERCARD(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("SINTERCARD")
+	}
+
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	if numKeys <= 0 {
+		return diceerrors.NewErrWithMessage("numkeys should be greater than 0")
+	}
+	if numKeys > len(args)-1 {
+		return diceerrors.NewErrWithMessage("Number of keys can't be greater than number of args")
+	}
+
+	limit := 0
+	if opts := args[1+numKeys:]; len(opts) > 0 {
+		if len(opts) != 2 || !strings.EqualFold(opts[0], Limit) {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		if limit, err = strconv.Atoi(opts[1]); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if limit < 0 {
+			return diceerrors.NewErrWithMessage("LIMIT can't be negative")
+		}
+	}
+
+	sets := make([]map[string]struct{}, 0, numKeys)
+	empty := false
+	for _, key := range args[1 : 1+numKeys] {
+		set, errResp := getSet(key, store)
+		if errResp != nil {
+			return errResp
+		}
+		empty = empty || len(set) == 0
+		sets = append(sets, set)
+	}
+	if empty {
+		return clientio.RespZero
+	}
+
+	// the members of the smallest set are looked up in the other ones
+	sort.Slice(sets, func(i, j int) bool {
+		return len(sets[i]) < len(sets[j])
+	})
+
+	count := 0
+	for member := range sets[0] {
+		found := true
+		for _, set := range sets[1:] {
+			if _, found = set[member]; !found {
+				break
+			}
+		}
+		if found {
+			count++
+			if count == limit {
+				break
+			}
+		}
+	}
+	return clientio.Encode(count, false)
+}
+
+// setAlgebraStore evaluates a *STORE variant of a set algebra command, storing
+// the set computed by op from the source keys at destination.
+func setAlgebraStore(cmd string, args []string, op func([]string, *dstore.Store) (map[string]struct{}, []byte), store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity(cmd)
+	}
+
+	keys, ttlOpts, errResp := parseStoreTTLOpts(cmd, args[1:])
+	if errResp != nil {
+		return errResp
+	}
+
+	if len(keys) == 0 {
+		return diceerrors.NewErrArity(cmd)
+	}
+
+	resultSet, errResp := op(keys, store)
+	if errResp != nil {
+		return errResp
+	}
+
+	return storeSetResult(args[0], resultSet, keys, ttlOpts, store)
+}
+
+// sunionHelper returns the union of the sets stored at keys.
+// Non-existing keys are considered to be empty sets.
+func sunionHelper(keys []string, store *dstore.Store) (map[string]struct{}, []byte) {
+	resultSet := make(map[string]struct{})
+	for _, key := range keys {
+		set, errResp := getSet(key, store)
+		if errResp != nil {
+			return nil, errResp
+		}
+		for member := range set {
+			resultSet[member] = struct{}{}
+		}
+	}
+	return resultSet, nil
+}
+
+// sdiffHelper returns the difference between the set stored at the first key
+// and the sets stored at the successive keys.
+// Non-existing keys are considered to be empty sets.
+func sdiffHelper(keys []string, store *dstore.Store) (map[string]struct{}, []byte) {
+	srcSet, errResp := getSet(keys[0], store)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	resultSet := make(map[string]struct{}, len(srcSet))
+	for member := range srcSet {
+		resultSet[member] = struct{}{}
+	}
+
+	// the other sets are still read once the result is empty, so that an
+	// error is returned if one of them is not a set
+	for _, key := range keys[1:] {
+		set, errResp := getSet(key, store)
+		if errResp != nil {
+			return nil, errResp
+		}
+
+		// iterate over the smallest of the two sets
+		if len(resultSet) < len(set) {
+			for member := range resultSet {
+				if _, ok := set[member]; ok {
+					delete(resultSet, member)
+				}
+			}
+		} else {
+			for member := range set {
+				delete(resultSet, member)
+			}
+		}
+	}
+	return resultSet, nil
+}
+
+// encodeSetMembers returns the RESP encoded members of set.
+func encodeSetMembers(set map[string]struct{}) []byte {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	return clientio.Encode(members, false)
+}
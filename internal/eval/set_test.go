@@ -0,0 +1,142 @@
+package eval
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestSetMembership(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalSADD([]string{"s", "a", "b", "c"}, store)
+	evalSET([]string{"str", "value"}, store)
+
+	tests := map[string]struct {
+		result   []byte
+		expected string
+	}{
+		"member":                {evalSISMEMBER([]string{"s", "a"}, store), ":1\r\n"},
+		"not a member":          {evalSISMEMBER([]string{"s", "d"}, store), ":0\r\n"},
+		"missing key":           {evalSISMEMBER([]string{"missing", "a"}, store), ":0\r\n"},
+		"wrong type":            {evalSISMEMBER([]string{"str", "a"}, store), "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		"arity":                 {evalSISMEMBER([]string{"s"}, store), "-ERR wrong number of arguments for 'sismember' command\r\n"},
+		"members":               {evalSMISMEMBER([]string{"s", "a", "d", "c"}, store), "*3\r\n:1\r\n:0\r\n:1\r\n"},
+		"members of missing":    {evalSMISMEMBER([]string{"missing", "a"}, store), "*1\r\n:0\r\n"},
+		"members of wrong type": {evalSMISMEMBER([]string{"str", "a"}, store), "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(tc.result))
+		})
+	}
+}
+
+func TestSPOP(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalSADD([]string{"s", "a", "b", "c"}, store)
+
+	// a single member is returned as a bulk string
+	popped := decodeReply(evalSPOP([]string{"s"}, store)).(string)
+	assert.Equal(t, ":0\r\n", string(evalSISMEMBER([]string{"s", popped}, store)))
+	assert.Equal(t, ":2\r\n", string(evalSCARD([]string{"s"}, store)))
+
+	// the key is deleted once the set is empty
+	members := decodeReply(evalSPOP([]string{"s", "5"}, store)).([]interface{})
+	assert.Equal(t, 2, len(members))
+	assert.Assert(t, store.Get("s") == nil)
+
+	assert.Equal(t, string(clientio.RespNIL), string(evalSPOP([]string{"s"}, store)))
+	assert.Equal(t, "*0\r\n", string(evalSPOP([]string{"s", "1"}, store)))
+	assert.Equal(t, "-ERR value is out of range, must be positive\r\n", string(evalSPOP([]string{"s", "-1"}, store)))
+	assert.Equal(t, "-ERR wrong number of arguments for 'spop' command\r\n", string(evalSPOP([]string{"s", "1", "2"}, store)))
+}
+
+func TestSRANDMEMBER(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalSADD([]string{"s", "a", "b", "c"}, store)
+
+	toStrings := func(reply []byte) []string {
+		var members []string
+		for _, m := range decodeReply(reply).([]interface{}) {
+			members = append(members, m.(string))
+		}
+		sort.Strings(members)
+		return members
+	}
+
+	// positive counts return distinct members, negative counts may repeat them
+	assert.DeepEqual(t, []string{"a", "b", "c"}, toStrings(evalSRANDMEMBER([]string{"s", "5"}, store)))
+	assert.Equal(t, 2, len(toStrings(evalSRANDMEMBER([]string{"s", "2"}, store))))
+	assert.Equal(t, 7, len(toStrings(evalSRANDMEMBER([]string{"s", "-7"}, store))))
+	member := decodeReply(evalSRANDMEMBER([]string{"s"}, store)).(string)
+	assert.Equal(t, ":1\r\n", string(evalSISMEMBER([]string{"s", member}, store)))
+
+	// the set is left as it is
+	assert.Equal(t, ":3\r\n", string(evalSCARD([]string{"s"}, store)))
+
+	assert.Equal(t, string(clientio.RespNIL), string(evalSRANDMEMBER([]string{"missing"}, store)))
+	assert.Equal(t, "*0\r\n", string(evalSRANDMEMBER([]string{"missing", "-3"}, store)))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", string(evalSRANDMEMBER([]string{"s", "x"}, store)))
+}
+
+func TestSetAlgebra(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalSADD([]string{"s1", "a", "b", "c"}, store)
+	evalSADD([]string{"s2", "b", "c", "d"}, store)
+	evalSADD([]string{"s3", "c", "e"}, store)
+	evalSET([]string{"str", "value"}, store)
+
+	members := func(reply []byte) []string {
+		result := []string{}
+		for _, m := range decodeReply(reply).([]interface{}) {
+			result = append(result, m.(string))
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	assert.DeepEqual(t, []string{"a", "b", "c", "d", "e"}, members(evalSUNION([]string{"s1", "s2", "s3", "missing"}, store)))
+	assert.DeepEqual(t, []string{"a"}, members(evalSDIFF([]string{"s1", "s2", "s3"}, store)))
+	assert.DeepEqual(t, []string{}, members(evalSDIFF([]string{"missing", "s1"}, store)))
+
+	// the results are stored at the destination, which may be one of the sources
+	assert.Equal(t, ":5\r\n", string(evalSUNIONSTORE([]string{"u", "s1", "s2", "s3"}, store)))
+	assert.DeepEqual(t, []string{"a", "b", "c", "d", "e"}, members(evalSMEMBERS([]string{"u"}, store)))
+	assert.Equal(t, ":2\r\n", string(evalSDIFFSTORE([]string{"s1", "s1", "s3"}, store)))
+	assert.DeepEqual(t, []string{"a", "b"}, members(evalSMEMBERS([]string{"s1"}, store)))
+	assert.Equal(t, ":0\r\n", string(evalSDIFFSTORE([]string{"u", "s1", "s1"}, store)))
+	assert.Assert(t, store.Get("u") == nil)
+
+	// the TTL options of the destination
+	evalSUNIONSTORE([]string{"u", "s1", "TTL", "EX", "100"}, store)
+	assert.Equal(t, ":100\r\n", string(evalTTL([]string{"u"}, store)))
+
+	tests := map[string]struct {
+		result   []byte
+		expected string
+	}{
+		"union with a wrong type":           {evalSUNION([]string{"s1", "str"}, store), "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		"difference with a wrong type":      {evalSDIFF([]string{"missing", "str"}, store), "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		"store without source":              {evalSUNIONSTORE([]string{"u"}, store), "-ERR wrong number of arguments for 'sunionstore' command\r\n"},
+		"store keys named like the options": {evalSUNIONSTORE([]string{"u", "TTL", "EX", "10"}, store), ":0\r\n"},
+		"store with a wrong type":           {evalSDIFFSTORE([]string{"u", "s1", "str"}, store), "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		"intersection cardinality":          {evalSINTERCARD([]string{"2", "s2", "s3"}, store), ":1\r\n"},
+		"intersection cardinality of one":   {evalSINTERCARD([]string{"1", "s2"}, store), ":3\r\n"},
+		"intersection cardinality limited":  {evalSINTERCARD([]string{"1", "s2", "LIMIT", "2"}, store), ":2\r\n"},
+		"intersection cardinality no limit": {evalSINTERCARD([]string{"1", "s2", "limit", "0"}, store), ":3\r\n"},
+		"intersection with a missing key":   {evalSINTERCARD([]string{"2", "s2", "missing"}, store), ":0\r\n"},
+		"intersection with a wrong type":    {evalSINTERCARD([]string{"2", "missing", "str"}, store), "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		"invalid numkeys":                   {evalSINTERCARD([]string{"0", "s2"}, store), "-ERR numkeys should be greater than 0\r\n"},
+		"too many numkeys":                  {evalSINTERCARD([]string{"3", "s2", "s3"}, store), "-ERR Number of keys can't be greater than number of args\r\n"},
+		"negative limit":                    {evalSINTERCARD([]string{"1", "s2", "LIMIT", "-1"}, store), "-ERR LIMIT can't be negative\r\n"},
+		"unknown option":                    {evalSINTERCARD([]string{"1", "s2", "COUNT", "1"}, store), "-ERR syntax error\r\n"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(tc.result))
+		})
+	}
+}
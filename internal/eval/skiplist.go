@@ -0,0 +1,336 @@
+package eval
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// The members of a sorted set are ordered by a skip list, as in Redis. Each
+// link of a node records its span, the number of nodes it skips over plus one,
+// so that the rank of a member, and the member of a rank, are found in
+// O(log n) by summing the spans of the links followed from the head.
+
+const (
+	// skipListMaxLevel is the highest level of the nodes, enough for 4^32 members.
+	skipListMaxLevel = 32
+	// skipListP is the probability of a node to reach the next level.
+	skipListP = 0.25
+)
+
+type skipListLevel struct {
+	forward *skipListNode
+	// span is the difference between the ranks of forward and of the node, or
+	// the number of nodes following the node if forward is nil.
+	span int
+}
+
+type skipListNode struct {
+	item     *SortedSetItem
+	backward *skipListNode
+	levels   []skipListLevel
+}
+
+// skipList holds the items of a sorted set, ordered by SortedSetItem.Less. Only
+// the payload of an item may be modified while in the list, the item must
+// otherwise be removed and inserted again.
+type skipList struct {
+	head   *skipListNode // head is a sentinel node of all the levels, holding no item
+	tail   *skipListNode
+	length int
+	level  int // level is the highest level of the nodes
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		head:  &skipListNode{levels: make([]skipListLevel, skipListMaxLevel)},
+		level: 1,
+	}
+}
+
+func randomSkipListLevel() int {
+	level := 1
+	for level < skipListMaxLevel && rand.Float64() < skipListP { //nolint:gosec
+		level++
+	}
+	return level
+}
+
+// Len returns the number of items in the list.
+func (sl *skipList) Len() int {
+	return sl.length
+}
+
+// Min returns the lowest item, or nil if the list is empty.
+func (sl *skipList) Min() *SortedSetItem {
+	if first := sl.head.levels[0].forward; first != nil {
+		return first.item
+	}
+	return nil
+}
+
+// Max returns the highest item, or nil if the list is empty.
+func (sl *skipList) Max() *SortedSetItem {
+	if sl.tail != nil {
+		return sl.tail.item
+	}
+	return nil
+}
+
+// Insert adds item to the list, which must not hold an item of the same score
+// and member already.
+func (sl *skipList) Insert(item *SortedSetItem) {
+	var update [skipListMaxLevel]*skipListNode
+	var rank [skipListMaxLevel]int
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].forward != nil && x.levels[i].forward.item.Less(item) {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	level := randomSkipListLevel()
+	if level > sl.level {
+		for i := sl.level; i < level; i++ {
+			update[i] = sl.head
+			update[i].levels[i].span = sl.length
+		}
+		sl.level = level
+	}
+
+	x = &skipListNode{item: item, levels: make([]skipListLevel, level)}
+	for i := 0; i < level; i++ {
+		x.levels[i].forward = update[i].levels[i].forward
+		update[i].levels[i].forward = x
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+	// the links above the node skip one more node
+	for i := level; i < sl.level; i++ {
+		update[i].levels[i].span++
+	}
+
+	if update[0] != sl.head {
+		x.backward = update[0]
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x
+	} else {
+		sl.tail = x
+	}
+	sl.length++
+}
+
+// Get returns the item of the given score and member, or nil if there is none.
+func (sl *skipList) Get(score float64, member string) *SortedSetItem {
+	x := sl.seek(&SortedSetItem{Score: score, Member: member}).levels[0].forward
+	if x == nil || x.item.Score != score || x.item.Member != member {
+		return nil
+	}
+	return x.item
+}
+
+// Delete removes the item of the score and the member of item from the list.
+// It returns false if there is no such item.
+func (sl *skipList) Delete(item *SortedSetItem) bool {
+	var update [skipListMaxLevel]*skipListNode
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.item.Less(item) {
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	x = x.levels[0].forward
+	if x == nil || x.item.Score != item.Score || x.item.Member != item.Member {
+		return false
+	}
+
+	for i := 0; i < sl.level; i++ {
+		if update[i].levels[i].forward == x {
+			update[i].levels[i].span += x.levels[i].span - 1
+			update[i].levels[i].forward = x.levels[i].forward
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x.backward
+	} else {
+		sl.tail = x.backward
+	}
+	for sl.level > 1 && sl.head.levels[sl.level-1].forward == nil {
+		sl.level--
+	}
+	sl.length--
+	return true
+}
+
+// DeleteMin removes and returns the lowest item, or nil if the list is empty.
+func (sl *skipList) DeleteMin() *SortedSetItem {
+	item := sl.Min()
+	if item != nil {
+		sl.Delete(item)
+	}
+	return item
+}
+
+// DeleteMax removes and returns the highest item, or nil if the list is empty.
+func (sl *skipList) DeleteMax() *SortedSetItem {
+	item := sl.Max()
+	if item != nil {
+		sl.Delete(item)
+	}
+	return item
+}
+
+// Rank returns the number of items lower than item, i.e. its 0-based rank if it
+// is in the list.
+func (sl *skipList) Rank(item *SortedSetItem) int {
+	rank := 0
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.item.Less(item) {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+	}
+	return rank
+}
+
+// nodeByRank returns the node of the 0-based rank, or nil if rank is out of
+// the list.
+func (sl *skipList) nodeByRank(rank int) *skipListNode {
+	if rank < 0 || rank >= sl.length {
+		return nil
+	}
+	// the head has the rank -1
+	traversed := -1
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && traversed+x.levels[i].span <= rank {
+			traversed += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+// seek returns the last node whose item is lower than pivot, the head if there
+// is none.
+func (sl *skipList) seek(pivot *SortedSetItem) *skipListNode {
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.item.Less(pivot) {
+			x = x.levels[i].forward
+		}
+	}
+	return x
+}
+
+func ascendFrom(x *skipListNode, fn func(item *SortedSetItem) bool) {
+	for x != nil && fn(x.item) {
+		x = x.levels[0].forward
+	}
+}
+
+func descendFrom(x *skipListNode, fn func(item *SortedSetItem) bool) {
+	for x != nil && fn(x.item) {
+		x = x.backward
+	}
+}
+
+// Ascend calls fn with the items by ascending order, till fn returns false.
+func (sl *skipList) Ascend(fn func(item *SortedSetItem) bool) {
+	ascendFrom(sl.head.levels[0].forward, fn)
+}
+
+// AscendFromRank calls fn with the items by ascending order from the 0-based
+// rank, till fn returns false.
+func (sl *skipList) AscendFromRank(rank int, fn func(item *SortedSetItem) bool) {
+	ascendFrom(sl.nodeByRank(rank), fn)
+}
+
+// AscendGreaterOrEqual calls fn with the items greater than or equal to pivot
+// by ascending order, till fn returns false.
+func (sl *skipList) AscendGreaterOrEqual(pivot *SortedSetItem, fn func(item *SortedSetItem) bool) {
+	ascendFrom(sl.seek(pivot).levels[0].forward, fn)
+}
+
+// AscendLessThan calls fn with the items lower than pivot by ascending order,
+// till fn returns false.
+func (sl *skipList) AscendLessThan(pivot *SortedSetItem, fn func(item *SortedSetItem) bool) {
+	sl.Ascend(func(item *SortedSetItem) bool {
+		return item.Less(pivot) && fn(item)
+	})
+}
+
+// Descend calls fn with the items by descending order, till fn returns false.
+func (sl *skipList) Descend(fn func(item *SortedSetItem) bool) {
+	descendFrom(sl.tail, fn)
+}
+
+// DescendFromRank calls fn with the items by descending order from the 0-based
+// rank, ranked by ascending order, till fn returns false.
+func (sl *skipList) DescendFromRank(rank int, fn func(item *SortedSetItem) bool) {
+	descendFrom(sl.nodeByRank(rank), fn)
+}
+
+// DescendLessOrEqual calls fn with the items lower than or equal to pivot by
+// descending order, till fn returns false.
+func (sl *skipList) DescendLessOrEqual(pivot *SortedSetItem, fn func(item *SortedSetItem) bool) {
+	x := sl.seek(pivot)
+	if next := x.levels[0].forward; next != nil && !pivot.Less(next.item) {
+		x = next
+	}
+	if x != sl.head {
+		descendFrom(x, fn)
+	}
+}
+
+// validate checks the links of the list: the backward links, the tail, the
+// length and the span of every link, as counted along the lowest level.
+func (sl *skipList) validate() error {
+	// the ranks of the nodes, the head having the rank -1
+	ranks := map[*skipListNode]int{sl.head: -1}
+	var prev *skipListNode
+	rank := 0
+	for x := sl.head.levels[0].forward; x != nil; x = x.levels[0].forward {
+		if x.backward != prev {
+			return fmt.Errorf("node of rank %d has a wrong backward link", rank)
+		}
+		ranks[x] = rank
+		prev = x
+		rank++
+	}
+	if sl.tail != prev {
+		return fmt.Errorf("wrong tail")
+	}
+	if sl.length != rank {
+		return fmt.Errorf("length %d but %d nodes", sl.length, rank)
+	}
+
+	for i := 0; i < sl.level; i++ {
+		for x := sl.head; x != nil; x = x.levels[i].forward {
+			want := sl.length - 1 - ranks[x]
+			if next := x.levels[i].forward; next != nil {
+				if _, ok := ranks[next]; !ok {
+					return fmt.Errorf("level %d links to a node out of the list", i)
+				}
+				want = ranks[next] - ranks[x]
+			}
+			if x.levels[i].span != want {
+				return fmt.Errorf("node of rank %d has a span of %d instead of %d at level %d", ranks[x], x.levels[i].span, want, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package eval
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// TestSkipList runs random insertions and deletions against a sorted slice,
+// checking the ranks and the iterations of the skip list after each batch.
+func TestSkipList(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+	sl := newSkipList()
+	var want []*SortedSetItem
+	find := func(item *SortedSetItem) int {
+		return sort.Search(len(want), func(i int) bool { return !want[i].Less(item) })
+	}
+
+	for round := 0; round < 50; round++ {
+		for i := 0; i < 40; i++ {
+			item := &SortedSetItem{Score: float64(rng.Intn(20)), Member: fmt.Sprintf("m%d", rng.Intn(100))}
+			pos := find(item)
+			exists := pos < len(want) && !item.Less(want[pos])
+			if rng.Intn(3) == 0 || exists {
+				assert.Equal(t, exists, sl.Delete(item))
+				if exists {
+					want = append(want[:pos], want[pos+1:]...)
+				}
+				continue
+			}
+			sl.Insert(item)
+			want = append(want[:pos], append([]*SortedSetItem{item}, want[pos:]...)...)
+		}
+
+		assert.NilError(t, sl.validate())
+		assert.Equal(t, len(want), sl.Len())
+		for rank, item := range want {
+			assert.Equal(t, rank, sl.Rank(item))
+			assert.Equal(t, item, sl.nodeByRank(rank).item)
+		}
+		assert.Assert(t, sl.nodeByRank(len(want)) == nil)
+
+		var got []*SortedSetItem
+		sl.Ascend(func(item *SortedSetItem) bool {
+			got = append(got, item)
+			return true
+		})
+		assert.DeepEqual(t, want, got)
+
+		if len(want) == 0 {
+			assert.Assert(t, sl.Min() == nil && sl.Max() == nil)
+			continue
+		}
+		assert.Equal(t, want[0], sl.Min())
+		assert.Equal(t, want[len(want)-1], sl.Max())
+
+		from := rng.Intn(len(want))
+		got = nil
+		sl.AscendFromRank(from, func(item *SortedSetItem) bool {
+			got = append(got, item)
+			return true
+		})
+		assert.DeepEqual(t, want[from:], got)
+
+		got = nil
+		sl.DescendFromRank(from, func(item *SortedSetItem) bool {
+			got = append(got, item)
+			return len(got) < 3
+		})
+		for i, item := range got {
+			assert.Equal(t, want[from-i], item)
+		}
+
+		pivot := &SortedSetItem{Score: float64(rng.Intn(20)), Member: "m5"}
+		pos := find(pivot)
+		got = nil
+		sl.AscendGreaterOrEqual(pivot, func(item *SortedSetItem) bool {
+			got = append(got, item)
+			return true
+		})
+		assert.DeepEqual(t, want[pos:], got)
+
+		got = nil
+		sl.AscendLessThan(pivot, func(item *SortedSetItem) bool {
+			got = append(got, item)
+			return true
+		})
+		assert.DeepEqual(t, want[:pos], got)
+
+		got = nil
+		sl.DescendLessOrEqual(want[pos/2], func(item *SortedSetItem) bool {
+			got = append(got, item)
+			return true
+		})
+		assert.Equal(t, pos/2+1, len(got))
+		assert.Equal(t, want[pos/2], got[0])
+	}
+}
+
+func TestSkipListPop(t *testing.T) {
+	sl := newSkipList()
+	for i := 0; i < 10; i++ {
+		sl.Insert(&SortedSetItem{Score: float64(i % 5), Member: fmt.Sprintf("m%d", i)})
+	}
+
+	assert.Equal(t, "m0", sl.DeleteMin().Member)
+	assert.Equal(t, "m9", sl.DeleteMax().Member)
+	assert.Equal(t, "m5", sl.DeleteMin().Member)
+	assert.NilError(t, sl.validate())
+	assert.Equal(t, 7, sl.Len())
+
+	for sl.Len() > 0 {
+		sl.DeleteMax()
+	}
+	assert.Assert(t, sl.DeleteMin() == nil && sl.DeleteMax() == nil)
+	assert.NilError(t, sl.validate())
+}
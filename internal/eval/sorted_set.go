@@ -1,19 +1,1462 @@
 package eval
 
-import "github.com/google/btree"
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 
-// SortedSetItem represents a member of a sorted set. It includes a score and a member.
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/comm"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/querymanager"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var errScoreNaN = diceerrors.NewErr(diceerrors.ScoreNaNErr)
+
+// SortedSetItem represents a member of a sorted set. It includes a score and a member,
+// and optionally a payload, auxiliary data stored along with the member.
 type SortedSetItem struct {
-	btree.Item
-	Score  float64
-	Member string
+	Score   float64
+	Member  string
+	Payload string // Payload is empty if the member has none
 }
 
-// Less compares two SortedSetItems. Required by the btree.Item interface.
-func (a *SortedSetItem) Less(b btree.Item) bool {
-	other := b.(*SortedSetItem)
+// Less compares two SortedSetItems, by score and then by member. It is the
+// order of the skip list of a sorted set.
+func (a *SortedSetItem) Less(other *SortedSetItem) bool {
 	if a.Score != other.Score {
 		return a.Score < other.Score
 	}
 	return a.Member < other.Member
 }
+
+// incrScore returns score incremented by incr. It fails if the result is not a
+// number, i.e. when adding infinities of opposite signs, or if the sum of two
+// finite scores overflows to an infinity.
+func incrScore(score, incr float64) (float64, error) {
+	res := score + incr
+	if math.IsNaN(res) {
+		return 0, errScoreNaN
+	}
+	if math.IsInf(res, 0) && !math.IsInf(score, 0) && !math.IsInf(incr, 0) {
+		return 0, errScoreNaN
+	}
+	return res, nil
+}
+
+// getSortedSet returns the tree and the member map of the sorted set held by
+// obj, or an encoded error if obj holds another type.
+func getSortedSet(obj *object.Obj) (*skipList, map[string]float64, []byte) {
+	if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeSortedSet, object.ObjEncodingBTree); err != nil {
+		return nil, nil, diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
+
+	valueSlice, ok := obj.Value.([]interface{})
+	if !ok || len(valueSlice) != 2 {
+		return nil, nil, diceerrors.NewErrWithMessage("Invalid sorted set object")
+	}
+	return valueSlice[0].(*skipList), valueSlice[1].(map[string]float64), nil
+}
+
+// PruneBelowScore removes the members of the sorted set held by obj with a
+// score lower than threshold. It returns the number of members removed and
+// whether the sorted set is empty afterwards. It is the dstore.PruneFunc of
+// the retention policies of the sorted sets.
+func PruneBelowScore(obj *object.Obj, threshold float64) (removed int, empty bool) {
+	tree, memberMap, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return 0, false
+	}
+
+	var pruned []*SortedSetItem
+	tree.AscendLessThan(&SortedSetItem{Score: threshold}, func(item *SortedSetItem) bool {
+		pruned = append(pruned, item)
+		return true
+	})
+	for _, item := range pruned {
+		tree.Delete(item)
+		delete(memberMap, item.Member)
+	}
+	return len(pruned), tree.Len() == 0
+}
+
+// evalZPRUNE removes the members of the sorted set stored at key with a score
+// lower than threshold, deleting the key once the sorted set is empty. It
+// returns the number of members removed.
+//
+// Usage: ZPRUNE key threshold
+func evalZPRUNE(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("ZPRUNE")
+	}
+
+	threshold, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || math.IsNaN(threshold) {
+		return diceerrors.NewErrWithMessage(diceerrors.InvalidFloatErr)
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.RespZero
+	}
+	if _, _, errResp := getSortedSet(obj); errResp != nil {
+		return errResp
+	}
+
+	removed, empty := PruneBelowScore(obj, threshold)
+	if empty {
+		store.Del(args[0])
+	}
+	return clientio.Encode(removed, false)
+}
+
+// evalZRETENTION sets the retention policy of the sorted set stored at key,
+// whose scores are timestamps in milliseconds: the members older than the
+// retention window are periodically removed by the shard. A window of 0
+// removes the policy. The policy is dropped along with the sorted set, once
+// the key is deleted or overwritten.
+//
+// It returns 1 if the policy was set or removed, 0 if the key does not exist.
+//
+// Usage: ZRETENTION key milliseconds
+func evalZRETENTION(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("ZRETENTION")
+	}
+
+	windowMs, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || windowMs < 0 {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.RespZero
+	}
+	if _, _, errResp := getSortedSet(obj); errResp != nil {
+		return errResp
+	}
+
+	if windowMs == 0 {
+		store.DelPrunePolicy(args[0])
+	} else {
+		store.SetPrunePolicy(args[0], windowMs, PruneBelowScore)
+	}
+	return clientio.RespOne
+}
+
+// zsetRank returns the rank of the member of the given score, by ascending
+// scores.
+func zsetRank(tree *skipList, score float64, member string) int {
+	return tree.Rank(&SortedSetItem{Score: score, Member: member})
+}
+
+// zsetDeltaRecorder records the changes of the members of a sorted set, for
+// the clients watching the deltas of its key with ZWATCH. A nil recorder, the
+// one of the keys not watched, records nothing.
+type zsetDeltaRecorder struct {
+	key     string
+	tree    *skipList
+	deltas  []dstore.ZSetDelta
+	oldRank int
+}
+
+// newZSetDeltaRecorder returns the recorder of the changes of the sorted set
+// stored at key, or nil if its deltas are not watched.
+func newZSetDeltaRecorder(key string, tree *skipList, store *dstore.Store) *zsetDeltaRecorder {
+	if !store.IsDeltaWatched(key) {
+		return nil
+	}
+	return &zsetDeltaRecorder{key: key, tree: tree}
+}
+
+// before is called before the score of an existing member changes, to record
+// its rank.
+func (r *zsetDeltaRecorder) before(member string, score float64) {
+	if r != nil {
+		r.oldRank = zsetRank(r.tree, score, member)
+	}
+}
+
+// after records the change of the score of a member, a nil score meaning that
+// the member is added or removed.
+func (r *zsetDeltaRecorder) after(member string, oldScore, newScore *float64) {
+	if r == nil {
+		return
+	}
+	delta := dstore.ZSetDelta{Member: member, OldScore: oldScore, NewScore: newScore}
+	if oldScore != nil && newScore != nil {
+		change := zsetRank(r.tree, *newScore, member) - r.oldRank
+		delta.RankChange = &change
+	}
+	r.deltas = append(r.deltas, delta)
+}
+
+// publish notifies the changes recorded.
+func (r *zsetDeltaRecorder) publish(store *dstore.Store) {
+	if r != nil {
+		store.NotifyDeltas(r.key, r.deltas)
+	}
+}
+
+// evalZREM removes the members from the sorted set stored at key, deleting the
+// key once the sorted set is empty. It returns the number of members removed,
+// the members that do not exist being ignored.
+//
+// Usage: ZREM key member [member ...]
+func evalZREM(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("ZREM")
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.RespZero
+	}
+	tree, memberMap, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+
+	recorder := newZSetDeltaRecorder(args[0], tree, store)
+	removed := 0
+	for _, member := range args[1:] {
+		score, ok := memberMap[member]
+		if !ok {
+			continue
+		}
+		tree.Delete(&SortedSetItem{Score: score, Member: member})
+		delete(memberMap, member)
+		recorder.after(member, &score, nil)
+		removed++
+	}
+
+	if tree.Len() == 0 {
+		store.Del(args[0])
+	}
+	recorder.publish(store)
+	return clientio.Encode(removed, false)
+}
+
+// EvalZWATCH adds the caller client to the watchers of the deltas of the sorted
+// set stored at key. Every time members are added, removed or have their score
+// changed, by ZADD, ZINCRBY or ZREM, the client is sent a push response of the
+// form [ZDELTA, key, deltas], every delta being [member, old score, new score,
+// rank change].
+//
+// It returns [ZWATCH, key, members] where members are the current members and
+// scores of the sorted set, by ascending scores, the deltas following from it.
+func EvalZWATCH(args []string, client *comm.Client, store *dstore.Store) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("ZWATCH")
+	}
+	if client == nil {
+		return diceerrors.NewErrWithMessage("ZWATCH is not supported by this server")
+	}
+
+	members := []string{}
+	if obj := store.Get(args[0]); obj != nil {
+		tree, _, errResp := getSortedSet(obj)
+		if errResp != nil {
+			return errResp
+		}
+		tree.Ascend(func(item *SortedSetItem) bool {
+			members = append(members, item.Member, formatScore(item.Score))
+			return true
+		})
+	}
+
+	done := make(chan struct{})
+	querymanager.DeltaSubscriptionChan <- querymanager.DeltaSubscription{
+		Subscribe: true,
+		Key:       args[0],
+		ClientFD:  client.Fd,
+		Done:      done,
+	}
+	// the deltas are sent only once the client is registered as a watcher
+	<-done
+	store.WatchDeltas(args[0])
+	client.Subscribed = true
+
+	return clientio.Encode([]interface{}{"ZWATCH", args[0], members}, false)
+}
+
+// EvalZUNWATCH removes the caller client from the watchers of the deltas of the
+// sorted set stored at key.
+func EvalZUNWATCH(args []string, client *comm.Client, store *dstore.Store) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("ZUNWATCH")
+	}
+	if client == nil {
+		return diceerrors.NewErrWithMessage("ZUNWATCH is not supported by this server")
+	}
+
+	querymanager.DeltaSubscriptionChan <- querymanager.DeltaSubscription{
+		Subscribe: false,
+		Key:       args[0],
+		ClientFD:  client.Fd,
+	}
+	store.UnwatchDeltas(args[0])
+
+	return clientio.RespOK
+}
+
+// firstNonEmptySortedSet returns the first key holding a non-empty sorted set,
+// along with its tree and member map, or an encoded error if one of the keys
+// before it holds another type.
+func firstNonEmptySortedSet(keys []string, store *dstore.Store) (string, *skipList, map[string]float64, []byte) {
+	for _, key := range keys {
+		obj := store.Get(key)
+		if obj == nil {
+			continue
+		}
+		tree, memberMap, errResp := getSortedSet(obj)
+		if errResp != nil {
+			return "", nil, nil, errResp
+		}
+		if tree.Len() > 0 {
+			return key, tree, memberMap, nil
+		}
+	}
+	return "", nil, nil, nil
+}
+
+// popSortedSet removes up to count members of the lowest scores, or of the
+// highest ones if highest is true, from the sorted set stored at key, deleting
+// the key once the sorted set is empty. It returns the members along with their
+// scores, in the order they were popped.
+func popSortedSet(key string, tree *skipList, memberMap map[string]float64, highest bool, count int64, store *dstore.Store) []string {
+	recorder := newZSetDeltaRecorder(key, tree, store)
+	result := make([]string, 0, 2*min(count, int64(tree.Len())))
+	for i := int64(0); i < count && tree.Len() > 0; i++ {
+		var ssi *SortedSetItem
+		if highest {
+			ssi = tree.DeleteMax()
+		} else {
+			ssi = tree.DeleteMin()
+		}
+		delete(memberMap, ssi.Member)
+		score := ssi.Score
+		recorder.after(ssi.Member, &score, nil)
+		result = append(result, ssi.Member, formatScore(ssi.Score))
+	}
+
+	if tree.Len() == 0 {
+		store.Del(key)
+	}
+	recorder.publish(store)
+	return result
+}
+
+// evalZPOPMIN removes and returns up to count members, 1 by default, with the
+// lowest scores in the sorted set stored at key, along with their scores.
+//
+// Usage: ZPOPMIN key [count]
+func evalZPOPMIN(args []string, store *dstore.Store) []byte {
+	return zpopHelper("ZPOPMIN", args, false, store)
+}
+
+// evalZPOPMAX is the counterpart of evalZPOPMIN popping the members with the
+// highest scores.
+//
+// Usage: ZPOPMAX key [count]
+func evalZPOPMAX(args []string, store *dstore.Store) []byte {
+	return zpopHelper("ZPOPMAX", args, true, store)
+}
+
+func zpopHelper(cmd string, args []string, highest bool, store *dstore.Store) []byte {
+	if len(args) < 1 || len(args) > 2 {
+		return diceerrors.NewErrArity(cmd)
+	}
+
+	count := int64(1)
+	if len(args) == 2 {
+		var err error
+		if count, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if count < 0 {
+			return diceerrors.NewErrWithMessage("value is out of range, must be positive")
+		}
+	}
+
+	key, tree, memberMap, errResp := firstNonEmptySortedSet(args[:1], store)
+	if errResp != nil {
+		return errResp
+	}
+	if tree == nil {
+		return clientio.Encode([]string{}, false)
+	}
+	return clientio.Encode(popSortedSet(key, tree, memberMap, highest, count, store), false)
+}
+
+// evalBZPOPMIN pops the member with the lowest score of the first non-empty
+// sorted set among the ones stored at the given keys, and returns the key along
+// with the member and its score. If all the sorted sets are empty, the client
+// is blocked till one of them is added to, or till the timeout in seconds
+// elapses, 0 blocking forever.
+//
+// Usage: BZPOPMIN key [key ...] timeout
+func evalBZPOPMIN(args []string, store *dstore.Store) ([]byte, *Blocked) {
+	return blockingZPop("BZPOPMIN", args, false, store)
+}
+
+// evalBZPOPMAX is the counterpart of evalBZPOPMIN popping the member with the
+// highest score.
+//
+// Usage: BZPOPMAX key [key ...] timeout
+func evalBZPOPMAX(args []string, store *dstore.Store) ([]byte, *Blocked) {
+	return blockingZPop("BZPOPMAX", args, true, store)
+}
+
+func blockingZPop(cmd string, args []string, highest bool, store *dstore.Store) ([]byte, *Blocked) {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity(cmd), nil
+	}
+
+	keys := args[:len(args)-1]
+	timeout, errResp := parseBlockingTimeout(args[len(args)-1])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	key, tree, memberMap, errResp := firstNonEmptySortedSet(keys, store)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if tree == nil {
+		return nil, &Blocked{Keys: keys, Timeout: timeout}
+	}
+
+	popped := popSortedSet(key, tree, memberMap, highest, 1, store)
+	return clientio.Encode([]string{key, popped[0], popped[1]}, false), nil
+}
+
+// parseZSetSide parses the side of the sorted sets the members are popped from,
+// MIN or MAX, returning true for MAX.
+func parseZSetSide(arg string) (bool, []byte) {
+	switch strings.ToUpper(arg) {
+	case Min:
+		return false, nil
+	case Max:
+		return true, nil
+	default:
+		return false, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+}
+
+// zmpopReply returns the key along with the members popped from it and their
+// scores, as pairs.
+func zmpopReply(key string, popped []string) []byte {
+	pairs := make([]interface{}, 0, len(popped)/2)
+	for i := 0; i < len(popped); i += 2 {
+		pairs = append(pairs, []string{popped[i], popped[i+1]})
+	}
+	return clientio.Encode([]interface{}{key, pairs}, false)
+}
+
+// evalZMPOP pops up to count members, 1 by default, with the lowest (MIN) or
+// the highest (MAX) scores from the first non-empty sorted set among the ones
+// stored at the given keys, and returns the key along with the members and
+// their scores. Returns nil if all the sorted sets are empty.
+//
+// Usage: ZMPOP numkeys key [key ...] MIN|MAX [COUNT count]
+func evalZMPOP(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("ZMPOP")
+	}
+
+	keys, highest, count, errResp := parseMPopArgs(args, parseZSetSide)
+	if errResp != nil {
+		return errResp
+	}
+
+	key, tree, memberMap, errResp := firstNonEmptySortedSet(keys, store)
+	if errResp != nil {
+		return errResp
+	}
+	if tree == nil {
+		return clientio.RespNIL
+	}
+	return zmpopReply(key, popSortedSet(key, tree, memberMap, highest, count, store))
+}
+
+// evalBZMPOP is the blocking variant of ZMPOP. If all the sorted sets are
+// empty, the client is blocked till one of them is added to, or till the
+// timeout in seconds elapses, 0 blocking forever.
+//
+// Usage: BZMPOP timeout numkeys key [key ...] MIN|MAX [COUNT count]
+func evalBZMPOP(args []string, store *dstore.Store) ([]byte, *Blocked) {
+	if len(args) < 4 {
+		return diceerrors.NewErrArity("BZMPOP"), nil
+	}
+
+	timeout, errResp := parseBlockingTimeout(args[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	keys, highest, count, errResp := parseMPopArgs(args[1:], parseZSetSide)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	key, tree, memberMap, errResp := firstNonEmptySortedSet(keys, store)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if tree == nil {
+		return nil, &Blocked{Keys: keys, Timeout: timeout}
+	}
+	return zmpopReply(key, popSortedSet(key, tree, memberMap, highest, count, store)), nil
+}
+
+var errLexRange = diceerrors.NewErr("min or max not valid string range item")
+
+// lexBound is a bound of the lexicographic ranges of members: [member and
+// (member are the inclusive and exclusive bounds, - and + the lowest and the
+// highest strings.
+type lexBound struct {
+	member    string
+	exclusive bool
+	inf       int // inf is -1 for -, 1 for +, 0 otherwise
+}
+
+// parseLexBound parses a bound of a lexicographic range.
+func parseLexBound(s string) (lexBound, error) {
+	switch {
+	case s == "-":
+		return lexBound{inf: -1}, nil
+	case s == "+":
+		return lexBound{inf: 1}, nil
+	case strings.HasPrefix(s, "["):
+		return lexBound{member: s[1:]}, nil
+	case strings.HasPrefix(s, "("):
+		return lexBound{member: s[1:], exclusive: true}, nil
+	}
+	return lexBound{}, errLexRange
+}
+
+// above returns true if member is not below the bound, taken as a minimum.
+func (b lexBound) above(member string) bool {
+	switch {
+	case b.inf != 0:
+		return b.inf < 0
+	case b.exclusive:
+		return member > b.member
+	}
+	return member >= b.member
+}
+
+// below returns true if member is not above the bound, taken as a maximum.
+func (b lexBound) below(member string) bool {
+	switch {
+	case b.inf != 0:
+		return b.inf > 0
+	case b.exclusive:
+		return member < b.member
+	}
+	return member <= b.member
+}
+
+// lexRange calls fn with the members of the sorted set between min and max, by
+// ascending order or by descending order if rev is true, till fn returns false.
+// As with Redis, the members are assumed to have the same score, the members
+// iterated being unspecified otherwise.
+func lexRange(tree *skipList, min, max lexBound, rev bool, fn func(item *SortedSetItem) bool) {
+	if tree.Len() == 0 || min.inf > 0 || max.inf < 0 {
+		return
+	}
+
+	if !rev {
+		iter := func(item *SortedSetItem) bool {
+			if !min.above(item.Member) {
+				return true
+			}
+			return max.below(item.Member) && fn(item)
+		}
+		if min.inf < 0 {
+			tree.Ascend(iter)
+		} else {
+			tree.AscendGreaterOrEqual(&SortedSetItem{Score: tree.Min().Score, Member: min.member}, iter)
+		}
+		return
+	}
+
+	iter := func(item *SortedSetItem) bool {
+		if !max.below(item.Member) {
+			return true
+		}
+		return min.above(item.Member) && fn(item)
+	}
+	if max.inf > 0 {
+		tree.Descend(iter)
+	} else {
+		tree.DescendLessOrEqual(&SortedSetItem{Score: tree.Max().Score, Member: max.member}, iter)
+	}
+}
+
+// evalZRANGEBYLEX returns the members of the sorted set stored at key between
+// min and max by lexicographic order, the members being assumed to have the
+// same score. The bounds are either [member or (member, inclusive and
+// exclusive, or - and + for the lowest and the highest strings. LIMIT skips
+// the first offset members and returns count members at most, all of them if
+// count is negative.
+//
+// Usage: ZRANGEBYLEX key min max [LIMIT offset count]
+func evalZRANGEBYLEX(args []string, store *dstore.Store) []byte {
+	return zrangeByLexHelper("ZRANGEBYLEX", args, false, store)
+}
+
+// evalZREVRANGEBYLEX is the counterpart of evalZRANGEBYLEX returning the
+// members by descending lexicographic order, from max to min.
+//
+// Usage: ZREVRANGEBYLEX key max min [LIMIT offset count]
+func evalZREVRANGEBYLEX(args []string, store *dstore.Store) []byte {
+	return zrangeByLexHelper("ZREVRANGEBYLEX", args, true, store)
+}
+
+func zrangeByLexHelper(cmd string, args []string, rev bool, store *dstore.Store) []byte {
+	if len(args) != 3 && len(args) != 6 {
+		if len(args) < 3 {
+			return diceerrors.NewErrArity(cmd)
+		}
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+
+	minArg, maxArg := args[1], args[2]
+	if rev {
+		minArg, maxArg = maxArg, minArg
+	}
+	min, err := parseLexBound(minArg)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	max, err := parseLexBound(maxArg)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	offset, count := int64(0), int64(-1)
+	if len(args) == 6 {
+		if !strings.EqualFold(args[3], Limit) {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		if offset, err = strconv.ParseInt(args[4], 10, 64); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if count, err = strconv.ParseInt(args[5], 10, 64); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.Encode([]string{}, false)
+	}
+	tree, _, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+
+	members := []string{}
+	if offset < 0 || count == 0 {
+		return clientio.Encode(members, false)
+	}
+	lexRange(tree, min, max, rev, func(item *SortedSetItem) bool {
+		if offset > 0 {
+			offset--
+			return true
+		}
+		members = append(members, item.Member)
+		return count < 0 || int64(len(members)) < count
+	})
+	return clientio.Encode(members, false)
+}
+
+// evalZLEXCOUNT returns the number of members of the sorted set stored at key
+// between min and max by lexicographic order, see evalZRANGEBYLEX.
+//
+// Usage: ZLEXCOUNT key min max
+func evalZLEXCOUNT(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("ZLEXCOUNT")
+	}
+
+	min, err := parseLexBound(args[1])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	max, err := parseLexBound(args[2])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.Encode(0, false)
+	}
+	tree, _, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+
+	count := 0
+	lexRange(tree, min, max, false, func(*SortedSetItem) bool {
+		count++
+		return true
+	})
+	return clientio.Encode(count, false)
+}
+
+var errScoreRange = diceerrors.NewErr("min or max is not a float")
+
+// scoreBound is a bound of the ranges of scores: score and (score are the
+// inclusive and exclusive bounds, -inf and +inf being valid scores.
+type scoreBound struct {
+	score     float64
+	exclusive bool
+}
+
+// parseScoreBound parses a bound of a range of scores.
+func parseScoreBound(s string) (scoreBound, error) {
+	b := scoreBound{}
+	if strings.HasPrefix(s, "(") {
+		s, b.exclusive = s[1:], true
+	}
+	score, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(score) {
+		return scoreBound{}, errScoreRange
+	}
+	b.score = score
+	return b, nil
+}
+
+// above returns true if score is not below the bound, taken as a minimum.
+func (b scoreBound) above(score float64) bool {
+	if b.exclusive {
+		return score > b.score
+	}
+	return score >= b.score
+}
+
+// below returns true if score is not above the bound, taken as a maximum.
+func (b scoreBound) below(score float64) bool {
+	if b.exclusive {
+		return score < b.score
+	}
+	return score <= b.score
+}
+
+// seekGE calls fn with the items of the sorted set with a score greater than or
+// equal to score, by ascending scores, till fn returns false. It descends the
+// skip list straight to the first of them rather than walking the lower scores.
+func seekGE(tree *skipList, score float64, fn func(item *SortedSetItem) bool) {
+	// the empty member is the lowest of the members of the score
+	tree.AscendGreaterOrEqual(&SortedSetItem{Score: score}, fn)
+}
+
+// scoreRange calls fn with the items of the sorted set with a score between
+// min and max, by ascending scores, till fn returns false.
+func scoreRange(tree *skipList, min, max scoreBound, fn func(item *SortedSetItem) bool) {
+	from := min.score
+	if min.exclusive {
+		// the members of the score itself are skipped by seeking to the next
+		// representable score, however many they are
+		if math.IsInf(from, 1) {
+			return
+		}
+		from = math.Nextafter(from, math.Inf(1))
+	}
+	seekGE(tree, from, func(item *SortedSetItem) bool {
+		return max.below(item.Score) && fn(item)
+	})
+}
+
+// evalZRANGEBYSCORE returns the members of the sorted set stored at key with a
+// score between min and max, by ascending scores, along with their scores with
+// WITHSCORES. The bounds are inclusive, or exclusive when prefixed by (, -inf
+// and +inf being valid bounds. LIMIT skips the first offset members and
+// returns count members at most, all of them if count is negative.
+// WITHPAYLOADS returns the payloads of the members after their scores, an
+// empty string for the members without one.
+//
+// Usage: ZRANGEBYSCORE key min max [WITHSCORES] [WITHPAYLOADS] [LIMIT offset count]
+func evalZRANGEBYSCORE(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("ZRANGEBYSCORE")
+	}
+
+	min, err := parseScoreBound(args[1])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	max, err := parseScoreBound(args[2])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	withScores, withPayloads := false, false
+	offset, count := int64(0), int64(-1)
+	for i := 3; i < len(args); i++ {
+		switch {
+		case strings.EqualFold(args[i], WithScores):
+			withScores = true
+		case strings.EqualFold(args[i], WithPayloads):
+			withPayloads = true
+		case strings.EqualFold(args[i], Limit) && i+2 < len(args):
+			if offset, err = strconv.ParseInt(args[i+1], 10, 64); err != nil {
+				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			if count, err = strconv.ParseInt(args[i+2], 10, 64); err != nil {
+				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			i += 2
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.Encode([]string{}, false)
+	}
+	tree, _, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+
+	reply := []string{}
+	if offset < 0 || count == 0 {
+		return clientio.Encode(reply, false)
+	}
+	returned := int64(0)
+	scoreRange(tree, min, max, func(item *SortedSetItem) bool {
+		if offset > 0 {
+			offset--
+			return true
+		}
+		reply = append(reply, item.Member)
+		if withScores {
+			reply = append(reply, formatScore(item.Score))
+		}
+		if withPayloads {
+			reply = append(reply, item.Payload)
+		}
+		returned++
+		return count < 0 || returned < count
+	})
+	return clientio.Encode(reply, false)
+}
+
+// evalZCOUNT returns the number of members of the sorted set stored at key with
+// a score between min and max, see evalZRANGEBYSCORE.
+//
+// Usage: ZCOUNT key min max
+func evalZCOUNT(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("ZCOUNT")
+	}
+
+	min, err := parseScoreBound(args[1])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	max, err := parseScoreBound(args[2])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.RespZero
+	}
+	tree, _, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+
+	count := 0
+	scoreRange(tree, min, max, func(*SortedSetItem) bool {
+		count++
+		return true
+	})
+	return clientio.Encode(count, false)
+}
+
+// removeSortedSetItems removes the items, collected from the tree of the sorted
+// set stored at key, deleting the key once the sorted set is empty. It returns
+// the number of members removed.
+func removeSortedSetItems(key string, tree *skipList, memberMap map[string]float64, items []*SortedSetItem, store *dstore.Store) int {
+	recorder := newZSetDeltaRecorder(key, tree, store)
+	emptied := len(items) == tree.Len()
+	for _, item := range items {
+		// the tree is dropped along with the key once emptied
+		if !emptied {
+			tree.Delete(item)
+			delete(memberMap, item.Member)
+		}
+		score := item.Score
+		recorder.after(item.Member, &score, nil)
+	}
+
+	if emptied && len(items) > 0 {
+		store.Del(key)
+	}
+	recorder.publish(store)
+	return len(items)
+}
+
+// zremRangeHelper removes the items of the sorted set stored at key that
+// collect iterates, see removeSortedSetItems.
+func zremRangeHelper(key string, store *dstore.Store, collect func(tree *skipList, fn func(item *SortedSetItem) bool)) []byte {
+	obj := store.Get(key)
+	if obj == nil {
+		return clientio.RespZero
+	}
+	tree, memberMap, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+
+	var items []*SortedSetItem
+	collect(tree, func(item *SortedSetItem) bool {
+		items = append(items, item)
+		return true
+	})
+	return clientio.Encode(removeSortedSetItems(key, tree, memberMap, items, store), false)
+}
+
+// evalZREMRANGEBYRANK removes the members of the sorted set stored at key with
+// a rank between start and stop, by ascending scores, deleting the key once the
+// sorted set is empty. The ranks are 0-based, negative ones being offsets from
+// the end of the sorted set, -1 being the last member. It returns the number of
+// members removed.
+//
+// Usage: ZREMRANGEBYRANK key start stop
+func evalZREMRANGEBYRANK(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("ZREMRANGEBYRANK")
+	}
+
+	start, err := strconv.Atoi(args[1])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	stop, err := strconv.Atoi(args[2])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+
+	return zremRangeHelper(args[0], store, func(tree *skipList, fn func(item *SortedSetItem) bool) {
+		length := tree.Len()
+		if start < 0 {
+			start = max(start+length, 0)
+		}
+		if stop < 0 {
+			stop += length
+		}
+		if start > stop || start >= length {
+			return
+		}
+
+		rank := start
+		tree.AscendFromRank(start, func(item *SortedSetItem) bool {
+			if rank > stop {
+				return false
+			}
+			fn(item)
+			rank++
+			return true
+		})
+	})
+}
+
+// evalZREMRANGEBYSCORE removes the members of the sorted set stored at key
+// with a score between min and max, deleting the key once the sorted set is
+// empty. The bounds are inclusive, or exclusive when prefixed by (, -inf and
+// +inf being valid bounds. It returns the number of members removed.
+//
+// Usage: ZREMRANGEBYSCORE key min max
+func evalZREMRANGEBYSCORE(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("ZREMRANGEBYSCORE")
+	}
+
+	min, err := parseScoreBound(args[1])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	max, err := parseScoreBound(args[2])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	return zremRangeHelper(args[0], store, func(tree *skipList, fn func(item *SortedSetItem) bool) {
+		scoreRange(tree, min, max, fn)
+	})
+}
+
+// evalZREMRANGEBYLEX removes the members of the sorted set stored at key
+// between min and max by lexicographic order, deleting the key once the sorted
+// set is empty. The bounds are the ones of ZRANGEBYLEX, the members being
+// assumed to have the same score. It returns the number of members removed.
+//
+// Usage: ZREMRANGEBYLEX key min max
+func evalZREMRANGEBYLEX(args []string, store *dstore.Store) []byte {
+	if len(args) != 3 {
+		return diceerrors.NewErrArity("ZREMRANGEBYLEX")
+	}
+
+	min, err := parseLexBound(args[1])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	max, err := parseLexBound(args[2])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	return zremRangeHelper(args[0], store, func(tree *skipList, fn func(item *SortedSetItem) bool) {
+		lexRange(tree, min, max, false, fn)
+	})
+}
+
+// zsetOpArgs are the arguments of ZUNION, ZINTER, ZDIFF and of their STORE
+// variants.
+type zsetOpArgs struct {
+	keys       []string
+	weights    []float64
+	aggregate  func(a, b float64) float64
+	withScores bool
+	ttl        storeTTLOpts // the TTL options of the destination of the STORE variants
+}
+
+// zsetAggregates are the functions combining the scores of a member in the
+// sorted sets of ZUNION and ZINTER. As with Redis, the sum of infinities of
+// opposite signs is 0.
+var zsetAggregates = map[string]func(a, b float64) float64{
+	Sum: func(a, b float64) float64 {
+		if res := a + b; !math.IsNaN(res) {
+			return res
+		}
+		return 0
+	},
+	Min: math.Min,
+	Max: math.Max,
+}
+
+// parseZSetOpArgs parses the arguments of the set operations on sorted sets,
+// from numkeys on. WEIGHTS and AGGREGATE are only accepted if weighted is true,
+// WITHSCORES if storing is false and the TTL options of the destination if it
+// is true.
+func parseZSetOpArgs(cmd string, args []string, weighted, storing bool) (*zsetOpArgs, []byte) {
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	if numKeys < 1 {
+		return nil, diceerrors.NewErrWithFormattedMessage("at least 1 input key is needed for '%s' command", strings.ToLower(cmd))
+	}
+	if numKeys > len(args)-1 {
+		return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+
+	op := &zsetOpArgs{keys: args[1 : 1+numKeys], aggregate: zsetAggregates[Sum], ttl: storeTTLOpts{expMs: -1}}
+	for i := 1 + numKeys; i < len(args); i++ {
+		switch arg := strings.ToUpper(args[i]); {
+		case arg == Weights && weighted:
+			if i+numKeys >= len(args) {
+				return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			op.weights = make([]float64, numKeys)
+			for j := range op.weights {
+				weight, err := strconv.ParseFloat(args[i+1+j], 64)
+				if err != nil || math.IsNaN(weight) {
+					return nil, diceerrors.NewErrWithMessage("weight value is not a float")
+				}
+				op.weights[j] = weight
+			}
+			i += numKeys
+		case arg == Aggregate && weighted:
+			if i+1 >= len(args) {
+				return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			aggregate, ok := zsetAggregates[strings.ToUpper(args[i+1])]
+			if !ok {
+				return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			op.aggregate = aggregate
+			i++
+		case arg == WithScores && !storing:
+			op.withScores = true
+		case isStoreTTLOpt(arg) && storing:
+			next, _, errResp := parseStoreTTLOpt(cmd, args, i, &op.ttl)
+			if errResp != nil {
+				return nil, errResp
+			}
+			i = next - 1
+		default:
+			return nil, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+	return op, nil
+}
+
+// weighted returns the score of a member of the i-th sorted set multiplied by
+// its weight. As with Redis, the product of 0 and an infinity is 0.
+func (op *zsetOpArgs) weighted(i int, score float64) float64 {
+	if op.weights == nil {
+		return score
+	}
+	if res := score * op.weights[i]; !math.IsNaN(res) {
+		return res
+	}
+	return 0
+}
+
+// zsetOpSources returns the scores of the members of the sorted sets stored at
+// keys, nil for the keys that do not exist. The members of the sets have a
+// score of 1, as with Redis.
+func zsetOpSources(keys []string, store *dstore.Store) ([]map[string]float64, []byte) {
+	sources := make([]map[string]float64, len(keys))
+	for i, key := range keys {
+		obj := store.Get(key)
+		if obj == nil {
+			continue
+		}
+		if object.AssertType(obj.TypeEncoding, object.ObjTypeSet) == nil {
+			members := obj.Value.(map[string]struct{})
+			sources[i] = make(map[string]float64, len(members))
+			for member := range members {
+				sources[i][member] = 1
+			}
+			continue
+		}
+		_, memberMap, errResp := getSortedSet(obj)
+		if errResp != nil {
+			return nil, errResp
+		}
+		sources[i] = memberMap
+	}
+	return sources, nil
+}
+
+// zsetUnion returns the union of the sorted sets, the scores of the members
+// being weighted and aggregated.
+func zsetUnion(op *zsetOpArgs, sources []map[string]float64) map[string]float64 {
+	result := make(map[string]float64)
+	for i, source := range sources {
+		for member, score := range source {
+			score = op.weighted(i, score)
+			if current, ok := result[member]; ok {
+				score = op.aggregate(current, score)
+			}
+			result[member] = score
+		}
+	}
+	return result
+}
+
+// zsetInter returns the intersection of the sorted sets, the scores of the
+// members being weighted and aggregated.
+func zsetInter(op *zsetOpArgs, sources []map[string]float64) map[string]float64 {
+	result := make(map[string]float64)
+	for _, source := range sources {
+		if len(source) == 0 {
+			return result
+		}
+	}
+
+member:
+	for member, score := range sources[0] {
+		score = op.weighted(0, score)
+		for i, source := range sources[1:] {
+			other, ok := source[member]
+			if !ok {
+				continue member
+			}
+			score = op.aggregate(score, op.weighted(i+1, other))
+		}
+		result[member] = score
+	}
+	return result
+}
+
+// zsetDiff returns the members of the first sorted set that are not in the
+// others, along with their scores.
+func zsetDiff(sources []map[string]float64) map[string]float64 {
+	result := make(map[string]float64)
+member:
+	for member, score := range sources[0] {
+		for _, source := range sources[1:] {
+			if _, ok := source[member]; ok {
+				continue member
+			}
+		}
+		result[member] = score
+	}
+	return result
+}
+
+// zsetOpReply returns the members of the result of a set operation by
+// ascending scores, along with their scores if withScores is true.
+func zsetOpReply(result map[string]float64, withScores bool) []byte {
+	items := make([]*SortedSetItem, 0, len(result))
+	for member, score := range result {
+		items = append(items, &SortedSetItem{Score: score, Member: member})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Less(items[j]) })
+
+	reply := make([]string, 0, 2*len(items))
+	for _, item := range items {
+		reply = append(reply, item.Member)
+		if withScores {
+			reply = append(reply, formatScore(item.Score))
+		}
+	}
+	return clientio.Encode(reply, false)
+}
+
+// zsetOpStore stores the result of a set operation as a sorted set at key,
+// replacing its value and applying the TTL options of op, or deletes the key
+// if the result is empty. It returns the number of members of the sorted set
+// stored.
+func zsetOpStore(key string, result map[string]float64, op *zsetOpArgs, store *dstore.Store) []byte {
+	if len(result) == 0 {
+		store.Del(key)
+		return clientio.RespZero
+	}
+
+	tree := newSkipList()
+	for member, score := range result {
+		tree.Insert(&SortedSetItem{Score: score, Member: member})
+	}
+	store.Put(key, store.NewObj([]interface{}{tree, result}, op.ttl.destExpiryMs(op.keys, store), object.ObjTypeSortedSet, object.ObjEncodingBTree))
+	return clientio.Encode(len(result), false)
+}
+
+// zsetOp runs ZUNION, ZINTER or ZDIFF, or their STORE variants if storing is
+// true, the destination key being args[0].
+func zsetOp(cmd string, args []string, compute func(op *zsetOpArgs, sources []map[string]float64) map[string]float64, weighted, storing bool, store *dstore.Store) []byte {
+	minArgs := 2
+	if storing {
+		minArgs = 3
+	}
+	if len(args) < minArgs {
+		return diceerrors.NewErrArity(cmd)
+	}
+
+	opArgs := args
+	if storing {
+		opArgs = args[1:]
+	}
+	op, errResp := parseZSetOpArgs(cmd, opArgs, weighted, storing)
+	if errResp != nil {
+		return errResp
+	}
+	sources, errResp := zsetOpSources(op.keys, store)
+	if errResp != nil {
+		return errResp
+	}
+
+	result := compute(op, sources)
+	if storing {
+		return zsetOpStore(args[0], result, op, store)
+	}
+	return zsetOpReply(result, op.withScores)
+}
+
+// evalZUNIONSTORE stores at destination the union of the sorted sets stored at
+// the numkeys keys, the sets being sorted sets whose members have a score of 1.
+// The score of a member is the sum of its scores in the sorted sets, or their
+// minimum or maximum with AGGREGATE MIN or MAX, the scores being multiplied by
+// the weight of their sorted set first with WEIGHTS. The destination is
+// replaced, and deleted if the union is empty. Its TTL is set with EX or PX,
+// or is the smallest TTL of the keys with INHERITTTL. It returns the number of
+// members of the sorted set stored.
+//
+// Usage: ZUNIONSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [EX seconds | PX milliseconds | INHERITTTL]
+func evalZUNIONSTORE(args []string, store *dstore.Store) []byte {
+	return zsetOp("ZUNIONSTORE", args, zsetUnion, true, true, store)
+}
+
+// evalZINTERSTORE is the counterpart of evalZUNIONSTORE storing the
+// intersection of the sorted sets.
+//
+// Usage: ZINTERSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [EX seconds | PX milliseconds | INHERITTTL]
+func evalZINTERSTORE(args []string, store *dstore.Store) []byte {
+	return zsetOp("ZINTERSTORE", args, zsetInter, true, true, store)
+}
+
+// evalZUNION returns the union of the sorted sets computed like
+// evalZUNIONSTORE does, by ascending scores, along with the scores with
+// WITHSCORES.
+//
+// Usage: ZUNION numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [WITHSCORES]
+func evalZUNION(args []string, store *dstore.Store) []byte {
+	return zsetOp("ZUNION", args, zsetUnion, true, false, store)
+}
+
+// evalZINTER returns the intersection of the sorted sets computed like
+// evalZINTERSTORE does, by ascending scores, along with the scores with
+// WITHSCORES.
+//
+// Usage: ZINTER numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM|MIN|MAX] [WITHSCORES]
+func evalZINTER(args []string, store *dstore.Store) []byte {
+	return zsetOp("ZINTER", args, zsetInter, true, false, store)
+}
+
+// evalZDIFF returns the members of the first sorted set that are not in the
+// other ones, by ascending scores, along with their scores with WITHSCORES.
+//
+// Usage: ZDIFF numkeys key [key ...] [WITHSCORES]
+func evalZDIFF(args []string, store *dstore.Store) []byte {
+	return zsetOp("ZDIFF", args, func(_ *zsetOpArgs, sources []map[string]float64) map[string]float64 {
+		return zsetDiff(sources)
+	}, false, false, store)
+}
+
+// evalZMSCORE returns the scores of the members of the sorted set stored at
+// key, nil for the members that are not in the sorted set.
+//
+// Usage: ZMSCORE key member [member ...]
+func evalZMSCORE(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("ZMSCORE")
+	}
+
+	results := make([]interface{}, len(args)-1)
+	for i := range results {
+		results[i] = clientio.RespNIL
+	}
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.Encode(results, false)
+	}
+	_, memberMap, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+
+	for i, member := range args[1:] {
+		if score, ok := memberMap[member]; ok {
+			results[i] = formatScore(score)
+		}
+	}
+	return clientio.Encode(results, false)
+}
+
+// zrankHelper runs ZRANK, or ZREVRANK if rev is true. The rank is found in
+// O(log n) from the spans of the skip list.
+func zrankHelper(cmd string, args []string, rev bool, store *dstore.Store) []byte {
+	if len(args) < 2 || len(args) > 3 {
+		return diceerrors.NewErrArity(cmd)
+	}
+	withScore := false
+	if len(args) == 3 {
+		if !strings.EqualFold(args[2], WithScore) {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		withScore = true
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return clientio.RespNIL
+	}
+	tree, memberMap, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return errResp
+	}
+	score, ok := memberMap[args[1]]
+	if !ok {
+		return clientio.RespNIL
+	}
+
+	rank := zsetRank(tree, score, args[1])
+	if rev {
+		rank = tree.Len() - 1 - rank
+	}
+	if withScore {
+		return clientio.Encode([]interface{}{rank, formatScore(score)}, false)
+	}
+	return clientio.Encode(rank, false)
+}
+
+// evalZRANK returns the 0-based rank of member in the sorted set stored at key,
+// by ascending scores, or nil if the member or the key does not exist.
+// WITHSCORE returns the score of the member along with its rank.
+//
+// Usage: ZRANK key member [WITHSCORE]
+func evalZRANK(args []string, store *dstore.Store) []byte {
+	return zrankHelper("ZRANK", args, false, store)
+}
+
+// evalZREVRANK is the counterpart of evalZRANK ranking the members by
+// descending scores.
+//
+// Usage: ZREVRANK key member [WITHSCORE]
+func evalZREVRANK(args []string, store *dstore.Store) []byte {
+	return zrankHelper("ZREVRANK", args, true, store)
+}
+
+// evalZRANDMEMBER returns random members of the sorted set stored at key.
+// Without count, a single member is returned, or nil if the key does not
+// exist. With a positive count, up to count distinct members are returned.
+// With a negative count, -count members are returned, the same member possibly
+// several times. WITHSCORES returns the scores along with the members.
+//
+// Usage: ZRANDMEMBER key [count [WITHSCORES]]
+func evalZRANDMEMBER(args []string, store *dstore.Store) []byte {
+	if len(args) < 1 || len(args) > 3 {
+		return diceerrors.NewErrArity("ZRANDMEMBER")
+	}
+
+	count := 1
+	withScores := false
+	if len(args) > 1 {
+		var err error
+		count, err = strconv.Atoi(args[1])
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if len(args) == 3 {
+			if !strings.EqualFold(args[2], WithScores) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			withScores = true
+		}
+	}
+
+	var memberMap map[string]float64
+	if obj := store.Get(args[0]); obj != nil {
+		var errResp []byte
+		if _, memberMap, errResp = getSortedSet(obj); errResp != nil {
+			return errResp
+		}
+	}
+
+	members := make([]string, 0, len(memberMap))
+	for member := range memberMap {
+		members = append(members, member)
+	}
+	results := make([]string, 0)
+	for _, i := range sampleIndexes(len(members), count, nil) {
+		results = append(results, members[i])
+		if withScores {
+			results = append(results, formatScore(memberMap[members[i]]))
+		}
+	}
+
+	if len(args) > 1 {
+		return clientio.Encode(results, false)
+	}
+	if len(results) == 0 {
+		return clientio.RespNIL
+	}
+	return clientio.Encode(results[0], false)
+}
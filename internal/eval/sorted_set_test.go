@@ -0,0 +1,639 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestZPRUNE(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	exec("ZADD", "events", "10", "a", "20", "b", "30", "c")
+	assert.Equal(t, ":0\r\n", exec("ZPRUNE", "events", "10"))
+	assert.Equal(t, ":2\r\n", exec("ZPRUNE", "events", "25.5"))
+	assert.Equal(t, "*2\r\n$1\r\nc\r\n$2\r\n30\r\n", exec("ZRANGE", "events", "0", "-1", "WITHSCORES"))
+	assert.Equal(t, ":0\r\n", exec("ZADD", "events", "40", "c"))
+	assert.Equal(t, "*1\r\n$1\r\nc\r\n", exec("ZRANGE", "events", "0", "-1"))
+
+	// the key is deleted once the sorted set is empty
+	assert.Equal(t, ":1\r\n", exec("ZPRUNE", "events", "+inf"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "events"))
+	assert.Equal(t, ":0\r\n", exec("ZPRUNE", "events", "100"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZPRUNE", "str", "100"))
+	assert.Equal(t, "-ERR value is not a valid float\r\n", exec("ZPRUNE", "events", "abc"))
+	assert.Equal(t, "-ERR value is not a valid float\r\n", exec("ZPRUNE", "events", "nan"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zprune' command\r\n", exec("ZPRUNE", "events"))
+}
+
+func TestZRETENTION(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, ":0\r\n", exec("ZRETENTION", "events", "1000"))
+
+	exec("ZADD", "events", "1", "a")
+	assert.Equal(t, ":1\r\n", exec("ZRETENTION", "events", "1000"))
+	windowMs, ok := store.PrunePolicy("events")
+	assert.Assert(t, ok)
+	assert.Equal(t, int64(1000), windowMs)
+
+	assert.Equal(t, ":1\r\n", exec("ZRETENTION", "events", "0"))
+	_, ok = store.PrunePolicy("events")
+	assert.Assert(t, !ok)
+
+	// the policy is dropped along with the sorted set
+	exec("ZRETENTION", "events", "1000")
+	exec("DEL", "events")
+	exec("ZADD", "events", "1", "a")
+	_, ok = store.PrunePolicy("events")
+	assert.Assert(t, !ok)
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZRETENTION", "str", "1000"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZRETENTION", "events", "-1"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZRETENTION", "events", "1.5"))
+}
+
+func TestPruneKeys(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+	ago := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(-d).UnixMilli(), 10)
+	}
+
+	var pruned []string
+	store.OnPrune(func(k string, _ float64) {
+		pruned = append(pruned, k)
+	})
+
+	exec("ZADD", "events", ago(time.Hour), "old", ago(2*time.Hour), "older", ago(0), "new")
+	exec("ZADD", "stale", ago(time.Hour), "old")
+	exec("ZADD", "other", ago(time.Hour), "old")
+	exec("ZRETENTION", "events", "60000")
+	exec("ZRETENTION", "stale", "60000")
+	exec("ZRETENTION", "other", "60000")
+
+	// the policy of an overwritten key is dropped
+	exec("DEL", "other")
+	exec("ZADD", "other", ago(time.Hour), "old")
+
+	dstore.PruneKeys(store)
+	assert.Equal(t, "*1\r\n$3\r\nnew\r\n", exec("ZRANGE", "events", "0", "-1"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "stale"))
+	assert.Equal(t, "*1\r\n$3\r\nold\r\n", exec("ZRANGE", "other", "0", "-1"))
+	assert.Equal(t, 2, len(pruned))
+	_, ok := store.PrunePolicy("stale")
+	assert.Assert(t, !ok)
+	_, ok = store.PrunePolicy("other")
+	assert.Assert(t, !ok)
+
+	// replicas leave the pruning to their primary
+	pruned = nil
+	replica := dstore.NewStore(nil)
+	replica.SetReplica(true)
+	replica.SetReplicating(true)
+	ExecuteCommand(&cmd.DiceDBCmd{Cmd: "ZADD", Args: []string{"events", ago(time.Hour), "old"}}, nil, replica, false, false)
+	ExecuteCommand(&cmd.DiceDBCmd{Cmd: "ZRETENTION", Args: []string{"events", "60000"}}, nil, replica, false, false)
+	replica.SetReplicating(false)
+	dstore.PruneKeys(replica)
+	assert.Equal(t, 1, replica.GetKeyCount())
+	assert.Equal(t, 0, len(pruned))
+}
+
+func TestZREM(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, ":0\r\n", exec("ZREM", "z", "a"))
+	exec("ZADD", "z", "1", "a", "2", "b", "3", "c")
+	assert.Equal(t, ":2\r\n", exec("ZREM", "z", "a", "c", "d", "a"))
+	assert.Equal(t, "*1\r\n$1\r\nb\r\n", exec("ZRANGE", "z", "0", "-1"))
+
+	// the key is deleted once the sorted set is empty
+	assert.Equal(t, ":1\r\n", exec("ZREM", "z", "b"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "z"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZREM", "str", "a"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zrem' command\r\n", exec("ZREM", "z"))
+}
+
+func TestZSetDeltas(t *testing.T) {
+	watchChan := make(chan dstore.QueryWatchEvent, 100)
+	store := dstore.NewStore(watchChan)
+	exec := func(name string, args ...string) {
+		ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+	deltas := func() []dstore.ZSetDelta {
+		for {
+			select {
+			case event := <-watchChan:
+				if event.Operation == dstore.ZDelta {
+					assert.Equal(t, "board", event.Key)
+					return event.Deltas
+				}
+			default:
+				return nil
+			}
+		}
+	}
+	score := func(s float64) *float64 { return &s }
+	rank := func(r int) *int { return &r }
+
+	// the deltas of the keys not watched are not computed
+	exec("ZADD", "board", "10", "a")
+	assert.Assert(t, deltas() == nil)
+
+	store.WatchDeltas("board")
+	exec("ZADD", "board", "20", "b", "5", "c", "10", "a")
+	assert.DeepEqual(t, []dstore.ZSetDelta{
+		{Member: "b", NewScore: score(20)},
+		{Member: "c", NewScore: score(5)},
+	}, deltas())
+
+	// a moves from the second to the last rank, c to the first
+	exec("ZINCRBY", "board", "15", "a")
+	assert.DeepEqual(t, []dstore.ZSetDelta{{Member: "a", OldScore: score(10), NewScore: score(25), RankChange: rank(1)}}, deltas())
+	exec("ZADD", "board", "XX", "1", "b", "30", "d")
+	assert.DeepEqual(t, []dstore.ZSetDelta{{Member: "b", OldScore: score(20), NewScore: score(1), RankChange: rank(-1)}}, deltas())
+	exec("ZREM", "board", "a", "missing")
+	assert.DeepEqual(t, []dstore.ZSetDelta{{Member: "a", OldScore: score(25)}}, deltas())
+
+	store.UnwatchDeltas("board")
+	exec("ZREM", "board", "b")
+	assert.Assert(t, deltas() == nil)
+}
+
+func TestZPOP(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, "*0\r\n", exec("ZPOPMIN", "z"))
+	exec("ZADD", "z", "1", "a", "2", "b", "3", "c", "4", "d")
+	assert.Equal(t, "*2\r\n$1\r\na\r\n$1\r\n1\r\n", exec("ZPOPMIN", "z"))
+	assert.Equal(t, "*4\r\n$1\r\nd\r\n$1\r\n4\r\n$1\r\nc\r\n$1\r\n3\r\n", exec("ZPOPMAX", "z", "2"))
+	assert.Equal(t, "*0\r\n", exec("ZPOPMAX", "z", "0"))
+
+	// the key is deleted once the sorted set is empty
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$1\r\n2\r\n", exec("ZPOPMIN", "z", "10"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "z"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZPOPMIN", "str"))
+	assert.Equal(t, "-ERR value is out of range, must be positive\r\n", exec("ZPOPMAX", "z", "-1"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZPOPMAX", "z", "one"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zpopmin' command\r\n", exec("ZPOPMIN", "z", "1", "2"))
+}
+
+func TestBZPOP(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) *EvalResponse {
+		return ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+	result := func(name string, args ...string) string {
+		return string(exec(name, args...).Result.([]byte))
+	}
+
+	exec("ZADD", "b", "1", "x", "2", "y")
+	assert.Equal(t, "*3\r\n$1\r\nb\r\n$1\r\nx\r\n$1\r\n1\r\n", result("BZPOPMIN", "a", "b", "0"))
+	assert.Equal(t, "*3\r\n$1\r\nb\r\n$1\r\ny\r\n$1\r\n2\r\n", result("BZPOPMAX", "a", "b", "0"))
+	assert.Equal(t, ":0\r\n", result("EXISTS", "b"))
+
+	resp := exec("BZPOPMIN", "a", "b", "0.5")
+	assert.Equal(t, "$-1\r\n", string(resp.Result.([]byte)))
+	assert.DeepEqual(t, &Blocked{Keys: []string{"a", "b"}, Timeout: 500 * time.Millisecond}, resp.Blocked)
+
+	// the client blocked is served once a member is added
+	var served string
+	store.Block(&dstore.Waiter{
+		Keys: resp.Blocked.Keys,
+		Serve: func() bool {
+			resp := exec("BZPOPMIN", "a", "b", "0")
+			if resp.Blocked != nil {
+				return false
+			}
+			served = string(resp.Result.([]byte))
+			return true
+		},
+	})
+	exec("ZADD", "a", "5", "z")
+	dstore.ServeBlocked(store)
+	assert.Equal(t, "*3\r\n$1\r\na\r\n$1\r\nz\r\n$1\r\n5\r\n", served)
+	assert.Assert(t, !store.HasWaiters())
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", result("BZPOPMAX", "str", "0"))
+	assert.Equal(t, "-ERR timeout is negative\r\n", result("BZPOPMAX", "a", "-1"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'bzpopmin' command\r\n", result("BZPOPMIN", "a"))
+}
+
+func TestZMPOP(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) *EvalResponse {
+		return ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+	}
+	result := func(name string, args ...string) string {
+		return string(exec(name, args...).Result.([]byte))
+	}
+
+	exec("ZADD", "b", "1", "x", "2", "y", "3", "z")
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n*1\r\n*2\r\n$1\r\nx\r\n$1\r\n1\r\n", result("ZMPOP", "2", "a", "b", "MIN"))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n*2\r\n*2\r\n$1\r\nz\r\n$1\r\n3\r\n*2\r\n$1\r\ny\r\n$1\r\n2\r\n",
+		result("ZMPOP", "2", "a", "b", "max", "COUNT", "5"))
+	assert.Equal(t, ":0\r\n", result("EXISTS", "b"))
+	assert.Equal(t, "$-1\r\n", result("ZMPOP", "2", "a", "b", "MIN"))
+
+	resp := exec("BZMPOP", "0.5", "2", "a", "b", "MIN")
+	assert.Equal(t, "$-1\r\n", string(resp.Result.([]byte)))
+	assert.DeepEqual(t, &Blocked{Keys: []string{"a", "b"}, Timeout: 500 * time.Millisecond}, resp.Blocked)
+
+	// the client blocked is served once a member is added
+	var served string
+	store.Block(&dstore.Waiter{
+		Keys: resp.Blocked.Keys,
+		Serve: func() bool {
+			resp := exec("BZMPOP", "0", "2", "a", "b", "MAX", "COUNT", "2")
+			if resp.Blocked != nil {
+				return false
+			}
+			served = string(resp.Result.([]byte))
+			return true
+		},
+	})
+	exec("ZADD", "b", "5", "w")
+	dstore.ServeBlocked(store)
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n*1\r\n*2\r\n$1\r\nw\r\n$1\r\n5\r\n", served)
+	assert.Assert(t, !store.HasWaiters())
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", result("ZMPOP", "1", "str", "MIN"))
+	assert.Equal(t, "-ERR numkeys should be greater than 0\r\n", result("ZMPOP", "0", "a", "MIN"))
+	assert.Equal(t, "-ERR syntax error\r\n", result("ZMPOP", "1", "a", "LEFT"))
+	assert.Equal(t, "-ERR syntax error\r\n", result("ZMPOP", "3", "a", "b", "MIN"))
+	assert.Equal(t, "-ERR count should be greater than 0\r\n", result("ZMPOP", "1", "a", "MIN", "COUNT", "0"))
+	assert.Equal(t, "-ERR timeout is negative\r\n", result("BZMPOP", "-1", "1", "a", "MIN"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'bzmpop' command\r\n", result("BZMPOP", "0", "1", "a"))
+}
+
+func TestZRANGEBYLEX(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+	members := func(m ...string) string {
+		res := "*" + strconv.Itoa(len(m)) + "\r\n"
+		for _, x := range m {
+			res += "$" + strconv.Itoa(len(x)) + "\r\n" + x + "\r\n"
+		}
+		return res
+	}
+
+	assert.Equal(t, "*0\r\n", exec("ZRANGEBYLEX", "z", "-", "+"))
+	assert.Equal(t, ":0\r\n", exec("ZLEXCOUNT", "z", "-", "+"))
+	exec("ZADD", "z", "0", "a", "0", "b", "0", "c", "0", "d", "0", "e", "0", "f", "0", "g")
+
+	assert.Equal(t, members("a", "b", "c", "d", "e", "f", "g"), exec("ZRANGEBYLEX", "z", "-", "+"))
+	assert.Equal(t, members("a", "b", "c"), exec("ZRANGEBYLEX", "z", "-", "[c"))
+	assert.Equal(t, members("a", "b"), exec("ZRANGEBYLEX", "z", "-", "(c"))
+	assert.Equal(t, members("b", "c", "d", "e", "f"), exec("ZRANGEBYLEX", "z", "[aaa", "(g"))
+	assert.Equal(t, members("c", "d"), exec("ZRANGEBYLEX", "z", "(b", "[d"))
+	assert.Equal(t, members(), exec("ZRANGEBYLEX", "z", "[d", "[c"))
+	assert.Equal(t, members(), exec("ZRANGEBYLEX", "z", "+", "-"))
+	assert.Equal(t, members("c", "d"), exec("ZRANGEBYLEX", "z", "-", "+", "LIMIT", "2", "2"))
+	assert.Equal(t, members("f", "g"), exec("ZRANGEBYLEX", "z", "[b", "+", "limit", "4", "-1"))
+	assert.Equal(t, members(), exec("ZRANGEBYLEX", "z", "-", "+", "LIMIT", "-1", "2"))
+	assert.Equal(t, members(), exec("ZRANGEBYLEX", "z", "-", "+", "LIMIT", "0", "0"))
+
+	assert.Equal(t, members("g", "f", "e", "d", "c", "b", "a"), exec("ZREVRANGEBYLEX", "z", "+", "-"))
+	assert.Equal(t, members("c", "b", "a"), exec("ZREVRANGEBYLEX", "z", "[c", "-"))
+	assert.Equal(t, members("f", "e", "d", "c", "b"), exec("ZREVRANGEBYLEX", "z", "(g", "[aaa"))
+	assert.Equal(t, members("e", "d"), exec("ZREVRANGEBYLEX", "z", "+", "-", "LIMIT", "2", "2"))
+
+	assert.Equal(t, ":7\r\n", exec("ZLEXCOUNT", "z", "-", "+"))
+	assert.Equal(t, ":2\r\n", exec("ZLEXCOUNT", "z", "(b", "[d"))
+	assert.Equal(t, ":0\r\n", exec("ZLEXCOUNT", "z", "[e", "(e"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZRANGEBYLEX", "str", "-", "+"))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZLEXCOUNT", "str", "-", "+"))
+	assert.Equal(t, "-ERR min or max not valid string range item\r\n", exec("ZRANGEBYLEX", "z", "a", "+"))
+	assert.Equal(t, "-ERR min or max not valid string range item\r\n", exec("ZLEXCOUNT", "z", "-", "c"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZRANGEBYLEX", "z", "-", "+", "LIMIT", "1"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZREVRANGEBYLEX", "z", "+", "-", "TOP", "1", "2"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZRANGEBYLEX", "z", "-", "+", "LIMIT", "x", "2"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zlexcount' command\r\n", exec("ZLEXCOUNT", "z", "-"))
+}
+
+func TestZREMRANGE(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+	members := func(key string) string {
+		return exec("ZRANGE", key, "0", "-1")
+	}
+
+	assert.Equal(t, ":0\r\n", exec("ZREMRANGEBYRANK", "z", "0", "-1"))
+
+	exec("ZADD", "z", "1", "a", "2", "b", "3", "c", "4", "d", "5", "e")
+	assert.Equal(t, ":2\r\n", exec("ZREMRANGEBYRANK", "z", "-2", "10"))
+	assert.Equal(t, "*3\r\n$1\r\na\r\n$1\r\nb\r\n$1\r\nc\r\n", members("z"))
+	assert.Equal(t, ":0\r\n", exec("ZREMRANGEBYRANK", "z", "2", "1"))
+	assert.Equal(t, ":1\r\n", exec("ZREMRANGEBYRANK", "z", "-10", "0"))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$1\r\nc\r\n", members("z"))
+
+	exec("ZADD", "s", "1", "a", "2", "b", "3", "c", "4", "d", "5", "e")
+	assert.Equal(t, ":2\r\n", exec("ZREMRANGEBYSCORE", "s", "(1", "3"))
+	assert.Equal(t, "*3\r\n$1\r\na\r\n$1\r\nd\r\n$1\r\ne\r\n", members("s"))
+	assert.Equal(t, ":0\r\n", exec("ZREMRANGEBYSCORE", "s", "(4", "(5"))
+	assert.Equal(t, ":2\r\n", exec("ZREMRANGEBYSCORE", "s", "4", "+inf"))
+	assert.Equal(t, "*1\r\n$1\r\na\r\n", members("s"))
+
+	exec("ZADD", "l", "0", "a", "0", "b", "0", "c", "0", "d")
+	assert.Equal(t, ":2\r\n", exec("ZREMRANGEBYLEX", "l", "(a", "[c"))
+	assert.Equal(t, "*2\r\n$1\r\na\r\n$1\r\nd\r\n", members("l"))
+
+	// the key is deleted once the sorted set is empty
+	assert.Equal(t, ":2\r\n", exec("ZREMRANGEBYLEX", "l", "-", "+"))
+	assert.Equal(t, ":1\r\n", exec("ZREMRANGEBYSCORE", "s", "-inf", "+inf"))
+	assert.Equal(t, ":2\r\n", exec("ZREMRANGEBYRANK", "z", "0", "-1"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "l", "s", "z"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZREMRANGEBYSCORE", "str", "0", "1"))
+	assert.Equal(t, "-ERR min or max is not a float\r\n", exec("ZREMRANGEBYSCORE", "z", "(x", "1"))
+	assert.Equal(t, "-ERR min or max is not a float\r\n", exec("ZREMRANGEBYSCORE", "z", "0", "nan"))
+	assert.Equal(t, "-ERR min or max not valid string range item\r\n", exec("ZREMRANGEBYLEX", "z", "a", "+"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZREMRANGEBYRANK", "z", "0", "x"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zremrangebyrank' command\r\n", exec("ZREMRANGEBYRANK", "z", "0"))
+}
+
+func TestZSetOps(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	exec("ZADD", "a", "1", "x", "2", "y", "3", "z")
+	exec("ZADD", "b", "4", "y", "5", "z", "6", "w")
+	exec("SADD", "s", "x", "w")
+
+	assert.Equal(t, "*8\r\n$1\r\nx\r\n$1\r\n1\r\n$1\r\nw\r\n$1\r\n6\r\n$1\r\ny\r\n$1\r\n6\r\n$1\r\nz\r\n$1\r\n8\r\n",
+		exec("ZUNION", "2", "a", "b", "WITHSCORES"))
+	assert.Equal(t, "*4\r\n$1\r\ny\r\n$1\r\n2\r\n$1\r\nz\r\n$1\r\n3\r\n",
+		exec("ZINTER", "2", "a", "b", "AGGREGATE", "MIN", "WITHSCORES"))
+	assert.Equal(t, "*4\r\n$1\r\ny\r\n$2\r\n16\r\n$1\r\nz\r\n$2\r\n21\r\n",
+		exec("ZINTER", "2", "a", "b", "WEIGHTS", "2", "3", "WITHSCORES"))
+	assert.Equal(t, "*1\r\n$1\r\nx\r\n", exec("ZDIFF", "2", "a", "b"))
+	assert.Equal(t, "*0\r\n", exec("ZINTER", "2", "a", "missing"))
+
+	// the members of the sets have a score of 1
+	assert.Equal(t, "*2\r\n$1\r\nx\r\n$1\r\n2\r\n", exec("ZINTER", "2", "a", "s", "WITHSCORES"))
+
+	assert.Equal(t, ":4\r\n", exec("ZUNIONSTORE", "dst", "3", "a", "b", "s", "AGGREGATE", "MAX"))
+	assert.Equal(t, "*8\r\n$1\r\nx\r\n$1\r\n1\r\n$1\r\ny\r\n$1\r\n4\r\n$1\r\nz\r\n$1\r\n5\r\n$1\r\nw\r\n$1\r\n6\r\n",
+		exec("ZRANGE", "dst", "0", "-1", "WITHSCORES"))
+	assert.Equal(t, ":2\r\n", exec("ZINTERSTORE", "dst", "2", "a", "b"))
+	assert.Equal(t, "*2\r\n$1\r\ny\r\n$1\r\nz\r\n", exec("ZRANGE", "dst", "0", "-1"))
+
+	// the destination is deleted when the result is empty
+	assert.Equal(t, ":0\r\n", exec("ZINTERSTORE", "dst", "2", "a", "missing"))
+	assert.Equal(t, ":0\r\n", exec("EXISTS", "dst"))
+
+	// the TTL options of the destination, after the keys
+	assert.Equal(t, ":2\r\n", exec("ZINTERSTORE", "dst", "2", "a", "b", "AGGREGATE", "MIN", "EX", "100"))
+	assert.Equal(t, ":100\r\n", exec("TTL", "dst"))
+	exec("PEXPIRE", "b", "5000")
+	assert.Equal(t, ":4\r\n", exec("ZUNIONSTORE", "dst", "2", "a", "b", "INHERITTTL"))
+	assert.Equal(t, ":5\r\n", exec("TTL", "dst"))
+	assert.Equal(t, ":4\r\n", exec("ZUNIONSTORE", "dst", "2", "a", "b"))
+	assert.Equal(t, ":-1\r\n", exec("TTL", "dst"))
+	exec("PERSIST", "b")
+
+	// keys named like the TTL options are keys
+	exec("ZADD", "EX", "1", "x")
+	assert.Equal(t, ":1\r\n", exec("ZINTERSTORE", "dst", "2", "a", "EX", "PX", "1000"))
+	assert.Equal(t, ":1\r\n", exec("TTL", "dst"))
+
+	// the product of 0 and an infinity is 0
+	exec("ZADD", "inf", "+inf", "x")
+	assert.Equal(t, "*2\r\n$1\r\nx\r\n$1\r\n0\r\n", exec("ZUNION", "1", "inf", "WEIGHTS", "0", "WITHSCORES"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZUNION", "2", "a", "str"))
+	assert.Equal(t, "-ERR at least 1 input key is needed for 'zunionstore' command\r\n", exec("ZUNIONSTORE", "dst", "0", "a"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZINTER", "x", "a"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZINTER", "3", "a", "b"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZINTER", "2", "a", "b", "WEIGHTS", "1"))
+	assert.Equal(t, "-ERR weight value is not a float\r\n", exec("ZINTER", "2", "a", "b", "WEIGHTS", "1", "x"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZINTER", "2", "a", "b", "AGGREGATE", "AVG"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZDIFF", "2", "a", "b", "WEIGHTS", "1", "1"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZINTERSTORE", "dst", "2", "a", "b", "WITHSCORES"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zunionstore' command\r\n", exec("ZUNIONSTORE", "dst", "1"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZUNIONSTORE", "dst", "1", "a", "EX", "10", "INHERITTTL"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZUNIONSTORE", "dst", "1", "a", "PX"))
+	assert.Equal(t, "-ERR invalid expire time in 'zunionstore' command\r\n", exec("ZUNIONSTORE", "dst", "1", "a", "EX", "0"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZUNION", "1", "a", "EX", "10"))
+}
+
+func TestZMSCORE(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	exec("ZADD", "z", "1", "a", "2.5", "b")
+	assert.Equal(t, "*3\r\n$1\r\n1\r\n$-1\r\n$3\r\n2.5\r\n", exec("ZMSCORE", "z", "a", "missing", "b"))
+	assert.Equal(t, "*2\r\n$-1\r\n$-1\r\n", exec("ZMSCORE", "missing", "a", "b"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZMSCORE", "str", "a"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zmscore' command\r\n", exec("ZMSCORE", "z"))
+}
+
+func TestZRANK(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	exec("ZADD", "z", "1", "a", "2", "b", "2", "c", "3.5", "d")
+	assert.Equal(t, ":0\r\n", exec("ZRANK", "z", "a"))
+	assert.Equal(t, ":2\r\n", exec("ZRANK", "z", "c"))
+	assert.Equal(t, ":0\r\n", exec("ZREVRANK", "z", "d"))
+	assert.Equal(t, ":2\r\n", exec("ZREVRANK", "z", "b"))
+	assert.Equal(t, "*2\r\n:3\r\n$3\r\n3.5\r\n", exec("ZRANK", "z", "d", "WITHSCORE"))
+	assert.Equal(t, "*2\r\n:3\r\n$1\r\n1\r\n", exec("ZREVRANK", "z", "a", "withscore"))
+	assert.Equal(t, "$-1\r\n", exec("ZRANK", "z", "missing"))
+	assert.Equal(t, "$-1\r\n", exec("ZREVRANK", "missing", "a", "WITHSCORE"))
+
+	// the ranks follow the changes of the scores
+	exec("ZADD", "z", "0", "d")
+	assert.Equal(t, ":0\r\n", exec("ZRANK", "z", "d"))
+	assert.Equal(t, ":1\r\n", exec("ZRANK", "z", "a"))
+	exec("ZREM", "z", "a")
+	assert.Equal(t, ":2\r\n", exec("ZRANK", "z", "c"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZRANK", "str", "a"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZRANK", "z", "a", "WITHSCORES"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zrevrank' command\r\n", exec("ZREVRANK", "z"))
+}
+
+func TestZRANDMEMBER(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	exec("ZADD", "z", "1", "a")
+	assert.Equal(t, "$1\r\na\r\n", exec("ZRANDMEMBER", "z"))
+	assert.Equal(t, "*2\r\n$1\r\na\r\n$1\r\n1\r\n", exec("ZRANDMEMBER", "z", "5", "WITHSCORES"))
+	assert.Equal(t, "*3\r\n$1\r\na\r\n$1\r\na\r\n$1\r\na\r\n", exec("ZRANDMEMBER", "z", "-3"))
+	assert.Equal(t, "*4\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\na\r\n$1\r\n1\r\n", exec("ZRANDMEMBER", "z", "-2", "withscores"))
+	assert.Equal(t, "*0\r\n", exec("ZRANDMEMBER", "z", "0"))
+
+	// a positive count returns distinct members
+	exec("ZADD", "z", "2", "b", "3", "c")
+	reply := exec("ZRANDMEMBER", "z", "3")
+	for _, member := range []string{"a", "b", "c"} {
+		assert.Assert(t, strings.Contains(reply, "$1\r\n"+member+"\r\n"), reply)
+	}
+
+	assert.Equal(t, "$-1\r\n", exec("ZRANDMEMBER", "missing"))
+	assert.Equal(t, "*0\r\n", exec("ZRANDMEMBER", "missing", "2"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZRANDMEMBER", "str"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZRANDMEMBER", "z", "x"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZRANDMEMBER", "z", "1", "WITHVALUES"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zrandmember' command\r\n", exec("ZRANDMEMBER", "z", "1", "WITHSCORES", "x"))
+}
+
+func TestZRANGEBYSCORE(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	exec("ZADD", "z", "-inf", "n", "1", "a", "1", "b", "1", "c", "2", "d", "3", "e", "+inf", "p")
+	assert.Equal(t, "*3\r\n$1\r\na\r\n$1\r\nb\r\n$1\r\nc\r\n", exec("ZRANGEBYSCORE", "z", "1", "1"))
+	assert.Equal(t, "*2\r\n$1\r\nd\r\n$1\r\ne\r\n", exec("ZRANGEBYSCORE", "z", "(1", "(+inf"))
+	assert.Equal(t, "*4\r\n$1\r\nd\r\n$1\r\n2\r\n$1\r\ne\r\n$1\r\n3\r\n", exec("ZRANGEBYSCORE", "z", "(1", "3", "WITHSCORES"))
+	assert.Equal(t, "*2\r\n$1\r\nn\r\n$1\r\na\r\n", exec("ZRANGEBYSCORE", "z", "-inf", "+inf", "LIMIT", "0", "2"))
+	assert.Equal(t, "*2\r\n$1\r\ne\r\n$1\r\np\r\n", exec("ZRANGEBYSCORE", "z", "-inf", "+inf", "LIMIT", "5", "-1"))
+	assert.Equal(t, "*2\r\n$1\r\nc\r\n$1\r\n1\r\n", exec("ZRANGEBYSCORE", "z", "(-inf", "1", "limit", "2", "1", "withscores"))
+	assert.Equal(t, "*1\r\n$1\r\np\r\n", exec("ZRANGEBYSCORE", "z", "+inf", "+inf"))
+	assert.Equal(t, "*0\r\n", exec("ZRANGEBYSCORE", "z", "(+inf", "+inf"))
+	assert.Equal(t, "*0\r\n", exec("ZRANGEBYSCORE", "z", "3", "1"))
+	assert.Equal(t, "*0\r\n", exec("ZRANGEBYSCORE", "z", "1", "3", "LIMIT", "-1", "2"))
+	assert.Equal(t, "*0\r\n", exec("ZRANGEBYSCORE", "missing", "1", "3"))
+
+	assert.Equal(t, ":7\r\n", exec("ZCOUNT", "z", "-inf", "+inf"))
+	assert.Equal(t, ":4\r\n", exec("ZCOUNT", "z", "1", "(3"))
+	assert.Equal(t, ":1\r\n", exec("ZCOUNT", "z", "(1", "(3"))
+	assert.Equal(t, ":0\r\n", exec("ZCOUNT", "missing", "-inf", "+inf"))
+
+	evalSET([]string{"str", "value"}, store)
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZRANGEBYSCORE", "str", "1", "2"))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("ZCOUNT", "str", "1", "2"))
+	assert.Equal(t, "-ERR min or max is not a float\r\n", exec("ZRANGEBYSCORE", "z", "x", "2"))
+	assert.Equal(t, "-ERR min or max is not a float\r\n", exec("ZCOUNT", "z", "1", "(x"))
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZRANGEBYSCORE", "z", "1", "2", "LIMIT", "1"))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", exec("ZRANGEBYSCORE", "z", "1", "2", "LIMIT", "x", "1"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'zcount' command\r\n", exec("ZCOUNT", "z", "1"))
+}
+
+func TestZSetPayloads(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, ":2\r\n", exec("ZADD", "z", "WITHPAYLOADS", "1", "a", "alice", "2", "b", ""))
+	assert.Equal(t, ":1\r\n", exec("ZADD", "z", "3", "c"))
+	assert.Equal(t, "*6\r\n$1\r\na\r\n$5\r\nalice\r\n$1\r\nb\r\n$0\r\n\r\n$1\r\nc\r\n$0\r\n\r\n",
+		exec("ZRANGE", "z", "0", "-1", "WITHPAYLOADS"))
+	assert.Equal(t, "*3\r\n$1\r\na\r\n$1\r\n1\r\n$5\r\nalice\r\n", exec("ZRANGEBYSCORE", "z", "1", "1", "WITHSCORES", "withpayloads"))
+
+	// the payload is kept when the score changes without WITHPAYLOADS
+	assert.Equal(t, "$1\r\n4\r\n", exec("ZINCRBY", "z", "3", "a"))
+	assert.Equal(t, "*3\r\n$1\r\na\r\n$1\r\n4\r\n$5\r\nalice\r\n", exec("ZRANGE", "z", "-1", "-1", "WITHSCORES", "WITHPAYLOADS"))
+
+	// the payload alone is updated in place, and counted as a change by CH
+	assert.Equal(t, ":1\r\n", exec("ZADD", "z", "CH", "WITHPAYLOADS", "2", "b", "bob"))
+	assert.Equal(t, ":0\r\n", exec("ZADD", "z", "CH", "WITHPAYLOADS", "2", "b", "bob"))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$3\r\nbob\r\n", exec("ZRANGEBYSCORE", "z", "2", "2", "WITHPAYLOADS"))
+	assert.Equal(t, ":1\r\n", exec("ZADD", "z", "CH", "WITHPAYLOADS", "2", "b", ""))
+	assert.Equal(t, "*2\r\n$1\r\nb\r\n$0\r\n\r\n", exec("ZRANGEBYSCORE", "z", "2", "2", "WITHPAYLOADS"))
+
+	// a removed member loses its payload
+	exec("ZREM", "z", "a")
+	exec("ZADD", "z", "1", "a")
+	assert.Equal(t, "*2\r\n$1\r\na\r\n$0\r\n\r\n", exec("ZRANGE", "z", "0", "0", "WITHPAYLOADS"))
+	assert.NilError(t, validateSortedSet(store.Get("z").Value))
+
+	assert.Equal(t, "-ERR syntax error\r\n", exec("ZADD", "z", "WITHPAYLOADS", "1", "a"))
+	assert.Equal(t, "-ERR INCR option supports a single increment-element pair\r\n",
+		exec("ZADD", "z", "INCR", "WITHPAYLOADS", "1", "a", "x", "1", "b", "y"))
+}
+
+// BenchmarkScoreRange measures the ranges of scores at the top of sorted sets,
+// seeking to the minimum against scanning the lower scores from the head.
+func BenchmarkScoreRange(b *testing.B) {
+	for _, n := range []int{1_000, 1_000_000, 10_000_000} {
+		tree := newSkipList()
+		for i := 0; i < n; i++ {
+			tree.Insert(&SortedSetItem{Score: float64(i), Member: strconv.Itoa(i)})
+		}
+		min := scoreBound{score: float64(n - 10)}
+		max := scoreBound{score: math.Inf(1)}
+
+		b.Run(fmt.Sprintf("seek/members_%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scoreRange(tree, min, max, func(*SortedSetItem) bool { return true })
+			}
+		})
+		b.Run(fmt.Sprintf("scan/members_%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tree.Ascend(func(item *SortedSetItem) bool {
+					return !min.above(item.Score) || max.below(item.Score)
+				})
+			}
+		})
+	}
+}
@@ -38,6 +38,7 @@ func evalSET(args []string, store *dstore.Store) *EvalResponse {
 	var exDurationMs int64 = -1
 	var state exDurationState = Uninitialized
 	var keepttl bool = false
+	var nx, xx bool
 
 	key, value = args[0], args[1]
 	oType, oEnc := deduceTypeEncoding(value)
@@ -123,24 +124,9 @@ func evalSET(args []string, store *dstore.Store) *EvalResponse {
 			state = Initialized
 
 		case XX:
-			// Get the key from the hash table
-			obj := store.Get(key)
-
-			// if key does not exist, return RESP encoded nil
-			if obj == nil {
-				return &EvalResponse{
-					Result: clientio.NIL,
-					Error:  nil,
-				}
-			}
+			xx = true
 		case NX:
-			obj := store.Get(key)
-			if obj != nil {
-				return &EvalResponse{
-					Result: clientio.NIL,
-					Error:  nil,
-				}
-			}
+			nx = true
 		case KeepTTL:
 			keepttl = true
 		default:
@@ -151,6 +137,22 @@ func evalSET(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
+	// NX and XX, as well as KEEPTTL and an expiration, exclude each other
+	if (nx && xx) || (keepttl && state != Uninitialized) {
+		return &EvalResponse{
+			Result: nil,
+			Error:  diceerrors.ErrSyntax,
+		}
+	}
+
+	// if the key exists with NX, or doesn't with XX, return RESP encoded nil
+	if (nx || xx) && (store.Get(key) != nil) == nx {
+		return &EvalResponse{
+			Result: clientio.NIL,
+			Error:  nil,
+		}
+	}
+
 	// Cast the value properly based on the encoding type
 	var storedValue interface{}
 	switch oEnc {
@@ -198,6 +200,13 @@ func evalGET(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
+	if !isStringObj(obj) {
+		return &EvalResponse{
+			Result: nil,
+			Error:  diceerrors.ErrWrongTypeOperation,
+		}
+	}
+
 	// Decode and return the value based on its encoding
 	switch _, oEnc := object.ExtractTypeEncoding(obj); oEnc {
 	case object.ObjEncodingInt:
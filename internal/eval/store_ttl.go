@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// storeTTLOpts holds the TTL options accepted by the *STORE command variants
+// (SINTERSTORE, ZUNIONSTORE, ...). They allow the destination key to be written
+// together with its expiry, avoiding the race of a follow-up EXPIRE.
+type storeTTLOpts struct {
+	inherit bool  // destination inherits the smallest TTL among the source keys
+	expMs   int64 // explicit TTL of the destination in milliseconds, -1 when not set
+}
+
+// parseStoreTTLOpts splits the keys of a *STORE variant taking any number of
+// keys, e.g. SINTERSTORE, from the TTL options following them. As a key may be
+// named like an option, the options must be introduced by TTL:
+//
+//	TTL INHERITTTL      - the destination gets the minimum TTL of the source keys
+//	TTL EX seconds      - the destination expires after the given number of seconds
+//	TTL PX milliseconds - the destination expires after the given number of milliseconds
+//
+// The options are mutually exclusive. The arguments are all keys unless the
+// last TTL among them is followed by an option.
+func parseStoreTTLOpts(cmd string, args []string) (keys []string, opts storeTTLOpts, errResp []byte) {
+	opts.expMs = -1
+	sep := -1
+	for i := len(args) - 2; i >= 1; i-- {
+		if strings.EqualFold(args[i], StoreTTL) {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 || !isStoreTTLOpt(args[sep+1]) {
+		return args, opts, nil
+	}
+
+	for i := sep + 1; i < len(args); {
+		next, ok, errResp := parseStoreTTLOpt(cmd, args, i, &opts)
+		if errResp != nil {
+			return nil, opts, errResp
+		}
+		if !ok {
+			return nil, opts, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		i = next
+	}
+	return args[:sep], opts, nil
+}
+
+// isStoreTTLOpt returns true if arg is one of the TTL options of the *STORE
+// variants.
+func isStoreTTLOpt(arg string) bool {
+	switch strings.ToUpper(arg) {
+	case InheritTTL, Ex, Px:
+		return true
+	}
+	return false
+}
+
+// parseStoreTTLOpt parses the TTL option of a *STORE variant at args[i] into
+// opts, see parseStoreTTLOpts, for the commands parsing their options after a
+// known number of keys, e.g. ZUNIONSTORE. It returns the index of the next
+// argument, or false if args[i] is not a TTL option.
+func parseStoreTTLOpt(cmd string, args []string, i int, opts *storeTTLOpts) (next int, ok bool, errResp []byte) {
+	opt := strings.ToUpper(args[i])
+	if !isStoreTTLOpt(opt) {
+		return i, false, nil
+	}
+	// the options are mutually exclusive
+	if opts.inherit || opts.expMs >= 0 {
+		return i, true, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	if opt == InheritTTL {
+		opts.inherit = true
+		return i + 1, true, nil
+	}
+
+	if i+1 >= len(args) {
+		return i, true, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	duration, err := strconv.ParseInt(args[i+1], 10, 64)
+	if err != nil {
+		return i, true, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+	}
+	if duration <= 0 || duration >= maxExDuration {
+		return i, true, diceerrors.NewErrExpireTime(cmd)
+	}
+	if opt == Ex {
+		duration *= 1000
+	}
+	opts.expMs = duration
+	return i + 2, true, nil
+}
+
+// destExpiryMs returns the expiry duration in milliseconds to be applied on the
+// destination key of a *STORE command, or -1 if the destination must not expire.
+// When the TTL is inherited, keys without an expiry are considered to live forever,
+// hence only the source keys having a TTL are taken into account.
+func (opts storeTTLOpts) destExpiryMs(srcKeys []string, store *dstore.Store) int64 {
+	if !opts.inherit {
+		return opts.expMs
+	}
+
+	var minExp uint64
+	found := false
+	for _, key := range srcKeys {
+		obj := store.Get(key)
+		if obj == nil {
+			continue
+		}
+
+		exp, ok := dstore.GetExpiry(obj, store)
+		if !ok {
+			continue
+		}
+
+		if !found || exp < minExp {
+			minExp = exp
+			found = true
+		}
+	}
+
+	if !found {
+		return -1
+	}
+
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	if minExp <= now {
+		return 0
+	}
+
+	return int64(minExp - now)
+}
@@ -0,0 +1,744 @@
+package eval
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/btree"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+const (
+	streamIDMin = "-"
+	streamIDMax = "+"
+	streamIDAny = "*"
+	streamIDNew = "$"
+)
+
+var (
+	errStreamInvalidID  = diceerrors.NewErr("Invalid stream ID specified as stream command argument")
+	errStreamIDTooSmall = diceerrors.NewErr("The ID specified in XADD is equal or smaller than the target stream top item")
+	errStreamIDZero     = diceerrors.NewErr("The ID specified in XADD must be greater than 0-0")
+	errStreamExhausted  = diceerrors.NewErr("The stream has exhausted the last possible ID, unable to add more items")
+)
+
+// StreamID identifies an entry of a stream. It is made of the unix time in
+// milliseconds at which the entry was added and of a sequence number for the
+// entries added within the same millisecond.
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id StreamID) Less(other StreamID) bool {
+	if id.Ms != other.Ms {
+		return id.Ms < other.Ms
+	}
+	return id.Seq < other.Seq
+}
+
+// incr returns the ID following id, ok is false if id is the last possible ID.
+func (id StreamID) incr() (next StreamID, ok bool) {
+	switch {
+	case id.Seq < math.MaxUint64:
+		return StreamID{Ms: id.Ms, Seq: id.Seq + 1}, true
+	case id.Ms < math.MaxUint64:
+		return StreamID{Ms: id.Ms + 1, Seq: 0}, true
+	default:
+		return id, false
+	}
+}
+
+// decr returns the ID preceding id, ok is false if id is 0-0.
+func (id StreamID) decr() (prev StreamID, ok bool) {
+	switch {
+	case id.Seq > 0:
+		return StreamID{Ms: id.Ms, Seq: id.Seq - 1}, true
+	case id.Ms > 0:
+		return StreamID{Ms: id.Ms - 1, Seq: math.MaxUint64}, true
+	default:
+		return id, false
+	}
+}
+
+// parseStreamID parses an ID of the form <ms>-<seq> or <ms>. When the sequence
+// part is missing, missingSeq is used instead.
+func parseStreamID(s string, missingSeq uint64) (StreamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, errStreamInvalidID
+	}
+
+	if !hasSeq {
+		return StreamID{Ms: ms, Seq: missingSeq}, nil
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return StreamID{}, errStreamInvalidID
+	}
+	return StreamID{Ms: ms, Seq: seq}, nil
+}
+
+// StreamEntry is an entry of a stream, holding its field-value pairs.
+type StreamEntry struct {
+	ID     StreamID
+	Fields []string
+}
+
+// Less compares two StreamEntries by ID. Required by the btree.Item interface.
+func (a *StreamEntry) Less(b btree.Item) bool {
+	return a.ID.Less(b.(*StreamEntry).ID)
+}
+
+func (a *StreamEntry) toResp() []interface{} {
+	return []interface{}{a.ID.String(), a.Fields}
+}
+
+// Stream is an append-only log of entries ordered by ID, packed in macro-nodes,
+// see streamNode.
+type Stream struct {
+	nodes  *btree.BTree // nodes are the macro-nodes holding the entries, ordered by master ID
+	length int
+	lastID StreamID
+	groups map[string]*StreamGroup
+}
+
+func NewStream() *Stream {
+	return &Stream{
+		nodes:  btree.New(2),
+		groups: make(map[string]*StreamGroup),
+	}
+}
+
+// nodeOf returns the node that holds the entry with the given ID if it exists,
+// the node with the greatest master ID not greater than id, or nil.
+func (s *Stream) nodeOf(id StreamID) *streamNode {
+	var node *streamNode
+	s.nodes.DescendLessOrEqual(&streamNode{master: id}, func(item btree.Item) bool {
+		node = item.(*streamNode)
+		return false
+	})
+	return node
+}
+
+// entry returns the entry with the given ID, or nil if there is none.
+func (s *Stream) entry(id StreamID) *StreamEntry {
+	node := s.nodeOf(id)
+	if node == nil || node.lastID.Less(id) {
+		return nil
+	}
+	entries := node.entries(id, id)
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries[0]
+}
+
+// lastEntryID returns the ID of the last entry of the stream, false if the
+// stream is empty.
+func (s *Stream) lastEntryID() (StreamID, bool) {
+	if s.nodes.Len() == 0 {
+		return StreamID{}, false
+	}
+	return s.nodes.Max().(*streamNode).lastID, true
+}
+
+// Len returns the number of entries of the stream.
+func (s *Stream) Len() int {
+	return s.length
+}
+
+// LastID returns the ID of the last entry ever added to the stream.
+func (s *Stream) LastID() StreamID {
+	return s.lastID
+}
+
+// Add appends a new entry to the stream. The ID must be greater than the last ID.
+func (s *Stream) Add(id StreamID, fields []string) {
+	var tail *streamNode
+	if s.nodes.Len() > 0 {
+		tail = s.nodes.Max().(*streamNode)
+	}
+	if tail == nil || tail.full() {
+		tail = &streamNode{master: id}
+		s.nodes.ReplaceOrInsert(tail)
+	}
+	tail.add(id, fields)
+	s.length++
+	s.lastID = id
+}
+
+// NextID returns the ID to be used for a new entry given the ID argument of XADD,
+// which is either `*`, `<ms>-*` or an explicit ID.
+func (s *Stream) NextID(arg string) (StreamID, error) {
+	if arg == streamIDAny {
+		ms := max(uint64(utils.GetCurrentTime().UnixMilli()), s.lastID.Ms)
+		if ms > s.lastID.Ms {
+			return StreamID{Ms: ms}, nil
+		}
+		next, ok := s.lastID.incr()
+		if !ok {
+			return StreamID{}, errStreamExhausted
+		}
+		return next, nil
+	}
+
+	if msPart, found := strings.CutSuffix(arg, "-*"); found {
+		ms, err := strconv.ParseUint(msPart, 10, 64)
+		if err != nil {
+			return StreamID{}, errStreamInvalidID
+		}
+		switch {
+		case ms < s.lastID.Ms:
+			return StreamID{}, errStreamIDTooSmall
+		case ms > s.lastID.Ms:
+			return StreamID{Ms: ms}, nil
+		case s.lastID.Seq == math.MaxUint64:
+			return StreamID{}, errStreamIDTooSmall
+		default:
+			return StreamID{Ms: ms, Seq: s.lastID.Seq + 1}, nil
+		}
+	}
+
+	id, err := parseStreamID(arg, 0)
+	if err != nil {
+		return StreamID{}, err
+	}
+	if id == (StreamID{}) {
+		return StreamID{}, errStreamIDZero
+	}
+	if !s.lastID.Less(id) {
+		return StreamID{}, errStreamIDTooSmall
+	}
+	return id, nil
+}
+
+// Range returns up to count entries (all of them if count is negative) whose ID
+// is within [start, end], from the lowest ID or from the highest ID when rev is set.
+func (s *Stream) Range(start, end StreamID, count int, rev bool) []*StreamEntry {
+	entries := make([]*StreamEntry, 0)
+	if count == 0 || end.Less(start) {
+		return entries
+	}
+
+	iter := func(item btree.Item) bool {
+		node := item.(*streamNode)
+		if rev && node.lastID.Less(start) || !rev && end.Less(node.master) {
+			return false
+		}
+		inRange := node.entries(start, end)
+		for i := range inRange {
+			if count >= 0 && len(entries) == count {
+				break
+			}
+			if rev {
+				i = len(inRange) - 1 - i
+			}
+			entries = append(entries, inRange[i])
+		}
+		return count < 0 || len(entries) < count
+	}
+
+	if rev {
+		s.nodes.DescendLessOrEqual(&streamNode{master: end}, iter)
+		return entries
+	}
+	if first := s.nodeOf(start); first != nil {
+		s.nodes.AscendGreaterOrEqual(first, iter)
+	} else {
+		s.nodes.Ascend(iter)
+	}
+	return entries
+}
+
+// trimNodes evicts the oldest nodes as long as drop returns true for them, and
+// evicting them keeps the number of evicted entries within limit, 0 meaning no
+// limit. It returns the number of evicted entries.
+func (s *Stream) trimNodes(drop func(node *streamNode) bool, limit int) int {
+	trimmed := 0
+	for s.nodes.Len() > 0 {
+		node := s.nodes.Min().(*streamNode)
+		if !drop(node) || limit > 0 && trimmed+node.count > limit {
+			break
+		}
+		s.nodes.DeleteMin()
+		s.length -= node.count
+		trimmed += node.count
+	}
+	return trimmed
+}
+
+// trimHead evicts up to max entries with an ID lower than minID from the first
+// node, which is never left empty as the whole nodes are evicted first.
+func (s *Stream) trimHead(minID StreamID, max int) int {
+	if s.nodes.Len() == 0 || max <= 0 {
+		return 0
+	}
+	trimmed := s.nodes.Min().(*streamNode).trimHead(minID, max)
+	s.length -= trimmed
+	return trimmed
+}
+
+// TrimMaxLen evicts the oldest entries so that the stream holds at most maxLen
+// entries, and returns the number of evicted entries.
+func (s *Stream) TrimMaxLen(maxLen int) int {
+	trimmed := s.trimNodes(func(node *streamNode) bool {
+		return s.length-node.count >= maxLen
+	}, 0)
+	return trimmed + s.trimHead(streamIDMaxValue, s.length-maxLen)
+}
+
+// TrimMinID evicts the entries whose ID is lower than minID, and returns the
+// number of evicted entries.
+func (s *Stream) TrimMinID(minID StreamID) int {
+	trimmed := s.trimNodes(func(node *streamNode) bool {
+		return node.lastID.Less(minID)
+	}, 0)
+	return trimmed + s.trimHead(minID, s.length)
+}
+
+// TrimMaxLenApprox evicts the oldest nodes as long as the stream is left with
+// at least maxLen entries, and at most limit entries are evicted, 0 meaning no
+// limit. The stream may hence hold more than maxLen entries afterwards. It
+// returns the number of evicted entries.
+func (s *Stream) TrimMaxLenApprox(maxLen, limit int) int {
+	return s.trimNodes(func(node *streamNode) bool {
+		return s.length-node.count >= maxLen
+	}, limit)
+}
+
+// TrimMinIDApprox evicts the oldest nodes whose entries all have an ID lower
+// than minID, at most limit entries being evicted, 0 meaning no limit. It
+// returns the number of evicted entries.
+func (s *Stream) TrimMinIDApprox(minID StreamID, limit int) int {
+	return s.trimNodes(func(node *streamNode) bool {
+		return node.lastID.Less(minID)
+	}, limit)
+}
+
+// getStream returns the stream stored at key, nil if the key does not exist,
+// or an encoded error if the key holds another type.
+func getStream(key string, store *dstore.Store) (*Stream, []byte) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, nil
+	}
+
+	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeStream); err != nil {
+		return nil, diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr)
+	}
+	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingStream); err != nil {
+		return nil, diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr)
+	}
+
+	return obj.Value.(*Stream), nil
+}
+
+func encodeStreamEntries(entries []*StreamEntry) []interface{} {
+	resp := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		resp[i] = entry.toResp()
+	}
+	return resp
+}
+
+// streamTrimOpts holds the trimming strategy of XADD and XTRIM.
+type streamTrimOpts struct {
+	strategy string // MAXLEN or MINID, empty when no trimming is requested
+	maxLen   int
+	minID    StreamID
+	approx   bool // approx is set by the `~` operator, only whole nodes being evicted then
+	limit    int  // limit is the maximum number of entries evicted by an approximate trimming, 0 for no limit
+}
+
+// parseStreamTrimOpts parses the threshold of the MAXLEN and MINID trimming
+// strategies, optionally prefixed by the `=` or `~` operators and followed by
+// LIMIT count, and returns the number of consumed arguments.
+func parseStreamTrimOpts(strategy string, args []string) (opts streamTrimOpts, consumed int, errResp []byte) {
+	if len(args) > 0 && (args[0] == "=" || args[0] == "~") {
+		opts.approx = args[0] == "~"
+		args = args[1:]
+		consumed++
+	}
+	if len(args) == 0 {
+		return opts, 0, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	consumed++
+
+	opts.strategy = strategy
+	if strategy == MaxLen {
+		maxLen, err := strconv.Atoi(args[0])
+		if err != nil {
+			return opts, 0, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if maxLen < 0 {
+			return opts, 0, diceerrors.NewErrWithMessage("The MAXLEN argument must be >= 0.")
+		}
+		opts.maxLen = maxLen
+	} else {
+		minID, err := parseStreamID(args[0], 0)
+		if err != nil {
+			return opts, 0, diceerrors.NewErrWithMessage(err.Error())
+		}
+		opts.minID = minID
+	}
+
+	if opts.approx {
+		opts.limit = 100 * streamNodeMaxEntries
+	}
+	if len(args) > 2 && strings.EqualFold(args[1], Limit) {
+		if !opts.approx {
+			return opts, 0, diceerrors.NewErrWithMessage("syntax error, LIMIT cannot be used without the special ~ option")
+		}
+		limit, err := strconv.Atoi(args[2])
+		if err != nil || limit < 0 {
+			return opts, 0, diceerrors.NewErrWithMessage("The LIMIT argument must be >= 0.")
+		}
+		opts.limit = limit
+		consumed += 2
+	}
+	return opts, consumed, nil
+}
+
+func (opts streamTrimOpts) trim(s *Stream) int {
+	switch {
+	case opts.strategy == MaxLen && opts.approx:
+		return s.TrimMaxLenApprox(opts.maxLen, opts.limit)
+	case opts.strategy == MaxLen:
+		return s.TrimMaxLen(opts.maxLen)
+	case opts.strategy == MinID && opts.approx:
+		return s.TrimMinIDApprox(opts.minID, opts.limit)
+	case opts.strategy == MinID:
+		return s.TrimMinID(opts.minID)
+	default:
+		return 0
+	}
+}
+
+// evalXADD appends a new entry to the stream stored at key, creating the stream
+// if it does not exist, and returns the ID of the added entry.
+//
+// Usage: XADD key [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id field value [field value ...]
+//
+// The ID can be `*` to let the server generate it, `<ms>-*` to let the server
+// generate the sequence part only, or an explicit ID greater than the last ID
+// of the stream. With NOMKSTREAM, nil is returned if the stream does not exist.
+func evalXADD(args []string, store *dstore.Store) []byte {
+	if len(args) < 4 {
+		return diceerrors.NewErrArity("XADD")
+	}
+
+	key := args[0]
+	i, noMkStream, trimOpts, errResp := parseXADDOpts(args)
+	if errResp != nil {
+		return errResp
+	}
+
+	fields := args[min(i+1, len(args)):]
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return diceerrors.NewErrArity("XADD")
+	}
+
+	stream, errResp := getStream(key, store)
+	if errResp != nil {
+		return errResp
+	}
+
+	isNew := stream == nil
+	if isNew {
+		if noMkStream {
+			return clientio.RespNIL
+		}
+		stream = NewStream()
+	}
+
+	id, err := stream.NextID(args[i])
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	stream.Add(id, append([]string(nil), fields...))
+	trimOpts.trim(stream)
+
+	if isNew {
+		store.Put(key, store.NewObj(stream, -1, object.ObjTypeStream, object.ObjEncodingStream))
+	}
+
+	return clientio.Encode(id.String(), false)
+}
+
+// parseXADDOpts parses the options of XADD key [NOMKSTREAM] [MAXLEN|MINID ...]
+// and returns the index of the ID in args, which follows them.
+func parseXADDOpts(args []string) (i int, noMkStream bool, trimOpts streamTrimOpts, errResp []byte) {
+	for i = 1; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		if opt == NoMkStream {
+			noMkStream = true
+			continue
+		}
+		if opt != MaxLen && opt != MinID {
+			break
+		}
+
+		opts, consumed, errResp := parseStreamTrimOpts(opt, args[i+1:])
+		if errResp != nil {
+			return 0, false, trimOpts, errResp
+		}
+		trimOpts = opts
+		i += consumed
+	}
+	return i, noMkStream, trimOpts, nil
+}
+
+// evalXLEN returns the number of entries of the stream stored at key, or 0 if
+// the key does not exist.
+func evalXLEN(args []string, store *dstore.Store) []byte {
+	if len(args) != 1 {
+		return diceerrors.NewErrArity("XLEN")
+	}
+
+	stream, errResp := getStream(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+	if stream == nil {
+		return clientio.RespZero
+	}
+
+	return clientio.Encode(stream.Len(), false)
+}
+
+// evalXTRIM evicts the oldest entries of the stream stored at key and returns
+// the number of evicted entries. MAXLEN keeps the threshold last entries and
+// MINID the entries whose ID is not lower than the threshold. With `~`, only
+// the whole nodes of the stream are evicted, which is much cheaper, the stream
+// possibly keeping a few more entries, and at most count entries are evicted
+// when LIMIT is given, 0 meaning no limit.
+//
+// Usage: XTRIM key MAXLEN|MINID [=|~] threshold [LIMIT count]
+func evalXTRIM(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("XTRIM")
+	}
+
+	strategy := strings.ToUpper(args[1])
+	if strategy != MaxLen && strategy != MinID {
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	opts, consumed, errResp := parseStreamTrimOpts(strategy, args[2:])
+	if errResp != nil {
+		return errResp
+	}
+	if 2+consumed != len(args) {
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+
+	stream, errResp := getStream(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+	if stream == nil {
+		return clientio.RespZero
+	}
+	return clientio.Encode(opts.trim(stream), false)
+}
+
+// parseStreamRangeBound parses a bound of XRANGE/XREVRANGE. It supports the `-`
+// and `+` special IDs, incomplete IDs, and exclusive bounds prefixed by `(`.
+func parseStreamRangeBound(s string, isStart bool) (id StreamID, ok bool, err error) {
+	switch s {
+	case streamIDMin:
+		return StreamID{}, true, nil
+	case streamIDMax:
+		return StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}, true, nil
+	}
+
+	exclusive := strings.HasPrefix(s, "(")
+	if exclusive {
+		s = s[1:]
+	}
+
+	missingSeq := uint64(0)
+	if !isStart {
+		missingSeq = math.MaxUint64
+	}
+	id, err = parseStreamID(s, missingSeq)
+	if err != nil {
+		return id, false, err
+	}
+
+	if !exclusive {
+		return id, true, nil
+	}
+	// an exclusive bound on the first or last possible ID matches nothing
+	if isStart {
+		id, ok = id.incr()
+	} else {
+		id, ok = id.decr()
+	}
+	return id, ok, nil
+}
+
+func evalStreamRange(cmd string, args []string, store *dstore.Store, rev bool) []byte {
+	if len(args) != 3 && len(args) != 5 {
+		return diceerrors.NewErrArity(cmd)
+	}
+
+	startArg, endArg := args[1], args[2]
+	if rev {
+		startArg, endArg = endArg, startArg
+	}
+
+	start, startOK, err := parseStreamRangeBound(startArg, true)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	end, endOK, err := parseStreamRangeBound(endArg, false)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+
+	count := -1
+	if len(args) == 5 {
+		if strings.ToUpper(args[3]) != Count {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		if count, err = strconv.Atoi(args[4]); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		count = max(count, 0)
+	}
+
+	stream, errResp := getStream(args[0], store)
+	if errResp != nil {
+		return errResp
+	}
+	if stream == nil || !startOK || !endOK {
+		return clientio.RespEmptyArray
+	}
+
+	return clientio.Encode(encodeStreamEntries(stream.Range(start, end, count, rev)), false)
+}
+
+// evalXRANGE returns the entries of the stream stored at key whose ID is within
+// the given range, from the lowest ID to the highest.
+//
+// Usage: XRANGE key start end [COUNT count]
+//
+// `-` and `+` stand for the lowest and highest possible IDs, a missing sequence
+// part defaults to 0 for start and to the highest sequence for end, and a bound
+// prefixed by `(` is exclusive.
+func evalXRANGE(args []string, store *dstore.Store) []byte {
+	return evalStreamRange("XRANGE", args, store, false)
+}
+
+// evalXREVRANGE is like XRANGE but returns the entries from the highest ID to the
+// lowest, hence the range is specified as end then start.
+//
+// Usage: XREVRANGE key end start [COUNT count]
+func evalXREVRANGE(args []string, store *dstore.Store) []byte {
+	return evalStreamRange("XREVRANGE", args, store, true)
+}
+
+// evalXREAD returns the entries with an ID greater than the given one for each
+// of the given streams. The ID `$` stands for the last ID of the stream.
+// Only the streams having new entries are part of the reply, nil is returned
+// if none of them has.
+//
+// Usage: XREAD [COUNT count] STREAMS key [key ...] id [id ...]
+//
+// Blocking reads are not supported yet, hence BLOCK is rejected.
+func evalXREAD(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("XREAD")
+	}
+
+	count := -1
+	i := 0
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case Count:
+			if i+1 >= len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			c, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			if c > 0 {
+				count = c
+			}
+			i++
+			continue
+		case Block:
+			return diceerrors.NewErrWithMessage("BLOCK option is not supported for 'xread' command")
+		case Streams:
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		break
+	}
+
+	streamArgs := args[min(i+1, len(args)):]
+	if len(streamArgs) == 0 || len(streamArgs)%2 != 0 {
+		return diceerrors.NewErrWithMessage("Unbalanced 'xread' list of streams: for each stream key an ID or '$' must be specified.")
+	}
+
+	keys, ids := streamArgs[:len(streamArgs)/2], streamArgs[len(streamArgs)/2:]
+	streams := make([]*Stream, len(keys))
+	after := make([]StreamID, len(keys))
+	for j, key := range keys {
+		stream, errResp := getStream(key, store)
+		if errResp != nil {
+			return errResp
+		}
+		streams[j] = stream
+
+		if ids[j] == streamIDNew {
+			if stream != nil {
+				after[j] = stream.LastID()
+			}
+			continue
+		}
+
+		id, err := parseStreamID(ids[j], 0)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		after[j] = id
+	}
+
+	resp := make([]interface{}, 0, len(keys))
+	for j, stream := range streams {
+		if stream == nil {
+			continue
+		}
+
+		start, ok := after[j].incr()
+		if !ok {
+			continue
+		}
+
+		entries := stream.Range(start, StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}, count, false)
+		if len(entries) > 0 {
+			resp = append(resp, []interface{}{keys[j], encodeStreamEntries(entries)})
+		}
+	}
+
+	if len(resp) == 0 {
+		return clientio.RespNIL
+	}
+	return clientio.Encode(resp, false)
+}
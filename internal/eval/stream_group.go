@@ -0,0 +1,844 @@
+package eval
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/btree"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// Consumer groups let several consumers cooperatively consume a stream, every
+// entry being delivered to a single consumer of the group. A group keeps track
+// of the ID of the last delivered entry and of its pending entries list (PEL),
+// i.e. the entries delivered but not acknowledged yet, along with the consumer
+// owning them, the time of their last delivery and their number of deliveries.
+
+const (
+	streamIDUndelivered = ">"
+
+	// xautoclaimDefaultCount is the number of entries claimed by XAUTOCLAIM when COUNT is not given.
+	xautoclaimDefaultCount = 100
+	// xautoclaimAttemptsFactor bounds the number of pending entries XAUTOCLAIM
+	// scans, as a multiple of COUNT, so that a huge PEL does not stall the server.
+	xautoclaimAttemptsFactor = 10
+)
+
+var streamIDMaxValue = StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+
+// StreamPendingEntry is an entry of the pending entries list of a consumer group.
+type StreamPendingEntry struct {
+	ID          StreamID
+	Consumer    string
+	DeliveredAt int64 // unix time in milliseconds of the last delivery
+	Deliveries  int64
+}
+
+// Less compares two StreamPendingEntries by ID. Required by the btree.Item interface.
+func (a *StreamPendingEntry) Less(b btree.Item) bool {
+	return a.ID.Less(b.(*StreamPendingEntry).ID)
+}
+
+// StreamConsumer is a consumer of a consumer group.
+type StreamConsumer struct {
+	Name    string
+	SeenAt  int64 // unix time in milliseconds of the last interaction
+	Pending int   // number of pending entries owned by the consumer
+}
+
+// StreamGroup is a consumer group of a stream.
+type StreamGroup struct {
+	Name            string
+	LastDeliveredID StreamID
+	pel             *btree.BTree
+	consumers       map[string]*StreamConsumer
+}
+
+func newStreamGroup(name string, lastDeliveredID StreamID) *StreamGroup {
+	return &StreamGroup{
+		Name:            name,
+		LastDeliveredID: lastDeliveredID,
+		pel:             btree.New(2),
+		consumers:       make(map[string]*StreamConsumer),
+	}
+}
+
+// Group returns the consumer group with the given name, or nil if there is none.
+func (s *Stream) Group(name string) *StreamGroup {
+	return s.groups[name]
+}
+
+// CreateGroup creates a consumer group delivering the entries following
+// lastDeliveredID. It returns false if the group already exists.
+func (s *Stream) CreateGroup(name string, lastDeliveredID StreamID) bool {
+	if _, ok := s.groups[name]; ok {
+		return false
+	}
+	s.groups[name] = newStreamGroup(name, lastDeliveredID)
+	return true
+}
+
+// DestroyGroup deletes a consumer group, it returns false if there is no such group.
+func (s *Stream) DestroyGroup(name string) bool {
+	if _, ok := s.groups[name]; !ok {
+		return false
+	}
+	delete(s.groups, name)
+	return true
+}
+
+// Consumer returns the consumer with the given name, or nil if there is none.
+func (g *StreamGroup) Consumer(name string) *StreamConsumer {
+	return g.consumers[name]
+}
+
+// createConsumer returns the consumer with the given name, creating it if needed,
+// and updates the time it was last seen. created is set if the consumer is new.
+func (g *StreamGroup) createConsumer(name string, now int64) (c *StreamConsumer, created bool) {
+	c, ok := g.consumers[name]
+	if !ok {
+		c = &StreamConsumer{Name: name}
+		g.consumers[name] = c
+	}
+	c.SeenAt = now
+	return c, !ok
+}
+
+// DeleteConsumer deletes a consumer along with its pending entries, and returns
+// the number of pending entries it owned.
+func (g *StreamGroup) DeleteConsumer(name string) int {
+	c, ok := g.consumers[name]
+	if !ok {
+		return 0
+	}
+
+	var owned []btree.Item
+	g.pel.Ascend(func(item btree.Item) bool {
+		if item.(*StreamPendingEntry).Consumer == name {
+			owned = append(owned, item)
+		}
+		return true
+	})
+	for _, item := range owned {
+		g.pel.Delete(item)
+	}
+
+	delete(g.consumers, name)
+	return c.Pending
+}
+
+// PendingLen returns the number of entries delivered and not acknowledged yet.
+func (g *StreamGroup) PendingLen() int {
+	return g.pel.Len()
+}
+
+func (g *StreamGroup) pending(id StreamID) *StreamPendingEntry {
+	item := g.pel.Get(&StreamPendingEntry{ID: id})
+	if item == nil {
+		return nil
+	}
+	return item.(*StreamPendingEntry)
+}
+
+// deliver records the delivery of the entry to the consumer, transferring the
+// ownership of the pending entry if it was delivered to another consumer before.
+func (g *StreamGroup) deliver(id StreamID, c *StreamConsumer, now int64) *StreamPendingEntry {
+	pe := g.pending(id)
+	if pe == nil {
+		pe = &StreamPendingEntry{ID: id, Consumer: c.Name}
+		g.pel.ReplaceOrInsert(pe)
+		c.Pending++
+	} else {
+		g.transfer(pe, c)
+	}
+
+	pe.DeliveredAt = now
+	pe.Deliveries++
+	return pe
+}
+
+func (g *StreamGroup) transfer(pe *StreamPendingEntry, c *StreamConsumer) {
+	if pe.Consumer == c.Name {
+		return
+	}
+	if prev, ok := g.consumers[pe.Consumer]; ok {
+		prev.Pending--
+	}
+	pe.Consumer = c.Name
+	c.Pending++
+}
+
+// Ack removes the entry from the pending entries list, it returns false if the
+// entry was not pending.
+func (g *StreamGroup) Ack(id StreamID) bool {
+	item := g.pel.Delete(&StreamPendingEntry{ID: id})
+	if item == nil {
+		return false
+	}
+	if c, ok := g.consumers[item.(*StreamPendingEntry).Consumer]; ok {
+		c.Pending--
+	}
+	return true
+}
+
+// PendingRange returns up to count pending entries whose ID is within [start, end],
+// optionally restricted to the entries of a consumer (when consumer is not empty)
+// and to the entries idle for at least minIdle milliseconds.
+func (g *StreamGroup) PendingRange(start, end StreamID, count int, consumer string, minIdle, now int64) []*StreamPendingEntry {
+	entries := make([]*StreamPendingEntry, 0)
+	if count <= 0 || end.Less(start) {
+		return entries
+	}
+
+	g.pel.AscendGreaterOrEqual(&StreamPendingEntry{ID: start}, func(item btree.Item) bool {
+		pe := item.(*StreamPendingEntry)
+		if end.Less(pe.ID) {
+			return false
+		}
+		if (consumer == "" || pe.Consumer == consumer) && now-pe.DeliveredAt >= minIdle {
+			entries = append(entries, pe)
+		}
+		return len(entries) < count
+	})
+	return entries
+}
+
+// getStreamGroup returns the stream stored at key along with the consumer group.
+// Both are nil if the key does not exist or if there is no such group.
+func getStreamGroup(key, group string, store *dstore.Store) (*Stream, *StreamGroup, []byte) {
+	stream, errResp := getStream(key, store)
+	if errResp != nil || stream == nil {
+		return nil, nil, errResp
+	}
+	return stream, stream.Group(group), nil
+}
+
+func errStreamNoGroup(key, group string) []byte {
+	return diceerrors.NewErrWithFormattedMessage("-NOGROUP No such key '%s' or consumer group '%s'", key, group)
+}
+
+// parseGroupStartID parses the ID of XGROUP CREATE and XGROUP SETID, `$`
+// standing for the last ID of the stream.
+func parseGroupStartID(arg string, stream *Stream) (StreamID, error) {
+	if arg == streamIDNew {
+		if stream == nil {
+			return StreamID{}, nil
+		}
+		return stream.LastID(), nil
+	}
+	return parseStreamID(arg, 0)
+}
+
+// evalXGROUP manages the consumer groups of a stream.
+//
+// Usage:
+//
+//	XGROUP CREATE key group id|$ [MKSTREAM]
+//	XGROUP SETID key group id|$
+//	XGROUP DESTROY key group
+//	XGROUP CREATECONSUMER key group consumer
+//	XGROUP DELCONSUMER key group consumer
+//
+// CREATE returns a BUSYGROUP error if the group already exists, and requires the
+// stream to exist unless MKSTREAM is given. DESTROY returns 1 if the group was
+// deleted, CREATECONSUMER returns 1 if the consumer was created and DELCONSUMER
+// returns the number of pending entries the deleted consumer owned.
+func evalXGROUP(args []string, store *dstore.Store) []byte {
+	if len(args) == 1 && strings.EqualFold(args[0], Help) {
+		return commandHelp("XGROUP")
+	}
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("XGROUP")
+	}
+
+	subcommand := strings.ToUpper(args[0])
+	key, group := args[1], args[2]
+
+	switch subcommand {
+	case Create:
+		if len(args) != 4 && len(args) != 5 {
+			return diceerrors.NewErrArity("XGROUP|CREATE")
+		}
+		if len(args) == 5 && strings.ToUpper(args[4]) != MkStream {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	case SetID, CreateCons, DelCons:
+		if len(args) != 4 {
+			return diceerrors.NewErrArity("XGROUP|" + subcommand)
+		}
+	case Destroy:
+		if len(args) != 3 {
+			return diceerrors.NewErrArity("XGROUP|DESTROY")
+		}
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try XGROUP HELP.", args[0])
+	}
+
+	stream, errResp := getStream(key, store)
+	if errResp != nil {
+		return errResp
+	}
+	if stream == nil {
+		if subcommand != Create || len(args) != 5 {
+			return diceerrors.NewErrWithMessage("The XGROUP subcommand requires the key to exist. " +
+				"Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.")
+		}
+		stream = NewStream()
+		store.Put(key, store.NewObj(stream, -1, object.ObjTypeStream, object.ObjEncodingStream))
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	switch subcommand {
+	case Create, SetID:
+		id, err := parseGroupStartID(args[3], stream)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		if subcommand == Create {
+			if !stream.CreateGroup(group, id) {
+				return diceerrors.NewErrWithMessage("-BUSYGROUP Consumer Group name already exists")
+			}
+			return clientio.RespOK
+		}
+
+		g := stream.Group(group)
+		if g == nil {
+			return errStreamNoGroup(key, group)
+		}
+		g.LastDeliveredID = id
+		return clientio.RespOK
+	case Destroy:
+		if stream.DestroyGroup(group) {
+			return clientio.RespOne
+		}
+		return clientio.RespZero
+	case CreateCons:
+		g := stream.Group(group)
+		if g == nil {
+			return errStreamNoGroup(key, group)
+		}
+		if _, created := g.createConsumer(args[3], now); created {
+			return clientio.RespOne
+		}
+		return clientio.RespZero
+	default: // DELCONSUMER
+		g := stream.Group(group)
+		if g == nil {
+			return errStreamNoGroup(key, group)
+		}
+		return clientio.Encode(g.DeleteConsumer(args[3]), false)
+	}
+}
+
+// evalXREADGROUP reads the entries of streams on behalf of a consumer of a group.
+//
+// Usage: XREADGROUP GROUP group consumer [COUNT count] [NOACK] STREAMS key [key ...] id [id ...]
+//
+// The ID `>` delivers the entries never delivered to any consumer of the group,
+// which are added to the pending entries list of the consumer unless NOACK is
+// given. Any other ID replays the history of the consumer, i.e. its pending
+// entries with a greater ID, an entry deleted from the stream in the meantime
+// being reported with a nil value. Nil is returned when there is nothing to deliver.
+//
+// Blocking reads are not supported yet, hence BLOCK is rejected.
+func evalXREADGROUP(args []string, store *dstore.Store) []byte {
+	if len(args) < 6 {
+		return diceerrors.NewErrArity("XREADGROUP")
+	}
+	if strings.ToUpper(args[0]) != Group {
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+	group, consumer := args[1], args[2]
+
+	count := -1
+	noAck := false
+	i := 3
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case Count:
+			if i+1 >= len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			c, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			if c > 0 {
+				count = c
+			}
+			i++
+			continue
+		case NoAck:
+			noAck = true
+			continue
+		case Block:
+			return diceerrors.NewErrWithMessage("BLOCK option is not supported for 'xreadgroup' command")
+		case Streams:
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		break
+	}
+
+	streamArgs := args[min(i+1, len(args)):]
+	if len(streamArgs) == 0 || len(streamArgs)%2 != 0 {
+		return diceerrors.NewErrWithMessage("Unbalanced 'xreadgroup' list of streams: for each stream key an ID or '>' must be specified.")
+	}
+
+	keys, ids := streamArgs[:len(streamArgs)/2], streamArgs[len(streamArgs)/2:]
+	streams := make([]*Stream, len(keys))
+	groups := make([]*StreamGroup, len(keys))
+	after := make([]StreamID, len(keys))
+	for j, key := range keys {
+		stream, g, errResp := getStreamGroup(key, group, store)
+		if errResp != nil {
+			return errResp
+		}
+		if g == nil {
+			return diceerrors.NewErrWithFormattedMessage(
+				"-NOGROUP No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option", key, group)
+		}
+		streams[j], groups[j] = stream, g
+
+		if ids[j] == streamIDUndelivered {
+			continue
+		}
+		id, err := parseStreamID(ids[j], 0)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		after[j] = id
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	resp := make([]interface{}, 0, len(keys))
+	for j, stream := range streams {
+		g := groups[j]
+		c, _ := g.createConsumer(consumer, now)
+
+		if ids[j] != streamIDUndelivered {
+			resp = append(resp, []interface{}{keys[j], readConsumerHistory(stream, g, c, after[j], count, now)})
+			continue
+		}
+
+		start, ok := g.LastDeliveredID.incr()
+		if !ok {
+			continue
+		}
+		entries := stream.Range(start, streamIDMaxValue, count, false)
+		if len(entries) == 0 {
+			continue
+		}
+		for _, entry := range entries {
+			g.LastDeliveredID = entry.ID
+			if !noAck {
+				g.deliver(entry.ID, c, now)
+			}
+		}
+		resp = append(resp, []interface{}{keys[j], encodeStreamEntries(entries)})
+	}
+
+	if len(resp) == 0 {
+		return clientio.RespNIL
+	}
+	return clientio.Encode(resp, false)
+}
+
+// readConsumerHistory returns the pending entries of the consumer whose ID is
+// greater than after, counting them as delivered once more.
+func readConsumerHistory(stream *Stream, g *StreamGroup, c *StreamConsumer, after StreamID, count int, now int64) []interface{} {
+	start, ok := after.incr()
+	if !ok {
+		return []interface{}{}
+	}
+	if count < 0 {
+		count = math.MaxInt
+	}
+
+	pending := g.PendingRange(start, streamIDMaxValue, count, c.Name, 0, now)
+	resp := make([]interface{}, len(pending))
+	for k, pe := range pending {
+		g.deliver(pe.ID, c, now)
+		if entry := stream.entry(pe.ID); entry != nil {
+			resp[k] = entry.toResp()
+		} else {
+			resp[k] = []interface{}{pe.ID.String(), nil}
+		}
+	}
+	return resp
+}
+
+// evalXACK acknowledges the given entries, removing them from the pending
+// entries list of the group, and returns the number of acknowledged entries.
+//
+// Usage: XACK key group id [id ...]
+func evalXACK(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("XACK")
+	}
+
+	ids := make([]StreamID, len(args)-2)
+	for j, arg := range args[2:] {
+		id, err := parseStreamID(arg, 0)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		ids[j] = id
+	}
+
+	_, g, errResp := getStreamGroup(args[0], args[1], store)
+	if errResp != nil {
+		return errResp
+	}
+	if g == nil {
+		return clientio.RespZero
+	}
+
+	acked := 0
+	for _, id := range ids {
+		if g.Ack(id) {
+			acked++
+		}
+	}
+	return clientio.Encode(acked, false)
+}
+
+// evalXPENDING inspects the pending entries list of a consumer group.
+//
+// Usage: XPENDING key group [[IDLE min-idle-time] start end count [consumer]]
+//
+// The summary form returns the number of pending entries, the lowest and the
+// highest pending IDs and the number of pending entries per consumer. The
+// extended form returns, for each pending entry within the range, its ID, its
+// consumer, the milliseconds elapsed since its last delivery and its number of deliveries.
+func evalXPENDING(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("XPENDING")
+	}
+	key, group := args[0], args[1]
+
+	extended := len(args) > 2
+	var minIdle int64
+	var start, end StreamID
+	startOK, endOK := true, true
+	count := 0
+	consumer := ""
+	if extended {
+		opts := args[2:]
+		if strings.ToUpper(opts[0]) == Idle {
+			if len(opts) < 2 {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			var err error
+			if minIdle, err = strconv.ParseInt(opts[1], 10, 64); err != nil {
+				return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+			}
+			opts = opts[2:]
+		}
+		if len(opts) != 3 && len(opts) != 4 {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+
+		var err error
+		if start, startOK, err = parseStreamRangeBound(opts[0], true); err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		if end, endOK, err = parseStreamRangeBound(opts[1], false); err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		if count, err = strconv.Atoi(opts[2]); err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if len(opts) == 4 {
+			consumer = opts[3]
+		}
+	}
+
+	_, g, errResp := getStreamGroup(key, group, store)
+	if errResp != nil {
+		return errResp
+	}
+	if g == nil {
+		return errStreamNoGroup(key, group)
+	}
+
+	if !extended {
+		return encodePendingSummary(g)
+	}
+	if !startOK || !endOK {
+		return clientio.RespEmptyArray
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	pending := g.PendingRange(start, end, count, consumer, minIdle, now)
+	resp := make([]interface{}, len(pending))
+	for k, pe := range pending {
+		resp[k] = []interface{}{pe.ID.String(), pe.Consumer, now - pe.DeliveredAt, pe.Deliveries}
+	}
+	return clientio.Encode(resp, false)
+}
+
+func encodePendingSummary(g *StreamGroup) []byte {
+	if g.PendingLen() == 0 {
+		return clientio.Encode([]interface{}{0, nil, nil, nil}, false)
+	}
+
+	names := make([]string, 0, len(g.consumers))
+	for name, c := range g.consumers {
+		if c.Pending > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	consumers := make([]interface{}, len(names))
+	for k, name := range names {
+		consumers[k] = []interface{}{name, strconv.Itoa(g.consumers[name].Pending)}
+	}
+
+	return clientio.Encode([]interface{}{
+		g.PendingLen(),
+		g.pel.Min().(*StreamPendingEntry).ID.String(),
+		g.pel.Max().(*StreamPendingEntry).ID.String(),
+		consumers,
+	}, false)
+}
+
+// evalXCLAIM transfers the ownership of pending entries idle for at least
+// min-idle-time milliseconds to the given consumer, and returns the claimed entries.
+//
+// Usage: XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-milliseconds]
+// [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID id]
+//
+// IDLE and TIME set the time of the last delivery, RETRYCOUNT sets the number of
+// deliveries, which is otherwise incremented unless JUSTID is given. FORCE claims
+// the entries of the stream that are not pending yet. JUSTID returns the claimed
+// IDs only. Pending entries deleted from the stream are dropped from the pending
+// entries list instead of being claimed.
+func evalXCLAIM(args []string, store *dstore.Store) []byte {
+	if len(args) < 5 {
+		return diceerrors.NewErrArity("XCLAIM")
+	}
+	key, group, consumer := args[0], args[1], args[2]
+
+	minIdle, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return diceerrors.NewErrWithMessage("Invalid min-idle-time argument for XCLAIM")
+	}
+
+	// the IDs are followed by the options, the first argument that is not an ID
+	// being the first option
+	i := 4
+	var ids []StreamID
+	for ; i < len(args); i++ {
+		id, err := parseStreamID(args[i], 0)
+		if err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return diceerrors.NewErrWithMessage(errStreamInvalidID.Error())
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	deliveredAt := now
+	retryCount := int64(-1)
+	force, justID := false, false
+	var lastID *StreamID
+	for ; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case Force:
+			force = true
+			continue
+		case JustID:
+			justID = true
+			continue
+		case Idle, Time, RetryCount, LastID:
+		default:
+			return diceerrors.NewErrWithFormattedMessage("Unrecognized XCLAIM option '%s'", args[i])
+		}
+
+		if i+1 >= len(args) {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		i++
+		if opt == LastID {
+			id, err := parseStreamID(args[i], 0)
+			if err != nil {
+				return diceerrors.NewErrWithMessage(err.Error())
+			}
+			lastID = &id
+			continue
+		}
+
+		v, err := strconv.ParseInt(args[i], 10, 64)
+		if err != nil || v < 0 {
+			return diceerrors.NewErrWithFormattedMessage("Invalid %s option argument for XCLAIM", opt)
+		}
+		switch opt {
+		case Idle:
+			deliveredAt = now - v
+		case Time:
+			deliveredAt = v
+		default:
+			retryCount = v
+		}
+	}
+
+	stream, g, errResp := getStreamGroup(key, group, store)
+	if errResp != nil {
+		return errResp
+	}
+	if g == nil {
+		return errStreamNoGroup(key, group)
+	}
+
+	if lastID != nil && g.LastDeliveredID.Less(*lastID) {
+		g.LastDeliveredID = *lastID
+	}
+
+	c, _ := g.createConsumer(consumer, now)
+	resp := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		entry := stream.entry(id)
+		pe := g.pending(id)
+		switch {
+		case pe == nil && (!force || entry == nil):
+			continue
+		case pe == nil:
+			// FORCE creates the pending entry, which is claimed regardless of min-idle-time
+			pe = &StreamPendingEntry{ID: id, Consumer: c.Name}
+			g.pel.ReplaceOrInsert(pe)
+			c.Pending++
+		case now-pe.DeliveredAt < minIdle:
+			continue
+		case entry == nil:
+			g.Ack(id)
+			continue
+		default:
+			g.transfer(pe, c)
+		}
+
+		pe.DeliveredAt = deliveredAt
+		if retryCount >= 0 {
+			pe.Deliveries = retryCount
+		} else if !justID {
+			pe.Deliveries++
+		}
+
+		if justID {
+			resp = append(resp, id.String())
+		} else {
+			resp = append(resp, entry.toResp())
+		}
+	}
+
+	return clientio.Encode(resp, false)
+}
+
+// evalXAUTOCLAIM claims the pending entries idle for at least min-idle-time
+// milliseconds, scanning the pending entries list of the group from start.
+//
+// Usage: XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID]
+//
+// It returns the ID to pass as start to the next call, 0-0 once the whole list
+// has been scanned, the claimed entries (or IDs with JUSTID), and the IDs of the
+// pending entries deleted from the stream, which are dropped from the list.
+func evalXAUTOCLAIM(args []string, store *dstore.Store) []byte {
+	if len(args) < 5 {
+		return diceerrors.NewErrArity("XAUTOCLAIM")
+	}
+	key, group, consumer := args[0], args[1], args[2]
+
+	minIdle, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return diceerrors.NewErrWithMessage("Invalid min-idle-time argument for XAUTOCLAIM")
+	}
+
+	start, ok, err := parseStreamRangeBound(args[4], true)
+	if err != nil {
+		return diceerrors.NewErrWithMessage(err.Error())
+	}
+	if !ok {
+		start = streamIDMaxValue
+	}
+
+	count := xautoclaimDefaultCount
+	justID := false
+	for i := 5; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case Count:
+			if i+1 >= len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			i++
+			c, err := strconv.Atoi(args[i])
+			if err != nil || c < 1 || c > math.MaxInt/xautoclaimAttemptsFactor {
+				return diceerrors.NewErrWithMessage("COUNT must be > 0")
+			}
+			count = c
+		case JustID:
+			justID = true
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	stream, g, errResp := getStreamGroup(key, group, store)
+	if errResp != nil {
+		return errResp
+	}
+	if g == nil {
+		return errStreamNoGroup(key, group)
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	c, _ := g.createConsumer(consumer, now)
+
+	var scanned []*StreamPendingEntry
+	next := StreamID{}
+	attempts := count * xautoclaimAttemptsFactor
+	g.pel.AscendGreaterOrEqual(&StreamPendingEntry{ID: start}, func(item btree.Item) bool {
+		pe := item.(*StreamPendingEntry)
+		if attempts == 0 || len(scanned) == count {
+			next = pe.ID
+			return false
+		}
+		attempts--
+		if now-pe.DeliveredAt >= minIdle {
+			scanned = append(scanned, pe)
+		}
+		return true
+	})
+
+	claimed := make([]interface{}, 0, len(scanned))
+	deleted := make([]interface{}, 0)
+	for _, pe := range scanned {
+		entry := stream.entry(pe.ID)
+		if entry == nil {
+			g.Ack(pe.ID)
+			deleted = append(deleted, pe.ID.String())
+			continue
+		}
+
+		g.transfer(pe, c)
+		pe.DeliveredAt = now
+		if justID {
+			claimed = append(claimed, pe.ID.String())
+		} else {
+			pe.Deliveries++
+			claimed = append(claimed, entry.toResp())
+		}
+	}
+
+	return clientio.Encode([]interface{}{next.String(), claimed, deleted}, false)
+}
@@ -0,0 +1,182 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestEvalStreamGroups(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	store := dstore.NewStore(nil)
+	entry := func(id string, fields ...string) []interface{} {
+		return []interface{}{id, fields}
+	}
+	expect := func(t *testing.T, expected interface{}, output []byte) {
+		t.Helper()
+		if b, ok := expected.([]byte); ok {
+			assert.Equal(t, string(b), string(output))
+			return
+		}
+		assert.Equal(t, string(clientio.Encode(expected, false)), string(output))
+	}
+	setup := func() {
+		dstore.ResetStore(store)
+		evalXADD([]string{"s", "1-1", "a", "1"}, store)
+		evalXADD([]string{"s", "2-1", "b", "2"}, store)
+		evalXADD([]string{"s", "3-1", "c", "3"}, store)
+		evalXGROUP([]string{"CREATE", "s", "g", "0"}, store)
+	}
+
+	t.Run("XGROUP", func(t *testing.T) {
+		setup()
+		expect(t, diceerrors.NewErrWithMessage("-BUSYGROUP Consumer Group name already exists"),
+			evalXGROUP([]string{"CREATE", "s", "g", "$"}, store))
+		expect(t, diceerrors.NewErrWithMessage("The XGROUP subcommand requires the key to exist. "+
+			"Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically."),
+			evalXGROUP([]string{"CREATE", "missing", "g", "$"}, store))
+		expect(t, clientio.RespOK, evalXGROUP([]string{"CREATE", "new", "g", "$", "MKSTREAM"}, store))
+		expect(t, clientio.RespZero, evalXLEN([]string{"new"}, store))
+
+		expect(t, clientio.RespOne, evalXGROUP([]string{"CREATECONSUMER", "s", "g", "alice"}, store))
+		expect(t, clientio.RespZero, evalXGROUP([]string{"CREATECONSUMER", "s", "g", "alice"}, store))
+		expect(t, errStreamNoGroup("s", "nope"), evalXGROUP([]string{"CREATECONSUMER", "s", "nope", "alice"}, store))
+
+		evalXREADGROUP([]string{"GROUP", "g", "alice", "COUNT", "2", "STREAMS", "s", ">"}, store)
+		expect(t, 2, evalXGROUP([]string{"DELCONSUMER", "s", "g", "alice"}, store))
+		expect(t, []interface{}{0, nil, nil, nil}, evalXPENDING([]string{"s", "g"}, store))
+
+		expect(t, clientio.RespOK, evalXGROUP([]string{"SETID", "s", "g", "2-1"}, store))
+		expect(t, []interface{}{[]interface{}{"s", []interface{}{entry("3-1", "c", "3")}}},
+			evalXREADGROUP([]string{"GROUP", "g", "bob", "STREAMS", "s", ">"}, store))
+
+		expect(t, clientio.RespOne, evalXGROUP([]string{"DESTROY", "s", "g"}, store))
+		expect(t, clientio.RespZero, evalXGROUP([]string{"DESTROY", "s", "g"}, store))
+		expect(t, diceerrors.NewErrWithMessage("unknown subcommand 'FOO'. Try XGROUP HELP."),
+			evalXGROUP([]string{"FOO", "s", "g"}, store))
+	})
+
+	t.Run("XREADGROUP", func(t *testing.T) {
+		setup()
+		expect(t, []interface{}{[]interface{}{"s", []interface{}{entry("1-1", "a", "1"), entry("2-1", "b", "2")}}},
+			evalXREADGROUP([]string{"GROUP", "g", "alice", "COUNT", "2", "STREAMS", "s", ">"}, store))
+		expect(t, []interface{}{[]interface{}{"s", []interface{}{entry("3-1", "c", "3")}}},
+			evalXREADGROUP([]string{"GROUP", "g", "bob", "STREAMS", "s", ">"}, store))
+		expect(t, clientio.RespNIL, evalXREADGROUP([]string{"GROUP", "g", "bob", "STREAMS", "s", ">"}, store))
+
+		// the history of a consumer only holds its own pending entries
+		expect(t, []interface{}{[]interface{}{"s", []interface{}{entry("2-1", "b", "2")}}},
+			evalXREADGROUP([]string{"GROUP", "g", "alice", "STREAMS", "s", "1-1"}, store))
+
+		// trimmed entries are reported with a nil value
+		evalXADD([]string{"s", "MAXLEN", "3", "4-1", "d", "4"}, store)
+		expect(t, []interface{}{[]interface{}{"s", []interface{}{[]interface{}{"1-1", nil}, entry("2-1", "b", "2")}}},
+			evalXREADGROUP([]string{"GROUP", "g", "alice", "STREAMS", "s", "0"}, store))
+
+		// NOACK entries are not pending
+		expect(t, []interface{}{[]interface{}{"s", []interface{}{entry("4-1", "d", "4")}}},
+			evalXREADGROUP([]string{"GROUP", "g", "carol", "NOACK", "STREAMS", "s", ">"}, store))
+		expect(t, []interface{}{3, "1-1", "3-1", []interface{}{[]interface{}{"alice", "2"}, []interface{}{"bob", "1"}}},
+			evalXPENDING([]string{"s", "g"}, store))
+
+		expect(t, diceerrors.NewErrWithMessage("-NOGROUP No such key 's' or consumer group 'nope' in XREADGROUP with GROUP option"),
+			evalXREADGROUP([]string{"GROUP", "nope", "alice", "STREAMS", "s", ">"}, store))
+		expect(t, diceerrors.NewErrWithMessage("BLOCK option is not supported for 'xreadgroup' command"),
+			evalXREADGROUP([]string{"GROUP", "g", "alice", "BLOCK", "0", "STREAMS", "s", ">"}, store))
+		expect(t, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr),
+			evalXREADGROUP([]string{"GRP", "g", "alice", "STREAMS", "s", ">"}, store))
+	})
+
+	t.Run("XACK and XPENDING", func(t *testing.T) {
+		setup()
+		evalXREADGROUP([]string{"GROUP", "g", "alice", "COUNT", "2", "STREAMS", "s", ">"}, store)
+		mockTime.SetTime(time.Unix(1005, 0))
+		evalXREADGROUP([]string{"GROUP", "g", "bob", "STREAMS", "s", ">"}, store)
+
+		expect(t, []interface{}{
+			[]interface{}{"1-1", "alice", int64(5000), int64(1)},
+			[]interface{}{"2-1", "alice", int64(5000), int64(1)},
+			[]interface{}{"3-1", "bob", int64(0), int64(1)},
+		}, evalXPENDING([]string{"s", "g", "-", "+", "10"}, store))
+		expect(t, []interface{}{[]interface{}{"3-1", "bob", int64(0), int64(1)}},
+			evalXPENDING([]string{"s", "g", "-", "+", "10", "bob"}, store))
+		expect(t, []interface{}{[]interface{}{"1-1", "alice", int64(5000), int64(1)}},
+			evalXPENDING([]string{"s", "g", "IDLE", "1000", "-", "+", "1"}, store))
+		expect(t, []interface{}{[]interface{}{"2-1", "alice", int64(5000), int64(1)}},
+			evalXPENDING([]string{"s", "g", "(1-1", "2", "10"}, store))
+
+		expect(t, 2, evalXACK([]string{"s", "g", "1-1", "3-1", "9-9"}, store))
+		expect(t, clientio.RespZero, evalXACK([]string{"s", "g", "1-1"}, store))
+		expect(t, clientio.RespZero, evalXACK([]string{"s", "nope", "2-1"}, store))
+		expect(t, diceerrors.NewErrWithMessage(errStreamInvalidID.Error()), evalXACK([]string{"s", "g", "x"}, store))
+		expect(t, []interface{}{1, "2-1", "2-1", []interface{}{[]interface{}{"alice", "1"}}},
+			evalXPENDING([]string{"s", "g"}, store))
+
+		expect(t, errStreamNoGroup("s", "nope"), evalXPENDING([]string{"s", "nope"}, store))
+	})
+
+	t.Run("XCLAIM", func(t *testing.T) {
+		setup()
+		evalXREADGROUP([]string{"GROUP", "g", "alice", "STREAMS", "s", ">"}, store)
+		mockTime.SetTime(time.Unix(1010, 0))
+
+		// not idle for long enough
+		expect(t, []interface{}{}, evalXCLAIM([]string{"s", "g", "bob", "60000", "1-1"}, store))
+
+		expect(t, []interface{}{entry("1-1", "a", "1")}, evalXCLAIM([]string{"s", "g", "bob", "5000", "1-1"}, store))
+		expect(t, []interface{}{"2-1"}, evalXCLAIM([]string{"s", "g", "bob", "0", "2-1", "JUSTID", "IDLE", "500"}, store))
+		expect(t, []interface{}{
+			[]interface{}{"1-1", "bob", int64(0), int64(2)},
+			[]interface{}{"2-1", "bob", int64(500), int64(1)},
+		}, evalXPENDING([]string{"s", "g", "-", "2", "10"}, store))
+
+		expect(t, []interface{}{"3-1"}, evalXCLAIM([]string{"s", "g", "bob", "0", "3-1", "RETRYCOUNT", "7", "JUSTID"}, store))
+		expect(t, []interface{}{[]interface{}{"3-1", "bob", int64(0), int64(7)}},
+			evalXPENDING([]string{"s", "g", "3", "+", "10"}, store))
+
+		// FORCE claims entries that are not pending, as long as they exist
+		evalXADD([]string{"s", "4-1", "d", "4"}, store)
+		expect(t, []interface{}{}, evalXCLAIM([]string{"s", "g", "bob", "0", "4-1"}, store))
+		expect(t, []interface{}{"4-1"}, evalXCLAIM([]string{"s", "g", "bob", "0", "4-1", "5-1", "FORCE", "JUSTID", "LASTID", "4-1"}, store))
+		expect(t, clientio.RespNIL, evalXREADGROUP([]string{"GROUP", "g", "bob", "STREAMS", "s", ">"}, store))
+
+		// pending entries deleted from the stream are dropped
+		evalXADD([]string{"s", "MAXLEN", "0", "5-1", "e", "5"}, store)
+		expect(t, []interface{}{}, evalXCLAIM([]string{"s", "g", "alice", "0", "1-1"}, store))
+		expect(t, []interface{}{3, "2-1", "4-1", []interface{}{[]interface{}{"bob", "3"}}},
+			evalXPENDING([]string{"s", "g"}, store))
+
+		expect(t, diceerrors.NewErrWithMessage("Unrecognized XCLAIM option 'FOO'"),
+			evalXCLAIM([]string{"s", "g", "bob", "0", "1-1", "FOO"}, store))
+		expect(t, diceerrors.NewErrWithMessage("Invalid min-idle-time argument for XCLAIM"),
+			evalXCLAIM([]string{"s", "g", "bob", "x", "1-1"}, store))
+	})
+
+	t.Run("XAUTOCLAIM", func(t *testing.T) {
+		setup()
+		evalXADD([]string{"s", "4-1", "d", "4"}, store)
+		evalXREADGROUP([]string{"GROUP", "g", "alice", "STREAMS", "s", ">"}, store)
+		mockTime.SetTime(time.Unix(1020, 0))
+
+		expect(t, []interface{}{"3-1", []interface{}{entry("1-1", "a", "1"), entry("2-1", "b", "2")}, []interface{}{}},
+			evalXAUTOCLAIM([]string{"s", "g", "bob", "1000", "0", "COUNT", "2"}, store))
+
+		evalXADD([]string{"s", "MINID", "4", "5-1", "e", "5"}, store)
+		expect(t, []interface{}{"0-0", []interface{}{"4-1"}, []interface{}{"3-1"}},
+			evalXAUTOCLAIM([]string{"s", "g", "bob", "1000", "3-1", "JUSTID"}, store))
+		expect(t, []interface{}{3, "1-1", "4-1", []interface{}{[]interface{}{"bob", "3"}}},
+			evalXPENDING([]string{"s", "g"}, store))
+
+		expect(t, diceerrors.NewErrWithMessage("COUNT must be > 0"),
+			evalXAUTOCLAIM([]string{"s", "g", "bob", "0", "0", "COUNT", "0"}, store))
+		expect(t, errStreamNoGroup("s", "nope"), evalXAUTOCLAIM([]string{"s", "nope", "bob", "0", "0"}, store))
+	})
+}
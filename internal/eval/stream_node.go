@@ -0,0 +1,135 @@
+package eval
+
+import (
+	"strconv"
+
+	"github.com/google/btree"
+)
+
+// The entries of a stream are packed in macro-nodes, like the radix tree nodes
+// of Redis: each node holds a listpack of consecutive entries, encoded with the
+// same encoding as the entries of the deques. The ID of an entry is encoded as
+// the difference with the master ID of its node, the ID of its first entry,
+// which makes the IDs of the entries added within a short time fit in a few
+// bytes. An entry is laid out as:
+//
+//	ms-diff seq-diff num-fields field value [field value ...]
+//
+// A node is filled up to streamNodeMaxEntries entries or streamNodeMaxBytes
+// bytes, then a new one is started. The approximate trimming, MAXLEN ~ and
+// MINID ~, only evicts whole nodes, hence never has to rewrite a node.
+
+const (
+	// streamNodeMaxEntries and streamNodeMaxBytes are the maximum number of
+	// entries and of bytes of a node, like stream-node-max-entries and
+	// stream-node-max-bytes in Redis.
+	streamNodeMaxEntries = 100
+	streamNodeMaxBytes   = 4096
+)
+
+// streamNode is a macro-node of a stream, holding the entries following its
+// master ID.
+type streamNode struct {
+	master StreamID // master is the ID the IDs of the entries are encoded relative to
+	lastID StreamID // lastID is the ID of the last entry of the node
+	count  int      // count is the number of entries of the node
+	data   []byte   // data is the listpack of the entries
+}
+
+// Less compares two streamNodes by master ID. Required by the btree.Item interface.
+func (n *streamNode) Less(b btree.Item) bool {
+	return n.master.Less(b.(*streamNode).master)
+}
+
+// full returns true if no entry can be added to the node.
+func (n *streamNode) full() bool {
+	return n.count >= streamNodeMaxEntries || len(n.data) >= streamNodeMaxBytes
+}
+
+// add appends an entry to the node, its ID being greater than the ones of the
+// entries of the node.
+func (n *streamNode) add(id StreamID, fields []string) {
+	// the differences wrap around, hence are decoded back exactly
+	n.data = append(n.data, EncodeDeqInt(int64(id.Ms-n.master.Ms))...)
+	n.data = append(n.data, EncodeDeqInt(int64(id.Seq-n.master.Seq))...)
+	n.data = append(n.data, EncodeDeqInt(int64(len(fields)/2))...)
+	for _, f := range fields {
+		// the strings are never encoded as integers, which would not keep them as is
+		n.data = append(n.data, EncodeDeqStr(f)...)
+	}
+	n.lastID = id
+	n.count++
+}
+
+// decodeInt decodes the integer at off in the listpack of the node and returns
+// the offset of the following item.
+func (n *streamNode) decodeInt(off int) (v int64, next int) {
+	s, size := DecodeDeqEntry(n.data[off:])
+	v, _ = strconv.ParseInt(s, 10, 64)
+	return v, off + size
+}
+
+// iterate calls fn with the entries of the node in order, until fn returns
+// false. The fields are decoded only for the entries fn needs them for, as
+// told by wantFields.
+func (n *streamNode) iterate(wantFields func(id StreamID) bool, fn func(entry *StreamEntry, off int) bool) {
+	off := 0
+	for i := 0; i < n.count; i++ {
+		start := off
+		var msDiff, seqDiff, numFields int64
+		msDiff, off = n.decodeInt(off)
+		seqDiff, off = n.decodeInt(off)
+		numFields, off = n.decodeInt(off)
+
+		entry := &StreamEntry{ID: StreamID{Ms: n.master.Ms + uint64(msDiff), Seq: n.master.Seq + uint64(seqDiff)}}
+		if wantFields(entry.ID) {
+			entry.Fields = make([]string, 2*numFields)
+		}
+		for j := 0; j < int(2*numFields); j++ {
+			s, size := DecodeDeqEntry(n.data[off:])
+			if entry.Fields != nil {
+				entry.Fields[j] = s
+			}
+			off += size
+		}
+		if !fn(entry, start) {
+			return
+		}
+	}
+}
+
+// entries returns the entries of the node whose ID is within [start, end].
+func (n *streamNode) entries(start, end StreamID) []*StreamEntry {
+	inRange := func(id StreamID) bool {
+		return !id.Less(start) && !end.Less(id)
+	}
+
+	var entries []*StreamEntry
+	n.iterate(inRange, func(entry *StreamEntry, _ int) bool {
+		if inRange(entry.ID) {
+			entries = append(entries, entry)
+		}
+		return !end.Less(entry.ID)
+	})
+	return entries
+}
+
+// trimHead evicts the entries of the node whose ID is lower than minID, up to
+// max entries, and returns the number of evicted entries. The listpack is
+// copied, so that the memory of the evicted entries is released.
+func (n *streamNode) trimHead(minID StreamID, max int) int {
+	trimmed, rest := 0, len(n.data)
+	n.iterate(func(StreamID) bool { return false }, func(entry *StreamEntry, off int) bool {
+		if trimmed == max || !entry.ID.Less(minID) {
+			rest = off
+			return false
+		}
+		trimmed++
+		return true
+	})
+	if trimmed > 0 {
+		n.data = append([]byte(nil), n.data[rest:]...)
+		n.count -= trimmed
+	}
+	return trimmed
+}
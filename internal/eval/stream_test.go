@@ -0,0 +1,350 @@
+package eval
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestStreamNextID(t *testing.T) {
+	s := NewStream()
+	s.Add(StreamID{Ms: 5, Seq: 3}, []string{"f", "v"})
+
+	tests := map[string]struct {
+		arg      string
+		expected StreamID
+		err      error
+	}{
+		"explicit id":                  {arg: "6-1", expected: StreamID{Ms: 6, Seq: 1}},
+		"explicit id without seq":      {arg: "6", expected: StreamID{Ms: 6}},
+		"explicit id equal to last":    {arg: "5-3", err: errStreamIDTooSmall},
+		"explicit id smaller":          {arg: "4-9", err: errStreamIDTooSmall},
+		"zero id":                      {arg: "0-0", err: errStreamIDZero},
+		"invalid id":                   {arg: "abc", err: errStreamInvalidID},
+		"auto seq on same ms":          {arg: "5-*", expected: StreamID{Ms: 5, Seq: 4}},
+		"auto seq on greater ms":       {arg: "7-*", expected: StreamID{Ms: 7}},
+		"auto seq on smaller ms":       {arg: "4-*", err: errStreamIDTooSmall},
+		"auto seq with invalid ms":     {arg: "x-*", err: errStreamInvalidID},
+		"auto id uses the clock":       {arg: "*", expected: StreamID{Ms: 1000}},
+		"explicit id on max ms":        {arg: strconv.FormatUint(math.MaxUint64, 10), expected: StreamID{Ms: math.MaxUint64}},
+		"explicit id with invalid seq": {arg: "6-x", err: errStreamInvalidID},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			id, err := s.NextID(tc.arg)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.NilError(t, err)
+			if tc.arg == streamIDAny {
+				assert.Assert(t, s.LastID().Less(id))
+				return
+			}
+			assert.Equal(t, tc.expected, id)
+		})
+	}
+
+	assert.ErrorIs(t, mustNextID(NewStream(), "0-0"), errStreamIDZero)
+	assert.NilError(t, mustNextID(NewStream(), "0-*"))
+
+	exhausted := NewStream()
+	exhausted.Add(StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}, []string{"f", "v"})
+	assert.ErrorIs(t, mustNextID(exhausted, "*"), errStreamExhausted)
+}
+
+func mustNextID(s *Stream, arg string) error {
+	_, err := s.NextID(arg)
+	return err
+}
+
+func TestStreamRangeAndTrim(t *testing.T) {
+	s := NewStream()
+	for i := uint64(1); i <= 10; i++ {
+		s.Add(StreamID{Ms: i}, []string{"n", strconv.FormatUint(i, 10)})
+	}
+
+	ids := func(entries []*StreamEntry) []uint64 {
+		res := make([]uint64, len(entries))
+		for i, e := range entries {
+			res[i] = e.ID.Ms
+		}
+		return res
+	}
+
+	assert.DeepEqual(t, []uint64{3, 4, 5}, ids(s.Range(StreamID{Ms: 3}, StreamID{Ms: 5}, -1, false)))
+	assert.DeepEqual(t, []uint64{5, 4}, ids(s.Range(StreamID{Ms: 3}, StreamID{Ms: 5}, 2, true)))
+	assert.DeepEqual(t, []uint64{}, ids(s.Range(StreamID{Ms: 5}, StreamID{Ms: 3}, -1, false)))
+
+	assert.Equal(t, 2, s.TrimMinID(StreamID{Ms: 3}))
+	assert.Equal(t, 8, s.Len())
+	assert.Equal(t, 5, s.TrimMaxLen(3))
+	assert.DeepEqual(t, []uint64{8, 9, 10}, ids(s.Range(StreamID{}, StreamID{Ms: math.MaxUint64}, -1, false)))
+	// the last ID is kept even if the entries are trimmed
+	assert.Equal(t, 3, s.TrimMaxLen(0))
+	assert.Equal(t, StreamID{Ms: 10}, s.LastID())
+}
+
+func TestStreamNodes(t *testing.T) {
+	s := NewStream()
+	// the IDs far apart and the fields looking like integers are kept as is
+	s.Add(StreamID{Ms: 1, Seq: math.MaxUint64}, []string{"n", "007"})
+	s.Add(StreamID{Ms: math.MaxUint64 - 1}, []string{"n", "-0", "", "x"})
+	assert.DeepEqual(t, []*StreamEntry{
+		{ID: StreamID{Ms: 1, Seq: math.MaxUint64}, Fields: []string{"n", "007"}},
+		{ID: StreamID{Ms: math.MaxUint64 - 1}, Fields: []string{"n", "-0", "", "x"}},
+	}, s.Range(StreamID{}, streamIDMaxValue, -1, false))
+
+	// the nodes are filled up to their maximum number of entries
+	s = NewStream()
+	for i := uint64(1); i <= 3*streamNodeMaxEntries+10; i++ {
+		s.Add(StreamID{Ms: 1000 + i/3, Seq: i % 3}, []string{"n", strconv.FormatUint(i, 10)})
+	}
+	assert.Equal(t, 4, s.nodes.Len())
+	assert.Equal(t, 3*streamNodeMaxEntries+10, s.Len())
+	entry := s.entry(StreamID{Ms: 1000 + 150/3, Seq: 0})
+	assert.DeepEqual(t, []string{"n", "150"}, entry.Fields)
+	assert.Assert(t, s.entry(StreamID{Ms: 1000, Seq: 0}) == nil)
+	assert.Equal(t, 5, len(s.Range(StreamID{Ms: 1033, Seq: 1}, streamIDMaxValue, 5, false)))
+	assert.Equal(t, 250, len(s.Range(StreamID{}, StreamID{Ms: 1000 + 250/3, Seq: 250 % 3}, -1, true)))
+
+	// the approximate trimming evicts whole nodes, up to its limit
+	assert.Equal(t, 0, s.TrimMaxLenApprox(s.Len()-streamNodeMaxEntries+1, 0))
+	assert.Equal(t, 0, s.TrimMaxLenApprox(0, streamNodeMaxEntries-1))
+	assert.Equal(t, streamNodeMaxEntries, s.TrimMaxLenApprox(s.Len()-streamNodeMaxEntries-5, streamNodeMaxEntries))
+	assert.Equal(t, streamNodeMaxEntries, s.TrimMinIDApprox(StreamID{Ms: 1000 + 250/3}, 0))
+	assert.Equal(t, streamNodeMaxEntries+10, s.Len())
+
+	// the exact trimming rewrites the first node
+	assert.Equal(t, 15, s.TrimMaxLen(streamNodeMaxEntries-5))
+	assert.Equal(t, streamNodeMaxEntries-5, s.Len())
+	first := s.Range(StreamID{}, streamIDMaxValue, 1, false)[0]
+	assert.DeepEqual(t, []string{"n", "216"}, first.Fields)
+	assert.Equal(t, 1, s.TrimMinID(StreamID{Ms: first.ID.Ms, Seq: first.ID.Seq + 1}))
+	assert.NilError(t, validateStream(s))
+}
+
+func TestEvalStreams(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	entry := func(id string, fields ...string) []interface{} {
+		return []interface{}{id, fields}
+	}
+
+	tests := map[string]struct {
+		setup    func()
+		eval     func([]string, *dstore.Store) []byte
+		input    []string
+		expected []byte
+	}{
+		"XADD with wrong number of arguments": {
+			eval:     evalXADD,
+			input:    []string{"s", "*", "f"},
+			expected: diceerrors.NewErrArity("XADD"),
+		},
+		"XADD with odd number of fields": {
+			eval:     evalXADD,
+			input:    []string{"s", "1-1", "f", "v", "f2"},
+			expected: diceerrors.NewErrArity("XADD"),
+		},
+		"XADD with explicit id": {
+			eval:     evalXADD,
+			input:    []string{"s", "1-1", "f", "v"},
+			expected: clientio.Encode("1-1", false),
+		},
+		"XADD with smaller id": {
+			setup:    func() { evalXADD([]string{"s", "2-1", "f", "v"}, store) },
+			eval:     evalXADD,
+			input:    []string{"s", "1-1", "f", "v"},
+			expected: diceerrors.NewErrWithMessage("The ID specified in XADD is equal or smaller than the target stream top item"),
+		},
+		"XADD with NOMKSTREAM on missing stream": {
+			eval:     evalXADD,
+			input:    []string{"s", "NOMKSTREAM", "*", "f", "v"},
+			expected: clientio.RespNIL,
+		},
+		"XADD on wrong type": {
+			setup:    func() { evalSET([]string{"s", "v"}, store) },
+			eval:     evalXADD,
+			input:    []string{"s", "*", "f", "v"},
+			expected: diceerrors.NewErrWithMessage(diceerrors.WrongTypeErr),
+		},
+		"XADD with MAXLEN trims the stream": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "f", "v"}, store)
+				evalXADD([]string{"s", "1-2", "f", "v"}, store)
+			},
+			eval:     evalXADD,
+			input:    []string{"s", "MAXLEN", "~", "2", "1-3", "f", "v"},
+			expected: clientio.Encode("1-3", false),
+		},
+		"XADD with invalid MAXLEN": {
+			eval:     evalXADD,
+			input:    []string{"s", "MAXLEN", "-1", "*", "f", "v"},
+			expected: diceerrors.NewErrWithMessage("The MAXLEN argument must be >= 0."),
+		},
+		"XADD with LIMIT without ~": {
+			eval:     evalXADD,
+			input:    []string{"s", "MAXLEN", "2", "LIMIT", "10", "*", "f", "v"},
+			expected: diceerrors.NewErrWithMessage("syntax error, LIMIT cannot be used without the special ~ option"),
+		},
+		"XTRIM on missing stream": {
+			eval:     evalXTRIM,
+			input:    []string{"s", "MAXLEN", "0"},
+			expected: clientio.RespZero,
+		},
+		"XTRIM with MINID": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "f", "v"}, store)
+				evalXADD([]string{"s", "2-1", "f", "v"}, store)
+				evalXADD([]string{"s", "3-1", "f", "v"}, store)
+			},
+			eval:     evalXTRIM,
+			input:    []string{"s", "MINID", "3"},
+			expected: clientio.Encode(2, false),
+		},
+		"XTRIM approximate within a node": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "f", "v"}, store)
+				evalXADD([]string{"s", "2-1", "f", "v"}, store)
+			},
+			eval:     evalXTRIM,
+			input:    []string{"s", "MAXLEN", "~", "1", "LIMIT", "0"},
+			expected: clientio.RespZero,
+		},
+		"XTRIM with invalid LIMIT": {
+			eval:     evalXTRIM,
+			input:    []string{"s", "MAXLEN", "~", "1", "LIMIT", "-1"},
+			expected: diceerrors.NewErrWithMessage("The LIMIT argument must be >= 0."),
+		},
+		"XTRIM with unknown strategy": {
+			eval:     evalXTRIM,
+			input:    []string{"s", "MAXSIZE", "1"},
+			expected: diceerrors.NewErrWithMessage(diceerrors.SyntaxErr),
+		},
+		"XTRIM with trailing arguments": {
+			eval:     evalXTRIM,
+			input:    []string{"s", "MAXLEN", "1", "2"},
+			expected: diceerrors.NewErrWithMessage(diceerrors.SyntaxErr),
+		},
+		"XLEN on missing stream": {
+			eval:     evalXLEN,
+			input:    []string{"s"},
+			expected: clientio.RespZero,
+		},
+		"XLEN after MAXLEN trimming": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "f", "v"}, store)
+				evalXADD([]string{"s", "1-2", "f", "v"}, store)
+				evalXADD([]string{"s", "MAXLEN", "2", "1-3", "f", "v"}, store)
+			},
+			eval:     evalXLEN,
+			input:    []string{"s"},
+			expected: clientio.Encode(2, false),
+		},
+		"XLEN after MINID trimming": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "f", "v"}, store)
+				evalXADD([]string{"s", "2-1", "f", "v"}, store)
+				evalXADD([]string{"s", "MINID", "=", "3", "3-1", "f", "v"}, store)
+			},
+			eval:     evalXLEN,
+			input:    []string{"s"},
+			expected: clientio.Encode(1, false),
+		},
+		"XRANGE whole stream": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "a", "1"}, store)
+				evalXADD([]string{"s", "2-1", "b", "2", "c", "3"}, store)
+			},
+			eval:     evalXRANGE,
+			input:    []string{"s", "-", "+"},
+			expected: clientio.Encode([]interface{}{entry("1-1", "a", "1"), entry("2-1", "b", "2", "c", "3")}, false),
+		},
+		"XRANGE with exclusive start and incomplete end": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "a", "1"}, store)
+				evalXADD([]string{"s", "2-1", "b", "2"}, store)
+				evalXADD([]string{"s", "2-5", "c", "3"}, store)
+				evalXADD([]string{"s", "3-1", "d", "4"}, store)
+			},
+			eval:     evalXRANGE,
+			input:    []string{"s", "(1-1", "2"},
+			expected: clientio.Encode([]interface{}{entry("2-1", "b", "2"), entry("2-5", "c", "3")}, false),
+		},
+		"XRANGE with COUNT": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "a", "1"}, store)
+				evalXADD([]string{"s", "2-1", "b", "2"}, store)
+			},
+			eval:     evalXRANGE,
+			input:    []string{"s", "-", "+", "COUNT", "1"},
+			expected: clientio.Encode([]interface{}{entry("1-1", "a", "1")}, false),
+		},
+		"XRANGE with invalid id": {
+			eval:     evalXRANGE,
+			input:    []string{"s", "x", "+"},
+			expected: diceerrors.NewErrWithMessage("Invalid stream ID specified as stream command argument"),
+		},
+		"XRANGE on missing stream": {
+			eval:     evalXRANGE,
+			input:    []string{"s", "-", "+"},
+			expected: clientio.RespEmptyArray,
+		},
+		"XREVRANGE with COUNT": {
+			setup: func() {
+				evalXADD([]string{"s", "1-1", "a", "1"}, store)
+				evalXADD([]string{"s", "2-1", "b", "2"}, store)
+				evalXADD([]string{"s", "3-1", "c", "3"}, store)
+			},
+			eval:     evalXREVRANGE,
+			input:    []string{"s", "+", "-", "COUNT", "2"},
+			expected: clientio.Encode([]interface{}{entry("3-1", "c", "3"), entry("2-1", "b", "2")}, false),
+		},
+		"XREAD from multiple streams": {
+			setup: func() {
+				evalXADD([]string{"s1", "1-1", "a", "1"}, store)
+				evalXADD([]string{"s1", "2-1", "b", "2"}, store)
+				evalXADD([]string{"s2", "1-1", "c", "3"}, store)
+			},
+			eval:  evalXREAD,
+			input: []string{"COUNT", "5", "STREAMS", "s1", "s2", "s3", "1-1", "0", "0"},
+			expected: clientio.Encode([]interface{}{
+				[]interface{}{"s1", []interface{}{entry("2-1", "b", "2")}},
+				[]interface{}{"s2", []interface{}{entry("1-1", "c", "3")}},
+			}, false),
+		},
+		"XREAD with $ returns nil": {
+			setup:    func() { evalXADD([]string{"s", "1-1", "a", "1"}, store) },
+			eval:     evalXREAD,
+			input:    []string{"STREAMS", "s", "$"},
+			expected: clientio.RespNIL,
+		},
+		"XREAD with unbalanced streams": {
+			eval:     evalXREAD,
+			input:    []string{"STREAMS", "s1", "s2", "0"},
+			expected: diceerrors.NewErrWithMessage("Unbalanced 'xread' list of streams: for each stream key an ID or '$' must be specified."),
+		},
+		"XREAD with BLOCK": {
+			eval:     evalXREAD,
+			input:    []string{"BLOCK", "0", "STREAMS", "s", "0"},
+			expected: diceerrors.NewErrWithMessage("BLOCK option is not supported for 'xread' command"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dstore.ResetStore(store)
+			if tc.setup != nil {
+				tc.setup()
+			}
+			assert.Equal(t, string(tc.expected), string(tc.eval(tc.input, store)))
+		})
+	}
+}
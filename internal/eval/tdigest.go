@@ -0,0 +1,444 @@
+package eval
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+const (
+	defaultTDigestCompression uint64 = 100
+	maxTDigestCompression     uint64 = 100000
+
+	// values added are buffered and merged into the centroids once the buffer
+	// holds this many times the compression
+	tdigestBufferFactor = 5
+)
+
+var (
+	errInvalidTDigestCompression = diceerrors.NewErr("invalid compression value provided")
+	errInvalidTDigestValue       = diceerrors.NewErr("invalid value provided")
+	errInvalidTDigestQuantile    = diceerrors.NewErr("invalid quantile value provided, must be between 0 and 1")
+	errInvalidTDigestNumKeys     = diceerrors.NewErr("invalid number of keys provided")
+
+	errTDigestKeyExists  = diceerrors.NewErr("key already exists")
+	errTDigestInvalidKey = diceerrors.NewErr("invalid key: no t-digest found")
+)
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest estimates the quantiles of a stream of values. The values are
+// summarized by centroids, sorted by mean, whose size is bounded according to
+// their quantile: centroids near the extremes are small, which keeps the
+// estimates of extreme quantiles accurate.
+type TDigest struct {
+	compression uint64
+	centroids   []centroid
+	weight      float64   // total weight of the centroids
+	buffer      []float64 // values added and not yet merged into the centroids
+	min         float64
+	max         float64
+}
+
+func newTDigest(compression uint64) *TDigest {
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// parseTDigestCompression parses the COMPRESSION argument of a t-digest.
+func parseTDigestCompression(arg string) (uint64, error) {
+	compression, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil || compression < 1 || compression > maxTDigestCompression {
+		return 0, errInvalidTDigestCompression
+	}
+	return compression, nil
+}
+
+// parseTDigestValue parses a finite value.
+func parseTDigestValue(arg string, invalid error) (float64, error) {
+	v, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, invalid
+	}
+	return v, nil
+}
+
+// add adds value to the digest.
+func (t *TDigest) add(value float64) {
+	t.buffer = append(t.buffer, value)
+	t.min, t.max = math.Min(t.min, value), math.Max(t.max, value)
+	if uint64(len(t.buffer)) >= tdigestBufferFactor*t.compression {
+		t.centroids, t.weight = t.merged()
+		t.buffer = t.buffer[:0]
+	}
+}
+
+// merged returns the centroids of the digest merged with the buffered values.
+// The digest itself is left untouched, so that reading it never modifies it.
+func (t *TDigest) merged() ([]centroid, float64) {
+	if len(t.buffer) == 0 {
+		return t.centroids, t.weight
+	}
+
+	items := make([]centroid, 0, len(t.centroids)+len(t.buffer))
+	items = append(items, t.centroids...)
+	for _, v := range t.buffer {
+		items = append(items, centroid{mean: v, weight: 1})
+	}
+	return compressCentroids(items, t.compression)
+}
+
+// compressCentroids merges adjacent centroids as long as they remain small
+// enough for their quantile, and returns the merged centroids along with their
+// total weight. It uses the scale function k(q) = compression/2π * asin(2q-1)
+// which allows at most one unit of k per centroid.
+func compressCentroids(items []centroid, compression uint64) ([]centroid, float64) {
+	if len(items) == 0 {
+		return nil, 0
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].mean < items[j].mean })
+
+	var total float64
+	for _, c := range items {
+		total += c.weight
+	}
+
+	normalizer := float64(compression) / (2 * math.Pi)
+	k := func(q float64) float64 { return normalizer * math.Asin(2*math.Min(q, 1)-1) }
+
+	merged := []centroid{items[0]}
+	var weightSoFar float64 // weight of the centroids before the last one
+	kLower := k(0)
+	for _, c := range items[1:] {
+		last := &merged[len(merged)-1]
+		if k((weightSoFar+last.weight+c.weight)/total)-kLower <= 1 {
+			last.weight += c.weight
+			last.mean += (c.mean - last.mean) * c.weight / last.weight
+			continue
+		}
+		weightSoFar += last.weight
+		kLower = k(weightSoFar / total)
+		merged = append(merged, c)
+	}
+	return merged, total
+}
+
+// quantile returns the estimated value below which the fraction q of the
+// values lies. The value is interpolated between the centers of the centroids.
+func (t *TDigest) quantile(q float64) float64 {
+	centroids, total := t.merged()
+	switch {
+	case len(centroids) == 0:
+		return math.NaN()
+	case q <= 0:
+		return t.min
+	case q >= 1:
+		return t.max
+	case len(centroids) == 1:
+		return centroids[0].mean
+	}
+
+	index := q * total
+	first := centroids[0]
+	if index < first.weight/2 {
+		return t.min + (first.mean-t.min)*index/(first.weight/2)
+	}
+
+	center := first.weight / 2 // cumulative weight at the center of the centroid
+	for i := 0; i < len(centroids)-1; i++ {
+		left, right := centroids[i], centroids[i+1]
+		next := center + (left.weight+right.weight)/2
+		if index < next {
+			return left.mean + (right.mean-left.mean)*(index-center)/(next-center)
+		}
+		center = next
+	}
+
+	last := centroids[len(centroids)-1]
+	return last.mean + (t.max-last.mean)*math.Min((index-center)/(last.weight/2), 1)
+}
+
+// cdf returns the estimated fraction of the values that are lower than or
+// equal to value.
+func (t *TDigest) cdf(value float64) float64 {
+	centroids, total := t.merged()
+	switch {
+	case len(centroids) == 0:
+		return math.NaN()
+	case value < t.min:
+		return 0
+	case value >= t.max:
+		return 1
+	case len(centroids) == 1:
+		return (value - t.min) / (t.max - t.min)
+	}
+
+	first := centroids[0]
+	if value < first.mean {
+		return (value - t.min) / (first.mean - t.min) * first.weight / 2 / total
+	}
+
+	center := first.weight / 2
+	for i := 0; i < len(centroids)-1; i++ {
+		left, right := centroids[i], centroids[i+1]
+		next := center + (left.weight+right.weight)/2
+		if value < right.mean {
+			return (center + (next-center)*(value-left.mean)/(right.mean-left.mean)) / total
+		}
+		center = next
+	}
+
+	last := centroids[len(centroids)-1]
+	return (center + last.weight/2*(value-last.mean)/(t.max-last.mean)) / total
+}
+
+// merge replaces the content of the digest with the values of sources, which
+// may include the digest itself.
+func (t *TDigest) merge(sources []*TDigest) {
+	var items []centroid
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, src := range sources {
+		centroids, _ := src.merged()
+		items = append(items, centroids...)
+		lo, hi = math.Min(lo, src.min), math.Max(hi, src.max)
+	}
+	t.centroids, t.weight = compressCentroids(items, t.compression)
+	t.buffer = nil
+	t.min, t.max = lo, hi
+}
+
+// DeepCopy creates a deep copy of the TDigest struct
+func (t *TDigest) DeepCopy() interface{} {
+	centroids := make([]centroid, len(t.centroids))
+	copy(centroids, t.centroids)
+	buffer := make([]float64, len(t.buffer))
+	copy(buffer, t.buffer)
+
+	return &TDigest{
+		compression: t.compression,
+		centroids:   centroids,
+		weight:      t.weight,
+		buffer:      buffer,
+		min:         t.min,
+		max:         t.max,
+	}
+}
+
+// formatTDigestValue formats an estimate the way the replies of the t-digest
+// commands hold them, as bulk strings.
+func formatTDigestValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "nan"
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// evalTDIGESTCREATE evaluates the TDIGEST.CREATE command responsible for
+// creating an empty t-digest. A higher compression keeps more centroids, which
+// makes the estimates more accurate.
+//
+// Usage: TDIGEST.CREATE key [COMPRESSION compression]
+func evalTDIGESTCREATE(args []string, store *dstore.Store) []byte {
+	if len(args) != 1 && len(args) != 3 {
+		return diceerrors.NewErrArity("TDIGEST.CREATE")
+	}
+
+	compression := defaultTDigestCompression
+	if len(args) == 3 {
+		if !strings.EqualFold(args[1], Compression) {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		var err error
+		if compression, err = parseTDigestCompression(args[2]); err != nil {
+			return probabilisticErr("TDIGEST.CREATE", err)
+		}
+	}
+
+	if store.Get(args[0]) != nil {
+		return probabilisticErr("TDIGEST.CREATE", errTDigestKeyExists)
+	}
+	store.Put(args[0], store.NewObj(newTDigest(compression), -1, object.ObjTypeTDigest, object.ObjEncodingTDigest))
+
+	return clientio.RespOK
+}
+
+// evalTDIGESTADD evaluates the TDIGEST.ADD command responsible for adding
+// values to a t-digest. No value is added if any of them is invalid.
+func evalTDIGESTADD(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("TDIGEST.ADD")
+	}
+
+	td, err := getTDigest(args[0], store)
+	if err != nil {
+		return probabilisticErr("TDIGEST.ADD", err)
+	}
+
+	values := make([]float64, len(args)-1)
+	for i, arg := range args[1:] {
+		if values[i], err = parseTDigestValue(arg, errInvalidTDigestValue); err != nil {
+			return probabilisticErr("TDIGEST.ADD", err)
+		}
+	}
+	for _, v := range values {
+		td.add(v)
+	}
+	return clientio.RespOK
+}
+
+// evalTDIGESTQUANTILE evaluates the TDIGEST.QUANTILE command, which returns
+// the estimated value of each of the given quantiles, or nan if the t-digest
+// is empty.
+func evalTDIGESTQUANTILE(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("TDIGEST.QUANTILE")
+	}
+
+	td, err := getTDigest(args[0], store)
+	if err != nil {
+		return probabilisticErr("TDIGEST.QUANTILE", err)
+	}
+
+	values := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		q, err := parseTDigestValue(arg, errInvalidTDigestQuantile)
+		if err != nil || q < 0 || q > 1 {
+			return probabilisticErr("TDIGEST.QUANTILE", errInvalidTDigestQuantile)
+		}
+		values[i] = formatTDigestValue(td.quantile(q))
+	}
+	return clientio.Encode(values, false)
+}
+
+// evalTDIGESTCDF evaluates the TDIGEST.CDF command, which returns for each of
+// the given values the estimated fraction of the values added that are lower
+// than or equal to it, or nan if the t-digest is empty.
+func evalTDIGESTCDF(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("TDIGEST.CDF")
+	}
+
+	td, err := getTDigest(args[0], store)
+	if err != nil {
+		return probabilisticErr("TDIGEST.CDF", err)
+	}
+
+	fractions := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		v, err := parseTDigestValue(arg, errInvalidTDigestValue)
+		if err != nil {
+			return probabilisticErr("TDIGEST.CDF", err)
+		}
+		fractions[i] = formatTDigestValue(td.cdf(v))
+	}
+	return clientio.Encode(fractions, false)
+}
+
+// evalTDIGESTMERGE evaluates the TDIGEST.MERGE command, which merges the
+// source t-digests into the destination one. The destination is created if it
+// does not exist, with the largest compression of the sources unless another
+// one is given. Otherwise its values are merged along with the ones of the
+// sources, unless OVERRIDE is given.
+//
+// Usage: TDIGEST.MERGE destination numKeys source [source ...] [COMPRESSION compression] [OVERRIDE]
+func evalTDIGESTMERGE(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("TDIGEST.MERGE")
+	}
+
+	numKeys, err := strconv.Atoi(args[1])
+	if err != nil || numKeys < 1 || numKeys > len(args)-2 {
+		return probabilisticErr("TDIGEST.MERGE", errInvalidTDigestNumKeys)
+	}
+
+	var compression uint64
+	override := false
+	for i := 2 + numKeys; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case Compression:
+			if i+1 == len(args) {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			if compression, err = parseTDigestCompression(args[i+1]); err != nil {
+				return probabilisticErr("TDIGEST.MERGE", err)
+			}
+			i++
+		case Override:
+			override = true
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	sources := make([]*TDigest, 0, numKeys+1)
+	maxCompression := uint64(0)
+	for _, key := range args[2 : 2+numKeys] {
+		src, err := getTDigest(key, store)
+		if err != nil {
+			return probabilisticErr("TDIGEST.MERGE", err)
+		}
+		sources = append(sources, src)
+		maxCompression = max(maxCompression, src.compression)
+	}
+
+	dst, err := getTDigest(args[0], store)
+	switch {
+	case err == nil:
+		if !override {
+			sources = append(sources, dst)
+		}
+		if compression != 0 {
+			dst.compression = compression
+		}
+	case err == errTDigestInvalidKey:
+		if compression == 0 {
+			compression = maxCompression
+		}
+		dst = newTDigest(compression)
+		store.Put(args[0], store.NewObj(dst, -1, object.ObjTypeTDigest, object.ObjEncodingTDigest))
+	default:
+		return probabilisticErr("TDIGEST.MERGE", err)
+	}
+
+	dst.merge(sources)
+	return clientio.RespOK
+}
+
+// getTDigest fetches an existing t-digest from the kv store.
+func getTDigest(key string, store *dstore.Store) (*TDigest, error) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, errTDigestInvalidKey
+	}
+
+	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeTDigest); err != nil {
+		return nil, errWrongType
+	}
+
+	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingTDigest); err != nil {
+		return nil, err
+	}
+
+	td, ok := obj.Value.(*TDigest)
+	if !ok {
+		return nil, errWrongType
+	}
+	return td, nil
+}
@@ -0,0 +1,106 @@
+package eval
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestTDigest(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	// TDIGEST.CREATE
+	assert.Equal(t, "-ERR syntax error\r\n", string(evalTDIGESTCREATE([]string{"td", "SIZE", "10"}, store)))
+	assert.Equal(t, "-ERR invalid compression value provided for 'TDIGEST.CREATE' command\r\n", string(evalTDIGESTCREATE([]string{"td", "COMPRESSION", "0"}, store)))
+	assert.Equal(t, string(clientio.RespOK), string(evalTDIGESTCREATE([]string{"td"}, store)))
+	assert.Equal(t, "-ERR key already exists for 'TDIGEST.CREATE' command\r\n", string(evalTDIGESTCREATE([]string{"td"}, store)))
+
+	// an empty t-digest has no quantiles
+	assert.DeepEqual(t, clientio.Encode([]string{"nan"}, false), evalTDIGESTQUANTILE([]string{"td", "0.5"}, store))
+	assert.DeepEqual(t, clientio.Encode([]string{"nan"}, false), evalTDIGESTCDF([]string{"td", "1"}, store))
+
+	// TDIGEST.ADD
+	assert.Equal(t, string(clientio.RespOK), string(evalTDIGESTADD([]string{"td", "1", "2", "3", "4", "5"}, store)))
+	assert.Equal(t, "-ERR invalid value provided for 'TDIGEST.ADD' command\r\n", string(evalTDIGESTADD([]string{"td", "6", "nan"}, store)))
+
+	// TDIGEST.QUANTILE and TDIGEST.CDF
+	assert.DeepEqual(t, clientio.Encode([]string{"1", "3", "5"}, false), evalTDIGESTQUANTILE([]string{"td", "0", "0.5", "1"}, store))
+	assert.Equal(t, "-ERR invalid quantile value provided, must be between 0 and 1 for 'TDIGEST.QUANTILE' command\r\n",
+		string(evalTDIGESTQUANTILE([]string{"td", "1.5"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]string{"0", "0.5", "1"}, false), evalTDIGESTCDF([]string{"td", "0", "3", "5"}, store))
+
+	// Commands against a missing key or a key holding the wrong kind of value
+	assert.Equal(t, "-ERR invalid key: no t-digest found for 'TDIGEST.ADD' command\r\n", string(evalTDIGESTADD([]string{"missing", "1"}, store)))
+	store.Put("str", store.NewObj("value", -1, 0, 0))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", string(evalTDIGESTCDF([]string{"str", "1"}, store)))
+}
+
+func TestTDigestAccuracy(t *testing.T) {
+	td := newTDigest(defaultTDigestCompression)
+	r := rand.New(rand.NewSource(1))
+	values := make([]float64, 100000)
+	for i := range values {
+		values[i] = r.NormFloat64()
+		td.add(values[i])
+	}
+	sort.Float64s(values)
+
+	assert.Assert(t, len(td.centroids) < 2*int(defaultTDigestCompression), len(td.centroids))
+	for _, q := range []float64{0.001, 0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+		expected := values[int(q*float64(len(values)))]
+		assert.Assert(t, math.Abs(td.quantile(q)-expected) < 0.05, "quantile %v: %v instead of %v", q, td.quantile(q), expected)
+		assert.Assert(t, math.Abs(td.cdf(expected)-q) < 0.005, "cdf of %v: %v instead of %v", expected, td.cdf(expected), q)
+	}
+}
+
+func TestTDigestMerge(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalTDIGESTCREATE([]string{"low", "COMPRESSION", "50"}, store)
+	evalTDIGESTCREATE([]string{"high", "COMPRESSION", "200"}, store)
+	for i := 1; i <= 100; i++ {
+		evalTDIGESTADD([]string{"low", strconv.Itoa(i)}, store)
+		evalTDIGESTADD([]string{"high", strconv.Itoa(100 + i)}, store)
+	}
+
+	// the destination is created with the largest compression of the sources
+	assert.Equal(t, string(clientio.RespOK), string(evalTDIGESTMERGE([]string{"dst", "2", "low", "high"}, store)))
+	dst := store.Get("dst").Value.(*TDigest)
+	assert.Equal(t, uint64(200), dst.compression)
+	assert.Equal(t, float64(200), dst.weight)
+	assert.DeepEqual(t, clientio.Encode([]string{"1", "200"}, false), evalTDIGESTQUANTILE([]string{"dst", "0", "1"}, store))
+
+	// the values of the destination are kept unless OVERRIDE is given
+	assert.Equal(t, string(clientio.RespOK), string(evalTDIGESTMERGE([]string{"dst", "1", "low", "COMPRESSION", "100"}, store)))
+	assert.Equal(t, uint64(100), dst.compression)
+	assert.Equal(t, float64(300), dst.weight)
+	assert.Equal(t, string(clientio.RespOK), string(evalTDIGESTMERGE([]string{"dst", "2", "dst", "low", "OVERRIDE"}, store)))
+	assert.Equal(t, float64(400), dst.weight)
+	assert.Equal(t, string(clientio.RespOK), string(evalTDIGESTMERGE([]string{"dst", "1", "low", "OVERRIDE"}, store)))
+	assert.Equal(t, float64(100), dst.weight)
+	assert.DeepEqual(t, clientio.Encode([]string{"100"}, false), evalTDIGESTQUANTILE([]string{"dst", "1"}, store))
+
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"missing source":       {[]string{"dst", "2", "low", "missing"}, "-ERR invalid key: no t-digest found for 'TDIGEST.MERGE' command\r\n"},
+		"invalid numkeys":      {[]string{"dst", "3", "low", "high"}, "-ERR invalid number of keys provided for 'TDIGEST.MERGE' command\r\n"},
+		"invalid compression":  {[]string{"dst", "1", "low", "COMPRESSION", "x"}, "-ERR invalid compression value provided for 'TDIGEST.MERGE' command\r\n"},
+		"missing compression":  {[]string{"dst", "1", "low", "COMPRESSION"}, "-ERR syntax error\r\n"},
+		"unknown option":       {[]string{"dst", "1", "low", "WEIGHTS"}, "-ERR syntax error\r\n"},
+		"wrong type of source": {[]string{"dst", "1", "str"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+	}
+	store.Put("str", store.NewObj("value", -1, 0, 0))
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(evalTDIGESTMERGE(tc.args, store)))
+			assert.Equal(t, float64(100), dst.weight)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package eval
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// tierKey is the key the values offloaded to the cold tier are encoded at, as
+// the tier locates its records by itself.
+const tierKey = "v"
+
+// TierCodec encodes the values offloaded to the cold tier of a store as the
+// RESP commands rebuilding them, like ExportRESP does, see dstore.ColdTier.
+// The byte arrays are kept in memory, as they would be read back as strings.
+var TierCodec = dstore.TierCodec{Encode: encodeTierValue, Decode: decodeTierValue}
+
+func encodeTierValue(obj *object.Obj) ([]byte, error) {
+	if oType, _ := object.ExtractTypeEncoding(obj); oType == object.ObjTypeByteArray {
+		return nil, nil
+	}
+
+	cmds, _, err := exportValue(tierKey, obj, 0, false)
+	if err != nil || len(cmds) == 0 {
+		return nil, err
+	}
+
+	var data []byte
+	for _, c := range cmds {
+		data = append(data, clientio.Encode(c, false)...)
+	}
+	return data, nil
+}
+
+func decodeTierValue(data []byte) (*object.Obj, error) {
+	scratch := dstore.NewStore(nil)
+	if _, err := ImportRESP(bytes.NewReader(data), scratch); err != nil {
+		return nil, err
+	}
+
+	obj := scratch.GetNoTouch(tierKey)
+	if obj == nil {
+		return nil, errors.New("no value rebuilt by the commands")
+	}
+	return obj, nil
+}
@@ -0,0 +1,71 @@
+package eval
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestTierCodec(t *testing.T) {
+	tier, err := dstore.NewColdTier(filepath.Join(t.TempDir(), "tier.dat"), TierCodec)
+	assert.NilError(t, err)
+	defer tier.Close()
+
+	store := dstore.NewStore(nil)
+	store.EnableTier(tier)
+	evalSET([]string{"str", "hello", Px, "100000"}, store)
+	evalSET([]string{"int", "42"}, store)
+	evalSETBIT([]string{"bits", "3", "1"}, store)
+	evalRPUSH([]string{"list", "a", "b", "c"}, store)
+	evalSADD([]string{"set", "x", "y"}, store)
+	evalHSET([]string{"hash", "f1", "v1", "f2", "v2"}, store)
+	evalZADD([]string{"zset", "1.5", "one", "2", "two"}, store)
+	evalJSONSET([]string{"doc", defaultRootPath, `{"a":1,"b":["x"]}`}, store)
+	exp, _ := dstore.GetExpiry(store.GetNoTouch("str"), store)
+
+	// TierHotKeys is 0, every value the codec encodes is offloaded
+	dstore.OffloadColdKeys(store)
+	stats, _ := store.TierStats()
+	assert.Equal(t, 7, stats.ColdKeys)
+
+	// the raw iterations read the cold values back
+	var buf bytes.Buffer
+	exported, err := ExportRESP(&buf, store)
+	assert.NilError(t, err)
+	assert.Equal(t, 8, exported)
+
+	assert.Equal(t, "hello", evalGET([]string{"str"}, store).Result)
+	assert.Equal(t, int64(42), evalGET([]string{"int"}, store).Result)
+	assert.DeepEqual(t, []string{"a", "b", "c"}, dequeElements(store.Get("list")))
+	assert.DeepEqual(t, map[string]struct{}{"x": {}, "y": {}}, store.Get("set").Value)
+	assert.DeepEqual(t, HashMap{"f1": "v1", "f2": "v2"}, store.Get("hash").Value)
+	assert.DeepEqual(t, map[string]float64{"one": 1.5, "two": 2},
+		store.Get("zset").Value.([]interface{})[1].(map[string]float64))
+	assertJSONEqual(t, `{"a":1,"b":["x"]}`, evalJSONGET([]string{"doc"}, store))
+
+	// the expiry is kept by the object, not by the tier
+	faultedExp, ok := dstore.GetExpiry(store.GetNoTouch("str"), store)
+	assert.Assert(t, ok)
+	assert.Equal(t, exp, faultedExp)
+
+	stats, _ = store.TierStats()
+	assert.Equal(t, 0, stats.ColdKeys)
+	assert.Equal(t, uint64(7), stats.Misses)
+}
+
+// assertJSONEqual asserts that the RESP encoded reply holds the JSON document
+// expected, whatever the order of its keys.
+func assertJSONEqual(t *testing.T, expected string, reply []byte) {
+	t.Helper()
+	value, err := clientio.NewRESPParser(bytes.NewBuffer(reply)).DecodeOne()
+	assert.NilError(t, err)
+	var want, got interface{}
+	assert.NilError(t, sonic.UnmarshalString(expected, &want))
+	assert.NilError(t, sonic.UnmarshalString(value.(string), &got))
+	assert.DeepEqual(t, want, got)
+}
@@ -0,0 +1,378 @@
+package eval
+
+import (
+	"container/heap"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hashing"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+const (
+	defaultTopKWidth uint64  = 8
+	defaultTopKDepth uint64  = 7
+	defaultTopKDecay float64 = 0.9
+
+	// seed of the generator deciding the decay of the counters
+	topkSeed uint64 = 0x9e3779b97f4a7c15
+)
+
+var (
+	errInvalidTopK           = diceerrors.NewErr("invalid topk value provided")
+	errInvalidTopKDimensions = diceerrors.NewErr("invalid width or depth value provided")
+	errInvalidTopKDecay      = diceerrors.NewErr("invalid decay value provided")
+
+	errTopKKeyExists  = diceerrors.NewErr("key already exists")
+	errTopKInvalidKey = diceerrors.NewErr("invalid key: no top-k tracker found")
+)
+
+// topkBucket is a counter of a HeavyKeeper, owned by the item whose
+// fingerprint it holds.
+type topkBucket struct {
+	fingerprint uint32
+	count       uint32
+}
+
+type topkItem struct {
+	item  string
+	count uint32
+}
+
+// topkHeap is a min-heap of the tracked items, ordered by count.
+type topkHeap []topkItem
+
+func (h topkHeap) Len() int            { return len(h) }
+func (h topkHeap) Less(i, j int) bool  { return h[i].count < h[j].count }
+func (h topkHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *topkHeap) Push(x interface{}) { *h = append(*h, x.(topkItem)) }
+func (h *topkHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// TopK tracks the k most frequent items with a HeavyKeeper: every item owns
+// one counter in each of the `depth` rows, and counters owned by other items
+// decay with a probability of decay^count instead of being incremented. Large
+// counts thus belong to frequent items, and only the k largest are tracked.
+type TopK struct {
+	k       uint64
+	width   uint64
+	depth   uint64
+	decay   float64
+	buckets []topkBucket // depth rows of width counters
+	heap    topkHeap
+	hasher  hashing.Hasher
+	// state of the generator deciding the decay of the counters. It is part of
+	// the tracker so that replicas applying the same commands decay the same
+	// counters as the primary.
+	state uint64
+}
+
+// newTopK extracts the number of items to track from `args`, followed by the
+// optional width, depth and decay of the HeavyKeeper.
+func newTopK(args []string) (*TopK, error) {
+	k, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil || k < 1 || k > math.MaxInt32 {
+		return nil, errInvalidTopK
+	}
+
+	width, depth, decay := defaultTopKWidth, defaultTopKDepth, defaultTopKDecay
+	if len(args) > 1 {
+		width, err = strconv.ParseUint(args[1], 10, 64)
+		if err != nil || width < 1 {
+			return nil, errInvalidTopKDimensions
+		}
+		depth, err = strconv.ParseUint(args[2], 10, 64)
+		if err != nil || depth < 1 {
+			return nil, errInvalidTopKDimensions
+		}
+		if hi, size := bits.Mul64(width, depth); hi != 0 || size > math.MaxInt32 {
+			return nil, errInvalidTopKDimensions
+		}
+		decay, err = strconv.ParseFloat(args[3], 64)
+		if err != nil || decay <= 0 || decay > 1 {
+			return nil, errInvalidTopKDecay
+		}
+	}
+
+	return &TopK{
+		k:       k,
+		width:   width,
+		depth:   depth,
+		decay:   decay,
+		buckets: make([]topkBucket, width*depth),
+		heap:    make(topkHeap, 0, k),
+		hasher:  sketchHasher(),
+		state:   topkSeed,
+	}, nil
+}
+
+// index returns the position of the counter of `item` in the row `row`.
+func (t *TopK) index(row uint64, item string) uint64 {
+	return row*t.width + t.hasher.Nth(row, item)%t.width
+}
+
+// fingerprint returns the fingerprint of `item` held by the counters it owns.
+func (t *TopK) fingerprint(item string) uint32 {
+	return uint32(t.hasher.Sum64(item) >> 32)
+}
+
+// random returns a pseudo-random number in [0, 1).
+func (t *TopK) random() float64 {
+	// splitmix64
+	t.state += 0x9e3779b97f4a7c15
+	z := t.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11) / (1 << 53)
+}
+
+// add counts one occurrence of `item`. If the item enters the top-k and
+// another one leaves it, the latter is returned.
+func (t *TopK) add(item string) (expelled string, ok bool) {
+	fp := t.fingerprint(item)
+
+	var count uint32
+	for row := uint64(0); row < t.depth; row++ {
+		b := &t.buckets[t.index(row, item)]
+		switch {
+		case b.count == 0:
+			b.fingerprint, b.count = fp, 1
+		case b.fingerprint == fp:
+			if b.count < math.MaxUint32 {
+				b.count++
+			}
+		default:
+			if t.random() < math.Pow(t.decay, float64(b.count)) {
+				b.count--
+				if b.count == 0 {
+					b.fingerprint, b.count = fp, 1
+				}
+			}
+		}
+		if b.fingerprint == fp {
+			count = max(count, b.count)
+		}
+	}
+
+	if i := t.position(item); i >= 0 {
+		t.heap[i].count = max(t.heap[i].count, count)
+		heap.Fix(&t.heap, i)
+		return "", false
+	}
+	if uint64(len(t.heap)) < t.k {
+		heap.Push(&t.heap, topkItem{item: item, count: count})
+		return "", false
+	}
+	if count > t.heap[0].count {
+		expelled = t.heap[0].item
+		t.heap[0] = topkItem{item: item, count: count}
+		heap.Fix(&t.heap, 0)
+		return expelled, true
+	}
+	return "", false
+}
+
+// position returns the position of `item` in the heap, or -1 if it is not
+// tracked.
+func (t *TopK) position(item string) int {
+	for i := range t.heap {
+		if t.heap[i].item == item {
+			return i
+		}
+	}
+	return -1
+}
+
+// count returns the estimated count of `item`.
+func (t *TopK) count(item string) uint32 {
+	fp := t.fingerprint(item)
+
+	var count uint32
+	for row := uint64(0); row < t.depth; row++ {
+		if b := t.buckets[t.index(row, item)]; b.fingerprint == fp {
+			count = max(count, b.count)
+		}
+	}
+	return count
+}
+
+// list returns the tracked items, the most frequent first.
+func (t *TopK) list() []topkItem {
+	items := make([]topkItem, len(t.heap))
+	copy(items, t.heap)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].count != items[j].count {
+			return items[i].count > items[j].count
+		}
+		return items[i].item < items[j].item
+	})
+	return items
+}
+
+// DeepCopy creates a deep copy of the TopK struct
+func (t *TopK) DeepCopy() interface{} {
+	buckets := make([]topkBucket, len(t.buckets))
+	copy(buckets, t.buckets)
+	h := make(topkHeap, len(t.heap), cap(t.heap))
+	copy(h, t.heap)
+
+	return &TopK{
+		k:       t.k,
+		width:   t.width,
+		depth:   t.depth,
+		decay:   t.decay,
+		buckets: buckets,
+		heap:    h,
+		state:   t.state,
+		hasher:  t.hasher,
+	}
+}
+
+// evalTOPKRESERVE evaluates the TOPK.RESERVE command responsible for creating
+// a tracker of the k most frequent items.
+//
+// Usage: TOPK.RESERVE key topk [width depth decay]
+func evalTOPKRESERVE(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 && len(args) != 5 {
+		return diceerrors.NewErrArity("TOPK.RESERVE")
+	}
+
+	topk, err := newTopK(args[1:])
+	if err != nil {
+		return probabilisticErr("TOPK.RESERVE", err)
+	}
+
+	if store.Get(args[0]) != nil {
+		return probabilisticErr("TOPK.RESERVE", errTopKKeyExists)
+	}
+	store.Put(args[0], store.NewObj(topk, -1, object.ObjTypeTopK, object.ObjEncodingTopK))
+
+	return clientio.RespOK
+}
+
+// evalTOPKADD evaluates the TOPK.ADD command responsible for adding items to
+// a top-k tracker. It returns, for each item, the item expelled from the
+// top-k by its addition, or nil.
+func evalTOPKADD(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("TOPK.ADD")
+	}
+
+	topk, err := getTopK(args[0], store)
+	if err != nil {
+		return probabilisticErr("TOPK.ADD", err)
+	}
+
+	expelled := make([]interface{}, len(args)-1)
+	for i, item := range args[1:] {
+		if e, ok := topk.add(item); ok {
+			expelled[i] = e
+		}
+	}
+	return clientio.Encode(expelled, false)
+}
+
+// evalTOPKQUERY evaluates the TOPK.QUERY command, which returns for each item
+// 1 if it is one of the top-k items, 0 otherwise.
+func evalTOPKQUERY(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("TOPK.QUERY")
+	}
+
+	topk, err := getTopK(args[0], store)
+	if err != nil {
+		return probabilisticErr("TOPK.QUERY", err)
+	}
+
+	found := make([]interface{}, len(args)-1)
+	for i, item := range args[1:] {
+		found[i] = 0
+		if topk.position(item) >= 0 {
+			found[i] = 1
+		}
+	}
+	return clientio.Encode(found, false)
+}
+
+// evalTOPKCOUNT evaluates the TOPK.COUNT command, which returns the estimated
+// count of each of the given items.
+func evalTOPKCOUNT(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("TOPK.COUNT")
+	}
+
+	topk, err := getTopK(args[0], store)
+	if err != nil {
+		return probabilisticErr("TOPK.COUNT", err)
+	}
+
+	counts := make([]interface{}, len(args)-1)
+	for i, item := range args[1:] {
+		counts[i] = topk.count(item)
+	}
+	return clientio.Encode(counts, false)
+}
+
+// evalTOPKLIST evaluates the TOPK.LIST command, which returns the top-k
+// items, the most frequent first, along with their counts if WITHCOUNT is
+// given.
+//
+// Usage: TOPK.LIST key [WITHCOUNT]
+func evalTOPKLIST(args []string, store *dstore.Store) []byte {
+	if len(args) != 1 && len(args) != 2 {
+		return diceerrors.NewErrArity("TOPK.LIST")
+	}
+
+	withCount := len(args) == 2
+	if withCount && !strings.EqualFold(args[1], WithCount) {
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+
+	topk, err := getTopK(args[0], store)
+	if err != nil {
+		return probabilisticErr("TOPK.LIST", err)
+	}
+
+	items := topk.list()
+	result := make([]interface{}, 0, 2*len(items))
+	for _, item := range items {
+		result = append(result, item.item)
+		if withCount {
+			result = append(result, item.count)
+		}
+	}
+	return clientio.Encode(result, false)
+}
+
+// getTopK fetches an existing top-k tracker from the kv store.
+func getTopK(key string, store *dstore.Store) (*TopK, error) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, errTopKInvalidKey
+	}
+
+	if err := object.AssertType(obj.TypeEncoding, object.ObjTypeTopK); err != nil {
+		return nil, errWrongType
+	}
+
+	if err := object.AssertEncoding(obj.TypeEncoding, object.ObjEncodingTopK); err != nil {
+		return nil, err
+	}
+
+	topk, ok := obj.Value.(*TopK)
+	if !ok {
+		return nil, errWrongType
+	}
+	return topk, nil
+}
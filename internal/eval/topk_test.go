@@ -0,0 +1,86 @@
+package eval
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestTopK(t *testing.T) {
+	store := dstore.NewStore(nil)
+
+	// TOPK.RESERVE
+	assert.Equal(t, "-ERR wrong number of arguments for 'topk.reserve' command\r\n", string(evalTOPKRESERVE([]string{"topk", "3", "8"}, store)))
+	assert.Equal(t, "-ERR invalid topk value provided for 'TOPK.RESERVE' command\r\n", string(evalTOPKRESERVE([]string{"topk", "0"}, store)))
+	assert.Equal(t, "-ERR invalid decay value provided for 'TOPK.RESERVE' command\r\n", string(evalTOPKRESERVE([]string{"topk", "3", "8", "7", "1.5"}, store)))
+	assert.Equal(t, string(clientio.RespOK), string(evalTOPKRESERVE([]string{"topk", "2", "50", "4", "0.9"}, store)))
+	assert.Equal(t, "-ERR key already exists for 'TOPK.RESERVE' command\r\n", string(evalTOPKRESERVE([]string{"topk", "2"}, store)))
+
+	// TOPK.ADD returns the items expelled from the top-k
+	assert.DeepEqual(t, clientio.Encode([]interface{}{nil, nil, nil}, false), evalTOPKADD([]string{"topk", "a", "a", "b"}, store))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{nil, "b", nil}, false), evalTOPKADD([]string{"topk", "c", "c", "c"}, store))
+
+	// TOPK.QUERY, TOPK.COUNT and TOPK.LIST
+	assert.DeepEqual(t, clientio.Encode([]interface{}{1, 0, 1}, false), evalTOPKQUERY([]string{"topk", "a", "b", "c"}, store))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint32(2), uint32(1), uint32(3), uint32(0)}, false),
+		evalTOPKCOUNT([]string{"topk", "a", "b", "c", "d"}, store))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{"c", "a"}, false), evalTOPKLIST([]string{"topk"}, store))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{"c", uint32(3), "a", uint32(2)}, false), evalTOPKLIST([]string{"topk", "WITHCOUNT"}, store))
+	assert.Equal(t, "-ERR syntax error\r\n", string(evalTOPKLIST([]string{"topk", "WITHSCORES"}, store)))
+
+	// Commands against a missing key or a key holding the wrong kind of value
+	assert.Equal(t, "-ERR invalid key: no top-k tracker found for 'TOPK.ADD' command\r\n", string(evalTOPKADD([]string{"missing", "a"}, store)))
+	store.Put("str", store.NewObj("value", -1, 0, 0))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", string(evalTOPKLIST([]string{"str"}, store)))
+}
+
+func TestTopKHeavyHitters(t *testing.T) {
+	topk, err := newTopK([]string{"5", "100", "5", "0.9"})
+	assert.NilError(t, err)
+
+	// 5 heavy hitters among many items seen a few times
+	for round := 0; round < 50; round++ {
+		for i := 0; i < 5; i++ {
+			topk.add("heavy" + strconv.Itoa(i))
+		}
+		for i := 0; i < 20; i++ {
+			topk.add("light" + strconv.Itoa(round*20+i))
+		}
+	}
+
+	items := topk.list()
+	assert.Equal(t, 5, len(items))
+	for _, item := range items {
+		assert.Assert(t, item.item[:5] == "heavy", item.item)
+		assert.Assert(t, item.count <= 50)
+	}
+
+	// the decay is deterministic, so that a copy fed with the same items ends
+	// up in the same state
+	cp := topk.DeepCopy().(*TopK)
+	for i := 0; i < 100; i++ {
+		topk.add("item" + strconv.Itoa(i%7))
+		cp.add("item" + strconv.Itoa(i%7))
+	}
+	assert.Assert(t, reflect.DeepEqual(topk, cp))
+}
+
+func TestTopKCopy(t *testing.T) {
+	saved := config.DiceConfig.Server
+	defer func() { config.DiceConfig.Server = saved }()
+	config.DiceConfig.Server.SketchHashFamily = "xxhash"
+	config.DiceConfig.Server.SketchHashSeed = 7
+
+	// the copy hashes the items like the tracker it is copied from
+	store := dstore.NewStore(nil)
+	evalTOPKRESERVE([]string{"topk", "2"}, store)
+	evalTOPKADD([]string{"topk", "a", "a", "a"}, store)
+	assert.Equal(t, string(clientio.RespOne), string(evalCOPY([]string{"topk", "copy"}, store)))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{"a", uint32(3)}, false), evalTOPKLIST([]string{"copy", "WITHCOUNT"}, store))
+	assert.DeepEqual(t, clientio.Encode([]interface{}{uint32(3)}, false), evalTOPKCOUNT([]string{"copy", "a"}, store))
+}
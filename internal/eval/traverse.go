@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"math"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The traversals below let the embedders walk the collections in place, e.g. for
+// analytic jobs over huge structures: the elements are passed to fn as they are
+// found, without building any result, till fn returns false. They run against
+// the store they are given, so fn must neither block nor modify the key. A key
+// that does not exist is an empty collection, whereas a key holding another type
+// fails with diceerrors.ErrWrongTypeOperation.
+
+// ZRangeByScoreFunc calls fn with the members of the sorted set stored at key
+// with a score between min and max, both inclusive, by ascending scores.
+func ZRangeByScoreFunc(store *dstore.Store, key string, min, max float64, fn func(member string, score float64) bool) error {
+	obj := store.Get(key)
+	if obj == nil || math.IsNaN(min) || math.IsNaN(max) {
+		return nil
+	}
+	tree, _, errResp := getSortedSet(obj)
+	if errResp != nil {
+		return diceerrors.ErrWrongTypeOperation
+	}
+
+	scoreRange(tree, scoreBound{score: min}, scoreBound{score: max}, func(item *SortedSetItem) bool {
+		return fn(item.Member, item.Score)
+	})
+	return nil
+}
+
+// HScanFunc calls fn with the fields of the hash stored at key along with their
+// values, in no particular order.
+func HScanFunc(store *dstore.Store, key string, fn func(field, value string) bool) error {
+	obj, errResp := getHashForFields(key, store)
+	if errResp != nil {
+		return diceerrors.ErrWrongTypeOperation
+	}
+	if obj == nil {
+		return nil
+	}
+
+	for field, value := range obj.Value.(HashMap) {
+		if !fn(field, value) {
+			break
+		}
+	}
+	return nil
+}
+
+// LRangeFunc calls fn with the elements of the list stored at key, from head to
+// tail, along with their index.
+func LRangeFunc(store *dstore.Store, key string, fn func(index int, element string) bool) error {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil
+	}
+	if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeByteList, object.ObjEncodingDeque); err != nil {
+		return diceerrors.ErrWrongTypeOperation
+	}
+
+	index := 0
+	obj.Value.(*Deque).Iterate(func(x string) bool {
+		index++
+		return fn(index-1, x)
+	})
+	return nil
+}
+
+// SScanFunc calls fn with the members of the set stored at key, in no particular
+// order.
+func SScanFunc(store *dstore.Store, key string, fn func(member string) bool) error {
+	set, errResp := getSet(key, store)
+	if errResp != nil {
+		return diceerrors.ErrWrongTypeOperation
+	}
+
+	for member := range set {
+		if !fn(member) {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"sort"
+	"testing"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestZRangeByScoreFunc(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalZADD([]string{"z", "1", "a", "2", "b", "2", "c", "3", "d"}, store)
+	evalSET([]string{"str", "value"}, store)
+
+	var members []string
+	var scores []float64
+	err := ZRangeByScoreFunc(store, "z", 2, 3, func(member string, score float64) bool {
+		members, scores = append(members, member), append(scores, score)
+		return true
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"b", "c", "d"}, members)
+	assert.DeepEqual(t, []float64{2, 2, 3}, scores)
+
+	// the traversal stops as soon as fn returns false
+	members = nil
+	err = ZRangeByScoreFunc(store, "z", 0, 10, func(member string, _ float64) bool {
+		members = append(members, member)
+		return len(members) < 2
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"a", "b"}, members)
+
+	called := false
+	assert.NilError(t, ZRangeByScoreFunc(store, "missing", 0, 10, func(string, float64) bool { called = true; return true }))
+	assert.Assert(t, !called)
+	assert.Equal(t, diceerrors.ErrWrongTypeOperation, ZRangeByScoreFunc(store, "str", 0, 10, func(string, float64) bool { return true }))
+}
+
+func TestCollectionTraversals(t *testing.T) {
+	store := dstore.NewStore(nil)
+	evalHSET([]string{"h", "f1", "v1", "f2", "v2"}, store)
+	evalRPUSH([]string{"l", "a", "b", "c"}, store)
+	evalSADD([]string{"s", "x", "y"}, store)
+	evalSET([]string{"str", "value"}, store)
+
+	var fields []string
+	assert.NilError(t, HScanFunc(store, "h", func(field, value string) bool {
+		fields = append(fields, field+"="+value)
+		return true
+	}))
+	sort.Strings(fields)
+	assert.DeepEqual(t, []string{"f1=v1", "f2=v2"}, fields)
+
+	var elements []string
+	assert.NilError(t, LRangeFunc(store, "l", func(index int, element string) bool {
+		assert.Equal(t, len(elements), index)
+		elements = append(elements, element)
+		return index < 1
+	}))
+	assert.DeepEqual(t, []string{"a", "b"}, elements)
+
+	var members []string
+	assert.NilError(t, SScanFunc(store, "s", func(member string) bool {
+		members = append(members, member)
+		return true
+	}))
+	sort.Strings(members)
+	assert.DeepEqual(t, []string{"x", "y"}, members)
+
+	assert.NilError(t, SScanFunc(store, "missing", func(string) bool { return false }))
+	assert.Equal(t, diceerrors.ErrWrongTypeOperation, HScanFunc(store, "str", func(string, string) bool { return true }))
+	assert.Equal(t, diceerrors.ErrWrongTypeOperation, LRangeFunc(store, "s", func(int, string) bool { return true }))
+	assert.Equal(t, diceerrors.ErrWrongTypeOperation, SScanFunc(store, "l", func(string) bool { return true }))
+}
@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// evalTTLJOB is the container command of the TTL jobs, setting or removing
+// the expiry of the keys matching a pattern in the background, see
+// dstore.StartTTLJob.
+// TTLJOB START pattern seconds|PERSIST [BATCH count] [RATE keys-per-second]
+// starts a job and returns its id.
+// TTLJOB STATUS [id] returns the status of the job id as name and value pairs,
+// or the status of every job.
+// TTLJOB CANCEL id stops the job id, returning 1 if it was running and 0
+// otherwise.
+func evalTTLJOB(args []string, store *dstore.Store) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("TTLJOB")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case Start:
+		return evalTTLJOBStart(args[1:], store)
+	case Status:
+		if len(args) > 2 {
+			return diceerrors.NewErrArity("TTLJOB|STATUS")
+		}
+		jobs := store.TTLJobs()
+		if len(args) == 1 {
+			statuses := make([]interface{}, len(jobs))
+			for i := range jobs {
+				statuses[i] = ttlJobStatus(&jobs[i])
+			}
+			return clientio.Encode(statuses, false)
+		}
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		for i := range jobs {
+			if jobs[i].ID == id {
+				return clientio.Encode(ttlJobStatus(&jobs[i]), false)
+			}
+		}
+		return diceerrors.NewErrWithFormattedMessage("no such TTL job %d", id)
+	case Cancel:
+		if len(args) != 2 {
+			return diceerrors.NewErrArity("TTLJOB|CANCEL")
+		}
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if store.CancelTTLJob(id) {
+			return clientio.RespOne
+		}
+		return clientio.RespZero
+	case Help:
+		return commandHelp("TTLJOB")
+	default:
+		return diceerrors.NewErrWithFormattedMessage("unknown subcommand '%s'. Try TTLJOB HELP.", args[0])
+	}
+}
+
+// evalTTLJOBStart starts a TTL job.
+//
+// Usage: TTLJOB START pattern seconds|PERSIST [BATCH count] [RATE keys-per-second]
+func evalTTLJOBStart(args []string, store *dstore.Store) []byte {
+	if len(args) < 2 {
+		return diceerrors.NewErrArity("TTLJOB|START")
+	}
+
+	pattern, ttlSec := args[0], dstore.PersistTTL
+	if !strings.EqualFold(args[1], Persist) {
+		sec, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		if sec <= 0 || sec > maxExDuration {
+			return diceerrors.NewErrExpireTime("TTLJOB|START")
+		}
+		ttlSec = sec
+	}
+
+	batch, rate := dstore.DefaultTTLJobBatch, dstore.DefaultTTLJobRate
+	for i := 2; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n <= 0 {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		switch strings.ToUpper(args[i]) {
+		case Batch:
+			batch = n
+		case Rate:
+			rate = n
+		default:
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+	}
+
+	return clientio.Encode(store.StartTTLJob(pattern, ttlSec, batch, rate), false)
+}
+
+// ttlJobStatus returns the status of job as name and value pairs.
+func ttlJobStatus(job *dstore.TTLJob) []interface{} {
+	ttl := strconv.FormatInt(job.TTLSec, 10)
+	if job.TTLSec == dstore.PersistTTL {
+		ttl = Persist
+	}
+	return []interface{}{
+		"id", job.ID,
+		"pattern", job.Pattern,
+		"ttl", ttl,
+		"batch", job.Batch,
+		"rate", job.Rate,
+		"state", string(job.State),
+		"started", job.StartedAt.Unix(),
+		"total", job.Total,
+		"processed", job.Processed,
+		"updated", job.Updated,
+	}
+}
@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestTTLJOB(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	store := dstore.NewStore(nil)
+	evalSET([]string{"tmp:1", "v"}, store)
+	evalSET([]string{"tmp:2", "v", "EX", "10"}, store)
+	evalSET([]string{"keep", "v"}, store)
+
+	assert.Equal(t, string(clientio.Encode(1, false)), string(evalTTLJOB([]string{"start", "tmp:*", "100", "BATCH", "10", "RATE", "50"}, store)))
+	mockTime.SetTime(time.Unix(1001, 0))
+	dstore.RunTTLJobs(store)
+	assert.Equal(t, string(clientio.Encode(100, false)), string(evalTTL([]string{"tmp:1"}, store)))
+	assert.Equal(t, string(clientio.Encode(100, false)), string(evalTTL([]string{"tmp:2"}, store)))
+	assert.Equal(t, string(clientio.RespMinusOne), string(evalTTL([]string{"keep"}, store)))
+
+	status := []interface{}{
+		"id", uint64(1), "pattern", "tmp:*", "ttl", "100", "batch", 10, "rate", 50,
+		"state", "done", "started", int64(1000), "total", 2, "processed", 2, "updated", 2,
+	}
+	assert.Equal(t, string(clientio.Encode(status, false)), string(evalTTLJOB([]string{"STATUS", "1"}, store)))
+	assert.Equal(t, string(clientio.Encode([]interface{}{status}, false)), string(evalTTLJOB([]string{"STATUS"}, store)))
+	assert.Equal(t, string(clientio.RespZero), string(evalTTLJOB([]string{"CANCEL", "1"}, store)))
+
+	// PERSIST removes the expiries
+	assert.Equal(t, string(clientio.Encode(2, false)), string(evalTTLJOB([]string{"START", "tmp:*", "persist"}, store)))
+	mockTime.SetTime(time.Unix(1002, 0))
+	dstore.RunTTLJobs(store)
+	assert.Equal(t, string(clientio.RespMinusOne), string(evalTTL([]string{"tmp:1"}, store)))
+
+	tests := []struct {
+		args []string
+		want []byte
+	}{
+		{[]string{"START", "tmp:*"}, diceerrors.NewErrArity("TTLJOB|START")},
+		{[]string{"START", "tmp:*", "0"}, diceerrors.NewErrExpireTime("TTLJOB|START")},
+		{[]string{"START", "tmp:*", "ten"}, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)},
+		{[]string{"START", "tmp:*", "10", "RATE"}, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)},
+		{[]string{"START", "tmp:*", "10", "RATE", "0"}, diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)},
+		{[]string{"START", "tmp:*", "10", "SPEED", "1"}, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)},
+		{[]string{"STATUS", "9"}, diceerrors.NewErrWithMessage("no such TTL job 9")},
+		{[]string{"CANCEL"}, diceerrors.NewErrArity("TTLJOB|CANCEL")},
+		{[]string{"PAUSE"}, diceerrors.NewErrWithMessage("unknown subcommand 'PAUSE'. Try TTLJOB HELP.")},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, string(tt.want), string(evalTTLJOB(tt.args, store)), tt.args)
+	}
+}
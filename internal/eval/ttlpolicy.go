@@ -0,0 +1,97 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// evalConfigDefaultTTL manages the default TTL policies, see dstore.TTLPolicies.
+// CONFIG DEFAULTTTL SET pattern ttl gives the keys matching pattern an expiry
+// of ttl, a duration such as 30m, once put without one.
+// CONFIG DEFAULTTTL DEL pattern removes the policy of pattern, returning 1 if
+// it was removed and 0 if there was no such policy.
+// CONFIG DEFAULTTTL LIST returns the pattern, the TTL in milliseconds and the
+// number of keys given an expiry of every policy, in the order they are matched.
+func evalConfigDefaultTTL(args []string) []byte {
+	if len(args) == 0 {
+		return diceerrors.NewErrArity("CONFIG|DEFAULTTTL")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case Set:
+		if len(args) != 3 {
+			return diceerrors.NewErrArity("CONFIG|DEFAULTTTL")
+		}
+		ttl, err := dstore.ParseTTL(args[2])
+		if err != nil {
+			return diceerrors.NewErrWithMessage(err.Error())
+		}
+		dstore.DefaultTTLs.Set(args[1], ttl)
+		return clientio.RespOK
+	case Del:
+		if len(args) != 2 {
+			return diceerrors.NewErrArity("CONFIG|DEFAULTTTL")
+		}
+		if dstore.DefaultTTLs.Del(args[1]) {
+			return clientio.RespOne
+		}
+		return clientio.RespZero
+	case List:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("CONFIG|DEFAULTTTL")
+		}
+		policies := dstore.DefaultTTLs.List()
+		reply := make([]interface{}, len(policies))
+		for i, policy := range policies {
+			reply[i] = []interface{}{policy.Pattern, policy.TTL.Milliseconds(), policy.Applied()}
+		}
+		return clientio.Encode(reply, false)
+	default:
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+}
+
+// ValidateConfig checks the settings of c the config package knows nothing of
+// the values of: the reply compatibility version, the revoke policy and the
+// default TTL policies. It is run on the config read at startup, the invalid
+// settings then falling back to their default, and on every reload, an invalid
+// config being rejected.
+func ValidateConfig(c *config.Config) error {
+	var errs []error
+	if !IsReplyCompat(c.Server.ReplyCompat) {
+		errs = append(errs, fmt.Errorf("unknown reply compatibility version %q", c.Server.ReplyCompat))
+	}
+	if p := c.Auth.RevokePolicy; !strings.EqualFold(p, auth.RevokeKill) && !strings.EqualFold(p, auth.RevokeDowngrade) {
+		errs = append(errs, fmt.Errorf("unknown revoke policy %q", p))
+	}
+	for _, entry := range c.Server.DefaultTTLs {
+		if _, err := dstore.ParseTTLPolicy(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReloadConfig reloads the config file, see config.Reload, checking it with
+// ValidateConfig, and loads the default TTL policies again if they changed in
+// the file.
+func ReloadConfig() (config.ReloadResult, error) {
+	res, err := config.Reload(ValidateConfig)
+	if err != nil {
+		return res, err
+	}
+	if slices.Contains(res.Applied, "server.defaultttls") {
+		if err := dstore.DefaultTTLs.Load(config.Current().Server.DefaultTTLs); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+func TestConfigDefaultTTL(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+	defer func() { assert.NilError(t, dstore.DefaultTTLs.Load(nil)) }()
+
+	store := dstore.NewStore(nil)
+	assert.Equal(t, string(evalCONFIG([]string{"DEFAULTTTL", "SET", "session:*", "30m"}, store)), string(clientio.RespOK))
+	assert.Equal(t, string(evalCONFIG([]string{"defaultttl", "set", "tmp:*", "1500ms"}, store)), string(clientio.RespOK))
+
+	// the keys created by any command get the default TTL
+	evalSET([]string{"session:1", "v"}, store)
+	evalHSET([]string{"session:2", "f", "v"}, store)
+	evalINCR([]string{"tmp:counter"}, store)
+	evalSET([]string{"user:1", "v"}, store)
+	assert.Equal(t, string(evalTTL([]string{"session:1"}, store)), string(clientio.Encode(1800, false)))
+	assert.Equal(t, string(evalTTL([]string{"session:2"}, store)), string(clientio.Encode(1800, false)))
+	assert.Equal(t, string(evalPTTL([]string{"tmp:counter"}, store)), string(clientio.Encode(1500, false)))
+	assert.Equal(t, string(evalTTL([]string{"user:1"}, store)), string(clientio.RespMinusOne))
+
+	// an expiry given by the command wins
+	evalSET([]string{"session:3", "v", "EX", "10"}, store)
+	assert.Equal(t, string(evalTTL([]string{"session:3"}, store)), string(clientio.Encode(10, false)))
+
+	list := []interface{}{
+		[]interface{}{"session:*", int64(1800000), int64(2)},
+		[]interface{}{"tmp:*", int64(1500), int64(1)},
+	}
+	assert.Equal(t, string(evalCONFIG([]string{"DEFAULTTTL", "LIST"}, store)), string(clientio.Encode(list, false)))
+	info := string(evalINFO(nil, store))
+	assert.Assert(t, strings.Contains(info, "default_ttl_policies:2\r\npolicy0:pattern=session:*,ttl_ms=1800000,applied=2\r\n"), info)
+
+	assert.Equal(t, string(evalCONFIG([]string{"DEFAULTTTL", "DEL", "tmp:*"}, store)), string(clientio.RespOne))
+	assert.Equal(t, string(evalCONFIG([]string{"DEFAULTTTL", "DEL", "tmp:*"}, store)), string(clientio.RespZero))
+	evalINCR([]string{"tmp:other"}, store)
+	assert.Equal(t, string(evalTTL([]string{"tmp:other"}, store)), string(clientio.RespMinusOne))
+
+	tests := []struct {
+		args []string
+		want []byte
+	}{
+		{[]string{"DEFAULTTTL"}, diceerrors.NewErrArity("CONFIG|DEFAULTTTL")},
+		{[]string{"DEFAULTTTL", "SET", "k*"}, diceerrors.NewErrArity("CONFIG|DEFAULTTTL")},
+		{[]string{"DEFAULTTTL", "SET", "k*", "10"}, diceerrors.NewErrWithMessage(`invalid default TTL "10", expected a duration of 1ms at least`)},
+		{[]string{"DEFAULTTTL", "SET", "k*", "-1s"}, diceerrors.NewErrWithMessage(`invalid default TTL "-1s", expected a duration of 1ms at least`)},
+		{[]string{"DEFAULTTTL", "LIST", "k*"}, diceerrors.NewErrArity("CONFIG|DEFAULTTTL")},
+		{[]string{"DEFAULTTTL", "GET"}, diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, string(evalCONFIG(tt.args, store)), string(tt.want), tt.args)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	c := *config.DiceConfig
+	c.Server.ReplyCompat = CompatRedis7
+	c.Auth.RevokePolicy = "Downgrade"
+	c.Server.DefaultTTLs = []string{"session:*=30m"}
+	assert.NilError(t, ValidateConfig(&c))
+
+	c.Server.ReplyCompat = "redis-2"
+	c.Auth.RevokePolicy = "close"
+	c.Server.DefaultTTLs = []string{"session:*=30m", "tmp:*"}
+	err := ValidateConfig(&c)
+	assert.ErrorContains(t, err, `unknown reply compatibility version "redis-2"`)
+	assert.ErrorContains(t, err, `unknown revoke policy "close"`)
+	assert.ErrorContains(t, err, `invalid default TTL policy "tmp:*"`)
+}
+
+func TestDefaultTTLRelocation(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+	defer func() { assert.NilError(t, dstore.DefaultTTLs.Load(nil)) }()
+	assert.NilError(t, dstore.DefaultTTLs.Load([]string{"session:*=30m"}))
+
+	dbs := dstore.NewDatabases(2, func() *dstore.Store { return dstore.NewStore(nil) })
+	store := dbs.Get(0)
+	exec := func(args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: args[0], Args: args[1:]}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	// the keys moved or restored keep the expiry they had, none here
+	exec("MSET", "user:1", "v")
+	assert.Assert(t, store.Rename("user:1", "session:1"))
+	assert.Equal(t, exec("MOVE", "session:1", "1"), string(clientio.RespOne))
+	assert.Equal(t, string(evalTTL([]string{"session:1"}, dbs.Get(1))), string(clientio.RespMinusOne))
+
+	_, err := ImportRESP(strings.NewReader("*3\r\n$3\r\nSET\r\n$9\r\nsession:2\r\n$1\r\nv\r\n"), store)
+	assert.NilError(t, err)
+	assert.Equal(t, exec("TTL", "session:2"), string(clientio.RespMinusOne))
+
+	// the keys written still get the default TTL
+	exec("MSET", "session:3", "v")
+	assert.Equal(t, exec("TTL", "session:3"), string(clientio.Encode(1800, false)))
+}
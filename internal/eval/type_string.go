@@ -6,14 +6,32 @@ import (
 	dstore "github.com/dicedb/dice/internal/object"
 )
 
+// maxEmbStrLen is the length of the longest strings stored with the embstr
+// encoding.
+const maxEmbStrLen = 44
+
 // Similar to
 // tryObjectEncoding function in Redis
 func deduceTypeEncoding(v string) (o, e uint8) {
 	if _, err := strconv.ParseInt(v, 10, 64); err == nil {
 		return dstore.ObjTypeInt, dstore.ObjEncodingInt
 	}
-	if len(v) <= 44 {
+	if len(v) <= maxEmbStrLen {
 		return dstore.ObjTypeString, dstore.ObjEncodingEmbStr
 	}
 	return dstore.ObjTypeString, dstore.ObjEncodingRaw
 }
+
+// isStringObj returns true if obj holds a value the string commands operate
+// on: a string, an integer or a bitmap. The HyperLogLogs, though strings, do
+// not expose their bytes.
+func isStringObj(obj *dstore.Obj) bool {
+	switch oType, _ := dstore.ExtractTypeEncoding(obj); oType {
+	case dstore.ObjTypeString:
+		_, ok := obj.Value.(string)
+		return ok
+	case dstore.ObjTypeInt, dstore.ObjTypeByteArray:
+		return true
+	}
+	return false
+}
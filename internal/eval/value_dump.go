@@ -0,0 +1,337 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/btree"
+
+	"github.com/dicedb/dice/internal/hashing"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// The values with no command representation, the streams along with their
+// consumer groups and the cuckoo filters, count-min sketches, top-k trackers
+// and t-digests, are exported to the snapshots as SNAPSHOT.VALUE commands
+// carrying their type and a binary dump of their state, so that the replicas
+// restore them exactly. The dumps are laid out in big endian, the integers on
+// 8 bytes, the floats as their IEEE 754 bits and the strings and the lists
+// prefixed with their length:
+//
+//	cuckoo:  family seed capacity bucketsize maxiterations buckets items deletes slots (2 bytes each)
+//	cms:     family seed width depth count counters
+//	topk:    family seed k width depth decay state buckets (fingerprint and count, 4 bytes each) items (item count)
+//	tdigest: compression weight min max centroids (mean weight) buffer
+//	stream:  lastid entries (id fields) groups (name lastdeliveredid consumers (name seenat pending) pending (id consumer deliveredat deliveries))
+//
+// The IDs of the streams are their milliseconds followed by their sequence
+// number.
+
+var errInvalidDump = errors.New("invalid value dump")
+
+// dumpWriter writes the fields of a dump. The writes to a buffer never fail.
+type dumpWriter struct {
+	bytes.Buffer
+}
+
+func (w *dumpWriter) uint(v uint64) {
+	_ = binary.Write(&w.Buffer, binary.BigEndian, v)
+}
+
+func (w *dumpWriter) float(v float64) {
+	w.uint(math.Float64bits(v))
+}
+
+func (w *dumpWriter) string(s string) {
+	w.uint(uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (w *dumpWriter) hasher(h hashing.Hasher) {
+	w.uint(uint64(h.Family))
+	w.uint(h.Seed)
+}
+
+func (w *dumpWriter) streamID(id StreamID) {
+	w.uint(id.Ms)
+	w.uint(id.Seq)
+}
+
+// dumpReader reads the fields of a dump. The first error is kept, the fields
+// read after it being zero.
+type dumpReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *dumpReader) uint() uint64 {
+	var v uint64
+	if r.err == nil && binary.Read(r.r, binary.BigEndian, &v) != nil {
+		r.err = errInvalidDump
+	}
+	return v
+}
+
+func (r *dumpReader) float() float64 {
+	return math.Float64frombits(r.uint())
+}
+
+// len reads the length of a list whose elements take at least size bytes, so
+// that a corrupted length allocates no more than the dump holds.
+func (r *dumpReader) len(size int) int {
+	n := r.uint()
+	if r.err == nil && n > uint64(r.r.Len()/size) {
+		r.err = errInvalidDump
+		return 0
+	}
+	return int(n)
+}
+
+func (r *dumpReader) string() string {
+	b := make([]byte, r.len(1))
+	if r.err == nil && len(b) > 0 {
+		_, _ = r.r.Read(b)
+	}
+	return string(b)
+}
+
+func (r *dumpReader) hasher() hashing.Hasher {
+	family := hashing.Family(r.uint())
+	seed := r.uint()
+	if _, err := hashing.ParseFamily(family.String()); err != nil && r.err == nil {
+		r.err = errInvalidDump
+	}
+	return hashing.Hasher{Family: family, Seed: seed}
+}
+
+func (r *dumpReader) streamID() StreamID {
+	return StreamID{Ms: r.uint(), Seq: r.uint()}
+}
+
+// check returns an error if a field could not be read, if the dump holds more
+// than the fields read or if valid is false.
+func (r *dumpReader) check(valid bool) error {
+	if r.err != nil || r.r.Len() > 0 || !valid {
+		return errInvalidDump
+	}
+	return nil
+}
+
+// dumpValue returns the type, as reported by the composition of the keyspace,
+// and the binary dump of the value of the plain object obj. It returns false
+// if the value has a command representation instead, see exportValue.
+func dumpValue(obj *object.Obj) (typ string, data []byte, ok bool) {
+	w := &dumpWriter{}
+	switch v := obj.Value.(type) {
+	case *Cuckoo:
+		w.hasher(v.hasher)
+		for _, n := range []uint64{v.opts.capacity, v.opts.bucketSize, v.opts.maxIterations, v.numBuckets, v.items, v.deletes} {
+			w.uint(n)
+		}
+		w.uint(uint64(len(v.slots)))
+		_ = binary.Write(&w.Buffer, binary.BigEndian, v.slots)
+	case *CountMinSketch:
+		w.hasher(v.hasher)
+		for _, n := range []uint64{v.width, v.depth, v.count} {
+			w.uint(n)
+		}
+		w.uint(uint64(len(v.matrix)))
+		_ = binary.Write(&w.Buffer, binary.BigEndian, v.matrix)
+	case *TopK:
+		w.hasher(v.hasher)
+		for _, n := range []uint64{v.k, v.width, v.depth} {
+			w.uint(n)
+		}
+		w.float(v.decay)
+		w.uint(v.state)
+		w.uint(uint64(len(v.buckets)))
+		for _, b := range v.buckets {
+			_ = binary.Write(&w.Buffer, binary.BigEndian, [2]uint32{b.fingerprint, b.count})
+		}
+		w.uint(uint64(len(v.heap)))
+		for _, item := range v.heap {
+			w.string(item.item)
+			w.uint(uint64(item.count))
+		}
+	case *TDigest:
+		w.uint(v.compression)
+		for _, f := range []float64{v.weight, v.min, v.max} {
+			w.float(f)
+		}
+		w.uint(uint64(len(v.centroids)))
+		for _, c := range v.centroids {
+			w.float(c.mean)
+			w.float(c.weight)
+		}
+		w.uint(uint64(len(v.buffer)))
+		for _, f := range v.buffer {
+			w.float(f)
+		}
+	case *Stream:
+		dumpStream(w, v)
+	default:
+		return "", nil, false
+	}
+	return compositionType(obj), w.Bytes(), true
+}
+
+func dumpStream(w *dumpWriter, s *Stream) {
+	w.streamID(s.lastID)
+	entries := s.Range(StreamID{}, streamIDMaxValue, -1, false)
+	w.uint(uint64(len(entries)))
+	for _, e := range entries {
+		w.streamID(e.ID)
+		w.uint(uint64(len(e.Fields)))
+		for _, f := range e.Fields {
+			w.string(f)
+		}
+	}
+
+	// the groups and the consumers are sorted, for equal streams to have equal
+	// dumps
+	w.uint(uint64(len(s.groups)))
+	for _, name := range sortedKeys(s.groups) {
+		g := s.groups[name]
+		w.string(g.Name)
+		w.streamID(g.LastDeliveredID)
+		w.uint(uint64(len(g.consumers)))
+		for _, name := range sortedKeys(g.consumers) {
+			c := g.consumers[name]
+			w.string(c.Name)
+			w.uint(uint64(c.SeenAt))
+			w.uint(uint64(c.Pending))
+		}
+		w.uint(uint64(g.pel.Len()))
+		g.pel.Ascend(func(item btree.Item) bool {
+			pe := item.(*StreamPendingEntry)
+			w.streamID(pe.ID)
+			w.string(pe.Consumer)
+			w.uint(uint64(pe.DeliveredAt))
+			w.uint(uint64(pe.Deliveries))
+			return true
+		})
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadValue returns the object of the value of type typ, as reported by the
+// composition of the keyspace, restored from its binary dump, see dumpValue.
+func loadValue(typ string, data []byte) (*object.Obj, error) {
+	r := &dumpReader{r: bytes.NewReader(data)}
+	switch typ {
+	case "cuckoo":
+		c := &Cuckoo{hasher: r.hasher(), opts: &CuckooOpts{capacity: r.uint(), bucketSize: r.uint(), maxIterations: r.uint()}}
+		c.numBuckets, c.items, c.deletes = r.uint(), r.uint(), r.uint()
+		c.slots = make([]uint16, r.len(2))
+		if r.err == nil {
+			_ = binary.Read(r.r, binary.BigEndian, c.slots)
+		}
+		valid := c.numBuckets > 0 && c.numBuckets&(c.numBuckets-1) == 0 && c.opts.bucketSize > 0 &&
+			uint64(len(c.slots))/c.opts.bucketSize == c.numBuckets && uint64(len(c.slots))%c.opts.bucketSize == 0
+		if err := r.check(valid); err != nil {
+			return nil, err
+		}
+		return &object.Obj{Value: c, TypeEncoding: object.ObjTypeCuckoo | object.ObjEncodingCF}, nil
+	case "cms":
+		c := &CountMinSketch{hasher: r.hasher(), width: r.uint(), depth: r.uint(), count: r.uint()}
+		c.matrix = make([]uint64, r.len(8))
+		if r.err == nil {
+			_ = binary.Read(r.r, binary.BigEndian, c.matrix)
+		}
+		valid := c.width > 0 && uint64(len(c.matrix))/c.width == c.depth && uint64(len(c.matrix))%c.width == 0
+		if err := r.check(valid); err != nil {
+			return nil, err
+		}
+		return &object.Obj{Value: c, TypeEncoding: object.ObjTypeCountMinSketch | object.ObjEncodingCMS}, nil
+	case "topk":
+		t := &TopK{hasher: r.hasher(), k: r.uint(), width: r.uint(), depth: r.uint(), decay: r.float(), state: r.uint()}
+		t.buckets = make([]topkBucket, r.len(8))
+		for i := range t.buckets {
+			var b [2]uint32
+			if r.err == nil {
+				_ = binary.Read(r.r, binary.BigEndian, &b)
+			}
+			t.buckets[i] = topkBucket{fingerprint: b[0], count: b[1]}
+		}
+		t.heap = make(topkHeap, r.len(16))
+		for i := range t.heap {
+			t.heap[i] = topkItem{item: r.string(), count: uint32(r.uint())}
+		}
+		valid := t.width > 0 && uint64(len(t.buckets))/t.width == t.depth && uint64(len(t.buckets))%t.width == 0 &&
+			uint64(len(t.heap)) <= t.k && t.decay > 0 && t.decay <= 1
+		if err := r.check(valid); err != nil {
+			return nil, err
+		}
+		return &object.Obj{Value: t, TypeEncoding: object.ObjTypeTopK | object.ObjEncodingTopK}, nil
+	case "tdigest":
+		t := &TDigest{compression: r.uint(), weight: r.float(), min: r.float(), max: r.float()}
+		t.centroids = make([]centroid, r.len(16))
+		for i := range t.centroids {
+			t.centroids[i] = centroid{mean: r.float(), weight: r.float()}
+		}
+		t.buffer = make([]float64, r.len(8))
+		for i := range t.buffer {
+			t.buffer[i] = r.float()
+		}
+		if err := r.check(t.compression >= 1 && t.compression <= maxTDigestCompression); err != nil {
+			return nil, err
+		}
+		return &object.Obj{Value: t, TypeEncoding: object.ObjTypeTDigest | object.ObjEncodingTDigest}, nil
+	case "stream":
+		s, valid := loadStream(r)
+		if err := r.check(valid); err != nil {
+			return nil, err
+		}
+		return &object.Obj{Value: s, TypeEncoding: object.ObjTypeStream | object.ObjEncodingStream}, nil
+	}
+	return nil, fmt.Errorf("unknown value type %s", typ)
+}
+
+// loadStream restores a stream from its dump. It returns false if the IDs of
+// the entries are not increasing up to the last ID.
+func loadStream(r *dumpReader) (*Stream, bool) {
+	s := NewStream()
+	lastID := r.streamID()
+	var prev *StreamID
+	for i, n := 0, r.len(24); i < n && r.err == nil; i++ {
+		id := r.streamID()
+		fields := make([]string, r.len(8))
+		for j := range fields {
+			fields[j] = r.string()
+		}
+		if prev != nil && !prev.Less(id) || len(fields)%2 != 0 {
+			return nil, false
+		}
+		s.Add(id, fields)
+		prev = &id
+	}
+	if prev != nil && lastID.Less(*prev) {
+		return nil, false
+	}
+	s.lastID = lastID
+
+	for i, n := 0, r.len(32); i < n && r.err == nil; i++ {
+		g := newStreamGroup(r.string(), r.streamID())
+		for j, m := 0, r.len(24); j < m; j++ {
+			c := &StreamConsumer{Name: r.string(), SeenAt: int64(r.uint()), Pending: int(r.uint())}
+			g.consumers[c.Name] = c
+		}
+		for j, m := 0, r.len(40); j < m; j++ {
+			g.pel.ReplaceOrInsert(&StreamPendingEntry{ID: r.streamID(), Consumer: r.string(), DeliveredAt: int64(r.uint()), Deliveries: int64(r.uint())})
+		}
+		s.groups[g.Name] = g
+	}
+	return s, true
+}
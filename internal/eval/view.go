@@ -0,0 +1,138 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// evalVIEWCREATE creates a read-only materialized view of a key. The views are
+// attached to their key, so that they are served by the shard owning it:
+//
+//	VIEW.CREATE key name TOP count [WITHSCORES]
+//	VIEW.CREATE key name CARD
+//
+// The result of the view is computed on the first read and kept until the key
+// is written. The views are local to the server, they are neither persisted
+// nor propagated to the replicas, which can create their own.
+func evalVIEWCREATE(args []string, store *dstore.Store) []byte {
+	if len(args) < 3 {
+		return diceerrors.NewErrArity("VIEW.CREATE")
+	}
+
+	var compute dstore.ViewFunc
+	switch strings.ToUpper(args[2]) {
+	case Top:
+		if len(args) != 4 && len(args) != 5 {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		count, err := strconv.Atoi(args[3])
+		if err != nil || count <= 0 {
+			return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		}
+		withScores := false
+		if len(args) == 5 {
+			if strings.ToUpper(args[4]) != WithScores {
+				return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+			}
+			withScores = true
+		}
+		compute = topView(count, withScores)
+	case Card:
+		if len(args) != 3 {
+			return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+		}
+		compute = cardView
+	default:
+		return diceerrors.NewErrWithMessage(diceerrors.SyntaxErr)
+	}
+
+	if !store.CreateView(args[0], args[1], compute) {
+		return diceerrors.NewErrWithMessage("view already exists")
+	}
+	return clientio.RespOK
+}
+
+// evalVIEWGET returns the result of a view, as the command the view stands
+// for would.
+func evalVIEWGET(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("VIEW.GET")
+	}
+
+	result, ok := store.View(args[0], args[1])
+	if !ok {
+		return diceerrors.NewErrWithMessage("no such view")
+	}
+	return result
+}
+
+// evalVIEWDROP removes a view. It returns 1 if the view was removed, 0 if
+// there was no such view.
+func evalVIEWDROP(args []string, store *dstore.Store) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("VIEW.DROP")
+	}
+
+	if !store.DropView(args[0], args[1]) {
+		return clientio.RespZero
+	}
+	return clientio.RespOne
+}
+
+// topView returns the view of the count members of a sorted set with the
+// highest scores, in decreasing order of score.
+func topView(count int, withScores bool) dstore.ViewFunc {
+	return func(obj *object.Obj) []byte {
+		if obj == nil {
+			return clientio.Encode([]string{}, false)
+		}
+		if err := object.AssertTypeAndEncoding(obj.TypeEncoding, object.ObjTypeSortedSet, object.ObjEncodingBTree); err != nil {
+			return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+		}
+
+		valueSlice, ok := obj.Value.([]interface{})
+		if !ok || len(valueSlice) != 2 {
+			return diceerrors.NewErrWithMessage("Invalid sorted set object")
+		}
+		tree := valueSlice[0].(*skipList)
+
+		result := make([]string, 0, 2*min(count, tree.Len()))
+		members := 0
+		tree.Descend(func(item *SortedSetItem) bool {
+			result = append(result, item.Member)
+			if withScores {
+				result = append(result, formatScore(item.Score))
+			}
+			members++
+			return members < count
+		})
+		return clientio.Encode(result, false)
+	}
+}
+
+// cardView is the view of the number of elements of a set, a sorted set or a hash.
+func cardView(obj *object.Obj) []byte {
+	if obj == nil {
+		return clientio.RespZero
+	}
+
+	switch oType, _ := object.ExtractTypeEncoding(obj); oType {
+	case object.ObjTypeSet:
+		return clientio.Encode(len(obj.Value.(map[string]struct{})), false)
+	case object.ObjTypeSortedSet:
+		valueSlice, ok := obj.Value.([]interface{})
+		if !ok || len(valueSlice) != 2 {
+			return diceerrors.NewErrWithMessage("Invalid sorted set object")
+		}
+		return clientio.Encode(len(valueSlice[1].(map[string]float64)), false)
+	case object.ObjTypeHashMap:
+		return clientio.Encode(len(obj.Value.(HashMap)), false)
+	default:
+		return diceerrors.NewErrWithFormattedMessage(diceerrors.WrongTypeErr)
+	}
+}
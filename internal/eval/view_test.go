@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+	"gotest.tools/v3/assert"
+)
+
+func TestView(t *testing.T) {
+	store := dstore.NewStore(nil)
+	exec := func(name string, args ...string) string {
+		resp := ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+		return string(resp.Result.([]byte))
+	}
+
+	assert.Equal(t, "+OK\r\n", exec("VIEW.CREATE", "board", "top2", "TOP", "2", "WITHSCORES"))
+	assert.Equal(t, "+OK\r\n", exec("VIEW.CREATE", "board", "size", "CARD"))
+	assert.Equal(t, "-ERR view already exists\r\n", exec("VIEW.CREATE", "board", "size", "CARD"))
+
+	// the views of a missing key
+	assert.Equal(t, "*0\r\n", exec("VIEW.GET", "board", "top2"))
+	assert.Equal(t, ":0\r\n", exec("VIEW.GET", "board", "size"))
+
+	// the views are computed again once the key is created and modified in place
+	exec("ZADD", "board", "10", "a", "30", "b", "20", "c")
+	assert.DeepEqual(t, clientio.Encode([]string{"b", "30", "c", "20"}, false), []byte(exec("VIEW.GET", "board", "top2")))
+	assert.Equal(t, ":3\r\n", exec("VIEW.GET", "board", "size"))
+	exec("ZADD", "board", "40", "a", "1", "d")
+	assert.DeepEqual(t, clientio.Encode([]string{"a", "40", "b", "30"}, false), []byte(exec("VIEW.GET", "board", "top2")))
+	assert.Equal(t, ":4\r\n", exec("VIEW.GET", "board", "size"))
+
+	// writes to other keys leave the views as they are
+	exec("ZADD", "other", "50", "e")
+	assert.DeepEqual(t, clientio.Encode([]string{"a", "40", "b", "30"}, false), []byte(exec("VIEW.GET", "board", "top2")))
+
+	// the views follow the key when it is replaced or deleted
+	exec("DEL", "board")
+	assert.Equal(t, ":0\r\n", exec("VIEW.GET", "board", "size"))
+	exec("SADD", "board", "x", "y")
+	assert.Equal(t, ":2\r\n", exec("VIEW.GET", "board", "size"))
+	assert.Equal(t, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", exec("VIEW.GET", "board", "top2"))
+
+	assert.Equal(t, ":1\r\n", exec("VIEW.DROP", "board", "top2"))
+	assert.Equal(t, ":0\r\n", exec("VIEW.DROP", "board", "top2"))
+	assert.Equal(t, "-ERR no such view\r\n", exec("VIEW.GET", "board", "top2"))
+
+	// the views are dropped along with the keys
+	exec("FLUSHDB")
+	assert.Equal(t, "-ERR no such view\r\n", exec("VIEW.GET", "board", "size"))
+}
+
+func TestViewCreateErrors(t *testing.T) {
+	store := dstore.NewStore(nil)
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"arity":             {[]string{"key", "name"}, "-ERR wrong number of arguments for 'view.create' command\r\n"},
+		"unknown kind":      {[]string{"key", "name", "BOTTOM", "5"}, "-ERR syntax error\r\n"},
+		"missing count":     {[]string{"key", "name", "TOP"}, "-ERR syntax error\r\n"},
+		"invalid count":     {[]string{"key", "name", "TOP", "0"}, "-ERR value is not an integer or out of range\r\n"},
+		"unknown option":    {[]string{"key", "name", "TOP", "5", "WITHVALUES"}, "-ERR syntax error\r\n"},
+		"extra cardinality": {[]string{"key", "name", "CARD", "5"}, "-ERR syntax error\r\n"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(evalVIEWCREATE(tc.args, store)))
+		})
+	}
+	assert.Assert(t, !store.HasViews())
+}
+
+func TestKeySpecsKeys(t *testing.T) {
+	assert.DeepEqual(t, []string{"a", "b"}, DiceCmds["MSET"].KeySpecs.keys([]string{"a", "1", "b", "2"}))
+	assert.DeepEqual(t, []string{"a", "b", "c"}, DiceCmds["DEL"].KeySpecs.keys([]string{"a", "b", "c"}))
+	assert.DeepEqual(t, []string{"s"}, DiceCmds["ZADD"].KeySpecs.keys([]string{"s", "1", "m"}))
+	assert.DeepEqual(t, []string{"src", "dst"}, DiceCmds["COPY"].KeySpecs.keys([]string{"src", "dst", "REPLACE"}))
+	assert.Assert(t, DiceCmds["PING"].KeySpecs.keys([]string{"hello"}) == nil)
+}
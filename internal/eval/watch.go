@@ -0,0 +1,102 @@
+package eval
+
+import (
+	"strconv"
+
+	"github.com/bytedance/sonic"
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The write commands modify most objects in place, without putting them back
+// in the store, so the store cannot tell whether they modified a key. The keys
+// watched by a transaction are digested before and after such a command
+// instead, their watches being touched only if the digests differ. The keys
+// that are not watched are not digested, as it costs a pass over their value.
+
+// valueDigests returns the digests of the keys, see valueDigest.
+func valueDigests(keys []string, store *dstore.Store) []uint64 {
+	digests := make([]uint64, len(keys))
+	for i, k := range keys {
+		digests[i], _ = valueDigest(k, store)
+	}
+	return digests
+}
+
+// touchModifiedWatches touches the watches of the keys whose digest changed
+// since the digests were taken, or which have no digest.
+func touchModifiedWatches(keys []string, digests []uint64, store *dstore.Store) {
+	for i, k := range keys {
+		if d, ok := valueDigest(k, store); !ok || d != digests[i] {
+			store.TouchWatches(k)
+		}
+	}
+}
+
+// valueDigest returns a digest of the value of the key, its expiry and the
+// expiries of its fields or members, 0 if it does not exist. It returns false
+// if the value has no command representation to digest. The members of the
+// sets and the fields of the hashes are digested regardless of their order.
+func valueDigest(key string, store *dstore.Store) (uint64, bool) {
+	obj, ok := store.GetStore().Get(key)
+	if !ok {
+		return 0, true
+	}
+
+	h := xxhash.New()
+	oType, _ := object.ExtractTypeEncoding(obj)
+	_, _ = h.WriteString(strconv.Itoa(int(oType)))
+	if exp, ok := dstore.GetExpiry(obj, store); ok {
+		_, _ = h.WriteString(" " + strconv.FormatUint(exp, 10))
+	}
+
+	var unordered uint64
+	store.FieldExpiries(key, func(field string, exp uint64) {
+		unordered ^= xxhash.Sum64String(field + "\x00" + strconv.FormatUint(exp, 10))
+	})
+
+	plain := dstore.PlainObj(obj)
+	switch v := plain.Value.(type) {
+	case map[string]struct{}:
+		for member := range v {
+			unordered ^= xxhash.Sum64String("\x01" + member)
+		}
+		_, _ = h.WriteString(" " + strconv.Itoa(len(v)))
+	case HashMap:
+		for field, value := range v {
+			unordered ^= xxhash.Sum64String("\x01" + field + "\x00" + value)
+		}
+		_, _ = h.WriteString(" " + strconv.Itoa(len(v)))
+	default:
+		if oType == object.ObjTypeJSON {
+			// the keys of the objects are sorted, for their order not to matter
+			value, err := sonic.ConfigStd.MarshalToString(plain.Value)
+			if err != nil {
+				return 0, false
+			}
+			_, _ = h.WriteString(" " + value)
+			break
+		}
+
+		cmds, _, err := exportValue(key, plain, 0, false)
+		if err != nil {
+			return 0, false
+		}
+		if len(cmds) == 0 {
+			_, data, ok := dumpValue(plain)
+			if !ok {
+				return 0, false
+			}
+			cmds = [][]string{{string(data)}}
+		}
+		for _, c := range cmds {
+			for _, arg := range c {
+				_, _ = h.WriteString(" " + strconv.Itoa(len(arg)) + ":" + arg)
+			}
+		}
+	}
+	_, _ = h.WriteString(" " + strconv.FormatUint(unordered, 10))
+	return h.Sum64(), true
+}
@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// wrongTypeFixtures are the commands creating the key k with each kind of
+// value.
+var wrongTypeFixtures = map[string][]string{
+	"string":  {"SET", "k", "value"},
+	"int":     {"SET", "k", "10"},
+	"bitmap":  {"SETBIT", "k", "3", "1"},
+	"hll":     {"PFADD", "k", "a"},
+	"list":    {"LPUSH", "k", "a"},
+	"set":     {"SADD", "k", "a"},
+	"hash":    {"HSET", "k", "f", "v"},
+	"zset":    {"ZADD", "k", "1", "a"},
+	"json":    {"JSON.SET", "k", "$", `{"a":1}`},
+	"stream":  {"XADD", "k", "*", "f", "v"},
+	"bloom":   {"BFINIT", "k"},
+	"cuckoo":  {"CF.RESERVE", "k", "100"},
+	"cms":     {"CMS.INITBYDIM", "k", "10", "5"},
+	"topk":    {"TOPK.RESERVE", "k", "3"},
+	"tdigest": {"TDIGEST.CREATE", "k"},
+}
+
+func newWrongTypeStore(fixture string) *dstore.Store {
+	store := dstore.NewStore(nil)
+	args := wrongTypeFixtures[fixture]
+	ExecuteCommand(&cmd.DiceDBCmd{Cmd: args[0], Args: args[1:]}, nil, store, false, false)
+	return store
+}
+
+func wrongTypeReply(resp *EvalResponse) string {
+	if resp.Error != nil {
+		return "-" + resp.Error.Error()
+	}
+	if reply, ok := resp.Result.([]byte); ok {
+		return string(reply)
+	}
+	return ""
+}
+
+// isWrongTypeReply returns true if reply is the error of a command operating on
+// the wrong kind of value. The JSON, list and sorted set commands reply with
+// the Dice type error rather than WRONGTYPE.
+func isWrongTypeReply(reply string) bool {
+	return strings.HasPrefix(reply, "-WRONGTYPE") || strings.Contains(reply, "Existing key has wrong Dice type")
+}
+
+func TestWrongType(t *testing.T) {
+	stringTypes := []string{"string", "int", "bitmap"}
+	tests := []struct {
+		args  []string // the command, operating on the key k
+		types []string // the kinds of values the command operates on
+	}{
+		{[]string{"GET", "k"}, stringTypes},
+		{[]string{"GETDEL", "k"}, stringTypes},
+		{[]string{"GETEX", "k"}, stringTypes},
+		{[]string{"GETSET", "k", "v"}, stringTypes},
+		{[]string{"GETRANGE", "k", "0", "1"}, []string{"string", "int"}},
+		{[]string{"APPEND", "k", "v"}, []string{"string", "int"}},
+		{[]string{"STRLEN", "k"}, []string{"string", "int"}},
+		{[]string{"GETBIT", "k", "1"}, stringTypes},
+		{[]string{"BITCOUNT", "k"}, stringTypes},
+		{[]string{"BITPOS", "k", "1"}, stringTypes},
+		{[]string{"BITFIELD", "k", "GET", "u8", "0"}, stringTypes},
+		{[]string{"PFADD", "k", "a"}, []string{"hll"}},
+		{[]string{"PFCOUNT", "k"}, []string{"hll"}},
+
+		{[]string{"LPUSH", "k", "a"}, []string{"list"}},
+		{[]string{"RPUSHX", "k", "a"}, []string{"list"}},
+		{[]string{"LPOP", "k"}, []string{"list"}},
+		{[]string{"RPOP", "k"}, []string{"list"}},
+		{[]string{"LLEN", "k"}, []string{"list"}},
+		{[]string{"LPOS", "k", "a"}, []string{"list"}},
+		{[]string{"LINSERT", "k", "BEFORE", "a", "b"}, []string{"list"}},
+		{[]string{"LMOVE", "k", "dst", "LEFT", "RIGHT"}, []string{"list"}},
+
+		{[]string{"SADD", "k", "a"}, []string{"set"}},
+		{[]string{"SREM", "k", "a"}, []string{"set"}},
+		{[]string{"SCARD", "k"}, []string{"set"}},
+		{[]string{"SMEMBERS", "k"}, []string{"set"}},
+		{[]string{"SISMEMBER", "k", "a"}, []string{"set"}},
+		{[]string{"SPOP", "k"}, []string{"set"}},
+		{[]string{"SINTER", "k", "k"}, []string{"set"}},
+
+		{[]string{"HSET", "k", "f", "v"}, []string{"hash"}},
+		{[]string{"HGET", "k", "f"}, []string{"hash"}},
+		{[]string{"HDEL", "k", "f"}, []string{"hash"}},
+		{[]string{"HGETALL", "k"}, []string{"hash"}},
+		{[]string{"HINCRBY", "k", "f", "1"}, []string{"hash"}},
+		{[]string{"HRANDFIELD", "k"}, []string{"hash"}},
+		{[]string{"HEXPIRE", "k", "10", "FIELDS", "1", "f"}, []string{"hash"}},
+		{[]string{"HTTL", "k", "FIELDS", "1", "f"}, []string{"hash"}},
+		{[]string{"HPERSIST", "k", "FIELDS", "1", "f"}, []string{"hash"}},
+		{[]string{"EXPIREMEMBER", "k", "a", "10"}, []string{"set", "zset", "hash"}},
+
+		{[]string{"ZADD", "k", "1", "a"}, []string{"zset"}},
+		{[]string{"ZINCRBY", "k", "1", "a"}, []string{"zset"}},
+		{[]string{"ZRANGE", "k", "0", "-1"}, []string{"zset"}},
+		{[]string{"ZREM", "k", "a"}, []string{"zset"}},
+		{[]string{"ZPOPMIN", "k"}, []string{"zset"}},
+		{[]string{"ZRANGEBYLEX", "k", "-", "+"}, []string{"zset"}},
+		{[]string{"ZLEXCOUNT", "k", "-", "+"}, []string{"zset"}},
+		{[]string{"ZRANGEBYSCORE", "k", "0", "1"}, []string{"zset"}},
+		{[]string{"ZCOUNT", "k", "0", "1"}, []string{"zset"}},
+		{[]string{"ZREMRANGEBYSCORE", "k", "0", "1"}, []string{"zset"}},
+		{[]string{"ZUNION", "1", "k"}, []string{"zset", "set"}},
+		{[]string{"ZMSCORE", "k", "a"}, []string{"zset"}},
+		{[]string{"ZRANK", "k", "a"}, []string{"zset"}},
+		{[]string{"ZREVRANK", "k", "a"}, []string{"zset"}},
+		{[]string{"ZRANDMEMBER", "k"}, []string{"zset"}},
+
+		{[]string{"JSON.GET", "k"}, []string{"json"}},
+		{[]string{"JSON.SET", "k", "$", "1"}, []string{"json"}},
+		{[]string{"JSON.TYPE", "k"}, []string{"json"}},
+		{[]string{"JSON.DEL", "k"}, []string{"json"}},
+		{[]string{"JSON.ARRLEN", "k"}, []string{"json"}},
+
+		{[]string{"XADD", "k", "*", "f", "v"}, []string{"stream"}},
+		{[]string{"XLEN", "k"}, []string{"stream"}},
+		{[]string{"XTRIM", "k", "MAXLEN", "1"}, []string{"stream"}},
+		{[]string{"XRANGE", "k", "-", "+"}, []string{"stream"}},
+
+		{[]string{"BFADD", "k", "a"}, []string{"bloom"}},
+		{[]string{"BFEXISTS", "k", "a"}, []string{"bloom"}},
+		{[]string{"BFINFO", "k"}, []string{"bloom"}},
+		{[]string{"BF.MADD", "k", "a"}, []string{"bloom"}},
+		{[]string{"BF.MEXISTS", "k", "a"}, []string{"bloom"}},
+		{[]string{"BF.INSERT", "k", "ITEMS", "a"}, []string{"bloom"}},
+		{[]string{"BF.CARD", "k"}, []string{"bloom"}},
+		{[]string{"BF.SCANDUMP", "k", "0"}, []string{"bloom"}},
+		{[]string{"BF.LOADCHUNK", "k", "2", "a"}, []string{"bloom"}},
+		{[]string{"CF.ADD", "k", "a"}, []string{"cuckoo"}},
+		{[]string{"CF.COUNT", "k", "a"}, []string{"cuckoo"}},
+		{[]string{"CMS.INCRBY", "k", "a", "1"}, []string{"cms"}},
+		{[]string{"CMS.QUERY", "k", "a"}, []string{"cms"}},
+		{[]string{"TOPK.ADD", "k", "a"}, []string{"topk"}},
+		{[]string{"TOPK.LIST", "k"}, []string{"topk"}},
+		{[]string{"TDIGEST.ADD", "k", "1"}, []string{"tdigest"}},
+		{[]string{"TDIGEST.QUANTILE", "k", "0.5"}, []string{"tdigest"}},
+	}
+
+	for _, tt := range tests {
+		for fixture := range wrongTypeFixtures {
+			if slices.Contains(tt.types, fixture) {
+				continue
+			}
+			store := newWrongTypeStore(fixture)
+			reply := wrongTypeReply(ExecuteCommand(&cmd.DiceDBCmd{Cmd: tt.args[0], Args: tt.args[1:]}, nil, store, false, false))
+			if !isWrongTypeReply(reply) {
+				t.Errorf("%v on a %s: got %q, want a WRONGTYPE error", tt.args, fixture, reply)
+			}
+		}
+	}
+}
+
+// TestWrongTypeNoPanic runs every command taking the key k first against
+// every kind of value, with a few shapes of arguments, none of which must
+// panic.
+func TestWrongTypeNoPanic(t *testing.T) {
+	argShapes := [][]string{
+		{"k"}, {"k", "1"}, {"k", "a"}, {"k", "1", "2"}, {"k", "a", "b"}, {"k", "0", "-1"},
+		{"k", "-", "+"}, {"k", "$"}, {"k", "$", "1"}, {"k", "f", "1"}, {"k", "1", "2", "3"},
+		{"k", "GET", "u8", "0"}, {"k", "*", "f", "v"}, {"k", "dst", "LEFT", "RIGHT"},
+	}
+
+	var names []string
+	for name, diceCmd := range DiceCmds {
+		if diceCmd.KeySpecs.BeginIndex == 1 && name != "SLEEP" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for fixture := range wrongTypeFixtures {
+			for _, args := range argShapes {
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							t.Errorf("%s %v on a %s panicked: %v", name, args, fixture, r)
+						}
+					}()
+					store := newWrongTypeStore(fixture)
+					ExecuteCommand(&cmd.DiceDBCmd{Cmd: name, Args: args}, nil, store, false, false)
+				}()
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package hashing holds the hash functions of the probabilistic structures,
+// bloom and cuckoo filters, count-min sketches and top-k trackers, so that
+// they all share the same vetted and benchmarked functions.
+//
+// A structure derives all its hashes from a Hasher, a hash family along with
+// a seed: the hashes of an item are the same across processes and restarts,
+// so that structures built separately with the same Hasher can be compared or
+// merged, and that the tests are reproducible.
+package hashing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/twmb/murmur3"
+)
+
+// Family is a family of 64-bit hash functions, its members being selected by
+// a seed.
+type Family uint8
+
+const (
+	// Murmur3 is the 64-bit half of MurmurHash3 x64 128, the default family.
+	Murmur3 Family = iota
+	// XXHash is XXH64.
+	XXHash
+	// WyHash is the final version 4 of wyhash.
+	WyHash
+)
+
+var familyNames = [...]string{
+	Murmur3: "murmur3",
+	XXHash:  "xxhash",
+	WyHash:  "wyhash",
+}
+
+func (f Family) String() string {
+	if int(f) < len(familyNames) {
+		return familyNames[f]
+	}
+	return fmt.Sprintf("Family(%d)", f)
+}
+
+// ParseFamily returns the family of the name, case-insensitively.
+func ParseFamily(name string) (Family, error) {
+	for f, n := range familyNames {
+		if strings.EqualFold(name, n) {
+			return Family(f), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hash family %q, expected one of %s", name, strings.Join(familyNames[:], ", "))
+}
+
+// Sum64 returns the hash of s by the member of the family selected by seed.
+func (f Family) Sum64(s string, seed uint64) uint64 {
+	switch f {
+	case XXHash:
+		if seed == 0 {
+			return xxhash.Sum64String(s)
+		}
+		var d xxhash.Digest
+		d.ResetWithSeed(seed)
+		_, _ = d.WriteString(s)
+		return d.Sum64()
+	case WyHash:
+		return wyhash(s, seed)
+	default:
+		return murmur3.SeedStringSum64(seed, s)
+	}
+}
+
+// Hasher derives the hashes of the items of a structure.
+type Hasher struct {
+	Family Family
+	Seed   uint64
+}
+
+// Sum64 returns the hash of s.
+func (h Hasher) Sum64(s string) uint64 {
+	return h.Family.Sum64(s, h.Seed)
+}
+
+// Nth returns the i-th hash of s, the hashes of different i being independent.
+// Nth(0, s) is Sum64(s).
+func (h Hasher) Nth(i uint64, s string) uint64 {
+	return h.Family.Sum64(s, h.Seed+i)
+}
+
+// Indexes fills indexes with as many positions of s in [0, m), by double
+// hashing: the i-th position is (h1 + i*h2) mod m, h1 and h2 being the hashes
+// Nth(0, s) and Nth(1, s). Computing two hashes whatever the number of
+// positions, it is as accurate as independent hashes for a bloom filter, see
+// "Less Hashing, Same Performance: Building a Better Bloom Filter" by Kirsch
+// and Mitzenmacher.
+func (h Hasher) Indexes(indexes []uint64, s string, m uint64) {
+	h1, h2 := h.Nth(0, s), h.Nth(1, s)
+	// an odd step visits all the positions when m is a power of 2
+	h2 |= 1
+	for i := range indexes {
+		indexes[i] = h1 % m
+		h1 += h2
+	}
+}
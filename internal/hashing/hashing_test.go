@@ -0,0 +1,91 @@
+package hashing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/twmb/murmur3"
+	"gotest.tools/v3/assert"
+)
+
+func TestWyHash(t *testing.T) {
+	// the test vectors of the reference implementation
+	tests := []struct {
+		s    string
+		seed uint64
+		want uint64
+	}{
+		{"", 0, 0x93228a4de0eec5a2},
+		{"a", 1, 0xc5bac3db178713c4},
+		{"abc", 2, 0xa97f2f7b1d9b3314},
+		{"message digest", 3, 0x786d1f1df3801df4},
+		{"abcdefghijklmnopqrstuvwxyz", 4, 0xdca5a8138ad37c87},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", 5, 0xb9e734f117cfaf70},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", 6, 0x6cc5eab49a92d617},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, WyHash.Sum64(tt.s, tt.seed), "wyhash(%q, %d)", tt.s, tt.seed)
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	assert.Equal(t, murmur3.SeedStringSum64(7, "dice"), Murmur3.Sum64("dice", 7))
+	assert.Equal(t, xxhash.Sum64String("dice"), XXHash.Sum64("dice", 0))
+	assert.Equal(t, xxhash.NewWithSeed(7).Sum64(), XXHash.Sum64("", 7))
+
+	for _, f := range []Family{Murmur3, XXHash, WyHash} {
+		parsed, err := ParseFamily(strings.ToUpper(f.String()))
+		assert.NilError(t, err)
+		assert.Equal(t, f, parsed)
+
+		// the seeds select different functions
+		assert.Assert(t, f.Sum64("dice", 1) != f.Sum64("dice", 2), f)
+	}
+	_, err := ParseFamily("crc32")
+	assert.ErrorContains(t, err, `unknown hash family "crc32"`)
+}
+
+func TestIndexes(t *testing.T) {
+	h := Hasher{Family: WyHash, Seed: 42}
+	indexes := make([]uint64, 7)
+	h.Indexes(indexes, "dice", 1000)
+	for _, i := range indexes {
+		assert.Assert(t, i < 1000)
+	}
+
+	again := make([]uint64, 7)
+	h.Indexes(again, "dice", 1000)
+	assert.DeepEqual(t, indexes, again)
+
+	// the positions are distinct when the step is coprime with m
+	seen := make(map[uint64]bool)
+	h.Indexes(indexes, "dice", 1024)
+	for _, i := range indexes {
+		assert.Assert(t, !seen[i], "position %d repeated", i)
+		seen[i] = true
+	}
+}
+
+func BenchmarkSum64(b *testing.B) {
+	for _, size := range []int{8, 32, 256} {
+		s := strings.Repeat("x", size)
+		for _, f := range []Family{Murmur3, XXHash, WyHash} {
+			b.Run(fmt.Sprintf("%s/%d", f, size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				for i := 0; i < b.N; i++ {
+					f.Sum64(s, uint64(i))
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkIndexes(b *testing.B) {
+	h := Hasher{Family: Murmur3}
+	indexes := make([]uint64, 7)
+	for i := 0; i < b.N; i++ {
+		h.Indexes(indexes, "user:1234", 9592)
+	}
+}
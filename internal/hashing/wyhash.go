@@ -0,0 +1,68 @@
+package hashing
+
+import "math/bits"
+
+// wyhash is the final version 4 of wyhash by Wang Yi, with its default secret,
+// see https://github.com/wangyi-fudan/wyhash. It is the fastest of the
+// families on short items.
+
+var wyp = [4]uint64{0x2d358dccaa6c78a5, 0x8bb84b93962eacc9, 0x4b33a62ed433d4a3, 0x4d5a2da51de1aa47}
+
+func wymix(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func wyr8(s string) uint64 {
+	_ = s[7]
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+		uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+}
+
+func wyr4(s string) uint64 {
+	_ = s[3]
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24
+}
+
+// wyr3 reads 1 to 3 bytes.
+func wyr3(s string, k int) uint64 {
+	return uint64(s[0])<<16 | uint64(s[k>>1])<<8 | uint64(s[k-1])
+}
+
+func wyhash(s string, seed uint64) uint64 {
+	n := len(s)
+	seed ^= wymix(seed^wyp[0], wyp[1])
+
+	var a, b uint64
+	switch {
+	case n == 0:
+	case n < 4:
+		a = wyr3(s, n)
+	case n <= 16:
+		off := (n >> 3) << 2
+		a = wyr4(s)<<32 | wyr4(s[off:])
+		b = wyr4(s[n-4:])<<32 | wyr4(s[n-4-off:])
+	default:
+		p := s
+		if len(p) > 48 {
+			see1, see2 := seed, seed
+			for len(p) > 48 {
+				seed = wymix(wyr8(p)^wyp[1], wyr8(p[8:])^seed)
+				see1 = wymix(wyr8(p[16:])^wyp[2], wyr8(p[24:])^see1)
+				see2 = wymix(wyr8(p[32:])^wyp[3], wyr8(p[40:])^see2)
+				p = p[48:]
+			}
+			seed ^= see1 ^ see2
+		}
+		for len(p) > 16 {
+			seed = wymix(wyr8(p)^wyp[1], wyr8(p[8:])^seed)
+			p = p[16:]
+		}
+		// the last 16 bytes, overlapping the ones already mixed if need be
+		a = wyr8(s[n-16:])
+		b = wyr8(s[n-8:])
+	}
+
+	b, a = bits.Mul64(a^wyp[1], b^seed)
+	return wymix(a^wyp[0]^uint64(n), b^wyp[1])
+}
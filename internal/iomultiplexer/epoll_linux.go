@@ -44,6 +44,14 @@ func (ep *Epoll) Subscribe(event Event) error {
 	return nil
 }
 
+// Unsubscribe stops watching the events of the given file descriptor
+func (ep *Epoll) Unsubscribe(fd int) error {
+	if err := syscall.EpollCtl(ep.fd, syscall.EPOLL_CTL_DEL, fd, nil); err != nil {
+		return fmt.Errorf("epoll unsubscribe: %w", err)
+	}
+	return nil
+}
+
 // Poll polls for all the subscribed events simultaneously
 // and returns all the events that were triggered
 // It blocks until at least one event is triggered or the timeout is reached
@@ -9,6 +9,9 @@ type IOMultiplexer interface {
 	// When the event is triggered, the Poll method will return it
 	Subscribe(event Event) error
 
+	// Unsubscribe stops watching the events of the given file descriptor
+	Unsubscribe(fd int) error
+
 	// Poll polls for all the subscribed events simultaneously
 	// and returns all the events that were triggered
 	// It blocks until at least one event is triggered or the timeout is reached
@@ -43,6 +43,15 @@ func (kq *KQueue) Subscribe(event Event) error {
 	return nil
 }
 
+// Unsubscribe stops watching the events of the given file descriptor
+func (kq *KQueue) Unsubscribe(fd int) error {
+	event := Event{Fd: fd, Op: OpRead}
+	if _, err := syscall.Kevent(kq.fd, []syscall.Kevent_t{event.toNative(syscall.EV_DELETE)}, nil, nil); err != nil {
+		return fmt.Errorf("kqueue unsubscribe: %w", err)
+	}
+	return nil
+}
+
 // Poll polls for all the subscribed events simultaneously
 // and returns all the events that were triggered
 // It blocks until at least one event is triggered or the timeout is reached
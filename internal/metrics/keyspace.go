@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TypeStats holds the number of keys of a type and the approximate memory
+// used by them, in bytes.
+type TypeStats struct {
+	Keys  int64
+	Bytes int64
+}
+
+// Composition is the composition of a keyspace by type, extrapolated from a
+// sample of its keys.
+type Composition struct {
+	Time    time.Time // time the keys were sampled at
+	Sampled int64     // Sampled is the number of keys sampled
+	Keys    int64     // Keys is the number of keys of the keyspace
+	Types   map[string]TypeStats
+}
+
+var keyspace = struct {
+	sync.Mutex
+	shards map[int]Composition
+}{shards: make(map[int]Composition)}
+
+// RecordComposition records the latest composition of the keyspace of a shard.
+func RecordComposition(shardID int, c Composition) {
+	keyspace.Lock()
+	defer keyspace.Unlock()
+
+	keyspace.shards[shardID] = c
+}
+
+// KeyspaceComposition returns the composition of the whole keyspace, summing
+// the latest compositions of the shards. Its time is the one of the oldest
+// sample, and it is zero if no shard was sampled yet.
+func KeyspaceComposition() Composition {
+	keyspace.Lock()
+	defer keyspace.Unlock()
+
+	total := Composition{Types: make(map[string]TypeStats)}
+	for _, c := range keyspace.shards {
+		if total.Time.IsZero() || c.Time.Before(total.Time) {
+			total.Time = c.Time
+		}
+		total.Sampled += c.Sampled
+		total.Keys += c.Keys
+		for t, s := range c.Types {
+			sum := total.Types[t]
+			sum.Keys += s.Keys
+			sum.Bytes += s.Bytes
+			total.Types[t] = sum
+		}
+	}
+	return total
+}
+
+// ResetComposition forgets the compositions recorded.
+func ResetComposition() {
+	keyspace.Lock()
+	defer keyspace.Unlock()
+
+	keyspace.shards = make(map[int]Composition)
+}
+
+// SortedTypes returns the types of the composition, sorted.
+func (c *Composition) SortedTypes() []string {
+	types := make([]string, 0, len(c.Types))
+	for t := range c.Types {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// WritePrometheus writes the composition of the keyspace to w in the text
+// exposition format of Prometheus.
+func WritePrometheus(w io.Writer) error {
+	c := KeyspaceComposition()
+	types := c.SortedTypes()
+
+	lines := []string{
+		"# HELP dicedb_keyspace_keys Number of keys by type, extrapolated from a sample of the keyspace.",
+		"# TYPE dicedb_keyspace_keys gauge",
+	}
+	for _, t := range types {
+		lines = append(lines, fmt.Sprintf("dicedb_keyspace_keys{type=%q} %d", t, c.Types[t].Keys))
+	}
+	lines = append(lines,
+		"# HELP dicedb_keyspace_bytes Approximate memory used by the keys by type, extrapolated from a sample of the keyspace.",
+		"# TYPE dicedb_keyspace_bytes gauge",
+	)
+	for _, t := range types {
+		lines = append(lines, fmt.Sprintf("dicedb_keyspace_bytes{type=%q} %d", t, c.Types[t].Bytes))
+	}
+	lines = append(lines,
+		"# HELP dicedb_keyspace_sampled_keys Number of keys sampled.",
+		"# TYPE dicedb_keyspace_sampled_keys gauge",
+		fmt.Sprintf("dicedb_keyspace_sampled_keys %d", c.Sampled),
+	)
+	if !c.Time.IsZero() {
+		lines = append(lines,
+			"# HELP dicedb_keyspace_sample_timestamp_seconds Unix time of the oldest sample of the keyspace.",
+			"# TYPE dicedb_keyspace_sample_timestamp_seconds gauge",
+			fmt.Sprintf("dicedb_keyspace_sample_timestamp_seconds %d", c.Time.Unix()),
+		)
+	}
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestKeyspaceComposition(t *testing.T) {
+	defer ResetComposition()
+
+	RecordComposition(0, Composition{
+		Time:    time.Unix(1010, 0),
+		Sampled: 2,
+		Keys:    4,
+		Types:   map[string]TypeStats{"string": {Keys: 2, Bytes: 200}, "zset": {Keys: 2, Bytes: 1000}},
+	})
+	RecordComposition(1, Composition{
+		Time:    time.Unix(1000, 0),
+		Sampled: 1,
+		Keys:    1,
+		Types:   map[string]TypeStats{"string": {Keys: 1, Bytes: 100}},
+	})
+	// a shard sampled again replaces its previous composition
+	RecordComposition(1, Composition{
+		Time:    time.Unix(1005, 0),
+		Sampled: 1,
+		Keys:    1,
+		Types:   map[string]TypeStats{"hash": {Keys: 1, Bytes: 50}},
+	})
+
+	c := KeyspaceComposition()
+	assert.Equal(t, time.Unix(1005, 0), c.Time)
+	assert.Equal(t, int64(3), c.Sampled)
+	assert.Equal(t, int64(5), c.Keys)
+	assert.DeepEqual(t, []string{"hash", "string", "zset"}, c.SortedTypes())
+	assert.Equal(t, TypeStats{Keys: 2, Bytes: 200}, c.Types["string"])
+
+	var buf bytes.Buffer
+	assert.NilError(t, WritePrometheus(&buf))
+	assert.Equal(t, `# HELP dicedb_keyspace_keys Number of keys by type, extrapolated from a sample of the keyspace.
+# TYPE dicedb_keyspace_keys gauge
+dicedb_keyspace_keys{type="hash"} 1
+dicedb_keyspace_keys{type="string"} 2
+dicedb_keyspace_keys{type="zset"} 2
+# HELP dicedb_keyspace_bytes Approximate memory used by the keys by type, extrapolated from a sample of the keyspace.
+# TYPE dicedb_keyspace_bytes gauge
+dicedb_keyspace_bytes{type="hash"} 50
+dicedb_keyspace_bytes{type="string"} 200
+dicedb_keyspace_bytes{type="zset"} 1000
+# HELP dicedb_keyspace_sampled_keys Number of keys sampled.
+# TYPE dicedb_keyspace_sampled_keys gauge
+dicedb_keyspace_sampled_keys 3
+# HELP dicedb_keyspace_sample_timestamp_seconds Unix time of the oldest sample of the keyspace.
+# TYPE dicedb_keyspace_sample_timestamp_seconds gauge
+dicedb_keyspace_sample_timestamp_seconds 1005
+`, buf.String())
+}
@@ -1,6 +1,7 @@
 package object
 
 import (
+	"github.com/axiomhq/hyperloglog"
 	"github.com/bytedance/sonic"
 )
 
@@ -22,8 +23,14 @@ func (obj *Obj) DeepCopy() *Obj {
 		sourceType, _ := ExtractTypeEncoding(obj)
 		switch sourceType {
 		case ObjTypeString:
-			sourceValue := obj.Value.(string)
-			newObj.Value = sourceValue
+			switch sourceValue := obj.Value.(type) {
+			case string:
+				newObj.Value = sourceValue
+			case *hyperloglog.Sketch:
+				newObj.Value = sourceValue.Clone()
+			default:
+				return nil
+			}
 
 		case ObjTypeJSON:
 			sourceValue := obj.Value
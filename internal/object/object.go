@@ -8,7 +8,11 @@ type Obj struct {
 	// But nonetheless, we can benchmark and see how that fares.
 	// For now, we continue with 32-bit integer to Store the LastAccessedAt
 	LastAccessedAt uint32
-	Value          interface{}
+	// ExpireAt is the time the object expires at, in unix-time-milliseconds, 0
+	// if it never expires. The expiry lives in the object rather than in a table
+	// keyed by its address, so that the copies of the object keep it.
+	ExpireAt uint64
+	Value    interface{}
 }
 
 var ObjTypeString uint8 = 0 << 4
@@ -16,6 +20,8 @@ var ObjTypeString uint8 = 0 << 4
 var ObjEncodingRaw uint8 = 0
 var ObjEncodingInt uint8 = 1
 var ObjEncodingEmbStr uint8 = 8
+var ObjEncodingLZ4 uint8 = 3  // large string stored LZ4 compressed, see store.CompressionStats
+var ObjEncodingCold uint8 = 5 // value of any type offloaded to the disk tier, see store.ColdTier
 
 var ObjTypeByteList uint8 = 1 << 4
 var ObjEncodingDeque uint8 = 4
@@ -41,6 +47,21 @@ var ObjTypeHashMap uint8 = 7 << 4
 var ObjTypeSortedSet uint8 = 8 << 4
 var ObjEncodingBTree uint8 = 8
 
+var ObjTypeStream uint8 = 9 << 4
+var ObjEncodingStream uint8 = 9
+
+var ObjTypeCuckoo uint8 = 10 << 4
+var ObjEncodingCF uint8 = 10
+
+var ObjTypeCountMinSketch uint8 = 11 << 4
+var ObjEncodingCMS uint8 = 13
+
+var ObjTypeTopK uint8 = 12 << 4
+var ObjEncodingTopK uint8 = 14
+
+var ObjTypeTDigest uint8 = 13 << 4
+var ObjEncodingTDigest uint8 = 15
+
 func ExtractTypeEncoding(obj *Obj) (e1, e2 uint8) {
 	return obj.TypeEncoding & 0b11110000, obj.TypeEncoding & 0b00001111
 }
@@ -4,21 +4,30 @@ import (
 	"github.com/dicedb/dice/internal/cmd"
 	"github.com/dicedb/dice/internal/comm"
 	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
 )
 
 type StoreOp struct {
-	SeqID       uint8          // SeqID is the sequence id of the operation within a single request (optional, may be used for ordering)
-	RequestID   uint32         // RequestID identifies the request that this StoreOp belongs to
-	Cmd         *cmd.DiceDBCmd // Cmd is the atomic Store command (e.g., GET, SET)
-	ShardID     uint8          // ShardID of the shard on which the Store command will be executed
-	WorkerID    string         // WorkerID is the ID of the worker that sent this Store operation
-	Client      *comm.Client   // Client that sent this Store operation. TODO: This can potentially replace the WorkerID in the future
-	HTTPOp      bool           // HTTPOp is true if this Store operation is an HTTP operation
-	WebsocketOp bool           // WebsocketOp is true if this Store operation is a Websocket operation
+	SeqID       uint8                     // SeqID is the sequence id of the operation within a single request (optional, may be used for ordering)
+	RequestID   uint32                    // RequestID identifies the request that this StoreOp belongs to
+	Cmd         *cmd.DiceDBCmd            // Cmd is the atomic Store command (e.g., GET, SET)
+	ShardID     uint8                     // ShardID of the shard on which the Store command will be executed
+	WorkerID    string                    // WorkerID is the ID of the worker that sent this Store operation
+	Client      *comm.Client              // Client that sent this Store operation. TODO: This can potentially replace the WorkerID in the future
+	HTTPOp      bool                      // HTTPOp is true if this Store operation is an HTTP operation
+	WebsocketOp bool                      // WebsocketOp is true if this Store operation is a Websocket operation
+	Batch       []*cmd.DiceDBCmd          // Batch holds the commands of a pipeline, evaluated in a single pass by the shard
+	Replicated  bool                      // Replicated is true if the commands of the batch come from the primary
+	Exec        func(store *dstore.Store) // Exec runs in the shard with exclusive access to its store, e.g. to take a consistent snapshot
+	CanBlock    bool                      // CanBlock is true if the client waits for a blocking command, e.g. BLPOP, to be served
+	DB          int                       // DB is the logical database the Store operation runs on, see SELECT
+	Watch       *dstore.KeyWatch          // Watch aborts the batch if a key it watches was modified, the keys being unwatched then, see WATCH
 }
 
 // StoreResponse represents the response of a Store operation.
 type StoreResponse struct {
-	RequestID    uint32             // RequestID that this StoreResponse belongs to
-	EvalResponse *eval.EvalResponse // Result of the Store operation, for now the type is set to []byte, but this can change in the future.
+	RequestID      uint32               // RequestID that this StoreResponse belongs to
+	EvalResponse   *eval.EvalResponse   // Result of the Store operation, for now the type is set to []byte, but this can change in the future.
+	BatchResponses []*eval.EvalResponse // Results of the commands of a batch, in the order they were received.
+	Aborted        bool                 // Aborted is true if the batch did not run as a key it watched was modified
 }
@@ -38,6 +38,14 @@ type (
 		ClientIdentifierID uint32                   // Helps identify qwatch client on httpserver side
 	}
 
+	// DeltaSubscription represents a subscription to watch the deltas of a sorted set.
+	DeltaSubscription struct {
+		Subscribe bool          // true for subscribe, false for unsubscribe
+		Key       string        // key of the sorted set to watch
+		ClientFD  int           // client file descriptor
+		Done      chan struct{} // closed once the subscription is processed, if not nil
+	}
+
 	// AdhocQueryResult represents the result of an adhoc query.
 	AdhocQueryResult struct {
 		Result      *[]sql.QueryResultRow
@@ -56,6 +64,7 @@ type (
 		WatchList    sync.Map                          // WatchList is a map of query string to their respective clients, type: map[string]*sync.Map[int]struct{}
 		QueryCache   common.ITable[string, CacheStore] // QueryCache is a map of fingerprints to their respective data caches
 		QueryCacheMu sync.RWMutex
+		DeltaWatch   sync.Map // DeltaWatch is a map of keys to the clients watching their deltas, type: map[string]*sync.Map[int]struct{}
 		logger       *slog.Logger
 	}
 
@@ -77,6 +86,9 @@ var (
 
 	// AdhocQueryChan is the channel to receive adhoc queries.
 	AdhocQueryChan chan AdhocQuery
+
+	// DeltaSubscriptionChan is the channel to receive updates about the subscriptions to the deltas of sorted sets.
+	DeltaSubscriptionChan chan DeltaSubscription
 )
 
 func NewClientIdentifier(clientIdentifierID int, isHTTPClient bool) ClientIdentifier {
@@ -110,6 +122,7 @@ func NewCacheStore() CacheStore {
 func NewQueryManager(logger *slog.Logger) *Manager {
 	QuerySubscriptionChan = make(chan QuerySubscription)
 	AdhocQueryChan = make(chan AdhocQuery, 1000)
+	DeltaSubscriptionChan = make(chan DeltaSubscription)
 	return &Manager{
 		WatchList:  sync.Map{},
 		QueryCache: NewQueryCacheStore(),
@@ -158,6 +171,16 @@ func (m *Manager) listenForSubscriptions(ctx context.Context) {
 			} else {
 				m.removeWatcher(&event.Query, client, event.QwatchClientChan)
 			}
+		case event := <-DeltaSubscriptionChan:
+			if event.Subscribe {
+				clients, _ := m.DeltaWatch.LoadOrStore(event.Key, &sync.Map{})
+				clients.(*sync.Map).Store(event.ClientFD, struct{}{})
+			} else {
+				m.removeDeltaWatcher(event.Key, event.ClientFD)
+			}
+			if event.Done != nil {
+				close(event.Done)
+			}
 		case <-ctx.Done():
 			return
 		}
@@ -178,6 +201,11 @@ func (m *Manager) watchKeys(ctx context.Context, watchChan <-chan dstore.QueryWa
 
 // processWatchEvent processes a single watch event.
 func (m *Manager) processWatchEvent(event dstore.QueryWatchEvent) {
+	if event.Operation == dstore.ZDelta {
+		m.notifyDeltaWatchers(event)
+		return
+	}
+
 	// Iterate over the watchlist to go through the query string
 	// and the corresponding client connections to that query string
 	m.WatchList.Range(func(key, value interface{}) bool {
@@ -259,7 +287,9 @@ func (m *Manager) notifyClients(query *sql.DSQLQuery, clients *sync.Map, queryRe
 			//   just be destroyed.
 			clientFD := clientIdentifier.ClientIdentifierID
 			// This is a regular client, use clientFD to send the response
-			go m.sendWithRetry(query, clientFD, encodedResult)
+			go m.sendWithRetry(clientFD, encodedResult, func() {
+				m.removeWatcher(query, NewClientIdentifier(clientFD, false), nil)
+			})
 		default:
 			m.logger.Warn("Invalid Client, response channel invalid.")
 		}
@@ -268,8 +298,42 @@ func (m *Manager) notifyClients(query *sql.DSQLQuery, clients *sync.Map, queryRe
 	})
 }
 
-// sendWithRetry writes data to a client file descriptor with retries. It writes with an exponential backoff.
-func (m *Manager) sendWithRetry(query *sql.DSQLQuery, clientFD int, data []byte) {
+// notifyDeltaWatchers sends the deltas of a sorted set to the clients watching
+// its key, as a push response of the form [ZDELTA, key, deltas], every delta
+// being [member, old score, new score, rank change].
+func (m *Manager) notifyDeltaWatchers(event dstore.QueryWatchEvent) {
+	clients, ok := m.DeltaWatch.Load(event.Key)
+	if !ok {
+		return
+	}
+
+	encodedResult := clientio.Encode(clientio.CreateDeltaPushResponse(event.Key, event.Deltas), false)
+	clients.(*sync.Map).Range(func(clientKey, _ interface{}) bool {
+		clientFD := clientKey.(int)
+		go m.sendWithRetry(clientFD, encodedResult, func() {
+			m.removeDeltaWatcher(event.Key, clientFD)
+		})
+		return true
+	})
+}
+
+// removeDeltaWatcher removes a client from the watchers of the deltas of a key.
+func (m *Manager) removeDeltaWatcher(key string, clientFD int) {
+	clients, ok := m.DeltaWatch.Load(key)
+	if !ok {
+		return
+	}
+
+	clients.(*sync.Map).Delete(clientFD)
+	if m.clientCount(clients.(*sync.Map)) == 0 {
+		m.DeltaWatch.Delete(key)
+	}
+	m.logger.Debug("client no longer watching deltas", slog.Int("client", clientFD), slog.String("key", key))
+}
+
+// sendWithRetry writes data to a client file descriptor with retries. It writes with an exponential backoff,
+// and calls remove if the client cannot be written to.
+func (m *Manager) sendWithRetry(clientFD int, data []byte, remove func()) {
 	maxRetries := 20
 	retryDelay := 20 * time.Millisecond
 
@@ -290,7 +354,7 @@ func (m *Manager) sendWithRetry(query *sql.DSQLQuery, clientFD int, data []byte)
 			slog.Int("client", clientFD),
 			slog.Any("error", err),
 		)
-		m.removeWatcher(query, NewClientIdentifier(clientFD, false), nil)
+		remove()
 		return
 	}
 }
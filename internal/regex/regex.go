@@ -1,34 +1,88 @@
 package regex
 
-// WildCardMatch checks if the key matches the pattern using * and ? as wildcards using two pointer approach
-func WildCardMatch(pattern, key string) bool {
-	patternLen := len(pattern)
-	keyLen := len(key)
-	patternIndex := 0
-	keyIndex := 0
-	starIndex := -1
-	kIndex := -1
+// GlobMatch checks if the key matches the glob-style pattern. It matches all the
+// patterns of keys, e.g. of KEYS, of the MATCH option of the SCAN commands and
+// of the LIKE operator of the queries, so that they all behave alike.
+// * matches any sequence of characters, ? any character, [abc] any character of
+// the set, [^abc] any character out of it, [a-z] any character of the range,
+// and \ escapes the next character. Unlike path.Match, / is not special and
+// malformed patterns never fail, an unterminated set ending with the pattern.
+func GlobMatch(pattern, key string) bool {
+	patternIndex, keyIndex := 0, 0
+	starIndex, kIndex := -1, 0
 
-	for keyIndex < keyLen {
-		if patternIndex < patternLen && (pattern[patternIndex] == '?' || pattern[patternIndex] == key[keyIndex]) {
-			patternIndex++
-			keyIndex++
-		} else if patternIndex < patternLen && pattern[patternIndex] == '*' {
-			starIndex = patternIndex
-			kIndex = keyIndex
-			patternIndex++
-		} else if starIndex != -1 {
-			patternIndex = starIndex + 1
-			kIndex++
-			keyIndex = kIndex
-		} else {
+	for keyIndex < len(key) {
+		if patternIndex < len(pattern) {
+			switch c := pattern[patternIndex]; c {
+			case '*':
+				starIndex, kIndex = patternIndex, keyIndex
+				patternIndex++
+				continue
+			case '?':
+				patternIndex++
+				keyIndex++
+				continue
+			case '[':
+				if end, ok := matchSet(pattern, patternIndex+1, key[keyIndex]); ok {
+					patternIndex = end
+					keyIndex++
+					continue
+				}
+			default:
+				if c == '\\' && patternIndex+1 < len(pattern) {
+					patternIndex++
+					c = pattern[patternIndex]
+				}
+				if c == key[keyIndex] {
+					patternIndex++
+					keyIndex++
+					continue
+				}
+			}
+		}
+
+		// on a mismatch, the last * matches one more character
+		if starIndex == -1 {
 			return false
 		}
+		kIndex++
+		patternIndex, keyIndex = starIndex+1, kIndex
 	}
 
-	for patternIndex < patternLen && pattern[patternIndex] == '*' {
+	for patternIndex < len(pattern) && pattern[patternIndex] == '*' {
 		patternIndex++
 	}
+	return patternIndex == len(pattern)
+}
+
+// matchSet checks if c belongs to the set of a glob pattern starting at
+// pattern[start], right after its [. It returns the index following the set.
+func matchSet(pattern string, start int, c byte) (end int, ok bool) {
+	i := start
+	negate := i < len(pattern) && pattern[i] == '^'
+	if negate {
+		i++
+	}
 
-	return patternIndex == patternLen
+	match := false
+	for ; i < len(pattern) && pattern[i] != ']'; i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			match = match || pattern[i] == c
+		case i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']':
+			lo, hi := pattern[i], pattern[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			match = match || (lo <= c && c <= hi)
+			i += 2
+		default:
+			match = match || pattern[i] == c
+		}
+	}
+	if i < len(pattern) {
+		i++ // the closing ]
+	}
+	return i, match != negate
 }
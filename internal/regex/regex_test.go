@@ -6,7 +6,7 @@ import (
 	"github.com/dicedb/dice/internal/server/utils"
 )
 
-func TestWildCardMatch(t *testing.T) {
+func TestGlobMatchWildcards(t *testing.T) {
 	tests := []struct {
 		pattern string
 		key     string
@@ -43,14 +43,14 @@ func TestWildCardMatch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.pattern+"_"+tt.key, func(t *testing.T) {
-			if got := WildCardMatch(tt.pattern, tt.key); got != tt.want {
-				t.Errorf("WildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+			if got := GlobMatch(tt.pattern, tt.key); got != tt.want {
+				t.Errorf("GlobMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
 			}
 		})
 	}
 }
 
-func BenchmarkWildCardMatch(b *testing.B) {
+func BenchmarkGlobMatch(b *testing.B) {
 	testCases := []struct {
 		pattern string
 		key     string
@@ -70,7 +70,47 @@ func BenchmarkWildCardMatch(b *testing.B) {
 	for _, tc := range testCases {
 		b.Run(tc.pattern, func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				WildCardMatch(tc.pattern, tc.key)
+				GlobMatch(tc.pattern, tc.key)
+			}
+		})
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "anything", true},
+		{"*", utils.EmptyStr, true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h*llo", "heeeello", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[b-a]llo", "hallo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{"h\\*llo", "h*llo", true},
+		{"h\\*llo", "hello", false},
+		{"h[\\]]llo", "h]llo", true},
+		{"user:*", "user:1/2", true},
+		{"*:*:name", "user:1:name", true},
+		{"*:*:name", "user:1:age", false},
+		{"a*b*c", "aXbYc", true},
+		{"a*b*c", "aXbYcZ", false},
+		{"[abc", "b", true},
+		{"[abc", "d", false},
+		{"trailing\\", "trailing\\", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.key, func(t *testing.T) {
+			if got := GlobMatch(tt.pattern, tt.key); got != tt.want {
+				t.Errorf("GlobMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,222 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// The change data capture (CDC) feed streams the writes of the replication
+// stream to downstream systems mirroring the keyspace, e.g. a search index, as
+// changes rather than RESP commands. A change carries the command propagated
+// along with the key it writes and, while subscribers are attached, summaries
+// of the value of the key before and after the command. The commands
+// propagated together, e.g. the commands of a batch, are enclosed in the
+// begin and commit boundaries of a transaction.
+//
+// The position of a change is the replication ID of the primary, the offset
+// of the replication stream once the change applied, the changes of a
+// transaction sharing the offset of its end, and the index of the change
+// within its transaction. A subscriber that disconnects resumes from the
+// position of the last change it processed, as long as the changes following
+// it are still held by the backlog of the primary. It performs a full sync
+// otherwise, e.g. by scanning the keyspace, and resumes from the position it
+// started at. The positions of another replication ID, e.g. once the server
+// restarted, are rejected as the offsets start over.
+
+const (
+	// cdcBacklogSize is the number of changes the primary holds so that the
+	// subscribers can resume from a past offset.
+	cdcBacklogSize = 1 << 14
+
+	// cdcBufferSize is the number of changes a subscriber can lag behind before
+	// the primary drops it. It then resumes from the last change it received.
+	cdcBufferSize = 1 << 12
+)
+
+const (
+	BoundaryBegin  = "begin"
+	BoundaryCommit = "commit"
+)
+
+var (
+	ErrPositionUnavailable = errors.New("the changes following this position are no longer held, a full sync is needed")
+	ErrSubscriberDropped   = errors.New("the subscriber lagged behind or the keyspace was replaced, resume from the last position received")
+)
+
+// Position is the position of a change in the CDC feed.
+type Position struct {
+	ReplID string `json:"replid"` // replication ID of the primary
+	Offset int64  `json:"offset"` // offset of the replication stream once the change applied
+	Seq    int    `json:"seq"`    // index of the change within its transaction, 0 if it applied alone
+}
+
+// before returns true if pos precedes other, both having the same replication
+// ID.
+func (pos Position) before(other Position) bool {
+	return pos.Offset < other.Offset || pos.Offset == other.Offset && pos.Seq < other.Seq
+}
+
+// Change is a write to the keyspace, as streamed to the CDC subscribers, or
+// the boundary of a transaction.
+type Change struct {
+	Position
+	Txn      uint64   `json:"txn,omitempty"`      // ID of the transaction the change is part of, 0 if it applied alone
+	Boundary string   `json:"boundary,omitempty"` // BoundaryBegin or BoundaryCommit for the boundaries of a transaction
+	Key      string   `json:"key,omitempty"`      // first key written by the command, if any
+	Cmd      string   `json:"cmd,omitempty"`
+	Args     []string `json:"args,omitempty"`
+	Old      *Summary `json:"old,omitempty"` // value of the key before the command, if known
+	New      *Summary `json:"new,omitempty"` // value of the key after the command, if known
+}
+
+// Summary describes a value without its content: its type, as reported by
+// TYPE or "none" if the key does not exist, and its approximate size in bytes.
+type Summary struct {
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+// Subscription is a CDC subscriber, receiving the changes following the
+// position it subscribed from.
+type Subscription struct {
+	id     uint64
+	p      *Primary
+	replay []Change    // replay are the changes of the backlog, received first
+	c      chan Change // c receives the live changes, closed once the subscriber is dropped
+}
+
+// Subscribe subscribes to the changes following the position from, replaying
+// the ones held by the backlog, or to the changes to come if from is nil. It
+// returns ErrPositionUnavailable if the changes following from are no longer
+// held, if from is beyond the current position or has another replication ID.
+func (p *Primary) Subscribe(from *Position) (*Subscription, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cur := p.position()
+	if from == nil {
+		from = &cur
+	}
+	if from.ReplID != p.replID || from.before(p.backlogStart) || cur.before(*from) {
+		return nil, ErrPositionUnavailable
+	}
+
+	i := sort.Search(len(p.backlog), func(i int) bool { return from.before(p.backlog[i].Position) })
+	p.lastSubID++
+	s := &Subscription{
+		id:     p.lastSubID,
+		p:      p,
+		replay: append([]Change(nil), p.backlog[i:]...),
+		c:      make(chan Change, cdcBufferSize),
+	}
+	p.subs[s.id] = s
+	p.watched.Add(1)
+	return s, nil
+}
+
+// Watched returns true if a CDC subscriber is attached, the summaries of the
+// values being only computed then.
+func (p *Primary) Watched() bool {
+	return p.watched.Load() > 0
+}
+
+// Next returns the next change, waiting for it if needed. It returns
+// ErrSubscriberDropped once the subscriber is dropped, or the error of ctx.
+func (s *Subscription) Next(ctx context.Context) (Change, error) {
+	if len(s.replay) > 0 {
+		c := s.replay[0]
+		s.replay = s.replay[1:]
+		return c, nil
+	}
+
+	select {
+	case c, ok := <-s.c:
+		if !ok {
+			return Change{}, ErrSubscriberDropped
+		}
+		return c, nil
+	case <-ctx.Done():
+		return Change{}, ctx.Err()
+	}
+}
+
+// Close unsubscribes s.
+func (s *Subscription) Close() {
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	s.p.unsubscribe(s)
+}
+
+// record appends the changes, propagated together, to the backlog and sends
+// them to the subscribers. It must be called with the lock held, once the
+// offset covers the changes.
+func (p *Primary) record(changes []Change) {
+	var txn uint64
+	if len(changes) > 1 {
+		p.lastTxn++
+		txn = p.lastTxn
+		changes = append(append([]Change{{Boundary: BoundaryBegin}}, changes...), Change{Boundary: BoundaryCommit})
+	}
+	for i := range changes {
+		changes[i].Position = Position{ReplID: p.replID, Offset: p.offset, Seq: i}
+		changes[i].Txn = txn
+	}
+	p.last = changes[len(changes)-1].Position
+
+	p.backlog = append(p.backlog, changes...)
+	if n := len(p.backlog) - cdcBacklogSize; n > 0 {
+		p.backlogStart = p.backlog[n-1].Position
+		p.backlog = p.backlog[n:]
+	}
+
+	for _, s := range p.subs {
+		if !s.send(changes) {
+			p.logger.Warn("dropping CDC subscriber lagging behind the changes")
+			p.unsubscribe(s)
+		}
+	}
+}
+
+// send sends the changes to s, returning false if s lags too far behind to
+// receive all of them.
+func (s *Subscription) send(changes []Change) bool {
+	for _, c := range changes {
+		select {
+		case s.c <- c:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// resetBacklog drops the backlog and the subscribers, once the keyspace is
+// replaced as a whole and can no longer be mirrored change by change.
+func (p *Primary) resetBacklog() {
+	p.backlog = nil
+	p.backlogStart = p.position()
+	for _, s := range p.subs {
+		p.unsubscribe(s)
+	}
+}
+
+// position returns the position of the last change, or the current offset if
+// commands that are not changes, e.g. PINGs, were propagated since. It must be
+// called with the lock held.
+func (p *Primary) position() Position {
+	if p.last.Offset == p.offset {
+		return p.last
+	}
+	return Position{ReplID: p.replID, Offset: p.offset}
+}
+
+// unsubscribe drops s. It must be called with the lock held.
+func (p *Primary) unsubscribe(s *Subscription) {
+	if p.subs[s.id] != s {
+		return
+	}
+	delete(p.subs, s.id)
+	close(s.c)
+	p.watched.Add(-1)
+}
@@ -0,0 +1,135 @@
+package replication
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/mocks"
+)
+
+func TestCDC(t *testing.T) {
+	p := NewPrimary(slog.New(mocks.SlogNoopHandler{}))
+	ctx := context.Background()
+
+	p.Propagate(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k1", "v1"}})
+	first := p.position()
+	sub, err := p.Subscribe(nil)
+	assert.NilError(t, err)
+	assert.Assert(t, p.Watched())
+
+	// the changes propagated together are a transaction
+	p.PropagateChanges([]Change{
+		{Key: "k2", Cmd: "SET", Args: []string{"k2", "v2"}, New: &Summary{Type: "string", Size: 2}},
+		{Key: "k2", Cmd: "PEXPIREAT", Args: []string{"k2", "1000"}},
+	})
+	var changes []Change
+	for i := 0; i < 4; i++ {
+		c, err := sub.Next(ctx)
+		assert.NilError(t, err)
+		changes = append(changes, c)
+	}
+	assert.Equal(t, BoundaryBegin, changes[0].Boundary)
+	assert.Equal(t, "SET", changes[1].Cmd)
+	assert.DeepEqual(t, &Summary{Type: "string", Size: 2}, changes[1].New)
+	assert.Equal(t, "PEXPIREAT", changes[2].Cmd)
+	assert.Equal(t, BoundaryCommit, changes[3].Boundary)
+	for i, c := range changes {
+		assert.Equal(t, uint64(1), c.Txn)
+		assert.DeepEqual(t, Position{ReplID: first.ReplID, Offset: p.Offset(), Seq: i}, c.Position)
+	}
+	sub.Close()
+	assert.Assert(t, !p.Watched())
+
+	// a subscriber resumes from the position of the last change it processed,
+	// even within a transaction
+	sub, err = p.Subscribe(&first)
+	assert.NilError(t, err)
+	c, err := sub.Next(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, BoundaryBegin, c.Boundary)
+	sub.Close()
+	sub, err = p.Subscribe(&changes[1].Position)
+	assert.NilError(t, err)
+	c, err = sub.Next(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, "PEXPIREAT", c.Cmd)
+	sub.Close()
+	sub, err = p.Subscribe(&Position{ReplID: first.ReplID})
+	assert.NilError(t, err)
+	c, err = sub.Next(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, Change{Position: first, Cmd: "SET", Args: []string{"k1", "v1"}}, c)
+	sub.Close()
+
+	_, err = p.Subscribe(&Position{ReplID: first.ReplID, Offset: p.Offset() + 1})
+	assert.ErrorIs(t, err, ErrPositionUnavailable)
+	_, err = p.Subscribe(&Position{ReplID: first.ReplID, Offset: p.Offset(), Seq: 4})
+	assert.ErrorIs(t, err, ErrPositionUnavailable)
+
+	// the positions of another primary, e.g. before a restart, are rejected
+	other := NewPrimary(slog.New(mocks.SlogNoopHandler{}))
+	assert.Assert(t, other.replID != first.ReplID)
+	_, err = other.Subscribe(&first)
+	assert.ErrorIs(t, err, ErrPositionUnavailable)
+
+	// the PINGs are not changes
+	sub, err = p.Subscribe(nil)
+	assert.NilError(t, err)
+	p.Ping()
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = sub.Next(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the subscribers are dropped once the keyspace is replaced, and cannot
+	// resume from before
+	pos := p.position()
+	p.Close()
+	_, err = sub.Next(context.Background())
+	assert.ErrorIs(t, err, ErrSubscriberDropped)
+	_, err = p.Subscribe(&first)
+	assert.ErrorIs(t, err, ErrPositionUnavailable)
+	_, err = p.Subscribe(&pos)
+	assert.NilError(t, err)
+}
+
+func TestCDCBacklog(t *testing.T) {
+	p := NewPrimary(slog.New(mocks.SlogNoopHandler{}))
+
+	// a subscriber lagging behind is dropped
+	sub, err := p.Subscribe(nil)
+	assert.NilError(t, err)
+	for i := 0; i < cdcBacklogSize+1; i++ {
+		p.Propagate(&cmd.DiceDBCmd{Cmd: "INCR", Args: []string{"k"}})
+	}
+	for i := 0; i < cdcBufferSize; i++ {
+		_, err = sub.Next(context.Background())
+		assert.NilError(t, err)
+	}
+	_, err = sub.Next(context.Background())
+	assert.ErrorIs(t, err, ErrSubscriberDropped)
+
+	// the backlog only holds the latest changes
+	_, err = p.Subscribe(&Position{ReplID: p.replID})
+	assert.ErrorIs(t, err, ErrPositionUnavailable)
+	sub, err = p.Subscribe(&p.backlogStart)
+	assert.NilError(t, err)
+	assert.Equal(t, cdcBacklogSize, len(sub.replay))
+
+	// the position the backlog starts after may be within a transaction, the
+	// positions before it within the same transaction are no longer held
+	p.PropagateChanges([]Change{{Cmd: "INCR", Args: []string{"k"}}, {Cmd: "INCR", Args: []string{"k"}}})
+	for i := 0; i < cdcBacklogSize-2; i++ {
+		p.Propagate(&cmd.DiceDBCmd{Cmd: "INCR", Args: []string{"k"}})
+	}
+	assert.Equal(t, 1, p.backlogStart.Seq)
+	_, err = p.Subscribe(&Position{ReplID: p.replID, Offset: p.backlogStart.Offset})
+	assert.ErrorIs(t, err, ErrPositionUnavailable)
+	sub, err = p.Subscribe(&p.backlogStart)
+	assert.NilError(t, err)
+	assert.Equal(t, cdcBacklogSize, len(sub.replay))
+}
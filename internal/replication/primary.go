@@ -0,0 +1,284 @@
+package replication
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+// linkBufferSize is the number of commands a replica can lag behind before the
+// primary drops it. The replica then reconnects and performs a new full sync.
+const linkBufferSize = 1 << 16
+
+// PingPeriod is the period the primary pings the replicas at when no command
+// is propagated, so that they can tell how stale their dataset is, like
+// repl-ping-replica-period in Redis.
+const PingPeriod = 10 * time.Second
+
+var ErrUnknownReplica = errors.New("unknown replica, the main link is gone")
+
+// Primary streams the write commands executed by the shards to the replicas.
+//
+// A replica performs a full sync over two connections. Its main link sends
+// SyncCmd and receives an ID, then its snapshot link sends SnapshotCmd with that
+// ID. The snapshot is taken by the shard and, in the same step, Activate starts
+// streaming the commands executed after it on the main link. The replica
+// buffers this live stream while it loads the snapshot, so that the primary does
+// not have to hold the commands executed during the transfer.
+type Primary struct {
+	mu     sync.Mutex
+	offset int64 // number of bytes of the replication stream produced so far
+	lastID uint64
+	lastIO time.Time // lastIO is when a command was last propagated
+	links  map[uint64]*link
+	logger *slog.Logger
+
+	// The changes of the CDC feed, see Subscribe.
+	replID       string // replID identifies the positions of the changes, see Position
+	last         Position
+	lastTxn      uint64
+	lastSubID    uint64
+	backlog      []Change                 // backlog holds the latest changes, by position
+	backlogStart Position                 // backlogStart is the position the backlog starts after
+	subs         map[uint64]*Subscription // subs are the CDC subscribers, by ID
+	watched      atomic.Int32             // watched is the number of subscribers
+}
+
+// link is the main link of a replica.
+type link struct {
+	id         uint64
+	addr       string
+	listenPort string
+	conn       net.Conn
+	out        chan []byte
+	active     bool  // the snapshot has been taken, commands are streamed
+	sent       int64 // offset of the stream written to the replica
+	closeOnce  sync.Once
+}
+
+// LinkInfo describes a replica attached to the primary.
+type LinkInfo struct {
+	Addr       string
+	ListenPort string
+	Active     bool
+	Offset     int64
+}
+
+// NewPrimary returns a primary with a new random replication ID, the offsets
+// of the replication stream starting over.
+func NewPrimary(logger *slog.Logger) *Primary {
+	replID := newReplID()
+	return &Primary{
+		links:        make(map[uint64]*link),
+		logger:       logger,
+		replID:       replID,
+		last:         Position{ReplID: replID},
+		backlogStart: Position{ReplID: replID},
+		subs:         make(map[uint64]*Subscription),
+	}
+}
+
+// newReplID returns a random replication ID of 40 hex characters, like the
+// ones of Redis.
+func newReplID() string {
+	b := make([]byte, 20)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Propagate appends the command to the replication stream. It is called by the
+// shards, right after executing a write command.
+func (p *Primary) Propagate(c *cmd.DiceDBCmd) {
+	p.PropagateChanges([]Change{{Cmd: c.Cmd, Args: c.Args}})
+}
+
+// PropagateChanges appends the commands of the changes to the replication
+// stream, in order and with no other command interleaved, and streams the
+// changes to the CDC subscribers as a transaction if there are several.
+func (p *Primary) PropagateChanges(changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+	encoded := make([][]byte, len(changes))
+	for i, c := range changes {
+		encoded[i] = encodeCmd(c.Cmd, c.Args)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, b := range encoded {
+		p.write(b)
+	}
+	p.record(changes)
+}
+
+// write appends the encoded command b to the replication stream. It must be
+// called with the lock held.
+func (p *Primary) write(b []byte) {
+	p.offset += int64(len(b))
+	p.lastIO = time.Now()
+	for id, l := range p.links {
+		if !l.active {
+			continue
+		}
+
+		select {
+		case l.out <- b:
+		default:
+			p.logger.Warn("dropping replica lagging behind the replication stream", slog.String("addr", l.addr))
+			delete(p.links, id)
+			l.close()
+		}
+	}
+}
+
+func encodeCmd(name string, args []string) []byte {
+	tokens := make([]string, 0, len(args)+1)
+	tokens = append(tokens, name)
+	tokens = append(tokens, args...)
+	return clientio.Encode(tokens, false)
+}
+
+// Ping propagates a PING when no command was propagated for PingPeriod and a
+// replica is attached. It is called periodically by the shards. The PINGs do
+// not change the keyspace, they are not streamed to the CDC subscribers.
+func (p *Primary) Ping() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.links) > 0 && time.Since(p.lastIO) >= PingPeriod {
+		p.write(encodeCmd("PING", nil))
+	}
+}
+
+// Offset returns the number of bytes of the replication stream produced so far.
+func (p *Primary) Offset() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.offset
+}
+
+// Attach registers conn as the main link of a new replica listening on
+// listenPort, and replies with its ID. Commands are only streamed once the
+// snapshot of the replica has been taken, see Activate.
+func (p *Primary) Attach(conn net.Conn, listenPort string) error {
+	p.mu.Lock()
+	p.lastID++
+	l := &link{
+		id:         p.lastID,
+		addr:       conn.RemoteAddr().String(),
+		listenPort: listenPort,
+		conn:       conn,
+		out:        make(chan []byte, linkBufferSize),
+	}
+	p.links[l.id] = l
+	p.mu.Unlock()
+
+	if _, err := fmt.Fprintf(conn, "+%s %d\r\n", fullSyncReply, l.id); err != nil {
+		p.detach(l)
+		return err
+	}
+
+	// The replica does not send anything on the main link, reading it only
+	// detects the disconnection.
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		p.detach(l)
+	}()
+	return nil
+}
+
+// Activate starts streaming the replication stream to the main link of the
+// replica, and returns the offset it starts at. It must be called by the shard
+// along with taking the snapshot, so that every command is either part of the
+// snapshot or of the stream.
+func (p *Primary) Activate(id uint64) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l, ok := p.links[id]
+	if !ok || l.active {
+		return 0, ErrUnknownReplica
+	}
+
+	l.active = true
+	l.sent = p.offset
+	go p.stream(l)
+	return p.offset, nil
+}
+
+// stream writes the commands of the replication stream to the main link.
+func (p *Primary) stream(l *link) {
+	for b := range l.out {
+		if _, err := l.conn.Write(b); err != nil {
+			p.logger.Debug("replica main link failed", slog.String("addr", l.addr), slog.Any("error", err))
+			p.detach(l)
+			return
+		}
+
+		p.mu.Lock()
+		l.sent += int64(len(b))
+		p.mu.Unlock()
+	}
+}
+
+func (p *Primary) detach(l *link) {
+	p.mu.Lock()
+	if p.links[l.id] == l {
+		delete(p.links, l.id)
+	}
+	p.mu.Unlock()
+	l.close()
+}
+
+// Links returns the replicas attached to the primary, ordered by ID.
+func (p *Primary) Links() []LinkInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make([]uint64, 0, len(p.links))
+	for id := range p.links {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	infos := make([]LinkInfo, 0, len(ids))
+	for _, id := range ids {
+		l := p.links[id]
+		infos = append(infos, LinkInfo{Addr: l.addr, ListenPort: l.listenPort, Active: l.active, Offset: l.sent})
+	}
+	return infos
+}
+
+// Close disconnects all the replicas and the CDC subscribers, which cannot
+// resume from the changes before.
+func (p *Primary) Close() {
+	p.mu.Lock()
+	links := p.links
+	p.links = make(map[uint64]*link)
+	p.resetBacklog()
+	p.mu.Unlock()
+
+	for _, l := range links {
+		l.close()
+	}
+}
+
+func (l *link) close() {
+	l.closeOnce.Do(func() {
+		l.conn.Close()
+		close(l.out)
+	})
+}
@@ -0,0 +1,120 @@
+package replication
+
+import (
+	"bufio"
+	"bytes"
+	"log/slog"
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/mocks"
+)
+
+func TestPrimaryStream(t *testing.T) {
+	p := NewPrimary(slog.New(mocks.SlogNoopHandler{}))
+	server, client := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		assert.Check(t, p.Attach(server, "7380"))
+	}()
+	br := bufio.NewReader(client)
+	fields, err := readStatus(br, fullSyncReply, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, "1", fields[0])
+
+	// commands executed before the snapshot are not streamed
+	p.Propagate(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k1", "v1"}})
+	before := p.Offset()
+	assert.Equal(t, int64(len(clientio.Encode([]string{"SET", "k1", "v1"}, false))), before)
+
+	offset, err := p.Activate(1)
+	assert.NilError(t, err)
+	assert.Equal(t, before, offset)
+	_, err = p.Activate(1)
+	assert.ErrorIs(t, err, ErrUnknownReplica)
+
+	p.Propagate(&cmd.DiceDBCmd{Cmd: "DEL", Args: []string{"k1"}})
+	stream := newStreamBuffer()
+	go stream.fill(br)
+	cmds, size, err := stream.next()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(cmds))
+	assert.DeepEqual(t, &cmd.DiceDBCmd{Cmd: "DEL", Args: []string{"k1"}}, cmds[0])
+	assert.Equal(t, p.Offset()-offset, size)
+
+	links := p.Links()
+	assert.Equal(t, 1, len(links))
+	assert.Equal(t, "7380", links[0].ListenPort)
+	assert.Assert(t, links[0].Active)
+
+	// the main link is dropped once closed by the replica
+	client.Close()
+	_, _, err = stream.next()
+	assert.Assert(t, err != nil)
+	p.Close()
+	assert.Equal(t, 0, len(p.Links()))
+}
+
+func TestPrimaryPing(t *testing.T) {
+	p := NewPrimary(slog.New(mocks.SlogNoopHandler{}))
+
+	// nothing is propagated without replicas
+	p.Ping()
+	assert.Equal(t, int64(0), p.Offset())
+
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		assert.Check(t, p.Attach(server, "7380"))
+	}()
+	br := bufio.NewReader(client)
+	_, err := readStatus(br, fullSyncReply, 1)
+	assert.NilError(t, err)
+	_, err = p.Activate(1)
+	assert.NilError(t, err)
+
+	// the replicas are only pinged once the stream is idle for PingPeriod
+	p.Propagate(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k1", "v1"}})
+	offset := p.Offset()
+	p.Ping()
+	assert.Equal(t, offset, p.Offset())
+
+	p.mu.Lock()
+	p.lastIO = p.lastIO.Add(-PingPeriod)
+	p.mu.Unlock()
+	p.Ping()
+	assert.Equal(t, offset+int64(len(clientio.Encode([]string{"PING"}, false))), p.Offset())
+
+	stream := newStreamBuffer()
+	go stream.fill(br)
+	var cmds []*cmd.DiceDBCmd
+	for len(cmds) < 2 {
+		next, _, err := stream.next()
+		assert.NilError(t, err)
+		cmds = append(cmds, next...)
+	}
+	assert.DeepEqual(t, &cmd.DiceDBCmd{Cmd: "PING", Args: []string{}}, cmds[1])
+	p.Close()
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	data := clientio.Encode([]string{"SET", "k1", "v1"}, false)
+	assert.NilError(t, WriteSnapshot(&buf, 42, data))
+
+	offset, read, err := readSnapshot(bufio.NewReader(&buf))
+	assert.NilError(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.DeepEqual(t, data, read)
+
+	_, _, err = readSnapshot(bufio.NewReader(bytes.NewBufferString("-ERR unknown replica\r\n")))
+	assert.Error(t, err, "ERR unknown replica")
+
+	_, _, err = readSnapshot(bufio.NewReader(bytes.NewBufferString("+FULLSYNC 1\r\n")))
+	assert.ErrorIs(t, err, errUnexpectedReply)
+}
@@ -0,0 +1,85 @@
+// Package replication implements the primary-replica replication.
+//
+// The primary propagates the write commands it executes as a stream of RESP
+// commands. A replica first loads a snapshot of the dataset, then applies the
+// stream from the offset the snapshot was taken at. The snapshot and the stream
+// travel over two separate connections, see Primary.
+package replication
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SyncCmd is sent by a replica on its main link to start a full sync,
+	// along with the port it listens on.
+	SyncCmd = "REPLSYNC"
+	// SnapshotCmd is sent by a replica on its snapshot link to receive the
+	// snapshot, along with the ID returned on its main link and the latest
+	// version of the snapshot format it supports.
+	SnapshotCmd = "REPLSNAPSHOT"
+
+	fullSyncReply = "FULLSYNC"
+	snapshotReply = "SNAPSHOT"
+)
+
+var errUnexpectedReply = errors.New("unexpected reply from the primary")
+
+// IsHandshake returns true if the command opens a replication link.
+func IsHandshake(name string) bool {
+	return name == SyncCmd || name == SnapshotCmd
+}
+
+// WriteSnapshot sends the snapshot taken at offset over the snapshot link.
+func WriteSnapshot(w io.Writer, offset int64, data []byte) error {
+	if _, err := fmt.Fprintf(w, "+%s %d %d\r\n", snapshotReply, offset, len(data)); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readSnapshot reads the snapshot sent by WriteSnapshot.
+func readSnapshot(br *bufio.Reader) (offset int64, data []byte, err error) {
+	fields, err := readStatus(br, snapshotReply, 2)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if offset, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+		return 0, nil, errUnexpectedReply
+	}
+	size, err := strconv.Atoi(fields[1])
+	if err != nil || size < 0 {
+		return 0, nil, errUnexpectedReply
+	}
+
+	data = make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return 0, nil, err
+	}
+	return offset, data, nil
+}
+
+// readStatus reads a status reply made of the given word followed by n fields.
+func readStatus(br *bufio.Reader, word string, n int) ([]string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	if strings.HasPrefix(line, "-") {
+		return nil, errors.New(line[1:])
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "+"))
+	if !strings.HasPrefix(line, "+") || len(fields) != n+1 || fields[0] != word {
+		return nil, fmt.Errorf("%w: %q", errUnexpectedReply, line)
+	}
+	return fields[1:], nil
+}
@@ -0,0 +1,324 @@
+package replication
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+const (
+	// retryInterval is the delay before a replica reconnects after losing its primary.
+	retryInterval = time.Second
+	dialTimeout   = 5 * time.Second
+)
+
+// State of a replica.
+const (
+	StateConnect   = "connect"   // connecting to the primary
+	StateSync      = "sync"      // receiving and loading the snapshot
+	StateConnected = "connected" // applying the replication stream
+)
+
+// Applier applies the replicated data to the local dataset.
+type Applier interface {
+	// LoadSnapshot replaces the dataset with the one of the snapshot.
+	LoadSnapshot(data []byte) error
+	// Apply executes the commands of the replication stream, in order.
+	Apply(cmds []*cmd.DiceDBCmd) error
+	// SnapshotVersion returns the latest version of the snapshot format that
+	// LoadSnapshot supports, the primary sending the snapshot in that version
+	// at most.
+	SnapshotVersion() int
+}
+
+// Replica keeps the local dataset in sync with a primary. It reconnects and
+// performs a new full sync whenever the connection to the primary is lost.
+type Replica struct {
+	host       string
+	port       int
+	listenPort int
+	password   string
+	applier    Applier
+	logger     *slog.Logger
+
+	mu     sync.Mutex
+	state  string
+	offset int64     // offset of the replication stream applied locally
+	lastIO time.Time // lastIO is when the replica last received data from the primary
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// ReplicaInfo describes the state of a replica.
+type ReplicaInfo struct {
+	Host   string
+	Port   int
+	State  string
+	Offset int64
+}
+
+func NewReplica(host string, port, listenPort int, password string, applier Applier, logger *slog.Logger) *Replica {
+	return &Replica{
+		host:       host,
+		port:       port,
+		listenPort: listenPort,
+		password:   password,
+		applier:    applier,
+		logger:     logger,
+		state:      StateConnect,
+	}
+}
+
+// Start starts replicating in the background, till Stop is called.
+func (r *Replica) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		for {
+			err := r.sync(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			r.logger.Warn("replication from the primary interrupted",
+				slog.String("primary", r.addr()), slog.Any("error", err))
+			r.setState(StateConnect)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}()
+}
+
+// Stop stops replicating and waits for the connections to the primary to be closed.
+func (r *Replica) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+// Info returns the state of the replica.
+func (r *Replica) Info() ReplicaInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReplicaInfo{Host: r.host, Port: r.port, State: r.state, Offset: r.offset}
+}
+
+// Staleness returns for how long the replica has not received data from the
+// primary, the primary pinging the replicas when idle, see PingPeriod. It
+// returns false if the replica has never synced with the primary.
+func (r *Replica) Staleness() (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lastIO.IsZero() {
+		return 0, false
+	}
+	return time.Since(r.lastIO), true
+}
+
+func (r *Replica) addr() string {
+	return net.JoinHostPort(r.host, strconv.Itoa(r.port))
+}
+
+func (r *Replica) setState(state string) {
+	r.mu.Lock()
+	r.state = state
+	r.mu.Unlock()
+}
+
+// sync performs a full sync with the primary, then applies the replication
+// stream till the connection is lost or ctx is canceled.
+func (r *Replica) sync(ctx context.Context) error {
+	r.setState(StateConnect)
+
+	main, mainReader, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer main.Close()
+	stop := context.AfterFunc(ctx, func() { main.Close() })
+	defer stop()
+
+	if err := writeCommand(main, SyncCmd, strconv.Itoa(r.listenPort)); err != nil {
+		return err
+	}
+	fields, err := readStatus(mainReader, fullSyncReply, 1)
+	if err != nil {
+		return err
+	}
+	id := fields[0]
+
+	// The live stream is buffered while the snapshot is transferred and loaded
+	stream := newStreamBuffer()
+	go stream.fill(mainReader)
+
+	r.setState(StateSync)
+	offset, data, err := r.fetchSnapshot(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.applier.LoadSnapshot(data); err != nil {
+		return fmt.Errorf("could not load the snapshot: %w", err)
+	}
+
+	r.mu.Lock()
+	r.offset = offset
+	r.state = StateConnected
+	r.lastIO = time.Now()
+	r.mu.Unlock()
+	r.logger.Info("synced with the primary", slog.String("primary", r.addr()), slog.Int64("offset", offset))
+
+	for {
+		cmds, size, err := stream.next()
+		if len(cmds) > 0 {
+			if err := r.applier.Apply(cmds); err != nil {
+				return fmt.Errorf("could not apply the replication stream: %w", err)
+			}
+			r.mu.Lock()
+			r.offset += size
+			r.lastIO = time.Now()
+			r.mu.Unlock()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fetchSnapshot opens the snapshot link and receives the snapshot of the dataset.
+func (r *Replica) fetchSnapshot(ctx context.Context, id string) (offset int64, data []byte, err error) {
+	conn, br, err := r.dial(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := writeCommand(conn, SnapshotCmd, id, strconv.Itoa(r.applier.SnapshotVersion())); err != nil {
+		return 0, nil, err
+	}
+	return readSnapshot(br)
+}
+
+// dial connects to the primary, authenticating if a password is set.
+func (r *Replica) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.addr())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	if r.password != "" {
+		if err := writeCommand(conn, "AUTH", r.password); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		if _, err := readStatus(br, "OK", 0); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("could not authenticate: %w", err)
+		}
+	}
+	return conn, br, nil
+}
+
+func writeCommand(conn net.Conn, args ...string) error {
+	_, err := conn.Write(clientio.Encode(args, false))
+	return err
+}
+
+// streamBuffer holds the commands received on the main link till they are applied.
+type streamBuffer struct {
+	mu    sync.Mutex
+	cmds  []*cmd.DiceDBCmd
+	size  int64
+	err   error
+	ready chan struct{} // signaled when commands or an error are available
+}
+
+func newStreamBuffer() *streamBuffer {
+	return &streamBuffer{ready: make(chan struct{}, 1)}
+}
+
+// fill decodes the commands of the main link into the buffer till it fails.
+func (b *streamBuffer) fill(br *bufio.Reader) {
+	rp := clientio.NewRESPParser(bufio.NewReadWriter(br, nil))
+	for {
+		value, err := rp.DecodeOne()
+		var c *cmd.DiceDBCmd
+		var size int
+		if err == nil {
+			c, size, err = toCommand(value)
+		}
+
+		b.mu.Lock()
+		if err != nil {
+			b.err = err
+		} else {
+			b.cmds = append(b.cmds, c)
+			b.size += int64(size)
+		}
+		b.mu.Unlock()
+
+		select {
+		case b.ready <- struct{}{}:
+		default:
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// next waits for commands and returns all the buffered ones, along with the
+// size they span in the replication stream. The error is set once the main
+// link failed and the remaining commands are returned.
+func (b *streamBuffer) next() ([]*cmd.DiceDBCmd, int64, error) {
+	for {
+		b.mu.Lock()
+		cmds, size, err := b.cmds, b.size, b.err
+		b.cmds, b.size = nil, 0
+		b.mu.Unlock()
+
+		if len(cmds) > 0 || err != nil {
+			return cmds, size, err
+		}
+		<-b.ready
+	}
+}
+
+// toCommand converts a decoded RESP array to a command, and returns the size of
+// its encoding.
+func toCommand(value interface{}) (*cmd.DiceDBCmd, int, error) {
+	tokens, ok := value.([]interface{})
+	if !ok || len(tokens) == 0 {
+		return nil, 0, fmt.Errorf("%w: %v", errUnexpectedReply, value)
+	}
+
+	args := make([]string, len(tokens))
+	for i, token := range tokens {
+		if args[i], ok = token.(string); !ok {
+			return nil, 0, fmt.Errorf("%w: %v", errUnexpectedReply, value)
+		}
+	}
+
+	size := len(clientio.Encode(args, false))
+	return &cmd.DiceDBCmd{Cmd: args[0], Args: args[1:]}, size, nil
+}
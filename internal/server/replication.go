@@ -0,0 +1,279 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/comm"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/replication"
+	"github.com/dicedb/dice/internal/shard"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The AsyncServer serves all its clients with shard 0, the replication works
+// on this shard as well: snapshots are taken from it and the replication
+// stream is applied to it.
+const replicationShard = 0
+
+// handleReplicationLink hands the connection of the client over to the
+// replication, as the main link or the snapshot link of a replica.
+func (s *AsyncServer) handleReplicationLink(client *comm.Client, c *cmd.DiceDBCmd) error {
+	conn, err := s.detachClient(client)
+	if err != nil {
+		return err
+	}
+
+	if c.Cmd == replication.SyncCmd {
+		listenPort := ""
+		if len(c.Args) > 0 {
+			listenPort = c.Args[0]
+		}
+		s.logger.Info("replica attached", slog.String("addr", client.Addr), slog.String("listening_port", listenPort))
+		return s.shardManager.Primary().Attach(conn, listenPort)
+	}
+
+	go s.sendSnapshot(conn, c.Args)
+	return nil
+}
+
+// detachClient removes the client from the event loop and returns its
+// connection as a net.Conn, to be served by its own goroutines.
+func (s *AsyncServer) detachClient(client *comm.Client) (net.Conn, error) {
+	if err := s.multiplexer.Unsubscribe(client.Fd); err != nil {
+		return nil, err
+	}
+
+	// FileConn duplicates the file descriptor, the original one is closed along with f
+	f := os.NewFile(uintptr(client.Fd), client.Addr)
+	conn, err := net.FileConn(f)
+	s.forgetClient(client.Fd)
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return conn, err
+}
+
+// sendSnapshot takes the snapshot of the dataset and sends it over the snapshot
+// link of the replica whose ID is in args, followed by the latest snapshot
+// version it supports. Taking the snapshot activates the main link of the
+// replica, which then receives the commands executed after it.
+func (s *AsyncServer) sendSnapshot(conn net.Conn, args []string) {
+	defer conn.Close()
+
+	var id uint64
+	var err error
+	// the replicas predating the versioning of the snapshots send no version
+	version := 1
+	switch {
+	case len(args) < 1 || len(args) > 2:
+		err = errors.New(diceerrors.SyntaxErr)
+	case len(args) == 2:
+		if version, err = strconv.Atoi(args[1]); err == nil && version < 1 {
+			err = errors.New(diceerrors.SyntaxErr)
+		}
+		version = min(version, eval.SnapshotVersion)
+	}
+	if err == nil {
+		id, err = strconv.ParseUint(args[0], 10, 64)
+	}
+
+	var data bytes.Buffer
+	var offset int64
+	if err == nil {
+		s.shardManager.Exec(replicationShard, func(store *dstore.Store) {
+			if _, err = eval.ExportSnapshot(&data, store, version); err == nil {
+				offset, err = s.shardManager.Primary().Activate(id)
+			}
+		})
+	}
+	if err != nil {
+		s.logger.Warn("could not send the snapshot to the replica", slog.Any("error", err))
+		if _, werr := conn.Write(diceerrors.NewErrWithMessage(err.Error())); werr != nil {
+			s.logger.Debug("failed to notify the replica", slog.Any("error", werr))
+		}
+		return
+	}
+
+	if err := replication.WriteSnapshot(conn, offset, data.Bytes()); err != nil {
+		s.logger.Warn("could not send the snapshot to the replica", slog.Any("error", err))
+		return
+	}
+	s.logger.Info("snapshot sent to the replica",
+		slog.String("addr", conn.RemoteAddr().String()),
+		slog.Int("bytes", data.Len()),
+		slog.Int64("offset", offset),
+	)
+}
+
+// replicaOf handles REPLICAOF host port, which makes the server a replica of
+// the given primary, and REPLICAOF NO ONE, which turns it back into a primary.
+func (s *AsyncServer) replicaOf(args []string) []byte {
+	if len(args) != 2 {
+		return diceerrors.NewErrArity("REPLICAOF")
+	}
+
+	if strings.EqualFold(args[0], "NO") && strings.EqualFold(args[1], "ONE") {
+		if s.replica != nil {
+			s.replica.Stop()
+			s.replica = nil
+			s.setReplicaStore(false)
+			s.logger.Info("replication stopped, serving as a primary")
+		}
+		return clientio.RespOK
+	}
+
+	port, err := strconv.Atoi(args[1])
+	if err != nil || port <= 0 || port > 65535 {
+		return diceerrors.NewErrWithMessage("Invalid master port")
+	}
+
+	if s.replica != nil {
+		s.replica.Stop()
+	}
+	s.setReplicaStore(true)
+	s.replica = replication.NewReplica(args[0], port, config.DiceConfig.Server.Port, config.DiceConfig.Auth.Password,
+		shardApplier{manager: s.shardManager}, s.logger)
+	s.replica.Start()
+	s.logger.Info("replicating from the primary", slog.String("host", args[0]), slog.Int("port", port))
+
+	return clientio.RespOK
+}
+
+// setReplicaStore switches the expiration of the keys between the primary mode,
+// where they are deleted once expired, and the replica mode, where they are
+// deleted only once the primary propagates their deletion.
+func (s *AsyncServer) setReplicaStore(replica bool) {
+	s.shardManager.Exec(replicationShard, func(store *dstore.Store) {
+		store.SetReplica(replica)
+	})
+}
+
+// replicaTooStale returns true if the server is a replica which has not heard
+// from its primary within config.DiceConfig.Server.ReplicaMaxStaleness, or has
+// never synced with it. The replica then refuses the read-only commands, so
+// that the clients read from the primary instead. A zero max staleness lets
+// the replica serve stale reads.
+func (s *AsyncServer) replicaTooStale() bool {
+	maxStaleness := config.Current().Server.ReplicaMaxStaleness
+	if s.replica == nil || maxStaleness <= 0 {
+		return false
+	}
+	staleness, ok := s.replica.Staleness()
+	return !ok || staleness > maxStaleness
+}
+
+// role returns the ROLE reply, describing the replication state of the server.
+func (s *AsyncServer) role() []byte {
+	if s.replica != nil {
+		info := s.replica.Info()
+		return clientio.Encode([]interface{}{"slave", info.Host, info.Port, info.State, info.Offset}, false)
+	}
+
+	primary := s.shardManager.Primary()
+	replicas := make([]interface{}, 0)
+	for _, l := range primary.Links() {
+		replicas = append(replicas, []interface{}{remoteIP(l.Addr), l.ListenPort, strconv.FormatInt(l.Offset, 10)})
+	}
+	return clientio.Encode([]interface{}{"master", primary.Offset(), replicas}, false)
+}
+
+// shardApplier applies the replicated data to the shard of the AsyncServer.
+type shardApplier struct {
+	manager *shard.ShardManager
+}
+
+func (a shardApplier) LoadSnapshot(data []byte) error {
+	// The replicas of this server cannot follow the reset of the dataset, they
+	// are disconnected so that they sync again.
+	a.manager.Primary().Close()
+
+	var err error
+	a.manager.Exec(replicationShard, func(store *dstore.Store) {
+		store.ResetStore()
+		_, err = eval.ImportRESP(bytes.NewReader(data), store)
+	})
+	return err
+}
+
+func (a shardApplier) SnapshotVersion() int {
+	return eval.SnapshotVersion
+}
+
+func (a shardApplier) Apply(cmds []*cmd.DiceDBCmd) error {
+	pipeline := a.manager.NewReplicationPipeline()
+	for _, c := range cmds {
+		pipeline.Queue(c.Cmd, c.Args...)
+	}
+	_, err := pipeline.Exec(context.Background())
+	return err
+}
+
+// The clients of a primary are issued a session token with each write, the
+// offset its replication stream reached once the write was propagated, see
+// CLIENT TOKEN. A client reading from a replica hands the token over with
+// CLIENT READAFTER, the replica then holding back its commands until it
+// applied the stream up to the token, for
+// config.DiceConfig.Server.ReplicaTokenTimeout at most. The client so reads
+// its own writes whatever the replica it reads from, the views included. The
+// tokens are only comparable along the stream of one primary: they mean
+// nothing to the replicas of another one, e.g. after a failover.
+
+// issueToken sets the session token of the client once it wrote.
+func (s *AsyncServer) issueToken(c *comm.Client) {
+	c.Token = s.shardManager.Primary().Offset()
+}
+
+// behindToken returns true if the server is a replica which has not caught up
+// with the token the client reads after. The CLIENT commands are never held
+// back, so that the client can always lower its token, and the commands of a
+// transaction are held back as a whole by EXEC.
+func (s *AsyncServer) behindToken(diceDBCmd *cmd.DiceDBCmd, c *comm.Client) bool {
+	if s.replica == nil || c.ReadAfter <= 0 || diceDBCmd.Cmd == "CLIENT" {
+		return false
+	}
+	if c.IsTxn && diceDBCmd.Cmd != eval.ExecCmdMeta.Name {
+		return false
+	}
+	return s.replica.Info().Offset < c.ReadAfter
+}
+
+// clientToken handles CLIENT TOKEN, which returns the session token of the
+// client, 0 until it writes, and CLIENT READAFTER token, which sets the token
+// the replica must have caught up with to serve the client, 0 to read whatever
+// the replica holds. It returns false for the other subcommands.
+func (s *AsyncServer) clientToken(args []string, c *comm.Client) ([]byte, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case eval.Token:
+		if len(args) != 1 {
+			return diceerrors.NewErrArity("CLIENT|TOKEN"), true
+		}
+		return clientio.Encode(c.Token, false), true
+	case eval.ReadAfter:
+		if len(args) != 2 {
+			return diceerrors.NewErrArity("CLIENT|READAFTER"), true
+		}
+		token, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || token < 0 {
+			return clientio.Encode(diceerrors.ErrIntegerOutOfRange, false), true
+		}
+		c.ReadAfter = token
+		return clientio.RespOK, true
+	default:
+		return nil, false
+	}
+}
@@ -39,6 +39,7 @@ type Server struct {
 	connBacklogSize int
 	wm              *worker.WorkerManager
 	sm              *shard.ShardManager
+	eventLoop       *worker.EventLoop // eventLoop serves the connections when enabled, instead of a goroutine each
 	globalErrorChan chan error
 	logger          *slog.Logger
 }
@@ -64,9 +65,23 @@ func (s *Server) Run(ctx context.Context) (err error) {
 
 	defer s.ReleasePort()
 
-	// Start a go routine to accept connections
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 	wg := &sync.WaitGroup{}
+
+	if config.EnableEventLoop {
+		if s.eventLoop, err = worker.NewEventLoop(config.DiceConfig.Server.MaxClients, s.sm, s.wm, s.globalErrorChan, s.logger); err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.eventLoop.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				errChan <- fmt.Errorf("event loop failed %w", err)
+			}
+		}()
+	}
+
+	// Start a go routine to accept connections
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		defer wg.Done()
@@ -168,6 +183,13 @@ func (s *Server) AcceptConnectionRequests(ctx context.Context, wg *sync.WaitGrou
 				return fmt.Errorf("error accepting connection: %w", err)
 			}
 
+			if s.eventLoop != nil {
+				if err := s.eventLoop.AddConn(clientFD, GenerateUniqueWorkerID()); err != nil {
+					return err
+				}
+				continue
+			}
+
 			// Register a new worker for the client
 			ioHandler, err := netconn.NewIOHandler(clientFD, s.logger)
 			if err != nil {
@@ -8,6 +8,8 @@ import (
 	"io"
 	"log/slog"
 	"net"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -24,16 +26,30 @@ import (
 	"github.com/dicedb/dice/internal/iomultiplexer"
 	"github.com/dicedb/dice/internal/ops"
 	"github.com/dicedb/dice/internal/querymanager"
+	"github.com/dicedb/dice/internal/replication"
 	"github.com/dicedb/dice/internal/shard"
 	dstore "github.com/dicedb/dice/internal/store"
 )
 
+const (
+	// idleTimerTick and idleTimerSlots size the timer wheel tracking the idle
+	// clients, a rotation of the wheel covers one minute.
+	idleTimerTick  = 100 * time.Millisecond
+	idleTimerSlots = 600
+)
+
 type AsyncServer struct {
 	serverFD               int
 	maxClients             int32
 	multiplexer            iomultiplexer.IOMultiplexer
 	multiplexerPollTimeout time.Duration
 	connectedClients       map[int]*comm.Client
+	connLimiter            *comm.ConnLimiter // enforces maxclients and the per-IP limit on the accepted connections
+	idleTimers             *comm.TimerWheel  // closes the clients idle for longer than their timeout
+	lastClientID           uint64
+	replica                *replication.Replica // set when the server replicates a primary, see REPLICAOF
+	pausedClients          []*pausedClient      // the clients whose commands are held back by CLIENT PAUSE or CLIENT READAFTER, in the order they were paused
+	usersVersion           uint64               // version of the users the sessions were last re-evaluated against
 	queryWatcher           *querymanager.Manager
 	shardManager           *shard.ShardManager
 	ioChan                 chan *ops.StoreResponse     // The server acts like a worker today, this behavior will change once IOThreads are introduced and each client gets its own worker.
@@ -43,25 +59,25 @@ type AsyncServer struct {
 
 // NewAsyncServer initializes a new AsyncServer
 func NewAsyncServer(shardManager *shard.ShardManager, watchChan chan dstore.QueryWatchEvent, logger *slog.Logger) *AsyncServer {
+	cfg := config.Current()
 	return &AsyncServer{
-		maxClients:             config.DiceConfig.Server.MaxClients,
+		maxClients:             cfg.Server.MaxClients,
 		connectedClients:       make(map[int]*comm.Client),
+		connLimiter:            comm.NewConnLimiter(int(cfg.Server.MaxClients), int(cfg.Server.MaxClientsPerIP)),
+		idleTimers:             comm.NewTimerWheel(idleTimerTick, idleTimerSlots, time.Now()),
 		shardManager:           shardManager,
 		queryWatcher:           querymanager.NewQueryManager(logger),
-		multiplexerPollTimeout: config.DiceConfig.Server.MultiplexerPollTimeout,
+		multiplexerPollTimeout: cfg.Server.MultiplexerPollTimeout,
 		ioChan:                 make(chan *ops.StoreResponse, 1000),
 		watchChan:              watchChan,
 		logger:                 logger,
 	}
 }
 
-// SetupUsers initializes the default user for the server
+// SetupUsers initializes the default user for the server, which needs no
+// password if none is configured, till ACL SETUSER gives it one or resetpass.
 func (s *AsyncServer) SetupUsers() error {
-	user, err := auth.UserStore.Add(config.DiceConfig.Auth.UserName)
-	if err != nil {
-		return err
-	}
-	return user.SetPassword(config.DiceConfig.Auth.Password)
+	return auth.UserStore.SetupDefault(config.DiceConfig.Auth.UserName, config.DiceConfig.Auth.Password)
 }
 
 // FindPortAndBind binds the server to the given host and port
@@ -129,12 +145,17 @@ func (s *AsyncServer) InitiateShutdown() {
 
 	s.shardManager.UnregisterWorker("server")
 
-	// Close all client connections
-	for fd := range s.connectedClients {
-		if err := syscall.Close(fd); err != nil {
+	if s.replica != nil {
+		s.replica.Stop()
+	}
+
+	// Close all client connections. Their watches are left to the stores, which
+	// go away along with the shards.
+	for fd, c := range s.connectedClients {
+		c.Watch = nil
+		if err := s.closeClient(fd); err != nil {
 			s.logger.Warn("failed to close client connection", slog.Any("error", err))
 		}
-		delete(s.connectedClients, fd)
 	}
 }
 
@@ -229,18 +250,43 @@ func (s *AsyncServer) eventLoop(ctx context.Context) error {
 					}
 				}
 			}
+
+			s.closeIdleClients(time.Now())
+			s.revokeSessions()
+			s.resumePausedClients()
 		}
 	}
 }
 
 // acceptConnection accepts a new client connection and subscribes to read events on the connection.
+// Connections exceeding the maxclients or the per-IP limit are sent an error and closed right away.
 func (s *AsyncServer) acceptConnection() error {
-	fd, _, err := syscall.Accept(s.serverFD)
+	fd, sa, err := syscall.Accept(s.serverFD)
 	if err != nil {
 		return err
 	}
 
-	s.connectedClients[fd] = comm.NewClient(fd)
+	addr := sockaddrToString(sa)
+	if err := s.connLimiter.Acquire(remoteIP(addr)); err != nil {
+		if _, werr := syscall.Write(fd, clientio.Encode(err, false)); werr != nil {
+			s.logger.Debug("failed to notify rejected client", slog.Any("error", werr))
+		}
+		if cerr := syscall.Close(fd); cerr != nil {
+			s.logger.Warn("failed to close rejected client connection", slog.Any("error", cerr))
+		}
+		return fmt.Errorf("rejected connection from %s: %w", addr, err)
+	}
+
+	now := time.Now()
+	s.lastClientID++
+	client := comm.NewClient(fd)
+	client.ID = s.lastClientID
+	client.Addr = addr
+	client.CreatedAt = now
+	client.LastActive = now
+
+	s.connectedClients[fd] = client
+	s.scheduleIdleTimeout(client)
 	if err := syscall.SetNonblock(fd, true); err != nil {
 		return err
 	}
@@ -260,14 +306,22 @@ func (s *AsyncServer) handleClientEvent(event iomultiplexer.Event) error {
 
 	commands, hasAbort, err := readCommands(client)
 	if err != nil {
-		if err := syscall.Close(event.Fd); err != nil {
+		if err := s.closeClient(event.Fd); err != nil {
 			s.logger.Error("error closing client connection", slog.Any("error", err))
 		}
-		delete(s.connectedClients, event.Fd)
 		return err
 	}
 
+	client.LastActive = time.Now()
+	if s.revokeSession(client) {
+		return nil
+	}
+	if len(commands.Cmds) > 0 && replication.IsHandshake(commands.Cmds[0].Cmd) && client.Session.IsActive() {
+		return s.handleReplicationLink(client, commands.Cmds[0])
+	}
+
 	s.EvalAndRespond(commands, client)
+	s.scheduleIdleTimeout(client)
 	if hasAbort {
 		return diceerrors.ErrAborted
 	}
@@ -275,6 +329,133 @@ func (s *AsyncServer) handleClientEvent(event iomultiplexer.Event) error {
 	return nil
 }
 
+// closeClient closes the connection of the client and releases the resources held for it.
+func (s *AsyncServer) closeClient(fd int) error {
+	s.forgetClient(fd)
+	return syscall.Close(fd)
+}
+
+// forgetClient releases the resources held for the client, without closing its connection.
+func (s *AsyncServer) forgetClient(fd int) {
+	if client, ok := s.connectedClients[fd]; ok {
+		s.connLimiter.Release(remoteIP(client.Addr))
+		s.unwatch(client.Watch)
+		delete(s.connectedClients, fd)
+	}
+	s.idleTimers.Cancel(fd)
+}
+
+// scheduleIdleTimeout (re)arms the idle timer of the client. Clients watching a
+// query are subject to the subscriber idle timeout, as they may legitimately stay
+// silent while receiving updates. A timeout of 0 disables the check.
+func (s *AsyncServer) scheduleIdleTimeout(c *comm.Client) {
+	timeout := config.Current().Server.IdleTimeout
+	if c.Subscribed {
+		timeout = config.Current().Server.SubscriberIdleTimeout
+	}
+
+	if timeout <= 0 {
+		s.idleTimers.Cancel(c.Fd)
+		return
+	}
+	s.idleTimers.Schedule(c.Fd, timeout)
+}
+
+// closeIdleClients closes the connections whose idle timer fired.
+func (s *AsyncServer) closeIdleClients(now time.Time) {
+	for _, fd := range s.idleTimers.Advance(now) {
+		client, ok := s.connectedClients[fd]
+		if !ok {
+			continue
+		}
+
+		s.logger.Debug("closing idle client connection",
+			slog.Uint64("id", client.ID),
+			slog.String("addr", client.Addr),
+			slog.Duration("idle", client.IdleTime(now)),
+		)
+		if err := s.closeClient(fd); err != nil {
+			s.logger.Warn("failed to close idle client connection", slog.Any("error", err))
+		}
+	}
+}
+
+// revokeSessions re-evaluates the sessions of the clients once the users
+// changed, killing or downgrading the ones whose user lost access.
+func (s *AsyncServer) revokeSessions() {
+	version := auth.UserStore.Version()
+	if version == s.usersVersion {
+		return
+	}
+	s.usersVersion = version
+
+	for _, client := range s.connectedClients {
+		s.revokeSession(client)
+	}
+}
+
+// revokeSession re-evaluates the session of the client if the users changed,
+// and kills or downgrades it, as told by the revoke policy, if its user lost
+// access. It returns true if the client was killed.
+func (s *AsyncServer) revokeSession(c *comm.Client) bool {
+	if !c.Session.Revoked() {
+		return false
+	}
+
+	username := c.Session.User.Username
+	if auth.RevokePolicy() == auth.RevokeDowngrade {
+		s.logger.Info("downgrading the client of a user who lost access",
+			slog.Uint64("id", c.ID), slog.String("user", username))
+		c.Session.Downgrade()
+		return false
+	}
+
+	s.logger.Info("killing the client of a user who lost access",
+		slog.Uint64("id", c.ID), slog.String("user", username))
+	if err := s.closeClient(c.Fd); err != nil {
+		s.logger.Warn("failed to close client connection", slog.Any("error", err))
+	}
+	return true
+}
+
+// clientList returns the CLIENT LIST reply, one line per connected client ordered by id.
+func (s *AsyncServer) clientList() string {
+	clients := make([]*comm.Client, 0, len(s.connectedClients))
+	for _, c := range s.connectedClients {
+		clients = append(clients, c)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ID < clients[j].ID
+	})
+
+	now := time.Now()
+	var sb strings.Builder
+	for _, c := range clients {
+		sb.WriteString(c.Info(now))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func sockaddrToString(sa syscall.Sockaddr) string {
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return net.JoinHostPort(net.IP(sa.Addr[:]).String(), strconv.Itoa(sa.Port))
+	case *syscall.SockaddrInet6:
+		return net.JoinHostPort(net.IP(sa.Addr[:]).String(), strconv.Itoa(sa.Port))
+	default:
+		return ""
+	}
+}
+
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
 func handleMigratedResp(resp interface{}, buf *bytes.Buffer) {
 	// Process the incoming response by calling the handleResponse function.
 	// This function checks the response against known RESP formatted values
@@ -298,19 +479,140 @@ func handleMigratedResp(resp interface{}, buf *bytes.Buffer) {
 }
 
 func (s *AsyncServer) executeCommandToBuffer(diceDBCmd *cmd.DiceDBCmd, buf *bytes.Buffer, c *comm.Client) {
+	if diceDBCmd.Cmd == eval.ExecBatchCmdMeta.Name {
+		s.executeBatch(diceDBCmd, buf, c)
+		return
+	}
+
+	// The dataset of a replica is only modified by the replication stream
+	if s.replica != nil && eval.IsWriteCommand(diceDBCmd.Cmd) {
+		buf.Write(diceerrors.NewErrWithMessage(diceerrors.ReadOnlyErr))
+		return
+	}
+	if eval.IsReadOnlyCommand(diceDBCmd.Cmd) && s.replicaTooStale() {
+		buf.Write(diceerrors.NewErrWithMessage(diceerrors.StaleReplicaErr))
+		return
+	}
+
 	s.shardManager.GetShard(0).ReqChan <- &ops.StoreOp{
 		Cmd:      diceDBCmd,
 		WorkerID: "server",
 		ShardID:  0,
 		Client:   c,
+		DB:       c.DB,
+	}
+
+	resp := <-s.ioChan
+	if eval.IsWriteCommand(diceDBCmd.Cmd) {
+		s.issueToken(c)
+	}
+	writeEvalResponse(diceDBCmd, resp.EvalResponse, buf)
+}
+
+// executeBatch runs the commands of an EXECBATCH as a single batch of the
+// shard, which evaluates them back-to-back, and writes their replies as an
+// array.
+func (s *AsyncServer) executeBatch(diceDBCmd *cmd.DiceDBCmd, buf *bytes.Buffer, c *comm.Client) {
+	cmds, errResp := eval.ParseExecBatch(diceDBCmd.Args)
+	if errResp == nil {
+		errResp = authorizeBatch(cmds, c)
+	}
+	if errResp != nil {
+		buf.Write(errResp)
+		return
+	}
+	resp, errResp := s.runBatch(cmds, nil, c)
+	if errResp != nil {
+		buf.Write(errResp)
+		return
+	}
+	s.writeBatchResponses(cmds, resp.BatchResponses, buf)
+}
+
+// authorizeBatch returns the NOPERM error of the first command of a batch the
+// user of the client may not run, nil if it may run all of them.
+func authorizeBatch(cmds []*cmd.DiceDBCmd, c *comm.Client) []byte {
+	info := c.Info(time.Now())
+	for _, bc := range cmds {
+		if err := c.Session.Authorize(bc.Cmd, info); err != nil {
+			return clientio.Encode(err, false)
+		}
+	}
+	return nil
+}
+
+// runBatch runs the commands as a single batch of the shard, which evaluates
+// them back-to-back, unless one of the keys watched by watch was modified. It
+// returns an encoded error if the commands cannot run on this server.
+func (s *AsyncServer) runBatch(cmds []*cmd.DiceDBCmd, watch *dstore.KeyWatch, c *comm.Client) (*ops.StoreResponse, []byte) {
+	// The dataset of a replica is only modified by the replication stream
+	if s.replica != nil {
+		for _, bc := range cmds {
+			if eval.IsWriteCommand(bc.Cmd) {
+				s.unwatch(watch)
+				return nil, diceerrors.NewErrWithMessage(diceerrors.ReadOnlyErr)
+			}
+			if eval.IsReadOnlyCommand(bc.Cmd) && s.replicaTooStale() {
+				s.unwatch(watch)
+				return nil, diceerrors.NewErrWithMessage(diceerrors.StaleReplicaErr)
+			}
+		}
+	}
+
+	s.shardManager.GetShard(0).ReqChan <- &ops.StoreOp{
+		Batch:    cmds,
+		WorkerID: "server",
+		ShardID:  0,
+		Client:   c,
+		DB:       c.DB,
+		Watch:    watch,
 	}
 
 	resp := <-s.ioChan
+	for _, bc := range cmds {
+		if !resp.Aborted && writes(bc) {
+			s.issueToken(c)
+			break
+		}
+	}
+	return resp, nil
+}
 
+// writeBatchResponses writes the replies of the commands of a batch as an array.
+func (s *AsyncServer) writeBatchResponses(cmds []*cmd.DiceDBCmd, responses []*eval.EvalResponse, buf *bytes.Buffer) {
+	if _, err := fmt.Fprintf(buf, "*%d\r\n", len(cmds)); err != nil {
+		s.logger.Error("Error writing to buffer", slog.Any("error", err))
+		return
+	}
+	for i, bc := range cmds {
+		writeEvalResponse(bc, responses[i], buf)
+	}
+}
+
+// unwatch unwatches the keys watched by watch, in the shard holding them.
+func (s *AsyncServer) unwatch(watch *dstore.KeyWatch) {
+	if watch == nil {
+		return
+	}
+	s.shardManager.Exec(0, func(*dstore.Store) {
+		watch.Unwatch()
+	})
+}
+
+// writeEvalResponse writes the reply of the command evaluated by the shard,
+// rewritten to the one of the reference server if set, see eval.CompatReply.
+func writeEvalResponse(diceDBCmd *cmd.DiceDBCmd, resp *eval.EvalResponse, buf *bytes.Buffer) {
+	var reply bytes.Buffer
+	encodeEvalResponse(diceDBCmd, resp, &reply)
+	buf.Write(eval.CompatReply(config.Current().Server.ReplyCompat, diceDBCmd.Cmd, reply.Bytes()))
+}
+
+// encodeEvalResponse encodes the reply of the command evaluated by the shard.
+func encodeEvalResponse(diceDBCmd *cmd.DiceDBCmd, resp *eval.EvalResponse, buf *bytes.Buffer) {
 	val, ok := WorkerCmdsMeta[diceDBCmd.Cmd]
 	// TODO: Remove this conditional check and if (true) condition when all commands are migrated
 	if !ok {
-		buf.Write(resp.EvalResponse.Result.([]byte))
+		buf.Write(resp.Result.([]byte))
 	} else {
 		// If command type is Global then return the worker eval
 		if val.CmdType == Global {
@@ -318,10 +620,10 @@ func (s *AsyncServer) executeCommandToBuffer(diceDBCmd *cmd.DiceDBCmd, buf *byte
 			return
 		}
 		// Handle error case independently
-		if resp.EvalResponse.Error != nil {
-			handleMigratedResp(resp.EvalResponse.Error, buf)
+		if resp.Error != nil {
+			handleMigratedResp(resp.Error, buf)
 		}
-		handleMigratedResp(resp.EvalResponse.Result, buf)
+		handleMigratedResp(resp.Result, buf)
 		return
 	}
 }
@@ -380,13 +682,45 @@ func toArrayString(ai []interface{}) ([]string, error) {
 }
 
 func (s *AsyncServer) EvalAndRespond(cmds *cmd.RedisCmds, c *comm.Client) {
+	// the commands of a client paused run after the ones held back
+	for _, p := range s.pausedClients {
+		if p.client == c {
+			p.cmds = append(p.cmds, cmds.Cmds...)
+			return
+		}
+	}
+	s.evalAndRespond(cmds.Cmds, c, time.Time{})
+}
+
+// evalAndRespond runs the commands of the client, until one of them is held
+// back. until is the deadline of the wait of the first command for the replica
+// to catch up with the token of the client, zero if it has not waited yet.
+func (s *AsyncServer) evalAndRespond(cmds []*cmd.DiceDBCmd, c *comm.Client, until time.Time) {
 	var resp []byte
 	buf := bytes.NewBuffer(resp)
 
-	for _, diceDBCmd := range cmds.Cmds {
+	for i, diceDBCmd := range cmds {
 		if !s.isAuthenticated(diceDBCmd, c, buf) {
 			continue
 		}
+		if isPaused(diceDBCmd, c) {
+			s.pausedClients = append(s.pausedClients, &pausedClient{client: c, cmds: cmds[i:], until: until})
+			break
+		}
+		if s.behindToken(diceDBCmd, c) {
+			now := time.Now()
+			if until.IsZero() {
+				until = now.Add(config.Current().Server.ReplicaTokenTimeout)
+			}
+			if now.Before(until) {
+				s.pausedClients = append(s.pausedClients, &pausedClient{client: c, cmds: cmds[i:], until: until})
+				break
+			}
+			buf.Write(diceerrors.NewErrWithMessage(diceerrors.TokenTimeoutErr))
+			until = time.Time{}
+			continue
+		}
+		until = time.Time{}
 
 		if c.IsTxn {
 			s.handleTransactionCommand(diceDBCmd, c, buf)
@@ -398,11 +732,84 @@ func (s *AsyncServer) EvalAndRespond(cmds *cmd.RedisCmds, c *comm.Client) {
 	s.writeResponse(c, buf)
 }
 
+// pausedClient is a client whose commands are held back by CLIENT PAUSE, or
+// until the replica caught up with its token, along with the commands left to
+// run.
+type pausedClient struct {
+	client *comm.Client
+	cmds   []*cmd.DiceDBCmd
+	until  time.Time // deadline of the wait for the token, see behindToken
+}
+
+// isPaused returns true if the command is held back by CLIENT PAUSE. The CLIENT
+// commands are never held back, so that the pause can always be ended. The
+// commands of a transaction are held back as a whole by EXEC, in WRITE mode if
+// one of them is a write command, and so are the ones of an EXECBATCH.
+func isPaused(diceDBCmd *cmd.DiceDBCmd, c *comm.Client) bool {
+	mode, _ := comm.ClientPause(time.Now())
+	switch {
+	case mode == comm.PauseNone || diceDBCmd.Cmd == "CLIENT":
+		return false
+	case c.IsTxn:
+		if diceDBCmd.Cmd != eval.ExecCmdMeta.Name {
+			return false
+		}
+		if mode == comm.PauseAll {
+			return true
+		}
+		for _, queued := range c.Cqueue.Cmds {
+			if writes(queued) {
+				return true
+			}
+		}
+		return false
+	}
+	return mode == comm.PauseAll || writes(diceDBCmd)
+}
+
+// writes returns true if the command may modify the keyspace, which an
+// EXECBATCH does if one of its commands does.
+func writes(diceDBCmd *cmd.DiceDBCmd) bool {
+	if diceDBCmd.Cmd != eval.ExecBatchCmdMeta.Name {
+		return eval.IsWriteCommand(diceDBCmd.Cmd)
+	}
+	cmds, _ := eval.ParseExecBatch(diceDBCmd.Args)
+	for _, bc := range cmds {
+		if eval.IsWriteCommand(bc.Cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// resumePausedClients runs the commands held back by CLIENT PAUSE, once the
+// pause ended or no longer holds them back, and the ones held back by CLIENT
+// READAFTER. The commands of the clients still paused are held back again.
+func (s *AsyncServer) resumePausedClients() {
+	if len(s.pausedClients) == 0 {
+		return
+	}
+
+	paused := s.pausedClients
+	s.pausedClients = nil
+	for _, p := range paused {
+		// the client may have disconnected while paused
+		if s.connectedClients[p.client.Fd] != p.client {
+			continue
+		}
+		s.evalAndRespond(p.cmds, p.client, p.until)
+	}
+}
+
 func (s *AsyncServer) isAuthenticated(diceDBCmd *cmd.DiceDBCmd, c *comm.Client, buf *bytes.Buffer) bool {
 	if diceDBCmd.Cmd != auth.Cmd && !c.Session.IsActive() {
 		buf.Write(clientio.Encode(errors.New("NOAUTH Authentication required"), false))
 		return false
 	}
+	if err := c.Session.Authorize(diceDBCmd.Cmd, c.Info(time.Now())); err != nil {
+		buf.Write(clientio.Encode(err, false))
+		return false
+	}
 	return true
 }
 
@@ -419,12 +826,25 @@ func (s *AsyncServer) handleTransactionCommand(diceDBCmd *cmd.DiceDBCmd, c *comm
 				slog.String("command", diceDBCmd.Cmd),
 			)
 		}
+	} else if diceDBCmd.Cmd == "WATCH" {
+		buf.Write(diceerrors.NewErrWithMessage("WATCH inside MULTI is not allowed"))
+	} else if serverCommands[diceDBCmd.Cmd] {
+		buf.Write(diceerrors.NewErrWithFormattedMessage("%s inside MULTI is not allowed", diceDBCmd.Cmd))
 	} else {
 		c.TxnQueue(diceDBCmd)
 		buf.Write(clientio.RespQueued)
 	}
 }
 
+// serverCommands are the commands run by the server rather than by the shards,
+// see handleNonTransactionCommand. They are not allowed inside MULTI, as the
+// transactions run as a batch of the shard.
+var serverCommands = map[string]bool{
+	"CLIENT":    true,
+	"REPLICAOF": true,
+	"ROLE":      true,
+}
+
 func (s *AsyncServer) handleNonTransactionCommand(diceDBCmd *cmd.DiceDBCmd, c *comm.Client, buf *bytes.Buffer) {
 	switch diceDBCmd.Cmd {
 	case eval.MultiCmdMeta.Name:
@@ -434,29 +854,83 @@ func (s *AsyncServer) handleNonTransactionCommand(diceDBCmd *cmd.DiceDBCmd, c *c
 		buf.Write(diceerrors.NewErrWithMessage("EXEC without MULTI"))
 	case eval.DiscardCmdMeta.Name:
 		buf.Write(diceerrors.NewErrWithMessage("DISCARD without MULTI"))
+	case "CLIENT":
+		// CLIENT LIST needs the connections, which are only known to the server
+		if len(diceDBCmd.Args) == 1 && strings.EqualFold(diceDBCmd.Args[0], "LIST") {
+			buf.Write(clientio.Encode(s.clientList(), false))
+			return
+		}
+		if reply, ok := s.clientToken(diceDBCmd.Args, c); ok {
+			buf.Write(reply)
+			return
+		}
+		s.executeCommandToBuffer(diceDBCmd, buf, c)
+	case "REPLICAOF":
+		buf.Write(s.replicaOf(diceDBCmd.Args))
+	case "ROLE":
+		buf.Write(s.role())
 	default:
 		s.executeCommandToBuffer(diceDBCmd, buf, c)
 	}
 }
 
+// executeTransaction runs the commands queued since MULTI atomically, as a
+// single batch of the shard, and writes their replies as an array, or nil if
+// one of the keys watched by the client was modified. The commands of the
+// EXECBATCHs queued run in the same batch, their replies being nested.
 func (s *AsyncServer) executeTransaction(c *comm.Client, buf *bytes.Buffer) {
-	cmds := c.Cqueue.Cmds
-	_, err := fmt.Fprintf(buf, "*%d\r\n", len(cmds))
-	if err != nil {
-		s.logger.Error("Error writing to buffer", slog.Any("error", err))
-		return
+	queued := c.Cqueue.Cmds
+	c.TxnDiscard()
+
+	cmds := make([]*cmd.DiceDBCmd, 0, len(queued))
+	batches := make([][]*cmd.DiceDBCmd, len(queued))
+	batchErrs := make([][]byte, len(queued))
+	for i, qc := range queued {
+		if qc.Cmd != eval.ExecBatchCmdMeta.Name {
+			cmds = append(cmds, qc)
+			continue
+		}
+		batches[i], batchErrs[i] = eval.ParseExecBatch(qc.Args)
+		if batchErrs[i] == nil {
+			batchErrs[i] = authorizeBatch(batches[i], c)
+		}
+		if batchErrs[i] == nil {
+			cmds = append(cmds, batches[i]...)
+		}
 	}
 
-	for _, cmd := range cmds {
-		s.executeCommandToBuffer(cmd, buf, c)
+	resp, errResp := s.runBatch(cmds, c.Watch, c)
+	if errResp != nil {
+		buf.Write(errResp)
+		return
+	}
+	if resp.Aborted {
+		buf.Write(clientio.RespNIL)
+		return
 	}
 
-	c.Cqueue.Cmds = make([]*cmd.DiceDBCmd, 0)
-	c.IsTxn = false
+	if _, err := fmt.Fprintf(buf, "*%d\r\n", len(queued)); err != nil {
+		s.logger.Error("Error writing to buffer", slog.Any("error", err))
+		return
+	}
+	responses := resp.BatchResponses
+	for i, qc := range queued {
+		switch {
+		case batchErrs[i] != nil:
+			buf.Write(batchErrs[i])
+		case batches[i] != nil:
+			s.writeBatchResponses(batches[i], responses[:len(batches[i])], buf)
+			responses = responses[len(batches[i]):]
+		default:
+			writeEvalResponse(qc, responses[0], buf)
+			responses = responses[1:]
+		}
+	}
 }
 
 func (s *AsyncServer) discardTransaction(c *comm.Client, buf *bytes.Buffer) {
 	c.TxnDiscard()
+	s.unwatch(c.Watch)
 	buf.Write(clientio.RespOK)
 }
 
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/ops"
 	"github.com/dicedb/dice/internal/querymanager"
+	"github.com/dicedb/dice/internal/replication"
 	"github.com/dicedb/dice/internal/server/utils"
 	"github.com/dicedb/dice/internal/shard"
 	dstore "github.com/dicedb/dice/internal/store"
@@ -67,6 +70,7 @@ func NewWebSocketServer(shardManager *shard.ShardManager, watchChan chan dstore.
 	}
 
 	mux.HandleFunc("/", websocketServer.WebsocketHandler)
+	mux.HandleFunc("/cdc", websocketServer.CDCHandler)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		_, err := w.Write([]byte("OK"))
 		if err != nil {
@@ -220,6 +224,83 @@ func (s *WebsocketServer) WebsocketHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// CDCHandler streams the changes of the keyspace to a downstream system, as JSON
+// messages, see replication.Change. The changes follow the position given by
+// the replid, from and seq parameters, seq being 0 if absent, or the current
+// one if they are absent, and the request fails with 410 Gone if they are no
+// longer held. The stream is closed with the reason once the subscriber is
+// dropped, and resumes from the position of the last change received.
+func (s *WebsocketServer) CDCHandler(w http.ResponseWriter, r *http.Request) {
+	from, err := parseCDCPosition(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sub, err := s.shardManager.Primary().Subscribe(from)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	}
+	defer sub.Close()
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// the subscriber sends nothing, reading the connection only detects that
+	// it is gone
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		change, err := sub.Next(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()))
+			}
+			return
+		}
+		if err := conn.WriteJSON(change); err != nil {
+			return
+		}
+	}
+}
+
+// parseCDCPosition parses the position the CDC subscriber resumes from, nil if
+// it subscribes to the changes to come.
+func parseCDCPosition(q url.Values) (*replication.Position, error) {
+	replID, from, seq := q.Get("replid"), q.Get("from"), q.Get("seq")
+	if replID == "" && from == "" && seq == "" {
+		return nil, nil
+	}
+	if replID == "" || from == "" {
+		return nil, errors.New("replid and from are required to resume")
+	}
+
+	pos := &replication.Position{ReplID: replID}
+	var err error
+	if pos.Offset, err = strconv.ParseInt(from, 10, 64); err != nil || pos.Offset < 0 {
+		return nil, errors.New("invalid from offset")
+	}
+	if seq != "" {
+		if pos.Seq, err = strconv.Atoi(seq); err != nil || pos.Seq < 0 {
+			return nil, errors.New("invalid seq")
+		}
+	}
+	return pos, nil
+}
+
 func writeResponse(conn *websocket.Conn, text []byte) {
 	_ = conn.WriteMessage(websocket.TextMessage, text)
 }
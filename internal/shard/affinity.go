@@ -0,0 +1,118 @@
+package shard
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// The shards may be pinned to the CPUs of the host, as told by
+// config.DiceConfig.Server.ShardAffinity:
+//   - none, the default, leaves the scheduling of the shards to the Go runtime.
+//   - thread locks the goroutine of every shard to an OS thread of its own, so
+//     that the shard is never moved to another thread by the runtime.
+//   - cpu locks the shards to their threads and pins every thread to a single
+//     CPU. The shards are spread round-robin across the NUMA nodes, then across
+//     the CPUs of each node.
+//   - numa locks the shards to their threads and pins every thread to the CPUs
+//     of a NUMA node, the shards being spread round-robin across the nodes. The
+//     kernel still balances the threads across the CPUs of their node.
+//
+// The Go allocator is not NUMA-aware, but the kernel backs a page with the
+// memory of the node of the thread first touching it, which keeps most of the
+// store of a shard pinned local to its CPUs.
+//
+// A shard never unlocks its thread: the thread exits along with the shard
+// rather than going back to the runtime with its affinity. The CPUs can only be
+// pinned on Linux, the shards are merely locked to their threads elsewhere.
+
+const (
+	AffinityNone   = "none"
+	AffinityThread = "thread"
+	AffinityCPU    = "cpu"
+	AffinityNUMA   = "numa"
+)
+
+// NUMANode is a NUMA node of the host, along with its CPUs the process may run
+// on.
+type NUMANode struct {
+	ID   int
+	CPUs []int
+}
+
+// placeShards returns the CPUs each of n shards is pinned to with affinity, as
+// spread across nodes. The CPUs of a shard are nil if it is not pinned.
+func placeShards(n int, nodes []NUMANode, affinity string) [][]int {
+	placement := make([][]int, n)
+	if len(nodes) == 0 || (affinity != AffinityCPU && affinity != AffinityNUMA) {
+		return placement
+	}
+
+	for i := range placement {
+		node := nodes[i%len(nodes)]
+		if affinity == AffinityNUMA {
+			placement[i] = node.CPUs
+		} else {
+			placement[i] = []int{node.CPUs[(i/len(nodes))%len(node.CPUs)]}
+		}
+	}
+	return placement
+}
+
+// pinShard locks the calling goroutine to its OS thread, and pins the thread to
+// cpus if there are any.
+func pinShard(cpus []int) error {
+	runtime.LockOSThread()
+	if len(cpus) == 0 {
+		return nil
+	}
+	return setAffinity(cpus)
+}
+
+// validAffinity returns true if affinity is one of the ways to pin the shards,
+// the empty one standing for none.
+func validAffinity(affinity string) bool {
+	switch affinity {
+	case "", AffinityNone, AffinityThread, AffinityCPU, AffinityNUMA:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseCPUList parses a list of CPUs in the format of the kernel, e.g.
+// 0-3,8,10-11.
+func parseCPUList(s string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(first)
+		if err != nil || lo < 0 {
+			return nil, fmt.Errorf("invalid CPU list %q", s)
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(last); err != nil || hi < lo {
+				return nil, fmt.Errorf("invalid CPU list %q", s)
+			}
+		}
+		for cpu := lo; cpu <= hi; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// localNode returns the single node of the hosts whose topology is unknown,
+// holding the CPUs the runtime may use.
+func localNode() []NUMANode {
+	cpus := make([]int, runtime.NumCPU())
+	for i := range cpus {
+		cpus[i] = i
+	}
+	return []NUMANode{{ID: 0, CPUs: cpus}}
+}
@@ -0,0 +1,93 @@
+package shard
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// nodesDir lists the NUMA nodes of the host, with the CPUs of each of them.
+const nodesDir = "/sys/devices/system/node"
+
+// cpuMaskWords is the size of the CPU masks in 64-bit words, enough for 1024
+// CPUs as the cpu_set_t of the C library.
+const cpuMaskWords = 16
+
+// setAffinity pins the calling thread to cpus.
+func setAffinity(cpus []int) error {
+	var mask [cpuMaskWords]uint64
+	for _, cpu := range cpus {
+		if cpu < cpuMaskWords*64 {
+			mask[cpu/64] |= 1 << (cpu % 64)
+		}
+	}
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, uintptr(len(mask)*8), uintptr(unsafe.Pointer(&mask[0])))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// allowedCPUs returns the CPUs the calling thread may run on.
+func allowedCPUs() ([]int, error) {
+	var mask [cpuMaskWords]uint64
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, uintptr(len(mask)*8), uintptr(unsafe.Pointer(&mask[0])))
+	if errno != 0 {
+		return nil, errno
+	}
+	var cpus []int
+	for cpu := 0; cpu < cpuMaskWords*64; cpu++ {
+		if mask[cpu/64]&(1<<(cpu%64)) != 0 {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// numaNodes returns the NUMA nodes of the host, along with their CPUs the
+// process may run on. The nodes with no such CPU are left out. The host is
+// deemed a single node if its topology is unknown.
+func numaNodes() []NUMANode {
+	allowed, err := allowedCPUs()
+	if err != nil {
+		return localNode()
+	}
+
+	entries, err := os.ReadDir(nodesDir)
+	if err != nil {
+		return []NUMANode{{ID: 0, CPUs: allowed}}
+	}
+	var nodes []NUMANode
+	for _, entry := range entries {
+		id, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), "node"))
+		if err != nil || !strings.HasPrefix(entry.Name(), "node") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(nodesDir, entry.Name(), "cpulist"))
+		if err != nil {
+			continue
+		}
+		cpus, err := parseCPUList(string(data))
+		if err != nil {
+			continue
+		}
+		cpus = slices.DeleteFunc(cpus, func(cpu int) bool {
+			return !slices.Contains(allowed, cpu)
+		})
+		if len(cpus) > 0 {
+			nodes = append(nodes, NUMANode{ID: id, CPUs: cpus})
+		}
+	}
+	if len(nodes) == 0 {
+		return []NUMANode{{ID: 0, CPUs: allowed}}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}
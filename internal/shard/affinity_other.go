@@ -0,0 +1,18 @@
+//go:build !linux
+
+package shard
+
+import "errors"
+
+var errAffinityUnsupported = errors.New("pinning the threads to CPUs is only supported on Linux")
+
+// setAffinity pins the calling thread to cpus, which is only supported on Linux.
+func setAffinity(_ []int) error {
+	return errAffinityUnsupported
+}
+
+// numaNodes returns the NUMA nodes of the host, whose topology is only known
+// on Linux: the host is deemed a single node.
+func numaNodes() []NUMANode {
+	return localNode()
+}
@@ -0,0 +1,123 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/mocks"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-3,8,10-11\n")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []int{0, 1, 2, 3, 8, 10, 11}, cpus)
+
+	cpus, err = parseCPUList("")
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(cpus))
+
+	for _, s := range []string{"a", "3-1", "1-", "-1"} {
+		_, err := parseCPUList(s)
+		assert.ErrorContains(t, err, "invalid CPU list", s)
+	}
+}
+
+func TestPlaceShards(t *testing.T) {
+	nodes := []NUMANode{{ID: 0, CPUs: []int{0, 1, 2}}, {ID: 1, CPUs: []int{4, 5}}}
+
+	// the shards are spread across the nodes first, then across their CPUs
+	assert.DeepEqual(t, [][]int{{0}, {4}, {1}, {5}, {2}, {4}}, placeShards(6, nodes, AffinityCPU))
+	assert.DeepEqual(t, [][]int{{0, 1, 2}, {4, 5}, {0, 1, 2}}, placeShards(3, nodes, AffinityNUMA))
+	assert.DeepEqual(t, [][]int{nil, nil}, placeShards(2, nodes, AffinityThread))
+	assert.DeepEqual(t, [][]int{nil}, placeShards(1, nodes, AffinityNone))
+
+	assert.Assert(t, validAffinity(""))
+	assert.Assert(t, validAffinity(AffinityNUMA))
+	assert.Assert(t, !validAffinity("socket"))
+}
+
+func TestPinShard(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the CPUs are only pinned on Linux")
+	}
+	nodes := numaNodes()
+	assert.Assert(t, len(nodes) > 0)
+	cpu := nodes[len(nodes)-1].CPUs[0]
+
+	errs := make(chan error)
+	go func() {
+		// the thread exits along with the goroutine, never to be reused
+		if err := pinShard([]int{cpu}); err != nil {
+			errs <- err
+			return
+		}
+		cpus, err := allowedCPUs()
+		if err == nil && (len(cpus) != 1 || cpus[0] != cpu) {
+			err = fmt.Errorf("pinned to %v instead of %d", cpus, cpu)
+		}
+		errs <- err
+	}()
+	assert.NilError(t, <-errs)
+}
+
+// BenchmarkShardAffinity measures the latency of the commands of a shard
+// competing for the CPUs with busy goroutines, pinned with every affinity. The
+// percentiles are reported along with the mean.
+func BenchmarkShardAffinity(b *testing.B) {
+	for _, affinity := range []string{AffinityNone, AffinityThread, AffinityCPU, AffinityNUMA} {
+		b.Run(affinity, func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			shard := NewShardThread(0, make(chan error, 1), make(chan *ShardError, 1), nil, nil, slog.New(mocks.SlogNoopHandler{}))
+			respChan := make(chan *ops.StoreResponse, 1)
+			shard.registerWorker("worker", respChan)
+			placement := placeShards(1, numaNodes(), affinity)
+			go func() {
+				if affinity != AffinityNone {
+					if err := pinShard(placement[0]); err != nil {
+						b.Error(err)
+					}
+				}
+				shard.Start(ctx)
+			}()
+
+			for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+				go func() {
+					for ctx.Err() == nil {
+						runtime.Gosched()
+					}
+				}()
+			}
+
+			latencies := make([]time.Duration, b.N)
+			set := &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}
+			get := &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c := get
+				if i%4 == 0 {
+					c = set
+				}
+				start := time.Now()
+				shard.ReqChan <- &ops.StoreOp{RequestID: uint32(i), Cmd: c, WorkerID: "worker"}
+				<-respChan
+				latencies[i] = time.Since(start)
+			}
+			b.StopTimer()
+
+			sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+			b.ReportMetric(float64(latencies[b.N/2].Nanoseconds()), "p50-ns")
+			b.ReportMetric(float64(latencies[b.N*99/100].Nanoseconds()), "p99-ns")
+			b.ReportMetric(float64(latencies[b.N*999/1000].Nanoseconds()), "p99.9-ns")
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package shard
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/mocks"
+	"gotest.tools/v3/assert"
+)
+
+func setupShardThread(t *testing.T) (*ShardThread, chan *ops.StoreResponse) {
+	ctx, cancel := context.WithCancel(context.Background())
+	shard := NewShardThread(0, make(chan error, 1), make(chan *ShardError, 1), nil, nil, slog.New(mocks.SlogNoopHandler{}))
+	respChan := make(chan *ops.StoreResponse, 10)
+	shard.registerWorker("worker", respChan)
+	go shard.Start(ctx)
+	t.Cleanup(cancel)
+	return shard, respChan
+}
+
+func TestBlockingCommandServedByPush(t *testing.T) {
+	shard, respChan := setupShardThread(t)
+
+	shard.ReqChan <- &ops.StoreOp{RequestID: 1, Cmd: &cmd.DiceDBCmd{Cmd: "BLPOP", Args: []string{"list", "0"}}, WorkerID: "worker", CanBlock: true}
+	shard.ReqChan <- &ops.StoreOp{RequestID: 2, Cmd: &cmd.DiceDBCmd{Cmd: "RPUSH", Args: []string{"list", "a", "b"}}, WorkerID: "worker"}
+
+	resp := <-respChan
+	assert.Equal(t, uint32(2), resp.RequestID)
+	resp = <-respChan
+	assert.Equal(t, uint32(1), resp.RequestID)
+	assert.Equal(t, "*2\r\n$4\r\nlist\r\n$1\r\na\r\n", string(resp.EvalResponse.Result.([]byte)))
+}
+
+func TestBlockingCommandTimesOut(t *testing.T) {
+	shard, respChan := setupShardThread(t)
+
+	start := time.Now()
+	shard.ReqChan <- &ops.StoreOp{RequestID: 1, Cmd: &cmd.DiceDBCmd{Cmd: "BRPOP", Args: []string{"list", "0.05"}}, WorkerID: "worker", CanBlock: true}
+
+	resp := <-respChan
+	assert.Assert(t, time.Since(start) >= 50*time.Millisecond)
+	assert.Equal(t, "$-1\r\n", string(resp.EvalResponse.Result.([]byte)))
+
+	// the clients that cannot block time out right away
+	shard.ReqChan <- &ops.StoreOp{RequestID: 2, Cmd: &cmd.DiceDBCmd{Cmd: "BRPOP", Args: []string{"list", "0"}}, WorkerID: "worker"}
+	resp = <-respChan
+	assert.Equal(t, uint32(2), resp.RequestID)
+	assert.Equal(t, "$-1\r\n", string(resp.EvalResponse.Result.([]byte)))
+}
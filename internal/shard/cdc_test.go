@@ -0,0 +1,73 @@
+package shard
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/replication"
+)
+
+func TestCDCChanges(t *testing.T) {
+	manager := setupShardManager(t, 1)
+	sub, err := manager.Primary().Subscribe(nil)
+	assert.NilError(t, err)
+	defer sub.Close()
+
+	p := manager.NewPipeline()
+	p.Set("k", "abc")
+	p.Queue("APPEND", "k", "de")
+	p.Get("k")
+	_, err = p.Exec(context.Background())
+	assert.NilError(t, err)
+
+	// the writes of a batch are a transaction, with the values of their key
+	// before and after them
+	var changes []replication.Change
+	for i := 0; i < 4; i++ {
+		c, err := sub.Next(context.Background())
+		assert.NilError(t, err)
+		changes = append(changes, c)
+	}
+	assert.Equal(t, replication.BoundaryBegin, changes[0].Boundary)
+	assert.Equal(t, "SET", changes[1].Cmd)
+	assert.Equal(t, "k", changes[1].Key)
+	assert.DeepEqual(t, &replication.Summary{Type: "none"}, changes[1].Old)
+	assert.DeepEqual(t, &replication.Summary{Type: "string", Size: 3}, changes[1].New)
+	assert.Equal(t, "APPEND", changes[2].Cmd)
+	assert.DeepEqual(t, &replication.Summary{Type: "string", Size: 3}, changes[2].Old)
+	assert.DeepEqual(t, &replication.Summary{Type: "string", Size: 5}, changes[2].New)
+	assert.Equal(t, replication.BoundaryCommit, changes[3].Boundary)
+	assert.Equal(t, manager.Primary().Offset(), changes[3].Offset)
+}
+
+func TestCDCEvictionInBatch(t *testing.T) {
+	saved := *config.DiceConfig
+	defer func() { *config.DiceConfig = saved }()
+	config.DiceConfig.Server.KeysLimit = 1
+	config.DiceConfig.Server.EvictionRatio = 1
+	config.DiceConfig.Server.EvictionPolicy = config.EvictSimpleFirst
+
+	manager := setupShardManager(t, 1)
+	sub, err := manager.Primary().Subscribe(nil)
+	assert.NilError(t, err)
+	defer sub.Close()
+
+	p := manager.NewPipeline()
+	p.Set("a", "1")
+	p.Set("b", "2")
+	_, err = p.Exec(context.Background())
+	assert.NilError(t, err)
+
+	// the eviction of the key written earlier by the batch is propagated in
+	// order, within the transaction of the batch
+	var cmds []string
+	for i := 0; i < 5; i++ {
+		c, err := sub.Next(context.Background())
+		assert.NilError(t, err)
+		cmds = append(cmds, c.Boundary+c.Cmd+" "+c.Key)
+	}
+	assert.DeepEqual(t, []string{"begin ", "SET a", "DEL a", "SET b", "commit "}, cmds)
+}
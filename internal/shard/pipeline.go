@@ -0,0 +1,171 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/id"
+	"github.com/dicedb/dice/internal/ops"
+)
+
+// Pipeline queues commands on behalf of an embedder and executes them with a single
+// round trip per shard. All the commands targeting the same shard are shipped as one
+// batch and evaluated back-to-back by the shard thread, which is the sole owner of
+// its store, so the batch is applied without any interleaving with other requests.
+//
+// A Pipeline is not safe for concurrent use.
+type Pipeline struct {
+	manager    *ShardManager
+	cmds       []*cmd.DiceDBCmd
+	replicated bool
+}
+
+// NewPipeline returns an empty pipeline bound to the shards of this manager.
+func (manager *ShardManager) NewPipeline() *Pipeline {
+	return &Pipeline{
+		manager: manager,
+		cmds:    make([]*cmd.DiceDBCmd, 0),
+	}
+}
+
+// NewReplicationPipeline returns an empty pipeline applying the commands
+// received from the primary. They see the keys as the primary does, including
+// the ones that expired but whose deletion was not propagated yet.
+func (manager *ShardManager) NewReplicationPipeline() *Pipeline {
+	p := manager.NewPipeline()
+	p.replicated = true
+	return p
+}
+
+// Queue adds a raw command to the pipeline and returns its position in the
+// results returned by Exec.
+func (p *Pipeline) Queue(c string, args ...string) int {
+	p.cmds = append(p.cmds, &cmd.DiceDBCmd{Cmd: c, Args: args})
+	return len(p.cmds) - 1
+}
+
+// Len returns the number of queued commands.
+func (p *Pipeline) Len() int {
+	return len(p.cmds)
+}
+
+// Set queues a SET of value at key.
+func (p *Pipeline) Set(key, value string) int {
+	return p.Queue("SET", key, value)
+}
+
+// Get queues a GET of key.
+func (p *Pipeline) Get(key string) int {
+	return p.Queue("GET", key)
+}
+
+// Del queues the deletion of key.
+func (p *Pipeline) Del(key string) int {
+	return p.Queue("DEL", key)
+}
+
+// HSet queues the update of field to value in the hash stored at key.
+func (p *Pipeline) HSet(key, field, value string) int {
+	return p.Queue("HSET", key, field, value)
+}
+
+// ZAdd queues the insertion of member with score in the sorted set stored at key.
+func (p *Pipeline) ZAdd(key string, score float64, member string) int {
+	return p.Queue("ZADD", key, strconv.FormatFloat(score, 'g', -1, 64), member)
+}
+
+// RPush queues the insertion of values at the tail of the list stored at key.
+func (p *Pipeline) RPush(key string, values ...string) int {
+	return p.Queue("RPUSH", append([]string{key}, values...)...)
+}
+
+// Exec ships the queued commands to their shards, one batch per shard, and waits for
+// all of them to be evaluated. The responses are returned in queue order, as
+// evaluated by the shards. As with the workers, a command whose keys map to
+// different shards is not run, its response carrying diceerrors.ErrCrossSlot. The
+// pipeline is emptied in any case so that it can be reused: if ctx is canceled
+// first, the commands may or may not have run, and are not queued anymore.
+func (p *Pipeline) Exec(ctx context.Context) ([]*eval.EvalResponse, error) {
+	if len(p.cmds) == 0 {
+		return nil, nil
+	}
+	defer func() { p.cmds = make([]*cmd.DiceDBCmd, 0) }()
+
+	// Group the commands by shard, remembering their position in the queue
+	results := make([]*eval.EvalResponse, len(p.cmds))
+	batches := make(map[ShardID][]*cmd.DiceDBCmd)
+	positions := make(map[ShardID][]int)
+	for i, c := range p.cmds {
+		sid, ok := p.pipelineShard(c)
+		if !ok {
+			results[i] = &eval.EvalResponse{Error: diceerrors.ErrCrossSlot}
+			continue
+		}
+		batches[sid] = append(batches[sid], c)
+		positions[sid] = append(positions[sid], i)
+	}
+
+	// The response channel is buffered so that shards never block on it, even
+	// if the caller stops waiting because the context got canceled.
+	workerID := fmt.Sprintf("pipeline-%d", id.NextID())
+	respChan := make(chan *ops.StoreResponse, len(batches))
+	p.manager.RegisterWorker(workerID, respChan)
+
+	requests := make(map[uint32]ShardID, len(batches))
+	for sid, batch := range batches {
+		reqID := id.NextID()
+		requests[reqID] = sid
+		p.manager.GetShard(sid).ReqChan <- &ops.StoreOp{
+			RequestID:  reqID,
+			Batch:      batch,
+			Replicated: p.replicated,
+			WorkerID:   workerID,
+			ShardID:    sid,
+		}
+	}
+
+	pending := len(batches)
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			// Keep the worker registered till every shard has replied
+			go func(pending int) {
+				for ; pending > 0; pending-- {
+					<-respChan
+				}
+				p.manager.UnregisterWorker(workerID)
+			}(pending)
+			return nil, ctx.Err()
+		case resp := <-respChan:
+			sid := requests[resp.RequestID]
+			for i, r := range resp.BatchResponses {
+				results[positions[sid][i]] = r
+			}
+			pending--
+		}
+	}
+
+	p.manager.UnregisterWorker(workerID)
+
+	return results, nil
+}
+
+// pipelineShard returns the shard the command is routed to, following the same
+// convention as the workers: the shard holding its keys or, for the commands
+// without keys, the shard of their first argument. It returns false if the keys
+// of the command map to different shards.
+func (p *Pipeline) pipelineShard(c *cmd.DiceDBCmd) (ShardID, bool) {
+	if keys := eval.CommandKeys(c); len(keys) > 0 {
+		return p.manager.GetKeysShard(keys)
+	}
+	if len(c.Args) > 0 {
+		sid, _ := p.manager.GetShardInfo(c.Args[0])
+		return sid, true
+	}
+	sid, _ := p.manager.GetShardInfo(c.Cmd)
+	return sid, true
+}
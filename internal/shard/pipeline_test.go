@@ -0,0 +1,115 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/mocks"
+	"gotest.tools/v3/assert"
+)
+
+func setupShardManager(t *testing.T, shardCount uint8) *ShardManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := NewShardManager(shardCount, nil, make(chan error, 1), slog.New(mocks.SlogNoopHandler{}))
+	go manager.Run(ctx)
+	t.Cleanup(cancel)
+	return manager
+}
+
+func TestPipelineExec(t *testing.T) {
+	manager := setupShardManager(t, 4)
+
+	p := manager.NewPipeline()
+	for i := 0; i < 100; i++ {
+		p.Set("key:"+strconv.Itoa(i), strconv.Itoa(i))
+	}
+	getIdx := p.Get("key:42")
+	missingIdx := p.Get("missing")
+	assert.Equal(t, p.Len(), 102)
+
+	results, err := p.Exec(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 102)
+	assert.Equal(t, p.Len(), 0)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, results[i].Result, clientio.OK)
+	}
+	assert.Equal(t, results[getIdx].Result, int64(42))
+	assert.Equal(t, results[missingIdx].Result, clientio.NIL)
+}
+
+func TestPipelineExecPreservesOrderWithinKey(t *testing.T) {
+	manager := setupShardManager(t, 2)
+
+	p := manager.NewPipeline()
+	p.RPush("list", "a", "b")
+	p.HSet("hash", "field", "value")
+	p.ZAdd("zset", 1.5, "member")
+	p.Del("list")
+	existsIdx := p.Queue("EXISTS", "list")
+
+	results, err := p.Exec(context.Background())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, results[1].Result, clientio.Encode(int64(1), false))
+	assert.DeepEqual(t, results[2].Result, clientio.Encode(int64(1), false))
+	assert.DeepEqual(t, results[3].Result, clientio.Encode(int64(1), false))
+	assert.DeepEqual(t, results[existsIdx].Result, clientio.Encode(int64(0), false))
+}
+
+func TestPipelineExecCrossSlot(t *testing.T) {
+	manager := setupShardManager(t, 4)
+
+	// find a key held by the same shard as src and one held by another shard
+	srcShard, _ := manager.GetShardInfo("src")
+	var same, other string
+	for i := 0; same == "" || other == ""; i++ {
+		key := "key:" + strconv.Itoa(i)
+		if sid, _ := manager.GetShardInfo(key); sid == srcShard {
+			same = key
+		} else {
+			other = key
+		}
+	}
+
+	p := manager.NewPipeline()
+	p.Set("src", "value")
+	crossIdx := p.Queue("COPY", "src", other)
+	copyIdx := p.Queue("COPY", "src", same)
+	results, err := p.Exec(context.Background())
+	assert.NilError(t, err)
+	assert.ErrorIs(t, results[crossIdx].Error, diceerrors.ErrCrossSlot)
+	assert.DeepEqual(t, results[copyIdx].Result, clientio.Encode(int64(1), false))
+}
+
+func TestPipelineExecEmpty(t *testing.T) {
+	manager := setupShardManager(t, 1)
+
+	results, err := manager.NewPipeline().Exec(context.Background())
+	assert.NilError(t, err)
+	assert.Assert(t, results == nil)
+}
+
+func TestPipelineExecCanceled(t *testing.T) {
+	manager := setupShardManager(t, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := manager.NewPipeline()
+	p.Queue("INCR", "counter")
+	// the shard may reply before the cancellation is seen
+	_, err := p.Exec(ctx)
+	assert.Assert(t, err == nil || errors.Is(err, context.Canceled), err)
+
+	// the commands of a canceled pipeline are not run again
+	assert.Equal(t, p.Len(), 0)
+	getIdx := p.Get("counter")
+	results, err := p.Exec(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, results[getIdx].Result, int64(1))
+}
@@ -9,7 +9,10 @@ import (
 	"syscall"
 
 	"github.com/cespare/xxhash/v2"
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/replication"
+	"github.com/dicedb/dice/internal/sink"
 	dstore "github.com/dicedb/dice/internal/store"
 )
 
@@ -23,6 +26,11 @@ type ShardManager struct {
 	ShardErrorChan  chan *ShardError              // ShardErrorChan is the channel for sending shard-level errors
 	sigChan         chan os.Signal                // sigChan is the signal channel for the shard manager
 	shardCount      uint8                         // shardCount is the number of shards managed by this manager
+	primary         *replication.Primary          // primary streams the write commands of the shards to the replicas
+	forwarder       *sink.Forwarder               // forwarder writes behind the keys modified by the shards, nil if disabled
+	affinity        string                        // affinity tells how the shards are pinned to the CPUs, see placeShards
+	placement       [][]int                       // placement holds the CPUs each shard is pinned to, nil if not pinned
+	logger          *slog.Logger
 }
 
 // NewShardManager creates a new ShardManager instance with the given number of Shards and a parent context.
@@ -30,22 +38,60 @@ func NewShardManager(shardCount uint8, watchChan chan dstore.QueryWatchEvent, gl
 	shards := make([]*ShardThread, shardCount)
 	shardReqMap := make(map[ShardID]chan *ops.StoreOp)
 	shardErrorChan := make(chan *ShardError)
+	primary := replication.NewPrimary(logger)
 
 	for i := uint8(0); i < shardCount; i++ {
 		// Shards are numbered from 0 to shardCount-1
-		shard := NewShardThread(i, globalErrorChan, shardErrorChan, watchChan, logger)
+		shard := NewShardThread(i, globalErrorChan, shardErrorChan, watchChan, primary, logger)
 		shards[i] = shard
 		shardReqMap[i] = shard.ReqChan
 	}
 
-	return &ShardManager{
+	manager := &ShardManager{
 		shards:          shards,
 		shardReqMap:     shardReqMap,
 		globalErrorChan: globalErrorChan,
 		ShardErrorChan:  shardErrorChan,
 		sigChan:         make(chan os.Signal, 1),
 		shardCount:      shardCount,
+		primary:         primary,
+		logger:          logger,
 	}
+	manager.placeShards(config.DiceConfig.Server.ShardAffinity)
+	if url := config.DiceConfig.Server.WriteBehindWebhook; url != "" {
+		manager.EnableWriteBehind(&sink.WebhookSink{URL: url}, config.DiceConfig.Server.WriteBehindPatterns, sink.Options{Logger: logger})
+	}
+	return manager
+}
+
+// EnableWriteBehind writes behind the state of the keys matching one of the
+// glob-style patterns to s, asynchronously. It must be called before Run.
+func (manager *ShardManager) EnableWriteBehind(s sink.Sink, patterns []string, opts sink.Options) {
+	manager.forwarder = sink.NewForwarder(s, patterns, opts)
+	for _, shard := range manager.shards {
+		shard.enableWriteBehind(manager.forwarder)
+	}
+}
+
+// placeShards decides the CPUs the shards are pinned to with affinity, once
+// they start, see pinShard.
+func (manager *ShardManager) placeShards(affinity string) {
+	if !validAffinity(affinity) {
+		manager.logger.Warn("unknown shard affinity, the shards are not pinned", slog.String("shardaffinity", affinity))
+		affinity = AffinityNone
+	}
+	manager.affinity = affinity
+	if affinity == "" || affinity == AffinityNone {
+		return
+	}
+
+	nodes := numaNodes()
+	manager.placement = placeShards(len(manager.shards), nodes, affinity)
+	manager.logger.Info("pinning the shards",
+		slog.String("affinity", affinity),
+		slog.Int("numa_nodes", len(nodes)),
+		slog.Int("shards", len(manager.shards)),
+	)
 }
 
 // Run starts the ShardManager, manages its lifecycle, and listens for errors.
@@ -67,19 +113,33 @@ func (manager *ShardManager) Run(ctx context.Context) {
 
 	close(manager.ShardErrorChan) // Close the error channel after all Shards stop
 	wg.Wait()                     // Wait for all shard goroutines to exit.
+	manager.primary.Close()       // Disconnect the replicas once no more commands are executed.
 }
 
 // start initializes and starts the shard threads.
 func (manager *ShardManager) start(ctx context.Context, wg *sync.WaitGroup) {
-	for _, shard := range manager.shards {
+	for i, shard := range manager.shards {
 		shard := shard
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if manager.affinity != "" && manager.affinity != AffinityNone {
+				if err := pinShard(manager.placement[i]); err != nil {
+					manager.logger.Warn("could not pin the shard to its CPUs", slog.Int("shard", i), slog.Any("error", err))
+				}
+			}
 			shard.Start(ctx)
 		}()
 	}
+
+	if manager.forwarder != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.forwarder.Run(ctx)
+		}()
+	}
 }
 
 func (manager *ShardManager) GetShardInfo(key string) (id ShardID, c chan *ops.StoreOp) {
@@ -88,6 +148,22 @@ func (manager *ShardManager) GetShardInfo(key string) (id ShardID, c chan *ops.S
 	return id, manager.GetShard(id).ReqChan
 }
 
+// GetKeysShard returns the shard holding all the keys, false if they map to
+// different shards. The shard of no key is the first one.
+func (manager *ShardManager) GetKeysShard(keys []string) (ShardID, bool) {
+	if len(keys) == 0 {
+		return 0, true
+	}
+
+	id, _ := manager.GetShardInfo(keys[0])
+	for _, key := range keys[1:] {
+		if other, _ := manager.GetShardInfo(key); other != id {
+			return 0, false
+		}
+	}
+	return id, true
+}
+
 // GetShardCount returns the number of shards managed by this ShardManager.
 func (manager *ShardManager) GetShardCount() int8 {
 	return int8(len(manager.shards))
@@ -113,3 +189,22 @@ func (manager *ShardManager) UnregisterWorker(workerID string) {
 		shard.unregisterWorker(workerID)
 	}
 }
+
+// Primary returns the replication stream fed by the shards.
+func (manager *ShardManager) Primary() *replication.Primary {
+	return manager.primary
+}
+
+// Exec runs f in the given shard, with exclusive access to its store, and waits
+// for it to return.
+func (manager *ShardManager) Exec(id ShardID, f func(store *dstore.Store)) {
+	done := make(chan struct{})
+	manager.GetShard(id).ReqChan <- &ops.StoreOp{
+		ShardID: id,
+		Exec: func(store *dstore.Store) {
+			defer close(done)
+			f(store)
+		},
+	}
+	<-done
+}
@@ -0,0 +1,36 @@
+package shard
+
+import (
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/mocks"
+	"gotest.tools/v3/assert"
+)
+
+func TestGetKeysShard(t *testing.T) {
+	manager := NewShardManager(4, nil, make(chan error, 1), slog.New(mocks.SlogNoopHandler{}))
+
+	id, ok := manager.GetKeysShard(nil)
+	assert.Assert(t, ok)
+	assert.Equal(t, ShardID(0), id)
+
+	// find two keys of the same shard and one of another shard
+	first, _ := manager.GetShardInfo("key:0")
+	var same, other string
+	for i := 1; same == "" || other == ""; i++ {
+		key := "key:" + strconv.Itoa(i)
+		if id, _ := manager.GetShardInfo(key); id == first {
+			same = key
+		} else {
+			other = key
+		}
+	}
+
+	id, ok = manager.GetKeysShard([]string{"key:0", same, "key:0"})
+	assert.Assert(t, ok)
+	assert.Equal(t, first, id)
+	_, ok = manager.GetKeysShard([]string{"key:0", same, other})
+	assert.Assert(t, !ok)
+}
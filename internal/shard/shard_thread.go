@@ -3,17 +3,24 @@ package shard
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	"log/slog"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
 	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/replication"
 	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/dicedb/dice/internal/sink"
 	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/watchdog"
 )
 
 type ShardID = uint8
@@ -25,7 +32,7 @@ type ShardError struct {
 
 type ShardThread struct {
 	id               ShardID                            // id is the unique identifier for the shard.
-	store            *dstore.Store                      // store that the shard is responsible for.
+	dbs              *dstore.Databases                  // dbs are the logical databases of the shard, each backed by a store, see SELECT.
 	ReqChan          chan *ops.StoreOp                  // ReqChan is this shard's channel for receiving requests.
 	workerMap        map[string]chan *ops.StoreResponse // workerMap maps workerID to its unique response channel
 	workerMutex      sync.RWMutex                       // workerMutex is the workerMap's mutex for thread safety.
@@ -33,22 +40,122 @@ type ShardThread struct {
 	shardErrorChan   chan *ShardError                   // ShardErrorChan is the channel for sending shard-level errors.
 	lastCronExecTime time.Time                          // lastCronExecTime is the last time the shard executed cron tasks.
 	cronFrequency    time.Duration                      // cronFrequency is the frequency at which the shard executes cron tasks.
+	expireFrequency  time.Duration                      // expireFrequency is the frequency of the active expiry sweeps, 0 if disabled.
 	logger           *slog.Logger                       // logger is the logger for the shard.
+	primary          *replication.Primary               // primary propagates the write commands to the replicas.
+	watchdog         *watchdog.Watchdog                 // watchdog reports the commands running for too long, nil if disabled.
+	tier             *dstore.ColdTier                   // tier is the disk tier the cold values are offloaded to, nil if disabled.
+	lastSampleTime   time.Time                          // lastSampleTime is the last time the shard sampled its keyspace composition.
+	checkCursor      uint64                             // checkCursor is the cursor of the integrity check of the keyspace in progress.
+	defaultTTLs      []*cmd.DiceDBCmd                   // defaultTTLs are the expiries given by the default TTL policies to the keys put by the command executing.
+	oldValues        map[string]*replication.Summary    // oldValues summarize the values of the keys of the command executing before it, while CDC subscribers are attached.
+	executing        bool                               // executing is true from the time a command executes till its changes are flushed, the changes made on behalf of the shard, e.g. evictions, being buffered along with its own then.
+	changes          []replication.Change               // changes are the changes propagated by the commands executed, till they are flushed.
 }
 
 // NewShardThread creates a new ShardThread instance with the given shard id and error channel.
-func NewShardThread(id ShardID, gec chan error, sec chan *ShardError, watchChan chan dstore.QueryWatchEvent, logger *slog.Logger) *ShardThread {
-	return &ShardThread{
+func NewShardThread(id ShardID, gec chan error, sec chan *ShardError, watchChan chan dstore.QueryWatchEvent, primary *replication.Primary, logger *slog.Logger) *ShardThread {
+	cfg := config.Current()
+	shard := &ShardThread{
 		id:               id,
-		store:            dstore.NewStore(watchChan),
 		ReqChan:          make(chan *ops.StoreOp, 1000),
 		workerMap:        make(map[string]chan *ops.StoreResponse),
 		globalErrorChan:  gec,
 		shardErrorChan:   sec,
 		lastCronExecTime: utils.GetCurrentTime(),
-		cronFrequency:    config.DiceConfig.Server.ShardCronFrequency,
+		cronFrequency:    cfg.Server.ShardCronFrequency,
+		expireFrequency:  cfg.Server.ActiveExpireFrequency,
 		logger:           logger,
+		primary:          primary,
+		oldValues:        make(map[string]*replication.Summary),
 	}
+	shard.dbs = dstore.NewDatabases(cfg.Server.Databases, func() *dstore.Store {
+		return shard.newStore(watchChan)
+	})
+	if threshold := cfg.Server.WatchdogThreshold; threshold > 0 {
+		shard.watchdog = watchdog.New(fmt.Sprintf("shard-%d", id), threshold, logger)
+	}
+	if cfg.Server.TierHotKeys > 0 {
+		shard.enableTier()
+	}
+	return shard
+}
+
+// newStore returns the store of a database of the shard. The replicas only
+// hold the database 0, the keys the other databases expire or prune are not
+// propagated.
+func (shard *ShardThread) newStore(watchChan chan dstore.QueryWatchEvent) *dstore.Store {
+	store := dstore.NewStore(watchChan)
+	store.OnExpire(func(key string) {
+		if shard.isReplicated(store) {
+			shard.propagateExpiry(key)
+		}
+	})
+	store.OnPrune(func(key string, threshold float64) {
+		if shard.isReplicated(store) {
+			shard.propagatePrune(key, threshold)
+		}
+	})
+	store.OnExpireFields(func(key string, obj *object.Obj, fields []string) {
+		if shard.isReplicated(store) {
+			shard.propagateFieldExpiry(key, obj, fields)
+		}
+	})
+	store.OnTTLJob(func(key string, expireAtSec int64) {
+		if shard.isReplicated(store) {
+			shard.propagateTTLJob(key, expireAtSec)
+		}
+	})
+	store.OnEvict(func(key string) {
+		if shard.isReplicated(store) {
+			shard.propagateExpiry(key)
+		}
+	})
+	store.SetFollowed(func() bool {
+		return shard.primary != nil && shard.isReplicated(store) && (len(shard.primary.Links()) > 0 || shard.primary.Watched())
+	})
+	store.MeasureValues(eval.ValueSize)
+	store.OnDefaultTTL(func(key string, expireAtMs uint64) {
+		if shard.primary != nil && shard.isReplicated(store) {
+			shard.defaultTTLs = append(shard.defaultTTLs,
+				&cmd.DiceDBCmd{Cmd: "PEXPIREAT", Args: []string{key, strconv.FormatUint(expireAtMs, 10)}})
+		}
+	})
+	return store
+}
+
+// isReplicated returns true if store is the one of the database 0, the only
+// database held by the replicas.
+func (shard *ShardThread) isReplicated(store *dstore.Store) bool {
+	return shard.dbs.Get(0) == store
+}
+
+// db returns the store of the database op runs on.
+func (shard *ShardThread) db(op *ops.StoreOp) *dstore.Store {
+	return shard.dbs.Get(op.DB)
+}
+
+// enableTier opens the cold tier of the database 0 of the shard, the shards
+// having a file each. The values are kept in memory if it cannot be opened, as
+// are the ones of the other databases.
+func (shard *ShardThread) enableTier() {
+	path := fmt.Sprintf("%s.%d", config.DiceConfig.Server.TierPath, shard.id)
+	tier, err := dstore.NewColdTier(path, eval.TierCodec)
+	if err != nil {
+		shard.logger.Error("could not open the cold tier", slog.String("path", path), slog.Any("error", err))
+		return
+	}
+	shard.tier = tier
+	shard.dbs.Get(0).EnableTier(tier)
+}
+
+// enableWriteBehind submits the state of the keys modified in the database 0
+// of the shard to the forwarder, on every cron tick.
+func (shard *ShardThread) enableWriteBehind(forwarder *sink.Forwarder) {
+	store := shard.dbs.Get(0)
+	store.EnableWriteBehind(forwarder, func(k string, obj *object.Obj) ([]byte, error) {
+		return eval.WriteBehindValue(k, obj, store)
+	})
 }
 
 // Start starts the shard thread, listening for incoming requests.
@@ -56,25 +163,133 @@ func (shard *ShardThread) Start(ctx context.Context) {
 	ticker := time.NewTicker(shard.cronFrequency)
 	defer ticker.Stop()
 
+	// expireTick fires the sweeps of the expired keys, never if the active
+	// expiry is disabled
+	var expireTick <-chan time.Time
+	if shard.expireFrequency > 0 {
+		expireTicker := time.NewTicker(shard.expireFrequency)
+		defer expireTicker.Stop()
+		expireTick = expireTicker.C
+	}
+
+	// blockTimer fires when the earliest blocking command times out
+	blockTimer := time.NewTimer(0)
+	blockTimer.Stop()
+	defer blockTimer.Stop()
+
+	if shard.watchdog != nil {
+		go shard.watchdog.Run(ctx)
+	}
+
 	for {
 		select {
 		case op := <-shard.ReqChan:
 			shard.processRequest(op)
+			for _, store := range shard.dbs.All() {
+				dstore.ServeBlocked(store)
+			}
 		case <-ticker.C:
 			shard.runCronTasks()
+		case <-expireTick:
+			for _, store := range shard.dbs.All() {
+				dstore.DeleteExpiredKeys(store, config.Current().Server.ActiveExpireEffort, shard.expireFrequency)
+			}
+		case <-blockTimer.C:
+			for _, store := range shard.dbs.All() {
+				dstore.ExpireBlocked(store)
+			}
 		case <-ctx.Done():
 			shard.cleanup()
 			return
 		}
+
+		if deadline, ok := shard.nextBlockedDeadline(); ok {
+			blockTimer.Reset(deadline.Sub(utils.GetCurrentTime()))
+		} else {
+			blockTimer.Stop()
+		}
 	}
 }
 
-// runCronTasks runs the cron tasks for the shard. This includes deleting expired keys.
+// nextBlockedDeadline returns the earliest time a blocking command times out
+// at in any database, false if no client is blocked with a timeout.
+func (shard *ShardThread) nextBlockedDeadline() (time.Time, bool) {
+	var next time.Time
+	for _, store := range shard.dbs.All() {
+		if deadline, ok := store.NextBlockedDeadline(); ok && (next.IsZero() || deadline.Before(next)) {
+			next = deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// runCronTasks runs the cron tasks for the shard. This includes deleting expired hash
+// fields, pruning the sorted sets with a retention policy, running the TTL and restore jobs,
+// writing behind the keys modified, offloading the cold values, shrinking the tables
+// left sparse by deletions in every database, sampling the composition of the keyspace,
+// checking the integrity of the values and pinging the replicas when idle.
 func (shard *ShardThread) runCronTasks() {
-	dstore.DeleteExpiredKeys(shard.store)
+	for _, store := range shard.dbs.All() {
+		dstore.ExpireFields(store)
+		dstore.PruneKeys(store)
+		dstore.RunTTLJobs(store)
+		dstore.RunRestoreJobs(store)
+		dstore.ForwardWriteBehind(store)
+		dstore.OffloadColdKeys(store)
+		dstore.ShrinkTables(store)
+	}
+	shard.sampleKeyspace()
+	shard.checkIntegrity()
+	if shard.primary != nil {
+		shard.primary.Ping()
+	}
 	shard.lastCronExecTime = utils.GetCurrentTime()
 }
 
+// sampleKeyspace records the composition of the database 0 of the shard by type,
+// once every config.DiceConfig.Server.KeyspaceSampleInterval, for INFO and the
+// metrics endpoint. A zero interval disables the sampling.
+func (shard *ShardThread) sampleKeyspace() {
+	interval := config.Current().Server.KeyspaceSampleInterval
+	now := utils.GetCurrentTime()
+	if interval <= 0 || now.Sub(shard.lastSampleTime) < interval {
+		return
+	}
+	shard.lastSampleTime = now
+	metrics.RecordComposition(int(shard.id), eval.SampleKeyspace(shard.dbs.Get(0), config.Current().Server.KeyspaceSampleSize))
+}
+
+// checkIntegrity validates config.DiceConfig.Server.IntegrityCheckKeys keys of
+// the database 0 of the shard against the invariants of their type, e.g. the index of a sorted set
+// matching its dictionary, and logs the violations found. The keyspace is walked
+// incrementally, a few keys per cron tick, from a snapshot of the keys taken when
+// a pass starts. With config.DiceConfig.Server.IntegrityCheckRepair, the broken
+// keys are repaired when possible. Zero keys disables the check.
+func (shard *ShardThread) checkIntegrity() {
+	count := config.Current().Server.IntegrityCheckKeys
+	if count <= 0 {
+		return
+	}
+
+	store := shard.dbs.Get(0)
+	next, keys, ok := store.Scan(shard.checkCursor, count, true)
+	if !ok {
+		// the snapshot was dropped, the next pass starts over
+		shard.checkCursor = 0
+		return
+	}
+	shard.checkCursor = next
+
+	for _, issue := range eval.CheckKeys(store, keys, config.Current().Server.IntegrityCheckRepair) {
+		shard.logger.Warn("integrity check found a broken key",
+			slog.Int("shard", int(shard.id)),
+			slog.String("key", issue.Key),
+			slog.String("reason", issue.Reason),
+			slog.String("action", issue.Action),
+		)
+	}
+}
+
 func (shard *ShardThread) registerWorker(workerID string, workerChan chan *ops.StoreResponse) {
 	shard.workerMutex.Lock()
 	shard.workerMap[workerID] = workerChan
@@ -87,13 +302,39 @@ func (shard *ShardThread) unregisterWorker(workerID string) {
 	shard.workerMutex.Unlock()
 }
 
+// workerChan returns the response channel of a worker, false if the worker is
+// not registered.
+func (shard *ShardThread) workerChan(workerID string) (chan *ops.StoreResponse, bool) {
+	shard.workerMutex.RLock()
+	defer shard.workerMutex.RUnlock()
+	workerChan, ok := shard.workerMap[workerID]
+	return workerChan, ok
+}
+
 // processRequest processes a Store operation for the shard.
 func (shard *ShardThread) processRequest(op *ops.StoreOp) {
-	resp := eval.ExecuteCommand(op.Cmd, op.Client, shard.store, op.HTTPOp, op.WebsocketOp)
+	if op.Exec != nil {
+		op.Exec(shard.dbs.Get(0))
+		return
+	}
+	if op.Batch != nil {
+		shard.processBatch(op)
+		return
+	}
 
-	shard.workerMutex.RLock()
-	workerChan, ok := shard.workerMap[op.WorkerID]
-	shard.workerMutex.RUnlock()
+	store := shard.db(op)
+	shard.watchdog.Begin(op.Cmd.Cmd)
+	resp := shard.execute(op.Cmd, op, store)
+	shard.watchdog.End()
+	if resp.Blocked != nil && op.CanBlock {
+		shard.flushChanges()
+		shard.block(op, store, resp)
+		return
+	}
+	shard.propagate(op.Cmd, resp, store)
+	shard.flushChanges()
+
+	workerChan, ok := shard.workerChan(op.WorkerID)
 
 	sp := &ops.StoreResponse{
 		RequestID: op.RequestID,
@@ -111,13 +352,242 @@ func (shard *ShardThread) processRequest(op *ops.StoreOp) {
 	workerChan <- sp
 }
 
+// processBatch evaluates all the commands of a pipeline batch back-to-back and
+// replies with a single response holding the results in order. A batch run by
+// EXEC aborts, running none of its commands, if one of the keys its client
+// watches was modified, the keys being unwatched either way. The commands
+// following a SELECT run on the database it selected.
+func (shard *ShardThread) processBatch(op *ops.StoreOp) {
+	store := shard.db(op)
+	if op.Replicated {
+		store.SetReplicating(true)
+		defer store.SetReplicating(false)
+	}
+
+	aborted := op.Watch != nil && op.Watch.Touched()
+	if op.Watch != nil {
+		op.Watch.Unwatch()
+	}
+
+	var responses []*eval.EvalResponse
+	if !aborted {
+		responses = make([]*eval.EvalResponse, len(op.Batch))
+		for i, c := range op.Batch {
+			shard.watchdog.Begin(c.Cmd)
+			responses[i] = shard.execute(c, op, store)
+			shard.watchdog.End()
+			shard.propagate(c, responses[i], store)
+			if c.Cmd == "SELECT" && op.Client != nil {
+				store = shard.dbs.Get(op.Client.DB)
+			}
+		}
+		shard.flushChanges()
+	}
+
+	workerChan, ok := shard.workerChan(op.WorkerID)
+	if !ok {
+		shard.shardErrorChan <- &ShardError{
+			ShardID: shard.id,
+			Error:   fmt.Errorf(diceerrors.WorkerNotFoundErr, op.WorkerID),
+		}
+		return
+	}
+
+	workerChan <- &ops.StoreResponse{
+		RequestID:      op.RequestID,
+		BatchResponses: responses,
+		Aborted:        aborted,
+	}
+}
+
+// execute executes the command c of op in store. The replicas are disconnected
+// once the database 0 is swapped, so that they sync again.
+func (shard *ShardThread) execute(c *cmd.DiceDBCmd, op *ops.StoreOp, store *dstore.Store) *eval.EvalResponse {
+	db0 := shard.dbs.Get(0)
+	shard.defaultTTLs = shard.defaultTTLs[:0]
+	shard.executing = true
+	shard.summarizeOldValues(c, store)
+	resp := eval.ExecuteCommand(c, op.Client, store, op.HTTPOp, op.WebsocketOp)
+	if shard.primary != nil && shard.dbs.Get(0) != db0 {
+		shard.primary.Close()
+	}
+	return resp
+}
+
+// block parks the client of a blocking command, e.g. BLPOP, in store till one of
+// the keys it waits for is modified and the command can be served, or till it
+// times out, replying with timedOut then.
+func (shard *ShardThread) block(op *ops.StoreOp, store *dstore.Store, timedOut *eval.EvalResponse) {
+	w := &dstore.Waiter{Keys: timedOut.Blocked.Keys}
+	if timeout := timedOut.Blocked.Timeout; timeout > 0 {
+		w.Deadline = utils.GetCurrentTime().Add(timeout)
+	}
+
+	w.Serve = func() bool {
+		workerChan, ok := shard.workerChan(op.WorkerID)
+		if !ok {
+			// the client is gone, there is no one left to serve
+			return true
+		}
+
+		shard.watchdog.Begin(op.Cmd.Cmd)
+		shard.defaultTTLs = shard.defaultTTLs[:0]
+		shard.executing = true
+		resp := eval.ExecuteCommand(op.Cmd, op.Client, store, op.HTTPOp, op.WebsocketOp)
+		shard.watchdog.End()
+		if resp.Blocked != nil {
+			shard.flushChanges()
+			return false
+		}
+		shard.propagate(op.Cmd, resp, store)
+		shard.flushChanges()
+		workerChan <- &ops.StoreResponse{RequestID: op.RequestID, EvalResponse: resp}
+		return true
+	}
+
+	w.Expire = func() {
+		if workerChan, ok := shard.workerChan(op.WorkerID); ok {
+			workerChan <- &ops.StoreResponse{RequestID: op.RequestID, EvalResponse: timedOut}
+		}
+	}
+
+	store.Block(w)
+}
+
+// propagate sends the write commands executed successfully in the database 0 to
+// the replicas, rewritten into deterministic ones when needed, followed by the
+// expiries the default TTL policies gave to the keys they put.
+func (shard *ShardThread) propagate(c *cmd.DiceDBCmd, resp *eval.EvalResponse, store *dstore.Store) {
+	if shard.primary == nil || !eval.IsWriteCommand(c.Cmd) || resp.Err() != nil || resp.Blocked != nil || !shard.isReplicated(store) {
+		return
+	}
+	for _, pc := range eval.PropagatedCommands(c, resp, store) {
+		shard.changes = append(shard.changes, shard.change(pc, store))
+	}
+	for _, pc := range shard.defaultTTLs {
+		shard.changes = append(shard.changes, shard.change(pc, store))
+	}
+}
+
+// change returns the change of the command pc propagated, along with the
+// summaries of the value of its first key before and after the command
+// executing, while CDC subscribers are attached.
+func (shard *ShardThread) change(pc *cmd.DiceDBCmd, store *dstore.Store) replication.Change {
+	c := replication.Change{Cmd: pc.Cmd, Args: pc.Args}
+	if keys := eval.CommandKeys(pc); len(keys) > 0 {
+		c.Key = keys[0]
+		if shard.primary.Watched() {
+			c.Old = shard.oldValues[c.Key]
+			c.New = summarize(store, c.Key)
+		}
+	}
+	return c
+}
+
+// flushChanges propagates the changes of the commands executed, the ones of a
+// batch being streamed to the CDC subscribers as a single transaction.
+func (shard *ShardThread) flushChanges() {
+	shard.executing = false
+	if len(shard.changes) == 0 {
+		return
+	}
+	shard.primary.PropagateChanges(shard.changes)
+	shard.changes = shard.changes[:0]
+}
+
+// summarizeOldValues summarizes the values of the keys of the write command c
+// before it executes, while CDC subscribers are attached.
+func (shard *ShardThread) summarizeOldValues(c *cmd.DiceDBCmd, store *dstore.Store) {
+	clear(shard.oldValues)
+	if shard.primary == nil || !shard.primary.Watched() || !eval.IsWriteCommand(c.Cmd) || !shard.isReplicated(store) {
+		return
+	}
+	for _, key := range eval.CommandKeys(c) {
+		shard.oldValues[key] = summarize(store, key)
+	}
+}
+
+// summarize returns the summary of the value stored at key.
+func summarize(store *dstore.Store, key string) *replication.Summary {
+	obj := store.GetNoTouch(key)
+	if obj == nil {
+		return &replication.Summary{Type: "none"}
+	}
+	typ, size := eval.ValueSummary(obj)
+	return &replication.Summary{Type: typ, Size: size}
+}
+
+// propagateExpiry sends the deletion of a key that expired or was evicted to
+// the replicas, which never expire nor evict keys on their own.
+func (shard *ShardThread) propagateExpiry(key string) {
+	if shard.primary == nil {
+		return
+	}
+	shard.propagateKey(key, &cmd.DiceDBCmd{Cmd: "DEL", Args: []string{key}})
+}
+
+// propagateFieldExpiry sends the deletion of the fields of a hash, or of the
+// members of a set or a sorted set, that expired to the replicas, which never
+// expire fields on their own.
+func (shard *ShardThread) propagateFieldExpiry(key string, obj *object.Obj, fields []string) {
+	if shard.primary == nil {
+		return
+	}
+	shard.propagateKey(key, eval.ExpiredMembersCmd(key, obj, fields))
+}
+
+// propagatePrune sends the pruning of a sorted set by its retention policy to
+// the replicas, which never prune on their own.
+func (shard *ShardThread) propagatePrune(key string, threshold float64) {
+	if shard.primary == nil {
+		return
+	}
+	shard.propagateKey(key, &cmd.DiceDBCmd{Cmd: "ZPRUNE", Args: []string{key, strconv.FormatFloat(threshold, 'f', -1, 64)}})
+}
+
+// propagateTTLJob sends the expiry set by a TTL job to the replicas, which never
+// run the jobs on their own.
+func (shard *ShardThread) propagateTTLJob(key string, expireAtSec int64) {
+	if shard.primary == nil {
+		return
+	}
+	if expireAtSec == dstore.PersistTTL {
+		shard.propagateKey(key, &cmd.DiceDBCmd{Cmd: "PERSIST", Args: []string{key}})
+		return
+	}
+	shard.propagateKey(key, &cmd.DiceDBCmd{Cmd: "EXPIREAT", Args: []string{key, strconv.FormatInt(expireAtSec, 10)}})
+}
+
+// propagateKey propagates the command c, changing key on behalf of the shard
+// rather than of a client. While a command executes, e.g. evicting or lazily
+// expiring a key, c is buffered along with the changes of the command so that
+// the replicas apply them in the same order. It is propagated right away
+// otherwise, e.g. from the cron.
+func (shard *ShardThread) propagateKey(key string, c *cmd.DiceDBCmd) {
+	change := replication.Change{Key: key, Cmd: c.Cmd, Args: c.Args}
+	if shard.executing {
+		shard.changes = append(shard.changes, change)
+		return
+	}
+	shard.primary.PropagateChanges([]replication.Change{change})
+}
+
 // cleanup handles cleanup logic when the shard stops.
 func (shard *ShardThread) cleanup() {
 	close(shard.ReqChan)
-	if !config.DiceConfig.Server.WriteAOFOnCleanup {
+	if shard.tier != nil {
+		defer shard.closeTier()
+	}
+	if !config.Current().Server.WriteAOFOnCleanup {
 		slog.Info("Skipping AOF dump.")
 		return
 	}
 
-	eval.EvalBGREWRITEAOF([]string{}, shard.store)
+	eval.EvalBGREWRITEAOF([]string{}, shard.dbs.Get(0))
+}
+
+func (shard *ShardThread) closeTier() {
+	if err := shard.tier.Close(); err != nil {
+		shard.logger.Error("could not close the cold tier", slog.Any("error", err))
+	}
 }
@@ -0,0 +1,55 @@
+package shard
+
+import (
+	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// The traversals below walk the collections stored at a key on behalf of an
+// embedder, in the shard owning the key, calling fn with the elements in place
+// rather than replying with a copy of them. fn runs on the shard thread, which
+// serves nothing else meanwhile, so it should return quickly and must not call
+// back into the manager. The traversal stops as soon as fn returns false.
+
+// ZRangeByScoreFunc calls fn with the members of the sorted set stored at key
+// with a score between min and max, both inclusive, by ascending scores, see
+// eval.ZRangeByScoreFunc.
+func (manager *ShardManager) ZRangeByScoreFunc(key string, min, max float64, fn func(member string, score float64) bool) error {
+	return manager.traverse(key, func(store *dstore.Store) error {
+		return eval.ZRangeByScoreFunc(store, key, min, max, fn)
+	})
+}
+
+// HScanFunc calls fn with the fields of the hash stored at key along with their
+// values, see eval.HScanFunc.
+func (manager *ShardManager) HScanFunc(key string, fn func(field, value string) bool) error {
+	return manager.traverse(key, func(store *dstore.Store) error {
+		return eval.HScanFunc(store, key, fn)
+	})
+}
+
+// LRangeFunc calls fn with the elements of the list stored at key, from head to
+// tail, see eval.LRangeFunc.
+func (manager *ShardManager) LRangeFunc(key string, fn func(index int, element string) bool) error {
+	return manager.traverse(key, func(store *dstore.Store) error {
+		return eval.LRangeFunc(store, key, fn)
+	})
+}
+
+// SScanFunc calls fn with the members of the set stored at key, see
+// eval.SScanFunc.
+func (manager *ShardManager) SScanFunc(key string, fn func(member string) bool) error {
+	return manager.traverse(key, func(store *dstore.Store) error {
+		return eval.SScanFunc(store, key, fn)
+	})
+}
+
+// traverse runs f in the shard owning key and returns its error.
+func (manager *ShardManager) traverse(key string, f func(store *dstore.Store) error) error {
+	sid, _ := manager.GetShardInfo(key)
+	var err error
+	manager.Exec(sid, func(store *dstore.Store) {
+		err = f(store)
+	})
+	return err
+}
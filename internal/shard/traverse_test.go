@@ -0,0 +1,28 @@
+package shard
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestZRangeByScoreFunc(t *testing.T) {
+	manager := setupShardManager(t, 4)
+
+	p := manager.NewPipeline()
+	for i := 0; i < 10; i++ {
+		p.ZAdd("zset", float64(i), "m"+strconv.Itoa(i))
+	}
+	_, err := p.Exec(context.Background())
+	assert.NilError(t, err)
+
+	var members []string
+	err = manager.ZRangeByScoreFunc("zset", 3, 8, func(member string, _ float64) bool {
+		members = append(members, member)
+		return len(members) < 3
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"m3", "m4", "m5"}, members)
+}
@@ -0,0 +1,195 @@
+package sink
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/regex"
+)
+
+// The shards submit the records of the keys they modify to a Forwarder, which
+// writes them to its sink from a goroutine of its own, so that a slow or
+// unavailable sink never stalls the commands. A record is dropped only once
+// the sink has acknowledged it: the writes that fail are retried with an
+// exponential backoff, hence the records are delivered at least once.
+//
+// The pending records are coalesced by key, a record replacing the one of its
+// key not yet written, so that the memory held while the sink is unavailable
+// is bounded by the number of keys rather than by the number of writes. The
+// records of a key are written in order, but not the ones of different keys.
+// The records pending when the server stops are lost.
+
+const (
+	defaultBatchSize  = 128
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// Options tune a Forwarder, the zero values selecting the defaults.
+type Options struct {
+	BatchSize  int           // BatchSize is the maximum number of records of a write
+	MinBackoff time.Duration // MinBackoff is the delay before retrying a failed write
+	MaxBackoff time.Duration // MaxBackoff caps the delay, doubled on each failure in a row
+	Logger     *slog.Logger  // Logger reports the failed writes, if not nil
+}
+
+// Stats describes the records forwarded so far.
+type Stats struct {
+	Pending   int    // records waiting to be written
+	Forwarded uint64 // records acknowledged by the sink
+	Failures  uint64 // writes that failed
+	LastError error  // error of the last write that failed
+}
+
+// Forwarder writes behind the records of the keys matching its patterns to a
+// sink.
+type Forwarder struct {
+	sink     Sink
+	patterns []string
+	opts     Options
+
+	mu      sync.Mutex
+	pending map[string]Record // pending maps the keys to their record not yet written
+	queue   []string          // queue holds the keys of pending, by order of submission
+	stats   Stats
+	wake    chan struct{}
+}
+
+// NewForwarder returns a Forwarder writing the records of the keys matching
+// one of the glob-style patterns to s. It writes nothing until Run is called.
+func NewForwarder(s Sink, patterns []string, opts Options) *Forwarder {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(defaultMaxBackoff, opts.MinBackoff)
+	}
+	return &Forwarder{
+		sink:     s,
+		patterns: patterns,
+		opts:     opts,
+		pending:  make(map[string]Record),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Matches reports whether the records of key are forwarded.
+func (f *Forwarder) Matches(key string) bool {
+	for _, pattern := range f.patterns {
+		if regex.GlobMatch(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Submit queues the records to be written, replacing the pending records of
+// their keys. It never blocks.
+func (f *Forwarder) Submit(records []Record) {
+	if len(records) == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	for _, r := range records {
+		if _, ok := f.pending[r.Key]; !ok {
+			f.queue = append(f.queue, r.Key)
+		}
+		f.pending[r.Key] = r
+	}
+	f.mu.Unlock()
+
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Stats returns the statistics about the records forwarded so far.
+func (f *Forwarder) Stats() Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats := f.stats
+	stats.Pending = len(f.pending)
+	return stats
+}
+
+// take removes the next batch of records from the pending ones.
+func (f *Forwarder) take() []Record {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := min(len(f.queue), f.opts.BatchSize)
+	batch := make([]Record, 0, n)
+	for _, key := range f.queue[:n] {
+		batch = append(batch, f.pending[key])
+		delete(f.pending, key)
+	}
+	f.queue = f.queue[n:]
+	return batch
+}
+
+// done records the outcome of the write of batch. The records of a failed
+// write are put back ahead of the pending ones, unless their key was submitted
+// again in the meantime, the new record superseding them.
+func (f *Forwarder) done(batch []Record, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		f.stats.Forwarded += uint64(len(batch))
+		return
+	}
+
+	f.stats.Failures++
+	f.stats.LastError = err
+	retry := make([]string, 0, len(batch))
+	for _, r := range batch {
+		if _, ok := f.pending[r.Key]; !ok {
+			f.pending[r.Key] = r
+			retry = append(retry, r.Key)
+		}
+	}
+	f.queue = append(retry, f.queue...)
+}
+
+// Run writes the pending records to the sink till ctx is done.
+func (f *Forwarder) Run(ctx context.Context) {
+	backoff := time.Duration(0)
+	for {
+		batch := f.take()
+		if len(batch) == 0 {
+			select {
+			case <-f.wake:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		err := f.sink.Write(ctx, batch)
+		f.done(batch, err)
+		if err == nil {
+			backoff = 0
+			continue
+		}
+
+		backoff = min(max(2*backoff, f.opts.MinBackoff), f.opts.MaxBackoff)
+		if f.opts.Logger != nil {
+			f.opts.Logger.Warn("could not write behind the records, retrying",
+				slog.Int("records", len(batch)), slog.Duration("backoff", backoff), slog.Any("error", err))
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
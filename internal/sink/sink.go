@@ -0,0 +1,103 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Record is the state of a key forwarded to a sink: the value it holds, or its
+// deletion. Value holds the RESP encoded commands rebuilding the value along
+// with its expiry, as written to the AOF, so that a sink can replay it into
+// any RESP compatible server or decode it into its own model.
+type Record struct {
+	Key     string `json:"key"`
+	Value   []byte `json:"value,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// Sink is a system of record the state of the keys is written behind to.
+// Write must return nil only once all the records are durably stored, as they
+// are written again otherwise. A record may hence be written several times,
+// the sinks being expected to be idempotent, the last record of a key holding
+// its latest state.
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+// Func is a Sink calling a Go function with the records.
+type Func func(ctx context.Context, records []Record) error
+
+func (f Func) Write(ctx context.Context, records []Record) error {
+	return f(ctx, records)
+}
+
+// KafkaProducer is the part of a Kafka client the Kafka sink needs, so that
+// any client can be plugged in. Produce must return once the message is
+// acknowledged by the brokers.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// KafkaSink produces the records to a Kafka topic, keyed by the keys. The
+// deletions are produced as tombstones, messages without value, so that a
+// compacted topic holds the latest state of every key.
+type KafkaSink struct {
+	Producer KafkaProducer
+	Topic    string
+}
+
+func (s *KafkaSink) Write(ctx context.Context, records []Record) error {
+	for _, r := range records {
+		var value []byte
+		if !r.Deleted {
+			value = r.Value
+		}
+		if err := s.Producer.Produce(ctx, s.Topic, []byte(r.Key), value); err != nil {
+			return fmt.Errorf("producing key %q to %s: %w", r.Key, s.Topic, err)
+		}
+	}
+	return nil
+}
+
+// webhookTimeout bounds the requests of the webhook sink.
+const webhookTimeout = 10 * time.Second
+
+// WebhookSink posts the records to a URL as a JSON array, the values being
+// base64 encoded. Any status other than 2xx fails the write.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client // Client is the client of the requests, http.DefaultClient if nil
+}
+
+func (s *WebhookSink) Write(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s replied with status %d", s.URL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// recorder is a sink recording the records written, failing the writes while
+// fail is set.
+type recorder struct {
+	mu      sync.Mutex
+	fail    bool
+	written []Record
+	writes  chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{writes: make(chan struct{}, 100)}
+}
+
+func (r *recorder) Write(_ context.Context, records []Record) error {
+	r.mu.Lock()
+	defer func() {
+		r.mu.Unlock()
+		r.writes <- struct{}{}
+	}()
+	if r.fail {
+		return errors.New("sink unavailable")
+	}
+	r.written = append(r.written, records...)
+	return nil
+}
+
+func (r *recorder) setFail(fail bool) {
+	r.mu.Lock()
+	r.fail = fail
+	r.mu.Unlock()
+}
+
+func (r *recorder) records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.written...)
+}
+
+// waitForwarded waits till the forwarder has written n records.
+func waitForwarded(t *testing.T, f *Forwarder, n uint64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for f.Stats().Forwarded < n {
+		assert.Assert(t, time.Now().Before(deadline), "%d records forwarded, want %d", f.Stats().Forwarded, n)
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestForwarderMatches(t *testing.T) {
+	f := NewForwarder(newRecorder(), []string{"user:*", "order:[0-9]*"}, Options{})
+	assert.Assert(t, f.Matches("user:1"))
+	assert.Assert(t, f.Matches("order:42"))
+	assert.Assert(t, !f.Matches("order:x"))
+	assert.Assert(t, !f.Matches("session:1"))
+}
+
+func TestForwarderCoalesces(t *testing.T) {
+	r := newRecorder()
+	f := NewForwarder(r, []string{"*"}, Options{BatchSize: 2})
+
+	// the records submitted before Run are coalesced by key
+	f.Submit([]Record{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("1")}})
+	f.Submit([]Record{{Key: "a", Value: []byte("2")}, {Key: "c", Deleted: true}})
+	assert.Equal(t, 3, f.Stats().Pending)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Run(ctx)
+
+	waitForwarded(t, f, 3)
+	assert.DeepEqual(t, []Record{
+		{Key: "a", Value: []byte("2")},
+		{Key: "b", Value: []byte("1")},
+		{Key: "c", Deleted: true},
+	}, r.records())
+	assert.Equal(t, 0, f.Stats().Pending)
+}
+
+func TestForwarderRetries(t *testing.T) {
+	r := newRecorder()
+	r.setFail(true)
+	f := NewForwarder(r, []string{"*"}, Options{MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Run(ctx)
+
+	f.Submit([]Record{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("1")}})
+	<-r.writes
+	<-r.writes
+	stats := f.Stats()
+	assert.Assert(t, stats.Failures >= 2)
+	assert.ErrorContains(t, stats.LastError, "sink unavailable")
+
+	// a record submitted while failing supersedes the one of its key
+	f.Submit([]Record{{Key: "a", Value: []byte("2")}})
+	r.setFail(false)
+
+	waitForwarded(t, f, 2)
+	records := r.records()
+	assert.Equal(t, 2, len(records))
+	for _, rec := range records {
+		if rec.Key == "a" {
+			assert.Equal(t, "2", string(rec.Value))
+		}
+	}
+}
+
+type fakeProducer struct {
+	messages [][3]string
+	fail     bool
+}
+
+func (p *fakeProducer) Produce(_ context.Context, topic string, key, value []byte) error {
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.messages = append(p.messages, [3]string{topic, string(key), string(value)})
+	return nil
+}
+
+func TestKafkaSink(t *testing.T) {
+	p := &fakeProducer{}
+	s := &KafkaSink{Producer: p, Topic: "cache"}
+	assert.NilError(t, s.Write(context.Background(), []Record{{Key: "a", Value: []byte("v")}, {Key: "b", Deleted: true}}))
+	assert.DeepEqual(t, [][3]string{{"cache", "a", "v"}, {"cache", "b", ""}}, p.messages)
+
+	p.fail = true
+	assert.ErrorContains(t, s.Write(context.Background(), []Record{{Key: "a"}}), "broker unavailable")
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got []Record
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Check(t, req.Method == http.MethodPost)
+		body, err := io.ReadAll(req.Body)
+		assert.Check(t, err)
+		assert.Check(t, json.Unmarshal(body, &got))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := &WebhookSink{URL: server.URL}
+	records := []Record{{Key: "a", Value: []byte("*1\r\n$1\r\nv\r\n")}, {Key: "b", Deleted: true}}
+	assert.NilError(t, s.Write(context.Background(), records))
+	assert.DeepEqual(t, records, got)
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorContains(t, s.Write(context.Background(), records), "status 503")
+}
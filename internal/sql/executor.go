@@ -464,9 +464,9 @@ func compareStrings(left, right, operator string) (bool, error) {
 	case sqlparser.GreaterEqualStr:
 		return left >= right, nil
 	case sqlparser.LikeStr:
-		return regex.WildCardMatch(right, left), nil
+		return regex.GlobMatch(right, left), nil
 	case sqlparser.NotLikeStr:
-		return !regex.WildCardMatch(right, left), nil
+		return !regex.GlobMatch(right, left), nil
 	default:
 		return false, fmt.Errorf("unsupported operator for strings: %s", operator)
 	}
@@ -3,10 +3,13 @@ package store
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -82,6 +85,125 @@ func (a *AOF) Load() ([]string, error) {
 	return operations, nil
 }
 
+// aofMaxBulkLen bounds the size of an argument, so that a corrupt length does not
+// trigger a huge allocation.
+const aofMaxBulkLen = 512 << 20
+
+var (
+	ErrAOFTruncated = errors.New("aof: truncated command")
+	ErrAOFCorrupt   = errors.New("aof: corrupt command")
+)
+
+// ReadAOF parses the commands of an AOF file, i.e. RESP arrays of bulk strings,
+// each optionally followed by a newline. It stops at the first command that is
+// truncated or malformed and returns the commands read so far along with the
+// number of bytes they span, so that the file can be truncated to its valid part.
+func ReadAOF(r io.Reader) (cmds [][]string, valid int64, err error) {
+	br := bufio.NewReader(r)
+	var offset int64
+	for {
+		for {
+			b, err := br.ReadByte()
+			if err == io.EOF {
+				return cmds, offset, nil
+			}
+			if err != nil {
+				return cmds, offset, err
+			}
+			if b != '\n' {
+				if err := br.UnreadByte(); err != nil {
+					return cmds, offset, err
+				}
+				break
+			}
+			offset++
+		}
+
+		cmd, n, err := readAOFCommand(br)
+		if err != nil {
+			return cmds, offset, fmt.Errorf("%w at offset %d", err, offset)
+		}
+		cmds = append(cmds, cmd)
+		offset += n
+	}
+}
+
+func readAOFCommand(br *bufio.Reader) (cmd []string, n int64, err error) {
+	count, read, err := readAOFHeader(br, '*')
+	n += read
+	if err != nil {
+		return nil, n, err
+	}
+
+	cmd = make([]string, 0, min(count, 1024))
+	for i := int64(0); i < count; i++ {
+		size, read, err := readAOFHeader(br, '$')
+		n += read
+		if err != nil {
+			return nil, n, err
+		}
+		if size > aofMaxBulkLen {
+			return nil, n, ErrAOFCorrupt
+		}
+
+		data := make([]byte, size+2)
+		read2, err := io.ReadFull(br, data)
+		n += int64(read2)
+		if err != nil {
+			return nil, n, ErrAOFTruncated
+		}
+		if data[size] != '\r' || data[size+1] != '\n' {
+			return nil, n, ErrAOFCorrupt
+		}
+		cmd = append(cmd, string(data[:size]))
+	}
+	return cmd, n, nil
+}
+
+// readAOFHeader reads a `<prefix><length>\r\n` line.
+func readAOFHeader(br *bufio.Reader, prefix byte) (length, n int64, err error) {
+	line, err := br.ReadString('\n')
+	n = int64(len(line))
+	if err == io.EOF {
+		return 0, n, ErrAOFTruncated
+	}
+	if err != nil {
+		return 0, n, err
+	}
+
+	if len(line) < 4 || line[0] != prefix || line[len(line)-2] != '\r' {
+		return 0, n, ErrAOFCorrupt
+	}
+	length, err = strconv.ParseInt(line[1:len(line)-2], 10, 64)
+	if err != nil || length < 0 {
+		return 0, n, ErrAOFCorrupt
+	}
+	return length, n, nil
+}
+
+// RewriteAOF atomically replaces the AOF file at path with the given commands.
+func RewriteAOF(path string, cmds [][]string) error {
+	tmp := path + ".rewrite"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	aof, err := NewAOF(tmp)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if err := aof.Write(string(encode(cmd))); err != nil {
+			aof.Close()
+			return err
+		}
+	}
+	if err := aof.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 func encodeString(v string) []byte {
 	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(v), v))
 }
@@ -104,24 +226,60 @@ func dumpKey(aof *AOF, key string, obj *object.Obj) (err error) {
 	return aof.Write(string(encode(tokens)))
 }
 
-// DumpAllAOF dumps all keys in the store to the AOF file
+func dumpDel(aof *AOF, key string) error {
+	return aof.Write(string(encode([]string{"DEL", key})))
+}
+
+// TakeAOFDirty returns the keys modified since the previous call, to be dumped
+// by DumpAOF. It is called before forking the process dumping them, so that the
+// store starts collecting the keys of the next dump right away.
+func (store *Store) TakeAOFDirty() DirtyKeys {
+	if store.aofDirty == nil {
+		store.aofDirty = store.TrackDirty()
+	}
+	return store.aofDirty.Take()
+}
+
+// DumpAllAOF dumps the keys modified since the previous dump to the AOF file,
+// see DumpAOF.
 func DumpAllAOF(store *Store) error {
+	return DumpAOF(store, store.TakeAOFDirty())
+}
+
+// DumpAOF dumps the dirty keys of the store to the AOF file. As the file is
+// appended to, the keys left unchanged since the previous dump are skipped, and
+// a DEL is written for the dirty keys that no longer exist. The first dump
+// writes all the keys.
+func DumpAOF(store *Store, dirty DirtyKeys) error {
 	var (
 		aof *AOF
 		err error
 	)
-	if aof, err = NewAOF(config.DiceConfig.Server.AOFFile); err != nil {
+	if aof, err = NewAOF(config.Current().Server.AOFFile); err != nil {
 		return err
 	}
 	defer aof.Close()
 
-	log.Println("rewriting AOF file at", config.DiceConfig.Server.AOFFile)
+	log.Println("rewriting AOF file at", config.Current().Server.AOFFile)
 
-	store.store.All(func(k string, obj *object.Obj) bool {
-		err = dumpKey(aof, k, obj)
-		// continue if no error
-		return err == nil
-	})
+	if dirty.All {
+		store.store.All(func(k string, obj *object.Obj) bool {
+			err = dumpKey(aof, k, PlainObj(obj))
+			// continue if no error
+			return err == nil
+		})
+	} else {
+		for _, k := range dirty.Keys {
+			if obj, ok := store.store.Get(k); ok && !hasExpired(obj, store) {
+				err = dumpKey(aof, k, PlainObj(obj))
+			} else {
+				err = dumpDel(aof, k)
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
 
 	log.Println("AOF file rewrite complete")
 	return err
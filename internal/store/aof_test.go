@@ -1,10 +1,12 @@
 package store
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -279,3 +281,84 @@ func BenchmarkAOFWithExat(b *testing.B) {
 		}
 	}
 }
+
+func TestReadAOF(t *testing.T) {
+	set := string(encode([]string{"SET", "k1", "v1"}))
+	del := string(encode([]string{"DEL", "k1"}))
+
+	tests := []struct {
+		name    string
+		input   string
+		cmds    [][]string
+		valid   int
+		wantErr error
+	}{
+		{
+			name:  "commands separated by newlines",
+			input: set + "\n" + del + "\n",
+			cmds:  [][]string{{"SET", "k1", "v1"}, {"DEL", "k1"}},
+			valid: len(set) + len(del) + 2,
+		},
+		{
+			name:  "commands without separators",
+			input: set + del,
+			cmds:  [][]string{{"SET", "k1", "v1"}, {"DEL", "k1"}},
+			valid: len(set) + len(del),
+		},
+		{
+			name:    "truncated command",
+			input:   set + "\n" + del[:len(del)-3],
+			cmds:    [][]string{{"SET", "k1", "v1"}},
+			valid:   len(set) + 1,
+			wantErr: ErrAOFTruncated,
+		},
+		{
+			name:    "corrupt command",
+			input:   set + "\n" + "*2\r\n$3\r\nDEL\r\n:1\r\n",
+			cmds:    [][]string{{"SET", "k1", "v1"}},
+			valid:   len(set) + 1,
+			wantErr: ErrAOFCorrupt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds, valid, err := ReadAOF(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(cmds, tt.cmds) {
+				t.Errorf("expected commands %v, got %v", tt.cmds, cmds)
+			}
+			if valid != int64(tt.valid) {
+				t.Errorf("expected %d valid bytes, got %d", tt.valid, valid)
+			}
+		})
+	}
+}
+
+func TestRewriteAOF(t *testing.T) {
+	path := t.TempDir() + "/test.aof"
+	if err := os.WriteFile(path, []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := [][]string{{"SET", "k1", "v1"}, {"SET", "k2", "v2"}}
+	if err := RewriteAOF(path, cmds); err != nil {
+		t.Fatalf("Failed to rewrite AOF: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	read, _, err := ReadAOF(f)
+	if err != nil {
+		t.Fatalf("Failed to read rewritten AOF: %v", err)
+	}
+	if !reflect.DeepEqual(read, cmds) {
+		t.Errorf("expected commands %v, got %v", cmds, read)
+	}
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"time"
+
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// Waiter is a client blocked by a command, e.g. BLPOP, till one of the keys the
+// command waits for is modified and the command can be served, or till it times
+// out. The waiters of a key are served in the order they blocked.
+type Waiter struct {
+	Keys     []string  // Keys are the keys whose modification may let the command be served
+	Deadline time.Time // Deadline is the time the command times out at, zero to block forever
+
+	// Serve evaluates the command again once one of its keys was modified. It
+	// returns false if the command is still blocked.
+	Serve func() bool
+
+	// Expire replies to the client once the command timed out.
+	Expire func()
+}
+
+// Block registers w on its keys till it is served or times out.
+func (store *Store) Block(w *Waiter) {
+	if store.waiters == nil {
+		store.waiters = make(map[string][]*Waiter)
+		store.blocked = make(map[*Waiter]struct{})
+	}
+	for _, k := range w.Keys {
+		store.waiters[k] = append(store.waiters[k], w)
+	}
+	store.blocked[w] = struct{}{}
+}
+
+// HasWaiters returns true if some clients are blocked.
+func (store *Store) HasWaiters() bool {
+	return len(store.blocked) > 0
+}
+
+// SignalKeysReady records that keys were modified, so that the clients blocked
+// on them are served by the next ServeBlocked. If keys is nil, all the keys
+// with waiters are considered modified.
+func (store *Store) SignalKeysReady(keys []string) {
+	if keys == nil {
+		for k := range store.waiters {
+			store.readyKeys = append(store.readyKeys, k)
+		}
+		return
+	}
+	for _, k := range keys {
+		if _, ok := store.waiters[k]; ok {
+			store.readyKeys = append(store.readyKeys, k)
+		}
+	}
+}
+
+// unblock removes w from the waiters of its keys.
+func (store *Store) unblock(w *Waiter) {
+	for _, k := range w.Keys {
+		waiters := store.waiters[k]
+		for i, other := range waiters {
+			if other == w {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(store.waiters, k)
+		} else {
+			store.waiters[k] = waiters
+		}
+	}
+	delete(store.blocked, w)
+}
+
+// ServeBlocked serves the clients blocked on the keys signaled as ready, in the
+// order they blocked. Serving a client may modify other keys, e.g. the
+// destination of BLMOVE, whose clients are then served as well.
+func ServeBlocked(store *Store) {
+	for len(store.readyKeys) > 0 {
+		k := store.readyKeys[0]
+		store.readyKeys = store.readyKeys[1:]
+
+		// the waiters are copied as serving them modifies the waiters of k
+		waiters := append([]*Waiter(nil), store.waiters[k]...)
+		for _, w := range waiters {
+			if _, ok := store.blocked[w]; !ok {
+				continue
+			}
+			if w.Serve() {
+				store.unblock(w)
+			}
+		}
+	}
+	store.readyKeys = nil
+}
+
+// ExpireBlocked replies to the clients whose blocking commands timed out.
+func ExpireBlocked(store *Store) {
+	now := utils.GetCurrentTime()
+	for w := range store.blocked {
+		if !w.Deadline.IsZero() && !now.Before(w.Deadline) {
+			store.unblock(w)
+			w.Expire()
+		}
+	}
+}
+
+// NextBlockedDeadline returns the earliest time a blocking command times out
+// at, false if no client is blocked with a timeout.
+func (store *Store) NextBlockedDeadline() (time.Time, bool) {
+	var next time.Time
+	for w := range store.blocked {
+		if !w.Deadline.IsZero() && (next.IsZero() || w.Deadline.Before(next)) {
+			next = w.Deadline
+		}
+	}
+	return next, !next.IsZero()
+}
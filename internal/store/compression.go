@@ -0,0 +1,181 @@
+package store
+
+import (
+	"log/slog"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/dencoding"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// Large string values are transparently stored LZ4 compressed when their size
+// is above config.DiceConfig.Server.CompressionThreshold, trading CPU for memory.
+// A compressed object is flagged with the ObjEncodingLZ4 encoding and holds a
+// *compressedValue. The evals only ever see plain values: a read command is
+// handed a decompressed copy, the stored object staying compressed, while a
+// write command, which may modify the objects it reads in place, gets them
+// decompressed in place, see BeginWrite, and compressed again by EndWrite
+// once it is done.
+
+// compressedValue holds an LZ4 compressed string value along with its original size.
+type compressedValue struct {
+	data []byte
+	size int
+}
+
+// CompressionStats describes the values currently stored compressed.
+type CompressionStats struct {
+	Keys            int   // number of keys holding a compressed value
+	RawBytes        int64 // size of the values before compression
+	CompressedBytes int64 // size of the values after compression
+}
+
+// Ratio returns the compression ratio, i.e. RawBytes / CompressedBytes, or 0 if
+// no value is compressed.
+func (s CompressionStats) Ratio() float64 {
+	if s.CompressedBytes == 0 {
+		return 0
+	}
+	return float64(s.RawBytes) / float64(s.CompressedBytes)
+}
+
+// pendingObj is an object written or decompressed while serving a write
+// command, which is a candidate for compression once the command is done.
+type pendingObj struct {
+	key string
+	obj *object.Obj
+}
+
+// CompressionStats returns the statistics about the compressed values of the store.
+func (store *Store) CompressionStats() CompressionStats {
+	return store.compressionStats
+}
+
+// BeginWrite makes the objects read decompressed in place rather than copied,
+// for the write command about to run to modify them, till EndWrite. The calls
+// nest, e.g. for the commands a snapshot restores.
+func (store *Store) BeginWrite() {
+	store.writes++
+}
+
+// EndWrite ends a BeginWrite, compressing the objects written or decompressed
+// once the outermost one ends.
+func (store *Store) EndWrite() {
+	if store.writes--; store.writes == 0 {
+		store.CompressPending()
+	}
+}
+
+// CompressPending compresses the objects written or decompressed since the last call,
+// provided they are still stored under the same key. It must be called once the
+// evaluation of a command is over, as the evals may still hold the plain values.
+func (store *Store) CompressPending() {
+	for _, p := range store.pendingCompression {
+		if current, ok := store.store.Get(p.key); ok && current == p.obj {
+			store.compress(p.obj)
+		}
+	}
+	store.pendingCompression = store.pendingCompression[:0]
+}
+
+// PlainObj returns obj, or a decompressed copy of it if its value is stored
+// compressed, or a copy read back from the cold tier if its value is
+// offloaded. It is meant for the code paths iterating over the raw store.
+func PlainObj(obj *object.Obj) *object.Obj {
+	if isCold(obj) {
+		return coldPlainObj(obj)
+	}
+	if obj.TypeEncoding != object.ObjTypeString|object.ObjEncodingLZ4 {
+		return obj
+	}
+
+	plain := *obj
+	if !decompress(&plain) {
+		return obj
+	}
+	return &plain
+}
+
+func (store *Store) markForCompression(k string, obj *object.Obj) {
+	if config.Current().Server.CompressionThreshold <= 0 {
+		return
+	}
+	store.pendingCompression = append(store.pendingCompression, pendingObj{key: k, obj: obj})
+}
+
+// compress compresses the value of obj in place if it is a raw string larger than
+// the configured threshold. Values that do not shrink are kept as they are.
+func (store *Store) compress(obj *object.Obj) {
+	threshold := config.Current().Server.CompressionThreshold
+	if threshold <= 0 || obj.TypeEncoding != object.ObjTypeString|object.ObjEncodingRaw {
+		return
+	}
+
+	value, ok := obj.Value.(string)
+	if !ok || len(value) < threshold {
+		return
+	}
+
+	data := dencoding.CompressLZ4([]byte(value))
+	if len(data) >= len(value) {
+		return
+	}
+
+	obj.Value = &compressedValue{data: data, size: len(value)}
+	obj.TypeEncoding = object.ObjTypeString | object.ObjEncodingLZ4
+
+	store.compressionStats.Keys++
+	store.compressionStats.RawBytes += int64(len(value))
+	store.compressionStats.CompressedBytes += int64(len(data))
+}
+
+// plainForRead returns the object obj stored at k with its value decompressed,
+// a copy of it unless a write command runs, see BeginWrite, in which case obj
+// is decompressed in place and marked to be compressed again once the command
+// is done.
+func (store *Store) plainForRead(k string, obj *object.Obj) *object.Obj {
+	if obj.TypeEncoding != object.ObjTypeString|object.ObjEncodingLZ4 {
+		return obj
+	}
+	if store.writes == 0 {
+		return PlainObj(obj)
+	}
+
+	store.untrackCompressed(obj)
+	if decompress(obj) {
+		store.markForCompression(k, obj)
+	}
+	return obj
+}
+
+// untrackCompressed updates the statistics when a compressed object is
+// decompressed or leaves the store.
+func (store *Store) untrackCompressed(obj *object.Obj) {
+	if obj.TypeEncoding != object.ObjTypeString|object.ObjEncodingLZ4 {
+		return
+	}
+
+	cv := obj.Value.(*compressedValue)
+	store.compressionStats.Keys--
+	store.compressionStats.RawBytes -= int64(cv.size)
+	store.compressionStats.CompressedBytes -= int64(len(cv.data))
+}
+
+// decompress restores the plain value of obj in place if it is compressed.
+func decompress(obj *object.Obj) bool {
+	if obj.TypeEncoding != object.ObjTypeString|object.ObjEncodingLZ4 {
+		return false
+	}
+
+	cv := obj.Value.(*compressedValue)
+	data, err := dencoding.DecompressLZ4(cv.data, cv.size)
+	if err != nil {
+		// should never happen as the data is produced by compress
+		slog.Error("could not decompress value", slog.Any("error", err))
+		return false
+	}
+
+	obj.Value = string(data)
+	obj.TypeEncoding = object.ObjTypeString | object.ObjEncodingRaw
+	return true
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"gotest.tools/v3/assert"
+)
+
+func TestCompression(t *testing.T) {
+	originalThreshold := config.DiceConfig.Server.CompressionThreshold
+	config.DiceConfig.Server.CompressionThreshold = 1024
+	defer func() { config.DiceConfig.Server.CompressionThreshold = originalThreshold }()
+
+	rawString := object.ObjTypeString | object.ObjEncodingRaw
+	compressedString := object.ObjTypeString | object.ObjEncodingLZ4
+	large := strings.Repeat("dicedb compresses large values ", 100)
+
+	t.Run("large values are compressed once the command is done", func(t *testing.T) {
+		store := NewStore(nil)
+		store.Put("k", store.NewObj(large, -1, object.ObjTypeString, object.ObjEncodingRaw))
+
+		// not compressed until the command is done
+		obj, _ := store.store.Get("k")
+		assert.Equal(t, rawString, obj.TypeEncoding)
+
+		store.CompressPending()
+		obj, _ = store.store.Get("k")
+		assert.Equal(t, compressedString, obj.TypeEncoding)
+
+		stats := store.CompressionStats()
+		assert.Equal(t, 1, stats.Keys)
+		assert.Equal(t, int64(len(large)), stats.RawBytes)
+		assert.Assert(t, stats.CompressedBytes < stats.RawBytes)
+		assert.Assert(t, stats.Ratio() > 1)
+	})
+
+	t.Run("values are read from a decompressed copy", func(t *testing.T) {
+		store := NewStore(nil)
+		store.Put("k", store.NewObj(large, -1, object.ObjTypeString, object.ObjEncodingRaw))
+		store.CompressPending()
+
+		obj := store.Get("k")
+		assert.Equal(t, rawString, obj.TypeEncoding)
+		assert.Equal(t, large, obj.Value)
+		assert.Equal(t, large, store.GetAll([]string{"k"})[0].Value)
+
+		// the stored object stays compressed, with nothing to compress back
+		stored, _ := store.store.Get("k")
+		assert.Equal(t, compressedString, stored.TypeEncoding)
+		assert.Equal(t, 1, store.CompressionStats().Keys)
+		assert.Equal(t, 0, len(store.pendingCompression))
+	})
+
+	t.Run("values are decompressed in place for a write and compressed back", func(t *testing.T) {
+		store := NewStore(nil)
+		store.Put("k", store.NewObj(large, -1, object.ObjTypeString, object.ObjEncodingRaw))
+		store.CompressPending()
+
+		store.BeginWrite()
+		obj := store.Get("k")
+		stored, _ := store.store.Get("k")
+		assert.Assert(t, obj == stored)
+		assert.Equal(t, large, obj.Value)
+		assert.Equal(t, 0, store.CompressionStats().Keys)
+		store.SetExpiry(obj, 60000)
+
+		store.EndWrite()
+		assert.Equal(t, compressedString, obj.TypeEncoding)
+		assert.Equal(t, 1, store.CompressionStats().Keys)
+		assert.Assert(t, store.Get("k").ExpireAt > 0)
+		assert.Equal(t, large, PlainObj(obj).Value)
+	})
+
+	t.Run("small and incompressible values are kept as is", func(t *testing.T) {
+		store := NewStore(nil)
+		store.Put("small", store.NewObj("small", -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+		incompressible := make([]byte, 2048)
+		rand.New(rand.NewSource(42)).Read(incompressible)
+		store.Put("random", store.NewObj(string(incompressible), -1, object.ObjTypeString, object.ObjEncodingRaw))
+		store.CompressPending()
+
+		assert.Equal(t, 0, store.CompressionStats().Keys)
+	})
+
+	t.Run("stats are updated when keys are overwritten or deleted", func(t *testing.T) {
+		store := NewStore(nil)
+		store.Put("k1", store.NewObj(large, -1, object.ObjTypeString, object.ObjEncodingRaw))
+		store.Put("k2", store.NewObj(large, -1, object.ObjTypeString, object.ObjEncodingRaw))
+		store.Put("k3", store.NewObj(large, -1, object.ObjTypeString, object.ObjEncodingRaw))
+		store.CompressPending()
+		assert.Equal(t, 3, store.CompressionStats().Keys)
+
+		store.Put("k1", store.NewObj("small", -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+		store.Del("k2")
+		assert.Equal(t, large, store.GetDel("k3").Value)
+		store.CompressPending()
+
+		assert.Equal(t, CompressionStats{}, store.CompressionStats())
+	})
+}
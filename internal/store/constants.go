@@ -3,4 +3,7 @@ package store
 const (
 	Set string = "set"
 	Del string = "del"
+	// ZDelta is the operation of the events carrying the changes of the members
+	// of a sorted set, see NotifyDeltas
+	ZDelta string = "zdelta"
 )
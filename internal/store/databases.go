@@ -0,0 +1,90 @@
+package store
+
+// A shard holds a number of logical databases, numbered from 0, each backed by
+// a Store of its own: the keys of a database are unknown to the others. The
+// clients pick the database their commands run on with SELECT, the new ones
+// using the database 0.
+//
+// SWAPDB exchanges the stores of two databases, so that the clients of either
+// see the keys of the other right away. A store takes along all its state,
+// e.g. the expiries of its keys and the clients blocked on them.
+
+// Databases are the logical databases of a shard.
+type Databases struct {
+	dbs []*Store
+}
+
+// NewDatabases returns n databases, at least one, whose stores are created by
+// newStore.
+func NewDatabases(n int, newStore func() *Store) *Databases {
+	d := &Databases{dbs: make([]*Store, max(n, 1))}
+	for i := range d.dbs {
+		d.dbs[i] = newStore()
+		d.dbs[i].databases = d
+	}
+	return d
+}
+
+// Len returns the number of databases.
+func (d *Databases) Len() int {
+	return len(d.dbs)
+}
+
+// Get returns the store of the database i, nil if there is no such database.
+func (d *Databases) Get(i int) *Store {
+	if i < 0 || i >= len(d.dbs) {
+		return nil
+	}
+	return d.dbs[i]
+}
+
+// All returns the stores of the databases, in order.
+func (d *Databases) All() []*Store {
+	return d.dbs
+}
+
+// Swap exchanges the databases i and j, which must exist. The transactions
+// watching keys of either abort, as the keys they see are all replaced.
+func (d *Databases) Swap(i, j int) {
+	d.dbs[i], d.dbs[j] = d.dbs[j], d.dbs[i]
+	d.dbs[i].touchAllWatches()
+	d.dbs[j].touchAllWatches()
+}
+
+// Databases returns the databases the store belongs to, nil if it stands alone.
+func (store *Store) Databases() *Databases {
+	return store.databases
+}
+
+// Move moves the key k to the store dst, along with its expiry and the
+// expiries of the fields of its hash. It returns false if k does not exist or
+// dst already holds it, in which case nothing is moved. It must run between
+// BeginWrite and EndWrite, for the object moved to be the one stored.
+func (store *Store) Move(k string, dst *Store) bool {
+	obj := store.Get(k)
+	if obj == nil || dst.Get(k) != nil {
+		return false
+	}
+
+	exp := obj.ExpireAt
+	fe := store.fieldExpiries[k]
+	store.deleteKey(k, obj)
+
+	dst.Put(k, obj, WithRelocation(true))
+	if exp > 0 {
+		dst.setExpireAt(obj, exp)
+		dst.indexExpiry(k, obj)
+	}
+	if fe != nil {
+		if dst.fieldExpiries == nil {
+			dst.fieldExpiries = make(map[string]*fieldExpiry)
+		}
+		dst.fieldExpiries[k] = fe
+	}
+	// the value decompressed in place to be moved is compressed again in dst
+	dst.CompressPending()
+	if dst.HasWaiters() {
+		dst.SignalKeysReady([]string{k})
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package store
+
+import "github.com/dicedb/dice/internal/object"
+
+// ZSetDelta is the change of a member of a sorted set, sent to the clients
+// watching the deltas of its key. OldScore is nil for the members added and
+// NewScore for the ones removed. RankChange is the change of the rank of the
+// member by ascending scores, as returned by ZRANK, nil unless both scores are
+// set.
+type ZSetDelta struct {
+	Member     string
+	OldScore   *float64
+	NewScore   *float64
+	RankChange *int
+}
+
+// WatchDeltas counts a watch of the deltas of k. The commands only compute the
+// deltas of the keys watched, and only if the store notifies a query manager.
+func (store *Store) WatchDeltas(k string) {
+	if store.deltaWatches == nil {
+		store.deltaWatches = make(map[string]int)
+	}
+	store.deltaWatches[k]++
+}
+
+// UnwatchDeltas removes a watch of the deltas of k counted by WatchDeltas.
+func (store *Store) UnwatchDeltas(k string) {
+	if store.deltaWatches[k] <= 1 {
+		delete(store.deltaWatches, k)
+		return
+	}
+	store.deltaWatches[k]--
+}
+
+// IsDeltaWatched returns true if the deltas of k are to be notified. As the
+// watches of the clients disconnected are not removed, it may return true for
+// keys that no client watches anymore.
+func (store *Store) IsDeltaWatched(k string) bool {
+	return store.watchChan != nil && store.deltaWatches[k] > 0
+}
+
+// NotifyDeltas notifies the query manager about the changes of the members of
+// the sorted set stored at k, so that it sends them to the clients watching
+// the key.
+func (store *Store) NotifyDeltas(k string, deltas []ZSetDelta) {
+	if !store.IsDeltaWatched(k) || len(deltas) == 0 {
+		return
+	}
+	store.watchChan <- QueryWatchEvent{Key: k, Operation: ZDelta, Value: object.Obj{}, Deltas: deltas}
+}
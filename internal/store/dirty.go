@@ -0,0 +1,77 @@
+package store
+
+// DirtySet collects the keys modified since it was last taken, so that the AOF
+// rewrites and incremental snapshots only write the keys that changed instead of
+// the whole keyspace. The keys deleted are collected as well.
+type DirtySet struct {
+	keys map[string]struct{}
+	all  bool // all is true if every key must be considered modified, e.g. once the store is reset
+}
+
+// DirtyKeys are the keys taken from a DirtySet.
+type DirtyKeys struct {
+	Keys []string // Keys are the keys modified, deleted ones included
+	All  bool     // All is true if every key must be considered modified, Keys being nil then
+}
+
+func (d *DirtySet) mark(k string) {
+	if !d.all {
+		d.keys[k] = struct{}{}
+	}
+}
+
+func (d *DirtySet) markAll() {
+	d.all = true
+	d.keys = make(map[string]struct{})
+}
+
+// Take returns the keys modified since the previous call and starts collecting
+// the next ones.
+func (d *DirtySet) Take() DirtyKeys {
+	if d.all {
+		d.all = false
+		return DirtyKeys{All: true}
+	}
+
+	keys := make([]string, 0, len(d.keys))
+	for k := range d.keys {
+		keys = append(keys, k)
+	}
+	d.keys = make(map[string]struct{})
+	return DirtyKeys{Keys: keys}
+}
+
+// TrackDirty returns a new DirtySet collecting the keys modified in the store.
+// As it knows nothing of the modifications made before, every key is considered
+// modified till it is first taken.
+func (store *Store) TrackDirty() *DirtySet {
+	d := &DirtySet{keys: make(map[string]struct{}), all: true}
+	store.dirtySets = append(store.dirtySets, d)
+	return d
+}
+
+// MarkDirty marks keys as modified in all the dirty sets, every key if keys is
+// nil, and drops their member indexes. It is called with the keys of the write
+// commands, as they may modify the objects of the keys in place. The watches of
+// the keys are left alone, the commands may not modify them after all, see
+// TouchWatches.
+func (store *Store) MarkDirty(keys []string) {
+	store.dropMemberIndexes(keys)
+	for _, d := range store.dirtySets {
+		if keys == nil {
+			d.markAll()
+			continue
+		}
+		for _, k := range keys {
+			d.mark(k)
+		}
+	}
+}
+
+func (store *Store) markDirty(k string) {
+	delete(store.memberIndexes, k)
+	store.touchWatches(k)
+	for _, d := range store.dirtySets {
+		d.mark(k)
+	}
+}
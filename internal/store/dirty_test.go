@@ -0,0 +1,83 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"gotest.tools/v3/assert"
+)
+
+func TestDirtySet(t *testing.T) {
+	store := NewStore(nil)
+	put := func(k string) {
+		store.Put(k, store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+	}
+	taken := func(d *DirtySet) DirtyKeys {
+		dirty := d.Take()
+		sort.Strings(dirty.Keys)
+		return dirty
+	}
+
+	put("a")
+	d := store.TrackDirty()
+	assert.DeepEqual(t, DirtyKeys{All: true}, taken(d))
+	assert.DeepEqual(t, DirtyKeys{Keys: []string{}}, taken(d))
+
+	put("b")
+	store.Del("a")
+	store.MarkDirty([]string{"c"})
+	store.Rename("b", "d")
+	assert.DeepEqual(t, DirtyKeys{Keys: []string{"a", "b", "c", "d"}}, taken(d))
+
+	// every set collects the modifications independently
+	other := store.TrackDirty()
+	other.Take()
+	put("e")
+	assert.DeepEqual(t, DirtyKeys{Keys: []string{"e"}}, taken(d))
+	put("f")
+	assert.DeepEqual(t, DirtyKeys{Keys: []string{"e", "f"}}, taken(other))
+
+	store.ResetStore()
+	assert.DeepEqual(t, DirtyKeys{All: true}, taken(d))
+	store.MarkDirty(nil)
+	assert.DeepEqual(t, DirtyKeys{All: true}, taken(other))
+}
+
+func TestDumpAOFSkipsUnchangedKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.aof")
+	defer func(file string) { config.DiceConfig.Server.AOFFile = file }(config.DiceConfig.Server.AOFFile)
+	config.DiceConfig.Server.AOFFile = path
+
+	store := NewStore(nil)
+	put := func(k, v string) {
+		store.Put(k, store.NewObj(v, -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+	}
+	dumped := func() [][]string {
+		f, err := os.Open(path)
+		assert.NilError(t, err)
+		defer f.Close()
+		cmds, _, err := ReadAOF(f)
+		assert.NilError(t, err)
+		assert.NilError(t, os.Remove(path))
+		return cmds
+	}
+
+	put("a", "1")
+	put("b", "2")
+	assert.NilError(t, DumpAllAOF(store))
+	assert.Equal(t, 2, len(dumped()))
+
+	put("b", "3")
+	store.Del("a")
+	assert.NilError(t, DumpAllAOF(store))
+	cmds := dumped()
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i][1] < cmds[j][1] })
+	assert.DeepEqual(t, [][]string{{"DEL", "a"}, {"SET", "b", "3"}}, cmds)
+
+	assert.NilError(t, DumpAllAOF(store))
+	assert.Equal(t, 0, len(dumped()))
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"math"
 	"math/rand"
 
 	"github.com/dicedb/dice/config"
@@ -12,7 +13,7 @@ import (
 // TODO: Make it efficient by doing thorough sampling
 func evictFirst(store *Store) {
 	store.store.All(func(k string, obj *object.Obj) bool {
-		store.delByPtr(k)
+		store.evictKey(k, obj)
 		// stop after iterating over the first element
 		return false
 	})
@@ -21,17 +22,45 @@ func evictFirst(store *Store) {
 // Randomly removes keys to make space for the new data added.
 // The number of keys removed will be sufficient to free up at least 10% space
 func evictAllkeysRandom(store *Store) {
-	evictCount := int64(config.DiceConfig.Server.EvictionRatio * float64(config.DiceConfig.Server.KeysLimit))
+	evictCount := int64(config.Current().Server.EvictionRatio * float64(config.Current().Server.KeysLimit))
 	// Iteration of Golang dictionary can be considered as a random
 	// because it depends on the hash of the inserted key
 	store.store.All(func(k string, obj *object.Obj) bool {
-		store.delByPtr(k)
+		store.evictKey(k, obj)
 		evictCount--
 		// continue if evictCount > 0
 		return evictCount > 0
 	})
 }
 
+// evictVolatileTTL removes the keys expiring first, as told by the expire index,
+// to make space for the new data added. The keys having no expiry are never
+// evicted.
+func evictVolatileTTL(store *Store) {
+	evictCount := int64(config.Current().Server.EvictionRatio * float64(config.Current().Server.KeysLimit))
+	for ; evictCount > 0; evictCount-- {
+		k, obj, ok := store.popSoonestExpiry(math.MaxUint64)
+		if !ok {
+			return
+		}
+		store.evictKey(k, obj)
+	}
+}
+
+// evictVolatileLRU removes the least recently used keys among the ones having
+// an expiry, as sampled, to make space for the new data added. The keys having
+// no expiry are never evicted.
+func evictVolatileLRU(store *Store) {
+	evictCount := int64(config.Current().Server.EvictionRatio * float64(config.Current().Server.KeysLimit))
+	for ; evictCount > 0; evictCount-- {
+		k, obj, ok := store.evictionCandidate(config.EvictVolatileLRU)
+		if !ok {
+			return
+		}
+		store.evictKey(k, obj)
+	}
+}
+
 /*
  *  The approximated LRU algorithm
  */
@@ -45,18 +74,50 @@ func GetLFULogCounter(lastAccessedAt uint32) uint8 {
 
 func UpdateLFULastAccessedAt(lastAccessedAt uint32) uint32 {
 	currentUnixTime := getCurrentClock()
-	counter := GetLFULogCounter(lastAccessedAt)
+	counter := decayedLFUCounter(lastAccessedAt)
 
 	counter = incrLogCounter(counter)
 	return (uint32(counter) << 24) | currentUnixTime
 }
 
+// lfuInitVal is the LFU log counter of the keys put, so that the new keys are
+// not evicted before they get a chance to be accessed.
+const lfuInitVal = 5
+
+// newLastAccessedAt returns the LastAccessedAt of an object put now.
+func newLastAccessedAt() uint32 {
+	return (uint32(lfuInitVal) << 24) | getCurrentClock()
+}
+
+// decayedLFUCounter returns the LFU log counter of an object as of now: the
+// counter is decremented once for every config.DiceConfig.Server.LFUDecayTime
+// minutes the object stayed idle, so that the keys once popular are evicted
+// eventually. The counter never decays if the decay time is 0.
+func decayedLFUCounter(lastAccessedAt uint32) uint8 {
+	counter := GetLFULogCounter(lastAccessedAt)
+	decayTime := config.Current().Server.LFUDecayTime
+	if decayTime <= 0 {
+		return counter
+	}
+	periods := GetIdleTime(lastAccessedAt) / uint32(decayTime*60)
+	if periods >= uint32(counter) {
+		return 0
+	}
+	return counter - uint8(periods)
+}
+
 func GetLastAccessedAt(lastAccessedAt uint32) uint32 {
 	return lastAccessedAt & 0x00FFFFFF
 }
 
+// NewLastAccessedAt returns the LastAccessedAt of an object last accessed idle
+// seconds ago, whose LFU log counter is counter.
+func NewLastAccessedAt(counter uint8, idle uint32) uint32 {
+	return (uint32(counter) << 24) | ((getCurrentClock() - idle) & 0x00FFFFFF)
+}
+
 func UpdateLastAccessedAt(lastAccessedAt uint32) uint32 {
-	if config.DiceConfig.Server.EvictionPolicy == config.EvictAllKeysLFU {
+	if config.Current().Server.EvictionPolicy == config.EvictAllKeysLFU {
 		return UpdateLFULastAccessedAt(lastAccessedAt)
 	}
 	return getCurrentClock()
@@ -72,8 +133,10 @@ func incrLogCounter(counter uint8) uint8 {
 	if counter == 255 {
 		return 255
 	}
+	// the counter grows from its initial value, see lfuInitVal
+	baseVal := max(int(counter)-lfuInitVal, 0)
 	randomFactor := rand.Float32() //nolint:gosec
-	approxFactor := 1.0 / float32(counter*uint8(config.DiceConfig.Server.LFULogFactor)+1)
+	approxFactor := 1.0 / float32(baseVal*config.Current().Server.LFULogFactor+1)
 	if approxFactor > randomFactor {
 		counter++
 	}
@@ -109,19 +172,21 @@ func PopulateEvictionPool(store *Store) {
 // TODO: no need to populate everytime. should populate only when the number of keys to evict is less than what we have in the pool
 func EvictAllkeysLRUOrLFU(store *Store) {
 	PopulateEvictionPool(store)
-	evictCount := int16(config.DiceConfig.Server.EvictionRatio * float64(config.DiceConfig.Server.KeysLimit))
+	evictCount := int16(config.Current().Server.EvictionRatio * float64(config.Current().Server.KeysLimit))
 
 	for i := 0; i < int(evictCount) && len(EPool.pool) > 0; i++ {
 		item := EPool.Pop()
 		if item == nil {
 			return
 		}
-		store.DelByPtr(item.keyPtr)
+		if obj, ok := store.store.Get(item.keyPtr); ok {
+			store.evictKey(item.keyPtr, obj)
+		}
 	}
 }
 
 func (store *Store) evict() {
-	switch config.DiceConfig.Server.EvictionPolicy {
+	switch config.Current().Server.EvictionPolicy {
 	case config.EvictSimpleFirst:
 		evictFirst(store)
 	case config.EvictAllKeysRandom:
@@ -130,5 +195,9 @@ func (store *Store) evict() {
 		EvictAllkeysLRUOrLFU(store)
 	case config.EvictAllKeysLFU:
 		EvictAllkeysLRUOrLFU(store)
+	case config.EvictVolatileTTL:
+		evictVolatileTTL(store)
+	case config.EvictVolatileLRU:
+		evictVolatileLRU(store)
 	}
 }
@@ -42,8 +42,8 @@ func (a ByCounterAndIdleTime) Swap(i, j int) {
 }
 
 func (a ByCounterAndIdleTime) Less(i, j int) bool {
-	counterI := GetLFULogCounter(a[i].lastAccessedAt)
-	counterJ := GetLFULogCounter(a[j].lastAccessedAt)
+	counterI := decayedLFUCounter(a[i].lastAccessedAt)
+	counterJ := decayedLFUCounter(a[j].lastAccessedAt)
 
 	if counterI == counterJ {
 		// if access counters are same, sort by idle time
@@ -66,15 +66,15 @@ func (pq *EvictionPool) Push(key string, lastAccessedAt uint32) {
 		pq.pool = append(pq.pool, item)
 
 		// Performance bottleneck
-		if config.DiceConfig.Server.EvictionPolicy == config.EvictAllKeysLFU {
+		if config.Current().Server.EvictionPolicy == config.EvictAllKeysLFU {
 			sort.Sort(ByCounterAndIdleTime(pq.pool))
 		} else {
 			sort.Sort(ByIdleTime(pq.pool))
 		}
 	} else {
 		shouldShift := func() bool {
-			if config.DiceConfig.Server.EvictionPolicy == config.EvictAllKeysLFU {
-				logCounter, poolLogCounter := GetLFULogCounter(lastAccessedAt), GetLFULogCounter(pq.pool[0].lastAccessedAt)
+			if config.Current().Server.EvictionPolicy == config.EvictAllKeysLFU {
+				logCounter, poolLogCounter := decayedLFUCounter(lastAccessedAt), decayedLFUCounter(pq.pool[0].lastAccessedAt)
 				if logCounter < poolLogCounter {
 					return true
 				}
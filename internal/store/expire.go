@@ -1,64 +1,98 @@
 package store
 
 import (
+	"time"
+
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/server/utils"
 )
 
-func hasExpired(obj *object.Obj, store *Store) bool {
-	exp, ok := store.expires.Get(obj)
-	if !ok {
+func hasExpired(obj *object.Obj, _ *Store) bool {
+	return obj.ExpireAt != 0 && obj.ExpireAt <= uint64(utils.GetCurrentTime().UnixMilli())
+}
+
+// expireIfNeeded returns true if the key k holding obj has expired and must be
+// treated as missing. The key is deleted, unless the store is a replica: the
+// keys of a replica are deleted only once the primary propagates their deletion,
+// so that the replica never diverges from its primary. While the commands of the
+// primary are applied, keys are seen as they are, whether they expired or not.
+func (store *Store) expireIfNeeded(k string, obj *object.Obj) bool {
+	if store.replicating || !hasExpired(obj, store) {
 		return false
 	}
-	return exp <= uint64(utils.GetCurrentTime().UnixMilli())
+	if !store.replica {
+		store.expireKey(k, obj)
+	}
+	return true
 }
 
-func GetExpiry(obj *object.Obj, store *Store) (uint64, bool) {
-	exp, ok := store.expires.Get(obj)
-	return exp, ok
+// expireKey deletes the key k, which has expired.
+func (store *Store) expireKey(k string, obj *object.Obj) {
+	store.deleteKey(k, obj)
+	if store.onExpire != nil {
+		store.onExpire(k)
+	}
 }
 
-func DelExpiry(obj *object.Obj, store *Store) {
-	store.expires.Delete(obj)
+// SetReplica sets whether the store is the one of a replica. A replica never
+// deletes the keys that expired, neither actively nor when they are read: they
+// are served as missing till the primary propagates their deletion.
+func (store *Store) SetReplica(replica bool) {
+	store.replica = replica
 }
 
-// TODO: Optimize
-//   - Sampling
-//   - Unnecessary iteration
-func expireSample(store *Store) float32 {
-	var limit = 20
-	var expiredCount = 0
-	var keysToDelete []string
+// SetReplicating is set to true while applying the commands received from the
+// primary, which see the keys as they are on the primary.
+func (store *Store) SetReplicating(replicating bool) {
+	store.replicating = replicating
+}
 
-	// Collect keys to be deleted
-	store.store.All(func(keyPtr string, obj *object.Obj) bool {
-		limit--
-		if hasExpired(obj, store) {
-			keysToDelete = append(keysToDelete, keyPtr)
-			expiredCount++
-		}
-		// once we iterated to 20 keys that have some expiration set
-		// we break the loop
-		return limit >= 0
-	})
+// ReadOnly returns true if the write commands are refused, the store being the
+// one of a replica: its keys are only written by the replication stream and by
+// the snapshots it loads.
+func (store *Store) ReadOnly() bool {
+	return store.replica && !store.replicating && !store.restoring
+}
 
-	// Delete the keys outside the read lock
-	for _, keyPtr := range keysToDelete {
-		store.DelByPtr(keyPtr)
-	}
+// OnExpire sets the function called with the keys deleted because they expired,
+// e.g. to propagate their deletion to the replicas.
+func (store *Store) OnExpire(f func(k string)) {
+	store.onExpire = f
+}
 
-	return float32(expiredCount) / float32(20.0)
+func GetExpiry(obj *object.Obj, _ *Store) (uint64, bool) {
+	return obj.ExpireAt, obj.ExpireAt != 0
+}
+
+func DelExpiry(obj *object.Obj, store *Store) {
+	store.clearExpiry(obj)
 }
 
-// DeleteExpiredKeys deletes all the expired keys - the active way
-// Sampling approach: https://redis.io/commands/expire/
-func DeleteExpiredKeys(store *Store) {
-	for {
-		frac := expireSample(store)
-		// if the sample had less than 25% keys expired
-		// we break the loop.
-		if frac < 0.25 {
-			break
+// The active expiry deletes the keys expired in the order of their expiry, as
+// told by the expire index, within a time budget. The effort, from 1 to 10,
+// trades the memory held by the keys expired for the time spent sweeping them.
+
+// activeExpireBudgetPercent is the percentage of the period of the sweeps that
+// a sweep may spend at effort 1, each level of effort adding two.
+const activeExpireBudgetPercent = 25
+
+// DeleteExpiredKeys deletes the expired keys - the active way, soonest expired
+// first, for at most its share of period, the time between two sweeps. effort
+// is clamped between 1 and 10. The keys deleted are notified to the watchers
+// like any deletion. A replica never deletes its expired keys, see SetReplica.
+func DeleteExpiredKeys(store *Store, effort int, period time.Duration) {
+	if store.replica {
+		return
+	}
+
+	effort = min(max(effort, 1), 10) - 1
+	deadline := time.Now().Add(period * time.Duration(activeExpireBudgetPercent+2*effort) / 100)
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	for deleted := 0; !PastDeadline(deadline, deleted); deleted++ {
+		k, obj, ok := store.popSoonestExpiry(now)
+		if !ok {
+			return
 		}
+		store.expireKey(k, obj)
 	}
 }
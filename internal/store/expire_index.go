@@ -0,0 +1,123 @@
+package store
+
+import (
+	"container/heap"
+
+	"github.com/dicedb/dice/internal/object"
+)
+
+// The expire index orders the keys having an expiry by deadline, so that the
+// active expiry and the volatile-ttl eviction find the keys expiring first in
+// O(log n) rather than by sampling. The expiries are held by the objects, which
+// know nothing about their key: the index is fed by the writes of the keys,
+// i.e. by Put and by the write commands once executed, see IndexExpiries.
+//
+// The index is lazy: the entries are never removed when an expiry changes or a
+// key is deleted, they are skipped once they reach the top of the heap if they
+// no longer describe the expiry of their key. The heap is rebuilt once it holds
+// too many of those stale entries, e.g. when the keys have their expiry pushed
+// back at every access.
+
+const (
+	// expireIndexMaxStale is the ratio of entries to keys indexed above which
+	// the heap is rebuilt from the keys indexed.
+	expireIndexMaxStale = 2
+	// expireIndexMinRebuild is the number of entries in excess below which the
+	// heap is never rebuilt, the rebuild costing more than the memory it saves.
+	expireIndexMinRebuild = 1024
+)
+
+type expireEntry struct {
+	at  uint64 // unix-time-milliseconds the key expires at
+	key string
+}
+
+type expireHeap []expireEntry
+
+func (h expireHeap) Len() int           { return len(h) }
+func (h expireHeap) Less(i, j int) bool { return h[i].at < h[j].at }
+func (h expireHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expireHeap) Push(x any)        { *h = append(*h, x.(expireEntry)) }
+func (h *expireHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+type expireIndex struct {
+	heap    expireHeap
+	indexed map[string]uint64 // indexed maps the keys indexed to the deadline of their valid entry
+}
+
+// index records that key expires at at, or never if at is 0.
+func (idx *expireIndex) index(key string, at uint64) {
+	if idx.indexed == nil {
+		idx.indexed = make(map[string]uint64)
+	}
+	if at == 0 {
+		delete(idx.indexed, key)
+		return
+	}
+	if idx.indexed[key] == at {
+		return
+	}
+	idx.indexed[key] = at
+	heap.Push(&idx.heap, expireEntry{at: at, key: key})
+
+	if len(idx.heap) > expireIndexMaxStale*len(idx.indexed)+expireIndexMinRebuild {
+		idx.rebuild()
+	}
+}
+
+// rebuild drops the stale entries of the heap.
+func (idx *expireIndex) rebuild() {
+	idx.heap = make(expireHeap, 0, len(idx.indexed))
+	for key, at := range idx.indexed {
+		idx.heap = append(idx.heap, expireEntry{at: at, key: key})
+	}
+	heap.Init(&idx.heap)
+}
+
+// IndexExpiries records the expiry of the keys in the expire index, after
+// they were written.
+func (store *Store) IndexExpiries(keys []string) {
+	for _, k := range keys {
+		if obj, ok := store.store.Get(k); ok {
+			store.expireIndex.index(k, obj.ExpireAt)
+		} else {
+			store.expireIndex.index(k, 0)
+		}
+	}
+}
+
+// indexExpiry records the expiry of the key k holding obj in the expire index.
+func (store *Store) indexExpiry(k string, obj *object.Obj) {
+	store.expireIndex.index(k, obj.ExpireAt)
+}
+
+// popSoonestExpiry removes from the expire index the key expiring first and
+// returns it along with its object, if it expires at or before deadline.
+// The entries found stale on the way are dropped, and the keys whose expiry
+// changed without being indexed are indexed again.
+func (store *Store) popSoonestExpiry(deadline uint64) (string, *object.Obj, bool) {
+	idx := &store.expireIndex
+	for len(idx.heap) > 0 && idx.heap[0].at <= deadline {
+		e := heap.Pop(&idx.heap).(expireEntry)
+		if at, ok := idx.indexed[e.key]; !ok || at != e.at {
+			continue
+		}
+		delete(idx.indexed, e.key)
+
+		obj, ok := store.store.Get(e.key)
+		if !ok {
+			continue
+		}
+		if obj.ExpireAt != e.at {
+			store.indexExpiry(e.key, obj)
+			continue
+		}
+		return e.key, obj, true
+	}
+	return "", nil, false
+}
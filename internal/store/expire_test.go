@@ -1,16 +1,18 @@
 package store
 
 import (
+	"math"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
 )
 
 func TestDelExpiry(t *testing.T) {
 	store := NewStore(nil)
-	// Initialize the test environment
-	store.store = NewStoreMap()
-	store.expires = NewExpireMap()
 
 	// Define test cases
 	tests := []struct {
@@ -23,7 +25,7 @@ func TestDelExpiry(t *testing.T) {
 			name: "Object with expiration",
 			obj:  &object.Obj{},
 			setup: func(obj *object.Obj) {
-				store.expires.Put(obj, 12345) // Set some expiration time
+				store.SetUnixTimeMsExpiry(obj, 12345) // Set some expiration time
 			},
 			expected: false,
 		},
@@ -47,10 +49,215 @@ func TestDelExpiry(t *testing.T) {
 			DelExpiry(tc.obj, store)
 
 			// Check if the key has been deleted from the expires map
-			_, exists := store.expires.Get(tc.obj)
+			_, exists := GetExpiry(tc.obj, store)
 			if exists != tc.expected {
 				t.Errorf("%s: expected key to be deleted: %v, got: %v", tc.name, tc.expected, exists)
 			}
 		})
 	}
 }
+
+func TestPersist(t *testing.T) {
+	store := NewStore(nil)
+	obj := store.NewObj("v", 60000, object.ObjTypeString, object.ObjEncodingRaw)
+	store.Put("k", obj)
+
+	if !store.Persist(obj) {
+		t.Error("expected the expiry to be removed")
+	}
+	if _, ok := GetExpiry(obj, store); ok {
+		t.Error("expected the object to have no expiry")
+	}
+	if store.Persist(obj) {
+		t.Error("expected no expiry to remove")
+	}
+}
+
+func TestReplicaExpiry(t *testing.T) {
+	store := NewStore(nil)
+	var expired []string
+	store.OnExpire(func(k string) {
+		expired = append(expired, k)
+	})
+
+	store.Put("k1", store.NewObj("v1", 0, object.ObjTypeString, object.ObjEncodingRaw))
+	store.Put("k2", store.NewObj("v2", 0, object.ObjTypeString, object.ObjEncodingRaw))
+	store.SetReplica(true)
+
+	// a replica serves the expired keys as missing, but keeps them
+	if obj := store.Get("k1"); obj != nil {
+		t.Errorf("expected k1 to be served as missing, got: %v", obj.Value)
+	}
+	DeleteExpiredKeys(store, 1, time.Second)
+	if store.GetKeyCount() != 2 || len(expired) != 0 {
+		t.Errorf("expected the replica to keep its expired keys, got %d keys and %v expired", store.GetKeyCount(), expired)
+	}
+
+	// the commands of the primary see the keys as they are
+	store.SetReplicating(true)
+	if obj := store.Get("k1"); obj == nil || obj.Value != "v1" {
+		t.Errorf("expected k1 to be visible while replicating, got: %v", obj)
+	}
+	store.SetReplicating(false)
+
+	// once promoted, the expired keys are deleted and reported
+	store.SetReplica(false)
+	if obj := store.Get("k1"); obj != nil {
+		t.Errorf("expected k1 to be deleted, got: %v", obj.Value)
+	}
+	DeleteExpiredKeys(store, 1, time.Second)
+	if store.GetKeyCount() != 0 || len(expired) != 2 {
+		t.Errorf("expected the expired keys to be deleted, got %d keys and %v expired", store.GetKeyCount(), expired)
+	}
+}
+
+func TestDeleteExpiredKeys(t *testing.T) {
+	watchChan := make(chan QueryWatchEvent, 1000)
+	store := NewStore(watchChan)
+	for i := 0; i < 1000; i++ {
+		var obj *object.Obj
+		switch {
+		case i < 500:
+			obj = store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw)
+		case i < 900:
+			obj = store.NewObj("v", 0, object.ObjTypeString, object.ObjEncodingRaw)
+		default:
+			obj = store.NewObj("v", 60000, object.ObjTypeString, object.ObjEncodingRaw)
+		}
+		store.Put("k"+strconv.Itoa(i), obj)
+	}
+	for len(watchChan) > 0 {
+		<-watchChan
+	}
+
+	DeleteExpiredKeys(store, 10, time.Second)
+	remaining := store.GetKeyCount()
+	if remaining != 600 {
+		t.Errorf("expected the expired keys to be deleted, got %d keys", remaining)
+	}
+	if len(watchChan) != 1000-remaining {
+		t.Errorf("expected a delete event per key expired, got %d events for %d keys", len(watchChan), 1000-remaining)
+	}
+	for len(watchChan) > 0 {
+		if e := <-watchChan; e.Operation != Del {
+			t.Errorf("expected a delete event, got %s", e.Operation)
+		}
+	}
+	for i := 0; i < 500; i++ {
+		if store.Get("k"+strconv.Itoa(i)) == nil {
+			t.Fatalf("expected the key k%d having no expiry to be kept", i)
+		}
+	}
+
+	// no time to sweep, the sweep stops at the first check of its deadline
+	store = NewStore(nil)
+	for i := 0; i < 1000; i++ {
+		store.Put("k"+strconv.Itoa(i), store.NewObj("v", 0, object.ObjTypeString, object.ObjEncodingRaw))
+	}
+	DeleteExpiredKeys(store, 1, 0)
+	if store.GetKeyCount() != 1000-scanDeadlineCheckInterval {
+		t.Errorf("expected %d keys to be deleted, got %d keys", scanDeadlineCheckInterval, store.GetKeyCount())
+	}
+}
+
+func TestExpireIndex(t *testing.T) {
+	store := NewStore(nil)
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	for i, at := range []uint64{now + 3000, now + 1000, now + 2000} {
+		obj := store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw)
+		store.SetUnixTimeMsExpiry(obj, at)
+		store.Put("k"+strconv.Itoa(i), obj)
+	}
+	store.Put("persistent", store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw))
+
+	// the expiries changed in place are picked up once the keys are indexed again
+	store.SetUnixTimeMsExpiry(store.Get("k0"), now+500)
+	store.IndexExpiries([]string{"k0"})
+	store.Persist(store.Get("k2"))
+	store.IndexExpiries([]string{"k2"})
+	store.Del("k1")
+
+	k, _, ok := store.popSoonestExpiry(now + 600)
+	if !ok || k != "k0" {
+		t.Errorf("expected k0 to expire first, got %q", k)
+	}
+	if k, _, ok = store.popSoonestExpiry(math.MaxUint64); ok {
+		t.Errorf("expected no key left to expire, got %q", k)
+	}
+
+	// the heap is rebuilt once it holds too many stale entries
+	obj := store.Get("persistent")
+	for i := 0; i < 5000; i++ {
+		store.SetUnixTimeMsExpiry(obj, now+uint64(i)+1)
+		store.IndexExpiries([]string{"persistent"})
+	}
+	if n := len(store.expireIndex.heap); n > expireIndexMaxStale+expireIndexMinRebuild {
+		t.Errorf("expected the stale entries to be dropped, got %d entries", n)
+	}
+	if k, _, ok = store.popSoonestExpiry(math.MaxUint64); !ok || k != "persistent" {
+		t.Errorf("expected persistent to expire, got %q", k)
+	}
+}
+
+func TestEvictVolatileTTL(t *testing.T) {
+	defer func(policy string, limit int, ratio float64) {
+		config.DiceConfig.Server.EvictionPolicy = policy
+		config.DiceConfig.Server.KeysLimit = limit
+		config.DiceConfig.Server.EvictionRatio = ratio
+	}(config.DiceConfig.Server.EvictionPolicy, config.DiceConfig.Server.KeysLimit, config.DiceConfig.Server.EvictionRatio)
+	config.DiceConfig.Server.EvictionPolicy = config.EvictVolatileTTL
+	config.DiceConfig.Server.KeysLimit = 4
+	config.DiceConfig.Server.EvictionRatio = 0.5
+
+	store := NewStore(nil)
+	store.Put("persistent", store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw))
+	store.Put("late", store.NewObj("v", 30000, object.ObjTypeString, object.ObjEncodingRaw))
+	store.Put("soon", store.NewObj("v", 10000, object.ObjTypeString, object.ObjEncodingRaw))
+	store.Put("sooner", store.NewObj("v", 5000, object.ObjTypeString, object.ObjEncodingRaw))
+
+	// the keys expiring first make room for the new one
+	store.Put("new", store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw))
+	for _, k := range []string{"persistent", "late", "new"} {
+		if store.Get(k) == nil {
+			t.Errorf("expected %s to be kept", k)
+		}
+	}
+	if store.GetKeyCount() != 3 {
+		t.Errorf("expected sooner and soon to be evicted, got %d keys", store.GetKeyCount())
+	}
+}
+
+func TestExpiryKeptByTheObject(t *testing.T) {
+	store := NewStore(nil)
+	obj := store.NewObj("v", 60000, object.ObjTypeString, object.ObjEncodingRaw)
+	store.Put("k", obj)
+	exp, ok := GetExpiry(obj, store)
+	if !ok || obj.ExpireAt != exp {
+		t.Fatalf("expected the object to hold its expiry, got %d", obj.ExpireAt)
+	}
+
+	// the copies of the object keep its expiry
+	cp := *obj
+	if got, ok := GetExpiry(&cp, store); !ok || got != exp {
+		t.Errorf("expected the copy to expire at %d, got %d", exp, got)
+	}
+
+	// putting the object again keeps its expiry only if told to
+	store.Put("k", obj, WithKeepTTL(true))
+	if got, ok := GetExpiry(obj, store); !ok || got != exp {
+		t.Errorf("expected the expiry %d to be kept, got %d", exp, got)
+	}
+	store.Put("k", obj)
+	if _, ok := GetExpiry(obj, store); ok {
+		t.Error("expected the expiry to be dropped")
+	}
+	if n := store.TableStats().Expires; n != 0 {
+		t.Errorf("expected no key with an expiry, got %d", n)
+	}
+
+	// an expiry at the epoch is an expiry all the same
+	store.SetUnixTimeMsExpiry(obj, 0)
+	if store.Get("k") != nil {
+		t.Error("expected the key to be expired")
+	}
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"reflect"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// The fields of a hash can be given an expiry of their own, e.g. by HEXPIRE,
+// as can the members of a set or a sorted set by EXPIREMEMBER, the members being
+// the fields of those. Like the keys, the fields that expired are deleted when
+// their hash is read and by ExpireFields, run by the cron of the shards. The
+// fields are deleted by the ExpireFieldsFunc of the hash, as the store does not
+// know the layout of the values.
+//
+// The expiries belong to the hash of their key: they are dropped once the key
+// is deleted or overwritten by another value, and follow the key when it is
+// renamed. As with the keys, a replica never deletes the fields that expired,
+// the primary propagates their deletion instead.
+
+// ExpireFieldsFunc deletes the fields of obj. It returns whether obj is empty
+// afterwards, in which case its key is deleted.
+type ExpireFieldsFunc func(obj *object.Obj, fields []string) (empty bool)
+
+type fieldExpiry struct {
+	obj    *object.Obj       // obj is the hash the expiries were set on
+	fields map[string]uint64 // fields maps the fields to their expiry, in unix-time-milliseconds
+	next   uint64            // next is a lower bound of the expiries, the fields never expiring before
+	expire ExpireFieldsFunc
+}
+
+// SetFieldExpiry sets the expiry of the field of the hash stored at key, which
+// must exist, in unix-time-milliseconds.
+func (store *Store) SetFieldExpiry(key, field string, expireAtMs uint64, expire ExpireFieldsFunc) {
+	obj, ok := store.store.Get(key)
+	if !ok {
+		return
+	}
+	fe := store.fieldExpiryOf(key)
+	if fe == nil {
+		if store.fieldExpiries == nil {
+			store.fieldExpiries = make(map[string]*fieldExpiry)
+		}
+		fe = &fieldExpiry{obj: obj, fields: make(map[string]uint64), next: expireAtMs}
+		store.fieldExpiries[key] = fe
+	}
+	fe.fields[field] = expireAtMs
+	fe.next = min(fe.next, expireAtMs)
+	fe.expire = expire
+}
+
+// FieldExpiry returns the expiry of the field of the hash stored at key, in
+// unix-time-milliseconds, false if it has none.
+func (store *Store) FieldExpiry(key, field string) (uint64, bool) {
+	fe := store.fieldExpiryOf(key)
+	if fe == nil {
+		return 0, false
+	}
+	exp, ok := fe.fields[field]
+	return exp, ok
+}
+
+// FieldExpiries calls fn with the fields of the hash stored at key having an
+// expiry, along with their expiry in unix-time-milliseconds.
+func (store *Store) FieldExpiries(key string, fn func(field string, expireAtMs uint64)) {
+	fe := store.fieldExpiryOf(key)
+	if fe == nil {
+		return
+	}
+	for field, exp := range fe.fields {
+		fn(field, exp)
+	}
+}
+
+// DelFieldExpiry removes the expiry of the fields of the hash stored at key,
+// e.g. once they are overwritten or deleted. It returns the number of fields
+// that had an expiry.
+func (store *Store) DelFieldExpiry(key string, fields ...string) int {
+	fe := store.fieldExpiryOf(key)
+	if fe == nil {
+		return 0
+	}
+	removed := 0
+	for _, field := range fields {
+		if _, ok := fe.fields[field]; ok {
+			delete(fe.fields, field)
+			removed++
+		}
+	}
+	if len(fe.fields) == 0 {
+		delete(store.fieldExpiries, key)
+	}
+	return removed
+}
+
+// OnExpireFields sets the function called with the fields deleted because they
+// expired along with their key and the object holding them, e.g. to propagate
+// their deletion to the replicas.
+func (store *Store) OnExpireFields(f func(k string, obj *object.Obj, fields []string)) {
+	store.onExpireFields = f
+}
+
+// fieldExpiryOf returns the expiries of the fields of the hash stored at key,
+// nil if it has none.
+func (store *Store) fieldExpiryOf(key string) *fieldExpiry {
+	fe, ok := store.fieldExpiries[key]
+	if !ok {
+		return nil
+	}
+	if obj, ok := store.store.Get(key); !ok || obj != fe.obj {
+		delete(store.fieldExpiries, key)
+		return nil
+	}
+	return fe
+}
+
+// replaceFieldExpiry is called when the object of key is replaced by obj. The
+// commands updating a hash store it again in a new object: the expiries of its
+// fields are kept if obj holds the same hash, and dropped otherwise.
+func (store *Store) replaceFieldExpiry(key string, obj *object.Obj) {
+	fe, ok := store.fieldExpiries[key]
+	if !ok {
+		return
+	}
+	old := collectionPointer(fe.obj.Value)
+	if old == 0 || old != collectionPointer(obj.Value) {
+		delete(store.fieldExpiries, key)
+		return
+	}
+	fe.obj = obj
+}
+
+// collectionPointer returns the pointer of the map holding the fields of value,
+// 0 if there is none. The sorted sets hold their member map along with their
+// tree.
+func collectionPointer(value interface{}) uintptr {
+	if values, ok := value.([]interface{}); ok {
+		for _, v := range values {
+			if p := collectionPointer(v); p != 0 {
+				return p
+			}
+		}
+		return 0
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Map {
+		return v.Pointer()
+	}
+	return 0
+}
+
+// renameFieldExpiry moves the expiries of the fields of the hash stored at src
+// to dst.
+func (store *Store) renameFieldExpiry(src, dst string) {
+	fe, ok := store.fieldExpiries[src]
+	if !ok {
+		return
+	}
+	delete(store.fieldExpiries, src)
+	store.fieldExpiries[dst] = fe
+}
+
+// expireFieldsIfNeeded deletes the fields that expired of the hash obj stored
+// at k. It returns true if the hash is left empty, and its key deleted.
+func (store *Store) expireFieldsIfNeeded(k string, obj *object.Obj) bool {
+	if store.replica || store.replicating || len(store.fieldExpiries) == 0 {
+		return false
+	}
+	fe, ok := store.fieldExpiries[k]
+	if !ok || fe.obj != obj {
+		return false
+	}
+	return store.expireFields(k, fe, uint64(utils.GetCurrentTime().UnixMilli()))
+}
+
+func (store *Store) expireFields(k string, fe *fieldExpiry, now uint64) bool {
+	if fe.next > now {
+		return false
+	}
+
+	var expired []string
+	next := uint64(0)
+	for field, exp := range fe.fields {
+		if exp <= now {
+			expired = append(expired, field)
+			delete(fe.fields, field)
+		} else if next == 0 || exp < next {
+			next = exp
+		}
+	}
+	fe.next = next
+	if len(fe.fields) == 0 {
+		delete(store.fieldExpiries, k)
+	}
+	if len(expired) == 0 {
+		return false
+	}
+
+	empty := fe.expire(fe.obj, expired)
+	store.MarkViewsStale([]string{k})
+	store.markDirty(k)
+	if empty {
+		store.deleteKey(k, fe.obj)
+	}
+	if store.onExpireFields != nil {
+		store.onExpireFields(k, fe.obj, expired)
+	}
+	return empty
+}
+
+// ExpireFields deletes all the fields that expired - the active way.
+func ExpireFields(store *Store) {
+	if store.replica || len(store.fieldExpiries) == 0 {
+		return
+	}
+
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	for k, fe := range store.fieldExpiries {
+		obj, ok := store.store.Get(k)
+		if !ok || obj != fe.obj {
+			delete(store.fieldExpiries, k)
+			continue
+		}
+		if fe.next > now || hasExpired(obj, store) || !store.faultIn(k, obj) {
+			continue
+		}
+		store.expireFields(k, fe, now)
+	}
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"gotest.tools/v3/assert"
+)
+
+func TestFieldExpiry(t *testing.T) {
+	store := NewStore(nil)
+	var expired []string
+	store.OnExpireFields(func(k string, _ *object.Obj, fields []string) {
+		sort.Strings(fields)
+		for _, f := range fields {
+			expired = append(expired, k+"."+f)
+		}
+	})
+	expire := func(obj *object.Obj, fields []string) bool {
+		h := obj.Value.(map[string]string)
+		for _, f := range fields {
+			delete(h, f)
+		}
+		return len(h) == 0
+	}
+	// the hashes are updated in place and stored again in a new object
+	put := func(k string, h map[string]string) {
+		store.Put(k, store.NewObj(h, -1, object.ObjTypeHashMap, object.ObjEncodingHashMap))
+	}
+
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	past, future := now-1000, now+60_000
+
+	h := map[string]string{"a": "1", "b": "2", "c": "3"}
+	put("h", h)
+	store.SetFieldExpiry("h", "a", past, expire)
+	store.SetFieldExpiry("h", "b", future, expire)
+	exp, ok := store.FieldExpiry("h", "b")
+	assert.Assert(t, ok)
+	assert.Equal(t, future, exp)
+
+	// the fields that expired are deleted when the hash is read
+	assert.DeepEqual(t, map[string]string{"b": "2", "c": "3"}, store.Get("h").Value)
+	assert.DeepEqual(t, []string{"h.a"}, expired)
+
+	// storing the same hash again keeps the expiries, another value drops them
+	h["d"] = "4"
+	put("h", h)
+	_, ok = store.FieldExpiry("h", "b")
+	assert.Assert(t, ok)
+	put("h", map[string]string{"b": "2"})
+	_, ok = store.FieldExpiry("h", "b")
+	assert.Assert(t, !ok)
+
+	// the sorted sets hold their member map along with their tree
+	members := map[string]float64{"a": 1}
+	store.Put("z", store.NewObj([]interface{}{"tree", members}, -1, object.ObjTypeSortedSet, object.ObjEncodingBTree))
+	store.SetFieldExpiry("z", "a", future, expire)
+	store.Put("z", store.NewObj([]interface{}{"tree", members}, -1, object.ObjTypeSortedSet, object.ObjEncodingBTree))
+	_, ok = store.FieldExpiry("z", "a")
+	assert.Assert(t, ok)
+	store.Del("z")
+
+	// the expiries follow the key when it is renamed
+	store.SetFieldExpiry("h", "b", future, expire)
+	store.Rename("h", "renamed")
+	_, ok = store.FieldExpiry("renamed", "b")
+	assert.Assert(t, ok)
+	assert.Equal(t, 1, store.DelFieldExpiry("renamed", "b", "missing"))
+	assert.Equal(t, 0, len(store.fieldExpiries))
+
+	// the active way deletes the key once its hash is empty
+	expired = nil
+	put("e", map[string]string{"a": "1", "b": "2"})
+	store.SetFieldExpiry("e", "a", past, expire)
+	store.SetFieldExpiry("e", "b", past, expire)
+	ExpireFields(store)
+	assert.DeepEqual(t, []string{"e.a", "e.b"}, expired)
+	assert.Assert(t, store.Get("e") == nil)
+	assert.Equal(t, 0, len(store.fieldExpiries))
+
+	// a replica waits for the primary to delete the fields
+	store.SetReplica(true)
+	expired = nil
+	put("r", map[string]string{"a": "1", "b": "2"})
+	store.SetFieldExpiry("r", "a", past, expire)
+	ExpireFields(store)
+	assert.DeepEqual(t, map[string]string{"a": "1", "b": "2"}, store.Get("r").Value)
+	assert.Equal(t, 0, len(expired))
+
+	// deleting the key drops the expiries
+	store.Del("r")
+	assert.Equal(t, 0, len(store.fieldExpiries))
+}
@@ -0,0 +1,237 @@
+package store
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// The memory used by the server is capped by config.DiceConfig.Server.MaxMemory,
+// unlimited if 0. The write commands are refused once the memory used is above
+// the limit, unless the keys evicted as told by the eviction policy free enough
+// memory first, see FreeMemory:
+//   - allkeys-lru and volatile-lru evict the least recently used of a sample of
+//     the keys, the latter only sampling the keys having an expiry.
+//   - allkeys-lfu evicts the least frequently used of a sample of the keys, as
+//     told by their LFU log counter, which decays while the keys are idle.
+//   - allkeys-random evicts any key.
+//   - volatile-ttl evicts the keys expiring first.
+//   - noeviction never evicts a key.
+//
+// The memory used is the live heap of the process as of the last garbage
+// collection, less the estimated size of the keys evicted since, which the next
+// collection reclaims. The memory limit of the Go runtime is set to maxmemory,
+// so that the collections run more often as the heap nears the limit and the
+// measure stays fresh. The memory is shared by the shards, every shard evicting
+// its own keys.
+//
+// A replica never evicts keys, the primary propagates the deletion of the keys
+// it evicts instead.
+
+// evictionSamples is the number of keys sampled to find the one to evict.
+const evictionSamples = 5
+
+// keyOverhead is the memory used by a key besides its name and its value.
+const keyOverhead = 48
+
+type memoryGauge struct {
+	mu      sync.Mutex
+	cycles  uint64 // cycles is the number of garbage collections as of the last measure
+	freed   int64  // freed is the estimated size of the keys evicted since the last garbage collection
+	evicted int64  // evicted is the number of keys evicted
+
+	limit atomic.Int64 // limit is the maxmemory the memory limit of the runtime was last set for
+
+	read     func() (live int64, cycles uint64) // read returns the live heap and the number of garbage collections
+	setLimit func(limit int64) int64            // setLimit sets the memory limit of the runtime
+}
+
+var memory = &memoryGauge{read: readHeap, setLimit: debug.SetMemoryLimit}
+
+// readHeap returns the live heap of the process as of the last garbage
+// collection, along with the number of collections.
+func readHeap() (live int64, cycles uint64) {
+	samples := []metrics.Sample{{Name: "/gc/heap/live:bytes"}, {Name: "/gc/cycles/total:gc-cycles"}}
+	metrics.Read(samples)
+	return int64(samples[0].Value.Uint64()), samples[1].Value.Uint64()
+}
+
+// limitRuntime sets the memory limit of the runtime to maxMemory if it changed,
+// lifting the limit if maxMemory is 0.
+func (g *memoryGauge) limitRuntime(maxMemory int64) {
+	if g.limit.Load() == maxMemory {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.limit.Load() == maxMemory {
+		return
+	}
+	limit := maxMemory
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	g.setLimit(limit)
+	g.limit.Store(maxMemory)
+}
+
+// used returns the memory used.
+func (g *memoryGauge) used() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	live, cycles := g.read()
+	if cycles != g.cycles {
+		g.cycles, g.freed = cycles, 0
+	}
+	return max(live-g.freed, 0)
+}
+
+// release records the eviction of a key of size bytes.
+func (g *memoryGauge) release(size int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.freed += size
+	g.evicted++
+}
+
+// UsedMemory returns the memory used by the server, as measured to enforce
+// maxmemory.
+func UsedMemory() int64 {
+	return memory.used()
+}
+
+// EvictedKeys returns the number of keys evicted to free memory.
+func EvictedKeys() int64 {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	return memory.evicted
+}
+
+// FreeMemory evicts keys, as told by the eviction policy, till the memory used
+// is below maxmemory. It returns false if the memory used is still above, the
+// policy being noeviction or no key being left to evict.
+func (store *Store) FreeMemory() bool {
+	maxMemory := config.Current().Server.MaxMemory
+	memory.limitRuntime(maxMemory)
+	if maxMemory <= 0 || store.replica || store.replicating {
+		return true
+	}
+	used := memory.used()
+
+	policy := config.Current().Server.EvictionPolicy
+	for used > maxMemory {
+		k, obj, ok := store.evictionCandidate(policy)
+		if !ok {
+			return false
+		}
+		size := store.approxSize(k, obj)
+		store.evictKey(k, obj)
+		memory.release(size)
+		used -= size
+	}
+	return true
+}
+
+// evictionCandidate returns the key to evict next as told by policy, false if
+// there is none.
+func (store *Store) evictionCandidate(policy string) (string, *object.Obj, bool) {
+	switch policy {
+	case config.EvictNoEviction:
+		return "", nil, false
+	case config.EvictVolatileTTL:
+		return store.popSoonestExpiry(math.MaxUint64)
+	case config.EvictAllKeysLRU, config.EvictAllKeysLFU, config.EvictVolatileLRU:
+		return store.sampleEvictionCandidate(policy)
+	default:
+		// the iteration of the map starts at a random key
+		var key string
+		var candidate *object.Obj
+		store.store.All(func(k string, obj *object.Obj) bool {
+			key, candidate = k, obj
+			return false
+		})
+		return key, candidate, candidate != nil
+	}
+}
+
+// sampleEvictionCandidate returns the least recently used, or the least
+// frequently used with allkeys-lfu, of a sample of the keys. Only the keys
+// having an expiry are sampled with volatile-lru.
+func (store *Store) sampleEvictionCandidate(policy string) (string, *object.Obj, bool) {
+	var key string
+	var candidate *object.Obj
+	samples := evictionSamples
+	sample := func(k string, obj *object.Obj) bool {
+		if candidate == nil || evictsBefore(policy, obj, candidate) {
+			key, candidate = k, obj
+		}
+		samples--
+		return samples > 0
+	}
+
+	if policy == config.EvictVolatileLRU {
+		for k := range store.expireIndex.indexed {
+			if obj, ok := store.store.Get(k); ok && !sample(k, obj) {
+				break
+			}
+		}
+	} else {
+		store.store.All(sample)
+	}
+	return key, candidate, candidate != nil
+}
+
+// evictsBefore returns true if obj is to be evicted before other as told by
+// policy.
+func evictsBefore(policy string, obj, other *object.Obj) bool {
+	if policy == config.EvictAllKeysLFU {
+		counter, otherCounter := decayedLFUCounter(obj.LastAccessedAt), decayedLFUCounter(other.LastAccessedAt)
+		if counter != otherCounter {
+			return counter < otherCounter
+		}
+	}
+	return GetIdleTime(obj.LastAccessedAt) > GetIdleTime(other.LastAccessedAt)
+}
+
+// evictKey deletes the key k holding obj to free memory.
+func (store *Store) evictKey(k string, obj *object.Obj) {
+	if store.deleteKey(k, obj) && store.onEvict != nil {
+		store.onEvict(k)
+	}
+}
+
+// approxSize returns the approximate memory used by the key k holding obj.
+func (store *Store) approxSize(k string, obj *object.Obj) int64 {
+	size := int64(len(k)) + keyOverhead
+	if store.valueSize != nil {
+		return size + store.valueSize(obj)
+	}
+	switch v := obj.Value.(type) {
+	case string:
+		size += int64(len(v))
+	case []byte:
+		size += int64(len(v))
+	case int64:
+		size += 8
+	}
+	return size
+}
+
+// MeasureValues sets the function estimating the memory used by the values,
+// which the store only knows for the strings and the integers.
+func (store *Store) MeasureValues(f func(obj *object.Obj) int64) {
+	store.valueSize = f
+}
+
+// OnEvict sets the function called with the keys evicted to free memory, or
+// once the keys limit is reached, e.g. to propagate their deletion to the
+// replicas.
+func (store *Store) OnEvict(f func(k string)) {
+	store.onEvict = f
+}
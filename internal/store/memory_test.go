@@ -0,0 +1,140 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"gotest.tools/v3/assert"
+)
+
+// fakeMemory replaces the measure of the memory used by live bytes, returning
+// a function setting the live bytes and the number of garbage collections.
+func fakeMemory(t *testing.T, live int64) func(live int64, cycles uint64) {
+	var cycles uint64
+	read, setLimit := memory.read, memory.setLimit
+	memory.read = func() (int64, uint64) { return live, cycles }
+	memory.setLimit = func(int64) int64 { return 0 }
+	t.Cleanup(func() {
+		memory.read, memory.setLimit = read, setLimit
+		memory.freed, memory.evicted = 0, 0
+		memory.limit.Store(0)
+	})
+	return func(l int64, c uint64) { live, cycles = l, c }
+}
+
+func TestFreeMemory(t *testing.T) {
+	defer func(maxMemory int64, policy string) {
+		config.DiceConfig.Server.MaxMemory = maxMemory
+		config.DiceConfig.Server.EvictionPolicy = policy
+	}(config.DiceConfig.Server.MaxMemory, config.DiceConfig.Server.EvictionPolicy)
+
+	// every key uses 51 bytes: its name, its value and the overhead of a key
+	const keySize = 2 + 1 + keyOverhead
+	setup := func() *Store {
+		store := NewStore(nil)
+		for _, k := range []struct {
+			key     string
+			exp     int64
+			idle    uint32
+			counter uint8
+		}{
+			{"k1", -1, 30, 9},
+			{"k2", 60000, 10, 2},
+			{"k3", 30000, 20, 7},
+		} {
+			store.Put(k.key, store.NewObj("v", k.exp, object.ObjTypeString, object.ObjEncodingRaw))
+			store.GetNoTouch(k.key).LastAccessedAt = NewLastAccessedAt(k.counter, k.idle)
+		}
+		return store
+	}
+
+	tests := []struct {
+		policy  string
+		evicted []string
+	}{
+		{config.EvictAllKeysLRU, []string{"k1"}},
+		{config.EvictAllKeysLFU, []string{"k2"}},
+		{config.EvictVolatileLRU, []string{"k3"}},
+		{config.EvictVolatileTTL, []string{"k3"}},
+		{config.EvictNoEviction, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.policy, func(t *testing.T) {
+			fakeMemory(t, 10000)
+			config.DiceConfig.Server.EvictionPolicy = tc.policy
+			config.DiceConfig.Server.MaxMemory = 10000 - keySize
+
+			store := setup()
+			var propagated []string
+			store.OnEvict(func(k string) { propagated = append(propagated, k) })
+
+			assert.Equal(t, tc.evicted != nil, store.FreeMemory())
+			assert.DeepEqual(t, tc.evicted, propagated)
+			assert.Equal(t, 3-len(tc.evicted), store.GetKeyCount())
+			assert.Equal(t, int64(len(tc.evicted)), EvictedKeys())
+		})
+	}
+
+	t.Run("memory freed till the next collection", func(t *testing.T) {
+		measure := fakeMemory(t, 10000)
+		config.DiceConfig.Server.EvictionPolicy = config.EvictAllKeysLRU
+		config.DiceConfig.Server.MaxMemory = 10000 - keySize
+		store := setup()
+
+		// the key evicted counts as freed till the heap is measured again
+		assert.Assert(t, store.FreeMemory())
+		assert.Equal(t, int64(10000-keySize), UsedMemory())
+		assert.Assert(t, store.FreeMemory())
+		assert.Equal(t, 2, store.GetKeyCount())
+
+		measure(10000, 1)
+		assert.Assert(t, store.FreeMemory())
+		assert.Equal(t, 1, store.GetKeyCount())
+
+		// the keys having no expiry are never evicted by volatile-lru
+		config.DiceConfig.Server.EvictionPolicy = config.EvictVolatileLRU
+		store.Put("k4", store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw))
+		measure(20000, 2)
+		assert.Assert(t, !store.FreeMemory())
+		assert.Assert(t, store.Get("k2") == nil)
+		assert.Assert(t, store.Get("k4") != nil)
+	})
+
+	t.Run("below maxmemory", func(t *testing.T) {
+		fakeMemory(t, 10000)
+		config.DiceConfig.Server.EvictionPolicy = config.EvictNoEviction
+		store := setup()
+
+		config.DiceConfig.Server.MaxMemory = 10000
+		assert.Assert(t, store.FreeMemory())
+		config.DiceConfig.Server.MaxMemory = 0
+		assert.Assert(t, store.FreeMemory())
+		assert.Equal(t, 3, store.GetKeyCount())
+	})
+}
+
+func TestDecayedLFUCounter(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(100000, 0)}
+	utils.CurrentTime = mockTime
+	defer func(decayTime int) {
+		utils.CurrentTime = utils.RealClock{}
+		config.DiceConfig.Server.LFUDecayTime = decayTime
+	}(config.DiceConfig.Server.LFUDecayTime)
+
+	// the counter decays once for every minute idle
+	config.DiceConfig.Server.LFUDecayTime = 1
+	lastAccessedAt := NewLastAccessedAt(10, 0)
+	mockTime.SetTime(time.Unix(100000+3*60+30, 0))
+	assert.Equal(t, uint8(7), decayedLFUCounter(lastAccessedAt))
+	mockTime.SetTime(time.Unix(100000+60*60, 0))
+	assert.Equal(t, uint8(0), decayedLFUCounter(lastAccessedAt))
+
+	config.DiceConfig.Server.LFUDecayTime = 0
+	assert.Equal(t, uint8(10), decayedLFUCounter(lastAccessedAt))
+
+	// the keys put start from the initial counter
+	assert.Equal(t, uint8(lfuInitVal), GetLFULogCounter(newLastAccessedAt()))
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// Sorted sets whose scores are timestamps, e.g. the events of a sliding window,
+// can be given a retention policy: PruneKeys, run by the cron of the shards,
+// removes their elements with a score lower than the current time in
+// milliseconds minus the retention window. The elements are removed by the
+// PruneFunc of the policy, as the store does not know the layout of the values.
+//
+// Like an expiration, a policy belongs to the object of its key: it is dropped
+// once the key is deleted or overwritten.
+
+// PruneFunc removes the elements of obj with a score lower than threshold. It
+// returns the number of elements removed and whether obj is empty afterwards.
+type PruneFunc func(obj *object.Obj, threshold float64) (removed int, empty bool)
+
+type prunePolicy struct {
+	obj      *object.Obj // obj is the object the policy was set on
+	windowMs int64
+	prune    PruneFunc
+}
+
+// SetPrunePolicy sets the retention window of the object stored at key, which
+// must exist, replacing its previous policy if any.
+func (store *Store) SetPrunePolicy(key string, windowMs int64, prune PruneFunc) {
+	obj, ok := store.store.Get(key)
+	if !ok {
+		return
+	}
+	if store.prunePolicies == nil {
+		store.prunePolicies = make(map[string]*prunePolicy)
+	}
+	store.prunePolicies[key] = &prunePolicy{obj: obj, windowMs: windowMs, prune: prune}
+}
+
+// DelPrunePolicy removes the retention policy of the object stored at key.
+func (store *Store) DelPrunePolicy(key string) {
+	delete(store.prunePolicies, key)
+}
+
+// PrunePolicy returns the retention window of the object stored at key, false
+// if it has no retention policy.
+func (store *Store) PrunePolicy(key string) (int64, bool) {
+	p, ok := store.prunePolicies[key]
+	if !ok {
+		return 0, false
+	}
+	if obj, ok := store.store.Get(key); !ok || obj != p.obj {
+		delete(store.prunePolicies, key)
+		return 0, false
+	}
+	return p.windowMs, true
+}
+
+// OnPrune sets the function called with the keys pruned by PruneKeys along with
+// the threshold they were pruned with, e.g. to propagate the pruning to the
+// replicas.
+func (store *Store) OnPrune(f func(k string, threshold float64)) {
+	store.onPrune = f
+}
+
+// PruneKeys applies the retention policies - the active way. A key is deleted
+// once its object is empty. Replicas never prune, the primary propagates the
+// pruning instead.
+func PruneKeys(store *Store) {
+	if store.replica || len(store.prunePolicies) == 0 {
+		return
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	for k, p := range store.prunePolicies {
+		obj, ok := store.store.Get(k)
+		if !ok || obj != p.obj {
+			delete(store.prunePolicies, k)
+			continue
+		}
+		if hasExpired(obj, store) || (isCold(obj) && !store.faultIn(k, obj)) {
+			continue
+		}
+
+		threshold := float64(now - p.windowMs)
+		removed, empty := p.prune(obj, threshold)
+		if removed == 0 {
+			continue
+		}
+
+		store.MarkViewsStale([]string{k})
+		if empty {
+			delete(store.prunePolicies, k)
+			store.deleteKey(k, obj)
+		}
+		if store.onPrune != nil {
+			store.onPrune(k, threshold)
+		}
+	}
+}
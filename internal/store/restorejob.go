@@ -0,0 +1,196 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// A restore job loads a snapshot file into the keyspace, e.g. to stage a
+// dataset next to the one being served, without blocking the shard for the
+// whole file: RunRestoreJobs, run by the cron of the shards, executes the
+// commands of the snapshot in batches. The keys are served as they are loaded,
+// the ones loaded before the job fails or is canceled being kept.
+//
+// The commands of the jobs are not propagated: a job fails as soon as replicas
+// or CDC subscribers follow the store, see SetFollowed, the keys loaded until
+// then reaching them with the rest of the dataset when they sync.
+
+const (
+	// DefaultRestoreJobBatch is the batch size of the jobs started without one.
+	DefaultRestoreJobBatch = 1000
+
+	// maxFinishedRestoreJobs is the number of finished jobs kept for their
+	// status, the oldest one being dropped first.
+	maxFinishedRestoreJobs = 16
+)
+
+// ErrFollowed is the error of the restore jobs stopped because replicas or CDC
+// subscribers follow the store.
+var ErrFollowed = errors.New("replicas or CDC subscribers are attached")
+
+// RestoreJobState is the state of a restore job.
+type RestoreJobState string
+
+const (
+	RestoreJobRunning  RestoreJobState = "running"
+	RestoreJobDone     RestoreJobState = "done"
+	RestoreJobCanceled RestoreJobState = "canceled"
+	RestoreJobFailed   RestoreJobState = "failed"
+)
+
+// RestoreLoader loads the commands of a snapshot into the store.
+type RestoreLoader interface {
+	// Load executes the next n commands of the snapshot at most. It returns
+	// false once the whole snapshot is loaded.
+	Load(n int) (bool, error)
+	// Keys returns the number of keys loaded so far.
+	Keys() int
+	// Close releases the snapshot.
+	Close() error
+}
+
+// RestoreJob is the status of a restore job, as returned by RestoreJobs.
+type RestoreJob struct {
+	ID        uint64
+	Path      string
+	Prefix    string // Prefix is prepended to the keys of the snapshot
+	Batch     int    // Batch is the maximum number of commands executed by a run of the cron
+	Keys      int    // Keys is the number of keys loaded so far
+	State     RestoreJobState
+	Error     string // Error is why the job failed
+	StartedAt time.Time
+
+	loader RestoreLoader
+}
+
+// StartRestoreJob starts a job loading the snapshot at path with loader, batch
+// commands at a time. It returns the id of the job.
+func (store *Store) StartRestoreJob(path, prefix string, batch int, loader RestoreLoader) uint64 {
+	store.lastRestoreJobID++
+	store.restoreJobs = append(store.restoreJobs, &RestoreJob{
+		ID:        store.lastRestoreJobID,
+		Path:      path,
+		Prefix:    prefix,
+		Batch:     batch,
+		State:     RestoreJobRunning,
+		StartedAt: utils.GetCurrentTime(),
+		loader:    loader,
+	})
+	store.dropFinishedRestoreJobs()
+	return store.lastRestoreJobID
+}
+
+// RestoreJobs returns the status of the jobs running and of the last ones
+// finished, in the order they started.
+func (store *Store) RestoreJobs() []RestoreJob {
+	jobs := make([]RestoreJob, len(store.restoreJobs))
+	for i, job := range store.restoreJobs {
+		jobs[i] = *job
+		jobs[i].loader = nil
+	}
+	return jobs
+}
+
+// CancelRestoreJob stops the job id, the keys already loaded being kept. It
+// returns false if the job is unknown or no longer running.
+func (store *Store) CancelRestoreJob(id uint64) bool {
+	for _, job := range store.restoreJobs {
+		if job.ID == id && job.State == RestoreJobRunning {
+			store.finishRestoreJob(job, RestoreJobCanceled, nil)
+			store.dropFinishedRestoreJobs()
+			return true
+		}
+	}
+	return false
+}
+
+// SetFollowed sets the function returning true while replicas or CDC
+// subscribers follow the writes to the store, which the restore jobs do not
+// propagate.
+func (store *Store) SetFollowed(f func() bool) {
+	store.followed = f
+}
+
+// Followed returns true while replicas or CDC subscribers follow the writes to
+// the store, see SetFollowed.
+func (store *Store) Followed() bool {
+	return store.followed != nil && store.followed()
+}
+
+// cancelRestoreJobs stops the running jobs, e.g. once the store is flushed.
+func (store *Store) cancelRestoreJobs() {
+	for _, job := range store.restoreJobs {
+		if job.State == RestoreJobRunning {
+			store.finishRestoreJob(job, RestoreJobCanceled, nil)
+		}
+	}
+	store.dropFinishedRestoreJobs()
+}
+
+func (store *Store) finishRestoreJob(job *RestoreJob, state RestoreJobState, err error) {
+	job.State = state
+	if err == nil {
+		err = job.loader.Close()
+	} else {
+		_ = job.loader.Close()
+	}
+	if err != nil {
+		job.State = RestoreJobFailed
+		job.Error = err.Error()
+	}
+	job.loader = nil
+}
+
+// dropFinishedRestoreJobs forgets the oldest finished jobs beyond
+// maxFinishedRestoreJobs.
+func (store *Store) dropFinishedRestoreJobs() {
+	finished := 0
+	for _, job := range store.restoreJobs {
+		if job.State != RestoreJobRunning {
+			finished++
+		}
+	}
+
+	jobs := store.restoreJobs[:0]
+	for _, job := range store.restoreJobs {
+		if job.State != RestoreJobRunning && finished > maxFinishedRestoreJobs {
+			finished--
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	store.restoreJobs = jobs
+}
+
+// runRestoreJob executes the next batch of commands of job.
+func (store *Store) runRestoreJob(job *RestoreJob) {
+	if store.Followed() {
+		store.finishRestoreJob(job, RestoreJobFailed, ErrFollowed)
+		return
+	}
+
+	more, err := job.loader.Load(job.Batch)
+	job.Keys = job.loader.Keys()
+	switch {
+	case err != nil:
+		store.finishRestoreJob(job, RestoreJobFailed, err)
+	case !more:
+		store.finishRestoreJob(job, RestoreJobDone, nil)
+	}
+}
+
+// RunRestoreJobs runs the next batch of the restore jobs running.
+func RunRestoreJobs(store *Store) {
+	if len(store.restoreJobs) == 0 {
+		return
+	}
+
+	for _, job := range store.restoreJobs {
+		if job.State == RestoreJobRunning {
+			store.runRestoreJob(job)
+		}
+	}
+	store.dropFinishedRestoreJobs()
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// countLoader loads n commands, each loading a key, failing with err once
+// they are all loaded if err is not nil.
+type countLoader struct {
+	n, loaded int
+	err       error
+	closed    bool
+}
+
+func (l *countLoader) Load(n int) (bool, error) {
+	l.loaded = min(l.loaded+n, l.n)
+	if l.loaded == l.n && l.err != nil {
+		return false, l.err
+	}
+	return l.loaded < l.n, nil
+}
+
+func (l *countLoader) Keys() int {
+	return l.loaded
+}
+
+func (l *countLoader) Close() error {
+	l.closed = true
+	return nil
+}
+
+func TestRestoreJob(t *testing.T) {
+	store := NewStore(nil)
+	job := func(id uint64) RestoreJob {
+		for _, job := range store.RestoreJobs() {
+			if job.ID == id {
+				return job
+			}
+		}
+		t.Fatalf("no job %d", id)
+		return RestoreJob{}
+	}
+
+	// the job loads 4 commands per run of the cron
+	loader := &countLoader{n: 10}
+	id := store.StartRestoreJob("dump.resp", "staging:", 4, loader)
+	RunRestoreJobs(store)
+	assert.Equal(t, 4, job(id).Keys)
+	assert.Equal(t, RestoreJobRunning, job(id).State)
+	RunRestoreJobs(store)
+	RunRestoreJobs(store)
+	assert.Equal(t, 10, job(id).Keys)
+	assert.Equal(t, RestoreJobDone, job(id).State)
+	assert.Assert(t, loader.closed)
+
+	// a job fails with the error of its snapshot
+	failing := &countLoader{n: 2, err: errors.New("corrupted")}
+	id = store.StartRestoreJob("bad.resp", "", 4, failing)
+	RunRestoreJobs(store)
+	assert.Equal(t, RestoreJobFailed, job(id).State)
+	assert.Equal(t, "corrupted", job(id).Error)
+	assert.Assert(t, failing.closed)
+
+	// and once replicas follow the store, the keys loaded until then being kept
+	followed := false
+	store.SetFollowed(func() bool { return followed })
+	id = store.StartRestoreJob("dump.resp", "", 4, &countLoader{n: 10})
+	RunRestoreJobs(store)
+	followed = true
+	RunRestoreJobs(store)
+	assert.Equal(t, RestoreJobFailed, job(id).State)
+	assert.Equal(t, ErrFollowed.Error(), job(id).Error)
+	assert.Equal(t, 4, job(id).Keys)
+	followed = false
+
+	// a job can be canceled, and is once the store is flushed
+	id = store.StartRestoreJob("dump.resp", "", 4, &countLoader{n: 10})
+	assert.Assert(t, store.CancelRestoreJob(id))
+	assert.Assert(t, !store.CancelRestoreJob(id))
+	assert.Equal(t, RestoreJobCanceled, job(id).State)
+	id = store.StartRestoreJob("dump.resp", "", 4, &countLoader{n: 10})
+	store.ResetStore()
+	assert.Equal(t, RestoreJobCanceled, job(id).State)
+
+	// the oldest finished jobs are forgotten
+	for i := 0; i < maxFinishedRestoreJobs; i++ {
+		store.CancelRestoreJob(store.StartRestoreJob("dump.resp", "", 4, &countLoader{n: 10}))
+	}
+	assert.Equal(t, maxFinishedRestoreJobs, len(store.RestoreJobs()))
+}
@@ -0,0 +1,307 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/google/btree"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// SCAN supports two iteration modes.
+//
+// The default one walks the keys by ascending hash, the cursor being the hash the
+// next call starts from, as SSCAN, HSCAN and ZSCAN walk the members of a
+// collection. The hash of a key never depends on the layout of the table, which
+// is free to grow or shrink: the iteration holds no state between the calls,
+// yet every key present for the whole iteration is returned exactly once,
+// whatever the keys added or removed in the meantime. A call returns all the
+// keys sharing the hash of its last key. The store keeps its keys ordered by
+// hash in a B-tree, the scan index, so that a call costs O(log n + count). The
+// hash is the upper half of the xxhash of the key, for a default cursor to be
+// below 1<<32.
+//
+// The snapshot mode captures the key set when the iteration starts. Every key
+// present for the whole duration of the scan is returned exactly once, keys
+// removed in the meantime are skipped and keys added after the start are not
+// returned. The cursor embeds the id of the snapshot in its upper 32 bits and the
+// position in the snapshot in its lower 32 bits, hence it is never mistaken for
+// a default cursor.
+//
+// SSCAN, HSCAN and ZSCAN walk the members of a collection the same way, see
+// ScanMembers, by ascending xxhash of the members. The members are not kept
+// ordered as they are modified, the index of a collection is built by the first
+// call walking it instead, and dropped once its key is written.
+//
+// A call may be given a deadline, e.g. to keep the latency of the SCAN commands
+// low on huge keyspaces: the call then returns early, with fewer keys than
+// asked, once it is past its deadline. It always makes some progress.
+
+const (
+	// maxScanSnapshots is the maximum number of snapshot iterations in progress,
+	// the least recently used snapshot is dropped when a new one is needed.
+	maxScanSnapshots = 64
+	// scanSnapshotIdleTimeout is the time after which an unused snapshot is dropped.
+	scanSnapshotIdleTimeout = 5 * time.Minute
+	// maxMemberIndexes is the maximum number of collections indexed for the
+	// member iterations in progress, the least recently used index is dropped
+	// when a new one is needed.
+	maxMemberIndexes = 64
+	// memberIndexIdleTimeout is the time after which an unused member index is
+	// dropped.
+	memberIndexIdleTimeout = 5 * time.Minute
+	// scanDeadlineCheckInterval is the number of items walked between two checks
+	// of the deadline of a call, as reading the clock is not free.
+	scanDeadlineCheckInterval = 16
+)
+
+type scanSnapshot struct {
+	keys     []string
+	lastUsed time.Time
+}
+
+// Scan returns up to count keys starting from cursor along with the cursor of the
+// next call, 0 meaning that the iteration is over. When snapshot is set and cursor
+// is 0, a new snapshot iteration is started. ok is false if the cursor refers to
+// an unknown or expired snapshot.
+func (store *Store) Scan(cursor uint64, count int, snapshot bool) (next uint64, keys []string, ok bool) {
+	return store.ScanFiltered(cursor, count, snapshot, nil, time.Time{})
+}
+
+// ScanFiltered is Scan, returning only the keys matched by match if not nil.
+// As with Redis, count bounds the keys walked, the filter being applied
+// afterwards. The call returns early once past the deadline, which is ignored
+// if zero.
+func (store *Store) ScanFiltered(cursor uint64, count int, snapshot bool, match func(key string) bool,
+	deadline time.Time) (next uint64, keys []string, ok bool) {
+	if cursor>>32 != 0 {
+		return store.scanSnapshot(cursor, count, match, deadline)
+	}
+
+	if snapshot && cursor == 0 {
+		return store.scanSnapshot(store.newScanSnapshot()<<32, count, match, deadline)
+	}
+
+	// the keys expired are walked as well, for a call to cost O(count) however
+	// many the index still holds
+	keys = make([]string, 0, count)
+	walked := 0
+	store.scanIndex.AscendGreaterOrEqual(scanEntry{hash: cursor}, func(e scanEntry) bool {
+		// the keys sharing a hash are returned by the same call
+		if walked > 0 && e.hash != cursor && (walked >= count || PastDeadline(deadline, walked)) {
+			next = e.hash
+			return false
+		}
+		cursor = e.hash
+		walked++
+		if v, _ := store.store.Get(e.key); v == nil || hasExpired(v, store) {
+			return true
+		}
+		if match == nil || match(e.key) {
+			keys = append(keys, e.key)
+		}
+		return true
+	})
+	return next, keys, true
+}
+
+// scanHash returns the hash the default iteration walks the key k by.
+func scanHash(k string) uint64 {
+	return xxhash.Sum64String(k) >> 32
+}
+
+// scanEntry is an entry of the scan index, ordering the keys by hash, then by
+// key for the keys sharing a hash.
+type scanEntry struct {
+	hash uint64
+	key  string
+}
+
+func scanEntryLess(a, b scanEntry) bool {
+	if a.hash != b.hash {
+		return a.hash < b.hash
+	}
+	return a.key < b.key
+}
+
+// memberIndex orders the members of a collection by hash, see ScanMembers.
+type memberIndex struct {
+	obj      *object.Obj // obj is the collection the index was built from
+	from     uint64      // from is the cursor the index was built at, the members below it are not indexed
+	entries  []scanEntry
+	lastUsed time.Time
+}
+
+// ScanMembers walks the members of the collection obj stored at key, e.g. a
+// set, by ascending hash from cursor, as ScanFiltered walks the keys. It
+// returns up to count members matched by match, if not nil, along with the
+// cursor of the next call, 0 once the iteration is over. The call returns
+// early once past the deadline, which is ignored if zero.
+//
+// all iterates over the members of the collection, which are indexed by hash
+// when the iteration starts or the key was written since, so that the other
+// calls cost O(log n + count). has tells whether a member is still in the
+// collection, the members deleted without the key being written, e.g. once
+// they expire, being skipped.
+func (store *Store) ScanMembers(key string, obj *object.Obj, cursor uint64, count int, all func(yield func(member string)),
+	has, match func(member string) bool, deadline time.Time) (next uint64, members []string) {
+	entries := store.memberIndex(key, obj, cursor, all).entries
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].hash >= cursor })
+
+	members = make([]string, 0, min(count, len(entries)-i))
+	for walked := 0; i < len(entries); i++ {
+		e := entries[i]
+		// the members sharing a hash are returned by the same call
+		if walked > 0 && e.hash != cursor && (walked >= count || PastDeadline(deadline, walked)) {
+			return e.hash, members
+		}
+		cursor = e.hash
+		walked++
+		if has(e.key) && (match == nil || match(e.key)) {
+			members = append(members, e.key)
+		}
+	}
+	delete(store.memberIndexes, key)
+	return 0, members
+}
+
+// memberIndex returns the index of the members of the collection obj stored at
+// key from cursor on, building it with all if there is none yet.
+func (store *Store) memberIndex(key string, obj *object.Obj, cursor uint64, all func(yield func(member string))) *memberIndex {
+	now := utils.GetCurrentTime()
+	if idx, ok := store.memberIndexes[key]; ok && idx.obj == obj && idx.from <= cursor {
+		idx.lastUsed = now
+		return idx
+	}
+
+	if store.memberIndexes == nil {
+		store.memberIndexes = make(map[string]*memberIndex)
+	}
+	delete(store.memberIndexes, key)
+	var lruKey string
+	for k, idx := range store.memberIndexes {
+		if now.Sub(idx.lastUsed) > memberIndexIdleTimeout {
+			delete(store.memberIndexes, k)
+			continue
+		}
+		if lruKey == "" || idx.lastUsed.Before(store.memberIndexes[lruKey].lastUsed) {
+			lruKey = k
+		}
+	}
+	if len(store.memberIndexes) >= maxMemberIndexes {
+		delete(store.memberIndexes, lruKey)
+	}
+
+	// the members already walked are left out, for the index to shrink as the
+	// iteration goes when the key is written in the meantime
+	idx := &memberIndex{obj: obj, from: cursor, lastUsed: now}
+	all(func(member string) {
+		if h := xxhash.Sum64String(member); h >= cursor {
+			idx.entries = append(idx.entries, scanEntry{h, member})
+		}
+	})
+	sort.Slice(idx.entries, func(i, j int) bool { return scanEntryLess(idx.entries[i], idx.entries[j]) })
+	store.memberIndexes[key] = idx
+	return idx
+}
+
+// dropMemberIndexes drops the member indexes of keys, as their collections may
+// have been modified, every index if keys is nil.
+func (store *Store) dropMemberIndexes(keys []string) {
+	if keys == nil {
+		store.memberIndexes = nil
+		return
+	}
+	for _, k := range keys {
+		delete(store.memberIndexes, k)
+	}
+}
+
+// newScanIndex returns an empty scan index.
+func newScanIndex() *btree.BTreeG[scanEntry] {
+	return btree.NewG(32, scanEntryLess)
+}
+
+func (store *Store) scanSnapshot(cursor uint64, count int, match func(key string) bool,
+	deadline time.Time) (next uint64, keys []string, ok bool) {
+	id, pos := uint32(cursor>>32), int(uint32(cursor))
+
+	snap, ok := store.scanSnapshots[id]
+	if !ok {
+		return 0, nil, false
+	}
+
+	keys = make([]string, 0, count)
+	// the keys deleted since the snapshot was taken count against the deadline,
+	// not against count
+	for found, walked := 0, 0; pos < len(snap.keys) && found < count && !PastDeadline(deadline, walked); pos++ {
+		walked++
+		// skip the keys deleted or expired since the snapshot was taken
+		if v, _ := store.store.Get(snap.keys[pos]); v != nil && !hasExpired(v, store) {
+			found++
+			if match == nil || match(snap.keys[pos]) {
+				keys = append(keys, snap.keys[pos])
+			}
+		}
+	}
+
+	if pos >= len(snap.keys) {
+		delete(store.scanSnapshots, id)
+		return 0, keys, true
+	}
+
+	snap.lastUsed = utils.GetCurrentTime()
+	return uint64(id)<<32 | uint64(pos), keys, true
+}
+
+// newScanSnapshot captures the current key set and returns the id of the snapshot.
+func (store *Store) newScanSnapshot() uint64 {
+	now := utils.GetCurrentTime()
+
+	if store.scanSnapshots == nil {
+		store.scanSnapshots = make(map[uint32]*scanSnapshot)
+	}
+
+	var lruID uint32
+	for id, snap := range store.scanSnapshots {
+		if now.Sub(snap.lastUsed) > scanSnapshotIdleTimeout {
+			delete(store.scanSnapshots, id)
+			continue
+		}
+		if lruID == 0 || snap.lastUsed.Before(store.scanSnapshots[lruID].lastUsed) {
+			lruID = id
+		}
+	}
+	if len(store.scanSnapshots) >= maxScanSnapshots {
+		delete(store.scanSnapshots, lruID)
+	}
+
+	keys := make([]string, 0, store.store.Len())
+	store.store.All(func(k string, _ *object.Obj) bool {
+		keys = append(keys, k)
+		return true
+	})
+
+	// ids start at 1 so that a snapshot cursor is never 0
+	store.lastScanSnapshotID++
+	if store.lastScanSnapshotID == 0 {
+		store.lastScanSnapshotID++
+	}
+	store.scanSnapshots[store.lastScanSnapshotID] = &scanSnapshot{keys: keys, lastUsed: now}
+
+	return uint64(store.lastScanSnapshotID)
+}
+
+// PastDeadline returns true if a walk having walked walked items, e.g. a call
+// of a SCAN command, is past its deadline, which is ignored if zero. The clock
+// is read once every scanDeadlineCheckInterval items only, the first ones being
+// always walked so that every walk makes progress.
+func PastDeadline(deadline time.Time, walked int) bool {
+	if deadline.IsZero() || walked == 0 || walked%scanDeadlineCheckInterval != 0 {
+		return false
+	}
+	return time.Now().After(deadline)
+}
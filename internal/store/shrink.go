@@ -0,0 +1,77 @@
+package store
+
+import "github.com/dicedb/dice/internal/common"
+
+// Neither the Go maps nor the swiss maps backing the store release their
+// buckets when keys are deleted: after a mass deletion, a table keeps the
+// memory of the most keys it ever held. The store thus remembers the peak size
+// of its tables since they were built, which approximates their capacity, and
+// ShrinkTables rebuilds the tables whose load fell well below it.
+
+const (
+	// shrinkMinPeak is the peak size below which a table is never rebuilt, the
+	// memory to reclaim not being worth the rebuild.
+	shrinkMinPeak = 1024
+	// shrinkLoadFactor is the ratio of its peak size below which a table is
+	// rebuilt.
+	shrinkLoadFactor = 0.25
+)
+
+// TableStats describes the load of the tables of the store.
+type TableStats struct {
+	Keys          int    // number of keys
+	PeakKeys      int    // most keys held since the keys table was built
+	Expires       int    // number of keys with an expiry, held by the keys themselves
+	Rebuilds      uint64 // tables rebuilt to shrink them
+	ReclaimedKeys uint64 // slots reclaimed by the rebuilds, in number of keys
+}
+
+// Fragmentation returns the ratio of the capacity of the keys table that is not
+// used, i.e. 1 - Keys / PeakKeys, or 0 if the table never held a key.
+func (s TableStats) Fragmentation() float64 {
+	if s.PeakKeys == 0 {
+		return 0
+	}
+	return 1 - float64(s.Keys)/float64(s.PeakKeys)
+}
+
+// TableStats returns the statistics about the load of the tables of the store.
+func (store *Store) TableStats() TableStats {
+	stats := store.tableStats
+	stats.Keys = store.store.Len()
+	stats.Expires = store.numExpires
+	return stats
+}
+
+// trackTablePeaks records the size of the tables if it is their peak.
+func (store *Store) trackTablePeaks() {
+	store.tableStats.PeakKeys = max(store.tableStats.PeakKeys, store.store.Len())
+}
+
+// needsShrink returns true if a table of size keys whose peak size is peak
+// should be rebuilt.
+func needsShrink(keys, peak int) bool {
+	return peak >= shrinkMinPeak && float64(keys) < shrinkLoadFactor*float64(peak)
+}
+
+// ShrinkTables rebuilds the tables of the store whose load fell below
+// shrinkLoadFactor of their peak size, releasing the memory of the keys
+// deleted.
+func ShrinkTables(store *Store) {
+	stats := &store.tableStats
+	if keys := store.store.Len(); needsShrink(keys, stats.PeakKeys) {
+		store.store = rebuildTable(store.store, NewStoreMap())
+		stats.Rebuilds++
+		stats.ReclaimedKeys += uint64(stats.PeakKeys - keys)
+		stats.PeakKeys = keys
+	}
+}
+
+// rebuildTable copies the entries of table to the empty table to and returns it.
+func rebuildTable[K comparable, V any](table, to common.ITable[K, V]) common.ITable[K, V] {
+	table.All(func(k K, v V) bool {
+		to.Put(k, v)
+		return true
+	})
+	return to
+}
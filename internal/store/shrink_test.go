@@ -0,0 +1,54 @@
+package store
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"gotest.tools/v3/assert"
+)
+
+func TestShrinkTables(t *testing.T) {
+	store := NewStore(nil)
+	for i := 0; i < 4000; i++ {
+		store.Put(strconv.Itoa(i), store.NewObj("v", 60000, object.ObjTypeString, object.ObjEncodingRaw))
+	}
+
+	stats := store.TableStats()
+	assert.Equal(t, 4000, stats.PeakKeys)
+	assert.Equal(t, 4000, stats.Expires)
+	assert.Equal(t, 0.0, stats.Fragmentation())
+
+	// a table still holding a quarter of its peak is kept
+	for i := 0; i < 3000; i++ {
+		store.Del(strconv.Itoa(i))
+	}
+	ShrinkTables(store)
+	stats = store.TableStats()
+	assert.Equal(t, uint64(0), stats.Rebuilds)
+	assert.Equal(t, 0.75, stats.Fragmentation())
+
+	store.Del("3000")
+	ShrinkTables(store)
+	stats = store.TableStats()
+	assert.Equal(t, uint64(1), stats.Rebuilds)
+	assert.Equal(t, uint64(3001), stats.ReclaimedKeys)
+	assert.Equal(t, 999, stats.Keys)
+	assert.Equal(t, 999, stats.PeakKeys)
+	assert.Equal(t, 999, stats.Expires)
+	assert.Equal(t, 0.0, stats.Fragmentation())
+
+	// the keys and their expiry survive the rebuild
+	obj := store.Get("3999")
+	assert.Assert(t, obj != nil)
+	exp, ok := GetExpiry(obj, store)
+	assert.Assert(t, ok && exp > 0)
+	assert.Equal(t, 999, store.GetKeyCount())
+
+	// the tables that never held many keys are never rebuilt
+	small := NewStore(nil)
+	small.Put("k", small.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw))
+	small.Del("k")
+	ShrinkTables(small)
+	assert.Equal(t, uint64(0), small.TableStats().Rebuilds)
+}
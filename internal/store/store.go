@@ -1,12 +1,12 @@
 package store
 
 import (
-	"path"
-
+	"github.com/google/btree"
 	"github.com/ohler55/ojg/jp"
 
 	"github.com/dicedb/dice/internal/common"
 	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/regex"
 	"github.com/dicedb/dice/internal/sql"
 	"github.com/xwb1989/sqlparser"
 
@@ -21,38 +21,86 @@ func NewStoreRegMap() common.ITable[string, *object.Obj] {
 	}
 }
 
-func NewExpireRegMap() common.ITable[*object.Obj, uint64] {
-	return &common.RegMap[*object.Obj, uint64]{
-		M: make(map[*object.Obj]uint64),
-	}
-}
-
 func NewStoreMap() common.ITable[string, *object.Obj] {
 	return NewStoreRegMap()
 }
 
-func NewExpireMap() common.ITable[*object.Obj, uint64] {
-	return NewExpireRegMap()
-}
-
 // QueryWatchEvent represents a change in a watched key.
 type QueryWatchEvent struct {
 	Key       string
 	Operation string
 	Value     object.Obj
+	Deltas    []ZSetDelta // Deltas are the changes of the members of a sorted set, for the ZDelta operation
 }
 
 type Store struct {
-	store     common.ITable[string, *object.Obj]
-	expires   common.ITable[*object.Obj, uint64] // Does not need to be thread-safe as it is only accessed by a single thread.
-	numKeys   int
-	watchChan chan QueryWatchEvent
+	store      common.ITable[string, *object.Obj]
+	numKeys    int
+	numExpires int // numExpires is the number of objects having an expiry, see object.Obj.ExpireAt
+	watchChan  chan QueryWatchEvent
+	databases  *Databases // databases are the logical databases the store is one of, nil if it stands alone
+
+	compressionStats   CompressionStats
+	pendingCompression []pendingObj
+	writes             int // writes counts the BeginWrite calls not yet ended, see BeginWrite
+
+	scanIndex          *btree.BTreeG[scanEntry] // scanIndex orders the keys by hash for SCAN, see ScanFiltered
+	scanSnapshots      map[uint32]*scanSnapshot
+	lastScanSnapshotID uint32
+	memberIndexes      map[string]*memberIndex // memberIndexes order the members of the collections by hash, see ScanMembers
+
+	views map[string]map[string]*view // views maps the keys to their views by name, see CreateView
+
+	prunePolicies map[string]*prunePolicy // prunePolicies maps the keys to their retention policy, see SetPrunePolicy
+
+	fieldExpiries map[string]*fieldExpiry // fieldExpiries maps the keys to the expiries of the fields of their hash, see SetFieldExpiry
+
+	ttlJobs      []*TTLJob // ttlJobs are the TTL jobs running and the last ones finished, see StartTTLJob
+	lastTTLJobID uint64
+
+	restoreJobs      []*RestoreJob // restoreJobs are the restore jobs running and the last ones finished, see StartRestoreJob
+	lastRestoreJobID uint64
+
+	dirtySets []*DirtySet // dirtySets collect the keys modified, see TrackDirty
+	aofDirty  *DirtySet   // aofDirty collects the keys modified since the last AOF dump, see TakeAOFDirty
+
+	watches map[string]map[*KeyWatch]struct{} // watches maps the keys to the transactions watching them, see Watch
+
+	// the clients blocked by commands such as BLPOP, see Block. They are not
+	// data, hence kept when the store is reset.
+	waiters   map[string][]*Waiter // waiters maps the keys to the clients blocked on them, in the order they blocked
+	blocked   map[*Waiter]struct{} // blocked holds all the clients blocked
+	readyKeys []string             // readyKeys are the keys modified since the blocked clients were last served
+
+	replica     bool                              // replica is true if the keys are expired by the primary, see SetReplica
+	replicating bool                              // replicating is true while applying the commands of the primary
+	restoring   bool                              // restoring is true while loading a dump, see SetRestoring
+	onExpire    func(k string)                    // onExpire is called with the keys deleted because they expired
+	onPrune     func(k string, threshold float64) // onPrune is called with the keys pruned by their retention policy
+
+	onExpireFields func(k string, obj *object.Obj, fields []string) // onExpireFields is called with the fields of hashes, sets and sorted sets deleted because they expired
+	onTTLJob       func(k string, expireAtSec int64)                // onTTLJob is called with the keys whose expiry was updated by a TTL job
+	onDefaultTTL   func(k string, expireAtMs uint64)                // onDefaultTTL is called with the keys given an expiry by a default TTL policy
+	onEvict        func(k string)                                   // onEvict is called with the keys evicted to free memory
+	followed       func() bool                                      // followed returns true while replicas or CDC subscribers follow the store, see SetFollowed
+
+	valueSize func(obj *object.Obj) int64 // valueSize estimates the memory used by the values, see MeasureValues
+
+	deltaWatches map[string]int // deltaWatches counts the watches of the deltas of the keys, see WatchDeltas
+
+	tier *ColdTier // tier is the disk tier the least recently used values are offloaded to, see EnableTier
+
+	writeBehind *writeBehind // writeBehind forwards the keys modified to a sink, see EnableWriteBehind
+
+	tableStats TableStats // tableStats tracks the peak size of the tables, see ShrinkTables
+
+	expireIndex expireIndex // expireIndex orders the keys having an expiry by deadline, see IndexExpiries
 }
 
 func NewStore(watchChan chan QueryWatchEvent) *Store {
 	return &Store{
 		store:     NewStoreRegMap(),
-		expires:   NewExpireRegMap(),
+		scanIndex: newScanIndex(),
 		watchChan: watchChan,
 	}
 }
@@ -60,7 +108,21 @@ func NewStore(watchChan chan QueryWatchEvent) *Store {
 func ResetStore(store *Store) *Store {
 	store.numKeys = 0
 	store.store = NewStoreMap()
-	store.expires = NewExpireMap()
+	store.scanIndex = newScanIndex()
+	store.numExpires = 0
+	store.expireIndex = expireIndex{}
+	store.tableStats = TableStats{Rebuilds: store.tableStats.Rebuilds, ReclaimedKeys: store.tableStats.ReclaimedKeys}
+	store.compressionStats = CompressionStats{}
+	store.pendingCompression = nil
+	store.scanSnapshots = nil
+	store.views = nil
+	store.prunePolicies = nil
+	store.fieldExpiries = nil
+	store.cancelTTLJobs()
+	store.cancelRestoreJobs()
+	store.resetTier()
+	store.MarkDirty(nil)
+	store.touchAllWatches()
 
 	return store
 }
@@ -69,7 +131,7 @@ func (store *Store) NewObj(value interface{}, expDurationMs int64, oType, oEnc u
 	obj := &object.Obj{
 		Value:          value,
 		TypeEncoding:   oType | oEnc,
-		LastAccessedAt: getCurrentClock(),
+		LastAccessedAt: newLastAccessedAt(),
 	}
 	if expDurationMs >= 0 {
 		store.SetExpiry(obj, expDurationMs)
@@ -80,11 +142,26 @@ func (store *Store) NewObj(value interface{}, expDurationMs int64, oType, oEnc u
 func (store *Store) ResetStore() {
 	store.numKeys = 0
 	store.store = NewStoreMap()
-	store.expires = NewExpireMap()
+	store.scanIndex = newScanIndex()
+	store.numExpires = 0
+	store.expireIndex = expireIndex{}
+	store.tableStats = TableStats{Rebuilds: store.tableStats.Rebuilds, ReclaimedKeys: store.tableStats.ReclaimedKeys}
+	store.compressionStats = CompressionStats{}
+	store.pendingCompression = nil
+	store.scanSnapshots = nil
+	store.views = nil
+	store.prunePolicies = nil
+	store.fieldExpiries = nil
+	store.cancelTTLJobs()
+	store.cancelRestoreJobs()
+	store.resetTier()
+	store.MarkDirty(nil)
+	store.touchAllWatches()
 }
 
 type PutOptions struct {
-	KeepTTL bool
+	KeepTTL    bool
+	Relocation bool // Relocation is true if the object is moved from another key, database or dump rather than written
 }
 
 func (store *Store) Put(k string, obj *object.Obj, opts ...PutOption) {
@@ -113,6 +190,15 @@ func WithKeepTTL(value bool) PutOption {
 	}
 }
 
+// WithRelocation tells Put the object is relocated, e.g. by RENAME, MOVE or
+// RESTORE, rather than written: it keeps the expiry it had, if any, and gets
+// none from the default TTL policies.
+func WithRelocation(value bool) PutOption {
+	return func(po *PutOptions) {
+		po.Relocation = value
+	}
+}
+
 func (store *Store) PutAll(data map[string]*object.Obj) {
 	for k, obj := range data {
 		store.putHelper(k, obj)
@@ -130,24 +216,36 @@ func (store *Store) putHelper(k string, obj *object.Obj, opts ...PutOption) {
 		optApplier(options)
 	}
 
-	if store.store.Len() >= config.DiceConfig.Server.KeysLimit {
+	if store.store.Len() >= config.Current().Server.KeysLimit {
 		store.evict()
 	}
-	obj.LastAccessedAt = getCurrentClock()
+	obj.LastAccessedAt = newLastAccessedAt()
 	currentObject, ok := store.store.Get(k)
 	if ok {
-		v, ok1 := store.expires.Get(currentObject)
-		if ok1 && options.KeepTTL && v > 0 {
-			v1, ok2 := store.expires.Get(currentObject)
-			if ok2 {
-				store.expires.Put(obj, v1)
-			}
+		// the new object gets the expiry of the current one if told to keep it,
+		// which is dropped otherwise, even when the object is put again
+		exp := currentObject.ExpireAt
+		store.clearExpiry(currentObject)
+		if options.KeepTTL && exp > 0 {
+			store.setExpireAt(obj, exp)
+		}
+		if currentObject != obj {
+			store.untrackCompressed(currentObject)
+			store.untrackCold(currentObject)
+			store.replaceFieldExpiry(k, obj)
 		}
-		store.expires.Delete(currentObject)
 	} else {
 		store.numKeys++
+		store.scanIndex.ReplaceOrInsert(scanEntry{scanHash(k), k})
+	}
+	if obj.ExpireAt == 0 && !(ok && options.KeepTTL) && !options.Relocation {
+		store.applyDefaultTTL(k, obj)
 	}
 	store.store.Put(k, obj)
+	store.indexExpiry(k, obj)
+	store.trackTablePeaks()
+	store.markForCompression(k, obj)
+	store.markDirty(k)
 
 	if store.watchChan != nil {
 		store.notifyQueryManager(k, Set, *obj)
@@ -159,11 +257,19 @@ func (store *Store) getHelper(k string, touch bool) *object.Obj {
 	var v *object.Obj
 	v, _ = store.store.Get(k)
 	if v != nil {
-		if hasExpired(v, store) {
-			store.deleteKey(k, v)
+		if store.expireIfNeeded(k, v) {
 			v = nil
-		} else if touch {
-			v.LastAccessedAt = UpdateLastAccessedAt(v.LastAccessedAt)
+		} else {
+			if touch {
+				v.LastAccessedAt = UpdateLastAccessedAt(v.LastAccessedAt)
+			}
+			if !store.faultIn(k, v) {
+				return nil
+			}
+			if store.expireFieldsIfNeeded(k, v) {
+				return nil
+			}
+			v = store.plainForRead(k, v)
 		}
 	}
 	return v
@@ -174,11 +280,15 @@ func (store *Store) GetAll(keys []string) []*object.Obj {
 	for _, k := range keys {
 		v, _ := store.store.Get(k)
 		if v != nil {
-			if hasExpired(v, store) {
-				store.deleteKey(k, v)
+			if store.expireIfNeeded(k, v) || !store.faultIn(k, v) {
 				response = append(response, nil)
 			} else {
 				v.LastAccessedAt = UpdateLastAccessedAt(v.LastAccessedAt)
+				if store.expireFieldsIfNeeded(k, v) {
+					v = nil
+				} else {
+					v = store.plainForRead(k, v)
+				}
 				response = append(response, v)
 			}
 		} else {
@@ -200,25 +310,17 @@ func (store *Store) DelByPtr(ptr string) bool {
 	return store.delByPtr(ptr)
 }
 
-func (store *Store) Keys(p string) ([]string, error) {
-	var keys []string
-	var err error
-
-	keys = make([]string, 0, store.store.Len())
-
-	store.store.All(func(k string, _ *object.Obj) bool {
-		if found, e := path.Match(p, k); e != nil {
-			err = e
-			// stop iteration if any error
-			return false
-		} else if found {
+// Keys returns the keys matching the glob-style pattern p, see regex.GlobMatch,
+// skipping the keys expired but not deleted yet.
+func (store *Store) Keys(p string) []string {
+	keys := make([]string, 0)
+	store.store.All(func(k string, v *object.Obj) bool {
+		if !hasExpired(v, store) && regex.GlobMatch(p, k) {
 			keys = append(keys, k)
 		}
-		// continue the iteration
 		return true
 	})
-
-	return keys, err
+	return keys
 }
 
 // GetDBSize returns number of keys present in the database
@@ -234,19 +336,20 @@ func (store *Store) Rename(sourceKey, destKey string) bool {
 	}
 
 	sourceObj, _ := store.store.Get(sourceKey)
-	if sourceObj == nil || hasExpired(sourceObj, store) {
-		if sourceObj != nil {
-			store.deleteKey(sourceKey, sourceObj)
-		}
+	if sourceObj == nil || store.expireIfNeeded(sourceKey, sourceObj) {
 		return false
 	}
 
 	// Use putHelper to handle putting the object at the destination key
-	store.putHelper(destKey, sourceObj)
+	store.putHelper(destKey, sourceObj, WithRelocation(true))
+	store.renameFieldExpiry(sourceKey, destKey)
 
 	// Remove the source key
 	store.store.Delete(sourceKey)
+	store.scanIndex.Delete(scanEntry{scanHash(sourceKey), sourceKey})
+	store.expireIndex.index(sourceKey, 0)
 	store.numKeys--
+	store.markDirty(sourceKey)
 
 	// Notify watchers about the deletion of the source key
 	if store.watchChan != nil {
@@ -264,11 +367,11 @@ func (store *Store) GetDel(k string) *object.Obj {
 	var v *object.Obj
 	v, _ = store.store.Get(k)
 	if v != nil {
-		expired := hasExpired(v, store)
-		store.deleteKey(k, v)
-		if expired {
-			v = nil
+		if store.expireIfNeeded(k, v) || !store.faultIn(k, v) {
+			return nil
 		}
+		store.deleteKey(k, v)
+		decompress(v)
 	}
 	return v
 }
@@ -276,21 +379,58 @@ func (store *Store) GetDel(k string) *object.Obj {
 // SetExpiry sets the expiry time for an object.
 // This method is not thread-safe. It should be called within a lock.
 func (store *Store) SetExpiry(obj *object.Obj, expDurationMs int64) {
-	store.expires.Put(obj, uint64(utils.GetCurrentTime().UnixMilli())+uint64(expDurationMs))
+	store.setExpireAt(obj, uint64(utils.GetCurrentTime().UnixMilli())+uint64(expDurationMs))
 }
 
 // SetUnixTimeExpiry sets the expiry time for an object.
 // This method is not thread-safe. It should be called within a lock.
 func (store *Store) SetUnixTimeExpiry(obj *object.Obj, exUnixTimeSec int64) {
 	// convert unix-time-seconds to unix-time-milliseconds
-	store.expires.Put(obj, uint64(exUnixTimeSec*1000))
+	store.setExpireAt(obj, uint64(exUnixTimeSec*1000))
+}
+
+// SetUnixTimeMsExpiry sets the expiry time for an object, in unix-time-milliseconds.
+// This method is not thread-safe. It should be called within a lock.
+func (store *Store) SetUnixTimeMsExpiry(obj *object.Obj, exUnixTimeMs uint64) {
+	store.setExpireAt(obj, exUnixTimeMs)
+}
+
+// Persist removes the expiry of an object, returning false if it had none.
+// This method is not thread-safe. It should be called within a lock.
+func (store *Store) Persist(obj *object.Obj) bool {
+	return store.clearExpiry(obj)
+}
+
+// setExpireAt sets the expiry of obj, in unix-time-milliseconds. The times
+// before the epoch are rounded to just after it, 0 meaning no expiry.
+func (store *Store) setExpireAt(obj *object.Obj, exp uint64) {
+	if obj.ExpireAt == 0 {
+		store.numExpires++
+	}
+	obj.ExpireAt = max(exp, 1)
+}
+
+// clearExpiry removes the expiry of obj, returning false if it had none.
+func (store *Store) clearExpiry(obj *object.Obj) bool {
+	if obj.ExpireAt == 0 {
+		return false
+	}
+	obj.ExpireAt = 0
+	store.numExpires--
+	return true
 }
 
 func (store *Store) deleteKey(k string, obj *object.Obj) bool {
 	if obj != nil {
 		store.store.Delete(k)
-		store.expires.Delete(obj)
+		store.scanIndex.Delete(scanEntry{scanHash(k), k})
+		store.clearExpiry(obj)
+		store.expireIndex.index(k, 0)
+		store.untrackCompressed(obj)
+		store.untrackCold(obj)
+		delete(store.fieldExpiries, k)
 		store.numKeys--
+		store.markDirty(k)
 
 		if store.watchChan != nil {
 			store.notifyQueryManager(k, Del, *obj)
@@ -312,7 +452,7 @@ func (store *Store) delByPtr(ptr string) bool {
 
 // notifyQueryManager notifies the query manager about a key change, so that it can update the query cache if needed.
 func (store *Store) notifyQueryManager(k, operation string, obj object.Obj) {
-	store.watchChan <- QueryWatchEvent{k, operation, obj}
+	store.watchChan <- QueryWatchEvent{k, operation, obj, nil}
 }
 
 func (store *Store) GetStore() common.ITable[string, *object.Obj] {
@@ -330,6 +470,7 @@ func (store *Store) CacheKeysForQuery(whereClause sqlparser.Expr, cacheChannel c
 		Value *object.Obj
 	}, 0)
 	store.store.All(func(k string, v *object.Obj) bool {
+		v = PlainObj(v)
 		matches, err := sql.EvaluateWhereClause(whereClause, sql.QueryResultRow{Key: k, Value: *v}, make(map[string]jp.Expr))
 		if err != nil || !matches {
 			return true
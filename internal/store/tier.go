@@ -0,0 +1,306 @@
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// When tiered storage is enabled, the store keeps at most
+// config.DiceConfig.Server.TierHotKeys values in memory: the least recently
+// used values beyond it are offloaded to a file on disk, the cold tier, by
+// OffloadColdKeys. An offloaded object stays in the keyspace, with its expiry
+// and access time, flagged with the ObjEncodingCold encoding and holding a
+// *coldValue. It is faulted back in place when read from the store, so that
+// the evals only ever see plain values.
+//
+// The tier is an append only file, the records of the values faulted back in
+// being reclaimed once they make up most of it. It is not a persistence layer:
+// the file is truncated when the tier is opened.
+
+// tierCompactMinGarbage is the size of the dead records below which the tier
+// file is never compacted.
+const tierCompactMinGarbage = 1 << 20
+
+// TierCodec converts the objects to and from the bytes stored in the cold
+// tier. Encode returns nil for the objects it cannot encode, which are kept in
+// memory. The expiry and the access time of the objects are not encoded.
+type TierCodec struct {
+	Encode func(obj *object.Obj) ([]byte, error)
+	Decode func(data []byte) (*object.Obj, error)
+}
+
+// TierStats describes the cold tier and how the reads are served.
+type TierStats struct {
+	ColdKeys  int    // number of keys whose value is offloaded
+	ColdBytes int64  // size of the values offloaded
+	FileBytes int64  // size of the tier file, dead records included
+	Hits      uint64 // reads served from memory
+	Misses    uint64 // reads that had to fault the value back in from disk
+	Offloaded uint64 // values offloaded since the tier was opened
+}
+
+// HitRate returns the ratio of the reads served from memory, or 0 if nothing
+// was read.
+func (s TierStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// ColdTier is the disk tier the values of a store are offloaded to.
+type ColdTier struct {
+	path    string
+	file    *os.File
+	codec   TierCodec
+	garbage int64 // size of the records no longer referenced
+	stats   TierStats
+}
+
+// coldValue locates the record of an offloaded value in the tier file.
+type coldValue struct {
+	tier   *ColdTier
+	offset int64
+	size   int
+}
+
+// NewColdTier opens the file of a cold tier at path, truncating it.
+func NewColdTier(path string, codec TierCodec) (*ColdTier, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &ColdTier{path: path, file: file, codec: codec}, nil
+}
+
+// Close closes and removes the tier file.
+func (tier *ColdTier) Close() error {
+	if err := tier.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(tier.path)
+}
+
+// EnableTier offloads the values of the store to the tier from now on.
+func (store *Store) EnableTier(tier *ColdTier) {
+	store.tier = tier
+}
+
+// TierStats returns the statistics about the cold tier of the store, and false
+// if tiered storage is disabled.
+func (store *Store) TierStats() (TierStats, bool) {
+	if store.tier == nil {
+		return TierStats{}, false
+	}
+	return store.tier.stats, true
+}
+
+func isCold(obj *object.Obj) bool {
+	_, enc := object.ExtractTypeEncoding(obj)
+	return enc == object.ObjEncodingCold
+}
+
+// OffloadColdKeys offloads the least recently used values to the cold tier,
+// till no more than config.DiceConfig.Server.TierHotKeys values are kept in
+// memory. The values that cannot be encoded are kept in memory.
+func OffloadColdKeys(store *Store) {
+	tier := store.tier
+	if tier == nil {
+		return
+	}
+
+	excess := store.numKeys - tier.stats.ColdKeys - config.DiceConfig.Server.TierHotKeys
+	if excess > 0 {
+		type candidate struct {
+			key  string
+			obj  *object.Obj
+			idle uint32
+		}
+		var candidates []candidate
+		store.store.All(func(k string, obj *object.Obj) bool {
+			if !isCold(obj) && !hasExpired(obj, store) {
+				candidates = append(candidates, candidate{k, obj, GetIdleTime(obj.LastAccessedAt)})
+			}
+			return true
+		})
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].idle > candidates[j].idle })
+
+		for _, c := range candidates[:min(excess, len(candidates))] {
+			if err := store.offload(c.key, c.obj); err != nil {
+				slog.Error("could not offload value", slog.String("key", c.key), slog.Any("error", err))
+				return
+			}
+		}
+	}
+
+	if tier.garbage >= tierCompactMinGarbage && 2*tier.garbage > tier.stats.FileBytes {
+		if err := store.compactTier(); err != nil {
+			slog.Error("could not compact the cold tier", slog.Any("error", err))
+		}
+	}
+}
+
+// offload writes the value of obj to the tier, replacing it in place by its
+// location in the tier file.
+func (store *Store) offload(k string, obj *object.Obj) error {
+	tier := store.tier
+	plain := PlainObj(obj)
+	data, err := tier.codec.Encode(plain)
+	if err != nil || data == nil {
+		return err
+	}
+
+	offset := tier.stats.FileBytes
+	if _, err := tier.file.WriteAt(data, offset); err != nil {
+		return err
+	}
+	tier.stats.FileBytes += int64(len(data))
+
+	store.untrackCompressed(obj)
+	oType, _ := object.ExtractTypeEncoding(plain)
+	obj.Value = &coldValue{tier: tier, offset: offset, size: len(data)}
+	obj.TypeEncoding = oType | object.ObjEncodingCold
+
+	tier.stats.ColdKeys++
+	tier.stats.ColdBytes += int64(len(data))
+	tier.stats.Offloaded++
+	return nil
+}
+
+// read returns the plain object decoded from the record of a cold object.
+func (cv *coldValue) read() (*object.Obj, error) {
+	data := make([]byte, cv.size)
+	if _, err := cv.tier.file.ReadAt(data, cv.offset); err != nil {
+		return nil, err
+	}
+
+	obj, err := cv.tier.codec.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("no value decoded from %d bytes", cv.size)
+	}
+	return PlainObj(obj), nil
+}
+
+// faultIn restores in place the value of obj if it is offloaded, and counts
+// the read. It returns false if the value could not be read back, in which
+// case the key is deleted.
+func (store *Store) faultIn(k string, obj *object.Obj) bool {
+	tier := store.tier
+	if !isCold(obj) {
+		if tier != nil {
+			tier.stats.Hits++
+		}
+		return true
+	}
+
+	tier.stats.Misses++
+	plain, err := obj.Value.(*coldValue).read()
+	if err != nil {
+		slog.Error("could not read offloaded value", slog.String("key", k), slog.Any("error", err))
+		store.deleteKey(k, obj)
+		return false
+	}
+
+	store.untrackCold(obj)
+	obj.Value = plain.Value
+	obj.TypeEncoding = plain.TypeEncoding
+	store.markForCompression(k, obj)
+	return true
+}
+
+// coldPlainObj returns a copy of the cold object obj with its value read back
+// from the tier, or obj itself if it cannot be read.
+func coldPlainObj(obj *object.Obj) *object.Obj {
+	plain, err := obj.Value.(*coldValue).read()
+	if err != nil {
+		slog.Error("could not read offloaded value", slog.Any("error", err))
+		return obj
+	}
+
+	cp := *obj
+	cp.Value = plain.Value
+	cp.TypeEncoding = plain.TypeEncoding
+	return &cp
+}
+
+// untrackCold updates the statistics when a cold object is faulted back in or
+// leaves the store, its record becoming dead.
+func (store *Store) untrackCold(obj *object.Obj) {
+	if !isCold(obj) {
+		return
+	}
+
+	cv := obj.Value.(*coldValue)
+	cv.tier.stats.ColdKeys--
+	cv.tier.stats.ColdBytes -= int64(cv.size)
+	cv.tier.garbage += int64(cv.size)
+}
+
+// compactTier rewrites the tier file with the records still referenced.
+func (store *Store) compactTier() error {
+	tier := store.tier
+	tmpPath := tier.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	moved := make(map[*coldValue]int64)
+	store.store.All(func(k string, obj *object.Obj) bool {
+		if !isCold(obj) {
+			return true
+		}
+		cv := obj.Value.(*coldValue)
+		data := make([]byte, cv.size)
+		if _, err = tier.file.ReadAt(data, cv.offset); err != nil {
+			return false
+		}
+		if _, err = tmp.WriteAt(data, offset); err != nil {
+			return false
+		}
+		moved[cv] = offset
+		offset += int64(cv.size)
+		return true
+	})
+	if err == nil {
+		err = os.Rename(tmpPath, tier.path)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	for cv, newOffset := range moved {
+		cv.offset = newOffset
+	}
+	tier.file.Close()
+	tier.file = tmp
+	tier.garbage = 0
+	tier.stats.FileBytes = offset
+	return nil
+}
+
+// resetTier drops all the records of the tier, once the store is reset.
+func (store *Store) resetTier() {
+	tier := store.tier
+	if tier == nil {
+		return
+	}
+	if err := tier.file.Truncate(0); err != nil {
+		slog.Error("could not truncate the cold tier", slog.Any("error", err))
+	}
+	tier.garbage = 0
+	tier.stats.ColdKeys = 0
+	tier.stats.ColdBytes = 0
+	tier.stats.FileBytes = 0
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"gotest.tools/v3/assert"
+)
+
+// stringCodec encodes the raw strings only.
+var stringCodec = TierCodec{
+	Encode: func(obj *object.Obj) ([]byte, error) {
+		if obj.TypeEncoding != object.ObjTypeString|object.ObjEncodingRaw {
+			return nil, nil
+		}
+		return []byte(obj.Value.(string)), nil
+	},
+	Decode: func(data []byte) (*object.Obj, error) {
+		return &object.Obj{Value: string(data), TypeEncoding: object.ObjTypeString | object.ObjEncodingRaw}, nil
+	},
+}
+
+func newTieredStore(t *testing.T) (*Store, *ColdTier) {
+	tier, err := NewColdTier(filepath.Join(t.TempDir(), "tier.dat"), stringCodec)
+	assert.NilError(t, err)
+	t.Cleanup(func() { tier.Close() })
+
+	store := NewStore(nil)
+	store.EnableTier(tier)
+	return store, tier
+}
+
+func TestOffloadColdKeys(t *testing.T) {
+	store, _ := newTieredStore(t)
+	for i, k := range []string{"a", "b", "c", "d"} {
+		obj := store.NewObj(strings.Repeat(k, 10), -1, object.ObjTypeString, object.ObjEncodingRaw)
+		store.Put(k, obj)
+		obj.LastAccessedAt = getCurrentClock() - uint32(10-i)
+	}
+	store.Put("n", store.NewObj(int64(1), -1, object.ObjTypeInt, object.ObjEncodingInt))
+
+	defer func(n int) { config.DiceConfig.Server.TierHotKeys = n }(config.DiceConfig.Server.TierHotKeys)
+	config.DiceConfig.Server.TierHotKeys = 2
+	OffloadColdKeys(store)
+
+	// the least recently used values are offloaded, but the ones the codec
+	// cannot encode
+	a, _ := store.store.Get("a")
+	b, _ := store.store.Get("b")
+	c, _ := store.store.Get("c")
+	n, _ := store.store.Get("n")
+	assert.Assert(t, isCold(a))
+	assert.Assert(t, isCold(b))
+	assert.Assert(t, isCold(c))
+	assert.Assert(t, !isCold(n))
+	stats, _ := store.TierStats()
+	assert.Equal(t, 3, stats.ColdKeys)
+	assert.Equal(t, int64(30), stats.ColdBytes)
+	assert.Equal(t, uint64(3), stats.Offloaded)
+
+	// the type of the cold values is kept, and the raw iterations read them back
+	assert.NilError(t, object.AssertType(a.TypeEncoding, object.ObjTypeString))
+	assert.Equal(t, "aaaaaaaaaa", PlainObj(a).Value)
+	assert.Assert(t, isCold(a))
+
+	// reads fault the values back in place
+	assert.Equal(t, a, store.Get("a"))
+	assert.Equal(t, "aaaaaaaaaa", a.Value)
+	assert.Equal(t, object.ObjTypeString|object.ObjEncodingRaw, a.TypeEncoding)
+	assert.Equal(t, "dddddddddd", store.Get("d").Value)
+	objs := store.GetAll([]string{"b", "x"})
+	assert.Equal(t, "bbbbbbbbbb", objs[0].Value)
+	assert.Assert(t, objs[1] == nil)
+
+	stats, _ = store.TierStats()
+	assert.Equal(t, 1, stats.ColdKeys)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, 1.0/3, stats.HitRate())
+
+	// overwriting or deleting a cold key drops its record
+	store.Del("c")
+	stats, _ = store.TierStats()
+	assert.Equal(t, 0, stats.ColdKeys)
+	assert.Equal(t, int64(0), stats.ColdBytes)
+	assert.Equal(t, int64(30), stats.FileBytes)
+}
+
+func TestColdTierCompaction(t *testing.T) {
+	store, tier := newTieredStore(t)
+	defer func(n int) { config.DiceConfig.Server.TierHotKeys = n }(config.DiceConfig.Server.TierHotKeys)
+	config.DiceConfig.Server.TierHotKeys = 0
+
+	value := func(k string) string { return strings.Repeat(k, tierCompactMinGarbage/2) }
+	for _, k := range []string{"a", "b", "c", "d"} {
+		store.Put(k, store.NewObj(value(k), -1, object.ObjTypeString, object.ObjEncodingRaw))
+	}
+	OffloadColdKeys(store)
+	stats, _ := store.TierStats()
+	assert.Equal(t, 4, stats.ColdKeys)
+	assert.Equal(t, int64(4*tierCompactMinGarbage/2), stats.FileBytes)
+
+	// the tier is compacted once most of the file is made of dead records
+	store.Del("a")
+	store.GetDel("b")
+	OffloadColdKeys(store)
+	stats, _ = store.TierStats()
+	assert.Equal(t, int64(4*tierCompactMinGarbage/2), stats.FileBytes)
+
+	store.Del("c")
+	OffloadColdKeys(store)
+	stats, _ = store.TierStats()
+	assert.Equal(t, 1, stats.ColdKeys)
+	assert.Equal(t, int64(tierCompactMinGarbage/2), stats.FileBytes)
+	assert.Equal(t, int64(0), tier.garbage)
+	assert.Equal(t, value("d"), store.Get("d").Value)
+
+	store.ResetStore()
+	stats, _ = store.TierStats()
+	assert.Equal(t, TierStats{Hits: stats.Hits, Misses: stats.Misses, Offloaded: 4}, stats)
+}
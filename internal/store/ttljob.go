@@ -0,0 +1,220 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/regex"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// A TTL job sets or removes the expiry of all the keys matching a pattern, e.g.
+// for an operational cleanup, without blocking the shard for the whole
+// keyspace: RunTTLJobs, run by the cron of the shards, updates the keys in
+// batches, at most Rate keys per second. The keys matching the pattern are
+// captured when the job starts, like a SCAN iteration in snapshot mode: the
+// keys deleted in the meantime are skipped and the keys added afterwards are
+// left untouched.
+//
+// A replica never runs jobs, the primary propagates the expiries its jobs set
+// instead.
+
+const (
+	// PersistTTL is the time to live of the jobs removing the expiry of the keys.
+	PersistTTL int64 = -1
+
+	// DefaultTTLJobBatch and DefaultTTLJobRate are the batch size and the rate
+	// of the jobs started without one.
+	DefaultTTLJobBatch = 1000
+	DefaultTTLJobRate  = 1000
+
+	// maxFinishedTTLJobs is the number of finished jobs kept for their status,
+	// the oldest one being dropped first.
+	maxFinishedTTLJobs = 16
+)
+
+// TTLJobState is the state of a TTL job.
+type TTLJobState string
+
+const (
+	TTLJobRunning  TTLJobState = "running"
+	TTLJobDone     TTLJobState = "done"
+	TTLJobCanceled TTLJobState = "canceled"
+)
+
+// TTLJob is the status of a TTL job, as returned by TTLJobs.
+type TTLJob struct {
+	ID        uint64
+	Pattern   string
+	TTLSec    int64 // TTLSec is the time to live set to the keys in seconds, PersistTTL to remove their expiry
+	Batch     int   // Batch is the maximum number of keys updated by a run of the cron
+	Rate      int   // Rate is the maximum number of keys updated per second
+	Total     int   // Total is the number of keys matching the pattern when the job started
+	Processed int   // Processed is the number of keys processed so far, the ones deleted since the start included
+	Updated   int   // Updated is the number of keys whose expiry was set or removed
+	State     TTLJobState
+	StartedAt time.Time
+
+	keys    []string
+	tokens  float64   // tokens is the number of keys the job may update before exceeding its rate
+	lastRun time.Time // lastRun is when the tokens were last replenished
+}
+
+// StartTTLJob starts a job setting the time to live of the keys matching the
+// glob pattern to ttlSec seconds, or removing their expiry if ttlSec is
+// PersistTTL. It returns the id of the job.
+func (store *Store) StartTTLJob(pattern string, ttlSec int64, batch, rate int) uint64 {
+	var keys []string
+	store.store.All(func(k string, _ *object.Obj) bool {
+		if regex.GlobMatch(pattern, k) {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	sort.Strings(keys)
+
+	now := utils.GetCurrentTime()
+	store.lastTTLJobID++
+	store.ttlJobs = append(store.ttlJobs, &TTLJob{
+		ID:        store.lastTTLJobID,
+		Pattern:   pattern,
+		TTLSec:    ttlSec,
+		Batch:     batch,
+		Rate:      rate,
+		Total:     len(keys),
+		State:     TTLJobRunning,
+		StartedAt: now,
+		keys:      keys,
+		lastRun:   now,
+	})
+	store.dropFinishedTTLJobs()
+	return store.lastTTLJobID
+}
+
+// TTLJobs returns the status of the jobs running and of the last ones finished,
+// in the order they started.
+func (store *Store) TTLJobs() []TTLJob {
+	jobs := make([]TTLJob, len(store.ttlJobs))
+	for i, job := range store.ttlJobs {
+		jobs[i] = *job
+		jobs[i].keys = nil
+	}
+	return jobs
+}
+
+// CancelTTLJob stops the job id, the keys already updated keeping their new
+// expiry. It returns false if the job is unknown or no longer running.
+func (store *Store) CancelTTLJob(id uint64) bool {
+	for _, job := range store.ttlJobs {
+		if job.ID == id && job.State == TTLJobRunning {
+			store.finishTTLJob(job, TTLJobCanceled)
+			store.dropFinishedTTLJobs()
+			return true
+		}
+	}
+	return false
+}
+
+// OnTTLJob sets the function called with the keys updated by the TTL jobs along
+// with their expiry in unix-time-seconds, PersistTTL if it was removed, e.g. to
+// propagate the expiries to the replicas.
+func (store *Store) OnTTLJob(f func(k string, expireAtSec int64)) {
+	store.onTTLJob = f
+}
+
+// cancelTTLJobs stops the running jobs, e.g. once the keys they captured are
+// flushed.
+func (store *Store) cancelTTLJobs() {
+	for _, job := range store.ttlJobs {
+		if job.State == TTLJobRunning {
+			store.finishTTLJob(job, TTLJobCanceled)
+		}
+	}
+	store.dropFinishedTTLJobs()
+}
+
+func (store *Store) finishTTLJob(job *TTLJob, state TTLJobState) {
+	job.State = state
+	job.keys = nil
+}
+
+// dropFinishedTTLJobs forgets the oldest finished jobs beyond maxFinishedTTLJobs.
+func (store *Store) dropFinishedTTLJobs() {
+	finished := 0
+	for _, job := range store.ttlJobs {
+		if job.State != TTLJobRunning {
+			finished++
+		}
+	}
+
+	jobs := store.ttlJobs[:0]
+	for _, job := range store.ttlJobs {
+		if job.State != TTLJobRunning && finished > maxFinishedTTLJobs {
+			finished--
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	store.ttlJobs = jobs
+}
+
+// runTTLJob updates the next batch of keys of job, as many as its rate allows.
+func (store *Store) runTTLJob(job *TTLJob, now time.Time) {
+	job.tokens = min(job.tokens+now.Sub(job.lastRun).Seconds()*float64(job.Rate), float64(job.Rate))
+	job.lastRun = now
+
+	n := min(job.Batch, int(job.tokens), len(job.keys)-job.Processed)
+	job.tokens -= float64(n)
+	for _, k := range job.keys[job.Processed : job.Processed+n] {
+		if store.applyTTLJob(job, k, now) {
+			job.Updated++
+		}
+	}
+	job.Processed += n
+
+	if job.Processed == len(job.keys) {
+		store.finishTTLJob(job, TTLJobDone)
+	}
+}
+
+// applyTTLJob sets the expiry of the key k as requested by job. It returns false
+// if the key no longer exists or has no expiry to remove.
+func (store *Store) applyTTLJob(job *TTLJob, k string, now time.Time) bool {
+	obj, ok := store.store.Get(k)
+	if !ok || hasExpired(obj, store) {
+		return false
+	}
+
+	expireAtSec := PersistTTL
+	if job.TTLSec == PersistTTL {
+		if !store.Persist(obj) {
+			return false
+		}
+	} else {
+		expireAtSec = now.Unix() + job.TTLSec
+		store.SetUnixTimeExpiry(obj, expireAtSec)
+	}
+
+	store.indexExpiry(k, obj)
+	store.markDirty(k)
+	if store.onTTLJob != nil {
+		store.onTTLJob(k, expireAtSec)
+	}
+	return true
+}
+
+// RunTTLJobs runs the next batch of the TTL jobs running.
+func RunTTLJobs(store *Store) {
+	if store.replica || len(store.ttlJobs) == 0 {
+		return
+	}
+
+	now := utils.GetCurrentTime()
+	for _, job := range store.ttlJobs {
+		if job.State == TTLJobRunning {
+			store.runTTLJob(job, now)
+		}
+	}
+	store.dropFinishedTTLJobs()
+}
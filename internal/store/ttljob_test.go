@@ -0,0 +1,101 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"gotest.tools/v3/assert"
+)
+
+func TestTTLJob(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	store := NewStore(nil)
+	updated := make(map[string]int64)
+	store.OnTTLJob(func(k string, expireAtSec int64) {
+		updated[k] = expireAtSec
+	})
+	for i := 0; i < 10; i++ {
+		store.Put(fmt.Sprintf("session:%d", i), store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+	}
+	store.Put("user:1", store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+	tick := func(d time.Duration) {
+		mockTime.SetTime(mockTime.CurrTime.Add(d))
+		RunTTLJobs(store)
+	}
+	job := func(id uint64) TTLJob {
+		for _, job := range store.TTLJobs() {
+			if job.ID == id {
+				return job
+			}
+		}
+		t.Fatalf("no job %d", id)
+		return TTLJob{}
+	}
+
+	// the job updates 4 keys per second, by batches of 3 keys at most
+	id := store.StartTTLJob("session:*", 60, 3, 4)
+	assert.Equal(t, 10, job(id).Total)
+	store.Del("session:1")
+	tick(time.Second)
+	assert.Equal(t, 3, job(id).Processed)
+	assert.Equal(t, 2, job(id).Updated)
+	tick(500 * time.Millisecond)
+	assert.Equal(t, 6, job(id).Processed)
+	tick(100 * time.Millisecond)
+	assert.Equal(t, 6, job(id).Processed)
+	tick(10 * time.Second)
+	tick(10 * time.Second)
+	assert.Equal(t, TTLJobDone, job(id).State)
+	assert.Equal(t, 9, job(id).Updated)
+	assert.Equal(t, 9, len(updated))
+	assert.Equal(t, int64(1001+60), updated["session:0"])
+	exp, ok := GetExpiry(store.Get("session:9"), store)
+	assert.Assert(t, ok)
+	assert.Equal(t, uint64(1021+60)*1000, exp)
+	_, ok = GetExpiry(store.Get("user:1"), store)
+	assert.Assert(t, !ok)
+
+	// PERSIST removes the expiries, the keys without one being left as is
+	updated = make(map[string]int64)
+	id = store.StartTTLJob("*", PersistTTL, 100, 100)
+	tick(time.Second)
+	assert.Equal(t, TTLJobDone, job(id).State)
+	assert.Equal(t, 10, job(id).Processed)
+	assert.Equal(t, 9, job(id).Updated)
+	assert.Equal(t, PersistTTL, updated["session:9"])
+	_, ok = GetExpiry(store.Get("session:9"), store)
+	assert.Assert(t, !ok)
+
+	// a canceled job stops, and so do the jobs running when the store is reset
+	id = store.StartTTLJob("*", 60, 1, 1)
+	tick(time.Second)
+	assert.Assert(t, store.CancelTTLJob(id))
+	assert.Assert(t, !store.CancelTTLJob(id))
+	tick(time.Second)
+	assert.Equal(t, TTLJobCanceled, job(id).State)
+	assert.Equal(t, 1, job(id).Processed)
+	id = store.StartTTLJob("*", 60, 1, 1)
+	store.ResetStore()
+	assert.Equal(t, TTLJobCanceled, job(id).State)
+
+	// a replica waits for the primary to propagate the expiries
+	store.SetReplica(true)
+	store.Put("r", store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+	id = store.StartTTLJob("*", 60, 1, 1)
+	tick(time.Second)
+	assert.Equal(t, 0, job(id).Processed)
+	store.SetReplica(false)
+
+	// only the last finished jobs are kept
+	for i := 0; i < maxFinishedTTLJobs; i++ {
+		store.CancelTTLJob(store.StartTTLJob("*", 60, 1, 1))
+	}
+	assert.Equal(t, maxFinishedTTLJobs+1, len(store.TTLJobs()))
+	assert.Equal(t, id, store.TTLJobs()[0].ID)
+}
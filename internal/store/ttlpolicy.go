@@ -0,0 +1,182 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/regex"
+)
+
+// The default TTL policies give an expiry to the keys put without one, by the
+// pattern of their name, e.g. session:* expiring after 30 minutes. They apply
+// whatever the command putting the key, e.g. SET, HSET, LPUSH or INCR, to the
+// keys created and to the keys replaced without keeping their expiry, e.g. by
+// SET without KEEPTTL. The first policy whose pattern matches the key wins.
+//
+// The policies are read from config.DiceConfig.Server.DefaultTTLs at startup
+// and managed with CONFIG DEFAULTTTL afterwards. Changing them leaves the keys
+// already put alone.
+//
+// A replica never applies the policies, the primary propagates the expiries
+// they set instead.
+
+// TTLPolicy gives the keys matching Pattern an expiry TTL after they are put.
+type TTLPolicy struct {
+	Pattern string
+	TTL     time.Duration
+	applied atomic.Int64 // applied is the number of keys the policy gave an expiry to
+}
+
+// Applied returns the number of keys the policy gave an expiry to.
+func (p *TTLPolicy) Applied() int64 {
+	return p.applied.Load()
+}
+
+// ParseTTLPolicy parses a policy written as pattern=ttl, e.g. session:*=30m,
+// the TTL being a positive duration.
+func ParseTTLPolicy(s string) (*TTLPolicy, error) {
+	i := strings.LastIndexByte(s, '=')
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid default TTL policy %q, expected pattern=ttl", s)
+	}
+	ttl, err := ParseTTL(s[i+1:])
+	if err != nil {
+		return nil, err
+	}
+	return &TTLPolicy{Pattern: s[:i], TTL: ttl}, nil
+}
+
+// ParseTTL parses the TTL of a policy, a positive duration such as 30m.
+func ParseTTL(s string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(s)
+	if err != nil || ttl < time.Millisecond {
+		return 0, fmt.Errorf("invalid default TTL %q, expected a duration of 1ms at least", s)
+	}
+	return ttl, nil
+}
+
+// TTLPolicies are the default TTL policies, in the order they are matched.
+// They are shared by the stores of all the shards: the policies are replaced
+// as a whole on every update, so that the stores match the keys without a lock.
+type TTLPolicies struct {
+	mu       sync.Mutex // mu serializes the updates
+	policies atomic.Pointer[[]*TTLPolicy]
+}
+
+// DefaultTTLs are the default TTL policies of the server.
+var DefaultTTLs = &TTLPolicies{}
+
+// Load replaces the policies by the ones written in entries, see
+// ParseTTLPolicy. The policies are left untouched if one of them is invalid.
+func (p *TTLPolicies) Load(entries []string) error {
+	policies := make([]*TTLPolicy, 0, len(entries))
+	for _, entry := range entries {
+		policy, err := ParseTTLPolicy(entry)
+		if err != nil {
+			return err
+		}
+		policies = append(policies, policy)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policies.Store(&policies)
+	return nil
+}
+
+// Set sets the TTL of the policy of pattern, which is matched after the others
+// if it is a new one.
+func (p *TTLPolicies) Set(pattern string, ttl time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := p.List()
+	policies := make([]*TTLPolicy, 0, len(current)+1)
+	replaced := false
+	for _, policy := range current {
+		if policy.Pattern == pattern {
+			policy = &TTLPolicy{Pattern: pattern, TTL: ttl}
+			replaced = true
+		}
+		policies = append(policies, policy)
+	}
+	if !replaced {
+		policies = append(policies, &TTLPolicy{Pattern: pattern, TTL: ttl})
+	}
+	p.policies.Store(&policies)
+}
+
+// Del removes the policy of pattern. It returns false if there is no such
+// policy.
+func (p *TTLPolicies) Del(pattern string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := p.List()
+	policies := make([]*TTLPolicy, 0, len(current))
+	for _, policy := range current {
+		if policy.Pattern != pattern {
+			policies = append(policies, policy)
+		}
+	}
+	if len(policies) == len(current) {
+		return false
+	}
+	p.policies.Store(&policies)
+	return true
+}
+
+// List returns the policies, in the order they are matched. The slice must not
+// be modified.
+func (p *TTLPolicies) List() []*TTLPolicy {
+	if policies := p.policies.Load(); policies != nil {
+		return *policies
+	}
+	return nil
+}
+
+// match returns the first policy whose pattern matches k, nil if none does.
+func (p *TTLPolicies) match(k string) *TTLPolicy {
+	for _, policy := range p.List() {
+		if regex.GlobMatch(policy.Pattern, k) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// applyDefaultTTL gives obj, put at k without an expiry, the expiry of the
+// default TTL policy matching k, if any. The keys restored get none, the dump
+// holding their expiry.
+func (store *Store) applyDefaultTTL(k string, obj *object.Obj) {
+	if store.replica || store.replicating || store.restoring {
+		return
+	}
+	policy := DefaultTTLs.match(k)
+	if policy == nil {
+		return
+	}
+
+	store.SetExpiry(obj, policy.TTL.Milliseconds())
+	policy.applied.Add(1)
+	if store.onDefaultTTL != nil {
+		store.onDefaultTTL(k, obj.ExpireAt)
+	}
+}
+
+// SetRestoring is set to true while loading a dump, e.g. a snapshot, through
+// the commands recreating its keys, for them to get no default TTL.
+func (store *Store) SetRestoring(restoring bool) {
+	store.restoring = restoring
+}
+
+// OnDefaultTTL sets the function called with the keys given an expiry by a
+// default TTL policy along with their expiry in unix-time-milliseconds, e.g. to
+// propagate the expiries to the replicas.
+func (store *Store) OnDefaultTTL(f func(k string, expireAtMs uint64)) {
+	store.onDefaultTTL = f
+}
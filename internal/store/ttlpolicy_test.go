@@ -0,0 +1,84 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"gotest.tools/v3/assert"
+)
+
+func TestDefaultTTLs(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Unix(1000, 0)}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+	defer func(policies []*TTLPolicy) { DefaultTTLs.policies.Store(&policies) }(DefaultTTLs.List())
+
+	assert.NilError(t, DefaultTTLs.Load([]string{"session:*=30m", "s*=1s", "tmp:a=b=10s"}))
+	assert.Equal(t, len(DefaultTTLs.List()), 3)
+	assert.Equal(t, DefaultTTLs.List()[2].Pattern, "tmp:a=b")
+	assert.ErrorContains(t, DefaultTTLs.Load([]string{"session:*=30m", "user:*"}), "expected pattern=ttl")
+	assert.ErrorContains(t, DefaultTTLs.Load([]string{"user:*=0s"}), "1ms at least")
+	assert.Equal(t, len(DefaultTTLs.List()), 3)
+
+	store := NewStore(nil)
+	var applied []string
+	store.OnDefaultTTL(func(k string, expireAtMs uint64) {
+		applied = append(applied, k)
+	})
+	newObj := func(expDurationMs int64) *object.Obj {
+		return store.NewObj("v", expDurationMs, object.ObjTypeString, object.ObjEncodingEmbStr)
+	}
+
+	// the first policy matching the key wins, the keys put with an expiry keep it
+	store.Put("session:1", newObj(-1))
+	store.Put("sx", newObj(-1))
+	store.Put("session:2", newObj(5000))
+	store.Put("user:1", newObj(-1))
+	assert.Equal(t, store.Get("session:1").ExpireAt, uint64(1000000+30*60*1000))
+	assert.Equal(t, store.Get("sx").ExpireAt, uint64(1001000))
+	assert.Equal(t, store.Get("session:2").ExpireAt, uint64(1005000))
+	assert.Equal(t, store.Get("user:1").ExpireAt, uint64(0))
+	assert.DeepEqual(t, applied, []string{"session:1", "sx"})
+	assert.Equal(t, DefaultTTLs.List()[0].Applied(), int64(1))
+
+	// a key replaced gets the default TTL again, unless its TTL is kept
+	mockTime.SetTime(time.Unix(1004, 0))
+	store.Put("session:1", newObj(-1))
+	assert.Equal(t, store.Get("session:1").ExpireAt, uint64(1004000+30*60*1000))
+	store.Put("session:2", newObj(-1), WithKeepTTL(true))
+	assert.Equal(t, store.Get("session:2").ExpireAt, uint64(1005000))
+	store.Persist(store.Get("session:2"))
+	store.Put("session:2", newObj(-1), WithKeepTTL(true))
+	assert.Equal(t, store.Get("session:2").ExpireAt, uint64(0))
+
+	// the policies updated apply to the keys put afterwards
+	DefaultTTLs.Set("user:*", time.Minute)
+	DefaultTTLs.Set("session:*", time.Hour)
+	assert.Equal(t, DefaultTTLs.List()[0].TTL, time.Hour)
+	assert.Equal(t, DefaultTTLs.List()[3].Pattern, "user:*")
+	assert.Equal(t, store.Get("user:1").ExpireAt, uint64(0))
+	store.Put("user:2", newObj(-1))
+	assert.Equal(t, store.Get("user:2").ExpireAt, uint64(1064000))
+	assert.Assert(t, DefaultTTLs.Del("user:*"))
+	assert.Assert(t, !DefaultTTLs.Del("user:*"))
+	store.Put("user:3", newObj(-1))
+	assert.Equal(t, store.Get("user:3").ExpireAt, uint64(0))
+
+	// the keys relocated or restored keep the expiry they had, if any
+	store.Put("user:4", newObj(-1))
+	assert.Assert(t, store.Rename("user:4", "session:4"))
+	assert.Equal(t, store.Get("session:4").ExpireAt, uint64(0))
+	store.Put("session:5", newObj(-1), WithRelocation(true))
+	assert.Equal(t, store.Get("session:5").ExpireAt, uint64(0))
+	store.SetRestoring(true)
+	store.Put("session:6", newObj(-1))
+	store.SetRestoring(false)
+	assert.Equal(t, store.Get("session:6").ExpireAt, uint64(0))
+
+	// the replicas get the expiries from the primary
+	store.SetReplica(true)
+	store.Put("session:3", newObj(-1))
+	assert.Equal(t, store.Get("session:3").ExpireAt, uint64(0))
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"github.com/dicedb/dice/internal/object"
+)
+
+// Views are read-only materialized views of keys, such as the top members of
+// a sorted set or the cardinality of a set. The result of a view is kept until
+// its key is written, so that serving it repeatedly costs nothing however
+// expensive computing it is.
+//
+// A view is stale once the object of its key is replaced or deleted, or once a
+// command modifying its key in place marks it with MarkViewsStale. A stale view
+// is computed again the next time it is read.
+
+// ViewFunc computes the result of a view from the object of its key, nil if
+// the key does not exist. The result must not be modified once returned.
+type ViewFunc func(obj *object.Obj) []byte
+
+type view struct {
+	compute ViewFunc
+	result  []byte
+	obj     *object.Obj // obj is the object the result was computed from
+	stale   bool
+}
+
+// CreateView adds the view name of key, computed with compute. It returns false
+// if key already has a view with this name.
+func (store *Store) CreateView(key, name string, compute ViewFunc) bool {
+	if _, ok := store.views[key][name]; ok {
+		return false
+	}
+	if store.views == nil {
+		store.views = make(map[string]map[string]*view)
+	}
+	if store.views[key] == nil {
+		store.views[key] = make(map[string]*view)
+	}
+	store.views[key][name] = &view{compute: compute, stale: true}
+	return true
+}
+
+// DropView removes the view name of key. It returns false if there is no such view.
+func (store *Store) DropView(key, name string) bool {
+	if _, ok := store.views[key][name]; !ok {
+		return false
+	}
+	delete(store.views[key], name)
+	if len(store.views[key]) == 0 {
+		delete(store.views, key)
+	}
+	return true
+}
+
+// View returns the result of the view name of key, computing it again if it is
+// stale. It returns false if there is no such view.
+func (store *Store) View(key, name string) ([]byte, bool) {
+	v, ok := store.views[key][name]
+	if !ok {
+		return nil, false
+	}
+
+	obj := store.Get(key)
+	if v.stale || v.obj != obj {
+		v.result = v.compute(obj)
+		v.obj = obj
+		v.stale = false
+	}
+	return v.result, true
+}
+
+// HasViews returns true if any key has a view.
+func (store *Store) HasViews() bool {
+	return len(store.views) > 0
+}
+
+// MarkViewsStale marks the views of keys as stale, the views of all the keys
+// if keys is nil.
+func (store *Store) MarkViewsStale(keys []string) {
+	if keys == nil {
+		for _, views := range store.views {
+			for _, v := range views {
+				v.stale = true
+			}
+		}
+		return
+	}
+	for _, k := range keys {
+		for _, v := range store.views[k] {
+			v.stale = true
+		}
+	}
+}
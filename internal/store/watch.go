@@ -0,0 +1,85 @@
+package store
+
+// KeyWatch is the optimistic lock of a transaction on the keys it watches, see
+// WATCH. It is touched as soon as one of them is modified, by a write command,
+// an expiry or an eviction, or once every key of their store is, e.g. by
+// FLUSHDB, the transaction aborting then. A KeyWatch is only used by the shard
+// thread of the stores it watches.
+type KeyWatch struct {
+	keys    map[*Store][]string // keys are the keys watched, by store
+	touched bool
+}
+
+func NewKeyWatch() *KeyWatch {
+	return &KeyWatch{keys: make(map[*Store][]string)}
+}
+
+// Touched returns true if a key watched was modified since it was watched.
+func (w *KeyWatch) Touched() bool {
+	return w.touched
+}
+
+// Unwatch stops watching the keys, so that w can watch others.
+func (w *KeyWatch) Unwatch() {
+	for store, keys := range w.keys {
+		for _, k := range keys {
+			delete(store.watches[k], w)
+			if len(store.watches[k]) == 0 {
+				delete(store.watches, k)
+			}
+		}
+	}
+	w.keys = make(map[*Store][]string)
+	w.touched = false
+}
+
+// Watch makes w watch the keys of the store.
+func (store *Store) Watch(w *KeyWatch, keys ...string) {
+	if store.watches == nil {
+		store.watches = make(map[string]map[*KeyWatch]struct{})
+	}
+	for _, k := range keys {
+		if _, ok := store.watches[k][w]; ok {
+			continue
+		}
+		if store.watches[k] == nil {
+			store.watches[k] = make(map[*KeyWatch]struct{})
+		}
+		store.watches[k][w] = struct{}{}
+		w.keys[store] = append(w.keys[store], k)
+	}
+}
+
+// WatchedKeys returns the keys among keys watched by a transaction.
+func (store *Store) WatchedKeys(keys []string) []string {
+	var watched []string
+	for _, k := range keys {
+		if len(store.watches[k]) > 0 {
+			watched = append(watched, k)
+		}
+	}
+	return watched
+}
+
+// TouchWatches touches the watches of the key k, once a write command modified
+// its object in place. The watches of the keys put or deleted are touched by
+// the store.
+func (store *Store) TouchWatches(k string) {
+	store.touchWatches(k)
+}
+
+// touchWatches touches the watches of the key k.
+func (store *Store) touchWatches(k string) {
+	for w := range store.watches[k] {
+		w.touched = true
+	}
+}
+
+// touchAllWatches touches the watches of every key.
+func (store *Store) touchAllWatches() {
+	for _, ws := range store.watches {
+		for w := range ws {
+			w.touched = true
+		}
+	}
+}
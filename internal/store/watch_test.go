@@ -0,0 +1,65 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"gotest.tools/v3/assert"
+)
+
+func TestKeyWatch(t *testing.T) {
+	store := NewStore(nil)
+	put := func(k string) {
+		store.Put(k, store.NewObj("v", -1, object.ObjTypeString, object.ObjEncodingRaw))
+	}
+
+	w := NewKeyWatch()
+	store.Watch(w, "a", "b", "a")
+	put("c")
+	assert.Assert(t, !w.Touched())
+	put("b")
+	assert.Assert(t, w.Touched())
+
+	// unwatching resets the watch and forgets the keys
+	w.Unwatch()
+	assert.Assert(t, !w.Touched())
+	assert.Equal(t, 0, len(store.watches))
+	put("b")
+	assert.Assert(t, !w.Touched())
+
+	// marking the keys of the write commands dirty does not touch the watches,
+	// the commands touch them once they modified the keys
+	store.Watch(w, "a")
+	assert.DeepEqual(t, []string{"a"}, store.WatchedKeys([]string{"a", "c"}))
+	store.MarkDirty([]string{"a"})
+	store.MarkDirty(nil)
+	assert.Assert(t, !w.Touched())
+	store.TouchWatches("a")
+	assert.Assert(t, w.Touched())
+	w.Unwatch()
+
+	// the deletions touch the watches
+	put("a")
+	store.Watch(w, "a")
+	store.Del("a")
+	assert.Assert(t, w.Touched())
+	w.Unwatch()
+
+	// resetting the store touches every watch
+	other := NewKeyWatch()
+	store.Watch(w, "a")
+	store.Watch(other, "missing")
+	store.ResetStore()
+	assert.Assert(t, w.Touched())
+	assert.Assert(t, other.Touched())
+	w.Unwatch()
+	other.Unwatch()
+	assert.Equal(t, 0, len(store.watches))
+
+	// and so does swapping its database
+	dbs := NewDatabases(2, func() *Store { return NewStore(nil) })
+	dbs.Get(1).Watch(w, "a")
+	dbs.Swap(0, 1)
+	assert.Assert(t, w.Touched())
+	w.Unwatch()
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/sink"
+)
+
+// When write-behind is enabled, the keys modified in the store and matching the
+// patterns of the forwarder are collected by a DirtySet, and their latest state
+// is submitted to the forwarder by ForwardWriteBehind, on every cron tick. A
+// key modified several times between two ticks is hence forwarded once.
+//
+// The keys deleted, expired ones included, are forwarded as deletions. The keys
+// deleted by a reset of the store, e.g. FLUSHDB, are not, all the keys left
+// being forwarded again instead.
+
+type writeBehind struct {
+	forwarder *sink.Forwarder
+	encode    func(k string, obj *object.Obj) ([]byte, error)
+	dirty     *DirtySet
+}
+
+// EnableWriteBehind submits the state of the keys modified to forwarder from
+// now on, encode returning the value of the records of the objects, nil for
+// the ones that are not forwarded. As the keys modified before are unknown,
+// every key is submitted on the first tick.
+func (store *Store) EnableWriteBehind(forwarder *sink.Forwarder, encode func(k string, obj *object.Obj) ([]byte, error)) {
+	store.writeBehind = &writeBehind{forwarder: forwarder, encode: encode, dirty: store.TrackDirty()}
+}
+
+// ForwardWriteBehind submits the state of the keys modified since the previous
+// call and matching the patterns of the forwarder of the store, if any.
+func ForwardWriteBehind(store *Store) {
+	wb := store.writeBehind
+	if wb == nil {
+		return
+	}
+
+	dirty := wb.dirty.Take()
+	keys := dirty.Keys
+	if dirty.All {
+		keys = make([]string, 0, store.store.Len())
+		store.store.All(func(k string, _ *object.Obj) bool {
+			keys = append(keys, k)
+			return true
+		})
+	}
+
+	var records []sink.Record
+	for _, k := range keys {
+		if !wb.forwarder.Matches(k) {
+			continue
+		}
+		obj := store.GetNoTouch(k)
+		if obj == nil {
+			records = append(records, sink.Record{Key: k, Deleted: true})
+			continue
+		}
+		value, err := wb.encode(k, obj)
+		if err != nil || value == nil {
+			continue
+		}
+		records = append(records, sink.Record{Key: k, Value: value})
+	}
+	wb.forwarder.Submit(records)
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/sink"
+	"gotest.tools/v3/assert"
+)
+
+func TestForwardWriteBehind(t *testing.T) {
+	var written []sink.Record
+	forwarder := sink.NewForwarder(sink.Func(func(_ context.Context, records []sink.Record) error {
+		written = append(written, records...)
+		return nil
+	}), []string{"user:*"}, sink.Options{})
+
+	store := NewStore(nil)
+	put := func(k, v string) {
+		store.Put(k, store.NewObj(v, -1, object.ObjTypeString, object.ObjEncodingEmbStr))
+	}
+	// forwarded returns the records submitted by ForwardWriteBehind, by key
+	forwarded := func() []sink.Record {
+		ForwardWriteBehind(store)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		written = nil
+		// Run writes the pending records before noticing that ctx is done
+		for forwarder.Stats().Pending > 0 {
+			forwarder.Run(ctx)
+		}
+		sort.Slice(written, func(i, j int) bool { return written[i].Key < written[j].Key })
+		return written
+	}
+
+	put("user:1", "a")
+	put("session:1", "s")
+	store.EnableWriteBehind(forwarder, func(k string, obj *object.Obj) ([]byte, error) {
+		if obj.Value == "skipped" {
+			return nil, nil
+		}
+		return []byte(k + "=" + obj.Value.(string)), nil
+	})
+
+	// the keys modified before write-behind was enabled are forwarded first
+	assert.DeepEqual(t, []sink.Record{{Key: "user:1", Value: []byte("user:1=a")}}, forwarded())
+	assert.Equal(t, 0, len(forwarded()))
+
+	// a key modified several times is forwarded once, with its latest state
+	put("user:2", "b")
+	put("user:2", "c")
+	put("user:3", "skipped")
+	put("session:1", "t")
+	store.Del("user:1")
+	assert.DeepEqual(t, []sink.Record{
+		{Key: "user:1", Deleted: true},
+		{Key: "user:2", Value: []byte("user:2=c")},
+	}, forwarded())
+
+	// the expired keys are forwarded as deletions
+	obj := store.Get("user:2")
+	store.SetExpiry(obj, -1)
+	store.Get("user:2")
+	assert.DeepEqual(t, []sink.Record{{Key: "user:2", Deleted: true}}, forwarded())
+}
@@ -0,0 +1,72 @@
+package watchdog
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CommandEvent is the latency event of the commands that ran for longer than
+// the watchdog threshold.
+const CommandEvent = "command"
+
+// LatencySample holds the latest and the largest latency of an event.
+type LatencySample struct {
+	Event  string
+	Time   time.Time // time the latest latency was recorded at
+	Latest time.Duration
+	Max    time.Duration
+}
+
+var latency = struct {
+	sync.Mutex
+	samples map[string]*LatencySample
+}{samples: make(map[string]*LatencySample)}
+
+// RecordLatency records the latency of an occurrence of event.
+func RecordLatency(event string, d time.Duration) {
+	latency.Lock()
+	defer latency.Unlock()
+
+	s, ok := latency.samples[event]
+	if !ok {
+		s = &LatencySample{Event: event}
+		latency.samples[event] = s
+	}
+	s.Time, s.Latest, s.Max = time.Now(), d, max(s.Max, d)
+}
+
+// LatestLatency returns the samples of the events recorded, sorted by event.
+func LatestLatency() []LatencySample {
+	latency.Lock()
+	defer latency.Unlock()
+
+	samples := make([]LatencySample, 0, len(latency.samples))
+	for _, s := range latency.samples {
+		samples = append(samples, *s)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Event < samples[j].Event })
+	return samples
+}
+
+// ResetLatency forgets the samples of the given events, or of all the events
+// if none is given. It returns the number of samples forgotten.
+func ResetLatency(events ...string) int {
+	latency.Lock()
+	defer latency.Unlock()
+
+	if len(events) == 0 {
+		n := len(latency.samples)
+		latency.samples = make(map[string]*LatencySample)
+		return n
+	}
+
+	n := 0
+	for _, event := range events {
+		if _, ok := latency.samples[event]; ok {
+			delete(latency.samples, event)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,148 @@
+// Package watchdog reports the commands that run for longer than a threshold,
+// so that the inputs blocking a shard for too long can be found in production.
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// minInterval bounds how often the running command is checked.
+const minInterval = 10 * time.Millisecond
+
+// Watchdog watches the commands executed one at a time by a goroutine, e.g. a
+// shard thread. When a command runs for longer than the threshold, the stack
+// trace of the goroutine is logged while the command still runs, and a
+// latency event is recorded once it completes.
+type Watchdog struct {
+	name      string
+	threshold time.Duration
+	logger    *slog.Logger
+
+	mu        sync.Mutex
+	goroutine []byte    // header of the stack trace of the watched goroutine
+	cmd       string    // command being executed, empty if none
+	start     time.Time // time the command started at
+	reported  bool      // whether the stack trace was logged for the command
+}
+
+// New creates a watchdog reporting the commands that run for longer than
+// threshold. The name identifies the watched goroutine in the logs.
+func New(name string, threshold time.Duration, logger *slog.Logger) *Watchdog {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Watchdog{
+		name:      name,
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+// Begin marks the start of the execution of cmd. It must be called by the
+// watched goroutine. A nil watchdog watches nothing.
+func (w *Watchdog) Begin(cmd string) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	if w.goroutine == nil {
+		w.goroutine = currentGoroutine()
+	}
+	w.cmd, w.start, w.reported = cmd, time.Now(), false
+	w.mu.Unlock()
+}
+
+// End marks the end of the command started with Begin. It records a latency
+// event if the command ran for longer than the threshold.
+func (w *Watchdog) End() {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	cmd, elapsed := w.cmd, time.Since(w.start)
+	w.cmd = ""
+	w.mu.Unlock()
+
+	if elapsed < w.threshold {
+		return
+	}
+	RecordLatency(CommandEvent, elapsed)
+	w.logger.Warn("slow command",
+		slog.String("watchdog", w.name),
+		slog.String("cmd", cmd),
+		slog.Duration("duration", elapsed))
+}
+
+// Run checks the command being executed until ctx is done.
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(max(w.threshold/2, minInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check logs the stack trace of the watched goroutine if the command being
+// executed has been running for longer than the threshold. The stack trace is
+// logged once per command.
+func (w *Watchdog) check() {
+	w.mu.Lock()
+	if w.cmd == "" || w.reported || time.Since(w.start) < w.threshold {
+		w.mu.Unlock()
+		return
+	}
+	w.reported = true
+	cmd, elapsed, goroutine := w.cmd, time.Since(w.start), w.goroutine
+	w.mu.Unlock()
+
+	w.logger.Warn("command exceeded the watchdog threshold",
+		slog.String("watchdog", w.name),
+		slog.String("cmd", cmd),
+		slog.Duration("elapsed", elapsed),
+		slog.String("stack", string(stackOf(goroutine))))
+}
+
+// currentGoroutine returns the header of the stack trace of the calling
+// goroutine, e.g. "goroutine 42 ", which identifies it in a dump of all the
+// goroutines.
+func currentGoroutine() []byte {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	if i := bytes.IndexByte(buf, '['); i > 0 {
+		return buf[:i]
+	}
+	return buf
+}
+
+// stackOf returns the stack trace of the goroutine with the given header.
+func stackOf(goroutine []byte) []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, goroutine) {
+			return stack
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// syncBuffer is a buffer safe to write to from the watchdog goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func slowCommand(w *Watchdog, d time.Duration) {
+	w.Begin("SLEEP")
+	time.Sleep(d)
+	w.End()
+}
+
+func TestWatchdog(t *testing.T) {
+	ResetLatency()
+	defer ResetLatency()
+
+	var logs syncBuffer
+	w := New("shard-0", 20*time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// commands within the threshold are not reported
+	w.Begin("GET")
+	w.End()
+	assert.Equal(t, 0, len(LatestLatency()))
+
+	slowCommand(w, 100*time.Millisecond)
+
+	// the stack trace of the slow command is logged while it runs
+	out := logs.String()
+	assert.Assert(t, strings.Contains(out, "command exceeded the watchdog threshold"), out)
+	assert.Assert(t, strings.Contains(out, "watchdog.slowCommand"), out)
+	assert.Assert(t, strings.Contains(out, "slow command"), out)
+
+	samples := LatestLatency()
+	assert.Equal(t, 1, len(samples))
+	assert.Equal(t, CommandEvent, samples[0].Event)
+	assert.Assert(t, samples[0].Latest >= 100*time.Millisecond)
+	assert.Equal(t, samples[0].Latest, samples[0].Max)
+}
+
+func TestNilWatchdog(t *testing.T) {
+	var w *Watchdog
+	w.Begin("GET")
+	w.End()
+}
+
+func TestLatency(t *testing.T) {
+	ResetLatency()
+	defer ResetLatency()
+
+	RecordLatency("b", 30*time.Millisecond)
+	RecordLatency("a", 20*time.Millisecond)
+	RecordLatency("a", 10*time.Millisecond)
+
+	samples := LatestLatency()
+	assert.Equal(t, 2, len(samples))
+	assert.Equal(t, "a", samples[0].Event)
+	assert.Equal(t, 10*time.Millisecond, samples[0].Latest)
+	assert.Equal(t, 20*time.Millisecond, samples[0].Max)
+
+	assert.Equal(t, 1, ResetLatency("a", "c"))
+	assert.Equal(t, 1, ResetLatency())
+	assert.Equal(t, 0, len(LatestLatency()))
+}
@@ -1,5 +1,10 @@
 package worker
 
+import (
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+)
+
 // Breakup file is used by Worker to split commands that need to be executed
 // across multiple shards. For commands that operate on multiple keys or
 // require distribution across shards (e.g., MultiShard commands), a Breakup
@@ -14,3 +19,49 @@ package worker
 //
 // The result is a list of commands, one for each shard, which are then
 // scattered to the shard threads for execution.
+
+// decomposeCounterIncr turns COUNTER.INCR into an INCRBY of one of the
+// sub-counters of the counter, picked at random, so that the increments of a
+// hot counter are spread across the shards. Invalid commands are sent as is
+// to the shard of the counter, which reports the error.
+func decomposeCounterIncr(c *cmd.DiceDBCmd) []*cmd.DiceDBCmd {
+	if len(c.Args) != 2 && len(c.Args) != 3 {
+		return []*cmd.DiceDBCmd{c}
+	}
+	shards, ok := eval.ParseCounterShards(c.Args[1])
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+
+	incr := "1"
+	if len(c.Args) == 3 {
+		incr = c.Args[2]
+	}
+	return []*cmd.DiceDBCmd{{
+		RequestID: c.RequestID,
+		Cmd:       "INCRBY",
+		Args:      []string{eval.RandomCounterSubKey(c.Args[0], shards), incr},
+	}}
+}
+
+// decomposeCounterGet turns COUNTER.GET into a GET of each sub-counter of the
+// counter, each sent to the shard owning it.
+func decomposeCounterGet(c *cmd.DiceDBCmd) []*cmd.DiceDBCmd {
+	if len(c.Args) != 2 {
+		return []*cmd.DiceDBCmd{c}
+	}
+	shards, ok := eval.ParseCounterShards(c.Args[1])
+	if !ok {
+		return []*cmd.DiceDBCmd{c}
+	}
+
+	cmds := make([]*cmd.DiceDBCmd, shards)
+	for i := range cmds {
+		cmds[i] = &cmd.DiceDBCmd{
+			RequestID: c.RequestID,
+			Cmd:       "GET",
+			Args:      []string{eval.CounterSubKey(c.Args[0], i)},
+		}
+	}
+	return cmds
+}
@@ -15,13 +15,15 @@ const (
 	SingleShard
 	MultiShard
 	Custom
+	AllShards // sent as is to every shard, each holding a part of every database
 )
 
 // Global commands
 const (
-	CmdPing  = "PING"
-	CmdAbort = "ABORT"
-	CmdAuth  = "AUTH"
+	CmdPing   = "PING"
+	CmdAbort  = "ABORT"
+	CmdAuth   = "AUTH"
+	CmdSelect = "SELECT"
 )
 
 // Single-shard commands.
@@ -31,6 +33,17 @@ const (
 	CmdGetSet = "GETSET"
 )
 
+// Multi-shard commands.
+const (
+	CmdCounterIncr = "COUNTER.INCR"
+	CmdCounterGet  = "COUNTER.GET"
+)
+
+// All-shard commands.
+const (
+	CmdSwapDB = "SWAPDB"
+)
+
 type CmdMeta struct {
 	CmdType
 	Cmd                  string
@@ -58,6 +71,9 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdAuth: {
 		CmdType: Custom,
 	},
+	CmdSelect: {
+		CmdType: Custom,
+	},
 
 	// Single-shard commands.
 	CmdSet: {
@@ -69,6 +85,24 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdGetSet: {
 		CmdType: SingleShard,
 	},
+
+	// Multi-shard commands.
+	CmdCounterIncr: {
+		CmdType:          MultiShard,
+		decomposeCommand: decomposeCounterIncr,
+		composeResponse:  composeCounterIncr,
+	},
+	CmdCounterGet: {
+		CmdType:          MultiShard,
+		decomposeCommand: decomposeCounterGet,
+		composeResponse:  composeCounterGet,
+	},
+
+	// All-shard commands.
+	CmdSwapDB: {
+		CmdType:         AllShards,
+		composeResponse: composeAllShards,
+	},
 }
 
 func init() {
@@ -92,6 +126,10 @@ func validateCmdMeta(c string, meta CmdMeta) error {
 		if meta.decomposeCommand == nil || meta.composeResponse == nil {
 			return fmt.Errorf("multi-shard command %s must have both decomposeCommand and composeResponse implemented", c)
 		}
+	case AllShards:
+		if meta.composeResponse == nil {
+			return fmt.Errorf("all-shard command %s must have composeResponse implemented", c)
+		}
 	case SingleShard, Custom:
 		// No specific validations for these types currently
 	default:
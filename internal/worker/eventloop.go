@@ -0,0 +1,326 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio/ioring"
+	respparser "github.com/dicedb/dice/internal/clientio/requestparser/resp"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/shard"
+)
+
+const (
+	// loopReadBufferSize is the size of the reads of the connections, a
+	// request filling the buffer being read on until a read falls short.
+	loopReadBufferSize = 16 * 1024
+	// loopMaxRequestSize is the maximum size of a request.
+	loopMaxRequestSize = 512 * 1024
+	// loopWaitTimeout bounds the waits for completions, for the loop to notice
+	// the cancellation of its context.
+	loopWaitTimeout = 100 * time.Millisecond
+)
+
+// EventLoop serves the connections of the multi-threaded mode from a single
+// goroutine, instead of a worker goroutine per connection. It reads the
+// commands of all its connections through a ring, scatters them to the shards
+// without waiting for them, and writes the replies once the shards responded,
+// the shards remaining the single writers of their keys. The commands are
+// served like the workers do, each connection having a BaseWorker holding its
+// session, without an IOHandler.
+//
+// A connection has one request in flight at a time: it is read again once
+// the reply to the previous one is written.
+type EventLoop struct {
+	ring            ioring.Ring
+	workerManager   *WorkerManager
+	shardManager    *shard.ShardManager
+	globalErrorChan chan error
+	logger          *slog.Logger
+	respChan        chan *ops.StoreResponse // respChan receives the responses of the shards to all the connections
+
+	mu        sync.Mutex
+	accepted  []loopAccept         // accepted holds the connections handed over, not yet served
+	responses []*ops.StoreResponse // responses holds the responses of the shards, not yet handled
+
+	conns         map[int]*loopConn    // conns maps the file descriptors to their connection
+	requests      map[uint32]*loopConn // requests maps the IDs of the requests waiting for the shards to their connection
+	lastRequestID uint32
+}
+
+type loopAccept struct {
+	fd       int
+	workerID string
+}
+
+// loopConn is a connection served by an EventLoop.
+type loopConn struct {
+	fd      int
+	worker  *BaseWorker
+	buf     []byte         // buf is the buffer the connection is read into
+	data    []byte         // data is the request read so far
+	cmd     *cmd.DiceDBCmd // cmd is the command waiting for the shards, nil if none
+	ct      CmdType
+	waiting int // waiting is the number of responses still expected from the shards
+	resps   []eval.EvalResponse
+	writes  int // writes is the number of writes in flight
+}
+
+// NewEventLoop creates an EventLoop serving up to maxClients connections
+// through a PollRing.
+func NewEventLoop(maxClients int32, sm *shard.ShardManager, wm *WorkerManager, gec chan error, logger *slog.Logger) (*EventLoop, error) {
+	ring, err := ioring.NewPollRing(maxClients)
+	if err != nil {
+		return nil, err
+	}
+	return &EventLoop{
+		ring:            ring,
+		workerManager:   wm,
+		shardManager:    sm,
+		globalErrorChan: gec,
+		logger:          logger,
+		respChan:        make(chan *ops.StoreResponse, 1000),
+		conns:           make(map[int]*loopConn),
+		requests:        make(map[uint32]*loopConn),
+	}, nil
+}
+
+// AddConn hands the connection fd over to the loop, which serves it as the
+// worker workerID. It is safe to call from any goroutine.
+func (l *EventLoop) AddConn(fd int, workerID string) error {
+	l.mu.Lock()
+	l.accepted = append(l.accepted, loopAccept{fd: fd, workerID: workerID})
+	l.mu.Unlock()
+	return l.ring.Wake()
+}
+
+// Run serves the connections till ctx is canceled, then closes them.
+func (l *EventLoop) Run(ctx context.Context) error {
+	defer l.ring.Close()
+
+	// the responses of the shards are handed over to the loop, which may be
+	// waiting for completions
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case resp := <-l.respChan:
+				l.mu.Lock()
+				l.responses = append(l.responses, resp)
+				l.mu.Unlock()
+				if err := l.ring.Wake(); err != nil {
+					l.logger.Warn("could not wake the event loop up", slog.Any("error", err))
+				}
+			}
+		}
+	}()
+
+	for ctx.Err() == nil {
+		completions, err := l.ring.Wait(loopWaitTimeout)
+		if err != nil {
+			l.closeAll()
+			return err
+		}
+		for _, c := range completions {
+			l.complete(ctx, c)
+		}
+
+		l.mu.Lock()
+		accepted, responses := l.accepted, l.responses
+		l.accepted, l.responses = nil, nil
+		l.mu.Unlock()
+		for _, a := range accepted {
+			l.add(a)
+		}
+		for _, resp := range responses {
+			l.respond(resp)
+		}
+	}
+
+	l.closeAll()
+	return ctx.Err()
+}
+
+// add starts serving an accepted connection.
+func (l *EventLoop) add(a loopAccept) {
+	if err := syscall.SetNonblock(a.fd, true); err != nil {
+		l.logger.Warn("could not serve the connection", slog.Int("client-fd", a.fd), slog.Any("error", err))
+		syscall.Close(a.fd)
+		return
+	}
+	w := NewWorker(a.workerID, l.respChan, nil, respparser.NewParser(l.logger), l.shardManager, l.globalErrorChan, l.logger)
+	if err := l.workerManager.RegisterWorker(w); err != nil {
+		l.logger.Warn("could not serve the connection", slog.Int("client-fd", a.fd), slog.Any("error", err))
+		syscall.Close(a.fd)
+		return
+	}
+
+	c := &loopConn{fd: a.fd, worker: w, buf: make([]byte, loopReadBufferSize)}
+	l.conns[a.fd] = c
+	l.read(c)
+}
+
+// read submits the next read of the connection.
+func (l *EventLoop) read(c *loopConn) {
+	if err := l.ring.Submit(ioring.Op{Code: ioring.OpRead, Fd: c.fd, Buf: c.buf}); err != nil {
+		l.logger.Debug("Read error, connection closed possibly", slog.String("workerID", c.worker.id), slog.Any("error", err))
+		l.close(c)
+	}
+}
+
+// write submits the write of a reply to the command cmdName to the connection,
+// see encodeReply.
+func (l *EventLoop) write(c *loopConn, cmdName string, reply interface{}) {
+	resp := encodeReply(cmdName, reply)
+	if err := l.ring.Submit(ioring.Op{Code: ioring.OpWrite, Fd: c.fd, Buf: resp}); err != nil {
+		l.logger.Debug("Write error, connection closed possibly", slog.String("workerID", c.worker.id), slog.Any("error", err))
+		l.close(c)
+		return
+	}
+	c.writes++
+}
+
+// complete handles the completion of an operation of a connection.
+func (l *EventLoop) complete(ctx context.Context, comp ioring.Completion) {
+	c, ok := l.conns[comp.Op.Fd]
+	if !ok {
+		return
+	}
+	if comp.Err != nil {
+		l.logger.Debug("Connection closed", slog.String("workerID", c.worker.id), slog.Any("error", comp.Err))
+		l.close(c)
+		return
+	}
+
+	switch comp.Op.Code {
+	case ioring.OpRead:
+		c.data = append(c.data, comp.Op.Buf[:comp.N]...)
+		if len(c.data) > loopMaxRequestSize {
+			l.logger.Warn("Request too large", slog.Any("size", len(c.data)))
+			l.close(c)
+			return
+		}
+		// a read filling the buffer likely left data to read
+		if comp.N == len(comp.Op.Buf) {
+			l.read(c)
+			return
+		}
+		l.serve(ctx, c)
+	case ioring.OpWrite:
+		// the connection is read again once the replies are written
+		if c.writes--; c.writes == 0 && c.cmd == nil {
+			l.read(c)
+		}
+	}
+}
+
+// serve parses the request read from the connection and serves its command,
+// like BaseWorker.Start does.
+func (l *EventLoop) serve(ctx context.Context, c *loopConn) {
+	w := c.worker
+	data := c.data
+	c.data = nil
+
+	cmds, err := w.parser.Parse(data)
+	if err != nil {
+		l.write(c, "", err)
+	}
+	if len(cmds) == 0 {
+		l.write(c, "", fmt.Errorf("ERR: Invalid request"))
+		return
+	}
+
+	// DiceDB supports clients to send only one request at a time
+	if len(cmds) > 1 {
+		l.write(c, "", fmt.Errorf("ERR: Multiple commands not supported"))
+	}
+	if err := w.isAuthenticated(cmds[0]); errors.Is(err, errSessionRevoked) {
+		l.logger.Info("Killing the connection of a user who lost access", slog.String("workerID", w.id))
+		l.close(c)
+		return
+	} else if err != nil {
+		l.write(c, cmds[0].Cmd, err)
+		return
+	}
+
+	l.lastRequestID++
+	diceDBCmd := cmds[0]
+	diceDBCmd.RequestID = l.lastRequestID
+
+	reply, cmdList, ct := w.dispatch(diceDBCmd)
+	if reply != nil {
+		l.write(c, diceDBCmd.Cmd, reply)
+		if diceDBCmd.Cmd == CmdAbort {
+			w.abort()
+		}
+		return
+	}
+
+	c.cmd, c.ct, c.waiting, c.resps = diceDBCmd, ct, len(cmdList), nil
+	l.requests[diceDBCmd.RequestID] = c
+	if err := w.scatter(ctx, cmdList, ct); err != nil {
+		delete(l.requests, diceDBCmd.RequestID)
+		c.cmd = nil
+		l.close(c)
+	}
+}
+
+// respond handles a response of a shard, writing the replies to its command
+// once all the shards it was scattered to responded.
+func (l *EventLoop) respond(resp *ops.StoreResponse) {
+	c, ok := l.requests[resp.RequestID]
+	if !ok || c.cmd == nil {
+		// the connection was closed in the meantime
+		return
+	}
+	if resp.EvalResponse != nil {
+		c.resps = append(c.resps, *resp.EvalResponse)
+	}
+	if c.waiting--; c.waiting > 0 {
+		return
+	}
+
+	delete(l.requests, resp.RequestID)
+	diceDBCmd := c.cmd
+	c.cmd = nil
+	if len(c.resps) == 0 {
+		l.close(c)
+		return
+	}
+	for _, reply := range c.worker.replies(diceDBCmd.Cmd, c.ct, c.resps) {
+		l.write(c, diceDBCmd.Cmd, reply)
+	}
+}
+
+// close closes the connection, dropping its operations in flight.
+func (l *EventLoop) close(c *loopConn) {
+	if _, ok := l.conns[c.fd]; !ok {
+		return
+	}
+	delete(l.conns, c.fd)
+	if c.cmd != nil {
+		delete(l.requests, c.cmd.RequestID)
+	}
+
+	err := errors.Join(l.ring.Cancel(c.fd), syscall.Close(c.fd))
+	if uerr := l.workerManager.UnregisterWorker(c.worker.id); uerr != nil {
+		err = errors.Join(err, uerr)
+	}
+	if err != nil {
+		l.logger.Warn("Error closing connection", slog.String("workerID", c.worker.id), slog.Any("error", err))
+	}
+}
+
+func (l *EventLoop) closeAll() {
+	for _, c := range l.conns {
+		l.close(c)
+	}
+}
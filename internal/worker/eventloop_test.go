@@ -0,0 +1,239 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/clientio/iohandler/netconn"
+	respparser "github.com/dicedb/dice/internal/clientio/requestparser/resp"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/mocks"
+)
+
+var testWorkerID atomic.Uint64
+
+// serveFunc serves the server end of a connection, either by an event loop or
+// by a worker.
+type serveFunc func(fd int)
+
+// startShards runs a shard manager till the end of the test.
+func startShards(tb testing.TB) (context.Context, *shard.ShardManager, *WorkerManager) {
+	tb.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	tb.Cleanup(cancel)
+
+	logger := slog.New(mocks.SlogNoopHandler{})
+	sm := shard.NewShardManager(2, nil, make(chan error, 10), logger)
+	go sm.Run(ctx)
+	return ctx, sm, NewWorkerManager(20000, sm)
+}
+
+func serveByEventLoop(tb testing.TB) serveFunc {
+	ctx, sm, wm := startShards(tb)
+	l, err := NewEventLoop(20000, sm, wm, make(chan error, 10), slog.New(mocks.SlogNoopHandler{}))
+	assert.NilError(tb, err)
+	go l.Run(ctx)
+	return func(fd int) {
+		assert.NilError(tb, l.AddConn(fd, fmt.Sprintf("L-%d", testWorkerID.Add(1))))
+	}
+}
+
+func serveByWorkers(tb testing.TB) serveFunc {
+	ctx, sm, wm := startShards(tb)
+	logger := slog.New(mocks.SlogNoopHandler{})
+	return func(fd int) {
+		ioHandler, err := netconn.NewIOHandler(fd, logger)
+		assert.NilError(tb, err)
+		w := NewWorker(fmt.Sprintf("W-%d", testWorkerID.Add(1)), make(chan *ops.StoreResponse), ioHandler,
+			respparser.NewParser(logger), sm, make(chan error, 10), logger)
+		assert.NilError(tb, wm.RegisterWorker(w))
+		go w.Start(ctx)
+	}
+}
+
+// testClient is the client end of a connection.
+type testClient struct {
+	conn   net.Conn
+	parser *clientio.RESPParser
+}
+
+func connect(tb testing.TB, serve serveFunc) *testClient {
+	tb.Helper()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	assert.NilError(tb, err)
+	serve(fds[0])
+
+	f := os.NewFile(uintptr(fds[1]), "client")
+	conn, err := net.FileConn(f)
+	assert.NilError(tb, err)
+	f.Close()
+	tb.Cleanup(func() { conn.Close() })
+	return &testClient{conn: conn, parser: clientio.NewRESPParser(conn)}
+}
+
+func (c *testClient) do(args ...string) (interface{}, error) {
+	cmd := make([]interface{}, len(args))
+	for i, arg := range args {
+		cmd[i] = arg
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(clientio.Encode(cmd, false)); err != nil {
+		return nil, err
+	}
+	return c.parser.DecodeOne()
+}
+
+func TestEventLoop(t *testing.T) {
+	serve := serveByEventLoop(t)
+	c1, c2 := connect(t, serve), connect(t, serve)
+
+	reply, err := c1.do("SET", "k", "v")
+	assert.NilError(t, err)
+	assert.Equal(t, "OK", reply)
+	reply, err = c2.do("GET", "k")
+	assert.NilError(t, err)
+	assert.Equal(t, "v", reply)
+
+	reply, err = c1.do("GET")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(reply.(string), "wrong number of arguments"))
+
+	// the keys of the commands run on a single shard must belong to it
+	for i := 0; ; i++ {
+		key := "k" + strconv.Itoa(i)
+		if reply, _ := c1.do("MSET", "k", "v", key, "v"); reply != "OK" {
+			assert.Assert(t, strings.HasPrefix(reply.(string), "CROSSSLOT"), reply)
+			break
+		}
+	}
+
+	// the commands broken down are scattered to the shards and gathered
+	reply, err = c2.do("COUNTER.INCR", "c", "4", "3")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(3), reply)
+	reply, err = c2.do("COUNTER.INCR", "c", "4", "2")
+	assert.NilError(t, err)
+	reply, err = c1.do("COUNTER.GET", "c", "4")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(5), reply)
+
+	// a closed connection is forgotten, the others are still served
+	c2.conn.Close()
+	reply, err = c1.do("GET", "k")
+	assert.NilError(t, err)
+	assert.Equal(t, "v", reply)
+}
+
+func TestSwapDBAllShards(t *testing.T) {
+	c := connect(t, serveByWorkers(t))
+
+	// the keys are spread over both shards
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = "swap:" + strconv.Itoa(i)
+		reply, err := c.do("SET", keys[i], "v")
+		assert.NilError(t, err)
+		assert.Equal(t, "OK", reply)
+	}
+
+	reply, err := c.do("SWAPDB", "0", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, "OK", reply)
+	for _, key := range keys {
+		reply, err = c.do("GET", key)
+		assert.NilError(t, err)
+		assert.Equal(t, "(nil)", reply, key)
+	}
+
+	reply, err = c.do("SELECT", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, "OK", reply)
+	for _, key := range keys {
+		reply, err = c.do("GET", key)
+		assert.NilError(t, err)
+		assert.Equal(t, "v", reply, key)
+	}
+
+	reply, err = c.do("SWAPDB", "0", "99")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(reply.(string), "out of range"), reply)
+}
+
+func TestReplyCompat(t *testing.T) {
+	saved := config.DiceConfig.Server.ReplyCompat
+	defer func() { config.DiceConfig.Server.ReplyCompat = saved }()
+	config.DiceConfig.Server.ReplyCompat = eval.CompatRedis7
+
+	for name, serve := range map[string]serveFunc{"workers": serveByWorkers(t), "event loop": serveByEventLoop(t)} {
+		c := connect(t, serve)
+
+		// SET is migrated, its error being encoded by the worker
+		reply, err := c.do("SET", "k", "v", "EX", "0")
+		assert.NilError(t, err)
+		assert.Equal(t, "ERR invalid expire time in 'set' command", reply, name)
+
+		reply, err = c.do("BF.RESERVE", "bf", "0.01", "100")
+		assert.NilError(t, err)
+		assert.Equal(t, "OK", reply, name)
+		reply, err = c.do("BF.RESERVE", "bf", "0.01", "100")
+		assert.NilError(t, err)
+		assert.Equal(t, "ERR item exists", reply, name)
+	}
+}
+
+// BenchmarkServe compares the event loop with the goroutine per connection
+// model, for 64 connections sending GET and SET commands concurrently.
+func BenchmarkServe(b *testing.B) {
+	models := []struct {
+		name  string
+		serve func(testing.TB) serveFunc
+	}{
+		{"event-loop", serveByEventLoop},
+		{"goroutine-per-connection", serveByWorkers},
+	}
+	for _, m := range models {
+		b.Run(m.name, func(b *testing.B) {
+			serve := m.serve(b)
+			clients := make(chan *testClient, 64)
+			for i := 0; i < cap(clients); i++ {
+				clients <- connect(b, serve)
+			}
+
+			b.SetParallelism(cap(clients) / runtime.GOMAXPROCS(0))
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				c := <-clients
+				defer func() { clients <- c }()
+				for i := 0; pb.Next(); i++ {
+					key := "key:" + strconv.Itoa(i%1000)
+					args := []string{"GET", key}
+					if i%2 == 0 {
+						args = []string{"SET", key, "value"}
+					}
+					if _, err := c.do(args...); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
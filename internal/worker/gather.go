@@ -1,5 +1,7 @@
 package worker
 
+import "github.com/dicedb/dice/internal/eval"
+
 // Gather file is used by Worker to collect and process responses
 // from multiple shards. For commands that are executed across
 // several shards (e.g., MultiShard commands), a Gather function
@@ -13,3 +15,34 @@ package worker
 // The result is a unified response that reflects the combined
 // outcome of operations executed across multiple shards, ensuring
 // that the client receives a single, cohesive result.
+
+// composeCounterIncr returns the reply of the increment of the sub-counter.
+func composeCounterIncr(responses ...eval.EvalResponse) interface{} {
+	if responses[0].Error != nil {
+		return responses[0].Error
+	}
+	return responses[0].Result
+}
+
+// composeCounterGet returns the sum of the sub-counters of the counter.
+func composeCounterGet(responses ...eval.EvalResponse) interface{} {
+	// an invalid COUNTER.GET is evaluated as is by a single shard
+	if b, ok := responses[0].Result.([]byte); ok && len(responses) == 1 {
+		return b
+	}
+	return eval.SumCounterShards(responses...)
+}
+
+// composeAllShards returns the reply of a command run by every shard, the
+// first error if any shard failed to run it.
+func composeAllShards(responses ...eval.EvalResponse) interface{} {
+	for i := range responses {
+		if responses[i].Err() != nil {
+			if responses[i].Error != nil {
+				return responses[i].Error
+			}
+			return responses[i].Result
+		}
+	}
+	return responses[0].Result
+}
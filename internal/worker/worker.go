@@ -13,6 +13,7 @@ import (
 	"github.com/dicedb/dice/internal/auth"
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/clientio/iohandler"
+	"github.com/dicedb/dice/internal/clientio/iohandler/netconn"
 	"github.com/dicedb/dice/internal/clientio/requestparser"
 	"github.com/dicedb/dice/internal/cmd"
 	diceerrors "github.com/dicedb/dice/internal/errors"
@@ -21,6 +22,10 @@ import (
 	"github.com/dicedb/dice/internal/shard"
 )
 
+// errSessionRevoked is returned for the connections to kill, their user having
+// lost access.
+var errSessionRevoked = errors.New("session revoked")
+
 // Worker interface
 type Worker interface {
 	ID() string
@@ -35,6 +40,7 @@ type BaseWorker struct {
 	shardManager    *shard.ShardManager
 	respChan        chan *ops.StoreResponse
 	Session         *auth.Session
+	db              int // db is the logical database the commands of the client run on, see SELECT
 	globalErrorChan chan error
 	logger          *slog.Logger
 }
@@ -111,16 +117,26 @@ func (w *BaseWorker) Start(ctx context.Context) error {
 			}
 
 			err = w.isAuthenticated(cmds[0])
+			if errors.Is(err, errSessionRevoked) {
+				w.logger.Info("Killing the connection of a user who lost access", slog.String("workerID", w.id))
+				return errors.Join(err, w.ioHandler.Close())
+			}
 			if err != nil {
-				werr := w.ioHandler.Write(ctx, err)
+				werr := w.writeReply(ctx, cmds[0].Cmd, err)
 				if werr != nil {
 					w.logger.Debug("Write error, connection closed possibly", slog.Any("error", errors.Join(err, werr)))
 					return errors.Join(err, werr)
 				}
+				continue
 			}
 			// executeCommand executes the command and return the response back to the client
 			func(errChan chan error) {
 				execctx, cancel := context.WithTimeout(ctx, 6*time.Second) // Timeout set to 6 seconds for integration tests
+				if eval.IsBlockingCommand(cmds[0].Cmd) {
+					// the blocking commands, e.g. BLPOP, time out on their own
+					cancel()
+					execctx, cancel = context.WithCancel(ctx)
+				}
 				defer cancel()
 				err = w.executeCommand(execctx, cmds[0])
 				if err != nil {
@@ -136,61 +152,26 @@ func (w *BaseWorker) Start(ctx context.Context) error {
 }
 
 func (w *BaseWorker) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) error {
-	// Break down the single command into multiple commands if multisharding is supported.
-	// The length of cmdList helps determine how many shards to wait for responses.
-	cmdList := make([]*cmd.DiceDBCmd, 0)
-
-	// Retrieve metadata for the command to determine if multisharding is supported.
-	meta, ok := CommandsMeta[diceDBCmd.Cmd]
-	if !ok {
-		// If no metadata exists, treat it as a single command and not migrated
-		cmdList = append(cmdList, diceDBCmd)
-	} else {
-		// Depending on the command type, decide how to handle it.
-		switch meta.CmdType {
-		case Global:
-			// If it's a global command, process it immediately without involving any shards.
-			err := w.ioHandler.Write(ctx, meta.WorkerCommandHandler(diceDBCmd.Args))
-			w.logger.Debug("Error executing for worker", slog.String("workerID", w.id), slog.Any("error", err))
-			return err
-
-		case SingleShard:
-			// For single-shard or custom commands, process them without breaking up.
-			cmdList = append(cmdList, diceDBCmd)
-
-		case MultiShard:
-			// If the command supports multisharding, break it down into multiple commands.
-			cmdList = meta.decomposeCommand(diceDBCmd)
-		case Custom:
-			switch diceDBCmd.Cmd {
-			case CmdAuth:
-				err := w.ioHandler.Write(ctx, w.RespAuth(diceDBCmd.Args))
-				if err != nil {
-					w.logger.Error("Error sending auth response to worker", slog.String("workerID", w.id), slog.Any("error", err))
-				}
-				return err
-			case CmdAbort:
-				err := w.ioHandler.Write(ctx, clientio.OK)
-				if err != nil {
-					w.logger.Error("Error sending abort response to worker", slog.String("workerID", w.id), slog.Any("error", err))
-				}
-				w.logger.Info("Received ABORT command, initiating server shutdown", slog.String("workerID", w.id))
-				w.globalErrorChan <- diceerrors.ErrAborted
-				return err
-			default:
-				cmdList = append(cmdList, diceDBCmd)
-			}
+	reply, cmdList, ct := w.dispatch(diceDBCmd)
+	if reply != nil {
+		err := w.writeReply(ctx, diceDBCmd.Cmd, reply)
+		if err != nil {
+			w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
 		}
+		if diceDBCmd.Cmd == CmdAbort {
+			w.abort()
+		}
+		return err
 	}
 
 	// Scatter the broken-down commands to the appropriate shards.
-	err := w.scatter(ctx, cmdList)
+	err := w.scatter(ctx, cmdList, ct)
 	if err != nil {
 		return err
 	}
 
 	// Gather the responses from the shards and write them to the buffer.
-	err = w.gather(ctx, diceDBCmd.Cmd, len(cmdList), meta.CmdType)
+	err = w.gather(ctx, diceDBCmd.Cmd, len(cmdList), ct)
 	if err != nil {
 		return err
 	}
@@ -198,9 +179,69 @@ func (w *BaseWorker) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDBCm
 	return nil
 }
 
+// dispatch works out how to serve a command. It returns either the reply to
+// send right away, or the commands to scatter to the shards along with the
+// type of the command, which tells how to compose their responses.
+func (w *BaseWorker) dispatch(diceDBCmd *cmd.DiceDBCmd) (reply interface{}, cmdList []*cmd.DiceDBCmd, ct CmdType) {
+	// Retrieve metadata for the command to determine if multisharding is supported.
+	meta, ok := CommandsMeta[diceDBCmd.Cmd]
+
+	// The commands that are not broken down run on a single shard, which must
+	// hold all their keys.
+	if (!ok || meta.CmdType != MultiShard) && w.isCrossShard(diceDBCmd) {
+		return diceerrors.ErrCrossSlot, nil, meta.CmdType
+	}
+	if !ok {
+		// If no metadata exists, treat it as a single command and not migrated
+		return nil, []*cmd.DiceDBCmd{diceDBCmd}, meta.CmdType
+	}
+
+	// Depending on the command type, decide how to handle it.
+	switch meta.CmdType {
+	case Global:
+		// If it's a global command, process it immediately without involving any shards.
+		return meta.WorkerCommandHandler(diceDBCmd.Args), nil, meta.CmdType
+	case MultiShard:
+		// If the command supports multisharding, break it down into multiple commands.
+		return nil, meta.decomposeCommand(diceDBCmd), meta.CmdType
+	case AllShards:
+		// The command runs on every shard, e.g. SWAPDB, which would otherwise
+		// swap the part of the databases held by a single shard.
+		cmds := make([]*cmd.DiceDBCmd, w.shardManager.GetShardCount())
+		for i := range cmds {
+			cmds[i] = diceDBCmd
+		}
+		return nil, cmds, meta.CmdType
+	case Custom:
+		switch diceDBCmd.Cmd {
+		case CmdAuth:
+			return w.RespAuth(diceDBCmd.Args), nil, meta.CmdType
+		case CmdSelect:
+			return w.RespSelect(diceDBCmd.Args), nil, meta.CmdType
+		case CmdAbort:
+			return clientio.OK, nil, meta.CmdType
+		}
+	}
+	// For single-shard or custom commands, process them without breaking up.
+	return nil, []*cmd.DiceDBCmd{diceDBCmd}, meta.CmdType
+}
+
+// abort initiates the shutdown of the server, once ABORT is acknowledged.
+func (w *BaseWorker) abort() {
+	w.logger.Info("Received ABORT command, initiating server shutdown", slog.String("workerID", w.id))
+	w.globalErrorChan <- diceerrors.ErrAborted
+}
+
+// isCrossShard returns true if the keys of the command map to different shards.
+func (w *BaseWorker) isCrossShard(diceDBCmd *cmd.DiceDBCmd) bool {
+	_, ok := w.shardManager.GetKeysShard(eval.CommandKeys(diceDBCmd))
+	return !ok
+}
+
 // scatter distributes the DiceDB commands to the respective shards based on the key.
 // For each command, it calculates the shard ID and sends the command to the shard's request channel for processing.
-func (w *BaseWorker) scatter(ctx context.Context, cmds []*cmd.DiceDBCmd) error {
+// The commands of type AllShards are sent to the shards in order, the i-th one to the shard i.
+func (w *BaseWorker) scatter(ctx context.Context, cmds []*cmd.DiceDBCmd, ct CmdType) error {
 	// Otherwise check for the shard based on the key using hash
 	// and send it to the particular shard
 	select {
@@ -211,13 +252,20 @@ func (w *BaseWorker) scatter(ctx context.Context, cmds []*cmd.DiceDBCmd) error {
 			var rc chan *ops.StoreOp
 			var sid shard.ShardID
 			var key string
-			if len(cmds[i].Args) > 0 {
+			if keys := eval.CommandKeys(cmds[i]); len(keys) > 0 {
+				key = keys[0]
+			} else if len(cmds[i].Args) > 0 {
 				key = cmds[i].Args[0]
 			} else {
 				key = cmds[i].Cmd
 			}
 
-			sid, rc = w.shardManager.GetShardInfo(key)
+			if ct == AllShards {
+				sid = shard.ShardID(i)
+				rc = w.shardManager.GetShard(sid).ReqChan
+			} else {
+				sid, rc = w.shardManager.GetShardInfo(key)
+			}
 
 			rc <- &ops.StoreOp{
 				SeqID:     i,
@@ -226,6 +274,8 @@ func (w *BaseWorker) scatter(ctx context.Context, cmds []*cmd.DiceDBCmd) error {
 				WorkerID:  w.id,
 				ShardID:   sid,
 				Client:    nil,
+				CanBlock:  true,
+				DB:        w.db,
 			}
 		}
 	}
@@ -255,6 +305,19 @@ func (w *BaseWorker) gather(ctx context.Context, c string, numCmds int, ct CmdTy
 		}
 	}
 
+	for _, reply := range w.replies(c, ct, evalResp) {
+		if err := w.writeReply(ctx, c, reply); err != nil {
+			w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replies returns the replies to write to the client, in order, for the
+// responses of the shards to the command c of type ct.
+func (w *BaseWorker) replies(c string, ct CmdType, evalResp []eval.EvalResponse) []interface{} {
 	// TODO: This is a temporary solution. In the future, all commands should be refactored to be multi-shard compatible.
 	// TODO: There are a few commands such as QWATCH, RENAME, MGET, MSET that wouldn't work in multi-shard mode without refactoring.
 	// TODO: These commands should be refactored to be multi-shard compatible before DICE-DB is completely multi-shard.
@@ -262,65 +325,58 @@ func (w *BaseWorker) gather(ctx context.Context, c string, numCmds int, ct CmdTy
 	// If not found, treat it as a command that's not yet refactored, and write the response back to the client.
 	val, ok := CommandsMeta[c]
 	if !ok {
+		var replies []interface{}
 		if evalResp[0].Error != nil {
-			err := w.ioHandler.Write(ctx, []byte(evalResp[0].Error.Error()))
-			if err != nil {
-				w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
-				return err
-			}
+			replies = append(replies, []byte(evalResp[0].Error.Error()))
 		}
-
-		err := w.ioHandler.Write(ctx, evalResp[0].Result.([]byte))
-		if err != nil {
-			w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
-			return err
-		}
-
-		return nil
+		return append(replies, evalResp[0].Result)
 	}
 
 	switch ct {
 	case SingleShard, Custom:
 		if evalResp[0].Error != nil {
-			err := w.ioHandler.Write(ctx, evalResp[0].Error)
-			if err != nil {
-				w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
-			}
-
-			return err
-		}
-
-		err := w.ioHandler.Write(ctx, evalResp[0].Result)
-		if err != nil {
-			w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
-			return err
+			return []interface{}{evalResp[0].Error}
 		}
-
-	case MultiShard:
-		err := w.ioHandler.Write(ctx, val.composeResponse(evalResp...))
-		if err != nil {
-			w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
-			return err
-		}
-
+		return []interface{}{evalResp[0].Result}
+	case MultiShard, AllShards:
+		return []interface{}{val.composeResponse(evalResp...)}
 	default:
 		w.logger.Error("Unknown command type", slog.String("workerID", w.id))
-		err := w.ioHandler.Write(ctx, diceerrors.ErrInternalServer)
-		if err != nil {
-			w.logger.Debug("Error sending response to client", slog.String("workerID", w.id), slog.Any("error", err))
-			return err
-		}
+		return []interface{}{diceerrors.ErrInternalServer}
 	}
+}
 
-	return nil
+// writeReply writes the reply to the command c, see encodeReply.
+func (w *BaseWorker) writeReply(ctx context.Context, c string, reply interface{}) error {
+	return w.ioHandler.Write(ctx, encodeReply(c, reply))
 }
 
+// encodeReply encodes the reply to the command c like the IOHandlers do,
+// rewritten to the one of the reference server if set, see eval.CompatReply.
+func encodeReply(c string, reply interface{}) []byte {
+	encoded := netconn.HandlePredefinedResponse(reply)
+	if encoded == nil {
+		encoded = clientio.Encode(reply, true)
+	}
+	return eval.CompatReply(config.Current().Server.ReplyCompat, c, encoded)
+}
+
+// isAuthenticated returns an error if the command may not run, the session
+// being unauthenticated or its user lacking the permission. The session is
+// re-evaluated first if the users changed: it returns errSessionRevoked if its
+// user lost access and the connection must be killed.
 func (w *BaseWorker) isAuthenticated(diceDBCmd *cmd.DiceDBCmd) error {
+	if w.Session.Revoked() {
+		if auth.RevokePolicy() == auth.RevokeKill {
+			return errSessionRevoked
+		}
+		w.Session.Downgrade()
+	}
 	if diceDBCmd.Cmd != auth.Cmd && !w.Session.IsActive() {
 		return errors.New("NOAUTH Authentication required")
 	}
 
-	return nil
+	return w.Session.Authorize(diceDBCmd.Cmd, "id="+w.id)
 }
 
 // RespAuth returns with an encoded "OK" if the user is authenticated
@@ -331,11 +387,14 @@ func (w *BaseWorker) RespAuth(args []string) interface{} {
 		return diceerrors.ErrWrongArgumentCount("AUTH")
 	}
 
-	if config.DiceConfig.Auth.Password == "" {
+	// the users set up by ACL SETUSER may authenticate without a password
+	// configured for the default user, as may the bearer tokens
+	if config.DiceConfig.Auth.Password == "" && len(args) == 1 && !auth.IsToken(args[0]) {
 		return diceerrors.ErrAuth
 	}
 
-	username := config.DiceConfig.Auth.UserName
+	// the default user, or the user named by a bearer token
+	username := ""
 	var password string
 
 	if len(args) == 1 {
@@ -351,6 +410,19 @@ func (w *BaseWorker) RespAuth(args []string) interface{} {
 	return clientio.OK
 }
 
+// RespSelect switches the logical database the commands of the client run on.
+func (w *BaseWorker) RespSelect(args []string) interface{} {
+	if len(args) != 1 {
+		return diceerrors.ErrWrongArgumentCount("SELECT")
+	}
+	db, err := eval.ParseDBIndex(args[0])
+	if err != nil {
+		return err
+	}
+	w.db = db
+	return clientio.OK
+}
+
 func (w *BaseWorker) Stop() error {
 	w.logger.Info("Stopping worker", slog.String("workerID", w.id))
 	w.Session.Expire()
@@ -12,7 +12,9 @@ import (
 	"syscall"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/auth"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
 	"github.com/dicedb/dice/internal/logger"
 	"github.com/dicedb/dice/internal/server"
 	"github.com/dicedb/dice/internal/server/resp"
@@ -26,12 +28,15 @@ func init() {
 	flag.IntVar(&config.Port, "port", 7379, "port for the dice server")
 	flag.BoolVar(&config.EnableHTTP, "enable-http", true, "run server in HTTP mode as well")
 	flag.BoolVar(&config.EnableMultiThreading, "enable-multithreading", false, "run server in multithreading mode")
+	flag.BoolVar(&config.EnableEventLoop, "enable-event-loop", false, "with --enable-multithreading, serve the connections from an event loop instead of a goroutine each")
 	flag.IntVar(&config.HTTPPort, "http-port", 8082, "HTTP port for the dice server")
 	flag.IntVar(&config.WebsocketPort, "websocket-port", 8379, "Websocket port for the dice server")
 	flag.StringVar(&config.RequirePass, "requirepass", config.RequirePass, "enable authentication for the default user")
 	flag.StringVar(&config.CustomConfigFilePath, "o", config.CustomConfigFilePath, "dir path to create the config file")
 	flag.StringVar(&config.FileLocation, "c", config.FileLocation, "file path of the config file")
 	flag.BoolVar(&config.InitConfigCmd, "init-config", false, "initialize a new config file")
+	flag.BoolVar(&config.CheckData, "check", false, "validate the AOF file and the data it holds, then exit")
+	flag.BoolVar(&config.CheckRepair, "check-repair", false, "with --check, repair or drop the corrupt data found")
 	flag.Parse()
 
 	config.SetupConfig()
@@ -41,12 +46,50 @@ func main() {
 	logr := logger.New(logger.Opts{WithTimestamp: true})
 	slog.SetDefault(logr)
 
+	if config.CheckData {
+		os.Exit(runCheck(logr))
+	}
+
+	// the invalid settings fall back to their default: the replies are left
+	// as is, the connections whose user lost access are killed and the keys get
+	// no default expiry
+	if err := eval.ValidateConfig(config.DiceConfig); err != nil {
+		logr.Warn("invalid config, the invalid settings fall back to their default", slog.Any("error", err))
+	}
+	if err := dstore.DefaultTTLs.Load(config.DiceConfig.Server.DefaultTTLs); err != nil {
+		logr.Debug("no default TTL policy loaded", slog.Any("error", err))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
+	auth.SetupTokenProviders(ctx)
 
 	// Handle SIGTERM and SIGINT
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
 
+	// Handle SIGHUP by reloading the config file
+	hups := make(chan os.Signal, 1)
+	signal.Notify(hups, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				signal.Stop(hups)
+				return
+			case <-hups:
+				res, err := eval.ReloadConfig()
+				if err != nil {
+					logr.Warn("could not reload the config", slog.Any("error", err))
+					continue
+				}
+				logr.Info("config reloaded",
+					slog.Any("applied", res.Applied),
+					slog.Any("restart_required", res.RestartRequired),
+				)
+			}
+		}
+	}()
+
 	watchChan := make(chan dstore.QueryWatchEvent, config.DiceConfig.Server.KeysLimit)
 	var serverErrCh chan error
 
@@ -222,3 +265,35 @@ func main() {
 	wg.Wait()
 	logr.Debug("Server has shut down gracefully")
 }
+
+// runCheck validates the AOF file, logs the report and returns the exit code of
+// the check mode.
+func runCheck(logr *slog.Logger) int {
+	path := config.DiceConfig.Server.AOFFile
+	report, err := eval.CheckAOF(path, config.CheckRepair)
+	if err != nil {
+		logr.Error("could not check the AOF file", slog.String("path", path), slog.Any("error", err))
+		return 2
+	}
+
+	for _, issue := range report.Issues {
+		logr.Warn("corrupt data",
+			slog.String("key", issue.Key),
+			slog.String("reason", issue.Reason),
+			slog.String("action", issue.Action),
+		)
+	}
+	logr.Info("AOF file checked",
+		slog.String("path", path),
+		slog.Int("commands", report.Commands),
+		slog.Int("keys", report.Keys),
+		slog.Int("issues", len(report.Issues)),
+		slog.Int64("truncated_bytes", report.Truncated),
+		slog.Bool("repaired", config.CheckRepair),
+	)
+
+	if report.OK() || config.CheckRepair {
+		return 0
+	}
+	return 1
+}